@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"key-value/proto/keyvalue"
+	"time"
+)
+
+// RestoreMode decides what Restore does with the current data
+type RestoreMode = keyvalue.RestoreMode
+
+// Restore modes
+const (
+	// RestoreReplace replaces the whole store with the backup, dropping keys it does not hold
+	RestoreReplace = keyvalue.RestoreMode_RESTORE_MODE_REPLACE
+	// RestoreMerge writes the pairs of the backup over the current data, keeping other keys
+	RestoreMerge = keyvalue.RestoreMode_RESTORE_MODE_MERGE
+)
+
+// restoreChunkBytes bounds the part of a backup file sent by one Restore request
+const restoreChunkBytes = 1 << 20
+
+// RestoreResult describes a restored backup
+type RestoreResult struct {
+	Pairs   int
+	Created time.Time
+}
+
+// Backup writes a backup file of the store, as of one point in time, to w. The file carries its format version and
+// a checksum and is restored with Restore. Streams are not bounded by the default timeout.
+func (c *KVStoreClient) Backup(ctx context.Context, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := keyvalue.NewBackupServiceClient(c.conn).Backup(ctx, &keyvalue.BackupRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to back up: %w", translateError(err))
+	}
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to back up: %w", translateError(err))
+		}
+		if _, err := w.Write(chunk.Data); err != nil {
+			return err
+		}
+	}
+}
+
+// Restore loads the backup file read from r. The service checks the whole file before changing the store and
+// returns ErrInvalidBackup when it is damaged. Streams are not bounded by the default timeout.
+func (c *KVStoreClient) Restore(ctx context.Context, mode RestoreMode, r io.Reader) (RestoreResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := keyvalue.NewBackupServiceClient(c.conn).Restore(ctx)
+	if err != nil {
+		return RestoreResult{}, fmt.Errorf("failed to restore: %w", translateError(err))
+	}
+	for {
+		// Sent messages must not be modified, so every chunk gets its own buffer
+		buf := make([]byte, restoreChunkBytes)
+		n, err := io.ReadFull(r, buf)
+		if n > 0 && stream.Send(&keyvalue.RestoreRequest{Mode: mode, Data: buf[:n]}) != nil {
+			// A failed send ends the stream, whose error CloseAndRecv reports
+			break
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			return RestoreResult{}, err
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return RestoreResult{}, fmt.Errorf("failed to restore: %w", translateError(err))
+	}
+	return RestoreResult{Pairs: int(resp.Pairs), Created: time.UnixMilli(resp.CreatedMillis)}, nil
+}
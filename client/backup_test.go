@@ -0,0 +1,104 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"key-value/proto/keyvalue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// backupService is an in-process backup service sending a fixed file and accepting that file only
+type backupService struct {
+	keyvalue.UnimplementedBackupServiceServer
+	file     []byte
+	modes    []keyvalue.RestoreMode
+	requests int
+}
+
+func (b *backupService) Backup(req *keyvalue.BackupRequest, stream keyvalue.BackupService_BackupServer) error {
+	for i := 0; i < len(b.file); i += 4 {
+		if err := stream.Send(&keyvalue.BackupChunk{Data: b.file[i:min(i+4, len(b.file))]}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *backupService) Restore(stream keyvalue.BackupService_RestoreServer) error {
+	var file []byte
+	b.modes, b.requests = nil, 0
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		b.modes = append(b.modes, req.Mode)
+		b.requests++
+		file = append(file, req.Data...)
+	}
+	if !bytes.Equal(file, b.file) {
+		st, _ := status.New(codes.InvalidArgument, "invalid backup: checksum mismatch").WithDetails(&errdetails.ErrorInfo{Reason: "INVALID_BACKUP", Domain: errorDomain})
+		return st.Err()
+	}
+	return stream.SendAndClose(&keyvalue.RestoreResponse{Pairs: 2, CreatedMillis: 1700000000000})
+}
+
+// serveBackups starts service and connects a client to it
+func serveBackups(t *testing.T, service *backupService) *KVStoreClient {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	keyvalue.RegisterBackupServiceServer(server, service)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	client, err := NewKVStoreClient(lis.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestKVStoreClient_Backup(t *testing.T) {
+	client := serveBackups(t, &backupService{file: []byte("KVBACKUP and the rest of the file")})
+
+	var file bytes.Buffer
+	require.NoError(t, client.Backup(context.Background(), &file))
+	assert.Equal(t, "KVBACKUP and the rest of the file", file.String())
+}
+
+func TestKVStoreClient_Restore(t *testing.T) {
+	large := strings.Repeat("x", restoreChunkBytes+10)
+	service := &backupService{file: []byte(large)}
+	client := serveBackups(t, service)
+
+	result, err := client.Restore(context.Background(), RestoreMerge, strings.NewReader(large))
+	require.NoError(t, err)
+	assert.Equal(t, RestoreResult{Pairs: 2, Created: time.UnixMilli(1700000000000)}, result)
+	assert.Equal(t, 2, service.requests, "the file is sent in chunks")
+	assert.Equal(t, []RestoreMode{RestoreMerge, RestoreMerge}, service.modes)
+
+	_, err = client.Restore(context.Background(), RestoreReplace, strings.NewReader("damaged"))
+	assert.ErrorIs(t, err, ErrInvalidBackup)
+
+	// Read errors abort the restore
+	_, err = client.Restore(context.Background(), RestoreReplace, io.MultiReader(strings.NewReader("KV"), iotest.ErrReader(errStop)))
+	assert.ErrorIs(t, err, errStop)
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/health" // Registers the client side health checking used by healthCheckConfig
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+)
+
+const (
+	// RoundRobin spreads calls over every healthy endpoint
+	RoundRobin = "round_robin"
+	// PickFirst sends every call to the first healthy endpoint and fails over to the next one
+	PickFirst = "pick_first"
+
+	// staticScheme is the resolver scheme used for comma separated endpoint lists
+	staticScheme = "kvstore-static"
+)
+
+// WithLoadBalancingPolicy selects how calls are spread across the resolved endpoints (RoundRobin or PickFirst).
+// It defaults to RoundRobin for endpoint lists and dns: names, which may resolve to several replicas, and PickFirst
+// for a single address.
+func WithLoadBalancingPolicy(policy string) Option {
+	return func(o *clientOptions) {
+		o.loadBalancingPolicy = policy
+	}
+}
+
+// parseEndpoints splits a comma separated address list, dropping empty entries
+func parseEndpoints(address string) []string {
+	var endpoints []string
+	for _, endpoint := range strings.Split(address, ",") {
+		endpoint = strings.TrimSpace(endpoint)
+		if endpoint != "" {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
+
+// balancingPolicy returns the configured policy, else RoundRobin whenever the endpoints may be several replicas
+func balancingPolicy(options *clientOptions, endpoints []string, members *MemberWatcher) (string, error) {
+	policy := options.loadBalancingPolicy
+	if policy == "" {
+		policy = PickFirst
+		if len(endpoints) > 1 || members != nil || resolvesToMany(endpoints[0]) {
+			policy = RoundRobin
+		}
+	}
+	if policy != RoundRobin && policy != PickFirst {
+		return "", fmt.Errorf("unsupported load balancing policy %q", policy)
+	}
+	return policy, nil
+}
+
+// resolvesToMany reports whether a target uses a resolver that can return several addresses, like dns:///name
+func resolvesToMany(target string) bool {
+	scheme, _, found := strings.Cut(target, ":")
+	return found && strings.EqualFold(scheme, "dns")
+}
+
+// resolveTarget turns the address given to NewKVStoreClient into a gRPC target.
+// A single address (or a dns:/// name resolving to several hosts) is passed through as is,
+// while a comma separated list is served by a static resolver. With a watcher the endpoints
+// are the alive members of the gossip group.
+func resolveTarget(address string, options *clientOptions, members *MemberWatcher) (string, []grpc.DialOption, error) {
+	endpoints := parseEndpoints(address)
+	if len(endpoints) == 0 {
+		return "", nil, fmt.Errorf("no key-value service address given")
+	}
+
+	policy, err := balancingPolicy(options, endpoints, members)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Health checking makes the balancer skip replicas reporting NOT_SERVING.
+	// Servers without the health service are treated as healthy.
+	serviceConfig := fmt.Sprintf(`{"loadBalancingConfig":[{%q:{}}],"healthCheckConfig":{"serviceName":""}}`, policy)
+	dialOptions := []grpc.DialOption{grpc.WithDefaultServiceConfig(serviceConfig)}
+
+	if members != nil {
+		r := &memberResolver{watcher: members, seeds: endpoints}
+		return membersScheme + ":///" + strings.Join(endpoints, ","), append(dialOptions, grpc.WithResolvers(r)), nil
+	}
+
+	if len(endpoints) == 1 {
+		return endpoints[0], dialOptions, nil
+	}
+
+	state := resolver.State{}
+	for _, endpoint := range endpoints {
+		state.Endpoints = append(state.Endpoints, resolver.Endpoint{
+			Addresses: []resolver.Address{{Addr: endpoint}},
+		})
+	}
+	r := manual.NewBuilderWithScheme(staticScheme)
+	r.InitialState(state)
+
+	return staticScheme + ":///" + strings.Join(endpoints, ","), append(dialOptions, grpc.WithResolvers(r)), nil
+}
@@ -0,0 +1,224 @@
+package client
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"key-value/proto/keyvalue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// replica is an in-process key-value service that counts the calls it receives
+type replica struct {
+	keyvalue.UnimplementedKeyValueServiceServer
+	calls  atomic.Int64
+	health *health.Server
+	server *grpc.Server
+	addr   string
+}
+
+func (r *replica) Health(ctx context.Context, req *keyvalue.HealthRequest) (*keyvalue.HealthResponse, error) {
+	r.calls.Add(1)
+	return &keyvalue.HealthResponse{Status: "healthy", Timestamp: time.Now().Unix()}, nil
+}
+
+func startReplicas(t *testing.T, n int) []*replica {
+	t.Helper()
+	replicas := make([]*replica, n)
+	for i := range replicas {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		r := &replica{
+			health: health.NewServer(),
+			server: grpc.NewServer(),
+			addr:   lis.Addr().String(),
+		}
+		keyvalue.RegisterKeyValueServiceServer(r.server, r)
+		healthpb.RegisterHealthServer(r.server, r.health)
+		go r.server.Serve(lis)
+		t.Cleanup(r.server.Stop)
+
+		replicas[i] = r
+	}
+	return replicas
+}
+
+func addresses(replicas []*replica) string {
+	addrs := make([]string, len(replicas))
+	for i, r := range replicas {
+		addrs[i] = r.addr
+	}
+	return strings.Join(addrs, ",")
+}
+
+func resetCalls(replicas []*replica) {
+	for _, r := range replicas {
+		r.calls.Store(0)
+	}
+}
+
+// waitForAllReady issues calls until every replica has served one, so the balancer has connected to all of them
+func waitForAllReady(t *testing.T, client *KVStoreClient, replicas []*replica) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		_ = client.Health(context.Background())
+		for _, r := range replicas {
+			if r.calls.Load() == 0 {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+	resetCalls(replicas)
+}
+
+func TestKVStoreClient_RoundRobinDistribution(t *testing.T) {
+	replicas := startReplicas(t, 3)
+
+	client, err := NewKVStoreClient(addresses(replicas))
+	require.NoError(t, err)
+	defer client.Close()
+	waitForAllReady(t, client, replicas)
+
+	for i := 0; i < 30; i++ {
+		require.NoError(t, client.Health(context.Background()))
+	}
+
+	for _, r := range replicas {
+		assert.Equal(t, int64(10), r.calls.Load(), "replica %s", r.addr)
+	}
+}
+
+func TestKVStoreClient_FailoverOnStoppedReplica(t *testing.T) {
+	replicas := startReplicas(t, 3)
+
+	client, err := NewKVStoreClient(addresses(replicas))
+	require.NoError(t, err)
+	defer client.Close()
+	waitForAllReady(t, client, replicas)
+
+	replicas[0].server.Stop()
+
+	// The balancer may need a moment to notice the closed connection
+	require.Eventually(t, func() bool {
+		return client.Health(context.Background()) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	resetCalls(replicas)
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, client.Health(context.Background()))
+	}
+
+	assert.Zero(t, replicas[0].calls.Load())
+	assert.Equal(t, int64(20), replicas[1].calls.Load()+replicas[2].calls.Load())
+}
+
+func TestKVStoreClient_SkipsUnhealthyReplica(t *testing.T) {
+	replicas := startReplicas(t, 2)
+
+	client, err := NewKVStoreClient(addresses(replicas))
+	require.NoError(t, err)
+	defer client.Close()
+	waitForAllReady(t, client, replicas)
+
+	replicas[1].health.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	// Wait until the health watch has taken the replica out of rotation
+	require.Eventually(t, func() bool {
+		resetCalls(replicas)
+		for i := 0; i < 10; i++ {
+			_ = client.Health(context.Background())
+		}
+		return replicas[1].calls.Load() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	replicas[1].health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+
+	require.Eventually(t, func() bool {
+		_ = client.Health(context.Background())
+		return replicas[1].calls.Load() > 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestKVStoreClient_PickFirst(t *testing.T) {
+	replicas := startReplicas(t, 2)
+
+	client, err := NewKVStoreClient(addresses(replicas), WithLoadBalancingPolicy(PickFirst))
+	require.NoError(t, err)
+	defer client.Close()
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, client.Health(context.Background()))
+	}
+	assert.Equal(t, int64(10), replicas[0].calls.Load())
+	assert.Zero(t, replicas[1].calls.Load())
+
+	replicas[0].server.Stop()
+
+	require.Eventually(t, func() bool {
+		return client.Health(context.Background()) == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Positive(t, replicas[1].calls.Load())
+}
+
+func TestBalancingPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  string
+		policy   string
+		expected string
+	}{
+		{name: "single address", address: "localhost:50051", expected: PickFirst},
+		{name: "dns name", address: "dns:///kv.internal:50051", expected: RoundRobin},
+		{name: "dns name with authority", address: "DNS://8.8.8.8/kv.internal:50051", expected: RoundRobin},
+		{name: "passthrough", address: "passthrough:///kv.internal:50051", expected: PickFirst},
+		{name: "endpoint list", address: "a:1,b:2", expected: RoundRobin},
+		{name: "explicit policy", address: "dns:///kv.internal:50051", policy: PickFirst, expected: PickFirst},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := balancingPolicy(&clientOptions{loadBalancingPolicy: tt.policy}, parseEndpoints(tt.address), nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, policy)
+		})
+	}
+}
+
+func TestResolveTarget(t *testing.T) {
+	tests := []struct {
+		name        string
+		address     string
+		policy      string
+		expectError bool
+		expected    string
+	}{
+		{name: "single address", address: "localhost:50051", expected: "localhost:50051"},
+		{name: "dns name", address: "dns:///kv.internal:50051", expected: "dns:///kv.internal:50051"},
+		{name: "endpoint list", address: "a:1, b:2", expected: staticScheme + ":///a:1,b:2"},
+		{name: "empty address", address: " , ", expectError: true},
+		{name: "unknown policy", address: "a:1", policy: "random", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, _, err := resolveTarget(tt.address, &clientOptions{loadBalancingPolicy: tt.policy}, nil)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, target)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"key-value/proto/keyvalue"
+	"key-value/shared/models"
+)
+
+// ImportMode decides what Import does with keys that already exist
+type ImportMode = keyvalue.ImportMode
+
+// Import modes
+const (
+	// ImportOverwrite writes over existing values
+	ImportOverwrite = keyvalue.ImportMode_IMPORT_MODE_OVERWRITE
+	// ImportSkipExisting keeps existing values, counting their pairs as skipped
+	ImportSkipExisting = keyvalue.ImportMode_IMPORT_MODE_SKIP_EXISTING
+	// ImportFailOnConflict fails with ErrConflict at the first key holding a different value. Keys already holding
+	// the imported value are skipped, so a failed import can be run again.
+	ImportFailOnConflict = keyvalue.ImportMode_IMPORT_MODE_FAIL_ON_CONFLICT
+)
+
+// Bounds of the requests sent by Import
+const (
+	importChunkPairs = 500
+	importChunkBytes = 1 << 20
+)
+
+// ImportResult counts the pairs an import wrote and skipped
+type ImportResult struct {
+	Written int
+	Skipped int
+}
+
+// Export passes the pairs with prefix to handle in key order, running until all were handled or handle fails.
+// Writes made during the export may or may not be included. Streams are not bounded by the default timeout.
+func (c *KVStoreClient) Export(ctx context.Context, prefix string, handle func(models.KeyValue) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := keyvalue.NewBulkServiceClient(c.conn).Export(ctx, &keyvalue.ExportRequest{Prefix: prefix})
+	if err != nil {
+		return fmt.Errorf("failed to export %q: %w", prefix, translateError(err))
+	}
+	for {
+		pair, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to export %q: %w", prefix, translateError(err))
+		}
+		if err := handle(models.KeyValue{Key: pair.Key, Value: pair.Value}); err != nil {
+			return err
+		}
+	}
+}
+
+// Import stores the pairs returned by next until it returns io.EOF, streaming them in chunks. Writes are not
+// atomic: when next or the service fails, the pairs stored before stay written. Streams are not bounded by the
+// default timeout.
+func (c *KVStoreClient) Import(ctx context.Context, mode ImportMode, next func() (models.KeyValue, error)) (ImportResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := keyvalue.NewBulkServiceClient(c.conn).Import(ctx)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("failed to import: %w", translateError(err))
+	}
+
+	chunk := &keyvalue.ImportRequest{Mode: mode}
+	size := 0
+	// send reports whether the chunk was sent. A failed send ends the stream, whose error CloseAndRecv reports.
+	send := func() bool {
+		ok := stream.Send(chunk) == nil
+		chunk = &keyvalue.ImportRequest{Mode: mode}
+		size = 0
+		return ok
+	}
+
+	for {
+		kv, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return ImportResult{}, err
+		}
+		if len(chunk.Items) > 0 && (len(chunk.Items) == importChunkPairs || size+len(kv.Key)+len(kv.Value) > importChunkBytes) {
+			if !send() {
+				break
+			}
+		}
+		chunk.Items = append(chunk.Items, &keyvalue.KeyValuePair{Key: kv.Key, Value: kv.Value})
+		size += len(kv.Key) + len(kv.Value)
+	}
+	if len(chunk.Items) > 0 {
+		send()
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("failed to import: %w", translateError(err))
+	}
+	return ImportResult{Written: int(resp.Written), Skipped: int(resp.Skipped)}, nil
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	"key-value/proto/keyvalue"
+	"key-value/shared/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// bulkService is an in-process bulk service exporting a fixed list of pairs and recording imported requests
+type bulkService struct {
+	keyvalue.UnimplementedBulkServiceServer
+	pairs    []*keyvalue.KeyValuePair
+	requests []*keyvalue.ImportRequest
+	conflict string
+}
+
+func (b *bulkService) Export(req *keyvalue.ExportRequest, stream keyvalue.BulkService_ExportServer) error {
+	for _, pair := range b.pairs {
+		if err := stream.Send(pair); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *bulkService) Import(stream keyvalue.BulkService_ImportServer) error {
+	resp := &keyvalue.ImportResponse{}
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(resp)
+		}
+		if err != nil {
+			return err
+		}
+		b.requests = append(b.requests, req)
+		for _, item := range req.Items {
+			if item.Key == b.conflict {
+				return status.Errorf(codes.Aborted, "key %s already holds a different value", item.Key)
+			}
+			resp.Written++
+		}
+	}
+}
+
+// serveBulk starts service and connects a client to it
+func serveBulk(t *testing.T, service *bulkService) *KVStoreClient {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	keyvalue.RegisterBulkServiceServer(server, service)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	client, err := NewKVStoreClient(lis.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestKVStoreClient_Export(t *testing.T) {
+	client := serveBulk(t, &bulkService{pairs: []*keyvalue.KeyValuePair{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}}})
+
+	var seen []models.KeyValue
+	err := client.Export(context.Background(), "", func(kv models.KeyValue) error {
+		seen = append(seen, kv)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []models.KeyValue{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}}, seen)
+
+	// Handler errors stop the export
+	err = client.Export(context.Background(), "", func(kv models.KeyValue) error {
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop)
+}
+
+// pairSource returns n generated pairs, then io.EOF
+func pairSource(n int, valueSize int) func() (models.KeyValue, error) {
+	i := 0
+	return func() (models.KeyValue, error) {
+		if i == n {
+			return models.KeyValue{}, io.EOF
+		}
+		i++
+		return models.KeyValue{Key: fmt.Sprintf("key%d", i), Value: string(make([]byte, valueSize))}, nil
+	}
+}
+
+func TestKVStoreClient_Import(t *testing.T) {
+	tests := []struct {
+		name      string
+		pairs     int
+		valueSize int
+		chunks    []int
+	}{
+		{name: "empty", chunks: nil},
+		{name: "one chunk", pairs: 3, chunks: []int{3}},
+		{name: "split by count", pairs: importChunkPairs + 1, chunks: []int{importChunkPairs, 1}},
+		{name: "split by size", pairs: 3, valueSize: importChunkBytes / 2, chunks: []int{1, 1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &bulkService{}
+			client := serveBulk(t, service)
+
+			result, err := client.Import(context.Background(), ImportSkipExisting, pairSource(tt.pairs, tt.valueSize))
+			require.NoError(t, err)
+			assert.Equal(t, ImportResult{Written: tt.pairs}, result)
+
+			var chunks []int
+			for _, req := range service.requests {
+				assert.Equal(t, ImportSkipExisting, req.Mode)
+				chunks = append(chunks, len(req.Items))
+			}
+			assert.Equal(t, tt.chunks, chunks)
+		})
+	}
+}
+
+func TestKVStoreClient_ImportErrors(t *testing.T) {
+	client := serveBulk(t, &bulkService{conflict: "key2"})
+
+	_, err := client.Import(context.Background(), ImportFailOnConflict, pairSource(3, 0))
+	assert.ErrorIs(t, err, ErrConflict)
+
+	// Source errors abort the import
+	_, err = client.Import(context.Background(), ImportOverwrite, func() (models.KeyValue, error) {
+		return models.KeyValue{}, errStop
+	})
+	assert.ErrorIs(t, err, errStop)
+}
@@ -0,0 +1,104 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"key-value/proto/keyvalue"
+	"time"
+)
+
+// Change operations
+const (
+	ChangeSet    = keyvalue.MutationOp_MUTATION_OP_SET
+	ChangeDelete = keyvalue.MutationOp_MUTATION_OP_DELETE
+)
+
+// Change is one Set or Delete captured by the key-value service
+type Change struct {
+	Seq   uint64
+	Op    keyvalue.MutationOp
+	Key   string
+	Value string
+	Time  time.Time
+}
+
+// ChangeOffset is the position of a consumer group and the window of changes the service retains
+type ChangeOffset struct {
+	Committed    uint64 // last change the group processed
+	HasCommitted bool
+	First        uint64 // oldest retained change, one past Latest when none is retained
+	Latest       uint64
+}
+
+// Changes streams the changes starting at fromSeq, or at the oldest retained change when fromSeq is 0, and passes
+// them in order to handle. It runs until ctx is done or handle fails, and fails with ErrChangesExpired when fromSeq
+// is no longer retained. Streams are not bounded by the default timeout.
+// Each key-value service numbers its own changes, so follow a single service.
+func (c *KVStoreClient) Changes(ctx context.Context, fromSeq uint64, handle func(Change) error) error {
+	return c.changes(ctx, &keyvalue.ChangesRequest{FromSeq: fromSeq}, handle)
+}
+
+// Consume streams the changes after the committed offset of group to handle and commits each change handle
+// accepted, so a restarted consumer resumes right after the last change it processed
+func (c *KVStoreClient) Consume(ctx context.Context, group string, handle func(Change) error) error {
+	return c.changes(ctx, &keyvalue.ChangesRequest{Group: group}, func(change Change) error {
+		if err := handle(change); err != nil {
+			return err
+		}
+		return c.CommitOffset(ctx, group, change.Seq)
+	})
+}
+
+func (c *KVStoreClient) changes(ctx context.Context, req *keyvalue.ChangesRequest, handle func(Change) error) error {
+	stream, err := keyvalue.NewChangeServiceClient(c.conn).Changes(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to stream changes: %w", translateError(err))
+	}
+	for {
+		change, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("failed to stream changes: %w", translateError(err))
+		}
+		if err := handle(Change{
+			Seq:   change.Seq,
+			Op:    change.Op,
+			Key:   change.Key,
+			Value: change.Value,
+			Time:  time.UnixMilli(change.TimestampMillis),
+		}); err != nil {
+			return err
+		}
+	}
+}
+
+// CommitOffset records that group processed every change up to and including seq
+func (c *KVStoreClient) CommitOffset(ctx context.Context, group string, seq uint64) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	_, err := keyvalue.NewChangeServiceClient(c.conn).CommitOffset(ctx, &keyvalue.CommitOffsetRequest{Group: group, Seq: seq})
+	if err != nil {
+		return fmt.Errorf("failed to commit offset of %s: %w", group, translateError(err))
+	}
+	return nil
+}
+
+// Offset returns the committed offset of group and the retained window of changes
+func (c *KVStoreClient) Offset(ctx context.Context, group string) (ChangeOffset, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := keyvalue.NewChangeServiceClient(c.conn).GetOffset(ctx, &keyvalue.GetOffsetRequest{Group: group})
+	if err != nil {
+		return ChangeOffset{}, fmt.Errorf("failed to get offset of %s: %w", group, translateError(err))
+	}
+	return ChangeOffset{
+		Committed:    resp.CommittedSeq,
+		HasCommitted: resp.Committed,
+		First:        resp.FirstSeq,
+		Latest:       resp.LatestSeq,
+	}, nil
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+
+	"key-value/proto/keyvalue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// changeLog is an in-process change service holding a fixed list of changes
+type changeLog struct {
+	keyvalue.UnimplementedChangeServiceServer
+	changes []*keyvalue.Change
+
+	mutex   sync.Mutex
+	offsets map[string]uint64
+}
+
+func (l *changeLog) Changes(req *keyvalue.ChangesRequest, stream keyvalue.ChangeService_ChangesServer) error {
+	from := max(req.FromSeq, 1)
+	l.mutex.Lock()
+	if offset, ok := l.offsets[req.Group]; ok && req.FromSeq == 0 {
+		from = offset + 1
+	}
+	l.mutex.Unlock()
+
+	for _, change := range l.changes[from-1:] {
+		if err := stream.Send(change); err != nil {
+			return err
+		}
+	}
+	<-stream.Context().Done()
+	return nil
+}
+
+func (l *changeLog) CommitOffset(ctx context.Context, req *keyvalue.CommitOffsetRequest) (*keyvalue.CommitOffsetResponse, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.offsets[req.Group] = req.Seq
+	return &keyvalue.CommitOffsetResponse{}, nil
+}
+
+func (l *changeLog) GetOffset(ctx context.Context, req *keyvalue.GetOffsetRequest) (*keyvalue.GetOffsetResponse, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	seq, ok := l.offsets[req.Group]
+	return &keyvalue.GetOffsetResponse{CommittedSeq: seq, Committed: ok, FirstSeq: 1, LatestSeq: uint64(len(l.changes))}, nil
+}
+
+func startChangeLog(t *testing.T, n int) *KVStoreClient {
+	t.Helper()
+	log := &changeLog{offsets: make(map[string]uint64)}
+	for i := range n {
+		log.changes = append(log.changes, &keyvalue.Change{Seq: uint64(i + 1), Op: ChangeSet, Key: "k", Value: "v"})
+	}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	keyvalue.RegisterChangeServiceServer(server, log)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	client, err := NewKVStoreClient(lis.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+var errStop = errors.New("stop")
+
+func TestKVStoreClient_ConsumeResumes(t *testing.T) {
+	client := startChangeLog(t, 5)
+	ctx := context.Background()
+
+	// The first consumer fails on the third change
+	var seen []uint64
+	err := client.Consume(ctx, "indexer", func(change Change) error {
+		if change.Seq == 3 {
+			return errStop
+		}
+		seen = append(seen, change.Seq)
+		return nil
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, []uint64{1, 2}, seen)
+
+	offset, err := client.Offset(ctx, "indexer")
+	require.NoError(t, err)
+	assert.Equal(t, ChangeOffset{Committed: 2, HasCommitted: true, First: 1, Latest: 5}, offset)
+
+	// The next one starts with the change that failed
+	seen = nil
+	err = client.Consume(ctx, "indexer", func(change Change) error {
+		seen = append(seen, change.Seq)
+		if change.Seq == 5 {
+			return errStop
+		}
+		return nil
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, []uint64{3, 4, 5}, seen)
+}
+
+func TestKVStoreClient_ChangesStopsWithContext(t *testing.T) {
+	client := startChangeLog(t, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var seen []uint64
+	err := client.Changes(ctx, 2, func(change Change) error {
+		seen = append(seen, change.Seq)
+		cancel()
+		return nil
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []uint64{2}, seen)
+}
@@ -0,0 +1,280 @@
+// Package election elects one leader among the candidates of a named election through the key-value service.
+//
+// The leader is the candidate holding the election key, attached to a lease it keeps alive in the background. When
+// the leader resigns, or dies and its lease expires, the key is deleted and the service notifies the watching
+// candidates and observers right away, so a new leader is elected without polling.
+package election
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"key-value/client"
+	"key-value/client/internal/keepalive"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultTTL           = 10 * time.Second
+	DefaultRetryInterval = 500 * time.Millisecond
+	DefaultPrefix        = "elections/"
+
+	// releaseTimeout bounds the revocation of the lease of a candidate that gave up
+	releaseTimeout = 5 * time.Second
+)
+
+var (
+	// ErrNoLeader is returned by Leader when the election has no leader
+	ErrNoLeader = errors.New("election has no leader")
+	// ErrLost is returned when the lease of a leader expired, so another candidate may lead since
+	ErrLost = errors.New("leadership was lost")
+)
+
+// Client is the part of client.KVStoreClient that elections use
+type Client interface {
+	Grant(ctx context.Context, ttl time.Duration) (client.Lease, error)
+	KeepAlive(ctx context.Context, id int64) (client.Lease, error)
+	Revoke(ctx context.Context, id int64) error
+	Acquire(ctx context.Context, key string, value string, leaseID int64) (client.Acquisition, error)
+	Get(ctx context.Context, key string) (string, bool, error)
+	Watch(ctx context.Context, key string, handle func(client.KeyEvent) error) error
+}
+
+// Election is a named election
+type Election struct {
+	client        Client
+	name          string
+	key           string
+	ttl           time.Duration
+	retryInterval time.Duration
+}
+
+// Option configures an Election
+type Option func(*Election)
+
+// WithTTL sets the time to live of the lease of a leader, DefaultTTL by default. The followers of a leader that
+// died take over after up to this long; the lease is renewed every third of it.
+func WithTTL(ttl time.Duration) Option {
+	return func(e *Election) {
+		if ttl > 0 {
+			e.ttl = ttl
+		}
+	}
+}
+
+// WithRetryInterval sets the pause before watching the election again after the watch failed,
+// DefaultRetryInterval by default
+func WithRetryInterval(interval time.Duration) Option {
+	return func(e *Election) {
+		if interval > 0 {
+			e.retryInterval = interval
+		}
+	}
+}
+
+// WithPrefix sets the prefix of the election keys, DefaultPrefix by default
+func WithPrefix(prefix string) Option {
+	return func(e *Election) {
+		e.key = prefix + e.name
+	}
+}
+
+// New creates the named election held through c
+func New(c Client, name string, opts ...Option) *Election {
+	e := &Election{
+		client:        c,
+		name:          name,
+		key:           DefaultPrefix + name,
+		ttl:           DefaultTTL,
+		retryInterval: DefaultRetryInterval,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Campaign waits until the candidate is elected or ctx is done. The value identifies the candidate, for example
+// its address, and is what Leader and Observe report while it leads.
+func (e *Election) Campaign(ctx context.Context, value string) (*Leadership, error) {
+	lease, err := e.client.Grant(ctx, e.ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to campaign in %s: %w", e.name, err)
+	}
+
+	// Keep the lease alive while waiting, so it does not expire before being elected
+	l := &Leadership{
+		election: e,
+		value:    value,
+		leaseID:  lease.ID,
+		renewal:  keepalive.Start(e.client, lease.ID, e.ttl),
+	}
+
+	for {
+		acquisition, err := e.client.Acquire(ctx, e.key, value, lease.ID)
+		if err != nil {
+			l.abandon()
+			if errors.Is(err, client.ErrLeaseNotFound) {
+				err = ErrLost
+			}
+			return nil, fmt.Errorf("failed to campaign in %s: %w", e.name, err)
+		}
+		if acquisition.Acquired {
+			l.token = acquisition.FencingToken
+			return l, nil
+		}
+
+		if err := e.waitVacant(ctx, l.renewal.Lost()); err != nil {
+			l.abandon()
+			return nil, err
+		}
+	}
+}
+
+// waitVacant returns once the election key is deleted, or fails when ctx is done or the lease is lost
+func (e *Election) waitVacant(ctx context.Context, lost <-chan struct{}) error {
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-lost:
+			cancel()
+		case <-watchCtx.Done():
+		}
+	}()
+
+	errVacant := errors.New("vacant")
+	err := e.client.Watch(watchCtx, e.key, func(event client.KeyEvent) error {
+		if event.Op == client.ChangeDelete {
+			return errVacant
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, errVacant):
+		return nil
+	case ctx.Err() != nil:
+		return ctx.Err()
+	}
+	select {
+	case <-lost:
+		return fmt.Errorf("failed to campaign in %s: %w", e.name, ErrLost)
+	default:
+	}
+
+	// The watch failed, try again after a pause
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(e.retryInterval):
+		return nil
+	}
+}
+
+// Leader returns the value of the current leader, failing with ErrNoLeader when there is none
+func (e *Election) Leader(ctx context.Context) (string, error) {
+	value, found, err := e.client.Get(ctx, e.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to get leader of %s: %w", e.name, err)
+	}
+	if !found {
+		return "", ErrNoLeader
+	}
+	return value, nil
+}
+
+// Observe returns a channel receiving the value of the current leader and then every change of leader, an empty
+// string while there is none, until ctx is done. A failed watch is retried, so changes may be coalesced but the
+// latest leader is always reported.
+func (e *Election) Observe(ctx context.Context) <-chan string {
+	leaders := make(chan string)
+	go func() {
+		defer close(leaders)
+		reported := false
+		var last string
+		for {
+			e.client.Watch(ctx, e.key, func(event client.KeyEvent) error {
+				leader := ""
+				if event.Op == client.ChangeSet {
+					leader = event.Value
+				}
+				if reported && leader == last {
+					return nil
+				}
+				select {
+				case leaders <- leader:
+					reported, last = true, leader
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(e.retryInterval):
+			}
+		}
+	}()
+	return leaders
+}
+
+// Leadership is the term of an elected candidate. Its lease is renewed in the background until Resign.
+type Leadership struct {
+	election *Election
+	value    string
+	token    uint64
+	leaseID  int64
+	renewal  *keepalive.Renewal
+
+	once      sync.Once
+	resignErr error
+}
+
+// Value returns the value the leader campaigned with
+func (l *Leadership) Value() string {
+	return l.value
+}
+
+// Token returns the fencing token of this term, larger than the tokens of every earlier leader.
+// Pass it along with writes to shared resources so they can reject stale leaders.
+func (l *Leadership) Token() uint64 {
+	return l.token
+}
+
+// Lost returns a channel closed when the lease of the leader could not be renewed in time.
+// From then on another candidate may lead, so stop acting as the leader.
+func (l *Leadership) Lost() <-chan struct{} {
+	return l.renewal.Lost()
+}
+
+// Resign ends the term, so another candidate can be elected right away. It returns ErrLost when the
+// leadership was lost before; calling it again returns the same result.
+func (l *Leadership) Resign(ctx context.Context) error {
+	l.once.Do(func() {
+		name := l.election.name
+		if l.renewal.Stop() {
+			l.resignErr = fmt.Errorf("failed to resign from %s: %w", name, ErrLost)
+			return
+		}
+
+		err := l.election.client.Revoke(ctx, l.leaseID)
+		if errors.Is(err, client.ErrLeaseNotFound) {
+			err = ErrLost
+		}
+		if err != nil {
+			l.resignErr = fmt.Errorf("failed to resign from %s: %w", name, err)
+		}
+	})
+	return l.resignErr
+}
+
+// abandon stops renewing and revokes the lease of a candidate that was not elected
+func (l *Leadership) abandon() {
+	l.renewal.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	l.election.client.Revoke(ctx, l.leaseID)
+}
@@ -0,0 +1,307 @@
+package election
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"key-value/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeService is an in-memory key-value service with leases and watches that expires leases in the background
+type fakeService struct {
+	mutex    sync.Mutex
+	lastID   int64
+	fence    uint64
+	expiry   map[int64]time.Time
+	ttl      map[int64]time.Duration
+	owners   map[string]int64
+	values   map[string]string
+	watchers map[chan client.KeyEvent]string // key each watcher watches
+}
+
+func newFakeService(t *testing.T) *fakeService {
+	f := &fakeService{
+		expiry:   make(map[int64]time.Time),
+		ttl:      make(map[int64]time.Duration),
+		owners:   make(map[string]int64),
+		values:   make(map[string]string),
+		watchers: make(map[chan client.KeyEvent]string),
+	}
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	go func() {
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				f.expire()
+			}
+		}
+	}()
+	return f
+}
+
+func (f *fakeService) expire() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for id, expiry := range f.expiry {
+		if !time.Now().Before(expiry) {
+			f.end(id)
+		}
+	}
+}
+
+// end removes a lease and deletes its keys. The caller holds the mutex.
+func (f *fakeService) end(id int64) {
+	delete(f.expiry, id)
+	for key, owner := range f.owners {
+		if owner == id {
+			delete(f.owners, key)
+			delete(f.values, key)
+			f.notify(client.KeyEvent{Op: client.ChangeDelete, Key: key})
+		}
+	}
+}
+
+// notify hands an event to the watchers of its key. The caller holds the mutex.
+func (f *fakeService) notify(event client.KeyEvent) {
+	for events, key := range f.watchers {
+		if key == event.Key {
+			events <- event
+		}
+	}
+}
+
+// alive reports whether a lease has not expired yet. The caller holds the mutex.
+func (f *fakeService) alive(id int64) bool {
+	expiry, ok := f.expiry[id]
+	return ok && time.Now().Before(expiry)
+}
+
+func (f *fakeService) Grant(ctx context.Context, ttl time.Duration) (client.Lease, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.lastID++
+	f.expiry[f.lastID] = time.Now().Add(ttl)
+	f.ttl[f.lastID] = ttl
+	return client.Lease{ID: f.lastID, TTL: ttl, Remaining: ttl}, nil
+}
+
+func (f *fakeService) KeepAlive(ctx context.Context, id int64) (client.Lease, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if !f.alive(id) {
+		return client.Lease{}, fmt.Errorf("failed to keep lease %d alive: %w", id, client.ErrLeaseNotFound)
+	}
+	f.expiry[id] = time.Now().Add(f.ttl[id])
+	return client.Lease{ID: id, TTL: f.ttl[id], Remaining: f.ttl[id]}, nil
+}
+
+func (f *fakeService) Revoke(ctx context.Context, id int64) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if !f.alive(id) {
+		return fmt.Errorf("failed to revoke lease %d: %w", id, client.ErrLeaseNotFound)
+	}
+	f.end(id)
+	return nil
+}
+
+func (f *fakeService) Acquire(ctx context.Context, key string, value string, leaseID int64) (client.Acquisition, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if !f.alive(leaseID) {
+		return client.Acquisition{}, fmt.Errorf("failed to acquire %s: %w", key, client.ErrLeaseNotFound)
+	}
+	if holder, ok := f.values[key]; ok {
+		return client.Acquisition{Holder: holder}, nil
+	}
+	f.owners[key] = leaseID
+	f.values[key] = value
+	f.fence++
+	f.notify(client.KeyEvent{Op: client.ChangeSet, Key: key, Value: value})
+	return client.Acquisition{Acquired: true, FencingToken: f.fence, Holder: value}, nil
+}
+
+func (f *fakeService) Get(ctx context.Context, key string) (string, bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	value, ok := f.values[key]
+	return value, ok, nil
+}
+
+func (f *fakeService) Watch(ctx context.Context, key string, handle func(client.KeyEvent) error) error {
+	events := make(chan client.KeyEvent, 64)
+	f.mutex.Lock()
+	current := client.KeyEvent{Op: client.ChangeDelete, Key: key}
+	if value, ok := f.values[key]; ok {
+		current = client.KeyEvent{Op: client.ChangeSet, Key: key, Value: value}
+	}
+	events <- current
+	f.watchers[events] = key
+	f.mutex.Unlock()
+
+	defer func() {
+		f.mutex.Lock()
+		delete(f.watchers, events)
+		f.mutex.Unlock()
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event := <-events:
+			if err := handle(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// kill stops renewing the lease of a leader without revoking it, as if its process died
+func kill(l *Leadership) {
+	l.renewal.Stop()
+}
+
+func TestElection_CandidatesRace(t *testing.T) {
+	service := newFakeService(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		mutex   sync.Mutex
+		leaders int
+		tokens  []uint64
+	)
+	for i := range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e := New(service, "scheduler", WithTTL(time.Second))
+			l, err := e.Campaign(ctx, fmt.Sprintf("candidate-%d", i))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			mutex.Lock()
+			leaders++
+			assert.Equal(t, 1, leaders, "two leaders at once")
+			tokens = append(tokens, l.Token())
+			mutex.Unlock()
+
+			leader, err := e.Leader(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, l.Value(), leader)
+			time.Sleep(5 * time.Millisecond)
+
+			mutex.Lock()
+			leaders--
+			mutex.Unlock()
+			assert.NoError(t, l.Resign(ctx))
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, tokens, 5)
+	for i := 1; i < len(tokens); i++ {
+		assert.Greater(t, tokens[i], tokens[i-1], "fencing tokens must grow with each term")
+	}
+	_, err := New(service, "scheduler").Leader(ctx)
+	assert.ErrorIs(t, err, ErrNoLeader)
+}
+
+func TestElection_LeaderKilled(t *testing.T) {
+	service := newFakeService(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	e := New(service, "scheduler", WithTTL(50*time.Millisecond))
+	observed := e.Observe(ctx)
+	assert.Equal(t, "", <-observed)
+
+	first, err := e.Campaign(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "a", <-observed)
+
+	// The follower waits on its watch until the leader's lease expires
+	elected := make(chan *Leadership)
+	go func() {
+		l, err := New(service, "scheduler", WithTTL(50*time.Millisecond)).Campaign(ctx, "b")
+		assert.NoError(t, err)
+		elected <- l
+	}()
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case <-elected:
+		t.Fatal("follower elected while the leader is alive")
+	default:
+	}
+
+	start := time.Now()
+	kill(first)
+	var second *Leadership
+	select {
+	case second = <-elected:
+	case <-ctx.Done():
+		t.Fatal("follower not elected after the leader died")
+	}
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Greater(t, second.Token(), first.Token())
+
+	assert.Equal(t, "", <-observed)
+	assert.Equal(t, "b", <-observed)
+
+	assert.ErrorIs(t, first.Resign(ctx), ErrLost)
+	require.NoError(t, second.Resign(ctx))
+	assert.Equal(t, "", <-observed)
+}
+
+func TestElection_LostLeadership(t *testing.T) {
+	service := newFakeService(t)
+	ctx := context.Background()
+
+	l, err := New(service, "scheduler", WithTTL(30*time.Millisecond)).Campaign(ctx, "a")
+	require.NoError(t, err)
+
+	// Held well past its time to live while renewed
+	time.Sleep(100 * time.Millisecond)
+	leader, err := New(service, "scheduler").Leader(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "a", leader)
+
+	require.NoError(t, service.Revoke(ctx, l.leaseID))
+	select {
+	case <-l.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("lost leadership not noticed")
+	}
+	assert.ErrorIs(t, l.Resign(ctx), ErrLost)
+	assert.ErrorIs(t, l.Resign(ctx), ErrLost, "resigning again returns the same result")
+}
+
+func TestElection_CampaignStopsWithContext(t *testing.T) {
+	service := newFakeService(t)
+	leader, err := New(service, "scheduler").Campaign(context.Background(), "a")
+	require.NoError(t, err)
+	defer leader.Resign(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = New(service, "scheduler").Campaign(ctx, "b")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The lease of the candidate was revoked, only the leader's remains
+	service.mutex.Lock()
+	defer service.mutex.Unlock()
+	assert.Len(t, service.expiry, 1)
+}
@@ -0,0 +1,349 @@
+// Package envelope encrypts values on the client, so the key-value service and its operators only see ciphertext.
+//
+// Keys are grouped in namespaces by prefix, each with its own keyring of key encryption keys. Every value is sealed
+// with AES-256-GCM under a fresh data key, which is itself sealed (wrapped) by the current key of the namespace. The
+// stored value is an envelope holding the ID of the wrapping key, the wrapped data key and the sealed value, so keys
+// can be rotated: new values use the current key while older ones are read with the key they name. Keys are stored
+// in plaintext so scans keep working, and every value is bound to its key, so the service cannot move values between
+// keys without the client noticing.
+package envelope
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"key-value/shared/models"
+	"strings"
+)
+
+// KeySize is the size of key encryption keys in bytes, selecting AES-256
+const KeySize = 32
+
+// envelopePrefix starts every envelope, followed by the format version
+const envelopePrefix = "kvenc:1:"
+
+var (
+	// ErrUnknownKey is returned when a value was wrapped by a key missing from the keyring of its namespace
+	ErrUnknownKey = errors.New("value is encrypted with an unknown key")
+	// ErrTampered is returned when a value fails authentication: it was modified, or moved from another key
+	ErrTampered = errors.New("encrypted value was tampered with")
+	// ErrNotEncrypted is returned when a key of an encrypted namespace holds a value that is not an envelope
+	ErrNotEncrypted = errors.New("value is not encrypted")
+	// ErrUnsupported is returned for calls the wrapped client does not support
+	ErrUnsupported = errors.New("operation is not supported")
+)
+
+// Client is the part of client.KVStoreClient and client.ShardedClient that encrypted values go through
+type Client interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, kv models.KeyValue) error
+	Delete(ctx context.Context, key string) error
+	Scan(ctx context.Context, prefix string, startAfter string, limit int) ([]models.KeyValue, bool, error)
+	BatchGet(ctx context.Context, keys []string) (map[string]string, error)
+	BatchSet(ctx context.Context, items []models.KeyValue) error
+}
+
+// ConditionalClient is implemented by clients with conditional writes, such as client.KVStoreClient
+type ConditionalClient interface {
+	SetIfAbsent(ctx context.Context, kv models.KeyValue) (bool, error)
+	DeleteIfValue(ctx context.Context, key string, value string) (bool, error)
+}
+
+// Keyring holds the key encryption keys of a namespace by ID. The current key wraps new values; the others only
+// unwrap values written before a rotation.
+type Keyring struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// NewKeyring creates a keyring of 32-byte keys by ID, wrapping new values with the current one. IDs are stored in
+// plaintext with every value and may not contain ':'.
+func NewKeyring(current string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("current key %q is not in the keyring", current)
+	}
+	k := &Keyring{current: current, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid key ID %q", id)
+		}
+		if len(key) != KeySize {
+			return nil, fmt.Errorf("key %q must be %d bytes, got %d", id, KeySize, len(key))
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[id] = aead
+	}
+	return k, nil
+}
+
+// Current returns the ID of the key wrapping new values
+func (k *Keyring) Current() string {
+	return k.current
+}
+
+// EncryptingClient encrypts the values of its namespaces on the way to the wrapped client and decrypts them on the
+// way back. Values of keys outside every namespace are passed through unchanged.
+type EncryptingClient struct {
+	client     Client
+	namespaces map[string]*Keyring
+}
+
+// Option configures an EncryptingClient
+type Option func(*EncryptingClient)
+
+// WithNamespace encrypts the values of the keys starting with prefix using keyring. A key belongs to the namespace
+// with the longest matching prefix; an empty prefix encrypts every value.
+func WithNamespace(prefix string, keyring *Keyring) Option {
+	return func(c *EncryptingClient) {
+		c.namespaces[prefix] = keyring
+	}
+}
+
+// New wraps c, encrypting the values of the namespaces given as options
+func New(c Client, opts ...Option) (*EncryptingClient, error) {
+	e := &EncryptingClient{client: c, namespaces: make(map[string]*Keyring)}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if len(e.namespaces) == 0 {
+		return nil, errors.New("at least one namespace is required")
+	}
+	for prefix, keyring := range e.namespaces {
+		if keyring == nil {
+			return nil, fmt.Errorf("namespace %q has no keyring", prefix)
+		}
+	}
+	return e, nil
+}
+
+// Get retrieves and decrypts a value by key
+func (c *EncryptingClient) Get(ctx context.Context, key string) (string, bool, error) {
+	value, found, err := c.client.Get(ctx, key)
+	if err != nil || !found {
+		return value, found, err
+	}
+	value, err = c.open(key, value)
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// Set encrypts and stores a key-value pair
+func (c *EncryptingClient) Set(ctx context.Context, kv models.KeyValue) error {
+	sealed, err := c.seal(kv)
+	if err != nil {
+		return err
+	}
+	return c.client.Set(ctx, sealed)
+}
+
+// SetIfAbsent encrypts and stores a key-value pair only if the key does not exist yet
+func (c *EncryptingClient) SetIfAbsent(ctx context.Context, kv models.KeyValue) (bool, error) {
+	conditional, ok := c.client.(ConditionalClient)
+	if !ok {
+		return false, fmt.Errorf("%w: client %T has no conditional writes", ErrUnsupported, c.client)
+	}
+	sealed, err := c.seal(kv)
+	if err != nil {
+		return false, err
+	}
+	return conditional.SetIfAbsent(ctx, sealed)
+}
+
+// Delete removes a key-value pair
+func (c *EncryptingClient) Delete(ctx context.Context, key string) error {
+	return c.client.Delete(ctx, key)
+}
+
+// DeleteIfValue removes a key only if it currently decrypts to value. Sealing the same value twice gives different
+// envelopes, so the current envelope is read and deleted only if it is still the one stored.
+func (c *EncryptingClient) DeleteIfValue(ctx context.Context, key string, value string) (bool, error) {
+	conditional, ok := c.client.(ConditionalClient)
+	if !ok {
+		return false, fmt.Errorf("%w: client %T has no conditional writes", ErrUnsupported, c.client)
+	}
+	if c.keyring(key) == nil {
+		return conditional.DeleteIfValue(ctx, key, value)
+	}
+	stored, found, err := c.client.Get(ctx, key)
+	if err != nil || !found {
+		return false, err
+	}
+	current, err := c.open(key, stored)
+	if err != nil {
+		return false, err
+	}
+	if current != value {
+		return false, nil
+	}
+	return conditional.DeleteIfValue(ctx, key, stored)
+}
+
+// Scan lists up to limit keys with prefix after startAfter in order with their decrypted values
+func (c *EncryptingClient) Scan(ctx context.Context, prefix string, startAfter string, limit int) ([]models.KeyValue, bool, error) {
+	items, more, err := c.client.Scan(ctx, prefix, startAfter, limit)
+	if err != nil {
+		return nil, false, err
+	}
+	for i, item := range items {
+		if items[i].Value, err = c.open(item.Key, item.Value); err != nil {
+			return nil, false, err
+		}
+	}
+	return items, more, nil
+}
+
+// BatchGet retrieves and decrypts several values, leaving out missing keys
+func (c *EncryptingClient) BatchGet(ctx context.Context, keys []string) (map[string]string, error) {
+	values, err := c.client.BatchGet(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range values {
+		if values[key], err = c.open(key, value); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// BatchSet encrypts and stores several key-value pairs
+func (c *EncryptingClient) BatchSet(ctx context.Context, items []models.KeyValue) error {
+	sealed := make([]models.KeyValue, len(items))
+	for i, item := range items {
+		var err error
+		if sealed[i], err = c.seal(item); err != nil {
+			return err
+		}
+	}
+	return c.client.BatchSet(ctx, sealed)
+}
+
+// KeyID returns the ID of the key that wrapped a stored value, empty when it is not an envelope. Values whose key ID
+// is not the current one of their namespace can be rewritten to complete a rotation.
+func KeyID(stored string) string {
+	id, _, ok := strings.Cut(strings.TrimPrefix(stored, envelopePrefix), ":")
+	if !ok || !strings.HasPrefix(stored, envelopePrefix) {
+		return ""
+	}
+	return id
+}
+
+// keyring returns the keyring of the namespace of key, nil when it is not encrypted
+func (c *EncryptingClient) keyring(key string) *Keyring {
+	var keyring *Keyring
+	longest := -1
+	for prefix, candidate := range c.namespaces {
+		if strings.HasPrefix(key, prefix) && len(prefix) > longest {
+			keyring, longest = candidate, len(prefix)
+		}
+	}
+	return keyring
+}
+
+// seal replaces the value of kv with its envelope when the key is in a namespace
+func (c *EncryptingClient) seal(kv models.KeyValue) (models.KeyValue, error) {
+	keyring := c.keyring(kv.Key)
+	if keyring == nil {
+		return kv, nil
+	}
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return models.KeyValue{}, err
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return models.KeyValue{}, err
+	}
+	wrapped := seal(keyring.keys[keyring.current], dataKey, wrapAD(keyring.current, kv.Key))
+	sealed := seal(data, []byte(kv.Value), []byte(kv.Key))
+
+	// Values travel as strings, which must be valid UTF-8
+	kv.Value = envelopePrefix + keyring.current + ":" + base64.RawStdEncoding.EncodeToString(wrapped) + ":" +
+		base64.RawStdEncoding.EncodeToString(sealed)
+	return kv, nil
+}
+
+// open decrypts the envelope stored under key when the key is in a namespace
+func (c *EncryptingClient) open(key string, stored string) (string, error) {
+	keyring := c.keyring(key)
+	if keyring == nil {
+		return stored, nil
+	}
+	if !strings.HasPrefix(stored, envelopePrefix) {
+		return "", fmt.Errorf("key %s: %w", key, ErrNotEncrypted)
+	}
+	parts := strings.Split(strings.TrimPrefix(stored, envelopePrefix), ":")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("key %s: %w", key, ErrTampered)
+	}
+	id := parts[0]
+	wrapping, ok := keyring.keys[id]
+	if !ok {
+		return "", fmt.Errorf("key %s: %w %q", key, ErrUnknownKey, id)
+	}
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("key %s: %w", key, ErrTampered)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("key %s: %w", key, ErrTampered)
+	}
+
+	dataKey, err := open(wrapping, wrapped, wrapAD(id, key))
+	if err != nil {
+		return "", fmt.Errorf("key %s: %w", key, err)
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return "", fmt.Errorf("key %s: %w", key, ErrTampered)
+	}
+	value, err := open(data, sealed, []byte(key))
+	if err != nil {
+		return "", fmt.Errorf("key %s: %w", key, err)
+	}
+	return string(value), nil
+}
+
+// wrapAD binds a wrapped data key to the key ID and the key of the value
+func wrapAD(id string, key string) []byte {
+	return []byte(id + ":" + key)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext under a random nonce, which prefixes the result
+func seal(aead cipher.AEAD, plaintext, additionalData []byte) []byte {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(fmt.Sprintf("failed to read random nonce: %v", err))
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData)
+}
+
+// open decrypts what seal returned
+func open(aead cipher.AEAD, sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrTampered
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], additionalData)
+	if err != nil {
+		return nil, ErrTampered
+	}
+	return plaintext, nil
+}
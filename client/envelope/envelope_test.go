@@ -0,0 +1,256 @@
+package envelope
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"key-value/shared/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore is an in-memory Client with conditional writes
+type fakeStore struct {
+	data map[string]string
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{data: make(map[string]string)}
+}
+
+func (f *fakeStore) Get(_ context.Context, key string) (string, bool, error) {
+	value, ok := f.data[key]
+	return value, ok, nil
+}
+
+func (f *fakeStore) Set(_ context.Context, kv models.KeyValue) error {
+	f.data[kv.Key] = kv.Value
+	return nil
+}
+
+func (f *fakeStore) Delete(_ context.Context, key string) error {
+	delete(f.data, key)
+	return nil
+}
+
+func (f *fakeStore) Scan(_ context.Context, prefix string, startAfter string, limit int) ([]models.KeyValue, bool, error) {
+	var keys []string
+	for key := range f.data {
+		if strings.HasPrefix(key, prefix) && key > startAfter {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	more := len(keys) > limit
+	if more {
+		keys = keys[:limit]
+	}
+	items := make([]models.KeyValue, len(keys))
+	for i, key := range keys {
+		items[i] = models.KeyValue{Key: key, Value: f.data[key]}
+	}
+	return items, more, nil
+}
+
+func (f *fakeStore) BatchGet(_ context.Context, keys []string) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, key := range keys {
+		if value, ok := f.data[key]; ok {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+func (f *fakeStore) BatchSet(_ context.Context, items []models.KeyValue) error {
+	for _, item := range items {
+		f.data[item.Key] = item.Value
+	}
+	return nil
+}
+
+func (f *fakeStore) SetIfAbsent(_ context.Context, kv models.KeyValue) (bool, error) {
+	if _, ok := f.data[kv.Key]; ok {
+		return false, nil
+	}
+	f.data[kv.Key] = kv.Value
+	return true, nil
+}
+
+func (f *fakeStore) DeleteIfValue(_ context.Context, key string, value string) (bool, error) {
+	if current, ok := f.data[key]; !ok || current != value {
+		return false, nil
+	}
+	delete(f.data, key)
+	return true, nil
+}
+
+// plainStore is a Client without conditional writes
+type plainStore struct {
+	Client
+}
+
+// testKeyring creates a keyring whose key of each ID is filled with the first byte of the ID
+func testKeyring(t *testing.T, current string, ids ...string) *Keyring {
+	t.Helper()
+	keys := make(map[string][]byte)
+	for _, id := range append(ids, current) {
+		keys[id] = bytes.Repeat([]byte{id[0]}, KeySize)
+	}
+	keyring, err := NewKeyring(current, keys)
+	require.NoError(t, err)
+	return keyring
+}
+
+func newTestClient(t *testing.T, store Client, secrets *Keyring) *EncryptingClient {
+	t.Helper()
+	c, err := New(store,
+		WithNamespace("secrets/", secrets),
+		WithNamespace("secrets/team-b/", testKeyring(t, "b1")),
+	)
+	require.NoError(t, err)
+	return c
+}
+
+func TestEncryptingClient_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeStore()
+	c := newTestClient(t, store, testKeyring(t, "a1"))
+
+	require.NoError(t, c.Set(ctx, models.KeyValue{Key: "secrets/db", Value: "hunter2"}))
+	require.NoError(t, c.BatchSet(ctx, []models.KeyValue{
+		{Key: "secrets/team-b/token", Value: "t0ken"},
+		{Key: "secrets/empty", Value: ""},
+		{Key: "config/color", Value: "blue"},
+	}))
+
+	// The service sees plaintext keys, ciphertext values and the key IDs
+	assert.NotContains(t, store.data["secrets/db"], "hunter2")
+	assert.Equal(t, "a1", KeyID(store.data["secrets/db"]))
+	assert.Equal(t, "b1", KeyID(store.data["secrets/team-b/token"]), "the longest prefix picks the namespace")
+	assert.Equal(t, "blue", store.data["config/color"], "values outside namespaces are stored as is")
+	assert.Equal(t, "", KeyID(store.data["config/color"]))
+
+	value, found, err := c.Get(ctx, "secrets/db")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "hunter2", value)
+	_, found, err = c.Get(ctx, "secrets/missing")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	items, more, err := c.Scan(ctx, "secrets/", "", 10)
+	require.NoError(t, err)
+	assert.False(t, more)
+	assert.Equal(t, []models.KeyValue{
+		{Key: "secrets/db", Value: "hunter2"},
+		{Key: "secrets/empty", Value: ""},
+		{Key: "secrets/team-b/token", Value: "t0ken"},
+	}, items)
+
+	values, err := c.BatchGet(ctx, []string{"secrets/team-b/token", "config/color", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"secrets/team-b/token": "t0ken", "config/color": "blue"}, values)
+}
+
+func TestEncryptingClient_Rotation(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeStore()
+	before := newTestClient(t, store, testKeyring(t, "a1"))
+	require.NoError(t, before.Set(ctx, models.KeyValue{Key: "secrets/old", Value: "1"}))
+
+	// After rotating, new values use the new key and old ones are still read with theirs
+	after := newTestClient(t, store, testKeyring(t, "a2", "a1"))
+	require.NoError(t, after.Set(ctx, models.KeyValue{Key: "secrets/new", Value: "2"}))
+	assert.Equal(t, "a2", KeyID(store.data["secrets/new"]))
+	values, err := after.BatchGet(ctx, []string{"secrets/old", "secrets/new"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"secrets/old": "1", "secrets/new": "2"}, values)
+
+	// Once the old key is dropped, values wrapped by it cannot be read
+	dropped := newTestClient(t, store, testKeyring(t, "a2"))
+	_, _, err = dropped.Get(ctx, "secrets/old")
+	assert.ErrorIs(t, err, ErrUnknownKey)
+}
+
+func TestEncryptingClient_Tampered(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeStore()
+	c := newTestClient(t, store, testKeyring(t, "a1"))
+	require.NoError(t, c.Set(ctx, models.KeyValue{Key: "secrets/a", Value: "1"}))
+	require.NoError(t, c.Set(ctx, models.KeyValue{Key: "secrets/b", Value: "2"}))
+	envelope := store.data["secrets/a"]
+
+	tests := []struct {
+		name   string
+		stored string
+		err    error
+	}{
+		{"moved from another key", store.data["secrets/b"], ErrTampered},
+		{"changed ciphertext", envelope[:len(envelope)-2] + "AA", ErrTampered},
+		{"missing part", envelope[:strings.LastIndex(envelope, ":")], ErrTampered},
+		{"not base64", envelope + "!", ErrTampered},
+		{"plaintext", "1", ErrNotEncrypted},
+		{"unknown key", strings.Replace(envelope, ":a1:", ":zz:", 1), ErrUnknownKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store.data["secrets/a"] = tt.stored
+			_, _, err := c.Get(ctx, "secrets/a")
+			assert.ErrorIs(t, err, tt.err)
+			_, _, err = c.Scan(ctx, "secrets/", "", 10)
+			assert.ErrorIs(t, err, tt.err)
+		})
+	}
+}
+
+func TestEncryptingClient_ConditionalWrites(t *testing.T) {
+	ctx := context.Background()
+	store := newFakeStore()
+	c := newTestClient(t, store, testKeyring(t, "a1"))
+
+	stored, err := c.SetIfAbsent(ctx, models.KeyValue{Key: "secrets/a", Value: "1"})
+	require.NoError(t, err)
+	assert.True(t, stored)
+	stored, err = c.SetIfAbsent(ctx, models.KeyValue{Key: "secrets/a", Value: "2"})
+	require.NoError(t, err)
+	assert.False(t, stored)
+
+	// Values are compared after decrypting, since every envelope of a value differs
+	deleted, err := c.DeleteIfValue(ctx, "secrets/a", "2")
+	require.NoError(t, err)
+	assert.False(t, deleted)
+	deleted, err = c.DeleteIfValue(ctx, "secrets/a", "1")
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	assert.Empty(t, store.data)
+
+	plain := newTestClient(t, plainStore{store}, testKeyring(t, "a1"))
+	_, err = plain.SetIfAbsent(ctx, models.KeyValue{Key: "secrets/a", Value: "1"})
+	assert.ErrorIs(t, err, ErrUnsupported)
+	_, err = plain.DeleteIfValue(ctx, "secrets/a", "1")
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestNew_Validation(t *testing.T) {
+	_, err := New(newFakeStore())
+	assert.Error(t, err, "a namespace is required")
+	_, err = New(newFakeStore(), WithNamespace("secrets/", nil))
+	assert.Error(t, err)
+
+	key := bytes.Repeat([]byte{1}, KeySize)
+	for name, build := range map[string]func() (*Keyring, error){
+		"missing current": func() (*Keyring, error) { return NewKeyring("a", map[string][]byte{"b": key}) },
+		"short key":       func() (*Keyring, error) { return NewKeyring("a", map[string][]byte{"a": key[:16]}) },
+		"colon in ID":     func() (*Keyring, error) { return NewKeyring("a:b", map[string][]byte{"a:b": key}) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := build()
+			assert.Error(t, err)
+		})
+	}
+}
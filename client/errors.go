@@ -0,0 +1,111 @@
+package client
+
+import (
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Sentinel errors returned by KVStoreClient. Compare with errors.Is; the original
+// gRPC status is kept in the chain and can still be read with status.FromError.
+var (
+	// ErrNotFound is returned when the key does not exist
+	ErrNotFound = errors.New("key not found")
+	// ErrConflict is returned when a write lost against a concurrent modification
+	ErrConflict = errors.New("conflicting modification")
+	// ErrTooLarge is returned when a key or value exceeds the service limits
+	ErrTooLarge = errors.New("too large")
+	// ErrInvalidKey is returned when the service rejected the key
+	ErrInvalidKey = errors.New("invalid key")
+	// ErrUnavailable is returned when the service could not be reached or timed out
+	ErrUnavailable = errors.New("service unavailable")
+	// ErrNotNumeric is returned when incrementing a value that is not an integer
+	ErrNotNumeric = errors.New("value is not an integer")
+	// ErrOverflow is returned when an increment would overflow an int64
+	ErrOverflow = errors.New("integer overflow")
+	// ErrNotLeader is returned when a replicated service could not route the request to its leader
+	ErrNotLeader = errors.New("not the leader")
+	// ErrQuorumNotReached is returned when too few replicas answered a quorum read or write
+	ErrQuorumNotReached = errors.New("quorum not reached")
+	// ErrChangesExpired is returned when the requested changes fell out of the service's retention window
+	ErrChangesExpired = errors.New("changes are no longer retained")
+	// ErrCompacted is returned when the requested revision of a key is no longer retained
+	ErrCompacted = errors.New("revision has been compacted")
+	// ErrFutureRevision is returned when the requested revision is past the latest revision of the service
+	ErrFutureRevision = errors.New("revision is in the future")
+	// ErrLeaseNotFound is returned when a lease expired, was revoked or never existed
+	ErrLeaseNotFound = errors.New("lease not found")
+	// ErrWatchLost is returned when a watch missed changes of its key; watch again to continue from the current value
+	ErrWatchLost = errors.New("watch lost events")
+	// ErrInvalidBackup is returned when restoring a file that is not a backup, is damaged or has an unsupported version
+	ErrInvalidBackup = errors.New("invalid backup")
+)
+
+// errorDomain matches the ErrorInfo domain set by the key-value service
+const errorDomain = "key-value"
+
+// reasonErrors maps the ErrorInfo reasons sent by the key-value service to sentinel errors
+var reasonErrors = map[string]error{
+	"NOT_FOUND":       ErrNotFound,
+	"CONFLICT":        ErrConflict,
+	"TOO_LARGE":       ErrTooLarge,
+	"INVALID_KEY":     ErrInvalidKey,
+	"NOT_NUMERIC":     ErrNotNumeric,
+	"OVERFLOW":        ErrOverflow,
+	"NOT_LEADER":      ErrNotLeader,
+	"EXPIRED":         ErrChangesExpired,
+	"COMPACTED":       ErrCompacted,
+	"FUTURE_REVISION": ErrFutureRevision,
+	"LEASE_NOT_FOUND": ErrLeaseNotFound,
+	"WATCH_LOST":      ErrWatchLost,
+	"INVALID_BACKUP":  ErrInvalidBackup,
+}
+
+// codeErrors is the fallback used when the status has no ErrorInfo (older servers, transport errors)
+var codeErrors = map[codes.Code]error{
+	codes.NotFound:          ErrNotFound,
+	codes.Aborted:           ErrConflict,
+	codes.AlreadyExists:     ErrConflict,
+	codes.ResourceExhausted: ErrTooLarge,
+	codes.InvalidArgument:   ErrInvalidKey,
+	codes.Unavailable:       ErrUnavailable,
+	codes.DeadlineExceeded:  ErrUnavailable,
+	codes.OutOfRange:        ErrOverflow,
+}
+
+// typedError carries both a sentinel error and the original gRPC error
+type typedError struct {
+	sentinel error
+	cause    error
+}
+
+func (e *typedError) Error() string {
+	return e.cause.Error()
+}
+
+func (e *typedError) Unwrap() []error {
+	return []error{e.sentinel, e.cause}
+}
+
+// translateError converts a gRPC error into one matching the package sentinel errors
+func translateError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == errorDomain {
+			if sentinel, ok := reasonErrors[info.Reason]; ok {
+				return &typedError{sentinel: sentinel, cause: err}
+			}
+		}
+	}
+
+	if sentinel, ok := codeErrors[st.Code()]; ok {
+		return &typedError{sentinel: sentinel, cause: err}
+	}
+	return err
+}
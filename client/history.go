@@ -0,0 +1,86 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"key-value/proto/keyvalue"
+	"time"
+)
+
+// Revision is the value of a key as of a revision of the key-value service
+type Revision struct {
+	Revision uint64
+	Value    string
+	Deleted  bool // the key was deleted at this revision
+	Time     time.Time
+}
+
+// KeyHistory is the retained revisions of a key, newest first
+type KeyHistory struct {
+	Revisions []Revision
+	Compacted uint64 // revisions before this one are no longer retained for any key
+	Current   uint64 // latest revision of the service
+}
+
+// GetAtRevision returns the value key held at a revision of the service, 0 reads the latest. It fails with
+// ErrNotFound when the key did not exist then and ErrCompacted once that revision is no longer retained.
+// Each key-value service numbers its own revisions, so only use revisions read from the same service.
+func (c *KVStoreClient) GetAtRevision(ctx context.Context, key string, revision uint64) (Revision, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := keyvalue.NewHistoryServiceClient(c.conn).GetAtRevision(ctx, &keyvalue.GetAtRevisionRequest{Key: key, Revision: revision})
+	if err != nil {
+		return Revision{}, fmt.Errorf("failed to get %s at revision %d: %w", key, revision, translateError(err))
+	}
+	return fromKeyRevision(resp), nil
+}
+
+// GetAtTime returns the value key held at t, failing like GetAtRevision
+func (c *KVStoreClient) GetAtTime(ctx context.Context, key string, t time.Time) (Revision, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := keyvalue.NewHistoryServiceClient(c.conn).GetAtTime(ctx, &keyvalue.GetAtTimeRequest{Key: key, TimestampMillis: t.UnixMilli()})
+	if err != nil {
+		return Revision{}, fmt.Errorf("failed to get %s at %s: %w", key, t.Format(time.RFC3339), translateError(err))
+	}
+	return fromKeyRevision(resp), nil
+}
+
+// History returns the retained revisions of key, newest first and including deletions
+func (c *KVStoreClient) History(ctx context.Context, key string) (KeyHistory, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := keyvalue.NewHistoryServiceClient(c.conn).History(ctx, &keyvalue.HistoryRequest{Key: key})
+	if err != nil {
+		return KeyHistory{}, fmt.Errorf("failed to get history of %s: %w", key, translateError(err))
+	}
+	history := KeyHistory{Compacted: resp.CompactedRevision, Current: resp.CurrentRevision}
+	for _, revision := range resp.Revisions {
+		history.Revisions = append(history.Revisions, fromKeyRevision(revision))
+	}
+	return history, nil
+}
+
+// Compact drops the revisions replaced before a revision of the service and returns how many were dropped
+func (c *KVStoreClient) Compact(ctx context.Context, revision uint64) (int, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := keyvalue.NewHistoryServiceClient(c.conn).Compact(ctx, &keyvalue.CompactRequest{Revision: revision})
+	if err != nil {
+		return 0, fmt.Errorf("failed to compact at revision %d: %w", revision, translateError(err))
+	}
+	return int(resp.Removed), nil
+}
+
+func fromKeyRevision(revision *keyvalue.KeyRevision) Revision {
+	return Revision{
+		Revision: revision.Revision,
+		Value:    revision.Value,
+		Deleted:  revision.Deleted,
+		Time:     time.UnixMilli(revision.TimestampMillis),
+	}
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"key-value/proto/keyvalue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// revisionLog is an in-process history service holding the revisions of one key, oldest first
+type revisionLog struct {
+	keyvalue.UnimplementedHistoryServiceServer
+	revisions []*keyvalue.KeyRevision
+}
+
+func (l *revisionLog) GetAtRevision(ctx context.Context, req *keyvalue.GetAtRevisionRequest) (*keyvalue.KeyRevision, error) {
+	return l.revisions[req.Revision-1], nil
+}
+
+func (l *revisionLog) History(ctx context.Context, req *keyvalue.HistoryRequest) (*keyvalue.HistoryResponse, error) {
+	resp := &keyvalue.HistoryResponse{CurrentRevision: uint64(len(l.revisions))}
+	for i := len(l.revisions) - 1; i >= 0; i-- {
+		resp.Revisions = append(resp.Revisions, l.revisions[i])
+	}
+	return resp, nil
+}
+
+func TestKVStoreClient_History(t *testing.T) {
+	written := time.UnixMilli(1700000000000)
+	log := &revisionLog{revisions: []*keyvalue.KeyRevision{
+		{Revision: 1, Value: "v1", TimestampMillis: written.UnixMilli()},
+		{Revision: 2, Deleted: true, TimestampMillis: written.UnixMilli()},
+	}}
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	keyvalue.RegisterHistoryServiceServer(server, log)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	client, err := NewKVStoreClient(lis.Addr().String())
+	require.NoError(t, err)
+	defer client.Close()
+
+	revision, err := client.GetAtRevision(context.Background(), "config", 1)
+	require.NoError(t, err)
+	assert.Equal(t, Revision{Revision: 1, Value: "v1", Time: written}, revision)
+
+	history, err := client.History(context.Background(), "config")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), history.Current)
+	assert.Equal(t, []Revision{
+		{Revision: 2, Deleted: true, Time: written},
+		{Revision: 1, Value: "v1", Time: written},
+	}, history.Revisions)
+}
@@ -0,0 +1,72 @@
+// Package keepalive renews a lease in the background for the lock and election packages
+package keepalive
+
+import (
+	"context"
+	"errors"
+	"key-value/client"
+	"time"
+)
+
+// KeepAliver restarts the time to live of a lease
+type KeepAliver interface {
+	KeepAlive(ctx context.Context, id int64) (client.Lease, error)
+}
+
+// Renewal keeps a lease alive every third of its time to live until stopped
+type Renewal struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	lost   chan struct{} // closed when the lease could not be renewed
+}
+
+// Start renews lease id of time to live ttl through c
+func Start(c KeepAliver, id int64, ttl time.Duration) *Renewal {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &Renewal{cancel: cancel, done: make(chan struct{}), lost: make(chan struct{})}
+	go r.run(ctx, c, id, ttl)
+	return r
+}
+
+// Lost returns a channel closed when the lease is gone or could not be renewed for a whole time to live
+func (r *Renewal) Lost() <-chan struct{} {
+	return r.lost
+}
+
+// Stop stops renewing and waits for the renewal in flight. It reports whether the lease was lost before.
+func (r *Renewal) Stop() (lost bool) {
+	r.cancel()
+	<-r.done
+	select {
+	case <-r.lost:
+		return true
+	default:
+		return false
+	}
+}
+
+func (r *Renewal) run(ctx context.Context, c KeepAliver, id int64, ttl time.Duration) {
+	defer close(r.done)
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+
+	renewed := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		_, err := c.KeepAlive(ctx, id)
+		switch {
+		case err == nil:
+			renewed = time.Now()
+		case ctx.Err() != nil:
+			return
+		case errors.Is(err, client.ErrLeaseNotFound) || time.Since(renewed) >= ttl:
+			close(r.lost)
+			return
+		}
+	}
+}
@@ -2,51 +2,84 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"key-value/proto/keyvalue"
 	"key-value/shared/models"
+	"time"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 // KVStoreClient wraps the gRPC client for the key-value service
 type KVStoreClient struct {
-	client keyvalue.KeyValueServiceClient
-	conn   *grpc.ClientConn
-	addr   string
+	client          keyvalue.KeyValueServiceClient
+	conn            *grpc.ClientConn
+	addr            string
+	defaultTimeout  time.Duration
+	readConsistency keyvalue.ReadConsistency
+	redirects       *leaderRedirects
+	members         *MemberWatcher
 }
 
-// NewKVStoreClient creates a new client connection to the key-value service
-func NewKVStoreClient(address string) (*KVStoreClient, error) {
-	// Set up connection options
-	options := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+// NewKVStoreClient creates a new client connection to the key-value service.
+// The address may be a single host:port, a dns:/// name resolving to several replicas,
+// or a comma separated list of replicas to balance across.
+// Without options the connection is plaintext with no default timeout.
+// Requests rejected by a follower are retried once against the leader or primary it names.
+func NewKVStoreClient(address string, opts ...Option) (*KVStoreClient, error) {
+	options := newClientOptions(opts...)
+
+	var members *MemberWatcher
+	if options.memberDiscovery {
+		watcher, err := WatchMembers(parseEndpoints(address), opts...)
+		if err != nil {
+			return nil, err
+		}
+		members = watcher
+	}
+
+	target, balancerOptions, err := resolveTarget(address, options, members)
+	if err != nil {
+		closeWatcher(members)
+		return nil, err
 	}
 
-	conn, err := grpc.NewClient(address, options...) // Use WithInsecure for development, use credentials for production
+	conn, err := grpc.NewClient(target, append(balancerOptions, options.dialOptions()...)...)
 	if err != nil {
+		closeWatcher(members)
 		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
 	}
 
 	client := keyvalue.NewKeyValueServiceClient(conn)
 
 	return &KVStoreClient{
-		client: client,
-		conn:   conn,
-		addr:   address,
+		client:          client,
+		conn:            conn,
+		addr:            address,
+		defaultTimeout:  options.defaultTimeout,
+		readConsistency: options.readConsistency,
+		redirects:       newLeaderRedirects(options.dialOptions()),
+		members:         members,
 	}, nil
 }
 
 // Get retrieves a value by key
 func (c *KVStoreClient) Get(ctx context.Context, key string) (string, bool, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	req := &keyvalue.GetRequest{
-		Key: key,
+		Key:         key,
+		Consistency: c.readConsistency,
 	}
 
 	resp, err := c.client.Get(ctx, req)
+	if leader, ok := c.redirects.client(err); ok {
+		resp, err = leader.Get(ctx, req)
+	}
 	if err != nil {
-		return "", false, fmt.Errorf("failed to get key %s: %w", key, err)
+		return "", false, fmt.Errorf("failed to get key %s: %w", key, translateError(err))
 	}
 
 	return resp.Value, resp.Found, nil
@@ -54,14 +87,29 @@ func (c *KVStoreClient) Get(ctx context.Context, key string) (string, bool, erro
 
 // Set stores a key-value pair
 func (c *KVStoreClient) Set(ctx context.Context, kv models.KeyValue) error {
-	req := &keyvalue.SetRequest{
-		Key:   kv.Key,
-		Value: kv.Value,
+	return c.set(ctx, &keyvalue.SetRequest{Key: kv.Key, Value: kv.Value})
+}
+
+// SetIfAbsent stores a key-value pair only if the key does not exist yet.
+// It reports false without changing anything when the key already exists.
+func (c *KVStoreClient) SetIfAbsent(ctx context.Context, kv models.KeyValue) (bool, error) {
+	err := c.set(ctx, &keyvalue.SetRequest{Key: kv.Key, Value: kv.Value, IfAbsent: true})
+	if errors.Is(err, ErrConflict) {
+		return false, nil
 	}
+	return err == nil, err
+}
+
+func (c *KVStoreClient) set(ctx context.Context, req *keyvalue.SetRequest) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
 
 	resp, err := c.client.Set(ctx, req)
+	if leader, ok := c.redirects.client(err); ok {
+		resp, err = leader.Set(ctx, req)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to set key %s: %w", kv.Key, err)
+		return fmt.Errorf("failed to set key %s: %w", req.Key, translateError(err))
 	}
 
 	if !resp.Success {
@@ -73,13 +121,29 @@ func (c *KVStoreClient) Set(ctx context.Context, kv models.KeyValue) error {
 
 // Delete removes a key-value pair
 func (c *KVStoreClient) Delete(ctx context.Context, key string) error {
-	req := &keyvalue.DeleteRequest{
-		Key: key,
+	return c.delete(ctx, &keyvalue.DeleteRequest{Key: key})
+}
+
+// DeleteIfValue removes a key only if it currently holds value.
+// It reports false without changing anything when the key is missing or holds another value.
+func (c *KVStoreClient) DeleteIfValue(ctx context.Context, key string, value string) (bool, error) {
+	err := c.delete(ctx, &keyvalue.DeleteRequest{Key: key, IfValue: &value})
+	if errors.Is(err, ErrConflict) {
+		return false, nil
 	}
+	return err == nil, err
+}
+
+func (c *KVStoreClient) delete(ctx context.Context, req *keyvalue.DeleteRequest) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
 
 	resp, err := c.client.Delete(ctx, req)
+	if leader, ok := c.redirects.client(err); ok {
+		resp, err = leader.Delete(ctx, req)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to delete key %s: %w", key, err)
+		return fmt.Errorf("failed to delete key %s: %w", req.Key, translateError(err))
 	}
 
 	if !resp.Success {
@@ -89,13 +153,107 @@ func (c *KVStoreClient) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// Increment atomically adds delta to the integer stored at key and returns the new value.
+// A missing key starts at 0; a non numeric value returns ErrNotNumeric.
+func (c *KVStoreClient) Increment(ctx context.Context, key string, delta int64) (int64, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	req := &keyvalue.IncrementRequest{
+		Key:   key,
+		Delta: delta,
+	}
+
+	resp, err := c.client.Increment(ctx, req)
+	if leader, ok := c.redirects.client(err); ok {
+		resp, err = leader.Increment(ctx, req)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment key %s: %w", key, translateError(err))
+	}
+
+	return resp.Value, nil
+}
+
+// Scan lists up to limit key-value pairs in key order whose key starts with prefix and sorts after startAfter.
+// more reports whether further pairs match; resume with startAfter set to the last returned key.
+// A limit of 0 uses the service default.
+func (c *KVStoreClient) Scan(ctx context.Context, prefix string, startAfter string, limit int) ([]models.KeyValue, bool, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	req := &keyvalue.ScanRequest{
+		Prefix:     prefix,
+		StartAfter: startAfter,
+		Limit:      int32(limit),
+	}
+
+	resp, err := c.client.Scan(ctx, req)
+	if leader, ok := c.redirects.client(err); ok {
+		resp, err = leader.Scan(ctx, req)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to scan prefix %s: %w", prefix, translateError(err))
+	}
+
+	return fromPairs(resp.Items), resp.More, nil
+}
+
+// BatchGet retrieves several keys at once. Missing keys are left out of the returned map.
+func (c *KVStoreClient) BatchGet(ctx context.Context, keys []string) (map[string]string, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	req := &keyvalue.BatchGetRequest{
+		Keys: keys,
+	}
+
+	resp, err := c.client.BatchGet(ctx, req)
+	if leader, ok := c.redirects.client(err); ok {
+		resp, err = leader.BatchGet(ctx, req)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %d keys: %w", len(keys), translateError(err))
+	}
+
+	values := make(map[string]string, len(resp.Items))
+	for _, item := range resp.Items {
+		values[item.Key] = item.Value
+	}
+	return values, nil
+}
+
+// BatchSet stores several key-value pairs. The batch is validated as a whole, but a failure
+// while writing can leave the pairs before the failing one written.
+func (c *KVStoreClient) BatchSet(ctx context.Context, items []models.KeyValue) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	req := &keyvalue.BatchSetRequest{
+		Items: toPairs(items),
+	}
+
+	_, err := c.client.BatchSet(ctx, req)
+	if leader, ok := c.redirects.client(err); ok {
+		_, err = leader.BatchSet(ctx, req)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set %d keys: %w", len(items), translateError(err))
+	}
+
+	return nil
+}
+
 // Health provides a health check endpoint
 func (c *KVStoreClient) Health(ctx context.Context) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
 	req := &keyvalue.HealthRequest{}
 
 	resp, err := c.client.Health(ctx, req)
 	if err != nil {
-		return fmt.Errorf("health check failed: %w", err)
+		return fmt.Errorf("health check failed: %w", translateError(err))
 	}
 
 	if resp.Status != "healthy" {
@@ -105,6 +263,23 @@ func (c *KVStoreClient) Health(ctx context.Context) error {
 	return nil
 }
 
+// Close closes the underlying connection and any connection opened to follow a redirect
 func (c *KVStoreClient) Close() error {
-	return c.conn.Close()
+	return errors.Join(c.conn.Close(), c.redirects.Close(), closeWatcher(c.members))
+}
+
+func toPairs(items []models.KeyValue) []*keyvalue.KeyValuePair {
+	pairs := make([]*keyvalue.KeyValuePair, len(items))
+	for i, item := range items {
+		pairs[i] = &keyvalue.KeyValuePair{Key: item.Key, Value: item.Value}
+	}
+	return pairs
+}
+
+func fromPairs(pairs []*keyvalue.KeyValuePair) []models.KeyValue {
+	items := make([]models.KeyValue, len(pairs))
+	for i, pair := range pairs {
+		items[i] = models.KeyValue{Key: pair.Key, Value: pair.Value}
+	}
+	return items
 }
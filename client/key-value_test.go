@@ -9,6 +9,7 @@ import (
 	"key-value/shared/models"
 
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -16,10 +17,14 @@ import (
 
 // MockKeyValueServiceClient implements the gRPC client interface for testing
 type MockKeyValueServiceClient struct {
-	GetFunc    func(ctx context.Context, in *keyvalue.GetRequest, opts ...grpc.CallOption) (*keyvalue.GetResponse, error)
-	SetFunc    func(ctx context.Context, in *keyvalue.SetRequest, opts ...grpc.CallOption) (*keyvalue.SetResponse, error)
-	DeleteFunc func(ctx context.Context, in *keyvalue.DeleteRequest, opts ...grpc.CallOption) (*keyvalue.DeleteResponse, error)
-	HealthFunc func(ctx context.Context, in *keyvalue.HealthRequest, opts ...grpc.CallOption) (*keyvalue.HealthResponse, error)
+	GetFunc       func(ctx context.Context, in *keyvalue.GetRequest, opts ...grpc.CallOption) (*keyvalue.GetResponse, error)
+	SetFunc       func(ctx context.Context, in *keyvalue.SetRequest, opts ...grpc.CallOption) (*keyvalue.SetResponse, error)
+	DeleteFunc    func(ctx context.Context, in *keyvalue.DeleteRequest, opts ...grpc.CallOption) (*keyvalue.DeleteResponse, error)
+	HealthFunc    func(ctx context.Context, in *keyvalue.HealthRequest, opts ...grpc.CallOption) (*keyvalue.HealthResponse, error)
+	IncrementFunc func(ctx context.Context, in *keyvalue.IncrementRequest, opts ...grpc.CallOption) (*keyvalue.IncrementResponse, error)
+	ScanFunc      func(ctx context.Context, in *keyvalue.ScanRequest, opts ...grpc.CallOption) (*keyvalue.ScanResponse, error)
+	BatchGetFunc  func(ctx context.Context, in *keyvalue.BatchGetRequest, opts ...grpc.CallOption) (*keyvalue.BatchGetResponse, error)
+	BatchSetFunc  func(ctx context.Context, in *keyvalue.BatchSetRequest, opts ...grpc.CallOption) (*keyvalue.BatchSetResponse, error)
 }
 
 func (m *MockKeyValueServiceClient) Get(ctx context.Context, in *keyvalue.GetRequest, opts ...grpc.CallOption) (*keyvalue.GetResponse, error) {
@@ -50,6 +55,34 @@ func (m *MockKeyValueServiceClient) Health(ctx context.Context, in *keyvalue.Hea
 	return &keyvalue.HealthResponse{Status: "healthy", Timestamp: time.Now().Unix()}, nil
 }
 
+func (m *MockKeyValueServiceClient) Increment(ctx context.Context, in *keyvalue.IncrementRequest, opts ...grpc.CallOption) (*keyvalue.IncrementResponse, error) {
+	if m.IncrementFunc != nil {
+		return m.IncrementFunc(ctx, in, opts...)
+	}
+	return &keyvalue.IncrementResponse{Value: in.Delta}, nil
+}
+
+func (m *MockKeyValueServiceClient) Scan(ctx context.Context, in *keyvalue.ScanRequest, opts ...grpc.CallOption) (*keyvalue.ScanResponse, error) {
+	if m.ScanFunc != nil {
+		return m.ScanFunc(ctx, in, opts...)
+	}
+	return &keyvalue.ScanResponse{}, nil
+}
+
+func (m *MockKeyValueServiceClient) BatchGet(ctx context.Context, in *keyvalue.BatchGetRequest, opts ...grpc.CallOption) (*keyvalue.BatchGetResponse, error) {
+	if m.BatchGetFunc != nil {
+		return m.BatchGetFunc(ctx, in, opts...)
+	}
+	return &keyvalue.BatchGetResponse{}, nil
+}
+
+func (m *MockKeyValueServiceClient) BatchSet(ctx context.Context, in *keyvalue.BatchSetRequest, opts ...grpc.CallOption) (*keyvalue.BatchSetResponse, error) {
+	if m.BatchSetFunc != nil {
+		return m.BatchSetFunc(ctx, in, opts...)
+	}
+	return &keyvalue.BatchSetResponse{}, nil
+}
+
 func TestKVStoreClient_Get(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -267,6 +300,68 @@ func TestKVStoreClient_Delete(t *testing.T) {
 	}
 }
 
+func TestKVStoreClient_Increment(t *testing.T) {
+	tests := []struct {
+		name          string
+		key           string
+		delta         int64
+		setupMock     func(*MockKeyValueServiceClient)
+		expectedValue int64
+		expectedErr   error
+	}{
+		{
+			name:  "successful increment",
+			key:   "counter",
+			delta: 2,
+			setupMock: func(m *MockKeyValueServiceClient) {
+				m.IncrementFunc = func(ctx context.Context, in *keyvalue.IncrementRequest, opts ...grpc.CallOption) (*keyvalue.IncrementResponse, error) {
+					return &keyvalue.IncrementResponse{Value: 40 + in.Delta}, nil
+				}
+			},
+			expectedValue: 42,
+		},
+		{
+			name:  "non numeric value",
+			key:   "text",
+			delta: 1,
+			setupMock: func(m *MockKeyValueServiceClient) {
+				m.IncrementFunc = func(ctx context.Context, in *keyvalue.IncrementRequest, opts ...grpc.CallOption) (*keyvalue.IncrementResponse, error) {
+					st, _ := status.New(codes.FailedPrecondition, "value is not an integer").WithDetails(&errdetails.ErrorInfo{
+						Reason: "NOT_NUMERIC",
+						Domain: errorDomain,
+					})
+					return nil, st.Err()
+				}
+			},
+			expectedErr: ErrNotNumeric,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockKeyValueServiceClient{}
+			tt.setupMock(mockClient)
+
+			// Create client with mock
+			client := &KVStoreClient{
+				client: mockClient,
+				addr:   "mock-address",
+			}
+
+			ctx := context.Background()
+			value, err := client.Increment(ctx, tt.key, tt.delta)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Contains(t, err.Error(), "failed to increment key "+tt.key)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedValue, value)
+			}
+		})
+	}
+}
+
 func TestKVStoreClient_Health(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -333,3 +428,106 @@ func TestKVStoreClient_Health(t *testing.T) {
 		})
 	}
 }
+
+func TestKVStoreClient_TypedErrors(t *testing.T) {
+	withReason := func(code codes.Code, reason string) error {
+		st, err := status.New(code, "rejected").WithDetails(&errdetails.ErrorInfo{
+			Reason: reason,
+			Domain: errorDomain,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return st.Err()
+	}
+
+	tests := []struct {
+		name        string
+		grpcErr     error
+		expectedErr error
+		expectCode  codes.Code
+	}{
+		{"error info reason", withReason(codes.ResourceExhausted, "TOO_LARGE"), ErrTooLarge, codes.ResourceExhausted},
+		{"conflict reason", withReason(codes.Aborted, "CONFLICT"), ErrConflict, codes.Aborted},
+		{"not leader reason", withReason(codes.FailedPrecondition, "NOT_LEADER"), ErrNotLeader, codes.FailedPrecondition},
+		{"compacted reason", withReason(codes.OutOfRange, "COMPACTED"), ErrCompacted, codes.OutOfRange},
+		{"watch lost reason", withReason(codes.Aborted, "WATCH_LOST"), ErrWatchLost, codes.Aborted},
+		{"code fallback", status.Error(codes.InvalidArgument, "bad key"), ErrInvalidKey, codes.InvalidArgument},
+		{"unavailable", status.Error(codes.Unavailable, "connection refused"), ErrUnavailable, codes.Unavailable},
+		{"deadline", status.Error(codes.DeadlineExceeded, "too slow"), ErrUnavailable, codes.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockKeyValueServiceClient{
+				SetFunc: func(ctx context.Context, in *keyvalue.SetRequest, opts ...grpc.CallOption) (*keyvalue.SetResponse, error) {
+					return nil, tt.grpcErr
+				},
+			}
+			client := &KVStoreClient{
+				client: mockClient,
+				addr:   "mock-address",
+			}
+
+			err := client.Set(context.Background(), models.KeyValue{Key: "k", Value: "v"})
+			assert.ErrorIs(t, err, tt.expectedErr)
+
+			// The gRPC status stays reachable for callers that need it
+			st, ok := status.FromError(err)
+			assert.True(t, ok)
+			assert.Equal(t, tt.expectCode, st.Code())
+		})
+	}
+}
+
+func TestKVStoreClient_ReadConsistency(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		expected keyvalue.ReadConsistency
+	}{
+		{"server default", nil, keyvalue.ReadConsistency_READ_CONSISTENCY_DEFAULT},
+		{"linearizable", []Option{WithReadConsistency(Linearizable)}, keyvalue.ReadConsistency_READ_CONSISTENCY_LINEARIZABLE},
+		{"stale", []Option{WithReadConsistency(Stale)}, keyvalue.ReadConsistency_READ_CONSISTENCY_STALE},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requested keyvalue.ReadConsistency
+			mockClient := &MockKeyValueServiceClient{
+				GetFunc: func(ctx context.Context, in *keyvalue.GetRequest, opts ...grpc.CallOption) (*keyvalue.GetResponse, error) {
+					requested = in.Consistency
+					return &keyvalue.GetResponse{Value: "v", Found: true}, nil
+				},
+			}
+			options := newClientOptions(tt.opts...)
+			client := &KVStoreClient{
+				client:          mockClient,
+				addr:            "mock-address",
+				readConsistency: options.readConsistency,
+			}
+
+			_, _, err := client.Get(context.Background(), "k")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, requested)
+		})
+	}
+}
+
+func TestKVStoreClient_UntypedErrorsPassThrough(t *testing.T) {
+	mockClient := &MockKeyValueServiceClient{
+		DeleteFunc: func(ctx context.Context, in *keyvalue.DeleteRequest, opts ...grpc.CallOption) (*keyvalue.DeleteResponse, error) {
+			return nil, status.Error(codes.Internal, "boom")
+		},
+	}
+	client := &KVStoreClient{
+		client: mockClient,
+		addr:   "mock-address",
+	}
+
+	err := client.Delete(context.Background(), "k")
+	assert.Error(t, err)
+	for _, sentinel := range []error{ErrNotFound, ErrConflict, ErrTooLarge, ErrInvalidKey, ErrUnavailable} {
+		assert.NotErrorIs(t, err, sentinel)
+	}
+}
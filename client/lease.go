@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"key-value/proto/keyvalue"
+	"time"
+)
+
+// Lease is a time limited lease granted by the key-value service. Keys attached to it are deleted when it
+// expires or is revoked.
+type Lease struct {
+	ID        int64
+	TTL       time.Duration
+	Remaining time.Duration // time left before the lease expires unless kept alive
+	Keys      []string
+}
+
+// Acquisition is the outcome of Acquire
+type Acquisition struct {
+	Acquired     bool
+	FencingToken uint64 // larger than every token handed out before, set when acquired
+	Holder       string // value of the key
+}
+
+// Grant creates a lease expiring after ttl unless kept alive.
+// Leases live in the service that granted them, so use them with a single service or the Raft leader.
+func (c *KVStoreClient) Grant(ctx context.Context, ttl time.Duration) (Lease, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := keyvalue.NewLeaseServiceClient(c.conn).Grant(ctx, &keyvalue.LeaseGrantRequest{TtlMillis: ttl.Milliseconds()})
+	if err != nil {
+		return Lease{}, fmt.Errorf("failed to grant lease: %w", translateError(err))
+	}
+	return fromLeaseResponse(resp), nil
+}
+
+// KeepAlive restarts the time to live of a lease, failing with ErrLeaseNotFound once it expired
+func (c *KVStoreClient) KeepAlive(ctx context.Context, id int64) (Lease, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := keyvalue.NewLeaseServiceClient(c.conn).KeepAlive(ctx, &keyvalue.LeaseKeepAliveRequest{Id: id})
+	if err != nil {
+		return Lease{}, fmt.Errorf("failed to keep lease %d alive: %w", id, translateError(err))
+	}
+	return fromLeaseResponse(resp), nil
+}
+
+// Revoke ends a lease right away, deleting its keys
+func (c *KVStoreClient) Revoke(ctx context.Context, id int64) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := keyvalue.NewLeaseServiceClient(c.conn).Revoke(ctx, &keyvalue.LeaseRevokeRequest{Id: id}); err != nil {
+		return fmt.Errorf("failed to revoke lease %d: %w", id, translateError(err))
+	}
+	return nil
+}
+
+// TimeToLive describes a lease without keeping it alive
+func (c *KVStoreClient) TimeToLive(ctx context.Context, id int64) (Lease, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := keyvalue.NewLeaseServiceClient(c.conn).TimeToLive(ctx, &keyvalue.LeaseTimeToLiveRequest{Id: id})
+	if err != nil {
+		return Lease{}, fmt.Errorf("failed to describe lease %d: %w", id, translateError(err))
+	}
+	return fromLeaseResponse(resp), nil
+}
+
+// SetWithLease stores a key-value pair attached to a lease, so it is deleted when the lease ends
+func (c *KVStoreClient) SetWithLease(ctx context.Context, key string, value string, leaseID int64) error {
+	return c.set(ctx, &keyvalue.SetRequest{Key: key, Value: value, LeaseId: leaseID})
+}
+
+// Acquire stores a key-value pair attached to a lease only if the key does not exist yet. When it does, the
+// acquisition carries a fencing token larger than every token the service handed out before.
+func (c *KVStoreClient) Acquire(ctx context.Context, key string, value string, leaseID int64) (Acquisition, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := keyvalue.NewLeaseServiceClient(c.conn).Acquire(ctx, &keyvalue.AcquireRequest{Key: key, Value: value, LeaseId: leaseID})
+	if err != nil {
+		return Acquisition{}, fmt.Errorf("failed to acquire %s: %w", key, translateError(err))
+	}
+	return Acquisition{Acquired: resp.Acquired, FencingToken: resp.FencingToken, Holder: resp.Holder}, nil
+}
+
+func fromLeaseResponse(resp *keyvalue.LeaseResponse) Lease {
+	return Lease{
+		ID:        resp.Id,
+		TTL:       time.Duration(resp.TtlMillis) * time.Millisecond,
+		Remaining: time.Duration(resp.RemainingMillis) * time.Millisecond,
+		Keys:      resp.Keys,
+	}
+}
@@ -0,0 +1,225 @@
+// Package lock provides a distributed lock on top of the leases of the key-value service.
+//
+// A lock is a key attached to a lease of its holder. The holder keeps the lease alive in the background; if it
+// crashes or loses touch with the service the lease expires and the lock is released. Every acquisition carries
+// a fencing token larger than the tokens of earlier holders, so a resource can reject a holder whose lock was
+// lost without it noticing by remembering the largest token it has seen.
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"key-value/client"
+	"key-value/client/internal/keepalive"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultTTL           = 10 * time.Second
+	DefaultRetryInterval = 100 * time.Millisecond
+	DefaultPrefix        = "locks/"
+
+	// releaseTimeout bounds the revocation of the lease of a lock that was not acquired
+	releaseTimeout = 5 * time.Second
+)
+
+var (
+	// ErrLocked is returned by TryLock when another owner holds the lock
+	ErrLocked = errors.New("lock is held by another owner")
+	// ErrLost is returned when the lease of a lock expired, so others may have acquired it since
+	ErrLost = errors.New("lock was lost")
+)
+
+// Client is the part of client.KVStoreClient that locks use
+type Client interface {
+	Grant(ctx context.Context, ttl time.Duration) (client.Lease, error)
+	KeepAlive(ctx context.Context, id int64) (client.Lease, error)
+	Revoke(ctx context.Context, id int64) error
+	Acquire(ctx context.Context, key string, value string, leaseID int64) (client.Acquisition, error)
+}
+
+// Locker acquires locks for one owner
+type Locker struct {
+	client        Client
+	ttl           time.Duration
+	retryInterval time.Duration
+	prefix        string
+	owner         string
+}
+
+// Option configures a Locker
+type Option func(*Locker)
+
+// WithTTL sets the time to live of the lease of each lock, DefaultTTL by default. A crashed holder keeps the
+// lock for up to this long; the lease is renewed every third of it.
+func WithTTL(ttl time.Duration) Option {
+	return func(l *Locker) {
+		if ttl > 0 {
+			l.ttl = ttl
+		}
+	}
+}
+
+// WithRetryInterval sets how often Lock retries while another owner holds the lock, DefaultRetryInterval by default
+func WithRetryInterval(interval time.Duration) Option {
+	return func(l *Locker) {
+		if interval > 0 {
+			l.retryInterval = interval
+		}
+	}
+}
+
+// WithPrefix sets the prefix of the lock keys, DefaultPrefix by default
+func WithPrefix(prefix string) Option {
+	return func(l *Locker) {
+		l.prefix = prefix
+	}
+}
+
+// WithOwner sets the value stored in the keys of held locks, a random ID by default
+func WithOwner(owner string) Option {
+	return func(l *Locker) {
+		l.owner = owner
+	}
+}
+
+// NewLocker creates a Locker taking locks through c
+func NewLocker(c Client, opts ...Option) *Locker {
+	l := &Locker{
+		client:        c,
+		ttl:           DefaultTTL,
+		retryInterval: DefaultRetryInterval,
+		prefix:        DefaultPrefix,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.owner == "" {
+		b := make([]byte, 8)
+		rand.Read(b)
+		l.owner = hex.EncodeToString(b)
+	}
+	return l
+}
+
+// Owner returns the value stored in the keys of held locks
+func (l *Locker) Owner() string {
+	return l.owner
+}
+
+// Lock acquires the named lock, waiting until it is free or ctx is done
+func (l *Locker) Lock(ctx context.Context, name string) (*Lock, error) {
+	return l.lock(ctx, name, true)
+}
+
+// TryLock acquires the named lock if it is free and fails with ErrLocked otherwise
+func (l *Locker) TryLock(ctx context.Context, name string) (*Lock, error) {
+	return l.lock(ctx, name, false)
+}
+
+func (l *Locker) lock(ctx context.Context, name string, wait bool) (*Lock, error) {
+	lease, err := l.client.Grant(ctx, l.ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", name, err)
+	}
+
+	// Keep the lease alive while waiting, so it does not expire between attempts
+	k := &Lock{
+		name:    name,
+		key:     l.prefix + name,
+		leaseID: lease.ID,
+		client:  l.client,
+		renewal: keepalive.Start(l.client, lease.ID, l.ttl),
+	}
+
+	for {
+		acquisition, err := l.client.Acquire(ctx, k.key, l.owner, lease.ID)
+		if err != nil {
+			k.abandon()
+			if errors.Is(err, client.ErrLeaseNotFound) {
+				err = ErrLost
+			}
+			return nil, fmt.Errorf("failed to lock %s: %w", name, err)
+		}
+		if acquisition.Acquired {
+			k.token = acquisition.FencingToken
+			return k, nil
+		}
+		if !wait {
+			k.abandon()
+			return nil, fmt.Errorf("%w: %s is held by %s", ErrLocked, name, acquisition.Holder)
+		}
+
+		select {
+		case <-ctx.Done():
+			k.abandon()
+			return nil, ctx.Err()
+		case <-k.renewal.Lost():
+			k.abandon()
+			return nil, fmt.Errorf("failed to lock %s: %w", name, ErrLost)
+		case <-time.After(l.retryInterval):
+		}
+	}
+}
+
+// Lock is a held lock. Its lease is renewed in the background until Unlock.
+type Lock struct {
+	name    string
+	key     string
+	token   uint64
+	leaseID int64
+	client  Client
+	renewal *keepalive.Renewal
+
+	once      sync.Once
+	unlockErr error
+}
+
+// Name returns the name of the lock
+func (k *Lock) Name() string {
+	return k.name
+}
+
+// Token returns the fencing token of this acquisition, larger than the tokens of every earlier holder.
+// Pass it along with writes to the protected resource so it can reject stale holders.
+func (k *Lock) Token() uint64 {
+	return k.token
+}
+
+// Lost returns a channel closed when the lease of the lock could not be renewed in time.
+// From then on another owner may hold the lock.
+func (k *Lock) Lost() <-chan struct{} {
+	return k.renewal.Lost()
+}
+
+// Unlock releases the lock. It returns ErrLost when the lock was lost before; calling it again returns the
+// same result.
+func (k *Lock) Unlock(ctx context.Context) error {
+	k.once.Do(func() {
+		if k.renewal.Stop() {
+			k.unlockErr = fmt.Errorf("failed to unlock %s: %w", k.name, ErrLost)
+			return
+		}
+
+		err := k.client.Revoke(ctx, k.leaseID)
+		if errors.Is(err, client.ErrLeaseNotFound) {
+			err = ErrLost
+		}
+		if err != nil {
+			k.unlockErr = fmt.Errorf("failed to unlock %s: %w", k.name, err)
+		}
+	})
+	return k.unlockErr
+}
+
+// abandon stops renewing and revokes the lease of a lock that was not acquired
+func (k *Lock) abandon() {
+	k.renewal.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	k.client.Revoke(ctx, k.leaseID)
+}
@@ -0,0 +1,251 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"key-value/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeLeases is an in-memory lease service for locks
+type fakeLeases struct {
+	mutex  sync.Mutex
+	lastID int64
+	fence  uint64
+	expiry map[int64]time.Time
+	ttl    map[int64]time.Duration
+	keys   map[string]int64 // lease holding each key
+	values map[string]string
+}
+
+func newFakeLeases() *fakeLeases {
+	return &fakeLeases{
+		expiry: make(map[int64]time.Time),
+		ttl:    make(map[int64]time.Duration),
+		keys:   make(map[string]int64),
+		values: make(map[string]string),
+	}
+}
+
+// alive expires the lease if its time is up. The caller holds the mutex.
+func (f *fakeLeases) alive(id int64) bool {
+	expiry, ok := f.expiry[id]
+	if ok && time.Now().Before(expiry) {
+		return true
+	}
+	f.end(id)
+	return false
+}
+
+// end removes a lease and its keys. The caller holds the mutex.
+func (f *fakeLeases) end(id int64) {
+	delete(f.expiry, id)
+	for key, owner := range f.keys {
+		if owner == id {
+			delete(f.keys, key)
+			delete(f.values, key)
+		}
+	}
+}
+
+func (f *fakeLeases) Grant(ctx context.Context, ttl time.Duration) (client.Lease, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.lastID++
+	f.expiry[f.lastID] = time.Now().Add(ttl)
+	f.ttl[f.lastID] = ttl
+	return client.Lease{ID: f.lastID, TTL: ttl, Remaining: ttl}, nil
+}
+
+func (f *fakeLeases) KeepAlive(ctx context.Context, id int64) (client.Lease, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if !f.alive(id) {
+		return client.Lease{}, fmt.Errorf("failed to keep lease %d alive: %w", id, client.ErrLeaseNotFound)
+	}
+	f.expiry[id] = time.Now().Add(f.ttl[id])
+	return client.Lease{ID: id, TTL: f.ttl[id], Remaining: f.ttl[id]}, nil
+}
+
+func (f *fakeLeases) Revoke(ctx context.Context, id int64) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if !f.alive(id) {
+		return fmt.Errorf("failed to revoke lease %d: %w", id, client.ErrLeaseNotFound)
+	}
+	f.end(id)
+	return nil
+}
+
+func (f *fakeLeases) Acquire(ctx context.Context, key string, value string, leaseID int64) (client.Acquisition, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if !f.alive(leaseID) {
+		return client.Acquisition{}, fmt.Errorf("failed to acquire %s: %w", key, client.ErrLeaseNotFound)
+	}
+	if owner, ok := f.keys[key]; ok && f.alive(owner) {
+		return client.Acquisition{Holder: f.values[key]}, nil
+	}
+	f.keys[key] = leaseID
+	f.values[key] = value
+	f.fence++
+	return client.Acquisition{Acquired: true, FencingToken: f.fence, Holder: value}, nil
+}
+
+// holder returns the value of a lock key, empty when nobody holds it
+func (f *fakeLeases) holder(key string) string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if owner, ok := f.keys[key]; ok && f.alive(owner) {
+		return f.values[key]
+	}
+	return ""
+}
+
+// expire ends a lease behind the back of its holder
+func (f *fakeLeases) expire(key string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.end(f.keys[key])
+}
+
+func TestLocker_MutualExclusion(t *testing.T) {
+	leases := newFakeLeases()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var (
+		wg      sync.WaitGroup
+		mutex   sync.Mutex
+		holders int
+		tokens  []uint64
+	)
+	for i := range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			locker := NewLocker(leases, WithTTL(time.Second), WithRetryInterval(time.Millisecond), WithOwner(fmt.Sprint(i)))
+			l, err := locker.Lock(ctx, "jobs")
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			mutex.Lock()
+			holders++
+			assert.Equal(t, 1, holders, "lock held twice")
+			tokens = append(tokens, l.Token())
+			mutex.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			mutex.Lock()
+			holders--
+			mutex.Unlock()
+			assert.NoError(t, l.Unlock(ctx))
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, tokens, 5)
+	for i := 1; i < len(tokens); i++ {
+		assert.Greater(t, tokens[i], tokens[i-1], "fencing tokens must grow with each acquisition")
+	}
+	assert.Empty(t, leases.holder("locks/jobs"))
+}
+
+func TestLocker_RenewsLease(t *testing.T) {
+	leases := newFakeLeases()
+	ctx := context.Background()
+
+	l, err := NewLocker(leases, WithTTL(30*time.Millisecond), WithOwner("a")).Lock(ctx, "jobs")
+	require.NoError(t, err)
+
+	// Held well past its time to live
+	time.Sleep(150 * time.Millisecond)
+	assert.Equal(t, "a", leases.holder("locks/jobs"))
+	select {
+	case <-l.Lost():
+		t.Fatal("lock lost while renewed")
+	default:
+	}
+
+	require.NoError(t, l.Unlock(ctx))
+	assert.Empty(t, leases.holder("locks/jobs"))
+	assert.NoError(t, l.Unlock(ctx), "unlocking again returns the same result")
+}
+
+func TestLocker_Lost(t *testing.T) {
+	leases := newFakeLeases()
+	ctx := context.Background()
+
+	first, err := NewLocker(leases, WithTTL(30*time.Millisecond), WithOwner("a")).Lock(ctx, "jobs")
+	require.NoError(t, err)
+	leases.expire("locks/jobs")
+
+	select {
+	case <-first.Lost():
+	case <-time.After(time.Second):
+		t.Fatal("lost lock not noticed")
+	}
+	assert.ErrorIs(t, first.Unlock(ctx), ErrLost)
+
+	second, err := NewLocker(leases, WithOwner("b")).TryLock(ctx, "jobs")
+	require.NoError(t, err)
+	assert.Greater(t, second.Token(), first.Token())
+	require.NoError(t, second.Unlock(ctx))
+}
+
+func TestLocker_TryLock(t *testing.T) {
+	tests := []struct {
+		name    string
+		held    bool
+		wantErr error
+	}{
+		{name: "free", held: false},
+		{name: "held", held: true, wantErr: ErrLocked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leases := newFakeLeases()
+			ctx := context.Background()
+			if tt.held {
+				held, err := NewLocker(leases, WithOwner("a")).Lock(ctx, "jobs")
+				require.NoError(t, err)
+				defer held.Unlock(ctx)
+			}
+
+			l, err := NewLocker(leases, WithOwner("b")).TryLock(ctx, "jobs")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Contains(t, err.Error(), "held by a")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "b", leases.holder("locks/jobs"))
+			require.NoError(t, l.Unlock(ctx))
+		})
+	}
+}
+
+func TestLocker_LockStopsWithContext(t *testing.T) {
+	leases := newFakeLeases()
+	held, err := NewLocker(leases, WithOwner("a")).Lock(context.Background(), "jobs")
+	require.NoError(t, err)
+	defer held.Unlock(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = NewLocker(leases, WithOwner("b"), WithRetryInterval(time.Millisecond)).Lock(ctx, "jobs")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// The lease of the waiter was revoked, only the holder's remains
+	leases.mutex.Lock()
+	defer leases.mutex.Unlock()
+	assert.Len(t, leases.expiry, 1)
+}
@@ -0,0 +1,262 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"key-value/proto/keyvalue"
+	"slices"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/resolver"
+)
+
+// Member states reported by the gossip membership of the key-value services
+const (
+	MemberAlive   = keyvalue.MemberState_MEMBER_STATE_ALIVE
+	MemberSuspect = keyvalue.MemberState_MEMBER_STATE_SUSPECT
+	MemberDead    = keyvalue.MemberState_MEMBER_STATE_DEAD
+	MemberLeft    = keyvalue.MemberState_MEMBER_STATE_LEFT
+)
+
+const (
+	// watchRetryInterval is the wait before watching another member after a stream broke
+	watchRetryInterval = time.Second
+
+	// membersScheme is the resolver scheme used by WithMemberDiscovery
+	membersScheme = "kvstore-members"
+)
+
+// Member is a key-value service as seen by the gossip membership
+type Member struct {
+	ID    string
+	Addr  string
+	State keyvalue.MemberState
+}
+
+// MemberWatcher follows the member list of a gossip group. It watches one member at a time and moves on to
+// another known member, or back to the seeds, when that member goes away.
+type MemberWatcher struct {
+	seeds       []string
+	dialOptions []grpc.DialOption
+
+	mutex       sync.Mutex
+	members     []Member
+	subscribers map[chan []Member]struct{}
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// WatchMembers starts following the member list, reaching the group through any of the seeds
+func WatchMembers(seeds []string, opts ...Option) (*MemberWatcher, error) {
+	if len(seeds) == 0 {
+		return nil, fmt.Errorf("no seed address given")
+	}
+	options := newClientOptions(opts...)
+
+	w := &MemberWatcher{
+		seeds:       seeds,
+		dialOptions: options.dialOptions(),
+		subscribers: make(map[chan []Member]struct{}),
+		done:        make(chan struct{}),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	go w.run(ctx)
+	return w, nil
+}
+
+// Members returns the latest member list, empty until a member answered
+func (w *MemberWatcher) Members() []Member {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return slices.Clone(w.members)
+}
+
+// Alive returns the addresses of the alive members
+func (w *MemberWatcher) Alive() []string {
+	return aliveAddrs(w.Members())
+}
+
+// Subscribe returns a channel receiving the member list after every change and a function ending the subscription.
+// A slow reader only misses intermediate lists, never the latest one.
+func (w *MemberWatcher) Subscribe() (<-chan []Member, func()) {
+	ch := make(chan []Member, 1)
+	w.mutex.Lock()
+	w.subscribers[ch] = struct{}{}
+	w.mutex.Unlock()
+
+	return ch, func() {
+		w.mutex.Lock()
+		defer w.mutex.Unlock()
+		if _, ok := w.subscribers[ch]; ok {
+			delete(w.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Close stops watching and ends every subscription
+func (w *MemberWatcher) Close() error {
+	w.cancel()
+	<-w.done
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for ch := range w.subscribers {
+		delete(w.subscribers, ch)
+		close(ch)
+	}
+	return nil
+}
+
+func (w *MemberWatcher) run(ctx context.Context) {
+	defer close(w.done)
+	for attempt := 0; ; attempt++ {
+		candidates := w.candidates()
+		err := w.watch(ctx, candidates[attempt%len(candidates)])
+		if ctx.Err() != nil {
+			return
+		}
+		// Switch members right away after a stream that worked, back off after a failed attempt
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(watchRetryInterval):
+			}
+		}
+	}
+}
+
+// candidates lists the alive members followed by the seeds
+func (w *MemberWatcher) candidates() []string {
+	candidates := w.Alive()
+	for _, seed := range w.seeds {
+		if !slices.Contains(candidates, seed) {
+			candidates = append(candidates, seed)
+		}
+	}
+	return candidates
+}
+
+// watch follows the member list of addr until the stream breaks. It returns nil if it received at least one list.
+func (w *MemberWatcher) watch(ctx context.Context, addr string) error {
+	conn, err := grpc.NewClient(addr, w.dialOptions...)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	stream, err := keyvalue.NewMembershipServiceClient(conn).WatchMembers(ctx, &keyvalue.MembersRequest{})
+	if err != nil {
+		return err
+	}
+	received := false
+	for {
+		list, err := stream.Recv()
+		if err != nil {
+			if received {
+				return nil
+			}
+			return err
+		}
+		received = true
+		w.update(list)
+	}
+}
+
+func (w *MemberWatcher) update(list *keyvalue.MemberList) {
+	members := make([]Member, len(list.Members))
+	for i, m := range list.Members {
+		members[i] = Member{ID: m.Id, Addr: m.Addr, State: m.State}
+	}
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if slices.Equal(members, w.members) {
+		return
+	}
+	w.members = members
+	for ch := range w.subscribers {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- slices.Clone(members)
+	}
+}
+
+// aliveAddrs returns the addresses of the alive members
+func aliveAddrs(members []Member) []string {
+	var addrs []string
+	for _, m := range members {
+		if m.State == MemberAlive {
+			addrs = append(addrs, m.Addr)
+		}
+	}
+	return addrs
+}
+
+// WithMemberDiscovery treats the address given to NewKVStoreClient as seeds of a gossip group and balances calls
+// across its alive members, following them as they join, fail and leave
+func WithMemberDiscovery() Option {
+	return func(o *clientOptions) {
+		o.memberDiscovery = true
+	}
+}
+
+// memberResolver feeds the alive members of a MemberWatcher to gRPC, falling back to the seeds when none is known
+type memberResolver struct {
+	watcher *MemberWatcher
+	seeds   []string
+}
+
+func (b *memberResolver) Scheme() string {
+	return membersScheme
+}
+
+func (b *memberResolver) Build(target resolver.Target, cc resolver.ClientConn, opts resolver.BuildOptions) (resolver.Resolver, error) {
+	updates, cancel := b.watcher.Subscribe()
+	push := func(members []Member) {
+		addrs := aliveAddrs(members)
+		if len(addrs) == 0 {
+			addrs = b.seeds
+		}
+		state := resolver.State{}
+		for _, addr := range addrs {
+			state.Endpoints = append(state.Endpoints, resolver.Endpoint{Addresses: []resolver.Address{{Addr: addr}}})
+		}
+		cc.UpdateState(state)
+	}
+
+	push(b.watcher.Members())
+	// Ends when the subscription is cancelled or the watcher closed
+	go func() {
+		for members := range updates {
+			push(members)
+		}
+	}()
+	return &memberResolution{cancel: cancel}, nil
+}
+
+// memberResolution is one resolver built by memberResolver
+type memberResolution struct {
+	cancel func()
+}
+
+func (r *memberResolution) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (r *memberResolution) Close() {
+	r.cancel()
+}
+
+// closeWatcher closes an optional watcher
+func closeWatcher(w *MemberWatcher) error {
+	if w == nil {
+		return nil
+	}
+	return w.Close()
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"key-value/proto/keyvalue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// gossipSeed is an in-process member streaming the lists it is given to one watcher at a time
+type gossipSeed struct {
+	keyvalue.UnimplementedMembershipServiceServer
+	lists chan *keyvalue.MemberList
+	addr  string
+}
+
+func (s *gossipSeed) WatchMembers(req *keyvalue.MembersRequest, stream keyvalue.MembershipService_WatchMembersServer) error {
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case list := <-s.lists:
+			if err := stream.Send(list); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func startGossipSeed(t *testing.T) *gossipSeed {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	seed := &gossipSeed{lists: make(chan *keyvalue.MemberList, 1), addr: lis.Addr().String()}
+	server := grpc.NewServer()
+	keyvalue.RegisterMembershipServiceServer(server, seed)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return seed
+}
+
+func memberList(replicas []*replica, states ...keyvalue.MemberState) *keyvalue.MemberList {
+	list := &keyvalue.MemberList{}
+	for i, r := range replicas {
+		list.Members = append(list.Members, &keyvalue.GossipMember{Id: r.addr, Addr: r.addr, State: states[i]})
+	}
+	return list
+}
+
+func TestMemberWatcher_Subscribe(t *testing.T) {
+	replicas := startReplicas(t, 2)
+	seed := startGossipSeed(t)
+
+	watcher, err := WatchMembers([]string{seed.addr})
+	require.NoError(t, err)
+	defer watcher.Close()
+	updates, cancel := watcher.Subscribe()
+	defer cancel()
+
+	seed.lists <- memberList(replicas, MemberAlive, MemberAlive)
+	select {
+	case members := <-updates:
+		assert.Len(t, members, 2)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no member list received")
+	}
+	assert.ElementsMatch(t, []string{replicas[0].addr, replicas[1].addr}, watcher.Alive())
+
+	seed.lists <- memberList(replicas, MemberAlive, MemberSuspect)
+	select {
+	case members := <-updates:
+		assert.Equal(t, MemberSuspect, members[1].State)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no member list received")
+	}
+	assert.Equal(t, []string{replicas[0].addr}, watcher.Alive())
+}
+
+func TestKVStoreClient_MemberDiscovery(t *testing.T) {
+	replicas := startReplicas(t, 3)
+	seed := startGossipSeed(t)
+	seed.lists <- memberList(replicas, MemberDead, MemberAlive, MemberAlive)
+
+	client, err := NewKVStoreClient(seed.addr, WithMemberDiscovery())
+	require.NoError(t, err)
+	defer client.Close()
+	waitForAllReady(t, client, replicas[1:])
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, client.Health(context.Background()))
+	}
+	assert.Zero(t, replicas[0].calls.Load())
+	assert.Equal(t, int64(10), replicas[1].calls.Load())
+	assert.Equal(t, int64(10), replicas[2].calls.Load())
+
+	// The first replica comes back while the second leaves
+	seed.lists <- memberList(replicas, MemberAlive, MemberLeft, MemberAlive)
+	require.Eventually(t, func() bool {
+		resetCalls(replicas)
+		for i := 0; i < 10; i++ {
+			require.NoError(t, client.Health(context.Background()))
+		}
+		return replicas[0].calls.Load() > 0 && replicas[1].calls.Load() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
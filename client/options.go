@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"key-value/proto/keyvalue"
+	"key-value/shared/limits"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+)
+
+// Option configures a KVStoreClient
+type Option func(*clientOptions)
+
+// clientOptions holds the settings collected from the Option functions
+type clientOptions struct {
+	transportCreds      credentials.TransportCredentials
+	perRPCCreds         []credentials.PerRPCCredentials
+	unaryInterceptors   []grpc.UnaryClientInterceptor
+	keepalive           *keepalive.ClientParameters
+	extraDialOptions    []grpc.DialOption
+	defaultTimeout      time.Duration
+	loadBalancingPolicy string
+	readConsistency     keyvalue.ReadConsistency
+	virtualNodes        int
+	replicas            int
+	readQuorum          int
+	writeQuorum         int
+	clientID            string
+	memberDiscovery     bool
+	maxMessageSize      int
+}
+
+// WithTLS secures the connection with the given TLS configuration.
+// Without it the client connects over plaintext.
+func WithTLS(config *tls.Config) Option {
+	return func(o *clientOptions) {
+		o.transportCreds = credentials.NewTLS(config)
+	}
+}
+
+// WithPerRPCCredentials attaches credentials (tokens, API keys, ...) to every call
+func WithPerRPCCredentials(creds credentials.PerRPCCredentials) Option {
+	return func(o *clientOptions) {
+		o.perRPCCreds = append(o.perRPCCreds, creds)
+	}
+}
+
+// WithUnaryInterceptor adds a unary interceptor. Interceptors run in the order they are added.
+func WithUnaryInterceptor(interceptor grpc.UnaryClientInterceptor) Option {
+	return func(o *clientOptions) {
+		o.unaryInterceptors = append(o.unaryInterceptors, interceptor)
+	}
+}
+
+// WithKeepalive sets the keepalive parameters of the underlying connection
+func WithKeepalive(params keepalive.ClientParameters) Option {
+	return func(o *clientOptions) {
+		o.keepalive = &params
+	}
+}
+
+// WithDialOptions passes raw gRPC dial options through to grpc.NewClient.
+// Use it for anything not covered by a dedicated option (message size limits, user agent, ...).
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *clientOptions) {
+		o.extraDialOptions = append(o.extraDialOptions, opts...)
+	}
+}
+
+// WithLimits raises the gRPC message limits of the client to fit keys and values as large as the service accepts.
+// Without it replies are limited to gRPC's default of 4 MiB, which is enough for the default limits.
+func WithLimits(l limits.Limits) Option {
+	return func(o *clientOptions) {
+		o.maxMessageSize = l.MessageSize()
+	}
+}
+
+// WithDefaultTimeout applies a timeout to every call whose context has no deadline
+func WithDefaultTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) {
+		o.defaultTimeout = timeout
+	}
+}
+
+// Read consistency levels for Get against a Raft replicated service
+const (
+	// Linearizable reads are served by the leader and see every acknowledged write
+	Linearizable = keyvalue.ReadConsistency_READ_CONSISTENCY_LINEARIZABLE
+	// Stale reads are served by whichever replica receives them and may lag behind the leader
+	Stale = keyvalue.ReadConsistency_READ_CONSISTENCY_STALE
+)
+
+// WithReadConsistency sets the consistency requested by Get. Without it the server default is used.
+func WithReadConsistency(consistency keyvalue.ReadConsistency) Option {
+	return func(o *clientOptions) {
+		o.readConsistency = consistency
+	}
+}
+
+// newClientOptions applies the options over the defaults
+func newClientOptions(opts ...Option) *clientOptions {
+	o := &clientOptions{
+		transportCreds: insecure.NewCredentials(),
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// dialOptions converts the collected settings into gRPC dial options
+func (o *clientOptions) dialOptions() []grpc.DialOption {
+	options := []grpc.DialOption{
+		grpc.WithTransportCredentials(o.transportCreds),
+	}
+	for _, creds := range o.perRPCCreds {
+		options = append(options, grpc.WithPerRPCCredentials(creds))
+	}
+	if len(o.unaryInterceptors) > 0 {
+		options = append(options, grpc.WithChainUnaryInterceptor(o.unaryInterceptors...))
+	}
+	if o.keepalive != nil {
+		options = append(options, grpc.WithKeepaliveParams(*o.keepalive))
+	}
+	if o.maxMessageSize > 0 {
+		options = append(options, grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(o.maxMessageSize),
+			grpc.MaxCallSendMsgSize(o.maxMessageSize),
+		))
+	}
+	// Raw dial options go last so they can override anything set above
+	return append(options, o.extraDialOptions...)
+}
+
+// withTimeout derives a context bounded by the default timeout when the caller did not set a deadline
+func (c *KVStoreClient) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.defaultTimeout <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.defaultTimeout)
+}
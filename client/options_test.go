@@ -0,0 +1,185 @@
+package client
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"key-value/proto/keyvalue"
+	"key-value/shared/limits"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// healthServer is a minimal key-value service used to exercise a real connection
+type healthServer struct {
+	keyvalue.UnimplementedKeyValueServiceServer
+	lastMetadata metadata.MD
+}
+
+func (s *healthServer) Health(ctx context.Context, req *keyvalue.HealthRequest) (*keyvalue.HealthResponse, error) {
+	s.lastMetadata, _ = metadata.FromIncomingContext(ctx)
+	return &keyvalue.HealthResponse{Status: "healthy", Timestamp: time.Now().Unix()}, nil
+}
+
+// staticToken is a PerRPCCredentials that does not require transport security
+type staticToken string
+
+func (t staticToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t staticToken) RequireTransportSecurity() bool {
+	return false
+}
+
+func startHealthServer(t *testing.T) (*healthServer, string) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := &healthServer{}
+	grpcServer := grpc.NewServer()
+	keyvalue.RegisterKeyValueServiceServer(grpcServer, srv)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	return srv, lis.Addr().String()
+}
+
+func TestNewKVStoreClient_Options(t *testing.T) {
+	srv, addr := startHealthServer(t)
+
+	var calls []string
+	interceptor := func(name string) grpc.UnaryClientInterceptor {
+		return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			calls = append(calls, name)
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+	}
+
+	client, err := NewKVStoreClient(addr,
+		WithPerRPCCredentials(staticToken("secret")),
+		WithUnaryInterceptor(interceptor("first")),
+		WithUnaryInterceptor(interceptor("second")),
+		WithDialOptions(grpc.WithUserAgent("kv-test")),
+	)
+	require.NoError(t, err)
+	defer client.Close()
+
+	assert.NoError(t, client.Health(context.Background()))
+	assert.Equal(t, []string{"first", "second"}, calls)
+	assert.Equal(t, []string{"Bearer secret"}, srv.lastMetadata.Get("authorization"))
+	assert.Contains(t, srv.lastMetadata.Get("user-agent")[0], "kv-test")
+}
+
+func TestNewKVStoreClient_BackwardsCompatible(t *testing.T) {
+	_, addr := startHealthServer(t)
+
+	client, err := NewKVStoreClient(addr)
+	require.NoError(t, err)
+	defer client.Close()
+
+	assert.NoError(t, client.Health(context.Background()))
+}
+
+// largeValueServer answers every Get with a value above gRPC's default message limit
+type largeValueServer struct {
+	keyvalue.UnimplementedKeyValueServiceServer
+}
+
+func (largeValueServer) Get(ctx context.Context, req *keyvalue.GetRequest) (*keyvalue.GetResponse, error) {
+	return &keyvalue.GetResponse{Value: strings.Repeat("v", 5<<20), Found: true}, nil
+}
+
+func TestNewKVStoreClient_WithLimits(t *testing.T) {
+	l := limits.Limits{MaxKeyLength: 1024, MaxValueSize: 8 << 20}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := grpc.NewServer(grpc.MaxSendMsgSize(l.MessageSize()))
+	keyvalue.RegisterKeyValueServiceServer(grpcServer, largeValueServer{})
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	plain, err := NewKVStoreClient(lis.Addr().String())
+	require.NoError(t, err)
+	defer plain.Close()
+	_, _, err = plain.Get(context.Background(), "large")
+	assert.Error(t, err, "the gRPC default limit should reject the reply")
+
+	client, err := NewKVStoreClient(lis.Addr().String(), WithLimits(l))
+	require.NoError(t, err)
+	defer client.Close()
+	value, found, err := client.Get(context.Background(), "large")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Len(t, value, 5<<20)
+}
+
+func TestKVStoreClient_DefaultTimeout(t *testing.T) {
+	tests := []struct {
+		name           string
+		defaultTimeout time.Duration
+		callerDeadline time.Duration
+		expectDeadline bool
+		maxRemaining   time.Duration
+	}{
+		{
+			name:           "no default timeout",
+			expectDeadline: false,
+		},
+		{
+			name:           "default timeout applied",
+			defaultTimeout: 50 * time.Millisecond,
+			expectDeadline: true,
+			maxRemaining:   50 * time.Millisecond,
+		},
+		{
+			name:           "caller deadline wins",
+			defaultTimeout: 50 * time.Millisecond,
+			callerDeadline: time.Hour,
+			expectDeadline: true,
+			maxRemaining:   time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var deadline time.Time
+			var hasDeadline bool
+			mockClient := &MockKeyValueServiceClient{
+				GetFunc: func(ctx context.Context, in *keyvalue.GetRequest, opts ...grpc.CallOption) (*keyvalue.GetResponse, error) {
+					deadline, hasDeadline = ctx.Deadline()
+					return &keyvalue.GetResponse{Found: true}, nil
+				},
+			}
+
+			client := &KVStoreClient{
+				client:         mockClient,
+				addr:           "mock-address",
+				defaultTimeout: tt.defaultTimeout,
+			}
+
+			ctx := context.Background()
+			if tt.callerDeadline > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.callerDeadline)
+				defer cancel()
+			}
+
+			_, _, err := client.Get(ctx, "key")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectDeadline, hasDeadline)
+			if tt.expectDeadline {
+				remaining := time.Until(deadline)
+				assert.LessOrEqual(t, remaining, tt.maxRemaining)
+				assert.Greater(t, remaining, tt.maxRemaining/2)
+			}
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"key-value/proto/keyvalue"
+	"key-value/shared/vclock"
+	"key-value/shared/vclock/vclockpb"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// Quorum defaults, the classic Dynamo configuration where reads and writes overlap on one replica
+const (
+	DefaultReplicas    = 3
+	DefaultReadQuorum  = 2
+	DefaultWriteQuorum = 2
+)
+
+// replicaTimeout bounds calls that keep running after the quorum answered, such as read repair,
+// when the client has no default timeout
+const replicaTimeout = 5 * time.Second
+
+// WithReplicas sets N, the number of nodes each key of a QuorumClient is stored on
+func WithReplicas(n int) Option {
+	return func(o *clientOptions) {
+		o.replicas = n
+	}
+}
+
+// WithReadQuorum sets R, the number of replicas that must answer a QuorumClient read
+func WithReadQuorum(r int) Option {
+	return func(o *clientOptions) {
+		o.readQuorum = r
+	}
+}
+
+// WithWriteQuorum sets W, the number of replicas that must acknowledge a QuorumClient write
+func WithWriteQuorum(w int) Option {
+	return func(o *clientOptions) {
+		o.writeQuorum = w
+	}
+}
+
+// WithClientID names the client in the vector clocks of its writes. Each concurrently running
+// client must use its own ID; without the option a random one is generated.
+func WithClientID(id string) Option {
+	return func(o *clientOptions) {
+		o.clientID = id
+	}
+}
+
+// Siblings is the outcome of a quorum read
+type Siblings struct {
+	// Values holds one value, or several when writes conflicted. It is empty when the key is missing or deleted.
+	Values []string
+	// Context is passed to Put or Delete so the write supersedes every value that was read
+	Context vclock.Clock
+}
+
+// QuorumClient stores each key on N nodes picked from a consistent hash ring, in the style of Dynamo.
+// Writes return once W replicas acknowledged them and reads once R replicas answered, so R + W > N
+// makes every read see the latest acknowledged write. Concurrent writes are detected with vector
+// clocks and returned as siblings; the client resolves them by writing with the merged Context.
+// Replicas found stale by a read are repaired in the background.
+type QuorumClient struct {
+	nodes          []keyvalue.VersionedServiceClient
+	conns          []*grpc.ClientConn
+	addrs          []string
+	ring           *hashRing
+	n, r, w        int
+	clientID       string
+	defaultTimeout time.Duration
+
+	mutex   sync.Mutex
+	counter uint64         // last count this client wrote into a clock
+	repairs sync.WaitGroup // background read repairs
+}
+
+// replicaResult is the answer of one replica
+type replicaResult struct {
+	node     int
+	versions []vclock.Version
+	err      error
+}
+
+// NewQuorumClient connects to every node. Unlike a sharded client each address is a single node,
+// since the QuorumClient does the replication itself. N is capped at the number of nodes.
+func NewQuorumClient(addresses []string, opts ...Option) (*QuorumClient, error) {
+	if len(addresses) == 0 {
+		return nil, errors.New("at least one node address is required")
+	}
+	options := newClientOptions(append([]Option{
+		WithReplicas(DefaultReplicas),
+		WithReadQuorum(DefaultReadQuorum),
+		WithWriteQuorum(DefaultWriteQuorum),
+	}, opts...)...)
+
+	n := min(options.replicas, len(addresses))
+	r, w := min(options.readQuorum, n), min(options.writeQuorum, n)
+	if n < 1 || r < 1 || w < 1 {
+		return nil, fmt.Errorf("invalid quorum N=%d R=%d W=%d, all must be at least 1", n, r, w)
+	}
+
+	clientID := options.clientID
+	if clientID == "" {
+		id := make([]byte, 8)
+		rand.Read(id)
+		clientID = hex.EncodeToString(id)
+	}
+
+	c := &QuorumClient{
+		addrs:          addresses,
+		ring:           newHashRing(addresses, options.virtualNodes),
+		n:              n,
+		r:              r,
+		w:              w,
+		clientID:       clientID,
+		defaultTimeout: options.defaultTimeout,
+	}
+	for _, address := range addresses {
+		conn, err := grpc.NewClient(address, options.dialOptions()...)
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+		}
+		c.conns = append(c.conns, conn)
+		c.nodes = append(c.nodes, keyvalue.NewVersionedServiceClient(conn))
+	}
+	return c, nil
+}
+
+// Get reads the key from its replicas and returns once R of them answered.
+// Replicas that miss versions known to others are repaired in the background.
+func (c *QuorumClient) Get(ctx context.Context, key string) (Siblings, error) {
+	results := c.call(ctx, key, func(ctx context.Context, node keyvalue.VersionedServiceClient) ([]*keyvalue.Version, error) {
+		resp, err := node.GetVersions(ctx, &keyvalue.GetVersionsRequest{Key: key})
+		return resp.GetVersions(), err
+	})
+
+	answered, received, err := c.await(ctx, results, c.r)
+	if err != nil {
+		return Siblings{}, fmt.Errorf("failed to get key %s: %w", key, err)
+	}
+
+	c.repairs.Add(1)
+	go func() {
+		defer c.repairs.Done()
+		c.readRepair(key, append(answered, collect(results, c.n-received)...))
+	}()
+
+	var versions [][]vclock.Version
+	for _, result := range answered {
+		versions = append(versions, result.versions)
+	}
+	return toSiblings(vclock.Resolve(versions...)), nil
+}
+
+// Put writes value over every version in clock, the Context of a previous Get.
+// A nil clock writes a value concurrent to any existing one.
+func (c *QuorumClient) Put(ctx context.Context, key string, value string, clock vclock.Clock) error {
+	if err := c.write(ctx, key, vclock.Version{Value: value, Clock: c.nextClock(clock)}); err != nil {
+		return fmt.Errorf("failed to put key %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete writes a tombstone over every version in clock, the Context of a previous Get
+func (c *QuorumClient) Delete(ctx context.Context, key string, clock vclock.Clock) error {
+	if err := c.write(ctx, key, vclock.Version{Deleted: true, Clock: c.nextClock(clock)}); err != nil {
+		return fmt.Errorf("failed to delete key %s: %w", key, err)
+	}
+	return nil
+}
+
+// Close waits for background read repairs and closes the connections to every node
+func (c *QuorumClient) Close() error {
+	c.repairs.Wait()
+	var errs []error
+	for _, conn := range c.conns {
+		errs = append(errs, conn.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// write sends version to every replica and returns once W acknowledged it
+func (c *QuorumClient) write(ctx context.Context, key string, version vclock.Version) error {
+	req := &keyvalue.PutVersionsRequest{Key: key, Versions: vclockpb.ToProto([]vclock.Version{version})}
+	results := c.call(ctx, key, func(ctx context.Context, node keyvalue.VersionedServiceClient) ([]*keyvalue.Version, error) {
+		resp, err := node.PutVersions(ctx, req)
+		return resp.GetVersions(), err
+	})
+	_, _, err := c.await(ctx, results, c.w)
+	return err
+}
+
+// nextClock returns a clock descending from clock with this client's count raised.
+// The count is unique per write, so two writes from this client never share a clock.
+func (c *QuorumClient) nextClock(clock vclock.Clock) vclock.Clock {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.counter = max(c.counter, clock[c.clientID]) + 1
+	next := clock.Copy()
+	next[c.clientID] = c.counter
+	return next
+}
+
+// replicas returns the nodes holding key
+func (c *QuorumClient) replicas(key string) []int {
+	return c.ring.preferenceList(key, c.n)
+}
+
+// call runs fn against every replica of key in parallel. The calls are detached from ctx so
+// replicas the caller does not wait for still receive them. Results arrive on the returned channel.
+func (c *QuorumClient) call(ctx context.Context, key string, fn func(ctx context.Context, node keyvalue.VersionedServiceClient) ([]*keyvalue.Version, error)) <-chan replicaResult {
+	timeout := c.defaultTimeout
+	if timeout <= 0 {
+		timeout = replicaTimeout
+	}
+
+	replicas := c.replicas(key)
+	results := make(chan replicaResult, len(replicas))
+	for _, node := range replicas {
+		go func() {
+			callCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+			defer cancel()
+			versions, err := fn(callCtx, c.nodes[node])
+			if err != nil {
+				err = fmt.Errorf("replica %s: %w", c.addrs[node], translateError(err))
+			}
+			results <- replicaResult{node: node, versions: vclockpb.FromProto(versions), err: err}
+		}()
+	}
+	return results
+}
+
+// await waits for quorum successful results and reports how many results it consumed. It fails as
+// soon as too many replicas failed for the quorum to be reached, or when ctx is done.
+func (c *QuorumClient) await(ctx context.Context, results <-chan replicaResult, quorum int) ([]replicaResult, int, error) {
+	var answered []replicaResult
+	var errs []error
+	for len(errs) <= c.n-quorum {
+		select {
+		case result := <-results:
+			if result.err != nil {
+				errs = append(errs, result.err)
+			} else {
+				answered = append(answered, result)
+			}
+		case <-ctx.Done():
+			return nil, 0, fmt.Errorf("%w: %w", ErrUnavailable, ctx.Err())
+		}
+
+		if len(answered) >= quorum {
+			return answered, len(answered) + len(errs), nil
+		}
+	}
+	return nil, 0, fmt.Errorf("%w: %d of %d replicas answered, %d required: %w", ErrQuorumNotReached, len(answered), c.n, quorum, errors.Join(errs...))
+}
+
+// collect reads the remaining count results
+func collect(results <-chan replicaResult, count int) []replicaResult {
+	collected := make([]replicaResult, 0, count)
+	for i := 0; i < count; i++ {
+		if result := <-results; result.err == nil {
+			collected = append(collected, result)
+		}
+	}
+	return collected
+}
+
+// readRepair sends every replica that answered the versions it is missing
+func (c *QuorumClient) readRepair(key string, results []replicaResult) {
+	var versions [][]vclock.Version
+	for _, result := range results {
+		versions = append(versions, result.versions)
+	}
+	resolved := vclock.Resolve(versions...)
+
+	for _, result := range results {
+		var missing []vclock.Version
+		for _, v := range resolved {
+			if !vclock.Contains(result.versions, v) {
+				missing = append(missing, v)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), replicaTimeout)
+		c.nodes[result.node].PutVersions(ctx, &keyvalue.PutVersionsRequest{Key: key, Versions: vclockpb.ToProto(missing)})
+		cancel()
+	}
+}
+
+// toSiblings drops tombstones from the values but keeps their clocks in the context
+func toSiblings(versions []vclock.Version) Siblings {
+	siblings := Siblings{Values: []string{}}
+	clocks := make([]vclock.Clock, 0, len(versions))
+	for _, v := range versions {
+		if !v.Deleted {
+			siblings.Values = append(siblings.Values, v.Value)
+		}
+		clocks = append(clocks, v.Clock)
+	}
+	siblings.Context = vclock.Merge(clocks...)
+	return siblings
+}
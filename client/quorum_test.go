@@ -0,0 +1,244 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"key-value/proto/keyvalue"
+	"key-value/shared/vclock"
+	"key-value/shared/vclock/vclockpb"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// versionedNode is an in-process quorum replica whose network can be cut to simulate a partition
+type versionedNode struct {
+	keyvalue.UnimplementedVersionedServiceServer
+	partitioned atomic.Bool
+	mutex       sync.Mutex
+	versions    map[string][]vclock.Version
+}
+
+func (n *versionedNode) GetVersions(ctx context.Context, req *keyvalue.GetVersionsRequest) (*keyvalue.GetVersionsResponse, error) {
+	if n.partitioned.Load() {
+		return nil, status.Error(codes.Unavailable, "partitioned")
+	}
+	return &keyvalue.GetVersionsResponse{Versions: vclockpb.ToProto(n.get(req.Key))}, nil
+}
+
+func (n *versionedNode) PutVersions(ctx context.Context, req *keyvalue.PutVersionsRequest) (*keyvalue.PutVersionsResponse, error) {
+	if n.partitioned.Load() {
+		return nil, status.Error(codes.Unavailable, "partitioned")
+	}
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	for _, v := range vclockpb.FromProto(req.Versions) {
+		n.versions[req.Key] = vclock.Reconcile(n.versions[req.Key], v)
+	}
+	return &keyvalue.PutVersionsResponse{Versions: vclockpb.ToProto(n.versions[req.Key])}, nil
+}
+
+func (n *versionedNode) get(key string) []vclock.Version {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return append([]vclock.Version(nil), n.versions[key]...)
+}
+
+// values lists the live values a node holds for key
+func (n *versionedNode) values(key string) []string {
+	var values []string
+	for _, v := range n.get(key) {
+		if !v.Deleted {
+			values = append(values, v.Value)
+		}
+	}
+	return values
+}
+
+func startVersionedNodes(t *testing.T, count int) ([]*versionedNode, []string) {
+	t.Helper()
+	var nodes []*versionedNode
+	var addrs []string
+	for i := 0; i < count; i++ {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		node := &versionedNode{versions: map[string][]vclock.Version{}}
+		grpcServer := grpc.NewServer()
+		keyvalue.RegisterVersionedServiceServer(grpcServer, node)
+		go grpcServer.Serve(lis)
+		t.Cleanup(grpcServer.Stop)
+
+		nodes = append(nodes, node)
+		addrs = append(addrs, lis.Addr().String())
+	}
+	return nodes, addrs
+}
+
+// replicasOf returns the nodes holding key, in preference order
+func replicasOf(c *QuorumClient, nodes []*versionedNode, key string) []*versionedNode {
+	var replicas []*versionedNode
+	for _, i := range c.replicas(key) {
+		replicas = append(replicas, nodes[i])
+	}
+	return replicas
+}
+
+func TestQuorumClient_PutGet(t *testing.T) {
+	nodes, addrs := startVersionedNodes(t, 5)
+	client, err := NewQuorumClient(addrs)
+	require.NoError(t, err)
+	defer client.Close()
+	ctx := context.Background()
+
+	siblings, err := client.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Empty(t, siblings.Values)
+
+	require.NoError(t, client.Put(ctx, "k", "v1", siblings.Context))
+	siblings, err = client.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1"}, siblings.Values)
+
+	require.NoError(t, client.Put(ctx, "k", "v2", siblings.Context))
+	siblings, err = client.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v2"}, siblings.Values)
+
+	// The key lives on N of the 5 nodes
+	holding := 0
+	for _, node := range nodes {
+		if len(node.get("k")) > 0 {
+			holding++
+		}
+	}
+	assert.Equal(t, DefaultReplicas, holding)
+
+	require.NoError(t, client.Delete(ctx, "k", siblings.Context))
+	siblings, err = client.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Empty(t, siblings.Values)
+}
+
+func TestQuorumClient_Partitions(t *testing.T) {
+	nodes, addrs := startVersionedNodes(t, 3)
+	client, err := NewQuorumClient(addrs)
+	require.NoError(t, err)
+	defer client.Close()
+	ctx := context.Background()
+
+	// One replica down still leaves a quorum of two
+	nodes[0].partitioned.Store(true)
+	require.NoError(t, client.Put(ctx, "k", "v1", nil))
+	siblings, err := client.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v1"}, siblings.Values)
+
+	// Two replicas down do not
+	nodes[1].partitioned.Store(true)
+	err = client.Put(ctx, "k", "v2", siblings.Context)
+	assert.ErrorIs(t, err, ErrQuorumNotReached)
+	assert.ErrorIs(t, err, ErrUnavailable)
+	_, err = client.Get(ctx, "k")
+	assert.ErrorIs(t, err, ErrQuorumNotReached)
+
+	// A failed write is not rolled back: the reachable replica kept it, and R=1 reads it
+	single, err := NewQuorumClient(addrs, WithReadQuorum(1))
+	require.NoError(t, err)
+	defer single.Close()
+	siblings, err = single.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v2"}, siblings.Values)
+}
+
+func TestQuorumClient_ConcurrentWritesBecomeSiblings(t *testing.T) {
+	_, addrs := startVersionedNodes(t, 3)
+	alice, err := NewQuorumClient(addrs, WithClientID("alice"))
+	require.NoError(t, err)
+	defer alice.Close()
+	bob, err := NewQuorumClient(addrs, WithClientID("bob"))
+	require.NoError(t, err)
+	defer bob.Close()
+	ctx := context.Background()
+
+	require.NoError(t, alice.Put(ctx, "cart", "milk", nil))
+	base, err := alice.Get(ctx, "cart")
+	require.NoError(t, err)
+
+	// Both update the same version without seeing each other
+	require.NoError(t, alice.Put(ctx, "cart", "milk,eggs", base.Context))
+	require.NoError(t, bob.Put(ctx, "cart", "milk,bread", base.Context))
+
+	siblings, err := alice.Get(ctx, "cart")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"milk,eggs", "milk,bread"}, siblings.Values)
+
+	// Writing with the merged context resolves the conflict
+	require.NoError(t, bob.Put(ctx, "cart", "milk,eggs,bread", siblings.Context))
+	siblings, err = alice.Get(ctx, "cart")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"milk,eggs,bread"}, siblings.Values)
+}
+
+func TestQuorumClient_ReadRepair(t *testing.T) {
+	nodes, addrs := startVersionedNodes(t, 3)
+	client, err := NewQuorumClient(addrs)
+	require.NoError(t, err)
+	defer client.Close()
+	ctx := context.Background()
+
+	require.NoError(t, client.Put(ctx, "k", "old", nil))
+	require.NoError(t, client.Put(ctx, "gone", "x", nil))
+	siblings, err := client.Get(ctx, "k")
+	require.NoError(t, err)
+
+	// The stale replica misses the update and the delete while partitioned.
+	// Writes return after W acknowledgements, so first wait for the third one.
+	stale := nodes[2]
+	require.Eventually(t, func() bool {
+		return len(stale.values("k")) == 1 && len(stale.values("gone")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	stale.partitioned.Store(true)
+	require.NoError(t, client.Put(ctx, "k", "new", siblings.Context))
+	gone, err := client.Get(ctx, "gone")
+	require.NoError(t, err)
+	require.NoError(t, client.Delete(ctx, "gone", gone.Context))
+	stale.partitioned.Store(false)
+	assert.Equal(t, []string{"old"}, stale.values("k"))
+	assert.Equal(t, []string{"x"}, stale.values("gone"))
+
+	// Reads return the newest version and repair the stale replica
+	for _, key := range []string{"k", "gone"} {
+		_, err := client.Get(ctx, key)
+		require.NoError(t, err)
+	}
+	assert.Eventually(t, func() bool {
+		values := stale.values("k")
+		return len(values) == 1 && values[0] == "new" && len(stale.values("gone")) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Once repaired, the stale replica alone answers with the latest version
+	for _, node := range replicasOf(client, nodes, "k") {
+		if node != stale {
+			node.partitioned.Store(true)
+		}
+	}
+	single, err := NewQuorumClient(addrs, WithReadQuorum(1))
+	require.NoError(t, err)
+	defer single.Close()
+	siblings, err = single.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"new"}, siblings.Values)
+}
+
+func TestQuorumClient_InvalidQuorum(t *testing.T) {
+	_, err := NewQuorumClient([]string{"127.0.0.1:1"}, WithWriteQuorum(0))
+	assert.Error(t, err)
+}
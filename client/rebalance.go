@@ -0,0 +1,300 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"key-value/shared/models"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// rebalancePageSize is the number of keys read from a shard at a time while rebalancing
+	rebalancePageSize = 1000
+	// keyLockStripes is the number of locks serialising writes and moves of the same key
+	keyLockStripes = 256
+)
+
+var (
+	// ErrRebalanceInProgress is returned when a rebalance is started while another one is running
+	ErrRebalanceInProgress = errors.New("a rebalance is already in progress")
+	// ErrRebalanceIncomplete is returned when the shards are changed before a failed rebalance was retried
+	ErrRebalanceIncomplete = errors.New("the previous rebalance did not complete")
+)
+
+// RebalanceState is the phase of a rebalance
+type RebalanceState string
+
+const (
+	RebalanceIdle      RebalanceState = "idle"
+	RebalanceRunning   RebalanceState = "running"
+	RebalanceCompleted RebalanceState = "completed"
+	RebalanceFailed    RebalanceState = "failed"
+)
+
+// RebalanceStatus reports the progress of the last rebalance
+type RebalanceStatus struct {
+	State       RebalanceState
+	From        []string // shards before the rebalance
+	To          []string // shards after the rebalance
+	StartedAt   time.Time
+	FinishedAt  time.Time
+	KeysScanned int64 // keys read from the old shards so far
+	KeysMoved   int64 // keys copied to a new shard so far
+	Error       string
+}
+
+// rebalanceRun tracks the rebalance of a ShardedClient
+type rebalanceRun struct {
+	mutex  sync.Mutex
+	status RebalanceStatus
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// stop cancels a running rebalance and waits for it to return
+func (r *rebalanceRun) stop() {
+	r.mutex.Lock()
+	cancel, done := r.cancel, r.done
+	r.mutex.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// RebalanceStatus returns the progress of the running or last rebalance
+func (c *ShardedClient) RebalanceStatus() RebalanceStatus {
+	c.rebalance.mutex.Lock()
+	defer c.rebalance.mutex.Unlock()
+
+	status := c.rebalance.status
+	if status.State == "" {
+		status.State = RebalanceIdle
+	}
+	status.From = slices.Clone(status.From)
+	status.To = slices.Clone(status.To)
+	return status
+}
+
+// Shards returns the addresses of the shards keys are currently placed on
+func (c *ShardedClient) Shards() []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return slices.Clone(c.current.addrs)
+}
+
+// Rebalance moves the keys onto a new set of shards and blocks until they are all moved.
+// Reads and writes keep working meanwhile: a key that changes shard is read from the old shard
+// and then the new one, and is moved before it is written. Once every key is moved the client
+// switches to the new shards and closes the connections to removed ones.
+//
+// A failed or cancelled rebalance leaves the client reading from both sets of shards;
+// it must be retried with the same addresses before a different change can be started.
+// Other clients of the same shards only see the new placement once they are configured with it.
+func (c *ShardedClient) Rebalance(ctx context.Context, addresses []string) error {
+	ctx, err := c.beginRebalance(ctx, addresses)
+	if err != nil {
+		return err
+	}
+	return c.runRebalance(ctx)
+}
+
+// StartRebalance starts Rebalance in the background, RebalanceStatus reports its progress.
+// Close cancels it.
+func (c *ShardedClient) StartRebalance(addresses []string) error {
+	ctx, err := c.beginRebalance(context.Background(), addresses)
+	if err != nil {
+		return err
+	}
+	go c.runRebalance(ctx)
+	return nil
+}
+
+// beginRebalance connects the new shards and makes them the target of every call
+func (c *ShardedClient) beginRebalance(ctx context.Context, addresses []string) (context.Context, error) {
+	if len(addresses) == 0 {
+		return nil, errors.New("at least one shard address is required")
+	}
+
+	c.rebalance.mutex.Lock()
+	defer c.rebalance.mutex.Unlock()
+	if c.rebalance.status.State == RebalanceRunning {
+		return nil, ErrRebalanceInProgress
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.target != nil && !sameShards(c.target.addrs, addresses) {
+		return nil, fmt.Errorf("%w, retry the rebalance to %v before changing the shards again", ErrRebalanceIncomplete, c.target.addrs)
+	}
+	if err := c.connect(addresses); err != nil {
+		return nil, err
+	}
+	from := c.current.addrs
+	c.target = newTopology(slices.Clone(addresses), c.vnodes)
+
+	ctx, cancel := context.WithCancel(ctx)
+	c.rebalance.cancel = cancel
+	c.rebalance.done = make(chan struct{})
+	c.rebalance.status = RebalanceStatus{
+		State:     RebalanceRunning,
+		From:      from,
+		To:        c.target.addrs,
+		StartedAt: time.Now(),
+	}
+	return ctx, nil
+}
+
+// runRebalance moves the keys, switches to the new shards and records the outcome
+func (c *ShardedClient) runRebalance(ctx context.Context) error {
+	err := c.moveKeys(ctx)
+	if err == nil {
+		c.finishRebalance()
+	}
+
+	c.rebalance.mutex.Lock()
+	defer c.rebalance.mutex.Unlock()
+	c.rebalance.cancel()
+	close(c.rebalance.done)
+	c.rebalance.cancel, c.rebalance.done = nil, nil
+	c.rebalance.status.FinishedAt = time.Now()
+	if err != nil {
+		c.rebalance.status.State = RebalanceFailed
+		c.rebalance.status.Error = err.Error()
+		return err
+	}
+	c.rebalance.status.State = RebalanceCompleted
+	return nil
+}
+
+// moveKeys pages through every old shard and moves the keys whose owner changes
+func (c *ShardedClient) moveKeys(ctx context.Context) error {
+	c.mutex.RLock()
+	sources, target := c.current.addrs, c.target
+	c.mutex.RUnlock()
+
+	for _, source := range sources {
+		startAfter := ""
+		for {
+			items, more, err := c.clients[source].Scan(ctx, "", startAfter, rebalancePageSize)
+			if err != nil {
+				return fmt.Errorf("shard %s: %w", source, err)
+			}
+
+			var moved int64
+			for _, item := range items {
+				if owner := target.owner(item.Key); owner != source {
+					ok, err := c.moveLocked(ctx, item.Key, source, owner)
+					if err != nil {
+						return err
+					}
+					if ok {
+						moved++
+					}
+				}
+			}
+			c.progress(int64(len(items)), moved)
+
+			if !more || len(items) == 0 {
+				break
+			}
+			startAfter = items[len(items)-1].Key
+		}
+	}
+	return nil
+}
+
+// moveLocked moves one key while holding its lock, so it does not race a write through this client
+func (c *ShardedClient) moveLocked(ctx context.Context, key string, from string, to string) (bool, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	unlock := c.lockKeys([]string{key})
+	defer unlock()
+	return c.moveKey(ctx, key, from, to)
+}
+
+// moveKey copies key from one shard to another unless the new shard already has it,
+// then deletes it from the old shard if it still holds the copied value. A failed delete
+// undoes the copy, so the old shard stays the one to read. It reports whether the key was copied.
+// The caller must hold the key's lock.
+func (c *ShardedClient) moveKey(ctx context.Context, key string, from string, to string) (bool, error) {
+	source, dest := c.clients[from], c.clients[to]
+
+	value, found, err := source.Get(ctx, key)
+	if err != nil {
+		return false, fmt.Errorf("shard %s: %w", from, err)
+	}
+	if !found {
+		return false, nil
+	}
+
+	copied, err := dest.SetIfAbsent(ctx, models.KeyValue{Key: key, Value: value})
+	if err != nil {
+		return false, fmt.Errorf("shard %s: %w", to, err)
+	}
+
+	deleted, err := source.DeleteIfValue(ctx, key, value)
+	if err == nil && !deleted {
+		err = fmt.Errorf("key %s changed while it was moved: %w", key, ErrConflict)
+	}
+	if err != nil {
+		if copied {
+			dest.DeleteIfValue(ctx, key, value)
+		}
+		return false, fmt.Errorf("shard %s: %w", from, err)
+	}
+	return copied, nil
+}
+
+// finishRebalance switches to the new shards and closes the connections to removed ones
+func (c *ShardedClient) finishRebalance() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, addr := range c.current.addrs {
+		if !slices.Contains(c.target.addrs, addr) {
+			c.clients[addr].Close()
+			delete(c.clients, addr)
+		}
+	}
+	c.current, c.target = c.target, nil
+}
+
+func (c *ShardedClient) progress(scanned int64, moved int64) {
+	c.rebalance.mutex.Lock()
+	defer c.rebalance.mutex.Unlock()
+	c.rebalance.status.KeysScanned += scanned
+	c.rebalance.status.KeysMoved += moved
+}
+
+// lockKeys locks the stripes of keys in a fixed order and returns the function unlocking them
+func (c *ShardedClient) lockKeys(keys []string) func() {
+	stripes := make([]int, 0, len(keys))
+	for _, key := range keys {
+		stripes = append(stripes, int(hashString(key)%keyLockStripes))
+	}
+	sort.Ints(stripes)
+	stripes = slices.Compact(stripes)
+
+	for _, stripe := range stripes {
+		c.keyLocks[stripe].Lock()
+	}
+	return func() {
+		for _, stripe := range stripes {
+			c.keyLocks[stripe].Unlock()
+		}
+	}
+}
+
+// sameShards reports whether a and b hold the same addresses in any order
+func sameShards(a []string, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	sort.Strings(a)
+	sort.Strings(b)
+	return slices.Equal(a, b)
+}
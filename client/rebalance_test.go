@@ -0,0 +1,195 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"key-value/shared/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// valuesOf copies the contents of a shard
+func valuesOf(srv *memoryServer) map[string]string {
+	srv.mutex.Lock()
+	defer srv.mutex.Unlock()
+	values := make(map[string]string, len(srv.values))
+	for key, value := range srv.values {
+		values[key] = value
+	}
+	return values
+}
+
+func TestShardedClient_RebalanceMovesKeys(t *testing.T) {
+	servers, addrs := startShards(t, 3)
+	client, err := NewShardedClient(addrs[:2])
+	require.NoError(t, err)
+	defer client.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 300; i++ {
+		require.NoError(t, client.Set(ctx, models.KeyValue{Key: fmt.Sprintf("key-%d", i), Value: fmt.Sprint(i)}))
+	}
+
+	// Add the third shard and drop the first one
+	target := addrs[1:]
+	require.NoError(t, client.Rebalance(ctx, target))
+
+	status := client.RebalanceStatus()
+	assert.Equal(t, RebalanceCompleted, status.State)
+	assert.Equal(t, addrs[:2], status.From)
+	assert.Equal(t, target, status.To)
+	assert.GreaterOrEqual(t, status.KeysScanned, int64(300))
+	assert.Greater(t, status.KeysMoved, int64(150))
+	assert.Equal(t, target, client.Shards())
+
+	assert.Empty(t, valuesOf(servers[0]))
+	ring := newTopology(target, 0)
+	total := 0
+	for i, srv := range servers[1:] {
+		for key := range valuesOf(srv) {
+			assert.Equal(t, target[i], ring.owner(key), key)
+			total++
+		}
+	}
+	assert.Equal(t, 300, total)
+
+	for i := 0; i < 300; i += 7 {
+		value, found, err := client.Get(ctx, fmt.Sprintf("key-%d", i))
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, fmt.Sprint(i), value)
+	}
+}
+
+func TestShardedClient_ServesCallsWhileKeysMove(t *testing.T) {
+	servers, addrs := startShards(t, 2)
+	client, err := NewShardedClient(addrs[:1])
+	require.NoError(t, err)
+	defer client.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, client.Set(ctx, models.KeyValue{Key: fmt.Sprintf("key-%02d", i), Value: "old"}))
+	}
+
+	// Make the second shard the target without moving anything yet
+	moveCtx, err := client.beginRebalance(ctx, addrs)
+	require.NoError(t, err)
+
+	var moving, staying string
+	for i := 0; i < 20 && (moving == "" || staying == ""); i++ {
+		key := fmt.Sprintf("key-%02d", i)
+		if _, previous := client.route(key); previous != "" {
+			moving = key
+		} else {
+			staying = key
+		}
+	}
+	require.NotEmpty(t, moving)
+	require.NotEmpty(t, staying)
+
+	// Reads still find keys on the old shard
+	value, found, err := client.Get(ctx, moving)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "old", value)
+
+	// Writing a moving key moves it first
+	require.NoError(t, client.Set(ctx, models.KeyValue{Key: moving, Value: "new"}))
+	assert.NotContains(t, valuesOf(servers[0]), moving)
+	assert.Equal(t, "new", valuesOf(servers[1])[moving])
+
+	values, err := client.BatchGet(ctx, []string{moving, staying})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{moving: "new", staying: "old"}, values)
+
+	items, more, err := client.Scan(ctx, "key-", "", 100)
+	require.NoError(t, err)
+	assert.False(t, more)
+	assert.Len(t, items, 20)
+
+	// A second, different rebalance is refused while this one is pending
+	err = client.StartRebalance(addrs[1:])
+	assert.ErrorIs(t, err, ErrRebalanceInProgress)
+
+	require.NoError(t, client.runRebalance(moveCtx))
+	assert.Equal(t, addrs, client.Shards())
+}
+
+func TestShardedClient_RebalanceUnderLoad(t *testing.T) {
+	_, addrs := startShards(t, 3)
+	client, err := NewShardedClient(addrs[:1])
+	require.NoError(t, err)
+	defer client.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 500; i++ {
+		require.NoError(t, client.Set(ctx, models.KeyValue{Key: fmt.Sprintf("key-%03d", i), Value: "0"}))
+	}
+
+	require.NoError(t, client.StartRebalance(addrs))
+
+	// Keep incrementing counters while their keys move
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := w; i < 500; i += 4 {
+				_, err := client.Increment(ctx, fmt.Sprintf("key-%03d", i), 1)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Eventually(t, func() bool {
+		return client.RebalanceStatus().State == RebalanceCompleted
+	}, 10*time.Second, 10*time.Millisecond)
+
+	values, err := client.BatchGet(ctx, func() []string {
+		var keys []string
+		for i := 0; i < 500; i++ {
+			keys = append(keys, fmt.Sprintf("key-%03d", i))
+		}
+		return keys
+	}())
+	require.NoError(t, err)
+	assert.Len(t, values, 500)
+	for key, value := range values {
+		assert.Equal(t, "1", value, key)
+	}
+}
+
+func TestShardedClient_RetryFailedRebalance(t *testing.T) {
+	_, addrs := startShards(t, 2)
+	client, err := NewShardedClient(addrs[:1])
+	require.NoError(t, err)
+	defer client.Close()
+	ctx := context.Background()
+
+	require.NoError(t, client.Set(ctx, models.KeyValue{Key: "a", Value: "1"}))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	err = client.Rebalance(cancelled, addrs)
+	require.Error(t, err)
+	status := client.RebalanceStatus()
+	assert.Equal(t, RebalanceFailed, status.State)
+	assert.NotEmpty(t, status.Error)
+
+	// Only the same change can be retried
+	assert.ErrorIs(t, client.Rebalance(ctx, addrs[1:]), ErrRebalanceIncomplete)
+	require.NoError(t, client.Rebalance(ctx, addrs))
+	assert.Equal(t, RebalanceCompleted, client.RebalanceStatus().State)
+
+	value, found, err := client.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "1", value)
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"key-value/proto/keyvalue"
+	"sync"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// leaderRedirects keeps connections to the leaders or primaries named by NOT_LEADER errors,
+// so requests rejected by a follower can be retried where they will be accepted
+type leaderRedirects struct {
+	dialOptions []grpc.DialOption
+	mutex       sync.Mutex
+	conns       map[string]*grpc.ClientConn
+}
+
+func newLeaderRedirects(dialOptions []grpc.DialOption) *leaderRedirects {
+	return &leaderRedirects{
+		dialOptions: dialOptions,
+		conns:       make(map[string]*grpc.ClientConn),
+	}
+}
+
+// client returns a client for the leader named by err, if err is a NOT_LEADER rejection that names one
+func (r *leaderRedirects) client(err error) (keyvalue.KeyValueServiceClient, bool) {
+	if r == nil || err == nil {
+		return nil, false
+	}
+	addr := leaderAddr(err)
+	if addr == "" {
+		return nil, false
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	conn, ok := r.conns[addr]
+	if !ok {
+		var dialErr error
+		conn, dialErr = grpc.NewClient(addr, r.dialOptions...)
+		if dialErr != nil {
+			return nil, false
+		}
+		r.conns[addr] = conn
+	}
+	return keyvalue.NewKeyValueServiceClient(conn), true
+}
+
+// Close closes every leader connection
+func (r *leaderRedirects) Close() error {
+	if r == nil {
+		return nil
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var errs []error
+	for addr, conn := range r.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close connection to %s: %w", addr, err))
+		}
+		delete(r.conns, addr)
+	}
+	return errors.Join(errs...)
+}
+
+// leaderAddr extracts the leader address from a NOT_LEADER status, empty when there is none
+func leaderAddr(err error) string {
+	st, ok := status.FromError(err)
+	if !ok {
+		return ""
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == errorDomain && info.Reason == "NOT_LEADER" {
+			return info.Metadata["leader"]
+		}
+	}
+	return ""
+}
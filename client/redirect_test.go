@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"key-value/proto/keyvalue"
+	"key-value/shared/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// redirectServer serves reads and either accepts writes or rejects them naming the primary
+type redirectServer struct {
+	keyvalue.UnimplementedKeyValueServiceServer
+	primaryAddr string
+	values      map[string]string
+}
+
+func (s *redirectServer) reject() error {
+	st, _ := status.New(codes.FailedPrecondition, "not the leader").WithDetails(&errdetails.ErrorInfo{
+		Reason:   "NOT_LEADER",
+		Domain:   errorDomain,
+		Metadata: map[string]string{"leader": s.primaryAddr},
+	})
+	return st.Err()
+}
+
+func (s *redirectServer) Get(ctx context.Context, req *keyvalue.GetRequest) (*keyvalue.GetResponse, error) {
+	value, ok := s.values[req.Key]
+	return &keyvalue.GetResponse{Value: value, Found: ok}, nil
+}
+
+func (s *redirectServer) Set(ctx context.Context, req *keyvalue.SetRequest) (*keyvalue.SetResponse, error) {
+	if s.primaryAddr != "" {
+		return nil, s.reject()
+	}
+	s.values[req.Key] = req.Value
+	return &keyvalue.SetResponse{Success: true}, nil
+}
+
+func (s *redirectServer) Delete(ctx context.Context, req *keyvalue.DeleteRequest) (*keyvalue.DeleteResponse, error) {
+	if s.primaryAddr != "" {
+		return nil, s.reject()
+	}
+	delete(s.values, req.Key)
+	return &keyvalue.DeleteResponse{Success: true}, nil
+}
+
+func startRedirectServer(t *testing.T, primaryAddr string) (*redirectServer, string) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := &redirectServer{primaryAddr: primaryAddr, values: map[string]string{}}
+	grpcServer := grpc.NewServer()
+	keyvalue.RegisterKeyValueServiceServer(grpcServer, srv)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	return srv, lis.Addr().String()
+}
+
+func TestKVStoreClient_FollowsRedirectToPrimary(t *testing.T) {
+	primary, primaryAddr := startRedirectServer(t, "")
+	follower, followerAddr := startRedirectServer(t, primaryAddr)
+	follower.values["replicated"] = "yes"
+
+	client, err := NewKVStoreClient(followerAddr)
+	require.NoError(t, err)
+	defer client.Close()
+	ctx := context.Background()
+
+	// Reads are served by the follower
+	value, found, err := client.Get(ctx, "replicated")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "yes", value)
+
+	// Writes are rejected by the follower and retried on the primary
+	require.NoError(t, client.Set(ctx, models.KeyValue{Key: "key", Value: "value"}))
+	assert.Equal(t, "value", primary.values["key"])
+	assert.NotContains(t, follower.values, "key")
+
+	require.NoError(t, client.Delete(ctx, "key"))
+	assert.NotContains(t, primary.values, "key")
+}
+
+func TestLeaderRedirects_RequireLeaderAddress(t *testing.T) {
+	redirects := newLeaderRedirects(newClientOptions().dialOptions())
+	defer redirects.Close()
+
+	// A follower that does not know the primary yet cannot be redirected
+	leaderless := &redirectServer{}
+	_, ok := redirects.client(leaderless.reject())
+	assert.False(t, ok)
+
+	_, ok = redirects.client(status.Error(codes.FailedPrecondition, "no details"))
+	assert.False(t, ok)
+
+	_, ok = redirects.client(nil)
+	assert.False(t, ok)
+
+	client, ok := redirects.client((&redirectServer{primaryAddr: "localhost:50051"}).reject())
+	assert.True(t, ok)
+	assert.NotNil(t, client)
+}
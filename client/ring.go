@@ -0,0 +1,98 @@
+package client
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// DefaultVirtualNodes is the number of points each shard gets on the hash ring
+const DefaultVirtualNodes = 128
+
+// WithVirtualNodes sets the number of points each shard gets on the hash ring of a ShardedClient.
+// More points spread keys more evenly at the cost of a larger ring. Every client sharing the
+// shards must use the same value, or they will disagree on where keys live.
+func WithVirtualNodes(n int) Option {
+	return func(o *clientOptions) {
+		o.virtualNodes = n
+	}
+}
+
+// hashRing assigns keys to shards with consistent hashing. Each shard is placed at several
+// virtual points, a key belongs to the first point at or after its hash, so adding or removing
+// a shard only moves the keys of that shard.
+type hashRing struct {
+	points []uint64 // sorted hashes of the virtual nodes
+	owners []int    // shard index owning each point
+}
+
+// newHashRing places shards by name, so a shard keeps its keys when others are added or removed
+func newHashRing(shards []string, virtualNodes int) *hashRing {
+	if virtualNodes <= 0 {
+		virtualNodes = DefaultVirtualNodes
+	}
+
+	type point struct {
+		hash  uint64
+		owner int
+	}
+	points := make([]point, 0, len(shards)*virtualNodes)
+	for i, shard := range shards {
+		for v := 0; v < virtualNodes; v++ {
+			points = append(points, point{hash: hashString(shard + "#" + strconv.Itoa(v)), owner: i})
+		}
+	}
+	sort.Slice(points, func(a, b int) bool { return points[a].hash < points[b].hash })
+
+	r := &hashRing{
+		points: make([]uint64, len(points)),
+		owners: make([]int, len(points)),
+	}
+	for i, p := range points {
+		r.points[i] = p.hash
+		r.owners[i] = p.owner
+	}
+	return r
+}
+
+// shard returns the index of the shard owning key
+func (r *hashRing) shard(key string) int {
+	hash := hashString(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= hash })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[i]
+}
+
+// hashString is FNV-1a followed by a 64 bit finalizer, which spreads similar strings such as
+// "shard#1" and "shard#2" across the ring. It must stay stable so every client agrees on placement.
+func hashString(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
+
+// preferenceList returns the first n distinct shards found walking the ring clockwise from key.
+// The first is the shard owning key, the others hold its replicas.
+func (r *hashRing) preferenceList(key string, n int) []int {
+	hash := hashString(key)
+	start := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= hash })
+
+	var shards []int
+	seen := make(map[int]bool, n)
+	for i := 0; i < len(r.points) && len(shards) < n; i++ {
+		owner := r.owners[(start+i)%len(r.points)]
+		if !seen[owner] {
+			seen[owner] = true
+			shards = append(shards, owner)
+		}
+	}
+	return shards
+}
@@ -0,0 +1,76 @@
+package client
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashRing_Distribution(t *testing.T) {
+	shards := []string{"kv-0:50051", "kv-1:50051", "kv-2:50051", "kv-3:50051"}
+	ring := newHashRing(shards, DefaultVirtualNodes)
+
+	counts := make([]int, len(shards))
+	const keys = 40000
+	for i := 0; i < keys; i++ {
+		counts[ring.shard(fmt.Sprintf("key-%d", i))]++
+	}
+
+	// Every shard gets its fair share within 20%
+	for i, count := range counts {
+		assert.InDelta(t, keys/len(shards), count, float64(keys/len(shards))*0.2, "shard %d", i)
+	}
+}
+
+func TestHashRing_Stable(t *testing.T) {
+	first := newHashRing([]string{"a", "b", "c"}, 64)
+	// The order of shards does not change placement
+	second := newHashRing([]string{"c", "a", "b"}, 64)
+	names := map[*hashRing][]string{first: {"a", "b", "c"}, second: {"c", "a", "b"}}
+
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		assert.Equal(t, names[first][first.shard(key)], names[second][second.shard(key)])
+	}
+}
+
+func TestHashRing_AddingShardMovesFewKeys(t *testing.T) {
+	before := []string{"kv-0", "kv-1", "kv-2"}
+	after := append(before, "kv-3")
+	oldRing := newHashRing(before, DefaultVirtualNodes)
+	newRing := newHashRing(after, DefaultVirtualNodes)
+
+	moved := 0
+	const keys = 20000
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		oldShard := before[oldRing.shard(key)]
+		newShard := after[newRing.shard(key)]
+		if oldShard != newShard {
+			// Keys only ever move to the new shard
+			assert.Equal(t, "kv-3", newShard)
+			moved++
+		}
+	}
+
+	// About a quarter of the keys move, instead of most of them with modulo hashing
+	assert.InDelta(t, keys/4, moved, float64(keys/4)*0.3)
+}
+
+func TestHashRing_PreferenceList(t *testing.T) {
+	ring := newHashRing([]string{"a", "b", "c", "d", "e"}, 16)
+
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		list := ring.preferenceList(key, 3)
+		assert.Len(t, list, 3)
+		assert.Equal(t, ring.shard(key), list[0])
+		assert.NotEqual(t, list[0], list[1])
+		assert.NotEqual(t, list[1], list[2])
+		assert.NotEqual(t, list[0], list[2])
+	}
+
+	// Asking for more replicas than shards returns every shard once
+	assert.ElementsMatch(t, []int{0, 1, 2, 3, 4}, ring.preferenceList("key", 10))
+}
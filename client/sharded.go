@@ -0,0 +1,352 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"key-value/shared/models"
+	"sort"
+	"sync"
+)
+
+// DefaultScanLimit is the number of pairs a sharded Scan returns when no limit is given,
+// matching the default of the key-value service
+const DefaultScanLimit = 100
+
+// ShardedClient partitions keys across several key-value services with a consistent hash ring.
+// Single key calls go to the shard owning the key; scans and batches fan out and merge.
+// The set of shards can be changed online with Rebalance.
+type ShardedClient struct {
+	options []Option
+	vnodes  int
+
+	mutex   sync.RWMutex // held for reading by every call, for writing when the topology changes
+	clients map[string]*KVStoreClient
+	current *topology
+	target  *topology // topology being moved to, nil when no rebalance is pending
+
+	keyLocks  [keyLockStripes]sync.Mutex
+	rebalance rebalanceRun
+}
+
+// topology is a set of shards and the ring placing keys on them
+type topology struct {
+	addrs []string
+	ring  *hashRing
+}
+
+func newTopology(addresses []string, virtualNodes int) *topology {
+	return &topology{addrs: addresses, ring: newHashRing(addresses, virtualNodes)}
+}
+
+// owner returns the address of the shard owning key
+func (t *topology) owner(key string) string {
+	return t.addrs[t.ring.shard(key)]
+}
+
+// NewShardedClient connects to every shard. Each address accepts the same forms as NewKVStoreClient,
+// so a shard can itself be a set of replicas. The options apply to every shard.
+// The order of addresses does not matter, but all clients must use the same set.
+func NewShardedClient(addresses []string, opts ...Option) (*ShardedClient, error) {
+	if len(addresses) == 0 {
+		return nil, errors.New("at least one shard address is required")
+	}
+	options := newClientOptions(opts...)
+
+	c := &ShardedClient{
+		options: opts,
+		vnodes:  options.virtualNodes,
+		clients: make(map[string]*KVStoreClient, len(addresses)),
+		current: newTopology(addresses, options.virtualNodes),
+	}
+	if err := c.connect(addresses); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// connect opens a client for every address that does not have one yet
+func (c *ShardedClient) connect(addresses []string) error {
+	for _, address := range addresses {
+		if _, ok := c.clients[address]; ok {
+			continue
+		}
+		shard, err := NewKVStoreClient(address, c.options...)
+		if err != nil {
+			return fmt.Errorf("failed to connect to shard %s: %w", address, err)
+		}
+		c.clients[address] = shard
+	}
+	return nil
+}
+
+// route returns the shard owning key. While a rebalance is pending and the key moves,
+// previous is the shard it moves away from, otherwise it is empty.
+func (c *ShardedClient) route(key string) (owner string, previous string) {
+	owner = c.current.owner(key)
+	if c.target != nil {
+		if next := c.target.owner(key); next != owner {
+			return next, owner
+		}
+	}
+	return owner, ""
+}
+
+// Get retrieves a value by key from its shard. While the key is being moved it is read from
+// the old shard first and from the new one when the old no longer has it.
+func (c *ShardedClient) Get(ctx context.Context, key string) (string, bool, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	owner, previous := c.route(key)
+	if previous != "" {
+		value, found, err := c.clients[previous].Get(ctx, key)
+		if err != nil || found {
+			return value, found, err
+		}
+	}
+	return c.clients[owner].Get(ctx, key)
+}
+
+// Set stores a key-value pair on its shard
+func (c *ShardedClient) Set(ctx context.Context, kv models.KeyValue) error {
+	return c.write(ctx, kv.Key, func(shard *KVStoreClient) error {
+		return shard.Set(ctx, kv)
+	})
+}
+
+// Delete removes a key-value pair from its shard
+func (c *ShardedClient) Delete(ctx context.Context, key string) error {
+	return c.write(ctx, key, func(shard *KVStoreClient) error {
+		return shard.Delete(ctx, key)
+	})
+}
+
+// Increment atomically adds delta to the integer stored at key on its shard
+func (c *ShardedClient) Increment(ctx context.Context, key string, delta int64) (int64, error) {
+	var value int64
+	err := c.write(ctx, key, func(shard *KVStoreClient) error {
+		var err error
+		value, err = shard.Increment(ctx, key, delta)
+		return err
+	})
+	return value, err
+}
+
+// write calls fn with the shard owning key. A key that is being moved is first moved to
+// its new shard, so the write never leaves an older value behind on the old one.
+func (c *ShardedClient) write(ctx context.Context, key string, fn func(shard *KVStoreClient) error) error {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	owner, previous := c.route(key)
+	if previous != "" {
+		unlock := c.lockKeys([]string{key})
+		defer unlock()
+		if _, err := c.moveKey(ctx, key, previous, owner); err != nil {
+			return err
+		}
+	}
+	return fn(c.clients[owner])
+}
+
+// Scan asks every shard for its first limit matching pairs and merges them in key order.
+// While a rebalance is pending the old shards are scanned before the new ones: a key is copied
+// to its new shard before it is deleted from the old one, so one of the two scans sees it.
+func (c *ShardedClient) Scan(ctx context.Context, prefix string, startAfter string, limit int) ([]models.KeyValue, bool, error) {
+	if limit <= 0 {
+		limit = DefaultScanLimit
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	phases := [][]string{c.current.addrs}
+	if c.target != nil {
+		phases = append(phases, c.target.addrs)
+	}
+
+	var mutex sync.Mutex
+	values := make(map[string]string)
+	more := false
+	for _, addrs := range phases {
+		err := c.run(addrs, func(addr string) error {
+			items, shardMore, err := c.clients[addr].Scan(ctx, prefix, startAfter, limit)
+			if err != nil {
+				return err
+			}
+			mutex.Lock()
+			defer mutex.Unlock()
+			for _, item := range items {
+				values[item.Key] = item.Value
+			}
+			more = more || shardMore
+			return nil
+		})
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	merged := make([]models.KeyValue, 0, len(values))
+	for key, value := range values {
+		merged = append(merged, models.KeyValue{Key: key, Value: value})
+	}
+	sort.Slice(merged, func(a, b int) bool { return merged[a].Key < merged[b].Key })
+	if len(merged) > limit {
+		merged = merged[:limit]
+		more = true
+	}
+	return merged, more, nil
+}
+
+// BatchGet groups the keys by shard, queries the shards in parallel and merges the results.
+// Keys that are being moved and were not found on their old shard are looked up on the new one.
+func (c *ShardedClient) BatchGet(ctx context.Context, keys []string) (map[string]string, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	values, err := c.batchGet(ctx, c.current, keys)
+	if err != nil || c.target == nil {
+		return values, err
+	}
+
+	var missing []string
+	for _, key := range keys {
+		if _, ok := values[key]; !ok && c.target.owner(key) != c.current.owner(key) {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return values, nil
+	}
+	moved, err := c.batchGet(ctx, c.target, missing)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range moved {
+		values[key] = value
+	}
+	return values, nil
+}
+
+func (c *ShardedClient) batchGet(ctx context.Context, t *topology, keys []string) (map[string]string, error) {
+	groups := make(map[string][]string)
+	for _, key := range keys {
+		owner := t.owner(key)
+		groups[owner] = append(groups[owner], key)
+	}
+
+	var mutex sync.Mutex
+	values := make(map[string]string, len(keys))
+	err := c.run(shardsOf(groups), func(addr string) error {
+		shardValues, err := c.clients[addr].BatchGet(ctx, groups[addr])
+		if err != nil {
+			return err
+		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		for key, value := range shardValues {
+			values[key] = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// BatchSet groups the pairs by shard and writes them in parallel. A failure on one shard
+// does not undo the writes on the others.
+func (c *ShardedClient) BatchSet(ctx context.Context, items []models.KeyValue) error {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	groups := make(map[string][]models.KeyValue)
+	var moving []string
+	for _, item := range items {
+		owner, previous := c.route(item.Key)
+		groups[owner] = append(groups[owner], item)
+		if previous != "" {
+			moving = append(moving, item.Key)
+		}
+	}
+
+	if len(moving) > 0 {
+		unlock := c.lockKeys(moving)
+		defer unlock()
+		for _, key := range moving {
+			owner, previous := c.route(key)
+			if _, err := c.moveKey(ctx, key, previous, owner); err != nil {
+				return err
+			}
+		}
+	}
+
+	return c.run(shardsOf(groups), func(addr string) error {
+		return c.clients[addr].BatchSet(ctx, groups[addr])
+	})
+}
+
+// Health checks every shard, including those a pending rebalance moves to.
+// The sharded client is only healthy when all of them are.
+func (c *ShardedClient) Health(ctx context.Context) error {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.run(c.shardAddrs(), func(addr string) error {
+		return c.clients[addr].Health(ctx)
+	})
+}
+
+// Close stops a running rebalance and closes the connections to every shard
+func (c *ShardedClient) Close() error {
+	c.rebalance.stop()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var errs []error
+	for _, shard := range c.clients {
+		errs = append(errs, shard.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// shardAddrs returns the address of every connected shard in a stable order
+func (c *ShardedClient) shardAddrs() []string {
+	addrs := make([]string, 0, len(c.clients))
+	for addr := range c.clients {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+// shardsOf returns the shards that have work in groups
+func shardsOf[T any](groups map[string][]T) []string {
+	shards := make([]string, 0, len(groups))
+	for shard := range groups {
+		shards = append(shards, shard)
+	}
+	return shards
+}
+
+// run calls fn for each shard in parallel and joins the errors, naming the failing shards
+func (c *ShardedClient) run(shards []string, fn func(addr string) error) error {
+	errs := make([]error, len(shards))
+	var wg sync.WaitGroup
+	for i, shard := range shards {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(shard); err != nil {
+				errs[i] = fmt.Errorf("shard %s: %w", shard, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,232 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"key-value/proto/keyvalue"
+	"key-value/shared/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// memoryServer is a small in-memory key-value service standing in for one shard
+type memoryServer struct {
+	keyvalue.UnimplementedKeyValueServiceServer
+	mutex  sync.Mutex
+	values map[string]string
+}
+
+func (s *memoryServer) Get(ctx context.Context, req *keyvalue.GetRequest) (*keyvalue.GetResponse, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	value, ok := s.values[req.Key]
+	return &keyvalue.GetResponse{Value: value, Found: ok}, nil
+}
+
+func (s *memoryServer) Set(ctx context.Context, req *keyvalue.SetRequest) (*keyvalue.SetResponse, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, exists := s.values[req.Key]; exists && req.IfAbsent {
+		return nil, status.Error(codes.Aborted, "key exists")
+	}
+	s.values[req.Key] = req.Value
+	return &keyvalue.SetResponse{Success: true}, nil
+}
+
+func (s *memoryServer) Delete(ctx context.Context, req *keyvalue.DeleteRequest) (*keyvalue.DeleteResponse, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if value, exists := s.values[req.Key]; req.IfValue != nil && (!exists || value != *req.IfValue) {
+		return nil, status.Error(codes.Aborted, "value differs")
+	}
+	delete(s.values, req.Key)
+	return &keyvalue.DeleteResponse{Success: true}, nil
+}
+
+func (s *memoryServer) Increment(ctx context.Context, req *keyvalue.IncrementRequest) (*keyvalue.IncrementResponse, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	current, _ := strconv.ParseInt(s.values[req.Key], 10, 64)
+	s.values[req.Key] = strconv.FormatInt(current+req.Delta, 10)
+	return &keyvalue.IncrementResponse{Value: current + req.Delta}, nil
+}
+
+func (s *memoryServer) Scan(ctx context.Context, req *keyvalue.ScanRequest) (*keyvalue.ScanResponse, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var keys []string
+	for key := range s.values {
+		if strings.HasPrefix(key, req.Prefix) && key > req.StartAfter {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	resp := &keyvalue.ScanResponse{More: len(keys) > int(req.Limit)}
+	for _, key := range keys[:min(len(keys), int(req.Limit))] {
+		resp.Items = append(resp.Items, &keyvalue.KeyValuePair{Key: key, Value: s.values[key]})
+	}
+	return resp, nil
+}
+
+func (s *memoryServer) BatchGet(ctx context.Context, req *keyvalue.BatchGetRequest) (*keyvalue.BatchGetResponse, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	resp := &keyvalue.BatchGetResponse{}
+	for _, key := range req.Keys {
+		if value, ok := s.values[key]; ok {
+			resp.Items = append(resp.Items, &keyvalue.KeyValuePair{Key: key, Value: value})
+		}
+	}
+	return resp, nil
+}
+
+func (s *memoryServer) BatchSet(ctx context.Context, req *keyvalue.BatchSetRequest) (*keyvalue.BatchSetResponse, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, item := range req.Items {
+		s.values[item.Key] = item.Value
+	}
+	return &keyvalue.BatchSetResponse{}, nil
+}
+
+func (s *memoryServer) Health(ctx context.Context, req *keyvalue.HealthRequest) (*keyvalue.HealthResponse, error) {
+	return &keyvalue.HealthResponse{Status: "healthy"}, nil
+}
+
+func startShards(t *testing.T, n int) ([]*memoryServer, []string) {
+	t.Helper()
+	var servers []*memoryServer
+	var addrs []string
+	for i := 0; i < n; i++ {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		srv := &memoryServer{values: map[string]string{}}
+		grpcServer := grpc.NewServer()
+		keyvalue.RegisterKeyValueServiceServer(grpcServer, srv)
+		go grpcServer.Serve(lis)
+		t.Cleanup(grpcServer.Stop)
+
+		servers = append(servers, srv)
+		addrs = append(addrs, lis.Addr().String())
+	}
+	return servers, addrs
+}
+
+func TestShardedClient_RoutesKeysToOneShard(t *testing.T) {
+	servers, addrs := startShards(t, 3)
+	client, err := NewShardedClient(addrs)
+	require.NoError(t, err)
+	defer client.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 60; i++ {
+		require.NoError(t, client.Set(ctx, models.KeyValue{Key: fmt.Sprintf("key-%d", i), Value: fmt.Sprint(i)}))
+	}
+
+	// Every key lives on exactly one shard and every shard holds some keys
+	total := 0
+	for _, srv := range servers {
+		assert.NotEmpty(t, srv.values)
+		total += len(srv.values)
+	}
+	assert.Equal(t, 60, total)
+
+	value, found, err := client.Get(ctx, "key-42")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "42", value)
+
+	next, err := client.Increment(ctx, "key-42", 8)
+	require.NoError(t, err)
+	assert.Equal(t, int64(50), next)
+
+	require.NoError(t, client.Delete(ctx, "key-42"))
+	_, found, err = client.Get(ctx, "key-42")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	assert.NoError(t, client.Health(ctx))
+}
+
+func TestShardedClient_ScanMergesShards(t *testing.T) {
+	_, addrs := startShards(t, 3)
+	client, err := NewShardedClient(addrs)
+	require.NoError(t, err)
+	defer client.Close()
+	ctx := context.Background()
+
+	var expected []string
+	for i := 0; i < 25; i++ {
+		key := fmt.Sprintf("user:%02d", i)
+		expected = append(expected, key)
+		require.NoError(t, client.Set(ctx, models.KeyValue{Key: key, Value: "v"}))
+	}
+	require.NoError(t, client.Set(ctx, models.KeyValue{Key: "order:1", Value: "v"}))
+
+	// Page through all users 10 at a time
+	var scanned []string
+	startAfter := ""
+	for {
+		items, more, err := client.Scan(ctx, "user:", startAfter, 10)
+		require.NoError(t, err)
+		for _, item := range items {
+			scanned = append(scanned, item.Key)
+		}
+		if !more {
+			break
+		}
+		startAfter = items[len(items)-1].Key
+	}
+	assert.Equal(t, expected, scanned)
+}
+
+func TestShardedClient_Batches(t *testing.T) {
+	servers, addrs := startShards(t, 3)
+	client, err := NewShardedClient(addrs, WithVirtualNodes(16))
+	require.NoError(t, err)
+	defer client.Close()
+	ctx := context.Background()
+
+	var items []models.KeyValue
+	var keys []string
+	for i := 0; i < 30; i++ {
+		items = append(items, models.KeyValue{Key: fmt.Sprintf("k%d", i), Value: fmt.Sprint(i)})
+		keys = append(keys, fmt.Sprintf("k%d", i))
+	}
+	require.NoError(t, client.BatchSet(ctx, items))
+
+	total := 0
+	for _, srv := range servers {
+		total += len(srv.values)
+	}
+	assert.Equal(t, 30, total)
+
+	values, err := client.BatchGet(ctx, append(keys, "missing"))
+	require.NoError(t, err)
+	assert.Len(t, values, 30)
+	assert.Equal(t, "7", values["k7"])
+	assert.NotContains(t, values, "missing")
+}
+
+func TestShardedClient_ReportsFailingShard(t *testing.T) {
+	_, addrs := startShards(t, 2)
+	addrs = append(addrs, "127.0.0.1:1")
+	client, err := NewShardedClient(addrs)
+	require.NoError(t, err)
+	defer client.Close()
+
+	err = client.Health(context.Background())
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Contains(t, err.Error(), "127.0.0.1:1")
+}
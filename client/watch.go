@@ -0,0 +1,36 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"key-value/proto/keyvalue"
+)
+
+// KeyEvent is the value of a watched key after a change, Op is ChangeSet or ChangeDelete
+type KeyEvent struct {
+	Op    keyvalue.MutationOp
+	Key   string
+	Value string
+}
+
+// Watch passes the current value of key to handle, a ChangeDelete when it does not exist, and then every change of
+// it in order. It runs until ctx is done or handle fails, and fails with ErrWatchLost when the service dropped the
+// watch because it fell behind. Streams are not bounded by the default timeout.
+func (c *KVStoreClient) Watch(ctx context.Context, key string, handle func(KeyEvent) error) error {
+	stream, err := keyvalue.NewWatchServiceClient(c.conn).Watch(ctx, &keyvalue.WatchRequest{Key: key})
+	if err != nil {
+		return fmt.Errorf("failed to watch %s: %w", key, translateError(err))
+	}
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("failed to watch %s: %w", key, translateError(err))
+		}
+		if err := handle(KeyEvent{Op: event.Op, Key: event.Key, Value: event.Value}); err != nil {
+			return err
+		}
+	}
+}
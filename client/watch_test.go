@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"key-value/proto/keyvalue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// keyWatcher is an in-process watch service sending a fixed list of events, then dropping the watch
+type keyWatcher struct {
+	keyvalue.UnimplementedWatchServiceServer
+	events []*keyvalue.WatchEvent
+}
+
+func (w *keyWatcher) Watch(req *keyvalue.WatchRequest, stream keyvalue.WatchService_WatchServer) error {
+	for _, event := range w.events {
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+	}
+	st, _ := status.New(codes.Aborted, "watcher fell behind").WithDetails(&errdetails.ErrorInfo{Reason: "WATCH_LOST", Domain: errorDomain})
+	return st.Err()
+}
+
+func TestKVStoreClient_Watch(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	keyvalue.RegisterWatchServiceServer(server, &keyWatcher{events: []*keyvalue.WatchEvent{
+		{Op: ChangeDelete, Key: "leader"},
+		{Op: ChangeSet, Key: "leader", Value: "a"},
+		{Op: ChangeDelete, Key: "leader"},
+	}})
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	client, err := NewKVStoreClient(lis.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+
+	var seen []KeyEvent
+	err = client.Watch(context.Background(), "leader", func(event KeyEvent) error {
+		seen = append(seen, event)
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrWatchLost)
+	assert.Equal(t, []KeyEvent{
+		{Op: ChangeDelete, Key: "leader"},
+		{Op: ChangeSet, Key: "leader", Value: "a"},
+		{Op: ChangeDelete, Key: "leader"},
+	}, seen)
+
+	// Handler errors stop the watch
+	err = client.Watch(context.Background(), "leader", func(event KeyEvent) error {
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop)
+}
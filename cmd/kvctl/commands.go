@@ -0,0 +1,440 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"key-value/client"
+	"key-value/shared/models"
+	"key-value/shared/pairs"
+	"os"
+	"time"
+)
+
+// scanPageSize is how many keys scan and stats read per request
+const scanPageSize = 500
+
+// store is what the commands need from the key-value service. client.KVStoreClient implements it over gRPC and
+// gatewayClient over the REST gateway.
+type store interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, kv models.KeyValue) error
+	Delete(ctx context.Context, key string) error
+	Scan(ctx context.Context, prefix string, startAfter string, limit int) ([]models.KeyValue, bool, error)
+	Export(ctx context.Context, prefix string, handle func(models.KeyValue) error) error
+	Import(ctx context.Context, mode client.ImportMode, next func() (models.KeyValue, error)) (client.ImportResult, error)
+	Health(ctx context.Context) error
+	Close() error
+}
+
+// watcher is implemented by stores that can watch keys, which the gateway cannot
+type watcher interface {
+	Watch(ctx context.Context, key string, handle func(client.KeyEvent) error) error
+}
+
+// backuper is implemented by stores that can back up and restore the whole store, which the gateway cannot
+type backuper interface {
+	Backup(ctx context.Context, w io.Writer) error
+	Restore(ctx context.Context, mode client.RestoreMode, r io.Reader) (client.RestoreResult, error)
+}
+
+// errNotFound is returned by get for a missing key, so kvctl exits with a failure
+var errNotFound = errors.New("key not found")
+
+// env is what a command runs with
+type env struct {
+	store  store
+	out    *printer
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	usage  string // of the command running
+}
+
+// command runs a subcommand with its arguments
+type command struct {
+	usage string
+	help  string
+	run   func(ctx context.Context, e *env, args []string) error
+}
+
+var commands = map[string]command{
+	"get":     {"get <key>", "print the value of a key", get},
+	"set":     {"set <key> <value|->", "store a value, - reads it from standard input", set},
+	"del":     {"del <key>...", "delete keys", del},
+	"scan":    {"scan [-prefix p] [-start-after k] [-limit n]", "list keys and values in order", scan},
+	"watch":   {"watch <key>", "print the value of a key and every change of it, gRPC only", watch},
+	"import":  {"import [-format f] [-mode m] [file]", "store the key-value pairs of an NDJSON or CSV file or standard input", importPairs},
+	"export":  {"export [-prefix p] [-format f] [file]", "write the key-value pairs as NDJSON or CSV to a file or standard output", exportPairs},
+	"backup":  {"backup [file]", "write a backup file of the whole store, gRPC only", backupStore},
+	"restore": {"restore [-mode m] [file]", "load a backup file, replacing the store or merging into it, gRPC only", restoreStore},
+	"health":  {"health", "check that the service is healthy", health},
+	"stats":   {"stats [-prefix p]", "count the keys and their sizes", stats},
+}
+
+// commandOrder lists the commands in the usage text
+var commandOrder = []string{"get", "set", "del", "scan", "watch", "import", "export", "backup", "restore", "health", "stats"}
+
+// flags creates the flag set of the command, printing errors to the standard error of e
+func (e *env) flags(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(e.stderr, "usage: kvctl %s\n", e.usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// usageError reports that the command was called with the wrong arguments
+func (e *env) usageError() error {
+	return fmt.Errorf("usage: kvctl %s", e.usage)
+}
+
+// exactArgs fails unless there are n arguments
+func (e *env) exactArgs(args []string, n int) error {
+	if len(args) != n {
+		return e.usageError()
+	}
+	return nil
+}
+
+func get(ctx context.Context, e *env, args []string) error {
+	if err := e.exactArgs(args, 1); err != nil {
+		return err
+	}
+	value, found, err := e.store.Get(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%w: %s", errNotFound, args[0])
+	}
+	return e.out.value(models.KeyValue{Key: args[0], Value: value})
+}
+
+func set(ctx context.Context, e *env, args []string) error {
+	if err := e.exactArgs(args, 2); err != nil {
+		return err
+	}
+	value := args[1]
+	if value == "-" {
+		data, err := io.ReadAll(e.stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read the value: %w", err)
+		}
+		value = string(data)
+	}
+	return e.store.Set(ctx, models.KeyValue{Key: args[0], Value: value})
+}
+
+func del(ctx context.Context, e *env, args []string) error {
+	if len(args) == 0 {
+		return e.usageError()
+	}
+	for _, key := range args {
+		if err := e.store.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scan(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("scan")
+	prefix := fs.String("prefix", "", "only keys starting with prefix")
+	startAfter := fs.String("start-after", "", "only keys after this one")
+	limit := fs.Int("limit", 0, "most keys to list, 0 for all")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := e.exactArgs(fs.Args(), 0); err != nil {
+		return err
+	}
+
+	var items []models.KeyValue
+	err := scanAll(ctx, e.store, *prefix, *startAfter, *limit, func(page []models.KeyValue) error {
+		items = append(items, page...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return e.out.pairs(items)
+}
+
+func watch(ctx context.Context, e *env, args []string) error {
+	if err := e.exactArgs(args, 1); err != nil {
+		return err
+	}
+	w, ok := e.store.(watcher)
+	if !ok {
+		return errors.New("watch needs a gRPC address, the gateway cannot watch keys")
+	}
+	err := w.Watch(ctx, args[0], e.out.event)
+	if ctx.Err() != nil {
+		// Interrupted
+		return nil
+	}
+	return err
+}
+
+// importModes maps the -mode flag of import to the import modes
+var importModes = map[string]client.ImportMode{
+	"overwrite":        client.ImportOverwrite,
+	"skip-existing":    client.ImportSkipExisting,
+	"fail-on-conflict": client.ImportFailOnConflict,
+}
+
+// importModeNames names the import modes the way the gateway expects them
+var importModeNames = map[client.ImportMode]string{
+	client.ImportOverwrite:      "overwrite",
+	client.ImportSkipExisting:   "skip-existing",
+	client.ImportFailOnConflict: "fail-on-conflict",
+}
+
+// importPairs streams the pairs of an NDJSON or CSV file to the store
+func importPairs(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("import")
+	formatName := fs.String("format", "ndjson", "format of the input, ndjson or csv")
+	modeName := fs.String("mode", "overwrite", "what to do with existing keys, overwrite, skip-existing or fail-on-conflict")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	format, err := pairs.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+	mode, ok := importModes[*modeName]
+	if !ok {
+		return fmt.Errorf("unknown mode %q, expected overwrite, skip-existing or fail-on-conflict", *modeName)
+	}
+	if fs.NArg() > 1 {
+		return e.usageError()
+	}
+	input := e.stdin
+	if fs.NArg() == 1 && fs.Arg(0) != "-" {
+		file, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	result, err := e.store.Import(ctx, mode, pairs.NewReader(input, format).Read)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stderr, "imported %d pairs, skipped %d\n", result.Written, result.Skipped)
+	return nil
+}
+
+// exportPairs writes the pairs as NDJSON or CSV whatever the output format, so import can read them back
+func exportPairs(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("export")
+	prefix := fs.String("prefix", "", "only keys starting with prefix")
+	formatName := fs.String("format", "ndjson", "format of the output, ndjson or csv")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	format, err := pairs.ParseFormat(*formatName)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return e.usageError()
+	}
+	output := e.stdout
+	var file *os.File
+	if fs.NArg() == 1 && fs.Arg(0) != "-" {
+		if file, err = os.Create(fs.Arg(0)); err != nil {
+			return err
+		}
+		defer file.Close()
+		output = file
+	}
+
+	writer := pairs.NewWriter(output, format)
+	exported := 0
+	err = e.store.Export(ctx, *prefix, func(kv models.KeyValue) error {
+		exported++
+		return writer.Write(kv)
+	})
+	if err != nil {
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	if file != nil {
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(e.stderr, "exported %d pairs\n", exported)
+	return nil
+}
+
+// restoreModes maps the -mode flag of restore to the restore modes
+var restoreModes = map[string]client.RestoreMode{
+	"replace": client.RestoreReplace,
+	"merge":   client.RestoreMerge,
+}
+
+// backupStore writes a backup file, removing what was written of it when the backup fails
+func backupStore(ctx context.Context, e *env, args []string) (err error) {
+	fs := e.flags("backup")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		return e.usageError()
+	}
+	b, ok := e.store.(backuper)
+	if !ok {
+		return errors.New("backup needs a gRPC address, the gateway cannot take backups")
+	}
+	output := e.stdout
+	if fs.NArg() == 1 && fs.Arg(0) != "-" {
+		file, err := os.Create(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				os.Remove(file.Name())
+			}
+		}()
+		output = file
+	}
+
+	counter := &countingWriter{w: output}
+	if err := b.Backup(ctx, counter); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stderr, "backed up %d bytes\n", counter.n)
+	return nil
+}
+
+// restoreStore loads a backup file
+func restoreStore(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("restore")
+	modeName := fs.String("mode", "replace", "replace to swap the whole store for the backup, merge to write it over the current data")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	mode, ok := restoreModes[*modeName]
+	if !ok {
+		return fmt.Errorf("unknown mode %q, expected replace or merge", *modeName)
+	}
+	if fs.NArg() > 1 {
+		return e.usageError()
+	}
+	b, ok := e.store.(backuper)
+	if !ok {
+		return errors.New("restore needs a gRPC address, the gateway cannot restore backups")
+	}
+	input := e.stdin
+	if fs.NArg() == 1 && fs.Arg(0) != "-" {
+		file, err := os.Open(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	}
+
+	result, err := b.Restore(ctx, mode, input)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stderr, "restored %d pairs from a backup taken %s\n", result.Pairs, result.Created.Format(time.RFC3339))
+	return nil
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func health(ctx context.Context, e *env, args []string) error {
+	if err := e.exactArgs(args, 0); err != nil {
+		return err
+	}
+	if err := e.store.Health(ctx); err != nil {
+		return err
+	}
+	return e.out.fields([]field{{"status", "healthy"}})
+}
+
+// stats scans the keys and reports how many there are and how large they are
+func stats(ctx context.Context, e *env, args []string) error {
+	fs := e.flags("stats")
+	prefix := fs.String("prefix", "", "only keys starting with prefix")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if err := e.exactArgs(fs.Args(), 0); err != nil {
+		return err
+	}
+
+	var keys, keyBytes, valueBytes, largest int
+	var largestKey string
+	err := scanAll(ctx, e.store, *prefix, "", 0, func(page []models.KeyValue) error {
+		for _, kv := range page {
+			keys++
+			keyBytes += len(kv.Key)
+			valueBytes += len(kv.Value)
+			if largestKey == "" || len(kv.Value) > largest {
+				largest, largestKey = len(kv.Value), kv.Key
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return e.out.fields([]field{
+		{"keys", keys},
+		{"key_bytes", keyBytes},
+		{"value_bytes", valueBytes},
+		{"largest_value_bytes", largest},
+		{"largest_value_key", largestKey},
+	})
+}
+
+// scanAll passes the pages of keys with prefix after startAfter to handle, stopping after limit keys unless it is 0
+func scanAll(ctx context.Context, s store, prefix string, startAfter string, limit int, handle func([]models.KeyValue) error) error {
+	seen := 0
+	for {
+		size := scanPageSize
+		if limit > 0 {
+			size = min(size, limit-seen)
+		}
+		page, more, err := s.Scan(ctx, prefix, startAfter, size)
+		if err != nil {
+			return err
+		}
+		if err := handle(page); err != nil {
+			return err
+		}
+		seen += len(page)
+		if !more || len(page) == 0 || (limit > 0 && seen >= limit) {
+			return nil
+		}
+		startAfter = page[len(page)-1].Key
+	}
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"key-value/client"
+	"key-value/shared/models"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore keeps pairs in memory
+type fakeStore struct {
+	data map[string]string
+}
+
+func newFakeStore(pairs ...string) *fakeStore {
+	s := &fakeStore{data: make(map[string]string)}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		s.data[pairs[i]] = pairs[i+1]
+	}
+	return s
+}
+
+func (s *fakeStore) Get(_ context.Context, key string) (string, bool, error) {
+	value, ok := s.data[key]
+	return value, ok, nil
+}
+
+func (s *fakeStore) Set(_ context.Context, kv models.KeyValue) error {
+	s.data[kv.Key] = kv.Value
+	return nil
+}
+
+func (s *fakeStore) Delete(_ context.Context, key string) error {
+	delete(s.data, key)
+	return nil
+}
+
+func (s *fakeStore) Scan(_ context.Context, prefix string, startAfter string, limit int) ([]models.KeyValue, bool, error) {
+	var keys []string
+	for key := range s.data {
+		if strings.HasPrefix(key, prefix) && key > startAfter {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	more := limit > 0 && len(keys) > limit
+	if more {
+		keys = keys[:limit]
+	}
+	items := make([]models.KeyValue, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, models.KeyValue{Key: key, Value: s.data[key]})
+	}
+	return items, more, nil
+}
+
+func (s *fakeStore) Export(ctx context.Context, prefix string, handle func(models.KeyValue) error) error {
+	items, _, _ := s.Scan(ctx, prefix, "", 0)
+	for _, kv := range items {
+		if err := handle(kv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *fakeStore) Import(_ context.Context, mode client.ImportMode, next func() (models.KeyValue, error)) (client.ImportResult, error) {
+	var result client.ImportResult
+	for {
+		kv, err := next()
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		if err != nil {
+			return result, err
+		}
+		current, exists := s.data[kv.Key]
+		switch {
+		case !exists || mode == client.ImportOverwrite:
+			s.data[kv.Key] = kv.Value
+			result.Written++
+		case mode == client.ImportFailOnConflict && current != kv.Value:
+			return result, client.ErrConflict
+		default:
+			result.Skipped++
+		}
+	}
+}
+
+func (s *fakeStore) Health(context.Context) error { return nil }
+
+func (s *fakeStore) Close() error { return nil }
+
+// watchingStore is a fakeStore that can watch keys, replaying events
+type watchingStore struct {
+	*fakeStore
+	events []client.KeyEvent
+}
+
+func (s *watchingStore) Watch(ctx context.Context, key string, handle func(client.KeyEvent) error) error {
+	for _, event := range s.events {
+		if err := handle(event); err != nil {
+			return err
+		}
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// backingStore is a fakeStore that backs up to a JSON object and restores from one
+type backingStore struct {
+	*fakeStore
+}
+
+func (s *backingStore) Backup(_ context.Context, w io.Writer) error {
+	return json.NewEncoder(w).Encode(s.data)
+}
+
+func (s *backingStore) Restore(_ context.Context, mode client.RestoreMode, r io.Reader) (client.RestoreResult, error) {
+	var data map[string]string
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return client.RestoreResult{}, client.ErrInvalidBackup
+	}
+	if mode == client.RestoreReplace {
+		s.data = make(map[string]string)
+	}
+	for key, value := range data {
+		s.data[key] = value
+	}
+	return client.RestoreResult{Pairs: len(data), Created: time.Unix(1700000000, 0)}, nil
+}
+
+// execute runs a command against s and returns its standard output
+func execute(t *testing.T, ctx context.Context, s store, format string, stdin string, args ...string) (string, error) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	cmd, ok := commands[args[0]]
+	require.True(t, ok, "unknown command %s", args[0])
+	e := &env{
+		store:  s,
+		out:    &printer{w: &stdout, format: format},
+		stdin:  strings.NewReader(stdin),
+		stdout: &stdout,
+		stderr: &stderr,
+		usage:  cmd.usage,
+	}
+	err := cmd.run(ctx, e, args[1:])
+	return stdout.String(), err
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		stdin  string
+		args   []string
+		want   string
+		err    string
+	}{
+		{"get plain", outputPlain, "", []string{"get", "a"}, "1\n", ""},
+		{"get json", outputJSON, "", []string{"get", "a"}, `{"key":"a","value":"1"}` + "\n", ""},
+		{"get table", outputTable, "", []string{"get", "a"}, "KEY  VALUE\na    1\n", ""},
+		{"get missing", outputPlain, "", []string{"get", "missing"}, "", "key not found: missing"},
+		{"get without key", outputPlain, "", []string{"get"}, "", "usage: kvctl get <key>"},
+		{"scan plain", outputPlain, "", []string{"scan", "-prefix", "user:"}, "user:1\talice\nuser:2\tbob\n", ""},
+		{"scan limit", outputPlain, "", []string{"scan", "-limit", "1"}, "a\t1\n", ""},
+		{"scan start after", outputPlain, "", []string{"scan", "-start-after", "user:1"}, "user:2\tbob\n", ""},
+		{"scan json", outputJSON, "", []string{"scan", "-prefix", "none"}, "[]\n", ""},
+		{"scan table", outputTable, "", []string{"scan", "-prefix", "user:"}, "KEY     VALUE\nuser:1  alice\nuser:2  bob\n", ""},
+		{"scan bad flag", outputPlain, "", []string{"scan", "-bogus"}, "", "flag provided but not defined: -bogus"},
+		{"health", outputPlain, "", []string{"health"}, "status: healthy\n", ""},
+		{"health json", outputJSON, "", []string{"health"}, `{"status":"healthy"}` + "\n", ""},
+		{"stats", outputPlain, "", []string{"stats"},
+			"keys: 3\nkey_bytes: 13\nvalue_bytes: 9\nlargest_value_bytes: 5\nlargest_value_key: user:1\n", ""},
+		{"watch through gateway", outputPlain, "", []string{"watch", "a"}, "", "watch needs a gRPC address"},
+		{"backup through gateway", outputPlain, "", []string{"backup"}, "", "backup needs a gRPC address"},
+		{"restore through gateway", outputPlain, "", []string{"restore"}, "", "restore needs a gRPC address"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newFakeStore("a", "1", "user:1", "alice", "user:2", "bob")
+			got, err := execute(t, context.Background(), s, tt.format, tt.stdin, tt.args...)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCommands_Writes(t *testing.T) {
+	s := newFakeStore()
+	ctx := context.Background()
+
+	_, err := execute(t, ctx, s, outputPlain, "", "set", "a", "1")
+	require.NoError(t, err)
+	_, err = execute(t, ctx, s, outputPlain, "line one\nline two\n", "set", "b", "-")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "1", "b": "line one\nline two\n"}, s.data)
+
+	_, err = execute(t, ctx, s, outputPlain, "", "del", "a", "b", "missing")
+	require.NoError(t, err)
+	assert.Empty(t, s.data)
+	_, err = execute(t, ctx, s, outputPlain, "", "del")
+	assert.EqualError(t, err, "usage: kvctl del <key>...")
+}
+
+func TestCommands_Watch(t *testing.T) {
+	s := &watchingStore{fakeStore: newFakeStore(), events: []client.KeyEvent{
+		{Op: client.ChangeDelete, Key: "a"},
+		{Op: client.ChangeSet, Key: "a", Value: "1"},
+	}}
+
+	for format, want := range map[string]string{
+		outputPlain: "DELETE\ta\nSET\ta\t1\n",
+		outputJSON:  `{"op":"delete","key":"a"}` + "\n" + `{"op":"set","key":"a","value":"1"}` + "\n",
+	} {
+		t.Run(format, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			var got string
+			var err error
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				got, err = execute(t, ctx, s, format, "", "watch", "a")
+			}()
+			cancel()
+			<-done
+			require.NoError(t, err, "an interrupted watch succeeds")
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
+func TestCommands_ImportExport(t *testing.T) {
+	ctx := context.Background()
+	source := newFakeStore("a", "1", "b", "two words", "c", `{"json":"value"}`)
+	source.data["d"] = "line one\nline two"
+
+	for _, format := range []string{"ndjson", "csv"} {
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "export."+format)
+			_, err := execute(t, ctx, source, outputTable, "", "export", "-format", format, path)
+			require.NoError(t, err)
+
+			target := newFakeStore()
+			_, err = execute(t, ctx, target, outputPlain, "", "import", "-format", format, path)
+			require.NoError(t, err)
+			assert.Equal(t, source.data, target.data)
+		})
+	}
+
+	// Standard input and output work the same way
+	stdout, err := execute(t, ctx, source, outputPlain, "", "export", "-prefix", "a")
+	require.NoError(t, err)
+	assert.Equal(t, `{"key":"a","value":"1"}`+"\n", stdout)
+	target := newFakeStore("a", "old", "b", "2")
+	_, err = execute(t, ctx, target, outputPlain, stdout+"{\"key\":\"c\",\"value\":\"3\"}\n", "import", "-mode", "skip-existing")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "old", "b": "2", "c": "3"}, target.data)
+
+	_, err = execute(t, ctx, target, outputPlain, "key,value\nb,other\n", "import", "-format", "csv", "-mode", "fail-on-conflict")
+	assert.ErrorIs(t, err, client.ErrConflict)
+	_, err = execute(t, ctx, target, outputPlain, "{\"key\":\"a\"}\nnot json\n", "import")
+	assert.ErrorContains(t, err, "line 2")
+	_, err = execute(t, ctx, target, outputPlain, `{"value":"1"}`, "import")
+	assert.EqualError(t, err, "line 1: malformed input: key is required")
+	_, err = execute(t, ctx, target, outputPlain, "", "import", "-mode", "merge")
+	assert.EqualError(t, err, `unknown mode "merge", expected overwrite, skip-existing or fail-on-conflict`)
+	_, err = execute(t, ctx, target, outputPlain, "", "export", "-format", "xml")
+	assert.EqualError(t, err, `unknown format "xml", expected ndjson or csv`)
+}
+
+func TestCommands_BackupRestore(t *testing.T) {
+	ctx := context.Background()
+	source := &backingStore{newFakeStore("a", "1", "b", "2")}
+	path := filepath.Join(t.TempDir(), "store.backup")
+	_, err := execute(t, ctx, source, outputPlain, "", "backup", path)
+	require.NoError(t, err)
+
+	target := &backingStore{newFakeStore("a", "old", "c", "3")}
+	_, err = execute(t, ctx, target, outputPlain, "", "restore", "-mode", "merge", path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2", "c": "3"}, target.data)
+
+	// Standard input and output work the same way
+	stdout, err := execute(t, ctx, source, outputPlain, "", "backup")
+	require.NoError(t, err)
+	_, err = execute(t, ctx, target, outputPlain, stdout, "restore")
+	require.NoError(t, err)
+	assert.Equal(t, source.data, target.data)
+
+	_, err = execute(t, ctx, target, outputPlain, "damaged", "restore")
+	assert.ErrorIs(t, err, client.ErrInvalidBackup)
+	_, err = execute(t, ctx, target, outputPlain, "", "restore", "-mode", "append")
+	assert.EqualError(t, err, `unknown mode "append", expected replace or merge`)
+}
+
+func TestScanAll_Pages(t *testing.T) {
+	s := newFakeStore()
+	for i := 0; i < scanPageSize+10; i++ {
+		s.data[strings.Repeat("k", i+1)] = "v"
+	}
+
+	pages := 0
+	seen := 0
+	err := scanAll(context.Background(), s, "", "", 0, func(page []models.KeyValue) error {
+		pages++
+		seen += len(page)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, pages)
+	assert.Equal(t, scanPageSize+10, seen)
+
+	stop := errors.New("stop")
+	err = scanAll(context.Background(), s, "", "", 0, func([]models.KeyValue) error { return stop })
+	assert.ErrorIs(t, err, stop)
+}
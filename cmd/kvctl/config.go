@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Output formats
+const (
+	outputPlain = "plain"
+	outputJSON  = "json"
+	outputTable = "table"
+)
+
+// Config is where kvctl connects to and how it prints results
+type Config struct {
+	Addr    string        // gRPC address of the key-value service, in any form client.NewKVStoreClient takes
+	Gateway string        // URL of the REST gateway, used instead of Addr when set
+	APIKey  string        // API key sent to the gateway
+	TLS     bool          // Connect to Addr over TLS with the system roots
+	Output  string        // plain, json or table
+	Timeout time.Duration // Bound on each request, 0 for none
+}
+
+// setting is a configuration value taken from a flag, else the environment, else the profile, else its default
+type setting struct {
+	name    string // flag name and profile field
+	env     string
+	def     string
+	usage   string
+	boolean bool // the flag can be given without a value
+}
+
+// settingFlag is the flag of a setting
+type settingFlag struct {
+	value   string
+	boolean bool
+}
+
+func (f *settingFlag) String() string     { return f.value }
+func (f *settingFlag) Set(v string) error { f.value = v; return nil }
+func (f *settingFlag) IsBoolFlag() bool   { return f.boolean }
+
+var settings = []setting{
+	{"addr", "KVCTL_ADDR", "localhost:50051", "gRPC address of the key-value service", false},
+	{"gateway", "KVCTL_GATEWAY", "", "URL of the REST gateway, used instead of -addr when set", false},
+	{"api-key", "KVCTL_API_KEY", "", "API key sent to the gateway", false},
+	{"tls", "KVCTL_TLS", "false", "connect to -addr over TLS", true},
+	{"output", "KVCTL_OUTPUT", outputPlain, "output format: plain, json or table", false},
+	{"timeout", "KVCTL_TIMEOUT", "10s", "bound on each request, 0 for none", false},
+}
+
+// profiles is the content of the profile file: named sets of settings, keyed by flag name. Values are strings,
+// booleans or numbers.
+type profiles struct {
+	Profiles map[string]map[string]any `json:"profiles"`
+}
+
+// defaultProfile is read from the profile file unless another one is asked for
+const defaultProfile = "default"
+
+// loadConfig parses the global flags in args and returns the configuration with the remaining arguments.
+// The profile file is -config, KVCTL_CONFIG or kvctl/config.json in the user configuration directory, and the
+// profile -profile, KVCTL_PROFILE or default. A missing file is only an error when it or the profile was named.
+func loadConfig(args []string, getenv func(string) string, stderr io.Writer) (Config, []string, error) {
+	fs := flag.NewFlagSet("kvctl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { usage(fs) }
+	values := make(map[string]*settingFlag, len(settings))
+	for _, s := range settings {
+		values[s.name] = &settingFlag{boolean: s.boolean}
+		fs.Var(values[s.name], s.name, fmt.Sprintf("%s (%s, default %q)", s.usage, s.env, s.def))
+	}
+	configPath := fs.String("config", "", "profile file (KVCTL_CONFIG, default kvctl/config.json in the user config directory)")
+	profileName := fs.String("profile", "", "profile to use from the profile file (KVCTL_PROFILE, default \"default\")")
+	if err := fs.Parse(args); err != nil {
+		return Config{}, nil, err
+	}
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	profile, err := readProfile(first(*configPath, getenv("KVCTL_CONFIG")), first(*profileName, getenv("KVCTL_PROFILE")))
+	if err != nil {
+		return Config{}, nil, err
+	}
+
+	resolved := make(map[string]string, len(settings))
+	for _, s := range settings {
+		switch {
+		case set[s.name]:
+			resolved[s.name] = values[s.name].value
+		case getenv(s.env) != "":
+			resolved[s.name] = getenv(s.env)
+		case profile[s.name] != "":
+			resolved[s.name] = profile[s.name]
+		default:
+			resolved[s.name] = s.def
+		}
+	}
+
+	config := Config{
+		Addr:    resolved["addr"],
+		Gateway: resolved["gateway"],
+		APIKey:  resolved["api-key"],
+		Output:  resolved["output"],
+	}
+	if config.TLS, err = strconv.ParseBool(resolved["tls"]); err != nil {
+		return Config{}, nil, fmt.Errorf("invalid tls %q: %w", resolved["tls"], err)
+	}
+	if config.Timeout, err = time.ParseDuration(resolved["timeout"]); err != nil {
+		return Config{}, nil, fmt.Errorf("invalid timeout %q: %w", resolved["timeout"], err)
+	}
+	switch config.Output {
+	case outputPlain, outputJSON, outputTable:
+	default:
+		return Config{}, nil, fmt.Errorf("unknown output format %q, expected plain, json or table", config.Output)
+	}
+	return config, fs.Args(), nil
+}
+
+// readProfile reads a profile from the profile file. Empty path and name pick the defaults.
+func readProfile(path string, name string) (map[string]string, error) {
+	named := path != "" || name != ""
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			if named {
+				return nil, fmt.Errorf("failed to find the profile file: %w", err)
+			}
+			return nil, nil
+		}
+		path = filepath.Join(dir, "kvctl", "config.json")
+	}
+	if name == "" {
+		name = defaultProfile
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !named {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile file: %w", err)
+	}
+	var file profiles
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse profile file %s: %w", path, err)
+	}
+
+	profile, ok := file.Profiles[name]
+	if !ok {
+		if name == defaultProfile {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("profile %q not found in %s", name, path)
+	}
+	values := make(map[string]string, len(profile))
+	for field, value := range profile {
+		if !known(field) {
+			return nil, fmt.Errorf("unknown setting %q in profile %q", field, name)
+		}
+		values[field] = fmt.Sprint(value)
+	}
+	return values, nil
+}
+
+// known reports whether name is a setting
+func known(name string) bool {
+	for _, s := range settings {
+		if s.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// first returns the first non empty value
+func first(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
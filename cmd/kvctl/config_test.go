@@ -0,0 +1,99 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"profiles": {
+		"default": {"addr": "profile:50051", "output": "table"},
+		"prod": {"gateway": "https://kv.example.com", "api-key": "secret", "tls": true, "timeout": "3s"},
+		"typo": {"adress": "x"}
+	}}`), 0o600))
+
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		want Config
+		err  string
+	}{
+		{
+			name: "defaults",
+			args: []string{"-config", filepath.Join(t.TempDir(), "missing.json"), "get", "a"},
+			err:  "failed to read profile file",
+		},
+		{
+			name: "default profile",
+			args: []string{"-config", path, "get", "a"},
+			want: Config{Addr: "profile:50051", Output: outputTable, Timeout: 10 * time.Second},
+		},
+		{
+			name: "environment over profile",
+			args: []string{"get", "a"},
+			env:  map[string]string{"KVCTL_CONFIG": path, "KVCTL_OUTPUT": "json"},
+			want: Config{Addr: "profile:50051", Output: outputJSON, Timeout: 10 * time.Second},
+		},
+		{
+			name: "flags over environment",
+			args: []string{"-config", path, "-output", "plain", "-addr", "flag:1", "-tls", "get", "a"},
+			env:  map[string]string{"KVCTL_OUTPUT": "json"},
+			want: Config{Addr: "flag:1", Output: outputPlain, TLS: true, Timeout: 10 * time.Second},
+		},
+		{
+			name: "named profile",
+			args: []string{"-config", path, "-profile", "prod", "get", "a"},
+			want: Config{
+				Addr:    "localhost:50051",
+				Gateway: "https://kv.example.com",
+				APIKey:  "secret",
+				TLS:     true,
+				Output:  outputPlain,
+				Timeout: 3 * time.Second,
+			},
+		},
+		{
+			name: "missing profile",
+			args: []string{"-config", path, "get", "a"},
+			env:  map[string]string{"KVCTL_PROFILE": "staging"},
+			err:  `profile "staging" not found`,
+		},
+		{
+			name: "unknown setting",
+			args: []string{"-config", path, "-profile", "typo", "get", "a"},
+			err:  `unknown setting "adress"`,
+		},
+		{
+			name: "bad output",
+			args: []string{"-config", path, "-output", "yaml", "get", "a"},
+			err:  `unknown output format "yaml"`,
+		},
+		{
+			name: "bad timeout",
+			args: []string{"-config", path, "-timeout", "soon", "get", "a"},
+			err:  `invalid timeout "soon"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(name string) string { return tt.env[name] }
+			config, rest, err := loadConfig(tt.args, getenv, io.Discard)
+			if tt.err != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, config)
+			assert.Equal(t, []string{"get", "a"}, rest)
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"key-value/client"
+	"key-value/shared/models"
+	"key-value/shared/pairs"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gatewayClient talks to the key-value store through the REST gateway
+type gatewayClient struct {
+	baseURL string
+	apiKey  string
+	http    *http.Client
+	// streams sends the exports and imports, which are not bounded by the timeout
+	streams *http.Client
+}
+
+// newGatewayClient creates a client for the gateway at baseURL, bounding each request by timeout unless it is 0
+func newGatewayClient(baseURL string, apiKey string, timeout time.Duration) *gatewayClient {
+	return &gatewayClient{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		apiKey:  apiKey,
+		http:    &http.Client{Timeout: timeout},
+		streams: &http.Client{},
+	}
+}
+
+// scanResponse is a page of keys returned by the gateway
+type scanResponse struct {
+	Items []models.KeyValue `json:"items"`
+	More  bool              `json:"more"`
+}
+
+// Get reads a key, reporting whether it exists
+func (g *gatewayClient) Get(ctx context.Context, key string) (string, bool, error) {
+	var kv models.KeyValue
+	status, err := g.do(ctx, http.MethodGet, "/v1/values/"+url.PathEscape(key), nil, &kv, http.StatusNotFound)
+	if err != nil {
+		return "", false, err
+	}
+	if status == http.StatusNotFound {
+		return "", false, nil
+	}
+	return kv.Value, true, nil
+}
+
+// Set stores a key-value pair
+func (g *gatewayClient) Set(ctx context.Context, kv models.KeyValue) error {
+	_, err := g.do(ctx, http.MethodPut, "/v1/values", kv, nil)
+	return err
+}
+
+// Delete removes a key
+func (g *gatewayClient) Delete(ctx context.Context, key string) error {
+	_, err := g.do(ctx, http.MethodDelete, "/v1/values/"+url.PathEscape(key), nil, nil)
+	return err
+}
+
+// Scan lists up to limit keys with prefix after startAfter in order, reporting whether there are more
+func (g *gatewayClient) Scan(ctx context.Context, prefix string, startAfter string, limit int) ([]models.KeyValue, bool, error) {
+	query := url.Values{}
+	query.Set("prefix", prefix)
+	query.Set("start_after", startAfter)
+	query.Set("limit", strconv.Itoa(limit))
+	var page scanResponse
+	if _, err := g.do(ctx, http.MethodGet, "/v1/values?"+query.Encode(), nil, &page); err != nil {
+		return nil, false, err
+	}
+	return page.Items, page.More, nil
+}
+
+// Export passes the pairs with prefix to handle in key order, reading them as the gateway streams them
+func (g *gatewayClient) Export(ctx context.Context, prefix string, handle func(models.KeyValue) error) error {
+	path := "/v1/export?" + url.Values{"prefix": {prefix}}.Encode()
+	resp, err := g.send(ctx, g.streams, http.MethodGet, path, "", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	reader := pairs.NewReader(resp.Body, pairs.NDJSON)
+	for {
+		kv, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("GET %s: %w", path, err)
+		}
+		if err := handle(kv); err != nil {
+			return err
+		}
+	}
+}
+
+// Import streams the pairs returned by next until it returns io.EOF to the gateway in one request
+func (g *gatewayClient) Import(ctx context.Context, mode client.ImportMode, next func() (models.KeyValue, error)) (client.ImportResult, error) {
+	path := "/v1/import?" + url.Values{"mode": {importModeNames[mode]}}.Encode()
+
+	// Encode the pairs while the request is sent, failing the request with the error of next
+	body, pipe := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		writer := pairs.NewWriter(pipe, pairs.NDJSON)
+		for {
+			kv, err := next()
+			if errors.Is(err, io.EOF) {
+				pipe.CloseWithError(writer.Flush())
+				return
+			}
+			if err == nil {
+				err = writer.Write(kv)
+			}
+			if err != nil {
+				pipe.CloseWithError(err)
+				return
+			}
+		}
+	}()
+	// The gateway may answer before reading the whole body; stop encoding then
+	defer func() {
+		body.Close()
+		<-done
+	}()
+
+	resp, err := g.send(ctx, g.streams, http.MethodPost, path, pairs.NDJSON.ContentType(), body)
+	if err != nil {
+		return client.ImportResult{}, err
+	}
+	defer resp.Body.Close()
+	var result struct {
+		Written int `json:"written"`
+		Skipped int `json:"skipped"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return client.ImportResult{}, fmt.Errorf("POST %s: invalid response: %w", path, err)
+	}
+	return client.ImportResult{Written: result.Written, Skipped: result.Skipped}, nil
+}
+
+// Health checks that the gateway is up
+func (g *gatewayClient) Health(ctx context.Context) error {
+	_, err := g.do(ctx, http.MethodGet, "/health", nil, nil)
+	return err
+}
+
+// Close releases idle connections
+func (g *gatewayClient) Close() error {
+	g.http.CloseIdleConnections()
+	g.streams.CloseIdleConnections()
+	return nil
+}
+
+// do sends a request with an optional JSON body and decodes a JSON response into out when given. Statuses other
+// than 2xx and the allowed ones fail with the error message of the gateway.
+func (g *gatewayClient) do(ctx context.Context, method string, path string, body any, out any, allowed ...int) (int, error) {
+	var reader io.Reader
+	contentType := ""
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+	resp, err := g.send(ctx, g.http, method, path, contentType, reader, allowed...)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if out != nil && resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp.StatusCode, fmt.Errorf("%s %s: invalid response: %w", method, path, err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// send sends a request with httpClient, returning the response of a 2xx or allowed status for the caller to close.
+// Other statuses fail with the error message of the gateway.
+func (g *gatewayClient) send(ctx context.Context, httpClient *http.Client, method string, path string, contentType string, body io.Reader, allowed ...int) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, g.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if g.apiKey != "" {
+		req.Header.Set("x-api-key", g.apiKey)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if (resp.StatusCode >= 200 && resp.StatusCode <= 299) || slices.Contains(allowed, resp.StatusCode) {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	var failure struct {
+		Error string `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if json.Unmarshal(data, &failure) != nil || failure.Error == "" {
+		failure.Error = strings.TrimSpace(string(data))
+	}
+	return nil, fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, failure.Error)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"key-value/client"
+	"key-value/shared/models"
+	"key-value/shared/pairs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveGateway serves the REST routes kvctl uses over s, checking the API key
+func serveGateway(t *testing.T, s *fakeStore) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	reply := func(w http.ResponseWriter, status int, body any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	}
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("I am alive"))
+	})
+	guard := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("x-api-key") != "secret" {
+				reply(w, http.StatusUnauthorized, map[string]string{"error": "Invalid API key"})
+				return
+			}
+			h(w, r)
+		}
+	}
+	mux.HandleFunc("GET /v1/values/{key}", guard(func(w http.ResponseWriter, r *http.Request) {
+		value, ok, _ := s.Get(r.Context(), r.PathValue("key"))
+		if !ok {
+			reply(w, http.StatusNotFound, map[string]string{"error": "Key not found"})
+			return
+		}
+		reply(w, http.StatusOK, models.KeyValue{Key: r.PathValue("key"), Value: value})
+	}))
+	mux.HandleFunc("PUT /v1/values", guard(func(w http.ResponseWriter, r *http.Request) {
+		var kv models.KeyValue
+		json.NewDecoder(r.Body).Decode(&kv)
+		if kv.Key == "" {
+			reply(w, http.StatusBadRequest, map[string]string{"error": "Key is required"})
+			return
+		}
+		s.Set(r.Context(), kv)
+		reply(w, http.StatusOK, kv)
+	}))
+	mux.HandleFunc("DELETE /v1/values/{key}", guard(func(w http.ResponseWriter, r *http.Request) {
+		s.Delete(r.Context(), r.PathValue("key"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	mux.HandleFunc("GET /v1/values", guard(func(w http.ResponseWriter, r *http.Request) {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		items, more, _ := s.Scan(r.Context(), r.URL.Query().Get("prefix"), r.URL.Query().Get("start_after"), limit)
+		reply(w, http.StatusOK, map[string]any{"items": items, "more": more})
+	}))
+	mux.HandleFunc("GET /v1/export", guard(func(w http.ResponseWriter, r *http.Request) {
+		writer := pairs.NewWriter(w, pairs.NDJSON)
+		s.Export(r.Context(), r.URL.Query().Get("prefix"), writer.Write)
+		writer.Flush()
+	}))
+	mux.HandleFunc("POST /v1/import", guard(func(w http.ResponseWriter, r *http.Request) {
+		result, err := s.Import(r.Context(), importModes[r.URL.Query().Get("mode")], pairs.NewReader(r.Body, pairs.NDJSON).Read)
+		if err != nil {
+			reply(w, http.StatusConflict, map[string]string{"error": err.Error()})
+			return
+		}
+		reply(w, http.StatusOK, map[string]int{"written": result.Written, "skipped": result.Skipped})
+	}))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestGatewayClient(t *testing.T) {
+	s := newFakeStore("user:1", "alice", "user:2", "bob", "odd/key", "x")
+	g := newGatewayClient(serveGateway(t, s)+"/", "secret", time.Second)
+	defer g.Close()
+	ctx := context.Background()
+
+	require.NoError(t, g.Health(ctx))
+
+	value, found, err := g.Get(ctx, "user:1")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "alice", value)
+	_, found, err = g.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, found)
+	value, _, err = g.Get(ctx, "odd/key")
+	require.NoError(t, err)
+	assert.Equal(t, "x", value, "keys are escaped in paths")
+
+	require.NoError(t, g.Set(ctx, models.KeyValue{Key: "a", Value: "1"}))
+	assert.Equal(t, "1", s.data["a"])
+	require.NoError(t, g.Delete(ctx, "a"))
+	assert.NotContains(t, s.data, "a")
+	assert.ErrorContains(t, g.Set(ctx, models.KeyValue{}), "400 Bad Request: Key is required")
+
+	items, more, err := g.Scan(ctx, "user:", "", 1)
+	require.NoError(t, err)
+	assert.True(t, more)
+	assert.Equal(t, []models.KeyValue{{Key: "user:1", Value: "alice"}}, items)
+
+	var exported []models.KeyValue
+	require.NoError(t, g.Export(ctx, "user:", func(kv models.KeyValue) error {
+		exported = append(exported, kv)
+		return nil
+	}))
+	assert.Equal(t, []models.KeyValue{{Key: "user:1", Value: "alice"}, {Key: "user:2", Value: "bob"}}, exported)
+
+	result, err := g.Import(ctx, client.ImportSkipExisting, pairs.NewReader(strings.NewReader("key,value\nuser:1,new\nc,3\n"), pairs.CSV).Read)
+	require.NoError(t, err)
+	assert.Equal(t, client.ImportResult{Written: 1, Skipped: 1}, result)
+	assert.Equal(t, "3", s.data["c"])
+	_, err = g.Import(ctx, client.ImportFailOnConflict, pairs.NewReader(strings.NewReader("key,value\nuser:1,new\n"), pairs.CSV).Read)
+	assert.ErrorContains(t, err, "409 Conflict")
+
+	// Errors of the source fail the import
+	_, err = g.Import(ctx, client.ImportOverwrite, pairs.NewReader(strings.NewReader("not json"), pairs.NDJSON).Read)
+	assert.ErrorIs(t, err, pairs.ErrSyntax)
+
+	// The commands work the same way through the gateway
+	out, err := execute(t, ctx, g, outputPlain, "", "scan", "-prefix", "user:")
+	require.NoError(t, err)
+	assert.Equal(t, "user:1\talice\nuser:2\tbob\n", out)
+
+	unauthorized := newGatewayClient(serveGateway(t, s), "wrong", time.Second)
+	_, _, err = unauthorized.Get(ctx, "user:1")
+	assert.ErrorContains(t, err, "401 Unauthorized: Invalid API key")
+}
@@ -0,0 +1,95 @@
+// Command kvctl reads and writes the key-value store from the command line, either directly over gRPC or through
+// the REST gateway.
+//
+//	kvctl [flags] <command> [arguments]
+//
+// Settings come from flags, else KVCTL_* environment variables, else a profile of the profile file.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"key-value/client"
+	"key-value/shared/limits"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Getenv, os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "kvctl: %v\n", err)
+		}
+		os.Exit(1)
+	}
+}
+
+// run parses the global flags, connects to the store and runs the command named in args
+func run(ctx context.Context, args []string, getenv func(string) string, stdin io.Reader, stdout, stderr io.Writer) error {
+	config, args, err := loadConfig(args, getenv, stderr)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		printUsage(stderr)
+		return flag.ErrHelp
+	}
+	name := args[0]
+	if name == "help" {
+		printUsage(stdout)
+		return nil
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		printUsage(stderr)
+		return fmt.Errorf("unknown command %q", name)
+	}
+
+	s, err := connect(config)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	e := &env{store: s, out: &printer{w: stdout, format: config.Output}, stdin: stdin, stdout: stdout, stderr: stderr, usage: cmd.usage}
+	return cmd.run(ctx, e, args[1:])
+}
+
+// connect opens the gateway when configured and the gRPC service otherwise
+func connect(config Config) (store, error) {
+	if config.Gateway != "" {
+		return newGatewayClient(config.Gateway, config.APIKey, config.Timeout), nil
+	}
+	// Read back values as large as the service takes, which reads the same limits from the environment
+	opts := []client.Option{client.WithLimits(limits.Load())}
+	if config.Timeout > 0 {
+		opts = append(opts, client.WithDefaultTimeout(config.Timeout))
+	}
+	if config.TLS {
+		opts = append(opts, client.WithTLS(&tls.Config{}))
+	}
+	return client.NewKVStoreClient(config.Addr, opts...)
+}
+
+// usage prints the usage text with the global flags
+func usage(fs *flag.FlagSet) {
+	printUsage(fs.Output())
+	fmt.Fprintln(fs.Output(), "\nflags:")
+	fs.PrintDefaults()
+}
+
+// printUsage lists the commands
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: kvctl [flags] <command> [arguments]\n\ncommands:")
+	for _, name := range commandOrder {
+		fmt.Fprintf(w, "  %-46s %s\n", commands[name].usage, commands[name].help)
+	}
+}
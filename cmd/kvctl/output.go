@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"key-value/client"
+	"key-value/shared/models"
+	"text/tabwriter"
+)
+
+// field is a named value of a result
+type field struct {
+	Name  string
+	Value any
+}
+
+// printer writes results in the configured output format
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+// value prints a single key-value pair, just the value in plain output
+func (p *printer) value(kv models.KeyValue) error {
+	switch p.format {
+	case outputJSON:
+		return p.json(kv)
+	case outputTable:
+		return p.pairs([]models.KeyValue{kv})
+	}
+	_, err := fmt.Fprintln(p.w, kv.Value)
+	return err
+}
+
+// pairs prints key-value pairs, one tab separated pair per line in plain output
+func (p *printer) pairs(items []models.KeyValue) error {
+	switch p.format {
+	case outputJSON:
+		if items == nil {
+			items = []models.KeyValue{}
+		}
+		return p.json(items)
+	case outputTable:
+		tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "KEY\tVALUE")
+		for _, kv := range items {
+			fmt.Fprintf(tw, "%s\t%s\n", kv.Key, kv.Value)
+		}
+		return tw.Flush()
+	}
+	for _, kv := range items {
+		if _, err := fmt.Fprintf(p.w, "%s\t%s\n", kv.Key, kv.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fields prints named values in order, as a JSON object in JSON output
+func (p *printer) fields(fields []field) error {
+	switch p.format {
+	case outputJSON:
+		object := make(map[string]any, len(fields))
+		for _, f := range fields {
+			object[f.Name] = f.Value
+		}
+		return p.json(object)
+	case outputTable:
+		tw := tabwriter.NewWriter(p.w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "NAME\tVALUE")
+		for _, f := range fields {
+			fmt.Fprintf(tw, "%s\t%v\n", f.Name, f.Value)
+		}
+		return tw.Flush()
+	}
+	for _, f := range fields {
+		if _, err := fmt.Fprintf(p.w, "%s: %v\n", f.Name, f.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// event prints a change of a watched key as it happens, one JSON object per line in JSON output. Table output
+// cannot align a stream, so it prints events like plain output.
+func (p *printer) event(e client.KeyEvent) error {
+	op := "set"
+	if e.Op == client.ChangeDelete {
+		op = "delete"
+	}
+	if p.format == outputJSON {
+		return p.json(struct {
+			Op    string `json:"op"`
+			Key   string `json:"key"`
+			Value string `json:"value,omitempty"`
+		}{op, e.Key, e.Value})
+	}
+	if e.Op == client.ChangeDelete {
+		_, err := fmt.Fprintf(p.w, "DELETE\t%s\n", e.Key)
+		return err
+	}
+	_, err := fmt.Fprintf(p.w, "SET\t%s\t%s\n", e.Key, e.Value)
+	return err
+}
+
+// json prints v as a line of JSON
+func (p *printer) json(v any) error {
+	return json.NewEncoder(p.w).Encode(v)
+}
@@ -21,10 +21,275 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// RestoreMode decides what a restore does with the current data
+type RestoreMode int32
+
+const (
+	// Replace the whole store with the backup, dropping keys it does not hold
+	RestoreMode_RESTORE_MODE_REPLACE RestoreMode = 0
+	// Write the pairs of the backup over the current data, keeping other keys
+	RestoreMode_RESTORE_MODE_MERGE RestoreMode = 1
+)
+
+// Enum value maps for RestoreMode.
+var (
+	RestoreMode_name = map[int32]string{
+		0: "RESTORE_MODE_REPLACE",
+		1: "RESTORE_MODE_MERGE",
+	}
+	RestoreMode_value = map[string]int32{
+		"RESTORE_MODE_REPLACE": 0,
+		"RESTORE_MODE_MERGE":   1,
+	}
+)
+
+func (x RestoreMode) Enum() *RestoreMode {
+	p := new(RestoreMode)
+	*p = x
+	return p
+}
+
+func (x RestoreMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RestoreMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_keyvalue_proto_enumTypes[0].Descriptor()
+}
+
+func (RestoreMode) Type() protoreflect.EnumType {
+	return &file_proto_keyvalue_proto_enumTypes[0]
+}
+
+func (x RestoreMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RestoreMode.Descriptor instead.
+func (RestoreMode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{0}
+}
+
+// ImportMode decides what an import does with keys that already exist
+type ImportMode int32
+
+const (
+	// Write over existing values
+	ImportMode_IMPORT_MODE_OVERWRITE ImportMode = 0
+	// Keep existing values, counting their pairs as skipped
+	ImportMode_IMPORT_MODE_SKIP_EXISTING ImportMode = 1
+	// Fail with Aborted, reason CONFLICT, at the first key holding a different value. Keys already holding the
+	// imported value are skipped, so a failed import can be run again.
+	ImportMode_IMPORT_MODE_FAIL_ON_CONFLICT ImportMode = 2
+)
+
+// Enum value maps for ImportMode.
+var (
+	ImportMode_name = map[int32]string{
+		0: "IMPORT_MODE_OVERWRITE",
+		1: "IMPORT_MODE_SKIP_EXISTING",
+		2: "IMPORT_MODE_FAIL_ON_CONFLICT",
+	}
+	ImportMode_value = map[string]int32{
+		"IMPORT_MODE_OVERWRITE":        0,
+		"IMPORT_MODE_SKIP_EXISTING":    1,
+		"IMPORT_MODE_FAIL_ON_CONFLICT": 2,
+	}
+)
+
+func (x ImportMode) Enum() *ImportMode {
+	p := new(ImportMode)
+	*p = x
+	return p
+}
+
+func (x ImportMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ImportMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_keyvalue_proto_enumTypes[1].Descriptor()
+}
+
+func (ImportMode) Type() protoreflect.EnumType {
+	return &file_proto_keyvalue_proto_enumTypes[1]
+}
+
+func (x ImportMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ImportMode.Descriptor instead.
+func (ImportMode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{1}
+}
+
+// ReadConsistency selects how a replicated service serves a Get
+type ReadConsistency int32
+
+const (
+	// Use the consistency configured on the service
+	ReadConsistency_READ_CONSISTENCY_DEFAULT ReadConsistency = 0
+	// Served by the leader after confirming leadership, sees every acknowledged write
+	ReadConsistency_READ_CONSISTENCY_LINEARIZABLE ReadConsistency = 1
+	// Served from the local copy of any replica, may lag behind
+	ReadConsistency_READ_CONSISTENCY_STALE ReadConsistency = 2
+)
+
+// Enum value maps for ReadConsistency.
+var (
+	ReadConsistency_name = map[int32]string{
+		0: "READ_CONSISTENCY_DEFAULT",
+		1: "READ_CONSISTENCY_LINEARIZABLE",
+		2: "READ_CONSISTENCY_STALE",
+	}
+	ReadConsistency_value = map[string]int32{
+		"READ_CONSISTENCY_DEFAULT":      0,
+		"READ_CONSISTENCY_LINEARIZABLE": 1,
+		"READ_CONSISTENCY_STALE":        2,
+	}
+)
+
+func (x ReadConsistency) Enum() *ReadConsistency {
+	p := new(ReadConsistency)
+	*p = x
+	return p
+}
+
+func (x ReadConsistency) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ReadConsistency) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_keyvalue_proto_enumTypes[2].Descriptor()
+}
+
+func (ReadConsistency) Type() protoreflect.EnumType {
+	return &file_proto_keyvalue_proto_enumTypes[2]
+}
+
+func (x ReadConsistency) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ReadConsistency.Descriptor instead.
+func (ReadConsistency) EnumDescriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{2}
+}
+
+// MutationOp is the kind of change carried by a Mutation
+type MutationOp int32
+
+const (
+	MutationOp_MUTATION_OP_UNSPECIFIED MutationOp = 0
+	MutationOp_MUTATION_OP_SET         MutationOp = 1
+	MutationOp_MUTATION_OP_DELETE      MutationOp = 2
+)
+
+// Enum value maps for MutationOp.
+var (
+	MutationOp_name = map[int32]string{
+		0: "MUTATION_OP_UNSPECIFIED",
+		1: "MUTATION_OP_SET",
+		2: "MUTATION_OP_DELETE",
+	}
+	MutationOp_value = map[string]int32{
+		"MUTATION_OP_UNSPECIFIED": 0,
+		"MUTATION_OP_SET":         1,
+		"MUTATION_OP_DELETE":      2,
+	}
+)
+
+func (x MutationOp) Enum() *MutationOp {
+	p := new(MutationOp)
+	*p = x
+	return p
+}
+
+func (x MutationOp) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MutationOp) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_keyvalue_proto_enumTypes[3].Descriptor()
+}
+
+func (MutationOp) Type() protoreflect.EnumType {
+	return &file_proto_keyvalue_proto_enumTypes[3]
+}
+
+func (x MutationOp) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MutationOp.Descriptor instead.
+func (MutationOp) EnumDescriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{3}
+}
+
+// MemberState is the state of a member as known by the gossip group
+type MemberState int32
+
+const (
+	MemberState_MEMBER_STATE_UNSPECIFIED MemberState = 0
+	MemberState_MEMBER_STATE_ALIVE       MemberState = 1
+	// Failed a probe, declared dead unless it refutes the suspicion in time
+	MemberState_MEMBER_STATE_SUSPECT MemberState = 2
+	MemberState_MEMBER_STATE_DEAD    MemberState = 3
+	// Left the group on shutdown
+	MemberState_MEMBER_STATE_LEFT MemberState = 4
+)
+
+// Enum value maps for MemberState.
+var (
+	MemberState_name = map[int32]string{
+		0: "MEMBER_STATE_UNSPECIFIED",
+		1: "MEMBER_STATE_ALIVE",
+		2: "MEMBER_STATE_SUSPECT",
+		3: "MEMBER_STATE_DEAD",
+		4: "MEMBER_STATE_LEFT",
+	}
+	MemberState_value = map[string]int32{
+		"MEMBER_STATE_UNSPECIFIED": 0,
+		"MEMBER_STATE_ALIVE":       1,
+		"MEMBER_STATE_SUSPECT":     2,
+		"MEMBER_STATE_DEAD":        3,
+		"MEMBER_STATE_LEFT":        4,
+	}
+)
+
+func (x MemberState) Enum() *MemberState {
+	p := new(MemberState)
+	*p = x
+	return p
+}
+
+func (x MemberState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (MemberState) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_keyvalue_proto_enumTypes[4].Descriptor()
+}
+
+func (MemberState) Type() protoreflect.EnumType {
+	return &file_proto_keyvalue_proto_enumTypes[4]
+}
+
+func (x MemberState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use MemberState.Descriptor instead.
+func (MemberState) EnumDescriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{4}
+}
+
 // Request message for Get operation
 type GetRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Consistency   ReadConsistency        `protobuf:"varint,2,opt,name=consistency,proto3,enum=keyvalue.ReadConsistency" json:"consistency,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -66,6 +331,13 @@ func (x *GetRequest) GetKey() string {
 	return ""
 }
 
+func (x *GetRequest) GetConsistency() ReadConsistency {
+	if x != nil {
+		return x.Consistency
+	}
+	return ReadConsistency_READ_CONSISTENCY_DEFAULT
+}
+
 // Response message for Get operation
 type GetResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -129,9 +401,13 @@ func (x *GetResponse) GetError() string {
 
 // Request message for Set operation
 type SetRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// Only write when the key does not exist yet, otherwise fail with CONFLICT
+	IfAbsent bool `protobuf:"varint,3,opt,name=if_absent,json=ifAbsent,proto3" json:"if_absent,omitempty"`
+	// Attach the key to a lease so it is deleted when the lease ends, see LeaseService
+	LeaseId       int64 `protobuf:"varint,4,opt,name=lease_id,json=leaseId,proto3" json:"lease_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -180,6 +456,20 @@ func (x *SetRequest) GetValue() string {
 	return ""
 }
 
+func (x *SetRequest) GetIfAbsent() bool {
+	if x != nil {
+		return x.IfAbsent
+	}
+	return false
+}
+
+func (x *SetRequest) GetLeaseId() int64 {
+	if x != nil {
+		return x.LeaseId
+	}
+	return 0
+}
+
 // Response message for Set operation
 type SetResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -235,8 +525,10 @@ func (x *SetResponse) GetError() string {
 
 // Request message for Delete operation
 type DeleteRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Only delete when the key currently holds this value, otherwise fail with CONFLICT
+	IfValue       *string `protobuf:"bytes,2,opt,name=if_value,json=ifValue,proto3,oneof" json:"if_value,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -278,6 +570,13 @@ func (x *DeleteRequest) GetKey() string {
 	return ""
 }
 
+func (x *DeleteRequest) GetIfValue() string {
+	if x != nil && x.IfValue != nil {
+		return *x.IfValue
+	}
+	return ""
+}
+
 // Response message for Delete operation
 type DeleteResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -331,27 +630,29 @@ func (x *DeleteResponse) GetError() string {
 	return ""
 }
 
-// Request message for Health check
-type HealthRequest struct {
+// Request message for Increment operation
+type IncrementRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Delta         int64                  `protobuf:"varint,2,opt,name=delta,proto3" json:"delta,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthRequest) Reset() {
-	*x = HealthRequest{}
+func (x *IncrementRequest) Reset() {
+	*x = IncrementRequest{}
 	mi := &file_proto_keyvalue_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthRequest) String() string {
+func (x *IncrementRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthRequest) ProtoMessage() {}
+func (*IncrementRequest) ProtoMessage() {}
 
-func (x *HealthRequest) ProtoReflect() protoreflect.Message {
+func (x *IncrementRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_keyvalue_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -363,34 +664,47 @@ func (x *HealthRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
-func (*HealthRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use IncrementRequest.ProtoReflect.Descriptor instead.
+func (*IncrementRequest) Descriptor() ([]byte, []int) {
 	return file_proto_keyvalue_proto_rawDescGZIP(), []int{6}
 }
 
-// Response message for Health check
-type HealthResponse struct {
+func (x *IncrementRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *IncrementRequest) GetDelta() int64 {
+	if x != nil {
+		return x.Delta
+	}
+	return 0
+}
+
+// Response message for Increment operation
+type IncrementResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
-	Timestamp     int64                  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Value         int64                  `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *HealthResponse) Reset() {
-	*x = HealthResponse{}
+func (x *IncrementResponse) Reset() {
+	*x = IncrementResponse{}
 	mi := &file_proto_keyvalue_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *HealthResponse) String() string {
+func (x *IncrementResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthResponse) ProtoMessage() {}
+func (*IncrementResponse) ProtoMessage() {}
 
-func (x *HealthResponse) ProtoReflect() protoreflect.Message {
+func (x *IncrementResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_keyvalue_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -402,115 +716,4674 @@ func (x *HealthResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
-func (*HealthResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use IncrementResponse.ProtoReflect.Descriptor instead.
+func (*IncrementResponse) Descriptor() ([]byte, []int) {
 	return file_proto_keyvalue_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *HealthResponse) GetStatus() string {
+func (x *IncrementResponse) GetValue() int64 {
 	if x != nil {
-		return x.Status
+		return x.Value
 	}
-	return ""
+	return 0
 }
 
-func (x *HealthResponse) GetTimestamp() int64 {
+// KeyValuePair is a key and its value
+type KeyValuePair struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KeyValuePair) Reset() {
+	*x = KeyValuePair{}
+	mi := &file_proto_keyvalue_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KeyValuePair) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyValuePair) ProtoMessage() {}
+
+func (x *KeyValuePair) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[8]
 	if x != nil {
-		return x.Timestamp
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-var File_proto_keyvalue_proto protoreflect.FileDescriptor
+// Deprecated: Use KeyValuePair.ProtoReflect.Descriptor instead.
+func (*KeyValuePair) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{8}
+}
 
-const file_proto_keyvalue_proto_rawDesc = "" +
-	"\n" +
-	"\x14proto/keyvalue.proto\x12\bkeyvalue\"\x1e\n" +
-	"\n" +
-	"GetRequest\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\"O\n" +
-	"\vGetResponse\x12\x14\n" +
-	"\x05value\x18\x01 \x01(\tR\x05value\x12\x14\n" +
-	"\x05found\x18\x02 \x01(\bR\x05found\x12\x14\n" +
-	"\x05error\x18\x03 \x01(\tR\x05error\"4\n" +
-	"\n" +
-	"SetRequest\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value\"=\n" +
-	"\vSetResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"!\n" +
-	"\rDeleteRequest\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\"@\n" +
-	"\x0eDeleteResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
-	"\x05error\x18\x02 \x01(\tR\x05error\"\x0f\n" +
-	"\rHealthRequest\"F\n" +
-	"\x0eHealthResponse\x12\x16\n" +
-	"\x06status\x18\x01 \x01(\tR\x06status\x12\x1c\n" +
-	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp2\xf3\x01\n" +
-	"\x0fKeyValueService\x122\n" +
-	"\x03Get\x12\x14.keyvalue.GetRequest\x1a\x15.keyvalue.GetResponse\x122\n" +
-	"\x03Set\x12\x14.keyvalue.SetRequest\x1a\x15.keyvalue.SetResponse\x12;\n" +
-	"\x06Delete\x12\x17.keyvalue.DeleteRequest\x1a\x18.keyvalue.DeleteResponse\x12;\n" +
-	"\x06Health\x12\x17.keyvalue.HealthRequest\x1a\x18.keyvalue.HealthResponseB\x1aZ\x18key-value/proto/keyvalueb\x06proto3"
+func (x *KeyValuePair) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
 
-var (
-	file_proto_keyvalue_proto_rawDescOnce sync.Once
-	file_proto_keyvalue_proto_rawDescData []byte
-)
+func (x *KeyValuePair) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
 
-func file_proto_keyvalue_proto_rawDescGZIP() []byte {
-	file_proto_keyvalue_proto_rawDescOnce.Do(func() {
-		file_proto_keyvalue_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_keyvalue_proto_rawDesc), len(file_proto_keyvalue_proto_rawDesc)))
-	})
-	return file_proto_keyvalue_proto_rawDescData
+// Request message for Scan operation
+type ScanRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Prefix string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// Only keys sorting after start_after are returned, used to resume a previous scan
+	StartAfter string `protobuf:"bytes,2,opt,name=start_after,json=startAfter,proto3" json:"start_after,omitempty"`
+	// Maximum number of pairs returned, the service applies a default and a maximum
+	Limit         int32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-var file_proto_keyvalue_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
-var file_proto_keyvalue_proto_goTypes = []any{
-	(*GetRequest)(nil),     // 0: keyvalue.GetRequest
-	(*GetResponse)(nil),    // 1: keyvalue.GetResponse
-	(*SetRequest)(nil),     // 2: keyvalue.SetRequest
-	(*SetResponse)(nil),    // 3: keyvalue.SetResponse
-	(*DeleteRequest)(nil),  // 4: keyvalue.DeleteRequest
-	(*DeleteResponse)(nil), // 5: keyvalue.DeleteResponse
-	(*HealthRequest)(nil),  // 6: keyvalue.HealthRequest
-	(*HealthResponse)(nil), // 7: keyvalue.HealthResponse
+func (x *ScanRequest) Reset() {
+	*x = ScanRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
-var file_proto_keyvalue_proto_depIdxs = []int32{
-	0, // 0: keyvalue.KeyValueService.Get:input_type -> keyvalue.GetRequest
-	2, // 1: keyvalue.KeyValueService.Set:input_type -> keyvalue.SetRequest
-	4, // 2: keyvalue.KeyValueService.Delete:input_type -> keyvalue.DeleteRequest
-	6, // 3: keyvalue.KeyValueService.Health:input_type -> keyvalue.HealthRequest
-	1, // 4: keyvalue.KeyValueService.Get:output_type -> keyvalue.GetResponse
-	3, // 5: keyvalue.KeyValueService.Set:output_type -> keyvalue.SetResponse
-	5, // 6: keyvalue.KeyValueService.Delete:output_type -> keyvalue.DeleteResponse
-	7, // 7: keyvalue.KeyValueService.Health:output_type -> keyvalue.HealthResponse
-	4, // [4:8] is the sub-list for method output_type
-	0, // [0:4] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+
+func (x *ScanRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func init() { file_proto_keyvalue_proto_init() }
-func file_proto_keyvalue_proto_init() {
-	if File_proto_keyvalue_proto != nil {
-		return
+func (*ScanRequest) ProtoMessage() {}
+
+func (x *ScanRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanRequest.ProtoReflect.Descriptor instead.
+func (*ScanRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ScanRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *ScanRequest) GetStartAfter() string {
+	if x != nil {
+		return x.StartAfter
+	}
+	return ""
+}
+
+func (x *ScanRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// Response message for Scan operation
+type ScanResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Items []*KeyValuePair        `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	// More pairs match, resume with start_after set to the last returned key
+	More          bool `protobuf:"varint,2,opt,name=more,proto3" json:"more,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScanResponse) Reset() {
+	*x = ScanResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScanResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanResponse) ProtoMessage() {}
+
+func (x *ScanResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanResponse.ProtoReflect.Descriptor instead.
+func (*ScanResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ScanResponse) GetItems() []*KeyValuePair {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *ScanResponse) GetMore() bool {
+	if x != nil {
+		return x.More
+	}
+	return false
+}
+
+// Request message for BatchGet operation
+type BatchGetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetRequest) Reset() {
+	*x = BatchGetRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetRequest) ProtoMessage() {}
+
+func (x *BatchGetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetRequest.ProtoReflect.Descriptor instead.
+func (*BatchGetRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *BatchGetRequest) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+// Response message for BatchGet operation
+type BatchGetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*KeyValuePair        `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchGetResponse) Reset() {
+	*x = BatchGetResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchGetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchGetResponse) ProtoMessage() {}
+
+func (x *BatchGetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchGetResponse.ProtoReflect.Descriptor instead.
+func (*BatchGetResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *BatchGetResponse) GetItems() []*KeyValuePair {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+// Request message for BatchSet operation
+type BatchSetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*KeyValuePair        `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchSetRequest) Reset() {
+	*x = BatchSetRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchSetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchSetRequest) ProtoMessage() {}
+
+func (x *BatchSetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchSetRequest.ProtoReflect.Descriptor instead.
+func (*BatchSetRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *BatchSetRequest) GetItems() []*KeyValuePair {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+// Response message for BatchSet operation
+type BatchSetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchSetResponse) Reset() {
+	*x = BatchSetResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchSetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchSetResponse) ProtoMessage() {}
+
+func (x *BatchSetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchSetResponse.ProtoReflect.Descriptor instead.
+func (*BatchSetResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{14}
+}
+
+// Request message for Health check
+type HealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthRequest) Reset() {
+	*x = HealthRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthRequest) ProtoMessage() {}
+
+func (x *HealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
+func (*HealthRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{15}
+}
+
+// Response message for Health check
+type HealthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Timestamp     int64                  `protobuf:"varint,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthResponse) Reset() {
+	*x = HealthResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthResponse) ProtoMessage() {}
+
+func (x *HealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
+func (*HealthResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *HealthResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *HealthResponse) GetTimestamp() int64 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+// Member describes a node of the cluster
+type Member struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	RaftAddr      string                 `protobuf:"bytes,2,opt,name=raft_addr,json=raftAddr,proto3" json:"raft_addr,omitempty"`
+	GrpcAddr      string                 `protobuf:"bytes,3,opt,name=grpc_addr,json=grpcAddr,proto3" json:"grpc_addr,omitempty"`
+	Voter         bool                   `protobuf:"varint,4,opt,name=voter,proto3" json:"voter,omitempty"`
+	Leader        bool                   `protobuf:"varint,5,opt,name=leader,proto3" json:"leader,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Member) Reset() {
+	*x = Member{}
+	mi := &file_proto_keyvalue_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Member) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Member) ProtoMessage() {}
+
+func (x *Member) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Member.ProtoReflect.Descriptor instead.
+func (*Member) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Member) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Member) GetRaftAddr() string {
+	if x != nil {
+		return x.RaftAddr
+	}
+	return ""
+}
+
+func (x *Member) GetGrpcAddr() string {
+	if x != nil {
+		return x.GrpcAddr
+	}
+	return ""
+}
+
+func (x *Member) GetVoter() bool {
+	if x != nil {
+		return x.Voter
+	}
+	return false
+}
+
+func (x *Member) GetLeader() bool {
+	if x != nil {
+		return x.Leader
+	}
+	return false
+}
+
+// Request message for AddMember operation
+type AddMemberRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	RaftAddr      string                 `protobuf:"bytes,2,opt,name=raft_addr,json=raftAddr,proto3" json:"raft_addr,omitempty"`
+	GrpcAddr      string                 `protobuf:"bytes,3,opt,name=grpc_addr,json=grpcAddr,proto3" json:"grpc_addr,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddMemberRequest) Reset() {
+	*x = AddMemberRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddMemberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddMemberRequest) ProtoMessage() {}
+
+func (x *AddMemberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddMemberRequest.ProtoReflect.Descriptor instead.
+func (*AddMemberRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *AddMemberRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AddMemberRequest) GetRaftAddr() string {
+	if x != nil {
+		return x.RaftAddr
+	}
+	return ""
+}
+
+func (x *AddMemberRequest) GetGrpcAddr() string {
+	if x != nil {
+		return x.GrpcAddr
+	}
+	return ""
+}
+
+// Response message for AddMember operation
+type AddMemberResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddMemberResponse) Reset() {
+	*x = AddMemberResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddMemberResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddMemberResponse) ProtoMessage() {}
+
+func (x *AddMemberResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddMemberResponse.ProtoReflect.Descriptor instead.
+func (*AddMemberResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{19}
+}
+
+// Request message for RemoveMember operation
+type RemoveMemberRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveMemberRequest) Reset() {
+	*x = RemoveMemberRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveMemberRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveMemberRequest) ProtoMessage() {}
+
+func (x *RemoveMemberRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveMemberRequest.ProtoReflect.Descriptor instead.
+func (*RemoveMemberRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RemoveMemberRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Response message for RemoveMember operation
+type RemoveMemberResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveMemberResponse) Reset() {
+	*x = RemoveMemberResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveMemberResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveMemberResponse) ProtoMessage() {}
+
+func (x *RemoveMemberResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveMemberResponse.ProtoReflect.Descriptor instead.
+func (*RemoveMemberResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{21}
+}
+
+// Request message for ListMembers operation
+type ListMembersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMembersRequest) Reset() {
+	*x = ListMembersRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMembersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMembersRequest) ProtoMessage() {}
+
+func (x *ListMembersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMembersRequest.ProtoReflect.Descriptor instead.
+func (*ListMembersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{22}
+}
+
+// Response message for ListMembers operation
+type ListMembersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Members       []*Member              `protobuf:"bytes,1,rep,name=members,proto3" json:"members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMembersResponse) Reset() {
+	*x = ListMembersResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMembersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMembersResponse) ProtoMessage() {}
+
+func (x *ListMembersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMembersResponse.ProtoReflect.Descriptor instead.
+func (*ListMembersResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ListMembersResponse) GetMembers() []*Member {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+// Request message for Replicate operation
+type ReplicateRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	FromSeq uint64                 `protobuf:"varint,1,opt,name=from_seq,json=fromSeq,proto3" json:"from_seq,omitempty"`
+	// Epoch of the primary the follower replicated so far, a different epoch forces a snapshot
+	Epoch         uint64 `protobuf:"varint,2,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplicateRequest) Reset() {
+	*x = ReplicateRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplicateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicateRequest) ProtoMessage() {}
+
+func (x *ReplicateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicateRequest.ProtoReflect.Descriptor instead.
+func (*ReplicateRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ReplicateRequest) GetFromSeq() uint64 {
+	if x != nil {
+		return x.FromSeq
+	}
+	return 0
+}
+
+func (x *ReplicateRequest) GetEpoch() uint64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+// Mutation is a single entry of the primary's mutation log
+type Mutation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           uint64                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	Op            MutationOp             `protobuf:"varint,2,opt,name=op,proto3,enum=keyvalue.MutationOp" json:"op,omitempty"`
+	Key           string                 `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	Value         string                 `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Mutation) Reset() {
+	*x = Mutation{}
+	mi := &file_proto_keyvalue_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Mutation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Mutation) ProtoMessage() {}
+
+func (x *Mutation) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Mutation.ProtoReflect.Descriptor instead.
+func (*Mutation) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *Mutation) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *Mutation) GetOp() MutationOp {
+	if x != nil {
+		return x.Op
+	}
+	return MutationOp_MUTATION_OP_UNSPECIFIED
+}
+
+func (x *Mutation) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Mutation) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+// SnapshotChunk carries part of the primary's data as of seq. The follower replaces its data once last is received.
+type SnapshotChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           uint64                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	Entries       map[string]string      `protobuf:"bytes,2,rep,name=entries,proto3" json:"entries,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Last          bool                   `protobuf:"varint,3,opt,name=last,proto3" json:"last,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SnapshotChunk) Reset() {
+	*x = SnapshotChunk{}
+	mi := &file_proto_keyvalue_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SnapshotChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotChunk) ProtoMessage() {}
+
+func (x *SnapshotChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotChunk.ProtoReflect.Descriptor instead.
+func (*SnapshotChunk) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *SnapshotChunk) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *SnapshotChunk) GetEntries() map[string]string {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *SnapshotChunk) GetLast() bool {
+	if x != nil {
+		return x.Last
+	}
+	return false
+}
+
+// Heartbeat tells a follower the primary's latest sequence number and epoch, which changes when the primary restarts
+type Heartbeat struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           uint64                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	Epoch         uint64                 `protobuf:"varint,2,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Heartbeat) Reset() {
+	*x = Heartbeat{}
+	mi := &file_proto_keyvalue_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Heartbeat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Heartbeat) ProtoMessage() {}
+
+func (x *Heartbeat) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Heartbeat.ProtoReflect.Descriptor instead.
+func (*Heartbeat) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *Heartbeat) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *Heartbeat) GetEpoch() uint64 {
+	if x != nil {
+		return x.Epoch
+	}
+	return 0
+}
+
+// ReplicationEvent is one message of the replication stream
+type ReplicationEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*ReplicationEvent_Snapshot
+	//	*ReplicationEvent_Mutation
+	//	*ReplicationEvent_Heartbeat
+	Event         isReplicationEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplicationEvent) Reset() {
+	*x = ReplicationEvent{}
+	mi := &file_proto_keyvalue_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplicationEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicationEvent) ProtoMessage() {}
+
+func (x *ReplicationEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicationEvent.ProtoReflect.Descriptor instead.
+func (*ReplicationEvent) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ReplicationEvent) GetEvent() isReplicationEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *ReplicationEvent) GetSnapshot() *SnapshotChunk {
+	if x != nil {
+		if x, ok := x.Event.(*ReplicationEvent_Snapshot); ok {
+			return x.Snapshot
+		}
+	}
+	return nil
+}
+
+func (x *ReplicationEvent) GetMutation() *Mutation {
+	if x != nil {
+		if x, ok := x.Event.(*ReplicationEvent_Mutation); ok {
+			return x.Mutation
+		}
+	}
+	return nil
+}
+
+func (x *ReplicationEvent) GetHeartbeat() *Heartbeat {
+	if x != nil {
+		if x, ok := x.Event.(*ReplicationEvent_Heartbeat); ok {
+			return x.Heartbeat
+		}
+	}
+	return nil
+}
+
+type isReplicationEvent_Event interface {
+	isReplicationEvent_Event()
+}
+
+type ReplicationEvent_Snapshot struct {
+	Snapshot *SnapshotChunk `protobuf:"bytes,1,opt,name=snapshot,proto3,oneof"`
+}
+
+type ReplicationEvent_Mutation struct {
+	Mutation *Mutation `protobuf:"bytes,2,opt,name=mutation,proto3,oneof"`
+}
+
+type ReplicationEvent_Heartbeat struct {
+	Heartbeat *Heartbeat `protobuf:"bytes,3,opt,name=heartbeat,proto3,oneof"`
+}
+
+func (*ReplicationEvent_Snapshot) isReplicationEvent_Event() {}
+
+func (*ReplicationEvent_Mutation) isReplicationEvent_Event() {}
+
+func (*ReplicationEvent_Heartbeat) isReplicationEvent_Event() {}
+
+// Request message for ReplicationStatus operation
+type ReplicationStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplicationStatusRequest) Reset() {
+	*x = ReplicationStatusRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplicationStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicationStatusRequest) ProtoMessage() {}
+
+func (x *ReplicationStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicationStatusRequest.ProtoReflect.Descriptor instead.
+func (*ReplicationStatusRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{29}
+}
+
+// Response message for ReplicationStatus operation
+type ReplicationStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Role          string                 `protobuf:"bytes,1,opt,name=role,proto3" json:"role,omitempty"`
+	PrimaryAddr   string                 `protobuf:"bytes,2,opt,name=primary_addr,json=primaryAddr,proto3" json:"primary_addr,omitempty"`
+	AppliedSeq    uint64                 `protobuf:"varint,3,opt,name=applied_seq,json=appliedSeq,proto3" json:"applied_seq,omitempty"`
+	PrimarySeq    uint64                 `protobuf:"varint,4,opt,name=primary_seq,json=primarySeq,proto3" json:"primary_seq,omitempty"`
+	LagEntries    uint64                 `protobuf:"varint,5,opt,name=lag_entries,json=lagEntries,proto3" json:"lag_entries,omitempty"`
+	LagMillis     int64                  `protobuf:"varint,6,opt,name=lag_millis,json=lagMillis,proto3" json:"lag_millis,omitempty"`
+	Connected     bool                   `protobuf:"varint,7,opt,name=connected,proto3" json:"connected,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplicationStatusResponse) Reset() {
+	*x = ReplicationStatusResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplicationStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicationStatusResponse) ProtoMessage() {}
+
+func (x *ReplicationStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicationStatusResponse.ProtoReflect.Descriptor instead.
+func (*ReplicationStatusResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ReplicationStatusResponse) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *ReplicationStatusResponse) GetPrimaryAddr() string {
+	if x != nil {
+		return x.PrimaryAddr
+	}
+	return ""
+}
+
+func (x *ReplicationStatusResponse) GetAppliedSeq() uint64 {
+	if x != nil {
+		return x.AppliedSeq
+	}
+	return 0
+}
+
+func (x *ReplicationStatusResponse) GetPrimarySeq() uint64 {
+	if x != nil {
+		return x.PrimarySeq
+	}
+	return 0
+}
+
+func (x *ReplicationStatusResponse) GetLagEntries() uint64 {
+	if x != nil {
+		return x.LagEntries
+	}
+	return 0
+}
+
+func (x *ReplicationStatusResponse) GetLagMillis() int64 {
+	if x != nil {
+		return x.LagMillis
+	}
+	return 0
+}
+
+func (x *ReplicationStatusResponse) GetConnected() bool {
+	if x != nil {
+		return x.Connected
+	}
+	return false
+}
+
+// Version is one value of a key with the vector clock of the write that produced it
+type Version struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Value string                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	// A deleted key is kept as a tombstone so the delete supersedes older versions
+	Deleted       bool              `protobuf:"varint,2,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	Clock         map[string]uint64 `protobuf:"bytes,3,rep,name=clock,proto3" json:"clock,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Version) Reset() {
+	*x = Version{}
+	mi := &file_proto_keyvalue_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Version) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Version) ProtoMessage() {}
+
+func (x *Version) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Version.ProtoReflect.Descriptor instead.
+func (*Version) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *Version) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Version) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+func (x *Version) GetClock() map[string]uint64 {
+	if x != nil {
+		return x.Clock
+	}
+	return nil
+}
+
+// Request message for GetVersions operation
+type GetVersionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetVersionsRequest) Reset() {
+	*x = GetVersionsRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetVersionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVersionsRequest) ProtoMessage() {}
+
+func (x *GetVersionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVersionsRequest.ProtoReflect.Descriptor instead.
+func (*GetVersionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *GetVersionsRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+// Response message for GetVersions operation
+type GetVersionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Versions      []*Version             `protobuf:"bytes,1,rep,name=versions,proto3" json:"versions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetVersionsResponse) Reset() {
+	*x = GetVersionsResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetVersionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetVersionsResponse) ProtoMessage() {}
+
+func (x *GetVersionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetVersionsResponse.ProtoReflect.Descriptor instead.
+func (*GetVersionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *GetVersionsResponse) GetVersions() []*Version {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+// Request message for PutVersions operation
+type PutVersionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Versions      []*Version             `protobuf:"bytes,2,rep,name=versions,proto3" json:"versions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutVersionsRequest) Reset() {
+	*x = PutVersionsRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutVersionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutVersionsRequest) ProtoMessage() {}
+
+func (x *PutVersionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutVersionsRequest.ProtoReflect.Descriptor instead.
+func (*PutVersionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *PutVersionsRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *PutVersionsRequest) GetVersions() []*Version {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+// Response message for PutVersions operation
+type PutVersionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Versions      []*Version             `protobuf:"bytes,1,rep,name=versions,proto3" json:"versions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PutVersionsResponse) Reset() {
+	*x = PutVersionsResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PutVersionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PutVersionsResponse) ProtoMessage() {}
+
+func (x *PutVersionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PutVersionsResponse.ProtoReflect.Descriptor instead.
+func (*PutVersionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *PutVersionsResponse) GetVersions() []*Version {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+// Request message for MerkleHashes operation
+type MerkleHashesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Depth of the tree, both peers must use the same
+	Depth         uint32   `protobuf:"varint,1,opt,name=depth,proto3" json:"depth,omitempty"`
+	Level         uint32   `protobuf:"varint,2,opt,name=level,proto3" json:"level,omitempty"`
+	Indexes       []uint32 `protobuf:"varint,3,rep,packed,name=indexes,proto3" json:"indexes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MerkleHashesRequest) Reset() {
+	*x = MerkleHashesRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MerkleHashesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MerkleHashesRequest) ProtoMessage() {}
+
+func (x *MerkleHashesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MerkleHashesRequest.ProtoReflect.Descriptor instead.
+func (*MerkleHashesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *MerkleHashesRequest) GetDepth() uint32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+func (x *MerkleHashesRequest) GetLevel() uint32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *MerkleHashesRequest) GetIndexes() []uint32 {
+	if x != nil {
+		return x.Indexes
+	}
+	return nil
+}
+
+// Response message for MerkleHashes operation, one hash per requested index
+type MerkleHashesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hashes        [][]byte               `protobuf:"bytes,1,rep,name=hashes,proto3" json:"hashes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MerkleHashesResponse) Reset() {
+	*x = MerkleHashesResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MerkleHashesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MerkleHashesResponse) ProtoMessage() {}
+
+func (x *MerkleHashesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MerkleHashesResponse.ProtoReflect.Descriptor instead.
+func (*MerkleHashesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *MerkleHashesResponse) GetHashes() [][]byte {
+	if x != nil {
+		return x.Hashes
+	}
+	return nil
+}
+
+// Request message for BucketDigests operation
+type BucketDigestsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Depth         uint32                 `protobuf:"varint,1,opt,name=depth,proto3" json:"depth,omitempty"`
+	Buckets       []uint32               `protobuf:"varint,2,rep,packed,name=buckets,proto3" json:"buckets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BucketDigestsRequest) Reset() {
+	*x = BucketDigestsRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BucketDigestsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BucketDigestsRequest) ProtoMessage() {}
+
+func (x *BucketDigestsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BucketDigestsRequest.ProtoReflect.Descriptor instead.
+func (*BucketDigestsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *BucketDigestsRequest) GetDepth() uint32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+func (x *BucketDigestsRequest) GetBuckets() []uint32 {
+	if x != nil {
+		return x.Buckets
+	}
+	return nil
+}
+
+// KeyDigest identifies the versions a node holds for a key
+type KeyDigest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Digest        []byte                 `protobuf:"bytes,2,opt,name=digest,proto3" json:"digest,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KeyDigest) Reset() {
+	*x = KeyDigest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KeyDigest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyDigest) ProtoMessage() {}
+
+func (x *KeyDigest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyDigest.ProtoReflect.Descriptor instead.
+func (*KeyDigest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *KeyDigest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *KeyDigest) GetDigest() []byte {
+	if x != nil {
+		return x.Digest
+	}
+	return nil
+}
+
+// Response message for BucketDigests operation
+type BucketDigestsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Digests       []*KeyDigest           `protobuf:"bytes,1,rep,name=digests,proto3" json:"digests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BucketDigestsResponse) Reset() {
+	*x = BucketDigestsResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BucketDigestsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BucketDigestsResponse) ProtoMessage() {}
+
+func (x *BucketDigestsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BucketDigestsResponse.ProtoReflect.Descriptor instead.
+func (*BucketDigestsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *BucketDigestsResponse) GetDigests() []*KeyDigest {
+	if x != nil {
+		return x.Digests
+	}
+	return nil
+}
+
+// KeyVersions is the set of siblings of one key
+type KeyVersions struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Versions      []*Version             `protobuf:"bytes,2,rep,name=versions,proto3" json:"versions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *KeyVersions) Reset() {
+	*x = KeyVersions{}
+	mi := &file_proto_keyvalue_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KeyVersions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyVersions) ProtoMessage() {}
+
+func (x *KeyVersions) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyVersions.ProtoReflect.Descriptor instead.
+func (*KeyVersions) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *KeyVersions) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *KeyVersions) GetVersions() []*Version {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+// Request message for Exchange operation
+type ExchangeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []*KeyVersions         `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExchangeRequest) Reset() {
+	*x = ExchangeRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExchangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExchangeRequest) ProtoMessage() {}
+
+func (x *ExchangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExchangeRequest.ProtoReflect.Descriptor instead.
+func (*ExchangeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *ExchangeRequest) GetKeys() []*KeyVersions {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+// Response message for Exchange operation
+type ExchangeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []*KeyVersions         `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExchangeResponse) Reset() {
+	*x = ExchangeResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExchangeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExchangeResponse) ProtoMessage() {}
+
+func (x *ExchangeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExchangeResponse.ProtoReflect.Descriptor instead.
+func (*ExchangeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *ExchangeResponse) GetKeys() []*KeyVersions {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+// Request message for AntiEntropyStatus operation
+type AntiEntropyStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AntiEntropyStatusRequest) Reset() {
+	*x = AntiEntropyStatusRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AntiEntropyStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AntiEntropyStatusRequest) ProtoMessage() {}
+
+func (x *AntiEntropyStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AntiEntropyStatusRequest.ProtoReflect.Descriptor instead.
+func (*AntiEntropyStatusRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{44}
+}
+
+// Response message for AntiEntropyStatus operation
+type AntiEntropyStatusResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Unix milliseconds of the last completed repair round, 0 if none completed
+	LastRepairMillis int64 `protobuf:"varint,1,opt,name=last_repair_millis,json=lastRepairMillis,proto3" json:"last_repair_millis,omitempty"`
+	// Keys found different and exchanged since the node started
+	KeysRepaired  uint64   `protobuf:"varint,2,opt,name=keys_repaired,json=keysRepaired,proto3" json:"keys_repaired,omitempty"`
+	Rounds        uint64   `protobuf:"varint,3,opt,name=rounds,proto3" json:"rounds,omitempty"`
+	LastError     string   `protobuf:"bytes,4,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	Peers         []string `protobuf:"bytes,5,rep,name=peers,proto3" json:"peers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AntiEntropyStatusResponse) Reset() {
+	*x = AntiEntropyStatusResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AntiEntropyStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AntiEntropyStatusResponse) ProtoMessage() {}
+
+func (x *AntiEntropyStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AntiEntropyStatusResponse.ProtoReflect.Descriptor instead.
+func (*AntiEntropyStatusResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *AntiEntropyStatusResponse) GetLastRepairMillis() int64 {
+	if x != nil {
+		return x.LastRepairMillis
+	}
+	return 0
+}
+
+func (x *AntiEntropyStatusResponse) GetKeysRepaired() uint64 {
+	if x != nil {
+		return x.KeysRepaired
+	}
+	return 0
+}
+
+func (x *AntiEntropyStatusResponse) GetRounds() uint64 {
+	if x != nil {
+		return x.Rounds
+	}
+	return 0
+}
+
+func (x *AntiEntropyStatusResponse) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *AntiEntropyStatusResponse) GetPeers() []string {
+	if x != nil {
+		return x.Peers
+	}
+	return nil
+}
+
+// GossipMember is the state of one member. Higher incarnations, set by the member itself, override lower ones.
+type GossipMember struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// gRPC address of the member
+	Addr          string      `protobuf:"bytes,2,opt,name=addr,proto3" json:"addr,omitempty"`
+	State         MemberState `protobuf:"varint,3,opt,name=state,proto3,enum=keyvalue.MemberState" json:"state,omitempty"`
+	Incarnation   uint64      `protobuf:"varint,4,opt,name=incarnation,proto3" json:"incarnation,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GossipMember) Reset() {
+	*x = GossipMember{}
+	mi := &file_proto_keyvalue_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GossipMember) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GossipMember) ProtoMessage() {}
+
+func (x *GossipMember) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GossipMember.ProtoReflect.Descriptor instead.
+func (*GossipMember) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *GossipMember) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GossipMember) GetAddr() string {
+	if x != nil {
+		return x.Addr
+	}
+	return ""
+}
+
+func (x *GossipMember) GetState() MemberState {
+	if x != nil {
+		return x.State
+	}
+	return MemberState_MEMBER_STATE_UNSPECIFIED
+}
+
+func (x *GossipMember) GetIncarnation() uint64 {
+	if x != nil {
+		return x.Incarnation
+	}
+	return 0
+}
+
+// Request message for Ping operation
+type PingRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID the sender expects the receiver to have, so a new member reusing an address is not mistaken for the old one
+	TargetId      string          `protobuf:"bytes,1,opt,name=target_id,json=targetId,proto3" json:"target_id,omitempty"`
+	From          *GossipMember   `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	Updates       []*GossipMember `protobuf:"bytes,3,rep,name=updates,proto3" json:"updates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PingRequest) Reset() {
+	*x = PingRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PingRequest) ProtoMessage() {}
+
+func (x *PingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PingRequest.ProtoReflect.Descriptor instead.
+func (*PingRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *PingRequest) GetTargetId() string {
+	if x != nil {
+		return x.TargetId
+	}
+	return ""
+}
+
+func (x *PingRequest) GetFrom() *GossipMember {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *PingRequest) GetUpdates() []*GossipMember {
+	if x != nil {
+		return x.Updates
+	}
+	return nil
+}
+
+// Response message for Ping operation
+type PingResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Updates       []*GossipMember        `protobuf:"bytes,1,rep,name=updates,proto3" json:"updates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PingResponse) Reset() {
+	*x = PingResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PingResponse) ProtoMessage() {}
+
+func (x *PingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PingResponse.ProtoReflect.Descriptor instead.
+func (*PingResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *PingResponse) GetUpdates() []*GossipMember {
+	if x != nil {
+		return x.Updates
+	}
+	return nil
+}
+
+// Request message for IndirectPing operation
+type IndirectPingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Target        *GossipMember          `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	From          *GossipMember          `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	Updates       []*GossipMember        `protobuf:"bytes,3,rep,name=updates,proto3" json:"updates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IndirectPingRequest) Reset() {
+	*x = IndirectPingRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IndirectPingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IndirectPingRequest) ProtoMessage() {}
+
+func (x *IndirectPingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IndirectPingRequest.ProtoReflect.Descriptor instead.
+func (*IndirectPingRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *IndirectPingRequest) GetTarget() *GossipMember {
+	if x != nil {
+		return x.Target
+	}
+	return nil
+}
+
+func (x *IndirectPingRequest) GetFrom() *GossipMember {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *IndirectPingRequest) GetUpdates() []*GossipMember {
+	if x != nil {
+		return x.Updates
+	}
+	return nil
+}
+
+// Response message for IndirectPing operation
+type IndirectPingResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Whether the target answered the receiver's probe
+	Ack           bool            `protobuf:"varint,1,opt,name=ack,proto3" json:"ack,omitempty"`
+	Updates       []*GossipMember `protobuf:"bytes,2,rep,name=updates,proto3" json:"updates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IndirectPingResponse) Reset() {
+	*x = IndirectPingResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IndirectPingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IndirectPingResponse) ProtoMessage() {}
+
+func (x *IndirectPingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IndirectPingResponse.ProtoReflect.Descriptor instead.
+func (*IndirectPingResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *IndirectPingResponse) GetAck() bool {
+	if x != nil {
+		return x.Ack
+	}
+	return false
+}
+
+func (x *IndirectPingResponse) GetUpdates() []*GossipMember {
+	if x != nil {
+		return x.Updates
+	}
+	return nil
+}
+
+// Request message for Join operation
+type JoinRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Member        *GossipMember          `protobuf:"bytes,1,opt,name=member,proto3" json:"member,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JoinRequest) Reset() {
+	*x = JoinRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JoinRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JoinRequest) ProtoMessage() {}
+
+func (x *JoinRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JoinRequest.ProtoReflect.Descriptor instead.
+func (*JoinRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *JoinRequest) GetMember() *GossipMember {
+	if x != nil {
+		return x.Member
+	}
+	return nil
+}
+
+// Response message for Join operation
+type JoinResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Members       []*GossipMember        `protobuf:"bytes,1,rep,name=members,proto3" json:"members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JoinResponse) Reset() {
+	*x = JoinResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JoinResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JoinResponse) ProtoMessage() {}
+
+func (x *JoinResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JoinResponse.ProtoReflect.Descriptor instead.
+func (*JoinResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *JoinResponse) GetMembers() []*GossipMember {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+// Request message for Members and WatchMembers operations
+type MembersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MembersRequest) Reset() {
+	*x = MembersRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MembersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MembersRequest) ProtoMessage() {}
+
+func (x *MembersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MembersRequest.ProtoReflect.Descriptor instead.
+func (*MembersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{53}
+}
+
+// MemberList is every member known to a node, including itself and recently failed members
+type MemberList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Members       []*GossipMember        `protobuf:"bytes,1,rep,name=members,proto3" json:"members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MemberList) Reset() {
+	*x = MemberList{}
+	mi := &file_proto_keyvalue_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MemberList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MemberList) ProtoMessage() {}
+
+func (x *MemberList) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MemberList.ProtoReflect.Descriptor instead.
+func (*MemberList) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *MemberList) GetMembers() []*GossipMember {
+	if x != nil {
+		return x.Members
+	}
+	return nil
+}
+
+// Request message for Changes operation
+type ChangesRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	FromSeq uint64                 `protobuf:"varint,1,opt,name=from_seq,json=fromSeq,proto3" json:"from_seq,omitempty"`
+	// Consumer group resuming after its committed offset when from_seq is not set
+	Group         string `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChangesRequest) Reset() {
+	*x = ChangesRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangesRequest) ProtoMessage() {}
+
+func (x *ChangesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangesRequest.ProtoReflect.Descriptor instead.
+func (*ChangesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *ChangesRequest) GetFromSeq() uint64 {
+	if x != nil {
+		return x.FromSeq
+	}
+	return 0
+}
+
+func (x *ChangesRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+// Change is one Set or Delete, numbered from 1 in the order it was applied
+type Change struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Seq             uint64                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	Op              MutationOp             `protobuf:"varint,2,opt,name=op,proto3,enum=keyvalue.MutationOp" json:"op,omitempty"`
+	Key             string                 `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	Value           string                 `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	TimestampMillis int64                  `protobuf:"varint,5,opt,name=timestamp_millis,json=timestampMillis,proto3" json:"timestamp_millis,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Change) Reset() {
+	*x = Change{}
+	mi := &file_proto_keyvalue_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Change) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Change) ProtoMessage() {}
+
+func (x *Change) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Change.ProtoReflect.Descriptor instead.
+func (*Change) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *Change) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *Change) GetOp() MutationOp {
+	if x != nil {
+		return x.Op
+	}
+	return MutationOp_MUTATION_OP_UNSPECIFIED
+}
+
+func (x *Change) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Change) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *Change) GetTimestampMillis() int64 {
+	if x != nil {
+		return x.TimestampMillis
+	}
+	return 0
+}
+
+// Request message for CommitOffset operation
+type CommitOffsetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         string                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	Seq           uint64                 `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommitOffsetRequest) Reset() {
+	*x = CommitOffsetRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommitOffsetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommitOffsetRequest) ProtoMessage() {}
+
+func (x *CommitOffsetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommitOffsetRequest.ProtoReflect.Descriptor instead.
+func (*CommitOffsetRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *CommitOffsetRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *CommitOffsetRequest) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+// Response message for CommitOffset operation
+type CommitOffsetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommitOffsetResponse) Reset() {
+	*x = CommitOffsetResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommitOffsetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommitOffsetResponse) ProtoMessage() {}
+
+func (x *CommitOffsetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommitOffsetResponse.ProtoReflect.Descriptor instead.
+func (*CommitOffsetResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{58}
+}
+
+// Request message for GetOffset operation
+type GetOffsetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Group         string                 `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOffsetRequest) Reset() {
+	*x = GetOffsetRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOffsetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOffsetRequest) ProtoMessage() {}
+
+func (x *GetOffsetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOffsetRequest.ProtoReflect.Descriptor instead.
+func (*GetOffsetRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *GetOffsetRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+// Response message for GetOffset operation
+type GetOffsetResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Last change processed by the group, 0 when it never committed
+	CommittedSeq uint64 `protobuf:"varint,1,opt,name=committed_seq,json=committedSeq,proto3" json:"committed_seq,omitempty"`
+	Committed    bool   `protobuf:"varint,2,opt,name=committed,proto3" json:"committed,omitempty"`
+	// Oldest retained change, one past latest_seq when none is retained
+	FirstSeq      uint64 `protobuf:"varint,3,opt,name=first_seq,json=firstSeq,proto3" json:"first_seq,omitempty"`
+	LatestSeq     uint64 `protobuf:"varint,4,opt,name=latest_seq,json=latestSeq,proto3" json:"latest_seq,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOffsetResponse) Reset() {
+	*x = GetOffsetResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOffsetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOffsetResponse) ProtoMessage() {}
+
+func (x *GetOffsetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOffsetResponse.ProtoReflect.Descriptor instead.
+func (*GetOffsetResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *GetOffsetResponse) GetCommittedSeq() uint64 {
+	if x != nil {
+		return x.CommittedSeq
+	}
+	return 0
+}
+
+func (x *GetOffsetResponse) GetCommitted() bool {
+	if x != nil {
+		return x.Committed
+	}
+	return false
+}
+
+func (x *GetOffsetResponse) GetFirstSeq() uint64 {
+	if x != nil {
+		return x.FirstSeq
+	}
+	return 0
+}
+
+func (x *GetOffsetResponse) GetLatestSeq() uint64 {
+	if x != nil {
+		return x.LatestSeq
+	}
+	return 0
+}
+
+// KeyRevision is the value of a key as of a revision of the store
+type KeyRevision struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Revision uint64                 `protobuf:"varint,1,opt,name=revision,proto3" json:"revision,omitempty"`
+	Value    string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// The key was deleted at this revision
+	Deleted         bool  `protobuf:"varint,3,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	TimestampMillis int64 `protobuf:"varint,4,opt,name=timestamp_millis,json=timestampMillis,proto3" json:"timestamp_millis,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *KeyRevision) Reset() {
+	*x = KeyRevision{}
+	mi := &file_proto_keyvalue_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *KeyRevision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyRevision) ProtoMessage() {}
+
+func (x *KeyRevision) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyRevision.ProtoReflect.Descriptor instead.
+func (*KeyRevision) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *KeyRevision) GetRevision() uint64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+func (x *KeyRevision) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *KeyRevision) GetDeleted() bool {
+	if x != nil {
+		return x.Deleted
+	}
+	return false
+}
+
+func (x *KeyRevision) GetTimestampMillis() int64 {
+	if x != nil {
+		return x.TimestampMillis
+	}
+	return 0
+}
+
+// Request message for GetAtRevision operation
+type GetAtRevisionRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Revision of the store, 0 reads the latest one
+	Revision      uint64 `protobuf:"varint,2,opt,name=revision,proto3" json:"revision,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAtRevisionRequest) Reset() {
+	*x = GetAtRevisionRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAtRevisionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAtRevisionRequest) ProtoMessage() {}
+
+func (x *GetAtRevisionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAtRevisionRequest.ProtoReflect.Descriptor instead.
+func (*GetAtRevisionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *GetAtRevisionRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *GetAtRevisionRequest) GetRevision() uint64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+// Request message for GetAtTime operation
+type GetAtTimeRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Key             string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	TimestampMillis int64                  `protobuf:"varint,2,opt,name=timestamp_millis,json=timestampMillis,proto3" json:"timestamp_millis,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GetAtTimeRequest) Reset() {
+	*x = GetAtTimeRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAtTimeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAtTimeRequest) ProtoMessage() {}
+
+func (x *GetAtTimeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAtTimeRequest.ProtoReflect.Descriptor instead.
+func (*GetAtTimeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *GetAtTimeRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *GetAtTimeRequest) GetTimestampMillis() int64 {
+	if x != nil {
+		return x.TimestampMillis
+	}
+	return 0
+}
+
+// Request message for History operation
+type HistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistoryRequest) Reset() {
+	*x = HistoryRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryRequest) ProtoMessage() {}
+
+func (x *HistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryRequest.ProtoReflect.Descriptor instead.
+func (*HistoryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *HistoryRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+// Response message for History operation
+type HistoryResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Revisions []*KeyRevision         `protobuf:"bytes,1,rep,name=revisions,proto3" json:"revisions,omitempty"`
+	// Revisions before this one are no longer retained for any key
+	CompactedRevision uint64 `protobuf:"varint,2,opt,name=compacted_revision,json=compactedRevision,proto3" json:"compacted_revision,omitempty"`
+	CurrentRevision   uint64 `protobuf:"varint,3,opt,name=current_revision,json=currentRevision,proto3" json:"current_revision,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *HistoryResponse) Reset() {
+	*x = HistoryResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryResponse) ProtoMessage() {}
+
+func (x *HistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryResponse.ProtoReflect.Descriptor instead.
+func (*HistoryResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *HistoryResponse) GetRevisions() []*KeyRevision {
+	if x != nil {
+		return x.Revisions
+	}
+	return nil
+}
+
+func (x *HistoryResponse) GetCompactedRevision() uint64 {
+	if x != nil {
+		return x.CompactedRevision
+	}
+	return 0
+}
+
+func (x *HistoryResponse) GetCurrentRevision() uint64 {
+	if x != nil {
+		return x.CurrentRevision
+	}
+	return 0
+}
+
+// Request message for Compact operation
+type CompactRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Revision      uint64                 `protobuf:"varint,1,opt,name=revision,proto3" json:"revision,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompactRequest) Reset() {
+	*x = CompactRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompactRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompactRequest) ProtoMessage() {}
+
+func (x *CompactRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompactRequest.ProtoReflect.Descriptor instead.
+func (*CompactRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *CompactRequest) GetRevision() uint64 {
+	if x != nil {
+		return x.Revision
+	}
+	return 0
+}
+
+// Response message for Compact operation
+type CompactResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Number of revisions dropped
+	Removed       uint64 `protobuf:"varint,1,opt,name=removed,proto3" json:"removed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompactResponse) Reset() {
+	*x = CompactResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompactResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompactResponse) ProtoMessage() {}
+
+func (x *CompactResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompactResponse.ProtoReflect.Descriptor instead.
+func (*CompactResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *CompactResponse) GetRemoved() uint64 {
+	if x != nil {
+		return x.Removed
+	}
+	return 0
+}
+
+// Request message for Grant operation
+type LeaseGrantRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TtlMillis     int64                  `protobuf:"varint,1,opt,name=ttl_millis,json=ttlMillis,proto3" json:"ttl_millis,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaseGrantRequest) Reset() {
+	*x = LeaseGrantRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaseGrantRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaseGrantRequest) ProtoMessage() {}
+
+func (x *LeaseGrantRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaseGrantRequest.ProtoReflect.Descriptor instead.
+func (*LeaseGrantRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{68}
+}
+
+func (x *LeaseGrantRequest) GetTtlMillis() int64 {
+	if x != nil {
+		return x.TtlMillis
+	}
+	return 0
+}
+
+// Request message for KeepAlive operation
+type LeaseKeepAliveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaseKeepAliveRequest) Reset() {
+	*x = LeaseKeepAliveRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[69]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaseKeepAliveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaseKeepAliveRequest) ProtoMessage() {}
+
+func (x *LeaseKeepAliveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[69]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaseKeepAliveRequest.ProtoReflect.Descriptor instead.
+func (*LeaseKeepAliveRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{69}
+}
+
+func (x *LeaseKeepAliveRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+// Request message for Revoke operation
+type LeaseRevokeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaseRevokeRequest) Reset() {
+	*x = LeaseRevokeRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[70]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaseRevokeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaseRevokeRequest) ProtoMessage() {}
+
+func (x *LeaseRevokeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[70]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaseRevokeRequest.ProtoReflect.Descriptor instead.
+func (*LeaseRevokeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{70}
+}
+
+func (x *LeaseRevokeRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+// Response message for Revoke operation
+type LeaseRevokeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaseRevokeResponse) Reset() {
+	*x = LeaseRevokeResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[71]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaseRevokeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaseRevokeResponse) ProtoMessage() {}
+
+func (x *LeaseRevokeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[71]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaseRevokeResponse.ProtoReflect.Descriptor instead.
+func (*LeaseRevokeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{71}
+}
+
+// Request message for TimeToLive operation
+type LeaseTimeToLiveRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaseTimeToLiveRequest) Reset() {
+	*x = LeaseTimeToLiveRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaseTimeToLiveRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaseTimeToLiveRequest) ProtoMessage() {}
+
+func (x *LeaseTimeToLiveRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[72]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaseTimeToLiveRequest.ProtoReflect.Descriptor instead.
+func (*LeaseTimeToLiveRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *LeaseTimeToLiveRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+// LeaseResponse describes a lease
+type LeaseResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	TtlMillis int64                  `protobuf:"varint,2,opt,name=ttl_millis,json=ttlMillis,proto3" json:"ttl_millis,omitempty"`
+	// Time left before the lease expires unless kept alive
+	RemainingMillis int64    `protobuf:"varint,3,opt,name=remaining_millis,json=remainingMillis,proto3" json:"remaining_millis,omitempty"`
+	Keys            []string `protobuf:"bytes,4,rep,name=keys,proto3" json:"keys,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *LeaseResponse) Reset() {
+	*x = LeaseResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[73]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaseResponse) ProtoMessage() {}
+
+func (x *LeaseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[73]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaseResponse.ProtoReflect.Descriptor instead.
+func (*LeaseResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{73}
+}
+
+func (x *LeaseResponse) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *LeaseResponse) GetTtlMillis() int64 {
+	if x != nil {
+		return x.TtlMillis
+	}
+	return 0
+}
+
+func (x *LeaseResponse) GetRemainingMillis() int64 {
+	if x != nil {
+		return x.RemainingMillis
+	}
+	return 0
+}
+
+func (x *LeaseResponse) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+// Request message for Acquire operation
+type AcquireRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	LeaseId       int64                  `protobuf:"varint,3,opt,name=lease_id,json=leaseId,proto3" json:"lease_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AcquireRequest) Reset() {
+	*x = AcquireRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AcquireRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcquireRequest) ProtoMessage() {}
+
+func (x *AcquireRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[74]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcquireRequest.ProtoReflect.Descriptor instead.
+func (*AcquireRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{74}
+}
+
+func (x *AcquireRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *AcquireRequest) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *AcquireRequest) GetLeaseId() int64 {
+	if x != nil {
+		return x.LeaseId
+	}
+	return 0
+}
+
+// Response message for Acquire operation
+type AcquireResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Acquired bool                   `protobuf:"varint,1,opt,name=acquired,proto3" json:"acquired,omitempty"`
+	// Set when acquired, larger than every token handed out before by the service
+	FencingToken uint64 `protobuf:"varint,2,opt,name=fencing_token,json=fencingToken,proto3" json:"fencing_token,omitempty"`
+	// Value of the key, the caller's own value when acquired
+	Holder        string `protobuf:"bytes,3,opt,name=holder,proto3" json:"holder,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AcquireResponse) Reset() {
+	*x = AcquireResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[75]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AcquireResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcquireResponse) ProtoMessage() {}
+
+func (x *AcquireResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[75]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcquireResponse.ProtoReflect.Descriptor instead.
+func (*AcquireResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{75}
+}
+
+func (x *AcquireResponse) GetAcquired() bool {
+	if x != nil {
+		return x.Acquired
+	}
+	return false
+}
+
+func (x *AcquireResponse) GetFencingToken() uint64 {
+	if x != nil {
+		return x.FencingToken
+	}
+	return 0
+}
+
+func (x *AcquireResponse) GetHolder() string {
+	if x != nil {
+		return x.Holder
+	}
+	return ""
+}
+
+// Request message for Watch operation
+type WatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchRequest) Reset() {
+	*x = WatchRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[76]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchRequest) ProtoMessage() {}
+
+func (x *WatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[76]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchRequest.ProtoReflect.Descriptor instead.
+func (*WatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{76}
+}
+
+func (x *WatchRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+// WatchEvent is the value of a watched key. The first event carries the value when the watch started,
+// a delete when the key did not exist.
+type WatchEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Op            MutationOp             `protobuf:"varint,1,opt,name=op,proto3,enum=keyvalue.MutationOp" json:"op,omitempty"`
+	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Value         string                 `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEvent) Reset() {
+	*x = WatchEvent{}
+	mi := &file_proto_keyvalue_proto_msgTypes[77]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEvent) ProtoMessage() {}
+
+func (x *WatchEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[77]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEvent.ProtoReflect.Descriptor instead.
+func (*WatchEvent) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{77}
+}
+
+func (x *WatchEvent) GetOp() MutationOp {
+	if x != nil {
+		return x.Op
+	}
+	return MutationOp_MUTATION_OP_UNSPECIFIED
+}
+
+func (x *WatchEvent) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *WatchEvent) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+// Request message for Export operation
+type ExportRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Prefix string                 `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// Resume after this key, empty to start from the first one
+	StartAfter    string `protobuf:"bytes,2,opt,name=start_after,json=startAfter,proto3" json:"start_after,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportRequest) Reset() {
+	*x = ExportRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[78]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportRequest) ProtoMessage() {}
+
+func (x *ExportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[78]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportRequest.ProtoReflect.Descriptor instead.
+func (*ExportRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{78}
+}
+
+func (x *ExportRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *ExportRequest) GetStartAfter() string {
+	if x != nil {
+		return x.StartAfter
+	}
+	return ""
+}
+
+// Request message for Import operation, a chunk of the pairs to store
+type ImportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mode          ImportMode             `protobuf:"varint,1,opt,name=mode,proto3,enum=keyvalue.ImportMode" json:"mode,omitempty"`
+	Items         []*KeyValuePair        `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportRequest) Reset() {
+	*x = ImportRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[79]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportRequest) ProtoMessage() {}
+
+func (x *ImportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[79]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportRequest.ProtoReflect.Descriptor instead.
+func (*ImportRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{79}
+}
+
+func (x *ImportRequest) GetMode() ImportMode {
+	if x != nil {
+		return x.Mode
+	}
+	return ImportMode_IMPORT_MODE_OVERWRITE
+}
+
+func (x *ImportRequest) GetItems() []*KeyValuePair {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+// Response message for Import operation
+type ImportResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Written       uint64                 `protobuf:"varint,1,opt,name=written,proto3" json:"written,omitempty"`
+	Skipped       uint64                 `protobuf:"varint,2,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportResponse) Reset() {
+	*x = ImportResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[80]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportResponse) ProtoMessage() {}
+
+func (x *ImportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[80]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportResponse.ProtoReflect.Descriptor instead.
+func (*ImportResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{80}
+}
+
+func (x *ImportResponse) GetWritten() uint64 {
+	if x != nil {
+		return x.Written
+	}
+	return 0
+}
+
+func (x *ImportResponse) GetSkipped() uint64 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+// Request message for Backup operation
+type BackupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackupRequest) Reset() {
+	*x = BackupRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[81]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupRequest) ProtoMessage() {}
+
+func (x *BackupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[81]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupRequest.ProtoReflect.Descriptor instead.
+func (*BackupRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{81}
+}
+
+// BackupChunk is the next part of a backup file
+type BackupChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackupChunk) Reset() {
+	*x = BackupChunk{}
+	mi := &file_proto_keyvalue_proto_msgTypes[82]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupChunk) ProtoMessage() {}
+
+func (x *BackupChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[82]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupChunk.ProtoReflect.Descriptor instead.
+func (*BackupChunk) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{82}
+}
+
+func (x *BackupChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// Request message for Restore operation, the next part of a backup file
+type RestoreRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mode          RestoreMode            `protobuf:"varint,1,opt,name=mode,proto3,enum=keyvalue.RestoreMode" json:"mode,omitempty"`
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreRequest) Reset() {
+	*x = RestoreRequest{}
+	mi := &file_proto_keyvalue_proto_msgTypes[83]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreRequest) ProtoMessage() {}
+
+func (x *RestoreRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[83]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreRequest.ProtoReflect.Descriptor instead.
+func (*RestoreRequest) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{83}
+}
+
+func (x *RestoreRequest) GetMode() RestoreMode {
+	if x != nil {
+		return x.Mode
+	}
+	return RestoreMode_RESTORE_MODE_REPLACE
+}
+
+func (x *RestoreRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// Response message for Restore operation
+type RestoreResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Number of pairs in the backup
+	Pairs uint64 `protobuf:"varint,1,opt,name=pairs,proto3" json:"pairs,omitempty"`
+	// When the backup was taken
+	CreatedMillis int64 `protobuf:"varint,2,opt,name=created_millis,json=createdMillis,proto3" json:"created_millis,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreResponse) Reset() {
+	*x = RestoreResponse{}
+	mi := &file_proto_keyvalue_proto_msgTypes[84]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreResponse) ProtoMessage() {}
+
+func (x *RestoreResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_keyvalue_proto_msgTypes[84]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreResponse.ProtoReflect.Descriptor instead.
+func (*RestoreResponse) Descriptor() ([]byte, []int) {
+	return file_proto_keyvalue_proto_rawDescGZIP(), []int{84}
+}
+
+func (x *RestoreResponse) GetPairs() uint64 {
+	if x != nil {
+		return x.Pairs
+	}
+	return 0
+}
+
+func (x *RestoreResponse) GetCreatedMillis() int64 {
+	if x != nil {
+		return x.CreatedMillis
+	}
+	return 0
+}
+
+var File_proto_keyvalue_proto protoreflect.FileDescriptor
+
+const file_proto_keyvalue_proto_rawDesc = "" +
+	"\n" +
+	"\x14proto/keyvalue.proto\x12\bkeyvalue\"[\n" +
+	"\n" +
+	"GetRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12;\n" +
+	"\vconsistency\x18\x02 \x01(\x0e2\x19.keyvalue.ReadConsistencyR\vconsistency\"O\n" +
+	"\vGetResponse\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\tR\x05value\x12\x14\n" +
+	"\x05found\x18\x02 \x01(\bR\x05found\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"l\n" +
+	"\n" +
+	"SetRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\x12\x1b\n" +
+	"\tif_absent\x18\x03 \x01(\bR\bifAbsent\x12\x19\n" +
+	"\blease_id\x18\x04 \x01(\x03R\aleaseId\"=\n" +
+	"\vSetResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"N\n" +
+	"\rDeleteRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x1e\n" +
+	"\bif_value\x18\x02 \x01(\tH\x00R\aifValue\x88\x01\x01B\v\n" +
+	"\t_if_value\"@\n" +
+	"\x0eDeleteResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\":\n" +
+	"\x10IncrementRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05delta\x18\x02 \x01(\x03R\x05delta\")\n" +
+	"\x11IncrementResponse\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\x03R\x05value\"6\n" +
+	"\fKeyValuePair\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\"\\\n" +
+	"\vScanRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\x12\x1f\n" +
+	"\vstart_after\x18\x02 \x01(\tR\n" +
+	"startAfter\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x05R\x05limit\"P\n" +
+	"\fScanResponse\x12,\n" +
+	"\x05items\x18\x01 \x03(\v2\x16.keyvalue.KeyValuePairR\x05items\x12\x12\n" +
+	"\x04more\x18\x02 \x01(\bR\x04more\"%\n" +
+	"\x0fBatchGetRequest\x12\x12\n" +
+	"\x04keys\x18\x01 \x03(\tR\x04keys\"@\n" +
+	"\x10BatchGetResponse\x12,\n" +
+	"\x05items\x18\x01 \x03(\v2\x16.keyvalue.KeyValuePairR\x05items\"?\n" +
+	"\x0fBatchSetRequest\x12,\n" +
+	"\x05items\x18\x01 \x03(\v2\x16.keyvalue.KeyValuePairR\x05items\"\x12\n" +
+	"\x10BatchSetResponse\"\x0f\n" +
+	"\rHealthRequest\"F\n" +
+	"\x0eHealthResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x1c\n" +
+	"\ttimestamp\x18\x02 \x01(\x03R\ttimestamp\"\x80\x01\n" +
+	"\x06Member\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\traft_addr\x18\x02 \x01(\tR\braftAddr\x12\x1b\n" +
+	"\tgrpc_addr\x18\x03 \x01(\tR\bgrpcAddr\x12\x14\n" +
+	"\x05voter\x18\x04 \x01(\bR\x05voter\x12\x16\n" +
+	"\x06leader\x18\x05 \x01(\bR\x06leader\"\\\n" +
+	"\x10AddMemberRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\traft_addr\x18\x02 \x01(\tR\braftAddr\x12\x1b\n" +
+	"\tgrpc_addr\x18\x03 \x01(\tR\bgrpcAddr\"\x13\n" +
+	"\x11AddMemberResponse\"%\n" +
+	"\x13RemoveMemberRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x16\n" +
+	"\x14RemoveMemberResponse\"\x14\n" +
+	"\x12ListMembersRequest\"A\n" +
+	"\x13ListMembersResponse\x12*\n" +
+	"\amembers\x18\x01 \x03(\v2\x10.keyvalue.MemberR\amembers\"C\n" +
+	"\x10ReplicateRequest\x12\x19\n" +
+	"\bfrom_seq\x18\x01 \x01(\x04R\afromSeq\x12\x14\n" +
+	"\x05epoch\x18\x02 \x01(\x04R\x05epoch\"j\n" +
+	"\bMutation\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq\x12$\n" +
+	"\x02op\x18\x02 \x01(\x0e2\x14.keyvalue.MutationOpR\x02op\x12\x10\n" +
+	"\x03key\x18\x03 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\tR\x05value\"\xb1\x01\n" +
+	"\rSnapshotChunk\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq\x12>\n" +
+	"\aentries\x18\x02 \x03(\v2$.keyvalue.SnapshotChunk.EntriesEntryR\aentries\x12\x12\n" +
+	"\x04last\x18\x03 \x01(\bR\x04last\x1a:\n" +
+	"\fEntriesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"3\n" +
+	"\tHeartbeat\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq\x12\x14\n" +
+	"\x05epoch\x18\x02 \x01(\x04R\x05epoch\"\xb9\x01\n" +
+	"\x10ReplicationEvent\x125\n" +
+	"\bsnapshot\x18\x01 \x01(\v2\x17.keyvalue.SnapshotChunkH\x00R\bsnapshot\x120\n" +
+	"\bmutation\x18\x02 \x01(\v2\x12.keyvalue.MutationH\x00R\bmutation\x123\n" +
+	"\theartbeat\x18\x03 \x01(\v2\x13.keyvalue.HeartbeatH\x00R\theartbeatB\a\n" +
+	"\x05event\"\x1a\n" +
+	"\x18ReplicationStatusRequest\"\xf2\x01\n" +
+	"\x19ReplicationStatusResponse\x12\x12\n" +
+	"\x04role\x18\x01 \x01(\tR\x04role\x12!\n" +
+	"\fprimary_addr\x18\x02 \x01(\tR\vprimaryAddr\x12\x1f\n" +
+	"\vapplied_seq\x18\x03 \x01(\x04R\n" +
+	"appliedSeq\x12\x1f\n" +
+	"\vprimary_seq\x18\x04 \x01(\x04R\n" +
+	"primarySeq\x12\x1f\n" +
+	"\vlag_entries\x18\x05 \x01(\x04R\n" +
+	"lagEntries\x12\x1d\n" +
+	"\n" +
+	"lag_millis\x18\x06 \x01(\x03R\tlagMillis\x12\x1c\n" +
+	"\tconnected\x18\a \x01(\bR\tconnected\"\xa7\x01\n" +
+	"\aVersion\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\tR\x05value\x12\x18\n" +
+	"\adeleted\x18\x02 \x01(\bR\adeleted\x122\n" +
+	"\x05clock\x18\x03 \x03(\v2\x1c.keyvalue.Version.ClockEntryR\x05clock\x1a8\n" +
+	"\n" +
+	"ClockEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x04R\x05value:\x028\x01\"&\n" +
+	"\x12GetVersionsRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"D\n" +
+	"\x13GetVersionsResponse\x12-\n" +
+	"\bversions\x18\x01 \x03(\v2\x11.keyvalue.VersionR\bversions\"U\n" +
+	"\x12PutVersionsRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12-\n" +
+	"\bversions\x18\x02 \x03(\v2\x11.keyvalue.VersionR\bversions\"D\n" +
+	"\x13PutVersionsResponse\x12-\n" +
+	"\bversions\x18\x01 \x03(\v2\x11.keyvalue.VersionR\bversions\"[\n" +
+	"\x13MerkleHashesRequest\x12\x14\n" +
+	"\x05depth\x18\x01 \x01(\rR\x05depth\x12\x14\n" +
+	"\x05level\x18\x02 \x01(\rR\x05level\x12\x18\n" +
+	"\aindexes\x18\x03 \x03(\rR\aindexes\".\n" +
+	"\x14MerkleHashesResponse\x12\x16\n" +
+	"\x06hashes\x18\x01 \x03(\fR\x06hashes\"F\n" +
+	"\x14BucketDigestsRequest\x12\x14\n" +
+	"\x05depth\x18\x01 \x01(\rR\x05depth\x12\x18\n" +
+	"\abuckets\x18\x02 \x03(\rR\abuckets\"5\n" +
+	"\tKeyDigest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x16\n" +
+	"\x06digest\x18\x02 \x01(\fR\x06digest\"F\n" +
+	"\x15BucketDigestsResponse\x12-\n" +
+	"\adigests\x18\x01 \x03(\v2\x13.keyvalue.KeyDigestR\adigests\"N\n" +
+	"\vKeyVersions\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12-\n" +
+	"\bversions\x18\x02 \x03(\v2\x11.keyvalue.VersionR\bversions\"<\n" +
+	"\x0fExchangeRequest\x12)\n" +
+	"\x04keys\x18\x01 \x03(\v2\x15.keyvalue.KeyVersionsR\x04keys\"=\n" +
+	"\x10ExchangeResponse\x12)\n" +
+	"\x04keys\x18\x01 \x03(\v2\x15.keyvalue.KeyVersionsR\x04keys\"\x1a\n" +
+	"\x18AntiEntropyStatusRequest\"\xbb\x01\n" +
+	"\x19AntiEntropyStatusResponse\x12,\n" +
+	"\x12last_repair_millis\x18\x01 \x01(\x03R\x10lastRepairMillis\x12#\n" +
+	"\rkeys_repaired\x18\x02 \x01(\x04R\fkeysRepaired\x12\x16\n" +
+	"\x06rounds\x18\x03 \x01(\x04R\x06rounds\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x04 \x01(\tR\tlastError\x12\x14\n" +
+	"\x05peers\x18\x05 \x03(\tR\x05peers\"\x81\x01\n" +
+	"\fGossipMember\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04addr\x18\x02 \x01(\tR\x04addr\x12+\n" +
+	"\x05state\x18\x03 \x01(\x0e2\x15.keyvalue.MemberStateR\x05state\x12 \n" +
+	"\vincarnation\x18\x04 \x01(\x04R\vincarnation\"\x88\x01\n" +
+	"\vPingRequest\x12\x1b\n" +
+	"\ttarget_id\x18\x01 \x01(\tR\btargetId\x12*\n" +
+	"\x04from\x18\x02 \x01(\v2\x16.keyvalue.GossipMemberR\x04from\x120\n" +
+	"\aupdates\x18\x03 \x03(\v2\x16.keyvalue.GossipMemberR\aupdates\"@\n" +
+	"\fPingResponse\x120\n" +
+	"\aupdates\x18\x01 \x03(\v2\x16.keyvalue.GossipMemberR\aupdates\"\xa3\x01\n" +
+	"\x13IndirectPingRequest\x12.\n" +
+	"\x06target\x18\x01 \x01(\v2\x16.keyvalue.GossipMemberR\x06target\x12*\n" +
+	"\x04from\x18\x02 \x01(\v2\x16.keyvalue.GossipMemberR\x04from\x120\n" +
+	"\aupdates\x18\x03 \x03(\v2\x16.keyvalue.GossipMemberR\aupdates\"Z\n" +
+	"\x14IndirectPingResponse\x12\x10\n" +
+	"\x03ack\x18\x01 \x01(\bR\x03ack\x120\n" +
+	"\aupdates\x18\x02 \x03(\v2\x16.keyvalue.GossipMemberR\aupdates\"=\n" +
+	"\vJoinRequest\x12.\n" +
+	"\x06member\x18\x01 \x01(\v2\x16.keyvalue.GossipMemberR\x06member\"@\n" +
+	"\fJoinResponse\x120\n" +
+	"\amembers\x18\x01 \x03(\v2\x16.keyvalue.GossipMemberR\amembers\"\x10\n" +
+	"\x0eMembersRequest\">\n" +
+	"\n" +
+	"MemberList\x120\n" +
+	"\amembers\x18\x01 \x03(\v2\x16.keyvalue.GossipMemberR\amembers\"A\n" +
+	"\x0eChangesRequest\x12\x19\n" +
+	"\bfrom_seq\x18\x01 \x01(\x04R\afromSeq\x12\x14\n" +
+	"\x05group\x18\x02 \x01(\tR\x05group\"\x93\x01\n" +
+	"\x06Change\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq\x12$\n" +
+	"\x02op\x18\x02 \x01(\x0e2\x14.keyvalue.MutationOpR\x02op\x12\x10\n" +
+	"\x03key\x18\x03 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\tR\x05value\x12)\n" +
+	"\x10timestamp_millis\x18\x05 \x01(\x03R\x0ftimestampMillis\"=\n" +
+	"\x13CommitOffsetRequest\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\x12\x10\n" +
+	"\x03seq\x18\x02 \x01(\x04R\x03seq\"\x16\n" +
+	"\x14CommitOffsetResponse\"(\n" +
+	"\x10GetOffsetRequest\x12\x14\n" +
+	"\x05group\x18\x01 \x01(\tR\x05group\"\x92\x01\n" +
+	"\x11GetOffsetResponse\x12#\n" +
+	"\rcommitted_seq\x18\x01 \x01(\x04R\fcommittedSeq\x12\x1c\n" +
+	"\tcommitted\x18\x02 \x01(\bR\tcommitted\x12\x1b\n" +
+	"\tfirst_seq\x18\x03 \x01(\x04R\bfirstSeq\x12\x1d\n" +
+	"\n" +
+	"latest_seq\x18\x04 \x01(\x04R\tlatestSeq\"\x84\x01\n" +
+	"\vKeyRevision\x12\x1a\n" +
+	"\brevision\x18\x01 \x01(\x04R\brevision\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\x12\x18\n" +
+	"\adeleted\x18\x03 \x01(\bR\adeleted\x12)\n" +
+	"\x10timestamp_millis\x18\x04 \x01(\x03R\x0ftimestampMillis\"D\n" +
+	"\x14GetAtRevisionRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x1a\n" +
+	"\brevision\x18\x02 \x01(\x04R\brevision\"O\n" +
+	"\x10GetAtTimeRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12)\n" +
+	"\x10timestamp_millis\x18\x02 \x01(\x03R\x0ftimestampMillis\"\"\n" +
+	"\x0eHistoryRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"\xa0\x01\n" +
+	"\x0fHistoryResponse\x123\n" +
+	"\trevisions\x18\x01 \x03(\v2\x15.keyvalue.KeyRevisionR\trevisions\x12-\n" +
+	"\x12compacted_revision\x18\x02 \x01(\x04R\x11compactedRevision\x12)\n" +
+	"\x10current_revision\x18\x03 \x01(\x04R\x0fcurrentRevision\",\n" +
+	"\x0eCompactRequest\x12\x1a\n" +
+	"\brevision\x18\x01 \x01(\x04R\brevision\"+\n" +
+	"\x0fCompactResponse\x12\x18\n" +
+	"\aremoved\x18\x01 \x01(\x04R\aremoved\"2\n" +
+	"\x11LeaseGrantRequest\x12\x1d\n" +
+	"\n" +
+	"ttl_millis\x18\x01 \x01(\x03R\tttlMillis\"'\n" +
+	"\x15LeaseKeepAliveRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"$\n" +
+	"\x12LeaseRevokeRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x15\n" +
+	"\x13LeaseRevokeResponse\"(\n" +
+	"\x16LeaseTimeToLiveRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"}\n" +
+	"\rLeaseResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"ttl_millis\x18\x02 \x01(\x03R\tttlMillis\x12)\n" +
+	"\x10remaining_millis\x18\x03 \x01(\x03R\x0fremainingMillis\x12\x12\n" +
+	"\x04keys\x18\x04 \x03(\tR\x04keys\"S\n" +
+	"\x0eAcquireRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\x12\x19\n" +
+	"\blease_id\x18\x03 \x01(\x03R\aleaseId\"j\n" +
+	"\x0fAcquireResponse\x12\x1a\n" +
+	"\bacquired\x18\x01 \x01(\bR\bacquired\x12#\n" +
+	"\rfencing_token\x18\x02 \x01(\x04R\ffencingToken\x12\x16\n" +
+	"\x06holder\x18\x03 \x01(\tR\x06holder\" \n" +
+	"\fWatchRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"Z\n" +
+	"\n" +
+	"WatchEvent\x12$\n" +
+	"\x02op\x18\x01 \x01(\x0e2\x14.keyvalue.MutationOpR\x02op\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\tR\x05value\"H\n" +
+	"\rExportRequest\x12\x16\n" +
+	"\x06prefix\x18\x01 \x01(\tR\x06prefix\x12\x1f\n" +
+	"\vstart_after\x18\x02 \x01(\tR\n" +
+	"startAfter\"g\n" +
+	"\rImportRequest\x12(\n" +
+	"\x04mode\x18\x01 \x01(\x0e2\x14.keyvalue.ImportModeR\x04mode\x12,\n" +
+	"\x05items\x18\x02 \x03(\v2\x16.keyvalue.KeyValuePairR\x05items\"D\n" +
+	"\x0eImportResponse\x12\x18\n" +
+	"\awritten\x18\x01 \x01(\x04R\awritten\x12\x18\n" +
+	"\askipped\x18\x02 \x01(\x04R\askipped\"\x0f\n" +
+	"\rBackupRequest\"!\n" +
+	"\vBackupChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\"O\n" +
+	"\x0eRestoreRequest\x12)\n" +
+	"\x04mode\x18\x01 \x01(\x0e2\x15.keyvalue.RestoreModeR\x04mode\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\"N\n" +
+	"\x0fRestoreResponse\x12\x14\n" +
+	"\x05pairs\x18\x01 \x01(\x04R\x05pairs\x12%\n" +
+	"\x0ecreated_millis\x18\x02 \x01(\x03R\rcreatedMillis*?\n" +
+	"\vRestoreMode\x12\x18\n" +
+	"\x14RESTORE_MODE_REPLACE\x10\x00\x12\x16\n" +
+	"\x12RESTORE_MODE_MERGE\x10\x01*h\n" +
+	"\n" +
+	"ImportMode\x12\x19\n" +
+	"\x15IMPORT_MODE_OVERWRITE\x10\x00\x12\x1d\n" +
+	"\x19IMPORT_MODE_SKIP_EXISTING\x10\x01\x12 \n" +
+	"\x1cIMPORT_MODE_FAIL_ON_CONFLICT\x10\x02*n\n" +
+	"\x0fReadConsistency\x12\x1c\n" +
+	"\x18READ_CONSISTENCY_DEFAULT\x10\x00\x12!\n" +
+	"\x1dREAD_CONSISTENCY_LINEARIZABLE\x10\x01\x12\x1a\n" +
+	"\x16READ_CONSISTENCY_STALE\x10\x02*V\n" +
+	"\n" +
+	"MutationOp\x12\x1b\n" +
+	"\x17MUTATION_OP_UNSPECIFIED\x10\x00\x12\x13\n" +
+	"\x0fMUTATION_OP_SET\x10\x01\x12\x16\n" +
+	"\x12MUTATION_OP_DELETE\x10\x02*\x8b\x01\n" +
+	"\vMemberState\x12\x1c\n" +
+	"\x18MEMBER_STATE_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12MEMBER_STATE_ALIVE\x10\x01\x12\x18\n" +
+	"\x14MEMBER_STATE_SUSPECT\x10\x02\x12\x15\n" +
+	"\x11MEMBER_STATE_DEAD\x10\x03\x12\x15\n" +
+	"\x11MEMBER_STATE_LEFT\x10\x042\xf6\x03\n" +
+	"\x0fKeyValueService\x122\n" +
+	"\x03Get\x12\x14.keyvalue.GetRequest\x1a\x15.keyvalue.GetResponse\x122\n" +
+	"\x03Set\x12\x14.keyvalue.SetRequest\x1a\x15.keyvalue.SetResponse\x12;\n" +
+	"\x06Delete\x12\x17.keyvalue.DeleteRequest\x1a\x18.keyvalue.DeleteResponse\x12D\n" +
+	"\tIncrement\x12\x1a.keyvalue.IncrementRequest\x1a\x1b.keyvalue.IncrementResponse\x125\n" +
+	"\x04Scan\x12\x15.keyvalue.ScanRequest\x1a\x16.keyvalue.ScanResponse\x12A\n" +
+	"\bBatchGet\x12\x19.keyvalue.BatchGetRequest\x1a\x1a.keyvalue.BatchGetResponse\x12A\n" +
+	"\bBatchSet\x12\x19.keyvalue.BatchSetRequest\x1a\x1a.keyvalue.BatchSetResponse\x12;\n" +
+	"\x06Health\x12\x17.keyvalue.HealthRequest\x1a\x18.keyvalue.HealthResponse2\xf1\x01\n" +
+	"\x0eClusterService\x12D\n" +
+	"\tAddMember\x12\x1a.keyvalue.AddMemberRequest\x1a\x1b.keyvalue.AddMemberResponse\x12M\n" +
+	"\fRemoveMember\x12\x1d.keyvalue.RemoveMemberRequest\x1a\x1e.keyvalue.RemoveMemberResponse\x12J\n" +
+	"\vListMembers\x12\x1c.keyvalue.ListMembersRequest\x1a\x1d.keyvalue.ListMembersResponse2\xb9\x01\n" +
+	"\x12ReplicationService\x12E\n" +
+	"\tReplicate\x12\x1a.keyvalue.ReplicateRequest\x1a\x1a.keyvalue.ReplicationEvent0\x01\x12\\\n" +
+	"\x11ReplicationStatus\x12\".keyvalue.ReplicationStatusRequest\x1a#.keyvalue.ReplicationStatusResponse2\xaa\x01\n" +
+	"\x10VersionedService\x12J\n" +
+	"\vGetVersions\x12\x1c.keyvalue.GetVersionsRequest\x1a\x1d.keyvalue.GetVersionsResponse\x12J\n" +
+	"\vPutVersions\x12\x1c.keyvalue.PutVersionsRequest\x1a\x1d.keyvalue.PutVersionsResponse2\xd6\x02\n" +
+	"\x12AntiEntropyService\x12M\n" +
+	"\fMerkleHashes\x12\x1d.keyvalue.MerkleHashesRequest\x1a\x1e.keyvalue.MerkleHashesResponse\x12P\n" +
+	"\rBucketDigests\x12\x1e.keyvalue.BucketDigestsRequest\x1a\x1f.keyvalue.BucketDigestsResponse\x12A\n" +
+	"\bExchange\x12\x19.keyvalue.ExchangeRequest\x1a\x1a.keyvalue.ExchangeResponse\x12\\\n" +
+	"\x11AntiEntropyStatus\x12\".keyvalue.AntiEntropyStatusRequest\x1a#.keyvalue.AntiEntropyStatusResponse2\xcd\x02\n" +
+	"\x11MembershipService\x125\n" +
+	"\x04Ping\x12\x15.keyvalue.PingRequest\x1a\x16.keyvalue.PingResponse\x12M\n" +
+	"\fIndirectPing\x12\x1d.keyvalue.IndirectPingRequest\x1a\x1e.keyvalue.IndirectPingResponse\x125\n" +
+	"\x04Join\x12\x15.keyvalue.JoinRequest\x1a\x16.keyvalue.JoinResponse\x129\n" +
+	"\aMembers\x12\x18.keyvalue.MembersRequest\x1a\x14.keyvalue.MemberList\x12@\n" +
+	"\fWatchMembers\x12\x18.keyvalue.MembersRequest\x1a\x14.keyvalue.MemberList0\x012\xdd\x01\n" +
+	"\rChangeService\x127\n" +
+	"\aChanges\x12\x18.keyvalue.ChangesRequest\x1a\x10.keyvalue.Change0\x01\x12M\n" +
+	"\fCommitOffset\x12\x1d.keyvalue.CommitOffsetRequest\x1a\x1e.keyvalue.CommitOffsetResponse\x12D\n" +
+	"\tGetOffset\x12\x1a.keyvalue.GetOffsetRequest\x1a\x1b.keyvalue.GetOffsetResponse2\x98\x02\n" +
+	"\x0eHistoryService\x12F\n" +
+	"\rGetAtRevision\x12\x1e.keyvalue.GetAtRevisionRequest\x1a\x15.keyvalue.KeyRevision\x12>\n" +
+	"\tGetAtTime\x12\x1a.keyvalue.GetAtTimeRequest\x1a\x15.keyvalue.KeyRevision\x12>\n" +
+	"\aHistory\x12\x18.keyvalue.HistoryRequest\x1a\x19.keyvalue.HistoryResponse\x12>\n" +
+	"\aCompact\x12\x18.keyvalue.CompactRequest\x1a\x19.keyvalue.CompactResponse2\xe4\x02\n" +
+	"\fLeaseService\x12=\n" +
+	"\x05Grant\x12\x1b.keyvalue.LeaseGrantRequest\x1a\x17.keyvalue.LeaseResponse\x12E\n" +
+	"\tKeepAlive\x12\x1f.keyvalue.LeaseKeepAliveRequest\x1a\x17.keyvalue.LeaseResponse\x12E\n" +
+	"\x06Revoke\x12\x1c.keyvalue.LeaseRevokeRequest\x1a\x1d.keyvalue.LeaseRevokeResponse\x12G\n" +
+	"\n" +
+	"TimeToLive\x12 .keyvalue.LeaseTimeToLiveRequest\x1a\x17.keyvalue.LeaseResponse\x12>\n" +
+	"\aAcquire\x12\x18.keyvalue.AcquireRequest\x1a\x19.keyvalue.AcquireResponse2G\n" +
+	"\fWatchService\x127\n" +
+	"\x05Watch\x12\x16.keyvalue.WatchRequest\x1a\x14.keyvalue.WatchEvent0\x012\x89\x01\n" +
+	"\vBulkService\x12;\n" +
+	"\x06Export\x12\x17.keyvalue.ExportRequest\x1a\x16.keyvalue.KeyValuePair0\x01\x12=\n" +
+	"\x06Import\x12\x17.keyvalue.ImportRequest\x1a\x18.keyvalue.ImportResponse(\x012\x8d\x01\n" +
+	"\rBackupService\x12:\n" +
+	"\x06Backup\x12\x17.keyvalue.BackupRequest\x1a\x15.keyvalue.BackupChunk0\x01\x12@\n" +
+	"\aRestore\x12\x18.keyvalue.RestoreRequest\x1a\x19.keyvalue.RestoreResponse(\x01B\x1aZ\x18key-value/proto/keyvalueb\x06proto3"
+
+var (
+	file_proto_keyvalue_proto_rawDescOnce sync.Once
+	file_proto_keyvalue_proto_rawDescData []byte
+)
+
+func file_proto_keyvalue_proto_rawDescGZIP() []byte {
+	file_proto_keyvalue_proto_rawDescOnce.Do(func() {
+		file_proto_keyvalue_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_keyvalue_proto_rawDesc), len(file_proto_keyvalue_proto_rawDesc)))
+	})
+	return file_proto_keyvalue_proto_rawDescData
+}
+
+var file_proto_keyvalue_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_proto_keyvalue_proto_msgTypes = make([]protoimpl.MessageInfo, 87)
+var file_proto_keyvalue_proto_goTypes = []any{
+	(RestoreMode)(0),                  // 0: keyvalue.RestoreMode
+	(ImportMode)(0),                   // 1: keyvalue.ImportMode
+	(ReadConsistency)(0),              // 2: keyvalue.ReadConsistency
+	(MutationOp)(0),                   // 3: keyvalue.MutationOp
+	(MemberState)(0),                  // 4: keyvalue.MemberState
+	(*GetRequest)(nil),                // 5: keyvalue.GetRequest
+	(*GetResponse)(nil),               // 6: keyvalue.GetResponse
+	(*SetRequest)(nil),                // 7: keyvalue.SetRequest
+	(*SetResponse)(nil),               // 8: keyvalue.SetResponse
+	(*DeleteRequest)(nil),             // 9: keyvalue.DeleteRequest
+	(*DeleteResponse)(nil),            // 10: keyvalue.DeleteResponse
+	(*IncrementRequest)(nil),          // 11: keyvalue.IncrementRequest
+	(*IncrementResponse)(nil),         // 12: keyvalue.IncrementResponse
+	(*KeyValuePair)(nil),              // 13: keyvalue.KeyValuePair
+	(*ScanRequest)(nil),               // 14: keyvalue.ScanRequest
+	(*ScanResponse)(nil),              // 15: keyvalue.ScanResponse
+	(*BatchGetRequest)(nil),           // 16: keyvalue.BatchGetRequest
+	(*BatchGetResponse)(nil),          // 17: keyvalue.BatchGetResponse
+	(*BatchSetRequest)(nil),           // 18: keyvalue.BatchSetRequest
+	(*BatchSetResponse)(nil),          // 19: keyvalue.BatchSetResponse
+	(*HealthRequest)(nil),             // 20: keyvalue.HealthRequest
+	(*HealthResponse)(nil),            // 21: keyvalue.HealthResponse
+	(*Member)(nil),                    // 22: keyvalue.Member
+	(*AddMemberRequest)(nil),          // 23: keyvalue.AddMemberRequest
+	(*AddMemberResponse)(nil),         // 24: keyvalue.AddMemberResponse
+	(*RemoveMemberRequest)(nil),       // 25: keyvalue.RemoveMemberRequest
+	(*RemoveMemberResponse)(nil),      // 26: keyvalue.RemoveMemberResponse
+	(*ListMembersRequest)(nil),        // 27: keyvalue.ListMembersRequest
+	(*ListMembersResponse)(nil),       // 28: keyvalue.ListMembersResponse
+	(*ReplicateRequest)(nil),          // 29: keyvalue.ReplicateRequest
+	(*Mutation)(nil),                  // 30: keyvalue.Mutation
+	(*SnapshotChunk)(nil),             // 31: keyvalue.SnapshotChunk
+	(*Heartbeat)(nil),                 // 32: keyvalue.Heartbeat
+	(*ReplicationEvent)(nil),          // 33: keyvalue.ReplicationEvent
+	(*ReplicationStatusRequest)(nil),  // 34: keyvalue.ReplicationStatusRequest
+	(*ReplicationStatusResponse)(nil), // 35: keyvalue.ReplicationStatusResponse
+	(*Version)(nil),                   // 36: keyvalue.Version
+	(*GetVersionsRequest)(nil),        // 37: keyvalue.GetVersionsRequest
+	(*GetVersionsResponse)(nil),       // 38: keyvalue.GetVersionsResponse
+	(*PutVersionsRequest)(nil),        // 39: keyvalue.PutVersionsRequest
+	(*PutVersionsResponse)(nil),       // 40: keyvalue.PutVersionsResponse
+	(*MerkleHashesRequest)(nil),       // 41: keyvalue.MerkleHashesRequest
+	(*MerkleHashesResponse)(nil),      // 42: keyvalue.MerkleHashesResponse
+	(*BucketDigestsRequest)(nil),      // 43: keyvalue.BucketDigestsRequest
+	(*KeyDigest)(nil),                 // 44: keyvalue.KeyDigest
+	(*BucketDigestsResponse)(nil),     // 45: keyvalue.BucketDigestsResponse
+	(*KeyVersions)(nil),               // 46: keyvalue.KeyVersions
+	(*ExchangeRequest)(nil),           // 47: keyvalue.ExchangeRequest
+	(*ExchangeResponse)(nil),          // 48: keyvalue.ExchangeResponse
+	(*AntiEntropyStatusRequest)(nil),  // 49: keyvalue.AntiEntropyStatusRequest
+	(*AntiEntropyStatusResponse)(nil), // 50: keyvalue.AntiEntropyStatusResponse
+	(*GossipMember)(nil),              // 51: keyvalue.GossipMember
+	(*PingRequest)(nil),               // 52: keyvalue.PingRequest
+	(*PingResponse)(nil),              // 53: keyvalue.PingResponse
+	(*IndirectPingRequest)(nil),       // 54: keyvalue.IndirectPingRequest
+	(*IndirectPingResponse)(nil),      // 55: keyvalue.IndirectPingResponse
+	(*JoinRequest)(nil),               // 56: keyvalue.JoinRequest
+	(*JoinResponse)(nil),              // 57: keyvalue.JoinResponse
+	(*MembersRequest)(nil),            // 58: keyvalue.MembersRequest
+	(*MemberList)(nil),                // 59: keyvalue.MemberList
+	(*ChangesRequest)(nil),            // 60: keyvalue.ChangesRequest
+	(*Change)(nil),                    // 61: keyvalue.Change
+	(*CommitOffsetRequest)(nil),       // 62: keyvalue.CommitOffsetRequest
+	(*CommitOffsetResponse)(nil),      // 63: keyvalue.CommitOffsetResponse
+	(*GetOffsetRequest)(nil),          // 64: keyvalue.GetOffsetRequest
+	(*GetOffsetResponse)(nil),         // 65: keyvalue.GetOffsetResponse
+	(*KeyRevision)(nil),               // 66: keyvalue.KeyRevision
+	(*GetAtRevisionRequest)(nil),      // 67: keyvalue.GetAtRevisionRequest
+	(*GetAtTimeRequest)(nil),          // 68: keyvalue.GetAtTimeRequest
+	(*HistoryRequest)(nil),            // 69: keyvalue.HistoryRequest
+	(*HistoryResponse)(nil),           // 70: keyvalue.HistoryResponse
+	(*CompactRequest)(nil),            // 71: keyvalue.CompactRequest
+	(*CompactResponse)(nil),           // 72: keyvalue.CompactResponse
+	(*LeaseGrantRequest)(nil),         // 73: keyvalue.LeaseGrantRequest
+	(*LeaseKeepAliveRequest)(nil),     // 74: keyvalue.LeaseKeepAliveRequest
+	(*LeaseRevokeRequest)(nil),        // 75: keyvalue.LeaseRevokeRequest
+	(*LeaseRevokeResponse)(nil),       // 76: keyvalue.LeaseRevokeResponse
+	(*LeaseTimeToLiveRequest)(nil),    // 77: keyvalue.LeaseTimeToLiveRequest
+	(*LeaseResponse)(nil),             // 78: keyvalue.LeaseResponse
+	(*AcquireRequest)(nil),            // 79: keyvalue.AcquireRequest
+	(*AcquireResponse)(nil),           // 80: keyvalue.AcquireResponse
+	(*WatchRequest)(nil),              // 81: keyvalue.WatchRequest
+	(*WatchEvent)(nil),                // 82: keyvalue.WatchEvent
+	(*ExportRequest)(nil),             // 83: keyvalue.ExportRequest
+	(*ImportRequest)(nil),             // 84: keyvalue.ImportRequest
+	(*ImportResponse)(nil),            // 85: keyvalue.ImportResponse
+	(*BackupRequest)(nil),             // 86: keyvalue.BackupRequest
+	(*BackupChunk)(nil),               // 87: keyvalue.BackupChunk
+	(*RestoreRequest)(nil),            // 88: keyvalue.RestoreRequest
+	(*RestoreResponse)(nil),           // 89: keyvalue.RestoreResponse
+	nil,                               // 90: keyvalue.SnapshotChunk.EntriesEntry
+	nil,                               // 91: keyvalue.Version.ClockEntry
+}
+var file_proto_keyvalue_proto_depIdxs = []int32{
+	2,  // 0: keyvalue.GetRequest.consistency:type_name -> keyvalue.ReadConsistency
+	13, // 1: keyvalue.ScanResponse.items:type_name -> keyvalue.KeyValuePair
+	13, // 2: keyvalue.BatchGetResponse.items:type_name -> keyvalue.KeyValuePair
+	13, // 3: keyvalue.BatchSetRequest.items:type_name -> keyvalue.KeyValuePair
+	22, // 4: keyvalue.ListMembersResponse.members:type_name -> keyvalue.Member
+	3,  // 5: keyvalue.Mutation.op:type_name -> keyvalue.MutationOp
+	90, // 6: keyvalue.SnapshotChunk.entries:type_name -> keyvalue.SnapshotChunk.EntriesEntry
+	31, // 7: keyvalue.ReplicationEvent.snapshot:type_name -> keyvalue.SnapshotChunk
+	30, // 8: keyvalue.ReplicationEvent.mutation:type_name -> keyvalue.Mutation
+	32, // 9: keyvalue.ReplicationEvent.heartbeat:type_name -> keyvalue.Heartbeat
+	91, // 10: keyvalue.Version.clock:type_name -> keyvalue.Version.ClockEntry
+	36, // 11: keyvalue.GetVersionsResponse.versions:type_name -> keyvalue.Version
+	36, // 12: keyvalue.PutVersionsRequest.versions:type_name -> keyvalue.Version
+	36, // 13: keyvalue.PutVersionsResponse.versions:type_name -> keyvalue.Version
+	44, // 14: keyvalue.BucketDigestsResponse.digests:type_name -> keyvalue.KeyDigest
+	36, // 15: keyvalue.KeyVersions.versions:type_name -> keyvalue.Version
+	46, // 16: keyvalue.ExchangeRequest.keys:type_name -> keyvalue.KeyVersions
+	46, // 17: keyvalue.ExchangeResponse.keys:type_name -> keyvalue.KeyVersions
+	4,  // 18: keyvalue.GossipMember.state:type_name -> keyvalue.MemberState
+	51, // 19: keyvalue.PingRequest.from:type_name -> keyvalue.GossipMember
+	51, // 20: keyvalue.PingRequest.updates:type_name -> keyvalue.GossipMember
+	51, // 21: keyvalue.PingResponse.updates:type_name -> keyvalue.GossipMember
+	51, // 22: keyvalue.IndirectPingRequest.target:type_name -> keyvalue.GossipMember
+	51, // 23: keyvalue.IndirectPingRequest.from:type_name -> keyvalue.GossipMember
+	51, // 24: keyvalue.IndirectPingRequest.updates:type_name -> keyvalue.GossipMember
+	51, // 25: keyvalue.IndirectPingResponse.updates:type_name -> keyvalue.GossipMember
+	51, // 26: keyvalue.JoinRequest.member:type_name -> keyvalue.GossipMember
+	51, // 27: keyvalue.JoinResponse.members:type_name -> keyvalue.GossipMember
+	51, // 28: keyvalue.MemberList.members:type_name -> keyvalue.GossipMember
+	3,  // 29: keyvalue.Change.op:type_name -> keyvalue.MutationOp
+	66, // 30: keyvalue.HistoryResponse.revisions:type_name -> keyvalue.KeyRevision
+	3,  // 31: keyvalue.WatchEvent.op:type_name -> keyvalue.MutationOp
+	1,  // 32: keyvalue.ImportRequest.mode:type_name -> keyvalue.ImportMode
+	13, // 33: keyvalue.ImportRequest.items:type_name -> keyvalue.KeyValuePair
+	0,  // 34: keyvalue.RestoreRequest.mode:type_name -> keyvalue.RestoreMode
+	5,  // 35: keyvalue.KeyValueService.Get:input_type -> keyvalue.GetRequest
+	7,  // 36: keyvalue.KeyValueService.Set:input_type -> keyvalue.SetRequest
+	9,  // 37: keyvalue.KeyValueService.Delete:input_type -> keyvalue.DeleteRequest
+	11, // 38: keyvalue.KeyValueService.Increment:input_type -> keyvalue.IncrementRequest
+	14, // 39: keyvalue.KeyValueService.Scan:input_type -> keyvalue.ScanRequest
+	16, // 40: keyvalue.KeyValueService.BatchGet:input_type -> keyvalue.BatchGetRequest
+	18, // 41: keyvalue.KeyValueService.BatchSet:input_type -> keyvalue.BatchSetRequest
+	20, // 42: keyvalue.KeyValueService.Health:input_type -> keyvalue.HealthRequest
+	23, // 43: keyvalue.ClusterService.AddMember:input_type -> keyvalue.AddMemberRequest
+	25, // 44: keyvalue.ClusterService.RemoveMember:input_type -> keyvalue.RemoveMemberRequest
+	27, // 45: keyvalue.ClusterService.ListMembers:input_type -> keyvalue.ListMembersRequest
+	29, // 46: keyvalue.ReplicationService.Replicate:input_type -> keyvalue.ReplicateRequest
+	34, // 47: keyvalue.ReplicationService.ReplicationStatus:input_type -> keyvalue.ReplicationStatusRequest
+	37, // 48: keyvalue.VersionedService.GetVersions:input_type -> keyvalue.GetVersionsRequest
+	39, // 49: keyvalue.VersionedService.PutVersions:input_type -> keyvalue.PutVersionsRequest
+	41, // 50: keyvalue.AntiEntropyService.MerkleHashes:input_type -> keyvalue.MerkleHashesRequest
+	43, // 51: keyvalue.AntiEntropyService.BucketDigests:input_type -> keyvalue.BucketDigestsRequest
+	47, // 52: keyvalue.AntiEntropyService.Exchange:input_type -> keyvalue.ExchangeRequest
+	49, // 53: keyvalue.AntiEntropyService.AntiEntropyStatus:input_type -> keyvalue.AntiEntropyStatusRequest
+	52, // 54: keyvalue.MembershipService.Ping:input_type -> keyvalue.PingRequest
+	54, // 55: keyvalue.MembershipService.IndirectPing:input_type -> keyvalue.IndirectPingRequest
+	56, // 56: keyvalue.MembershipService.Join:input_type -> keyvalue.JoinRequest
+	58, // 57: keyvalue.MembershipService.Members:input_type -> keyvalue.MembersRequest
+	58, // 58: keyvalue.MembershipService.WatchMembers:input_type -> keyvalue.MembersRequest
+	60, // 59: keyvalue.ChangeService.Changes:input_type -> keyvalue.ChangesRequest
+	62, // 60: keyvalue.ChangeService.CommitOffset:input_type -> keyvalue.CommitOffsetRequest
+	64, // 61: keyvalue.ChangeService.GetOffset:input_type -> keyvalue.GetOffsetRequest
+	67, // 62: keyvalue.HistoryService.GetAtRevision:input_type -> keyvalue.GetAtRevisionRequest
+	68, // 63: keyvalue.HistoryService.GetAtTime:input_type -> keyvalue.GetAtTimeRequest
+	69, // 64: keyvalue.HistoryService.History:input_type -> keyvalue.HistoryRequest
+	71, // 65: keyvalue.HistoryService.Compact:input_type -> keyvalue.CompactRequest
+	73, // 66: keyvalue.LeaseService.Grant:input_type -> keyvalue.LeaseGrantRequest
+	74, // 67: keyvalue.LeaseService.KeepAlive:input_type -> keyvalue.LeaseKeepAliveRequest
+	75, // 68: keyvalue.LeaseService.Revoke:input_type -> keyvalue.LeaseRevokeRequest
+	77, // 69: keyvalue.LeaseService.TimeToLive:input_type -> keyvalue.LeaseTimeToLiveRequest
+	79, // 70: keyvalue.LeaseService.Acquire:input_type -> keyvalue.AcquireRequest
+	81, // 71: keyvalue.WatchService.Watch:input_type -> keyvalue.WatchRequest
+	83, // 72: keyvalue.BulkService.Export:input_type -> keyvalue.ExportRequest
+	84, // 73: keyvalue.BulkService.Import:input_type -> keyvalue.ImportRequest
+	86, // 74: keyvalue.BackupService.Backup:input_type -> keyvalue.BackupRequest
+	88, // 75: keyvalue.BackupService.Restore:input_type -> keyvalue.RestoreRequest
+	6,  // 76: keyvalue.KeyValueService.Get:output_type -> keyvalue.GetResponse
+	8,  // 77: keyvalue.KeyValueService.Set:output_type -> keyvalue.SetResponse
+	10, // 78: keyvalue.KeyValueService.Delete:output_type -> keyvalue.DeleteResponse
+	12, // 79: keyvalue.KeyValueService.Increment:output_type -> keyvalue.IncrementResponse
+	15, // 80: keyvalue.KeyValueService.Scan:output_type -> keyvalue.ScanResponse
+	17, // 81: keyvalue.KeyValueService.BatchGet:output_type -> keyvalue.BatchGetResponse
+	19, // 82: keyvalue.KeyValueService.BatchSet:output_type -> keyvalue.BatchSetResponse
+	21, // 83: keyvalue.KeyValueService.Health:output_type -> keyvalue.HealthResponse
+	24, // 84: keyvalue.ClusterService.AddMember:output_type -> keyvalue.AddMemberResponse
+	26, // 85: keyvalue.ClusterService.RemoveMember:output_type -> keyvalue.RemoveMemberResponse
+	28, // 86: keyvalue.ClusterService.ListMembers:output_type -> keyvalue.ListMembersResponse
+	33, // 87: keyvalue.ReplicationService.Replicate:output_type -> keyvalue.ReplicationEvent
+	35, // 88: keyvalue.ReplicationService.ReplicationStatus:output_type -> keyvalue.ReplicationStatusResponse
+	38, // 89: keyvalue.VersionedService.GetVersions:output_type -> keyvalue.GetVersionsResponse
+	40, // 90: keyvalue.VersionedService.PutVersions:output_type -> keyvalue.PutVersionsResponse
+	42, // 91: keyvalue.AntiEntropyService.MerkleHashes:output_type -> keyvalue.MerkleHashesResponse
+	45, // 92: keyvalue.AntiEntropyService.BucketDigests:output_type -> keyvalue.BucketDigestsResponse
+	48, // 93: keyvalue.AntiEntropyService.Exchange:output_type -> keyvalue.ExchangeResponse
+	50, // 94: keyvalue.AntiEntropyService.AntiEntropyStatus:output_type -> keyvalue.AntiEntropyStatusResponse
+	53, // 95: keyvalue.MembershipService.Ping:output_type -> keyvalue.PingResponse
+	55, // 96: keyvalue.MembershipService.IndirectPing:output_type -> keyvalue.IndirectPingResponse
+	57, // 97: keyvalue.MembershipService.Join:output_type -> keyvalue.JoinResponse
+	59, // 98: keyvalue.MembershipService.Members:output_type -> keyvalue.MemberList
+	59, // 99: keyvalue.MembershipService.WatchMembers:output_type -> keyvalue.MemberList
+	61, // 100: keyvalue.ChangeService.Changes:output_type -> keyvalue.Change
+	63, // 101: keyvalue.ChangeService.CommitOffset:output_type -> keyvalue.CommitOffsetResponse
+	65, // 102: keyvalue.ChangeService.GetOffset:output_type -> keyvalue.GetOffsetResponse
+	66, // 103: keyvalue.HistoryService.GetAtRevision:output_type -> keyvalue.KeyRevision
+	66, // 104: keyvalue.HistoryService.GetAtTime:output_type -> keyvalue.KeyRevision
+	70, // 105: keyvalue.HistoryService.History:output_type -> keyvalue.HistoryResponse
+	72, // 106: keyvalue.HistoryService.Compact:output_type -> keyvalue.CompactResponse
+	78, // 107: keyvalue.LeaseService.Grant:output_type -> keyvalue.LeaseResponse
+	78, // 108: keyvalue.LeaseService.KeepAlive:output_type -> keyvalue.LeaseResponse
+	76, // 109: keyvalue.LeaseService.Revoke:output_type -> keyvalue.LeaseRevokeResponse
+	78, // 110: keyvalue.LeaseService.TimeToLive:output_type -> keyvalue.LeaseResponse
+	80, // 111: keyvalue.LeaseService.Acquire:output_type -> keyvalue.AcquireResponse
+	82, // 112: keyvalue.WatchService.Watch:output_type -> keyvalue.WatchEvent
+	13, // 113: keyvalue.BulkService.Export:output_type -> keyvalue.KeyValuePair
+	85, // 114: keyvalue.BulkService.Import:output_type -> keyvalue.ImportResponse
+	87, // 115: keyvalue.BackupService.Backup:output_type -> keyvalue.BackupChunk
+	89, // 116: keyvalue.BackupService.Restore:output_type -> keyvalue.RestoreResponse
+	76, // [76:117] is the sub-list for method output_type
+	35, // [35:76] is the sub-list for method input_type
+	35, // [35:35] is the sub-list for extension type_name
+	35, // [35:35] is the sub-list for extension extendee
+	0,  // [0:35] is the sub-list for field type_name
+}
+
+func init() { file_proto_keyvalue_proto_init() }
+func file_proto_keyvalue_proto_init() {
+	if File_proto_keyvalue_proto != nil {
+		return
+	}
+	file_proto_keyvalue_proto_msgTypes[4].OneofWrappers = []any{}
+	file_proto_keyvalue_proto_msgTypes[28].OneofWrappers = []any{
+		(*ReplicationEvent_Snapshot)(nil),
+		(*ReplicationEvent_Mutation)(nil),
+		(*ReplicationEvent_Heartbeat)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_keyvalue_proto_rawDesc), len(file_proto_keyvalue_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   8,
+			NumEnums:      5,
+			NumMessages:   87,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   12,
 		},
 		GoTypes:           file_proto_keyvalue_proto_goTypes,
 		DependencyIndexes: file_proto_keyvalue_proto_depIdxs,
+		EnumInfos:         file_proto_keyvalue_proto_enumTypes,
 		MessageInfos:      file_proto_keyvalue_proto_msgTypes,
 	}.Build()
 	File_proto_keyvalue_proto = out.File
@@ -19,10 +19,14 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	KeyValueService_Get_FullMethodName    = "/keyvalue.KeyValueService/Get"
-	KeyValueService_Set_FullMethodName    = "/keyvalue.KeyValueService/Set"
-	KeyValueService_Delete_FullMethodName = "/keyvalue.KeyValueService/Delete"
-	KeyValueService_Health_FullMethodName = "/keyvalue.KeyValueService/Health"
+	KeyValueService_Get_FullMethodName       = "/keyvalue.KeyValueService/Get"
+	KeyValueService_Set_FullMethodName       = "/keyvalue.KeyValueService/Set"
+	KeyValueService_Delete_FullMethodName    = "/keyvalue.KeyValueService/Delete"
+	KeyValueService_Increment_FullMethodName = "/keyvalue.KeyValueService/Increment"
+	KeyValueService_Scan_FullMethodName      = "/keyvalue.KeyValueService/Scan"
+	KeyValueService_BatchGet_FullMethodName  = "/keyvalue.KeyValueService/BatchGet"
+	KeyValueService_BatchSet_FullMethodName  = "/keyvalue.KeyValueService/BatchSet"
+	KeyValueService_Health_FullMethodName    = "/keyvalue.KeyValueService/Health"
 )
 
 // KeyValueServiceClient is the client API for KeyValueService service.
@@ -37,6 +41,14 @@ type KeyValueServiceClient interface {
 	Set(ctx context.Context, in *SetRequest, opts ...grpc.CallOption) (*SetResponse, error)
 	// Delete removes a key-value pair
 	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	// Increment atomically adds delta to an integer value, creating the key at 0 if missing
+	Increment(ctx context.Context, in *IncrementRequest, opts ...grpc.CallOption) (*IncrementResponse, error)
+	// Scan lists key-value pairs in key order, optionally restricted to a prefix
+	Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (*ScanResponse, error)
+	// BatchGet retrieves several keys at once, missing keys are left out of the response
+	BatchGet(ctx context.Context, in *BatchGetRequest, opts ...grpc.CallOption) (*BatchGetResponse, error)
+	// BatchSet stores several key-value pairs, in order, stopping at the first failure
+	BatchSet(ctx context.Context, in *BatchSetRequest, opts ...grpc.CallOption) (*BatchSetResponse, error)
 	// Health check for service availability
 	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
 }
@@ -79,6 +91,46 @@ func (c *keyValueServiceClient) Delete(ctx context.Context, in *DeleteRequest, o
 	return out, nil
 }
 
+func (c *keyValueServiceClient) Increment(ctx context.Context, in *IncrementRequest, opts ...grpc.CallOption) (*IncrementResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IncrementResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_Increment_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) Scan(ctx context.Context, in *ScanRequest, opts ...grpc.CallOption) (*ScanResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScanResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_Scan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) BatchGet(ctx context.Context, in *BatchGetRequest, opts ...grpc.CallOption) (*BatchGetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchGetResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_BatchGet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *keyValueServiceClient) BatchSet(ctx context.Context, in *BatchSetRequest, opts ...grpc.CallOption) (*BatchSetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchSetResponse)
+	err := c.cc.Invoke(ctx, KeyValueService_BatchSet_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *keyValueServiceClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(HealthResponse)
@@ -101,6 +153,14 @@ type KeyValueServiceServer interface {
 	Set(context.Context, *SetRequest) (*SetResponse, error)
 	// Delete removes a key-value pair
 	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	// Increment atomically adds delta to an integer value, creating the key at 0 if missing
+	Increment(context.Context, *IncrementRequest) (*IncrementResponse, error)
+	// Scan lists key-value pairs in key order, optionally restricted to a prefix
+	Scan(context.Context, *ScanRequest) (*ScanResponse, error)
+	// BatchGet retrieves several keys at once, missing keys are left out of the response
+	BatchGet(context.Context, *BatchGetRequest) (*BatchGetResponse, error)
+	// BatchSet stores several key-value pairs, in order, stopping at the first failure
+	BatchSet(context.Context, *BatchSetRequest) (*BatchSetResponse, error)
 	// Health check for service availability
 	Health(context.Context, *HealthRequest) (*HealthResponse, error)
 	mustEmbedUnimplementedKeyValueServiceServer()
@@ -122,6 +182,18 @@ func (UnimplementedKeyValueServiceServer) Set(context.Context, *SetRequest) (*Se
 func (UnimplementedKeyValueServiceServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
 }
+func (UnimplementedKeyValueServiceServer) Increment(context.Context, *IncrementRequest) (*IncrementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Increment not implemented")
+}
+func (UnimplementedKeyValueServiceServer) Scan(context.Context, *ScanRequest) (*ScanResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Scan not implemented")
+}
+func (UnimplementedKeyValueServiceServer) BatchGet(context.Context, *BatchGetRequest) (*BatchGetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchGet not implemented")
+}
+func (UnimplementedKeyValueServiceServer) BatchSet(context.Context, *BatchSetRequest) (*BatchSetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchSet not implemented")
+}
 func (UnimplementedKeyValueServiceServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
 }
@@ -200,6 +272,78 @@ func _KeyValueService_Delete_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _KeyValueService_Increment_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IncrementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).Increment(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_Increment_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).Increment(ctx, req.(*IncrementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_Scan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).Scan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_Scan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).Scan(ctx, req.(*ScanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_BatchGet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchGetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).BatchGet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_BatchGet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).BatchGet(ctx, req.(*BatchGetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _KeyValueService_BatchSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchSetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KeyValueServiceServer).BatchSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: KeyValueService_BatchSet_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KeyValueServiceServer).BatchSet(ctx, req.(*BatchSetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _KeyValueService_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HealthRequest)
 	if err := dec(in); err != nil {
@@ -237,6 +381,22 @@ var KeyValueService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Delete",
 			Handler:    _KeyValueService_Delete_Handler,
 		},
+		{
+			MethodName: "Increment",
+			Handler:    _KeyValueService_Increment_Handler,
+		},
+		{
+			MethodName: "Scan",
+			Handler:    _KeyValueService_Scan_Handler,
+		},
+		{
+			MethodName: "BatchGet",
+			Handler:    _KeyValueService_BatchGet_Handler,
+		},
+		{
+			MethodName: "BatchSet",
+			Handler:    _KeyValueService_BatchSet_Handler,
+		},
 		{
 			MethodName: "Health",
 			Handler:    _KeyValueService_Health_Handler,
@@ -245,3 +405,2108 @@ var KeyValueService_ServiceDesc = grpc.ServiceDesc{
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/keyvalue.proto",
 }
+
+const (
+	ClusterService_AddMember_FullMethodName    = "/keyvalue.ClusterService/AddMember"
+	ClusterService_RemoveMember_FullMethodName = "/keyvalue.ClusterService/RemoveMember"
+	ClusterService_ListMembers_FullMethodName  = "/keyvalue.ClusterService/ListMembers"
+)
+
+// ClusterServiceClient is the client API for ClusterService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ClusterService manages the members of a Raft replicated key-value service
+type ClusterServiceClient interface {
+	// AddMember adds a voting member, forwarded to the leader if needed
+	AddMember(ctx context.Context, in *AddMemberRequest, opts ...grpc.CallOption) (*AddMemberResponse, error)
+	// RemoveMember removes a member, forwarded to the leader if needed
+	RemoveMember(ctx context.Context, in *RemoveMemberRequest, opts ...grpc.CallOption) (*RemoveMemberResponse, error)
+	// ListMembers returns the current cluster configuration
+	ListMembers(ctx context.Context, in *ListMembersRequest, opts ...grpc.CallOption) (*ListMembersResponse, error)
+}
+
+type clusterServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewClusterServiceClient(cc grpc.ClientConnInterface) ClusterServiceClient {
+	return &clusterServiceClient{cc}
+}
+
+func (c *clusterServiceClient) AddMember(ctx context.Context, in *AddMemberRequest, opts ...grpc.CallOption) (*AddMemberResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddMemberResponse)
+	err := c.cc.Invoke(ctx, ClusterService_AddMember_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterServiceClient) RemoveMember(ctx context.Context, in *RemoveMemberRequest, opts ...grpc.CallOption) (*RemoveMemberResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveMemberResponse)
+	err := c.cc.Invoke(ctx, ClusterService_RemoveMember_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *clusterServiceClient) ListMembers(ctx context.Context, in *ListMembersRequest, opts ...grpc.CallOption) (*ListMembersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMembersResponse)
+	err := c.cc.Invoke(ctx, ClusterService_ListMembers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ClusterServiceServer is the server API for ClusterService service.
+// All implementations must embed UnimplementedClusterServiceServer
+// for forward compatibility.
+//
+// ClusterService manages the members of a Raft replicated key-value service
+type ClusterServiceServer interface {
+	// AddMember adds a voting member, forwarded to the leader if needed
+	AddMember(context.Context, *AddMemberRequest) (*AddMemberResponse, error)
+	// RemoveMember removes a member, forwarded to the leader if needed
+	RemoveMember(context.Context, *RemoveMemberRequest) (*RemoveMemberResponse, error)
+	// ListMembers returns the current cluster configuration
+	ListMembers(context.Context, *ListMembersRequest) (*ListMembersResponse, error)
+	mustEmbedUnimplementedClusterServiceServer()
+}
+
+// UnimplementedClusterServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedClusterServiceServer struct{}
+
+func (UnimplementedClusterServiceServer) AddMember(context.Context, *AddMemberRequest) (*AddMemberResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddMember not implemented")
+}
+func (UnimplementedClusterServiceServer) RemoveMember(context.Context, *RemoveMemberRequest) (*RemoveMemberResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveMember not implemented")
+}
+func (UnimplementedClusterServiceServer) ListMembers(context.Context, *ListMembersRequest) (*ListMembersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMembers not implemented")
+}
+func (UnimplementedClusterServiceServer) mustEmbedUnimplementedClusterServiceServer() {}
+func (UnimplementedClusterServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeClusterServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ClusterServiceServer will
+// result in compilation errors.
+type UnsafeClusterServiceServer interface {
+	mustEmbedUnimplementedClusterServiceServer()
+}
+
+func RegisterClusterServiceServer(s grpc.ServiceRegistrar, srv ClusterServiceServer) {
+	// If the following call pancis, it indicates UnimplementedClusterServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ClusterService_ServiceDesc, srv)
+}
+
+func _ClusterService_AddMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServiceServer).AddMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClusterService_AddMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServiceServer).AddMember(ctx, req.(*AddMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ClusterService_RemoveMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServiceServer).RemoveMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClusterService_RemoveMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServiceServer).RemoveMember(ctx, req.(*RemoveMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ClusterService_ListMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClusterServiceServer).ListMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClusterService_ListMembers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClusterServiceServer).ListMembers(ctx, req.(*ListMembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ClusterService_ServiceDesc is the grpc.ServiceDesc for ClusterService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ClusterService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "keyvalue.ClusterService",
+	HandlerType: (*ClusterServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddMember",
+			Handler:    _ClusterService_AddMember_Handler,
+		},
+		{
+			MethodName: "RemoveMember",
+			Handler:    _ClusterService_RemoveMember_Handler,
+		},
+		{
+			MethodName: "ListMembers",
+			Handler:    _ClusterService_ListMembers_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/keyvalue.proto",
+}
+
+const (
+	ReplicationService_Replicate_FullMethodName         = "/keyvalue.ReplicationService/Replicate"
+	ReplicationService_ReplicationStatus_FullMethodName = "/keyvalue.ReplicationService/ReplicationStatus"
+)
+
+// ReplicationServiceClient is the client API for ReplicationService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ReplicationService streams the mutation log of a primary to its read replicas
+type ReplicationServiceClient interface {
+	// Replicate streams mutations starting at from_seq, preceded by a snapshot when the log no longer holds from_seq
+	Replicate(ctx context.Context, in *ReplicateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReplicationEvent], error)
+	// ReplicationStatus reports the role of the node and, on followers, how far behind the primary it is
+	ReplicationStatus(ctx context.Context, in *ReplicationStatusRequest, opts ...grpc.CallOption) (*ReplicationStatusResponse, error)
+}
+
+type replicationServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReplicationServiceClient(cc grpc.ClientConnInterface) ReplicationServiceClient {
+	return &replicationServiceClient{cc}
+}
+
+func (c *replicationServiceClient) Replicate(ctx context.Context, in *ReplicateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ReplicationEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ReplicationService_ServiceDesc.Streams[0], ReplicationService_Replicate_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ReplicateRequest, ReplicationEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ReplicationService_ReplicateClient = grpc.ServerStreamingClient[ReplicationEvent]
+
+func (c *replicationServiceClient) ReplicationStatus(ctx context.Context, in *ReplicationStatusRequest, opts ...grpc.CallOption) (*ReplicationStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReplicationStatusResponse)
+	err := c.cc.Invoke(ctx, ReplicationService_ReplicationStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReplicationServiceServer is the server API for ReplicationService service.
+// All implementations must embed UnimplementedReplicationServiceServer
+// for forward compatibility.
+//
+// ReplicationService streams the mutation log of a primary to its read replicas
+type ReplicationServiceServer interface {
+	// Replicate streams mutations starting at from_seq, preceded by a snapshot when the log no longer holds from_seq
+	Replicate(*ReplicateRequest, grpc.ServerStreamingServer[ReplicationEvent]) error
+	// ReplicationStatus reports the role of the node and, on followers, how far behind the primary it is
+	ReplicationStatus(context.Context, *ReplicationStatusRequest) (*ReplicationStatusResponse, error)
+	mustEmbedUnimplementedReplicationServiceServer()
+}
+
+// UnimplementedReplicationServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedReplicationServiceServer struct{}
+
+func (UnimplementedReplicationServiceServer) Replicate(*ReplicateRequest, grpc.ServerStreamingServer[ReplicationEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method Replicate not implemented")
+}
+func (UnimplementedReplicationServiceServer) ReplicationStatus(context.Context, *ReplicationStatusRequest) (*ReplicationStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplicationStatus not implemented")
+}
+func (UnimplementedReplicationServiceServer) mustEmbedUnimplementedReplicationServiceServer() {}
+func (UnimplementedReplicationServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeReplicationServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReplicationServiceServer will
+// result in compilation errors.
+type UnsafeReplicationServiceServer interface {
+	mustEmbedUnimplementedReplicationServiceServer()
+}
+
+func RegisterReplicationServiceServer(s grpc.ServiceRegistrar, srv ReplicationServiceServer) {
+	// If the following call pancis, it indicates UnimplementedReplicationServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ReplicationService_ServiceDesc, srv)
+}
+
+func _ReplicationService_Replicate_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReplicateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ReplicationServiceServer).Replicate(m, &grpc.GenericServerStream[ReplicateRequest, ReplicationEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ReplicationService_ReplicateServer = grpc.ServerStreamingServer[ReplicationEvent]
+
+func _ReplicationService_ReplicationStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReplicationStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReplicationServiceServer).ReplicationStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReplicationService_ReplicationStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReplicationServiceServer).ReplicationStatus(ctx, req.(*ReplicationStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ReplicationService_ServiceDesc is the grpc.ServiceDesc for ReplicationService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ReplicationService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "keyvalue.ReplicationService",
+	HandlerType: (*ReplicationServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ReplicationStatus",
+			Handler:    _ReplicationService_ReplicationStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Replicate",
+			Handler:       _ReplicationService_Replicate_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/keyvalue.proto",
+}
+
+const (
+	VersionedService_GetVersions_FullMethodName = "/keyvalue.VersionedService/GetVersions"
+	VersionedService_PutVersions_FullMethodName = "/keyvalue.VersionedService/PutVersions"
+)
+
+// VersionedServiceClient is the client API for VersionedService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// VersionedService stores vector clocked sibling versions for quorum replication.
+// Each node only reconciles what it is sent, the client coordinates the replicas.
+type VersionedServiceClient interface {
+	// GetVersions returns the concurrent versions of a key, including tombstones
+	GetVersions(ctx context.Context, in *GetVersionsRequest, opts ...grpc.CallOption) (*GetVersionsResponse, error)
+	// PutVersions reconciles versions into the siblings of a key and returns the result
+	PutVersions(ctx context.Context, in *PutVersionsRequest, opts ...grpc.CallOption) (*PutVersionsResponse, error)
+}
+
+type versionedServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewVersionedServiceClient(cc grpc.ClientConnInterface) VersionedServiceClient {
+	return &versionedServiceClient{cc}
+}
+
+func (c *versionedServiceClient) GetVersions(ctx context.Context, in *GetVersionsRequest, opts ...grpc.CallOption) (*GetVersionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetVersionsResponse)
+	err := c.cc.Invoke(ctx, VersionedService_GetVersions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *versionedServiceClient) PutVersions(ctx context.Context, in *PutVersionsRequest, opts ...grpc.CallOption) (*PutVersionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PutVersionsResponse)
+	err := c.cc.Invoke(ctx, VersionedService_PutVersions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// VersionedServiceServer is the server API for VersionedService service.
+// All implementations must embed UnimplementedVersionedServiceServer
+// for forward compatibility.
+//
+// VersionedService stores vector clocked sibling versions for quorum replication.
+// Each node only reconciles what it is sent, the client coordinates the replicas.
+type VersionedServiceServer interface {
+	// GetVersions returns the concurrent versions of a key, including tombstones
+	GetVersions(context.Context, *GetVersionsRequest) (*GetVersionsResponse, error)
+	// PutVersions reconciles versions into the siblings of a key and returns the result
+	PutVersions(context.Context, *PutVersionsRequest) (*PutVersionsResponse, error)
+	mustEmbedUnimplementedVersionedServiceServer()
+}
+
+// UnimplementedVersionedServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedVersionedServiceServer struct{}
+
+func (UnimplementedVersionedServiceServer) GetVersions(context.Context, *GetVersionsRequest) (*GetVersionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetVersions not implemented")
+}
+func (UnimplementedVersionedServiceServer) PutVersions(context.Context, *PutVersionsRequest) (*PutVersionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PutVersions not implemented")
+}
+func (UnimplementedVersionedServiceServer) mustEmbedUnimplementedVersionedServiceServer() {}
+func (UnimplementedVersionedServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeVersionedServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to VersionedServiceServer will
+// result in compilation errors.
+type UnsafeVersionedServiceServer interface {
+	mustEmbedUnimplementedVersionedServiceServer()
+}
+
+func RegisterVersionedServiceServer(s grpc.ServiceRegistrar, srv VersionedServiceServer) {
+	// If the following call pancis, it indicates UnimplementedVersionedServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&VersionedService_ServiceDesc, srv)
+}
+
+func _VersionedService_GetVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetVersionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VersionedServiceServer).GetVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VersionedService_GetVersions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VersionedServiceServer).GetVersions(ctx, req.(*GetVersionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _VersionedService_PutVersions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PutVersionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VersionedServiceServer).PutVersions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: VersionedService_PutVersions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(VersionedServiceServer).PutVersions(ctx, req.(*PutVersionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// VersionedService_ServiceDesc is the grpc.ServiceDesc for VersionedService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var VersionedService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "keyvalue.VersionedService",
+	HandlerType: (*VersionedServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetVersions",
+			Handler:    _VersionedService_GetVersions_Handler,
+		},
+		{
+			MethodName: "PutVersions",
+			Handler:    _VersionedService_PutVersions_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/keyvalue.proto",
+}
+
+const (
+	AntiEntropyService_MerkleHashes_FullMethodName      = "/keyvalue.AntiEntropyService/MerkleHashes"
+	AntiEntropyService_BucketDigests_FullMethodName     = "/keyvalue.AntiEntropyService/BucketDigests"
+	AntiEntropyService_Exchange_FullMethodName          = "/keyvalue.AntiEntropyService/Exchange"
+	AntiEntropyService_AntiEntropyStatus_FullMethodName = "/keyvalue.AntiEntropyService/AntiEntropyStatus"
+)
+
+// AntiEntropyServiceClient is the client API for AntiEntropyService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// AntiEntropyService lets replicas of the versioned keys find and repair their differences.
+// Peers compare Merkle trees over hash ranges of the keys top down, then exchange only the keys that differ.
+type AntiEntropyServiceClient interface {
+	// MerkleHashes returns the hashes of the given nodes of one level of the tree, level 0 being the root
+	MerkleHashes(ctx context.Context, in *MerkleHashesRequest, opts ...grpc.CallOption) (*MerkleHashesResponse, error)
+	// BucketDigests returns a digest of every key in the given leaves of the tree
+	BucketDigests(ctx context.Context, in *BucketDigestsRequest, opts ...grpc.CallOption) (*BucketDigestsResponse, error)
+	// Exchange reconciles the sent versions into the receiver and returns its resulting versions of the same keys
+	Exchange(ctx context.Context, in *ExchangeRequest, opts ...grpc.CallOption) (*ExchangeResponse, error)
+	// AntiEntropyStatus reports when this node last repaired and how many keys it repaired
+	AntiEntropyStatus(ctx context.Context, in *AntiEntropyStatusRequest, opts ...grpc.CallOption) (*AntiEntropyStatusResponse, error)
+}
+
+type antiEntropyServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAntiEntropyServiceClient(cc grpc.ClientConnInterface) AntiEntropyServiceClient {
+	return &antiEntropyServiceClient{cc}
+}
+
+func (c *antiEntropyServiceClient) MerkleHashes(ctx context.Context, in *MerkleHashesRequest, opts ...grpc.CallOption) (*MerkleHashesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MerkleHashesResponse)
+	err := c.cc.Invoke(ctx, AntiEntropyService_MerkleHashes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *antiEntropyServiceClient) BucketDigests(ctx context.Context, in *BucketDigestsRequest, opts ...grpc.CallOption) (*BucketDigestsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BucketDigestsResponse)
+	err := c.cc.Invoke(ctx, AntiEntropyService_BucketDigests_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *antiEntropyServiceClient) Exchange(ctx context.Context, in *ExchangeRequest, opts ...grpc.CallOption) (*ExchangeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExchangeResponse)
+	err := c.cc.Invoke(ctx, AntiEntropyService_Exchange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *antiEntropyServiceClient) AntiEntropyStatus(ctx context.Context, in *AntiEntropyStatusRequest, opts ...grpc.CallOption) (*AntiEntropyStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AntiEntropyStatusResponse)
+	err := c.cc.Invoke(ctx, AntiEntropyService_AntiEntropyStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AntiEntropyServiceServer is the server API for AntiEntropyService service.
+// All implementations must embed UnimplementedAntiEntropyServiceServer
+// for forward compatibility.
+//
+// AntiEntropyService lets replicas of the versioned keys find and repair their differences.
+// Peers compare Merkle trees over hash ranges of the keys top down, then exchange only the keys that differ.
+type AntiEntropyServiceServer interface {
+	// MerkleHashes returns the hashes of the given nodes of one level of the tree, level 0 being the root
+	MerkleHashes(context.Context, *MerkleHashesRequest) (*MerkleHashesResponse, error)
+	// BucketDigests returns a digest of every key in the given leaves of the tree
+	BucketDigests(context.Context, *BucketDigestsRequest) (*BucketDigestsResponse, error)
+	// Exchange reconciles the sent versions into the receiver and returns its resulting versions of the same keys
+	Exchange(context.Context, *ExchangeRequest) (*ExchangeResponse, error)
+	// AntiEntropyStatus reports when this node last repaired and how many keys it repaired
+	AntiEntropyStatus(context.Context, *AntiEntropyStatusRequest) (*AntiEntropyStatusResponse, error)
+	mustEmbedUnimplementedAntiEntropyServiceServer()
+}
+
+// UnimplementedAntiEntropyServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAntiEntropyServiceServer struct{}
+
+func (UnimplementedAntiEntropyServiceServer) MerkleHashes(context.Context, *MerkleHashesRequest) (*MerkleHashesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MerkleHashes not implemented")
+}
+func (UnimplementedAntiEntropyServiceServer) BucketDigests(context.Context, *BucketDigestsRequest) (*BucketDigestsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BucketDigests not implemented")
+}
+func (UnimplementedAntiEntropyServiceServer) Exchange(context.Context, *ExchangeRequest) (*ExchangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Exchange not implemented")
+}
+func (UnimplementedAntiEntropyServiceServer) AntiEntropyStatus(context.Context, *AntiEntropyStatusRequest) (*AntiEntropyStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AntiEntropyStatus not implemented")
+}
+func (UnimplementedAntiEntropyServiceServer) mustEmbedUnimplementedAntiEntropyServiceServer() {}
+func (UnimplementedAntiEntropyServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeAntiEntropyServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AntiEntropyServiceServer will
+// result in compilation errors.
+type UnsafeAntiEntropyServiceServer interface {
+	mustEmbedUnimplementedAntiEntropyServiceServer()
+}
+
+func RegisterAntiEntropyServiceServer(s grpc.ServiceRegistrar, srv AntiEntropyServiceServer) {
+	// If the following call pancis, it indicates UnimplementedAntiEntropyServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AntiEntropyService_ServiceDesc, srv)
+}
+
+func _AntiEntropyService_MerkleHashes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MerkleHashesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AntiEntropyServiceServer).MerkleHashes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AntiEntropyService_MerkleHashes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AntiEntropyServiceServer).MerkleHashes(ctx, req.(*MerkleHashesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AntiEntropyService_BucketDigests_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BucketDigestsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AntiEntropyServiceServer).BucketDigests(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AntiEntropyService_BucketDigests_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AntiEntropyServiceServer).BucketDigests(ctx, req.(*BucketDigestsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AntiEntropyService_Exchange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExchangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AntiEntropyServiceServer).Exchange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AntiEntropyService_Exchange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AntiEntropyServiceServer).Exchange(ctx, req.(*ExchangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AntiEntropyService_AntiEntropyStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AntiEntropyStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AntiEntropyServiceServer).AntiEntropyStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AntiEntropyService_AntiEntropyStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AntiEntropyServiceServer).AntiEntropyStatus(ctx, req.(*AntiEntropyStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AntiEntropyService_ServiceDesc is the grpc.ServiceDesc for AntiEntropyService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AntiEntropyService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "keyvalue.AntiEntropyService",
+	HandlerType: (*AntiEntropyServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "MerkleHashes",
+			Handler:    _AntiEntropyService_MerkleHashes_Handler,
+		},
+		{
+			MethodName: "BucketDigests",
+			Handler:    _AntiEntropyService_BucketDigests_Handler,
+		},
+		{
+			MethodName: "Exchange",
+			Handler:    _AntiEntropyService_Exchange_Handler,
+		},
+		{
+			MethodName: "AntiEntropyStatus",
+			Handler:    _AntiEntropyService_AntiEntropyStatus_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/keyvalue.proto",
+}
+
+const (
+	MembershipService_Ping_FullMethodName         = "/keyvalue.MembershipService/Ping"
+	MembershipService_IndirectPing_FullMethodName = "/keyvalue.MembershipService/IndirectPing"
+	MembershipService_Join_FullMethodName         = "/keyvalue.MembershipService/Join"
+	MembershipService_Members_FullMethodName      = "/keyvalue.MembershipService/Members"
+	MembershipService_WatchMembers_FullMethodName = "/keyvalue.MembershipService/WatchMembers"
+)
+
+// MembershipServiceClient is the client API for MembershipService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// MembershipService runs SWIM style gossip between key-value services: members probe each other, spread
+// membership changes piggybacked on the probes and publish the resulting member list to clients
+type MembershipServiceClient interface {
+	// Ping is a direct probe, answered with the membership changes the receiver is spreading
+	Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error)
+	// IndirectPing asks the receiver to probe a member the sender could not reach
+	IndirectPing(ctx context.Context, in *IndirectPingRequest, opts ...grpc.CallOption) (*IndirectPingResponse, error)
+	// Join adds the sender to the group and returns every member the receiver knows
+	Join(ctx context.Context, in *JoinRequest, opts ...grpc.CallOption) (*JoinResponse, error)
+	// Members returns the member list as the receiver sees it
+	Members(ctx context.Context, in *MembersRequest, opts ...grpc.CallOption) (*MemberList, error)
+	// WatchMembers streams the member list, once immediately and again after every change
+	WatchMembers(ctx context.Context, in *MembersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MemberList], error)
+}
+
+type membershipServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMembershipServiceClient(cc grpc.ClientConnInterface) MembershipServiceClient {
+	return &membershipServiceClient{cc}
+}
+
+func (c *membershipServiceClient) Ping(ctx context.Context, in *PingRequest, opts ...grpc.CallOption) (*PingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PingResponse)
+	err := c.cc.Invoke(ctx, MembershipService_Ping_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *membershipServiceClient) IndirectPing(ctx context.Context, in *IndirectPingRequest, opts ...grpc.CallOption) (*IndirectPingResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IndirectPingResponse)
+	err := c.cc.Invoke(ctx, MembershipService_IndirectPing_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *membershipServiceClient) Join(ctx context.Context, in *JoinRequest, opts ...grpc.CallOption) (*JoinResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JoinResponse)
+	err := c.cc.Invoke(ctx, MembershipService_Join_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *membershipServiceClient) Members(ctx context.Context, in *MembersRequest, opts ...grpc.CallOption) (*MemberList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MemberList)
+	err := c.cc.Invoke(ctx, MembershipService_Members_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *membershipServiceClient) WatchMembers(ctx context.Context, in *MembersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[MemberList], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MembershipService_ServiceDesc.Streams[0], MembershipService_WatchMembers_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[MembersRequest, MemberList]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MembershipService_WatchMembersClient = grpc.ServerStreamingClient[MemberList]
+
+// MembershipServiceServer is the server API for MembershipService service.
+// All implementations must embed UnimplementedMembershipServiceServer
+// for forward compatibility.
+//
+// MembershipService runs SWIM style gossip between key-value services: members probe each other, spread
+// membership changes piggybacked on the probes and publish the resulting member list to clients
+type MembershipServiceServer interface {
+	// Ping is a direct probe, answered with the membership changes the receiver is spreading
+	Ping(context.Context, *PingRequest) (*PingResponse, error)
+	// IndirectPing asks the receiver to probe a member the sender could not reach
+	IndirectPing(context.Context, *IndirectPingRequest) (*IndirectPingResponse, error)
+	// Join adds the sender to the group and returns every member the receiver knows
+	Join(context.Context, *JoinRequest) (*JoinResponse, error)
+	// Members returns the member list as the receiver sees it
+	Members(context.Context, *MembersRequest) (*MemberList, error)
+	// WatchMembers streams the member list, once immediately and again after every change
+	WatchMembers(*MembersRequest, grpc.ServerStreamingServer[MemberList]) error
+	mustEmbedUnimplementedMembershipServiceServer()
+}
+
+// UnimplementedMembershipServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMembershipServiceServer struct{}
+
+func (UnimplementedMembershipServiceServer) Ping(context.Context, *PingRequest) (*PingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
+}
+func (UnimplementedMembershipServiceServer) IndirectPing(context.Context, *IndirectPingRequest) (*IndirectPingResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IndirectPing not implemented")
+}
+func (UnimplementedMembershipServiceServer) Join(context.Context, *JoinRequest) (*JoinResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Join not implemented")
+}
+func (UnimplementedMembershipServiceServer) Members(context.Context, *MembersRequest) (*MemberList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Members not implemented")
+}
+func (UnimplementedMembershipServiceServer) WatchMembers(*MembersRequest, grpc.ServerStreamingServer[MemberList]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchMembers not implemented")
+}
+func (UnimplementedMembershipServiceServer) mustEmbedUnimplementedMembershipServiceServer() {}
+func (UnimplementedMembershipServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeMembershipServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MembershipServiceServer will
+// result in compilation errors.
+type UnsafeMembershipServiceServer interface {
+	mustEmbedUnimplementedMembershipServiceServer()
+}
+
+func RegisterMembershipServiceServer(s grpc.ServiceRegistrar, srv MembershipServiceServer) {
+	// If the following call pancis, it indicates UnimplementedMembershipServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&MembershipService_ServiceDesc, srv)
+}
+
+func _MembershipService_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MembershipServiceServer).Ping(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MembershipService_Ping_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MembershipServiceServer).Ping(ctx, req.(*PingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MembershipService_IndirectPing_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IndirectPingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MembershipServiceServer).IndirectPing(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MembershipService_IndirectPing_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MembershipServiceServer).IndirectPing(ctx, req.(*IndirectPingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MembershipService_Join_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JoinRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MembershipServiceServer).Join(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MembershipService_Join_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MembershipServiceServer).Join(ctx, req.(*JoinRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MembershipService_Members_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MembershipServiceServer).Members(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MembershipService_Members_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MembershipServiceServer).Members(ctx, req.(*MembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MembershipService_WatchMembers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(MembersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MembershipServiceServer).WatchMembers(m, &grpc.GenericServerStream[MembersRequest, MemberList]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MembershipService_WatchMembersServer = grpc.ServerStreamingServer[MemberList]
+
+// MembershipService_ServiceDesc is the grpc.ServiceDesc for MembershipService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MembershipService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "keyvalue.MembershipService",
+	HandlerType: (*MembershipServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Ping",
+			Handler:    _MembershipService_Ping_Handler,
+		},
+		{
+			MethodName: "IndirectPing",
+			Handler:    _MembershipService_IndirectPing_Handler,
+		},
+		{
+			MethodName: "Join",
+			Handler:    _MembershipService_Join_Handler,
+		},
+		{
+			MethodName: "Members",
+			Handler:    _MembershipService_Members_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchMembers",
+			Handler:       _MembershipService_WatchMembers_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/keyvalue.proto",
+}
+
+const (
+	ChangeService_Changes_FullMethodName      = "/keyvalue.ChangeService/Changes"
+	ChangeService_CommitOffset_FullMethodName = "/keyvalue.ChangeService/CommitOffset"
+	ChangeService_GetOffset_FullMethodName    = "/keyvalue.ChangeService/GetOffset"
+)
+
+// ChangeServiceClient is the client API for ChangeService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ChangeService streams every change made to the store in order, for downstream systems such as search indexes.
+// Consumer groups store their position in the service to resume where they left off.
+type ChangeServiceClient interface {
+	// Changes streams changes starting at from_seq. Without from_seq a group resumes after its committed offset,
+	// and anyone else starts at the oldest retained change. Fails with OutOfRange once from_seq is no longer retained.
+	Changes(ctx context.Context, in *ChangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Change], error)
+	// CommitOffset records that a group processed every change up to and including seq
+	CommitOffset(ctx context.Context, in *CommitOffsetRequest, opts ...grpc.CallOption) (*CommitOffsetResponse, error)
+	// GetOffset returns the committed offset of a group and the retained window
+	GetOffset(ctx context.Context, in *GetOffsetRequest, opts ...grpc.CallOption) (*GetOffsetResponse, error)
+}
+
+type changeServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewChangeServiceClient(cc grpc.ClientConnInterface) ChangeServiceClient {
+	return &changeServiceClient{cc}
+}
+
+func (c *changeServiceClient) Changes(ctx context.Context, in *ChangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Change], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ChangeService_ServiceDesc.Streams[0], ChangeService_Changes_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ChangesRequest, Change]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChangeService_ChangesClient = grpc.ServerStreamingClient[Change]
+
+func (c *changeServiceClient) CommitOffset(ctx context.Context, in *CommitOffsetRequest, opts ...grpc.CallOption) (*CommitOffsetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CommitOffsetResponse)
+	err := c.cc.Invoke(ctx, ChangeService_CommitOffset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *changeServiceClient) GetOffset(ctx context.Context, in *GetOffsetRequest, opts ...grpc.CallOption) (*GetOffsetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetOffsetResponse)
+	err := c.cc.Invoke(ctx, ChangeService_GetOffset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ChangeServiceServer is the server API for ChangeService service.
+// All implementations must embed UnimplementedChangeServiceServer
+// for forward compatibility.
+//
+// ChangeService streams every change made to the store in order, for downstream systems such as search indexes.
+// Consumer groups store their position in the service to resume where they left off.
+type ChangeServiceServer interface {
+	// Changes streams changes starting at from_seq. Without from_seq a group resumes after its committed offset,
+	// and anyone else starts at the oldest retained change. Fails with OutOfRange once from_seq is no longer retained.
+	Changes(*ChangesRequest, grpc.ServerStreamingServer[Change]) error
+	// CommitOffset records that a group processed every change up to and including seq
+	CommitOffset(context.Context, *CommitOffsetRequest) (*CommitOffsetResponse, error)
+	// GetOffset returns the committed offset of a group and the retained window
+	GetOffset(context.Context, *GetOffsetRequest) (*GetOffsetResponse, error)
+	mustEmbedUnimplementedChangeServiceServer()
+}
+
+// UnimplementedChangeServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedChangeServiceServer struct{}
+
+func (UnimplementedChangeServiceServer) Changes(*ChangesRequest, grpc.ServerStreamingServer[Change]) error {
+	return status.Errorf(codes.Unimplemented, "method Changes not implemented")
+}
+func (UnimplementedChangeServiceServer) CommitOffset(context.Context, *CommitOffsetRequest) (*CommitOffsetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CommitOffset not implemented")
+}
+func (UnimplementedChangeServiceServer) GetOffset(context.Context, *GetOffsetRequest) (*GetOffsetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOffset not implemented")
+}
+func (UnimplementedChangeServiceServer) mustEmbedUnimplementedChangeServiceServer() {}
+func (UnimplementedChangeServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeChangeServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ChangeServiceServer will
+// result in compilation errors.
+type UnsafeChangeServiceServer interface {
+	mustEmbedUnimplementedChangeServiceServer()
+}
+
+func RegisterChangeServiceServer(s grpc.ServiceRegistrar, srv ChangeServiceServer) {
+	// If the following call pancis, it indicates UnimplementedChangeServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ChangeService_ServiceDesc, srv)
+}
+
+func _ChangeService_Changes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ChangeServiceServer).Changes(m, &grpc.GenericServerStream[ChangesRequest, Change]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ChangeService_ChangesServer = grpc.ServerStreamingServer[Change]
+
+func _ChangeService_CommitOffset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommitOffsetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChangeServiceServer).CommitOffset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChangeService_CommitOffset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChangeServiceServer).CommitOffset(ctx, req.(*CommitOffsetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ChangeService_GetOffset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOffsetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ChangeServiceServer).GetOffset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ChangeService_GetOffset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ChangeServiceServer).GetOffset(ctx, req.(*GetOffsetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ChangeService_ServiceDesc is the grpc.ServiceDesc for ChangeService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ChangeService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "keyvalue.ChangeService",
+	HandlerType: (*ChangeServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CommitOffset",
+			Handler:    _ChangeService_CommitOffset_Handler,
+		},
+		{
+			MethodName: "GetOffset",
+			Handler:    _ChangeService_GetOffset_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Changes",
+			Handler:       _ChangeService_Changes_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/keyvalue.proto",
+}
+
+const (
+	HistoryService_GetAtRevision_FullMethodName = "/keyvalue.HistoryService/GetAtRevision"
+	HistoryService_GetAtTime_FullMethodName     = "/keyvalue.HistoryService/GetAtTime"
+	HistoryService_History_FullMethodName       = "/keyvalue.HistoryService/History"
+	HistoryService_Compact_FullMethodName       = "/keyvalue.HistoryService/Compact"
+)
+
+// HistoryServiceClient is the client API for HistoryService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// HistoryService reads earlier revisions of keys. Every change of the store gets the next revision number,
+// and each key keeps its recent revisions.
+type HistoryServiceClient interface {
+	// GetAtRevision returns the value a key held at a revision of the store. Fails with NotFound when the key did
+	// not exist then and OutOfRange when that revision was compacted or is in the future.
+	GetAtRevision(ctx context.Context, in *GetAtRevisionRequest, opts ...grpc.CallOption) (*KeyRevision, error)
+	// GetAtTime returns the value a key held at a point in time, failing like GetAtRevision
+	GetAtTime(ctx context.Context, in *GetAtTimeRequest, opts ...grpc.CallOption) (*KeyRevision, error)
+	// History lists the retained revisions of a key, newest first
+	History(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*HistoryResponse, error)
+	// Compact drops the revisions replaced before a revision of the store
+	Compact(ctx context.Context, in *CompactRequest, opts ...grpc.CallOption) (*CompactResponse, error)
+}
+
+type historyServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewHistoryServiceClient(cc grpc.ClientConnInterface) HistoryServiceClient {
+	return &historyServiceClient{cc}
+}
+
+func (c *historyServiceClient) GetAtRevision(ctx context.Context, in *GetAtRevisionRequest, opts ...grpc.CallOption) (*KeyRevision, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(KeyRevision)
+	err := c.cc.Invoke(ctx, HistoryService_GetAtRevision_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *historyServiceClient) GetAtTime(ctx context.Context, in *GetAtTimeRequest, opts ...grpc.CallOption) (*KeyRevision, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(KeyRevision)
+	err := c.cc.Invoke(ctx, HistoryService_GetAtTime_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *historyServiceClient) History(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*HistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HistoryResponse)
+	err := c.cc.Invoke(ctx, HistoryService_History_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *historyServiceClient) Compact(ctx context.Context, in *CompactRequest, opts ...grpc.CallOption) (*CompactResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CompactResponse)
+	err := c.cc.Invoke(ctx, HistoryService_Compact_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HistoryServiceServer is the server API for HistoryService service.
+// All implementations must embed UnimplementedHistoryServiceServer
+// for forward compatibility.
+//
+// HistoryService reads earlier revisions of keys. Every change of the store gets the next revision number,
+// and each key keeps its recent revisions.
+type HistoryServiceServer interface {
+	// GetAtRevision returns the value a key held at a revision of the store. Fails with NotFound when the key did
+	// not exist then and OutOfRange when that revision was compacted or is in the future.
+	GetAtRevision(context.Context, *GetAtRevisionRequest) (*KeyRevision, error)
+	// GetAtTime returns the value a key held at a point in time, failing like GetAtRevision
+	GetAtTime(context.Context, *GetAtTimeRequest) (*KeyRevision, error)
+	// History lists the retained revisions of a key, newest first
+	History(context.Context, *HistoryRequest) (*HistoryResponse, error)
+	// Compact drops the revisions replaced before a revision of the store
+	Compact(context.Context, *CompactRequest) (*CompactResponse, error)
+	mustEmbedUnimplementedHistoryServiceServer()
+}
+
+// UnimplementedHistoryServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedHistoryServiceServer struct{}
+
+func (UnimplementedHistoryServiceServer) GetAtRevision(context.Context, *GetAtRevisionRequest) (*KeyRevision, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAtRevision not implemented")
+}
+func (UnimplementedHistoryServiceServer) GetAtTime(context.Context, *GetAtTimeRequest) (*KeyRevision, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAtTime not implemented")
+}
+func (UnimplementedHistoryServiceServer) History(context.Context, *HistoryRequest) (*HistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method History not implemented")
+}
+func (UnimplementedHistoryServiceServer) Compact(context.Context, *CompactRequest) (*CompactResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Compact not implemented")
+}
+func (UnimplementedHistoryServiceServer) mustEmbedUnimplementedHistoryServiceServer() {}
+func (UnimplementedHistoryServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeHistoryServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to HistoryServiceServer will
+// result in compilation errors.
+type UnsafeHistoryServiceServer interface {
+	mustEmbedUnimplementedHistoryServiceServer()
+}
+
+func RegisterHistoryServiceServer(s grpc.ServiceRegistrar, srv HistoryServiceServer) {
+	// If the following call pancis, it indicates UnimplementedHistoryServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&HistoryService_ServiceDesc, srv)
+}
+
+func _HistoryService_GetAtRevision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAtRevisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HistoryServiceServer).GetAtRevision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HistoryService_GetAtRevision_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HistoryServiceServer).GetAtRevision(ctx, req.(*GetAtRevisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HistoryService_GetAtTime_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAtTimeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HistoryServiceServer).GetAtTime(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HistoryService_GetAtTime_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HistoryServiceServer).GetAtTime(ctx, req.(*GetAtTimeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HistoryService_History_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HistoryServiceServer).History(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HistoryService_History_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HistoryServiceServer).History(ctx, req.(*HistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HistoryService_Compact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HistoryServiceServer).Compact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HistoryService_Compact_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HistoryServiceServer).Compact(ctx, req.(*CompactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// HistoryService_ServiceDesc is the grpc.ServiceDesc for HistoryService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var HistoryService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "keyvalue.HistoryService",
+	HandlerType: (*HistoryServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetAtRevision",
+			Handler:    _HistoryService_GetAtRevision_Handler,
+		},
+		{
+			MethodName: "GetAtTime",
+			Handler:    _HistoryService_GetAtTime_Handler,
+		},
+		{
+			MethodName: "History",
+			Handler:    _HistoryService_History_Handler,
+		},
+		{
+			MethodName: "Compact",
+			Handler:    _HistoryService_Compact_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/keyvalue.proto",
+}
+
+const (
+	LeaseService_Grant_FullMethodName      = "/keyvalue.LeaseService/Grant"
+	LeaseService_KeepAlive_FullMethodName  = "/keyvalue.LeaseService/KeepAlive"
+	LeaseService_Revoke_FullMethodName     = "/keyvalue.LeaseService/Revoke"
+	LeaseService_TimeToLive_FullMethodName = "/keyvalue.LeaseService/TimeToLive"
+	LeaseService_Acquire_FullMethodName    = "/keyvalue.LeaseService/Acquire"
+)
+
+// LeaseServiceClient is the client API for LeaseService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// LeaseService grants leases that keys can be attached to. A lease that is not kept alive expires,
+// and the keys attached to it are deleted.
+type LeaseServiceClient interface {
+	// Grant creates a lease expiring after ttl_millis unless kept alive
+	Grant(ctx context.Context, in *LeaseGrantRequest, opts ...grpc.CallOption) (*LeaseResponse, error)
+	// KeepAlive restarts the time to live of a lease. Fails with NotFound once it expired.
+	KeepAlive(ctx context.Context, in *LeaseKeepAliveRequest, opts ...grpc.CallOption) (*LeaseResponse, error)
+	// Revoke ends a lease right away, deleting its keys
+	Revoke(ctx context.Context, in *LeaseRevokeRequest, opts ...grpc.CallOption) (*LeaseRevokeResponse, error)
+	// TimeToLive describes a lease without keeping it alive
+	TimeToLive(ctx context.Context, in *LeaseTimeToLiveRequest, opts ...grpc.CallOption) (*LeaseResponse, error)
+	// Acquire sets a key attached to a lease only if it does not exist, handing out a fencing token larger than
+	// every token handed out before. When the key exists it reports the value holding it.
+	Acquire(ctx context.Context, in *AcquireRequest, opts ...grpc.CallOption) (*AcquireResponse, error)
+}
+
+type leaseServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewLeaseServiceClient(cc grpc.ClientConnInterface) LeaseServiceClient {
+	return &leaseServiceClient{cc}
+}
+
+func (c *leaseServiceClient) Grant(ctx context.Context, in *LeaseGrantRequest, opts ...grpc.CallOption) (*LeaseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LeaseResponse)
+	err := c.cc.Invoke(ctx, LeaseService_Grant_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *leaseServiceClient) KeepAlive(ctx context.Context, in *LeaseKeepAliveRequest, opts ...grpc.CallOption) (*LeaseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LeaseResponse)
+	err := c.cc.Invoke(ctx, LeaseService_KeepAlive_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *leaseServiceClient) Revoke(ctx context.Context, in *LeaseRevokeRequest, opts ...grpc.CallOption) (*LeaseRevokeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LeaseRevokeResponse)
+	err := c.cc.Invoke(ctx, LeaseService_Revoke_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *leaseServiceClient) TimeToLive(ctx context.Context, in *LeaseTimeToLiveRequest, opts ...grpc.CallOption) (*LeaseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LeaseResponse)
+	err := c.cc.Invoke(ctx, LeaseService_TimeToLive_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *leaseServiceClient) Acquire(ctx context.Context, in *AcquireRequest, opts ...grpc.CallOption) (*AcquireResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AcquireResponse)
+	err := c.cc.Invoke(ctx, LeaseService_Acquire_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// LeaseServiceServer is the server API for LeaseService service.
+// All implementations must embed UnimplementedLeaseServiceServer
+// for forward compatibility.
+//
+// LeaseService grants leases that keys can be attached to. A lease that is not kept alive expires,
+// and the keys attached to it are deleted.
+type LeaseServiceServer interface {
+	// Grant creates a lease expiring after ttl_millis unless kept alive
+	Grant(context.Context, *LeaseGrantRequest) (*LeaseResponse, error)
+	// KeepAlive restarts the time to live of a lease. Fails with NotFound once it expired.
+	KeepAlive(context.Context, *LeaseKeepAliveRequest) (*LeaseResponse, error)
+	// Revoke ends a lease right away, deleting its keys
+	Revoke(context.Context, *LeaseRevokeRequest) (*LeaseRevokeResponse, error)
+	// TimeToLive describes a lease without keeping it alive
+	TimeToLive(context.Context, *LeaseTimeToLiveRequest) (*LeaseResponse, error)
+	// Acquire sets a key attached to a lease only if it does not exist, handing out a fencing token larger than
+	// every token handed out before. When the key exists it reports the value holding it.
+	Acquire(context.Context, *AcquireRequest) (*AcquireResponse, error)
+	mustEmbedUnimplementedLeaseServiceServer()
+}
+
+// UnimplementedLeaseServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedLeaseServiceServer struct{}
+
+func (UnimplementedLeaseServiceServer) Grant(context.Context, *LeaseGrantRequest) (*LeaseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Grant not implemented")
+}
+func (UnimplementedLeaseServiceServer) KeepAlive(context.Context, *LeaseKeepAliveRequest) (*LeaseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method KeepAlive not implemented")
+}
+func (UnimplementedLeaseServiceServer) Revoke(context.Context, *LeaseRevokeRequest) (*LeaseRevokeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Revoke not implemented")
+}
+func (UnimplementedLeaseServiceServer) TimeToLive(context.Context, *LeaseTimeToLiveRequest) (*LeaseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TimeToLive not implemented")
+}
+func (UnimplementedLeaseServiceServer) Acquire(context.Context, *AcquireRequest) (*AcquireResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Acquire not implemented")
+}
+func (UnimplementedLeaseServiceServer) mustEmbedUnimplementedLeaseServiceServer() {}
+func (UnimplementedLeaseServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeLeaseServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to LeaseServiceServer will
+// result in compilation errors.
+type UnsafeLeaseServiceServer interface {
+	mustEmbedUnimplementedLeaseServiceServer()
+}
+
+func RegisterLeaseServiceServer(s grpc.ServiceRegistrar, srv LeaseServiceServer) {
+	// If the following call pancis, it indicates UnimplementedLeaseServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&LeaseService_ServiceDesc, srv)
+}
+
+func _LeaseService_Grant_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaseGrantRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LeaseServiceServer).Grant(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LeaseService_Grant_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LeaseServiceServer).Grant(ctx, req.(*LeaseGrantRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LeaseService_KeepAlive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaseKeepAliveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LeaseServiceServer).KeepAlive(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LeaseService_KeepAlive_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LeaseServiceServer).KeepAlive(ctx, req.(*LeaseKeepAliveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LeaseService_Revoke_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaseRevokeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LeaseServiceServer).Revoke(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LeaseService_Revoke_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LeaseServiceServer).Revoke(ctx, req.(*LeaseRevokeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LeaseService_TimeToLive_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaseTimeToLiveRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LeaseServiceServer).TimeToLive(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LeaseService_TimeToLive_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LeaseServiceServer).TimeToLive(ctx, req.(*LeaseTimeToLiveRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _LeaseService_Acquire_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AcquireRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LeaseServiceServer).Acquire(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LeaseService_Acquire_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LeaseServiceServer).Acquire(ctx, req.(*AcquireRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// LeaseService_ServiceDesc is the grpc.ServiceDesc for LeaseService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var LeaseService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "keyvalue.LeaseService",
+	HandlerType: (*LeaseServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Grant",
+			Handler:    _LeaseService_Grant_Handler,
+		},
+		{
+			MethodName: "KeepAlive",
+			Handler:    _LeaseService_KeepAlive_Handler,
+		},
+		{
+			MethodName: "Revoke",
+			Handler:    _LeaseService_Revoke_Handler,
+		},
+		{
+			MethodName: "TimeToLive",
+			Handler:    _LeaseService_TimeToLive_Handler,
+		},
+		{
+			MethodName: "Acquire",
+			Handler:    _LeaseService_Acquire_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto/keyvalue.proto",
+}
+
+const (
+	WatchService_Watch_FullMethodName = "/keyvalue.WatchService/Watch"
+)
+
+// WatchServiceClient is the client API for WatchService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// WatchService streams the changes of single keys
+type WatchServiceClient interface {
+	// Watch sends the current value of a key, then every change of it until the client goes away. Fails with
+	// Aborted, reason WATCH_LOST, when the watcher fell behind; watch again to continue from the current value.
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEvent], error)
+}
+
+type watchServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWatchServiceClient(cc grpc.ClientConnInterface) WatchServiceClient {
+	return &watchServiceClient{cc}
+}
+
+func (c *watchServiceClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WatchService_ServiceDesc.Streams[0], WatchService_Watch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchRequest, WatchEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WatchService_WatchClient = grpc.ServerStreamingClient[WatchEvent]
+
+// WatchServiceServer is the server API for WatchService service.
+// All implementations must embed UnimplementedWatchServiceServer
+// for forward compatibility.
+//
+// WatchService streams the changes of single keys
+type WatchServiceServer interface {
+	// Watch sends the current value of a key, then every change of it until the client goes away. Fails with
+	// Aborted, reason WATCH_LOST, when the watcher fell behind; watch again to continue from the current value.
+	Watch(*WatchRequest, grpc.ServerStreamingServer[WatchEvent]) error
+	mustEmbedUnimplementedWatchServiceServer()
+}
+
+// UnimplementedWatchServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWatchServiceServer struct{}
+
+func (UnimplementedWatchServiceServer) Watch(*WatchRequest, grpc.ServerStreamingServer[WatchEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedWatchServiceServer) mustEmbedUnimplementedWatchServiceServer() {}
+func (UnimplementedWatchServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeWatchServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WatchServiceServer will
+// result in compilation errors.
+type UnsafeWatchServiceServer interface {
+	mustEmbedUnimplementedWatchServiceServer()
+}
+
+func RegisterWatchServiceServer(s grpc.ServiceRegistrar, srv WatchServiceServer) {
+	// If the following call pancis, it indicates UnimplementedWatchServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&WatchService_ServiceDesc, srv)
+}
+
+func _WatchService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WatchServiceServer).Watch(m, &grpc.GenericServerStream[WatchRequest, WatchEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WatchService_WatchServer = grpc.ServerStreamingServer[WatchEvent]
+
+// WatchService_ServiceDesc is the grpc.ServiceDesc for WatchService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var WatchService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "keyvalue.WatchService",
+	HandlerType: (*WatchServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _WatchService_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/keyvalue.proto",
+}
+
+const (
+	BulkService_Export_FullMethodName = "/keyvalue.BulkService/Export"
+	BulkService_Import_FullMethodName = "/keyvalue.BulkService/Import"
+)
+
+// BulkServiceClient is the client API for BulkService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BulkService moves many key-value pairs in and out of the store as streams, so neither side holds them all
+type BulkServiceClient interface {
+	// Export streams the pairs with a prefix in key order. Pairs are read a page at a time, so writes made during
+	// the export may or may not be included.
+	Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[KeyValuePair], error)
+	// Import stores the streamed pairs in order and reports how many were written and skipped. The mode of the
+	// first request applies to the whole stream. Writes are not atomic: on failure the pairs before the failing
+	// one stay written.
+	Import(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportRequest, ImportResponse], error)
+}
+
+type bulkServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBulkServiceClient(cc grpc.ClientConnInterface) BulkServiceClient {
+	return &bulkServiceClient{cc}
+}
+
+func (c *bulkServiceClient) Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[KeyValuePair], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BulkService_ServiceDesc.Streams[0], BulkService_Export_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportRequest, KeyValuePair]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BulkService_ExportClient = grpc.ServerStreamingClient[KeyValuePair]
+
+func (c *bulkServiceClient) Import(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportRequest, ImportResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BulkService_ServiceDesc.Streams[1], BulkService_Import_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportRequest, ImportResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BulkService_ImportClient = grpc.ClientStreamingClient[ImportRequest, ImportResponse]
+
+// BulkServiceServer is the server API for BulkService service.
+// All implementations must embed UnimplementedBulkServiceServer
+// for forward compatibility.
+//
+// BulkService moves many key-value pairs in and out of the store as streams, so neither side holds them all
+type BulkServiceServer interface {
+	// Export streams the pairs with a prefix in key order. Pairs are read a page at a time, so writes made during
+	// the export may or may not be included.
+	Export(*ExportRequest, grpc.ServerStreamingServer[KeyValuePair]) error
+	// Import stores the streamed pairs in order and reports how many were written and skipped. The mode of the
+	// first request applies to the whole stream. Writes are not atomic: on failure the pairs before the failing
+	// one stay written.
+	Import(grpc.ClientStreamingServer[ImportRequest, ImportResponse]) error
+	mustEmbedUnimplementedBulkServiceServer()
+}
+
+// UnimplementedBulkServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBulkServiceServer struct{}
+
+func (UnimplementedBulkServiceServer) Export(*ExportRequest, grpc.ServerStreamingServer[KeyValuePair]) error {
+	return status.Errorf(codes.Unimplemented, "method Export not implemented")
+}
+func (UnimplementedBulkServiceServer) Import(grpc.ClientStreamingServer[ImportRequest, ImportResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Import not implemented")
+}
+func (UnimplementedBulkServiceServer) mustEmbedUnimplementedBulkServiceServer() {}
+func (UnimplementedBulkServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeBulkServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BulkServiceServer will
+// result in compilation errors.
+type UnsafeBulkServiceServer interface {
+	mustEmbedUnimplementedBulkServiceServer()
+}
+
+func RegisterBulkServiceServer(s grpc.ServiceRegistrar, srv BulkServiceServer) {
+	// If the following call pancis, it indicates UnimplementedBulkServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BulkService_ServiceDesc, srv)
+}
+
+func _BulkService_Export_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BulkServiceServer).Export(m, &grpc.GenericServerStream[ExportRequest, KeyValuePair]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BulkService_ExportServer = grpc.ServerStreamingServer[KeyValuePair]
+
+func _BulkService_Import_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BulkServiceServer).Import(&grpc.GenericServerStream[ImportRequest, ImportResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BulkService_ImportServer = grpc.ClientStreamingServer[ImportRequest, ImportResponse]
+
+// BulkService_ServiceDesc is the grpc.ServiceDesc for BulkService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BulkService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "keyvalue.BulkService",
+	HandlerType: (*BulkServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Export",
+			Handler:       _BulkService_Export_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Import",
+			Handler:       _BulkService_Import_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "proto/keyvalue.proto",
+}
+
+const (
+	BackupService_Backup_FullMethodName  = "/keyvalue.BackupService/Backup"
+	BackupService_Restore_FullMethodName = "/keyvalue.BackupService/Restore"
+)
+
+// BackupServiceClient is the client API for BackupService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BackupService takes and loads backups of the whole store without stopping writes
+type BackupServiceClient interface {
+	// Backup streams a backup file of the store as of one point in time. The file carries its format version and a
+	// checksum, and is meant to be stored as is.
+	Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BackupChunk], error)
+	// Restore loads a streamed backup file. The file is checked in full before the store is changed. The mode of the
+	// first request applies to the whole stream.
+	Restore(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[RestoreRequest, RestoreResponse], error)
+}
+
+type backupServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBackupServiceClient(cc grpc.ClientConnInterface) BackupServiceClient {
+	return &backupServiceClient{cc}
+}
+
+func (c *backupServiceClient) Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BackupChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BackupService_ServiceDesc.Streams[0], BackupService_Backup_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BackupRequest, BackupChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BackupService_BackupClient = grpc.ServerStreamingClient[BackupChunk]
+
+func (c *backupServiceClient) Restore(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[RestoreRequest, RestoreResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &BackupService_ServiceDesc.Streams[1], BackupService_Restore_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RestoreRequest, RestoreResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BackupService_RestoreClient = grpc.ClientStreamingClient[RestoreRequest, RestoreResponse]
+
+// BackupServiceServer is the server API for BackupService service.
+// All implementations must embed UnimplementedBackupServiceServer
+// for forward compatibility.
+//
+// BackupService takes and loads backups of the whole store without stopping writes
+type BackupServiceServer interface {
+	// Backup streams a backup file of the store as of one point in time. The file carries its format version and a
+	// checksum, and is meant to be stored as is.
+	Backup(*BackupRequest, grpc.ServerStreamingServer[BackupChunk]) error
+	// Restore loads a streamed backup file. The file is checked in full before the store is changed. The mode of the
+	// first request applies to the whole stream.
+	Restore(grpc.ClientStreamingServer[RestoreRequest, RestoreResponse]) error
+	mustEmbedUnimplementedBackupServiceServer()
+}
+
+// UnimplementedBackupServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBackupServiceServer struct{}
+
+func (UnimplementedBackupServiceServer) Backup(*BackupRequest, grpc.ServerStreamingServer[BackupChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method Backup not implemented")
+}
+func (UnimplementedBackupServiceServer) Restore(grpc.ClientStreamingServer[RestoreRequest, RestoreResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Restore not implemented")
+}
+func (UnimplementedBackupServiceServer) mustEmbedUnimplementedBackupServiceServer() {}
+func (UnimplementedBackupServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeBackupServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BackupServiceServer will
+// result in compilation errors.
+type UnsafeBackupServiceServer interface {
+	mustEmbedUnimplementedBackupServiceServer()
+}
+
+func RegisterBackupServiceServer(s grpc.ServiceRegistrar, srv BackupServiceServer) {
+	// If the following call pancis, it indicates UnimplementedBackupServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BackupService_ServiceDesc, srv)
+}
+
+func _BackupService_Backup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BackupRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BackupServiceServer).Backup(m, &grpc.GenericServerStream[BackupRequest, BackupChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BackupService_BackupServer = grpc.ServerStreamingServer[BackupChunk]
+
+func _BackupService_Restore_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(BackupServiceServer).Restore(&grpc.GenericServerStream[RestoreRequest, RestoreResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type BackupService_RestoreServer = grpc.ClientStreamingServer[RestoreRequest, RestoreResponse]
+
+// BackupService_ServiceDesc is the grpc.ServiceDesc for BackupService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BackupService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "keyvalue.BackupService",
+	HandlerType: (*BackupServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Backup",
+			Handler:       _BackupService_Backup_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Restore",
+			Handler:       _BackupService_Restore_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "proto/keyvalue.proto",
+}
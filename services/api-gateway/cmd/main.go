@@ -4,7 +4,9 @@ import (
 	"context"
 	"key-value/client"
 	"key-value/services/api-gateway/internal/config"
+	"key-value/services/api-gateway/internal/handlers"
 	"key-value/services/api-gateway/internal/router"
+	"key-value/services/api-gateway/internal/webhooks"
 	"net/http"
 	"os"
 	"os/signal"
@@ -24,27 +26,59 @@ func main() {
 	e := echo.New()
 	e.Logger.SetLevel(log.INFO)
 
-	// Create a new KVStoreClient
-	kvstoreClient, err := client.NewKVStoreClient(config.KVServiceAddr)
+	// Create a new KVStoreClient, partitioning keys across shards when several are configured
+	clientOptions := []client.Option{client.WithLimits(config.Limits)}
+	if config.KVLBPolicy != "" {
+		clientOptions = append(clientOptions, client.WithLoadBalancingPolicy(config.KVLBPolicy))
+	}
+	var kvstoreClient handlers.KVStoreInterface
+	var err error
+	if len(config.KVShards) > 0 {
+		if config.KVVirtualNodes > 0 {
+			clientOptions = append(clientOptions, client.WithVirtualNodes(config.KVVirtualNodes))
+		}
+		kvstoreClient, err = client.NewShardedClient(config.KVShards, clientOptions...)
+		e.Logger.Infof("🧩 Sharding keys across %d key-value services", len(config.KVShards))
+	} else {
+		if config.KVDiscovery {
+			clientOptions = append(clientOptions, client.WithMemberDiscovery())
+			e.Logger.Infof("🛰️ Discovering key-value services through %s", config.KVServiceAddr)
+		}
+		kvstoreClient, err = client.NewKVStoreClient(config.KVServiceAddr, clientOptions...)
+	}
 	if err != nil {
 		e.Logger.Fatal("Failed to create KVStoreClient: %v", err)
 
 	}
 	defer kvstoreClient.Close()
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Deliver the changes of the store to the webhooks registered in it. Sharded stores have no single change
+	// stream, so their gateways serve no webhooks.
+	var dispatcher *webhooks.Dispatcher
+	if c, ok := kvstoreClient.(*client.KVStoreClient); ok {
+		dispatcher = webhooks.NewDispatcher(
+			webhooks.WithStore(c),
+			webhooks.WithMaxAttempts(config.Webhooks.MaxAttempts),
+			webhooks.WithBackoff(config.Webhooks.Backoff, 0),
+			webhooks.WithTimeout(config.Webhooks.Timeout),
+		)
+		defer dispatcher.Close()
+		go dispatcher.Run(ctx, c)
+	}
+
 	// Use middleware
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 
 	// Setup routes
-	err = router.SetupRoutes(e, config, kvstoreClient)
+	err = router.SetupRoutes(e, config, kvstoreClient, dispatcher)
 	if err != nil {
 		e.Logger.Fatal("Failed to setup routes: ", err)
 	}
 
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
-
 	// Start server with graceful shutdown
 	e.Logger.Info("🚀 Starting server on port " + config.Port)
 	go func(port string) {
@@ -1,17 +1,34 @@
 package config
 
 import (
+	"key-value/shared/limits"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/labstack/gommon/log"
 )
 
 type Config struct {
-	APIKey        string `env:"API_KEY"`
-	Port          string `env:"PORT"`
-	Environment   string `env:"ENVIRONMENT"`
-	KVServiceAddr string `env:"KV_SERVICE_ADDR"`
+	APIKey         string        `env:"API_KEY"`
+	Port           string        `env:"PORT"`
+	Environment    string        `env:"ENVIRONMENT"`
+	KVServiceAddr  string        `env:"KV_SERVICE_ADDR"`     // Single address, dns:/// name or comma separated list of replicas
+	KVLBPolicy     string        `env:"KV_LB_POLICY"`        // round_robin or pick_first, empty picks a default
+	KVShards       []string      `env:"KV_SHARDS"`           // Semicolon separated shard addresses, each in the KV_SERVICE_ADDR forms
+	KVVirtualNodes int           `env:"KV_VIRTUAL_NODES"`    // Points per shard on the hash ring, 0 uses the client default
+	KVDiscovery    bool          `env:"KV_MEMBER_DISCOVERY"` // Treat KV_SERVICE_ADDR as gossip seeds and route to the alive members
+	Limits         limits.Limits // MAX_KEY_LENGTH, MAX_VALUE_SIZE and KEY_PATTERN
+	Webhooks       WebhookConfig
+}
+
+// WebhookConfig tunes the delivery of webhook notifications, zero values use the defaults
+type WebhookConfig struct {
+	MaxAttempts int           `env:"WEBHOOK_MAX_ATTEMPTS"` // Tries per delivery before it fails
+	Backoff     time.Duration `env:"WEBHOOK_BACKOFF"`      // Wait after the first failed attempt, doubled after each further one
+	Timeout     time.Duration `env:"WEBHOOK_TIMEOUT"`      // Bound on each attempt
 }
 
 func Load() *Config {
@@ -26,10 +43,32 @@ func Load() *Config {
 		kvServiceAddr = "localhost:50051" // Default address
 	}
 
+	var kvShards []string
+	for _, shard := range strings.Split(os.Getenv("KV_SHARDS"), ";") {
+		if shard = strings.TrimSpace(shard); shard != "" {
+			kvShards = append(kvShards, shard)
+		}
+	}
+	kvVirtualNodes, _ := strconv.Atoi(os.Getenv("KV_VIRTUAL_NODES"))
+	kvDiscovery, _ := strconv.ParseBool(os.Getenv("KV_MEMBER_DISCOVERY"))
+	webhookMaxAttempts, _ := strconv.Atoi(os.Getenv("WEBHOOK_MAX_ATTEMPTS"))
+	webhookBackoff, _ := time.ParseDuration(os.Getenv("WEBHOOK_BACKOFF"))
+	webhookTimeout, _ := time.ParseDuration(os.Getenv("WEBHOOK_TIMEOUT"))
+
 	return &Config{
-		APIKey:        os.Getenv("API_KEY"),
-		Port:          os.Getenv("PORT"),
-		Environment:   os.Getenv("ENVIRONMENT"),
-		KVServiceAddr: kvServiceAddr,
+		APIKey:         os.Getenv("API_KEY"),
+		Port:           os.Getenv("PORT"),
+		Environment:    os.Getenv("ENVIRONMENT"),
+		KVServiceAddr:  kvServiceAddr,
+		KVLBPolicy:     os.Getenv("KV_LB_POLICY"),
+		KVShards:       kvShards,
+		KVVirtualNodes: kvVirtualNodes,
+		KVDiscovery:    kvDiscovery,
+		Limits:         limits.Load(),
+		Webhooks: WebhookConfig{
+			MaxAttempts: webhookMaxAttempts,
+			Backoff:     webhookBackoff,
+			Timeout:     webhookTimeout,
+		},
 	}
 }
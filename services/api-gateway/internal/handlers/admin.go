@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"key-value/client"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RebalanceRequest is the body starting a rebalance
+type RebalanceRequest struct {
+	Shards []string `json:"shards"`
+}
+
+// RebalanceResponse reports the progress of the running or last rebalance
+type RebalanceResponse struct {
+	State       string     `json:"state"`
+	From        []string   `json:"from,omitempty"`
+	To          []string   `json:"to,omitempty"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	KeysScanned int64      `json:"keys_scanned"`
+	KeysMoved   int64      `json:"keys_moved"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// StartRebalance moves the keys onto the shards in the body in the background.
+// Progress is reported by GetRebalanceStatus.
+func (h *Handler) StartRebalance(c echo.Context) error {
+	rebalancer, ok := h.kvstoreClient.(Rebalancer)
+	if !ok {
+		return c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "Rebalancing requires a sharded gateway"})
+	}
+
+	request := RebalanceRequest{}
+	if err := c.Bind(&request); err != nil {
+		log.Printf("Failed to bind request body: %v", err)
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request body"})
+	}
+	if len(request.Shards) == 0 {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "At least one shard is required"})
+	}
+
+	if err := rebalancer.StartRebalance(request.Shards); err != nil {
+		log.Printf("Failed to start rebalance: %v", err)
+		status := http.StatusBadRequest
+		if errors.Is(err, client.ErrRebalanceInProgress) || errors.Is(err, client.ErrRebalanceIncomplete) {
+			status = http.StatusConflict
+		}
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+	return c.JSON(http.StatusAccepted, toRebalanceResponse(rebalancer.RebalanceStatus()))
+}
+
+// GetRebalanceStatus reports the progress of the running or last rebalance
+func (h *Handler) GetRebalanceStatus(c echo.Context) error {
+	rebalancer, ok := h.kvstoreClient.(Rebalancer)
+	if !ok {
+		return c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "Rebalancing requires a sharded gateway"})
+	}
+	return c.JSON(http.StatusOK, toRebalanceResponse(rebalancer.RebalanceStatus()))
+}
+
+func toRebalanceResponse(status client.RebalanceStatus) RebalanceResponse {
+	response := RebalanceResponse{
+		State:       string(status.State),
+		From:        status.From,
+		To:          status.To,
+		KeysScanned: status.KeysScanned,
+		KeysMoved:   status.KeysMoved,
+		Error:       status.Error,
+	}
+	if !status.StartedAt.IsZero() {
+		response.StartedAt = &status.StartedAt
+	}
+	if !status.FinishedAt.IsZero() {
+		response.FinishedAt = &status.FinishedAt
+	}
+	return response
+}
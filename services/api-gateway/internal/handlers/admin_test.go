@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"key-value/client"
+	"key-value/shared/limits"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+// MockShardedClient is a MockKVStoreClient that can also rebalance
+type MockShardedClient struct {
+	MockKVStoreClient
+	StartRebalanceFunc func(addresses []string) error
+	Status             client.RebalanceStatus
+}
+
+func (m *MockShardedClient) StartRebalance(addresses []string) error {
+	if m.StartRebalanceFunc != nil {
+		return m.StartRebalanceFunc(addresses)
+	}
+	m.Status = client.RebalanceStatus{State: client.RebalanceRunning, To: addresses}
+	return nil
+}
+
+func (m *MockShardedClient) RebalanceStatus() client.RebalanceStatus {
+	return m.Status
+}
+
+func TestHandler_StartRebalance(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		setupMock      func(*MockShardedClient)
+		expectedStatus int
+		expectedState  string
+	}{
+		{
+			name:           "started",
+			requestBody:    `{"shards": ["kv-1:50051", "kv-2:50051"]}`,
+			setupMock:      func(m *MockShardedClient) {},
+			expectedStatus: http.StatusAccepted,
+			expectedState:  "running",
+		},
+		{
+			name:           "no shards",
+			requestBody:    `{"shards": []}`,
+			setupMock:      func(m *MockShardedClient) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid JSON",
+			requestBody:    `{"shards": "kv-1"}`,
+			setupMock:      func(m *MockShardedClient) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "already running",
+			requestBody: `{"shards": ["kv-1:50051"]}`,
+			setupMock: func(m *MockShardedClient) {
+				m.StartRebalanceFunc = func(addresses []string) error { return client.ErrRebalanceInProgress }
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:        "previous rebalance incomplete",
+			requestBody: `{"shards": ["kv-1:50051"]}`,
+			setupMock: func(m *MockShardedClient) {
+				m.StartRebalanceFunc = func(addresses []string) error {
+					return fmt.Errorf("%w, retry it", client.ErrRebalanceIncomplete)
+				}
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockShardedClient{}
+			tt.setupMock(mockClient)
+			handler := NewHandler(mockClient, limits.Default())
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/v1/admin/rebalance", strings.NewReader(tt.requestBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.StartRebalance(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			if tt.expectedState != "" {
+				var response RebalanceResponse
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedState, response.State)
+				assert.Equal(t, []string{"kv-1:50051", "kv-2:50051"}, response.To)
+			}
+		})
+	}
+}
+
+func TestHandler_GetRebalanceStatus(t *testing.T) {
+	mockClient := &MockShardedClient{Status: client.RebalanceStatus{
+		State:       client.RebalanceFailed,
+		From:        []string{"kv-1:50051"},
+		To:          []string{"kv-1:50051", "kv-2:50051"},
+		KeysScanned: 10,
+		KeysMoved:   4,
+		Error:       "shard kv-2:50051: unavailable",
+	}}
+	handler := NewHandler(mockClient, limits.Default())
+
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/v1/admin/rebalance", nil), rec)
+
+	assert.NoError(t, handler.GetRebalanceStatus(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response RebalanceResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, RebalanceResponse{
+		State:       "failed",
+		From:        []string{"kv-1:50051"},
+		To:          []string{"kv-1:50051", "kv-2:50051"},
+		KeysScanned: 10,
+		KeysMoved:   4,
+		Error:       "shard kv-2:50051: unavailable",
+	}, response)
+}
+
+func TestHandler_RebalanceRequiresShardedGateway(t *testing.T) {
+	handler := NewHandler(&MockKVStoreClient{}, limits.Default())
+
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/v1/admin/rebalance", nil), rec)
+
+	assert.NoError(t, handler.GetRebalanceStatus(c))
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+}
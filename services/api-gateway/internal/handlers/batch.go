@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"errors"
+	"key-value/shared/limits"
+	"key-value/shared/models"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ScanResponse is one page of key-value pairs in key order
+type ScanResponse struct {
+	Items []models.KeyValue `json:"items"`
+	More  bool              `json:"more"`
+	// NextStartAfter is passed as start_after to fetch the next page when More is set
+	NextStartAfter string `json:"next_start_after,omitempty"`
+}
+
+// BatchGetRequest is the body of a batch get
+type BatchGetRequest struct {
+	Keys []string `json:"keys"`
+}
+
+// BatchSetRequest is the body of a batch set
+type BatchSetRequest struct {
+	Items []models.KeyValue `json:"items"`
+}
+
+// BatchResponse lists the pairs read or written by a batch
+type BatchResponse struct {
+	Items []models.KeyValue `json:"items"`
+}
+
+// ScanValues lists key-value pairs in key order, filtered by the prefix query parameter and leaving reserved keys out.
+// Pages are resumed with start_after and sized with limit.
+func (h *Handler) ScanValues(c echo.Context) error {
+	prefix := c.QueryParam("prefix")
+	startAfter := c.QueryParam("start_after")
+
+	limit := 0
+	if raw := c.QueryParam("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 0 {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "limit must be a non negative integer"})
+		}
+		limit = parsed
+	}
+
+	items, more, err := h.kvstoreClient.Scan(c.Request().Context(), prefix, startAfter, limit)
+	if err != nil {
+		log.Printf("Failed to scan values: %v", err)
+		return c.JSON(httpStatus(err), ErrorResponse{Error: "Failed to scan values"})
+	}
+
+	// Reserved keys are left out, the next page still starts after the last key scanned
+	response := ScanResponse{More: more}
+	if more && len(items) > 0 {
+		response.NextStartAfter = items[len(items)-1].Key
+	}
+	response.Items = public(items)
+	if response.Items == nil {
+		response.Items = []models.KeyValue{}
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// BatchGetValues retrieves several keys at once, leaving missing keys out of the response
+func (h *Handler) BatchGetValues(c echo.Context) error {
+	request := BatchGetRequest{}
+	if err := c.Bind(&request); err != nil {
+		log.Printf("Failed to bind request body: %v", err)
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request body"})
+	}
+	for _, key := range request.Keys {
+		if err := h.validateKey(key); err != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		}
+	}
+
+	values, err := h.kvstoreClient.BatchGet(c.Request().Context(), request.Keys)
+	if err != nil {
+		log.Printf("Failed to get values: %v", err)
+		return c.JSON(httpStatus(err), ErrorResponse{Error: "Failed to get values"})
+	}
+
+	// Keep the order of the request
+	response := BatchResponse{Items: []models.KeyValue{}}
+	for _, key := range request.Keys {
+		if value, ok := values[key]; ok {
+			response.Items = append(response.Items, models.KeyValue{Key: key, Value: value})
+		}
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// BatchUpdateValues stores several key-value pairs, writing over existing values
+func (h *Handler) BatchUpdateValues(c echo.Context) error {
+	request := BatchSetRequest{}
+	if err := c.Bind(&request); err != nil {
+		log.Printf("Failed to bind request body: %v", err)
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request body"})
+	}
+	for _, item := range request.Items {
+		if err := h.validate(item.Key, item.Value); err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, limits.ErrValueTooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			return c.JSON(status, ErrorResponse{Error: err.Error()})
+		}
+	}
+
+	if err := h.kvstoreClient.BatchSet(c.Request().Context(), request.Items); err != nil {
+		log.Printf("Failed to update values: %v", err)
+		return c.JSON(httpStatus(err), ErrorResponse{Error: "Failed to update values " + err.Error()})
+	}
+
+	if request.Items == nil {
+		request.Items = []models.KeyValue{}
+	}
+	return c.JSON(http.StatusOK, BatchResponse{Items: request.Items})
+}
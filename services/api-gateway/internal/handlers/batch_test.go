@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"key-value/client"
+	"key-value/shared/limits"
+	"key-value/shared/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_ScanValues(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		setupMock      func(*MockKVStoreClient)
+		expectedStatus int
+		expectedBody   ScanResponse
+		expectedError  string
+	}{
+		{
+			name:  "page with more results",
+			query: "prefix=user:&start_after=user:1&limit=2",
+			setupMock: func(m *MockKVStoreClient) {
+				m.ScanFunc = func(ctx context.Context, prefix string, startAfter string, limit int) ([]models.KeyValue, bool, error) {
+					assert.Equal(t, "user:", prefix)
+					assert.Equal(t, "user:1", startAfter)
+					assert.Equal(t, 2, limit)
+					return []models.KeyValue{{Key: "user:2", Value: "b"}, {Key: "user:3", Value: "c"}}, true, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: ScanResponse{
+				Items:          []models.KeyValue{{Key: "user:2", Value: "b"}, {Key: "user:3", Value: "c"}},
+				More:           true,
+				NextStartAfter: "user:3",
+			},
+		},
+		{
+			name:  "reserved keys left out",
+			query: "limit=2",
+			setupMock: func(m *MockKVStoreClient) {
+				m.ScanFunc = func(ctx context.Context, prefix string, startAfter string, limit int) ([]models.KeyValue, bool, error) {
+					return []models.KeyValue{{Key: "Z", Value: "z"}, {Key: "_gateway/webhooks/1", Value: "{}"}}, true, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: ScanResponse{
+				Items:          []models.KeyValue{{Key: "Z", Value: "z"}},
+				More:           true,
+				NextStartAfter: "_gateway/webhooks/1",
+			},
+		},
+		{
+			name:           "empty result",
+			query:          "",
+			setupMock:      func(m *MockKVStoreClient) {},
+			expectedStatus: http.StatusOK,
+			expectedBody:   ScanResponse{Items: []models.KeyValue{}},
+		},
+		{
+			name:           "invalid limit",
+			query:          "limit=-1",
+			setupMock:      func(m *MockKVStoreClient) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "limit must be a non negative integer",
+		},
+		{
+			name:  "shard unavailable",
+			query: "prefix=a",
+			setupMock: func(m *MockKVStoreClient) {
+				m.ScanFunc = func(ctx context.Context, prefix string, startAfter string, limit int) ([]models.KeyValue, bool, error) {
+					return nil, false, fmt.Errorf("shard kv-2: %w", client.ErrUnavailable)
+				}
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedError:  "Failed to scan values",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockKVStoreClient{}
+			tt.setupMock(mockClient)
+			handler := NewHandler(mockClient, limits.Default())
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/v1/values?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.ScanValues(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			if tt.expectedError != "" {
+				var response ErrorResponse
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedError, response.Error)
+				return
+			}
+			var response ScanResponse
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedBody, response)
+		})
+	}
+}
+
+func TestHandler_BatchGetValues(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		setupMock      func(*MockKVStoreClient)
+		expectedStatus int
+		expectedItems  []models.KeyValue
+	}{
+		{
+			name:        "found keys in request order",
+			requestBody: `{"keys": ["c", "missing", "a"]}`,
+			setupMock: func(m *MockKVStoreClient) {
+				m.BatchGetFunc = func(ctx context.Context, keys []string) (map[string]string, error) {
+					return map[string]string{"a": "1", "c": "3"}, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedItems:  []models.KeyValue{{Key: "c", Value: "3"}, {Key: "a", Value: "1"}},
+		},
+		{
+			name:           "invalid key",
+			requestBody:    `{"keys": ["a", ""]}`,
+			setupMock:      func(m *MockKVStoreClient) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "invalid JSON",
+			requestBody:    `{"keys": "a"}`,
+			setupMock:      func(m *MockKVStoreClient) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockKVStoreClient{}
+			tt.setupMock(mockClient)
+			handler := NewHandler(mockClient, limits.Default())
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(tt.requestBody)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.BatchGetValues(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			if tt.expectedItems != nil {
+				var response BatchResponse
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedItems, response.Items)
+			}
+		})
+	}
+}
+
+func TestHandler_BatchUpdateValues(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		setupMock      func(*MockKVStoreClient)
+		expectedStatus int
+	}{
+		{
+			name:        "all pairs written",
+			requestBody: `{"items": [{"key": "a", "value": "1"}, {"key": "b", "value": "2"}]}`,
+			setupMock: func(m *MockKVStoreClient) {
+				m.BatchSetFunc = func(ctx context.Context, items []models.KeyValue) error {
+					assert.Len(t, items, 2)
+					return nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:        "value too large",
+			requestBody: `{"items": [{"key": "a", "value": "1"}, {"key": "b", "value": "` + strings.Repeat("v", 17) + `"}]}`,
+			setupMock: func(m *MockKVStoreClient) {
+				m.BatchSetFunc = func(ctx context.Context, items []models.KeyValue) error {
+					t.Error("BatchSet should not be called")
+					return nil
+				}
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:        "store error",
+			requestBody: `{"items": [{"key": "a", "value": "1"}]}`,
+			setupMock: func(m *MockKVStoreClient) {
+				m.BatchSetFunc = func(ctx context.Context, items []models.KeyValue) error {
+					return client.ErrUnavailable
+				}
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := &MockKVStoreClient{}
+			tt.setupMock(mockClient)
+			handler := NewHandler(mockClient, limits.Limits{MaxKeyLength: 8, MaxValueSize: 16})
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader([]byte(tt.requestBody)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.BatchUpdateValues(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"key-value/client"
+	"key-value/shared/limits"
+	"key-value/shared/models"
+	"key-value/shared/pairs"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ImportResponse counts the pairs an import wrote and the pairs it kept the existing values of
+type ImportResponse struct {
+	Written int `json:"written"`
+	Skipped int `json:"skipped"`
+}
+
+// importModes maps the mode query parameter of imports to the import modes
+var importModes = map[string]client.ImportMode{
+	"":                 client.ImportOverwrite,
+	"overwrite":        client.ImportOverwrite,
+	"skip-existing":    client.ImportSkipExisting,
+	"fail-on-conflict": client.ImportFailOnConflict,
+}
+
+// importError is a problem with the uploaded pairs, answered with its status
+type importError struct {
+	status int
+	err    error
+}
+
+func (e *importError) Error() string {
+	return e.err.Error()
+}
+
+// ExportValues streams the pairs with the prefix query parameter in key order, as NDJSON or, when format is csv,
+// as CSV, leaving reserved keys out. The pairs are relayed as they arrive rather than collected first.
+func (h *Handler) ExportValues(c echo.Context) error {
+	bulk, ok := h.kvstoreClient.(BulkTransferer)
+	if !ok {
+		return c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "Bulk import and export are not available through this gateway"})
+	}
+	format, err := pairs.ParseFormat(c.QueryParam("format"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	// The status is only sent with the first pair, so that a failure before it is still answered with an error
+	response := c.Response()
+	writer := pairs.NewWriter(response, format)
+	start := func() {
+		if !response.Committed {
+			response.Header().Set(echo.HeaderContentType, format.ContentType())
+			response.WriteHeader(http.StatusOK)
+		}
+	}
+	err = bulk.Export(c.Request().Context(), c.QueryParam("prefix"), func(kv models.KeyValue) error {
+		if isReserved(kv.Key) {
+			return nil
+		}
+		start()
+		return writer.Write(kv)
+	})
+	if err == nil {
+		start()
+		err = writer.Flush()
+	}
+	if err != nil {
+		log.Printf("Failed to export values: %v", err)
+		if !response.Committed {
+			return c.JSON(httpStatus(err), ErrorResponse{Error: "Failed to export values"})
+		}
+		// Cut the connection so the client cannot mistake a partial export for a complete one
+		panic(http.ErrAbortHandler)
+	}
+	return nil
+}
+
+// ImportValues stores the NDJSON or CSV pairs of the body as they are read, without holding the whole body in
+// memory. The format is taken from the format query parameter or else the content type, and mode decides what
+// happens to existing keys. Imports are not atomic.
+func (h *Handler) ImportValues(c echo.Context) error {
+	bulk, ok := h.kvstoreClient.(BulkTransferer)
+	if !ok {
+		return c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "Bulk import and export are not available through this gateway"})
+	}
+	formatName := c.QueryParam("format")
+	if formatName == "" && strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), pairs.CSV.ContentType()) {
+		formatName = string(pairs.CSV)
+	}
+	format, err := pairs.ParseFormat(formatName)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	mode, ok := importModes[c.QueryParam("mode")]
+	if !ok {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "mode must be overwrite, skip-existing or fail-on-conflict"})
+	}
+
+	reader := pairs.NewReader(c.Request().Body, format)
+	read := 0
+	next := func() (models.KeyValue, error) {
+		kv, err := reader.Read()
+		if errors.Is(err, pairs.ErrSyntax) {
+			return kv, &importError{status: http.StatusBadRequest, err: err}
+		}
+		if err != nil {
+			return kv, err
+		}
+		read++
+		if err := h.validate(kv.Key, kv.Value); err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, limits.ErrValueTooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			return kv, &importError{status: status, err: fmt.Errorf("pair %d: %w", read, err)}
+		}
+		return kv, nil
+	}
+
+	result, err := bulk.Import(c.Request().Context(), mode, next)
+	if err != nil {
+		log.Printf("Failed to import values: %v", err)
+		var inputErr *importError
+		if errors.As(err, &inputErr) {
+			return c.JSON(inputErr.status, ErrorResponse{Error: inputErr.Error()})
+		}
+		return c.JSON(httpStatus(err), ErrorResponse{Error: "Failed to import values " + err.Error()})
+	}
+	return c.JSON(http.StatusOK, ImportResponse{Written: result.Written, Skipped: result.Skipped})
+}
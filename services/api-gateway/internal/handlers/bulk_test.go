@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"key-value/client"
+	"key-value/shared/limits"
+	"key-value/shared/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockBulkClient is a MockKVStoreClient that exports and imports the pairs of a map
+type MockBulkClient struct {
+	MockKVStoreClient
+	Data map[string]string
+	// ExportErr fails exports after the pairs were handled
+	ExportErr error
+}
+
+func (m *MockBulkClient) Export(ctx context.Context, prefix string, handle func(models.KeyValue) error) error {
+	var keys []string
+	for key := range m.Data {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := handle(models.KeyValue{Key: key, Value: m.Data[key]}); err != nil {
+			return err
+		}
+	}
+	return m.ExportErr
+}
+
+func (m *MockBulkClient) Import(ctx context.Context, mode client.ImportMode, next func() (models.KeyValue, error)) (client.ImportResult, error) {
+	var result client.ImportResult
+	for {
+		kv, err := next()
+		if errors.Is(err, io.EOF) {
+			return result, nil
+		}
+		if err != nil {
+			return client.ImportResult{}, err
+		}
+		current, exists := m.Data[kv.Key]
+		switch {
+		case !exists || mode == client.ImportOverwrite:
+			m.Data[kv.Key] = kv.Value
+			result.Written++
+		case mode == client.ImportFailOnConflict && current != kv.Value:
+			return client.ImportResult{}, client.ErrConflict
+		default:
+			result.Skipped++
+		}
+	}
+}
+
+func newMockBulkClient() *MockBulkClient {
+	return &MockBulkClient{Data: map[string]string{"user:1": "alice", "user:2": "bob", "config": "x"}}
+}
+
+// bulkRequest calls handler with a request for target carrying body
+func bulkRequest(handler echo.HandlerFunc, method, target, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set(echo.HeaderContentType, contentType)
+	}
+	rec := httptest.NewRecorder()
+	handler(echo.New().NewContext(req, rec))
+	return rec
+}
+
+func TestHandler_ExportValues(t *testing.T) {
+	tests := []struct {
+		name                string
+		query               string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "ndjson",
+			query:               "prefix=user:",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "application/x-ndjson",
+			expectedBody:        "{\"key\":\"user:1\",\"value\":\"alice\"}\n{\"key\":\"user:2\",\"value\":\"bob\"}\n",
+		},
+		{
+			name:                "csv",
+			query:               "format=csv",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv",
+			expectedBody:        "key,value\nconfig,x\nuser:1,alice\nuser:2,bob\n",
+		},
+		{
+			name:                "empty csv",
+			query:               "prefix=none&format=csv",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: "text/csv",
+			expectedBody:        "key,value\n",
+		},
+		{name: "unknown format", query: "format=xml", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(newMockBulkClient(), limits.Default())
+
+			rec := bulkRequest(handler.ExportValues, http.MethodGet, "/v1/export?"+tt.query, "", "")
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedContentType, rec.Header().Get(echo.HeaderContentType))
+				assert.Equal(t, tt.expectedBody, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_ExportValuesReserved(t *testing.T) {
+	mockClient := newMockBulkClient()
+	mockClient.Data["_gateway/webhooks/1"] = `{"Secret":"s"}`
+	handler := NewHandler(mockClient, limits.Default())
+
+	rec := bulkRequest(handler.ExportValues, http.MethodGet, "/v1/export?format=csv", "", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "key,value\nconfig,x\nuser:1,alice\nuser:2,bob\n", rec.Body.String())
+}
+
+func TestHandler_ExportValuesFailure(t *testing.T) {
+	// Failures before the first pair are answered with an error
+	mockClient := &MockBulkClient{Data: map[string]string{}, ExportErr: client.ErrUnavailable}
+	handler := NewHandler(mockClient, limits.Default())
+	rec := bulkRequest(handler.ExportValues, http.MethodGet, "/v1/export", "", "")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	// Later failures cut the connection
+	mockClient.Data["a"] = "1"
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		bulkRequest(handler.ExportValues, http.MethodGet, "/v1/export", "", "")
+	})
+}
+
+func TestHandler_ImportValues(t *testing.T) {
+	tests := []struct {
+		name             string
+		query            string
+		contentType      string
+		body             string
+		expectedStatus   int
+		expectedResponse ImportResponse
+		expectedData     map[string]string
+	}{
+		{
+			name:             "ndjson overwrite",
+			body:             "{\"key\":\"user:1\",\"value\":\"new\"}\n{\"key\":\"user:3\",\"value\":\"carol\"}\n",
+			expectedStatus:   http.StatusOK,
+			expectedResponse: ImportResponse{Written: 2},
+			expectedData:     map[string]string{"user:1": "new", "user:2": "bob", "user:3": "carol", "config": "x"},
+		},
+		{
+			name:             "csv from the content type",
+			query:            "mode=skip-existing",
+			contentType:      "text/csv; charset=utf-8",
+			body:             "key,value\nuser:1,new\nuser:3,carol\n",
+			expectedStatus:   http.StatusOK,
+			expectedResponse: ImportResponse{Written: 1, Skipped: 1},
+			expectedData:     map[string]string{"user:1": "alice", "user:2": "bob", "user:3": "carol", "config": "x"},
+		},
+		{
+			name:           "conflict",
+			query:          "mode=fail-on-conflict&format=csv",
+			body:           "key,value\nuser:1,new\n",
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "malformed",
+			body:           "{\"key\":\"user:3\",\"value\":\"carol\"}\nnot json\n",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "value too large",
+			body:           `{"key":"big","value":"` + strings.Repeat("x", limits.Default().MaxValueSize+1) + `"}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "reserved key",
+			body:           `{"key":"_gateway/webhooks/1","value":"{}"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{name: "unknown mode", query: "mode=merge", expectedStatus: http.StatusBadRequest},
+		{name: "unknown format", query: "format=xml", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := newMockBulkClient()
+			handler := NewHandler(mockClient, limits.Default())
+
+			rec := bulkRequest(handler.ImportValues, http.MethodPost, "/v1/import?"+tt.query, tt.contentType, tt.body)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedStatus == http.StatusOK {
+				var response ImportResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedResponse, response)
+				assert.Equal(t, tt.expectedData, mockClient.Data)
+			}
+		})
+	}
+}
+
+func TestHandler_BulkRequiresSupport(t *testing.T) {
+	handler := NewHandler(&MockKVStoreClient{}, limits.Default())
+	assert.Equal(t, http.StatusNotImplemented, bulkRequest(handler.ExportValues, http.MethodGet, "/v1/export", "", "").Code)
+	assert.Equal(t, http.StatusNotImplemented, bulkRequest(handler.ImportValues, http.MethodPost, "/v1/import", "", "").Code)
+}
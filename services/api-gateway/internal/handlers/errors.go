@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"errors"
+	"key-value/client"
+	"net/http"
+)
+
+// errorStatuses maps the typed client errors to HTTP status codes
+var errorStatuses = []struct {
+	err    error
+	status int
+}{
+	{client.ErrNotFound, http.StatusNotFound},
+	{client.ErrConflict, http.StatusConflict},
+	{client.ErrTooLarge, http.StatusRequestEntityTooLarge},
+	{client.ErrInvalidKey, http.StatusBadRequest},
+	{client.ErrUnavailable, http.StatusServiceUnavailable},
+	{client.ErrNotNumeric, http.StatusUnprocessableEntity},
+	{client.ErrOverflow, http.StatusUnprocessableEntity},
+	{client.ErrCompacted, http.StatusGone},
+	{client.ErrFutureRevision, http.StatusBadRequest},
+}
+
+// httpStatus picks the HTTP status for an error returned by the key-value client, defaulting to 500
+func httpStatus(err error) int {
+	for _, m := range errorStatuses {
+		if errors.Is(err, m.err) {
+			return m.status
+		}
+	}
+	return http.StatusInternalServerError
+}
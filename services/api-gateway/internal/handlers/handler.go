@@ -2,24 +2,103 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"key-value/client"
+	"key-value/services/api-gateway/internal/webhooks"
+	"key-value/shared/limits"
 	"key-value/shared/models"
+	"slices"
+	"strings"
+	"time"
 )
 
+// ErrReservedKey is returned for keys starting with webhooks.StatePrefix, which hold the state of the gateways,
+// like the webhook secrets, and are neither readable nor writable through them
+var ErrReservedKey = errors.New("keys starting with " + webhooks.StatePrefix + " are reserved for the gateway")
+
 // KVStoreInterface defines the interface for key-value store operations
 type KVStoreInterface interface {
 	Get(ctx context.Context, key string) (string, bool, error)
 	Set(ctx context.Context, kv models.KeyValue) error
 	Delete(ctx context.Context, key string) error
+	Increment(ctx context.Context, key string, delta int64) (int64, error)
+	Scan(ctx context.Context, prefix string, startAfter string, limit int) ([]models.KeyValue, bool, error)
+	BatchGet(ctx context.Context, keys []string) (map[string]string, error)
+	BatchSet(ctx context.Context, items []models.KeyValue) error
 	Health(ctx context.Context) error
 	Close() error
 }
 
+// Rebalancer is implemented by sharded clients that can move keys onto a new set of shards
+type Rebalancer interface {
+	StartRebalance(addresses []string) error
+	RebalanceStatus() client.RebalanceStatus
+}
+
+// Historian is implemented by clients that can read the earlier revisions of keys
+type Historian interface {
+	GetAtRevision(ctx context.Context, key string, revision uint64) (client.Revision, error)
+	GetAtTime(ctx context.Context, key string, t time.Time) (client.Revision, error)
+	History(ctx context.Context, key string) (client.KeyHistory, error)
+	Compact(ctx context.Context, revision uint64) (int, error)
+}
+
+// BulkTransferer is implemented by clients that can stream every pair in and out of the store
+type BulkTransferer interface {
+	Export(ctx context.Context, prefix string, handle func(models.KeyValue) error) error
+	Import(ctx context.Context, mode client.ImportMode, next func() (models.KeyValue, error)) (client.ImportResult, error)
+}
+
 type Handler struct {
 	kvstoreClient KVStoreInterface
+	limits        limits.Limits
+	webhooks      *webhooks.Dispatcher
+}
+
+// Option configures a Handler
+type Option func(*Handler)
+
+// WithWebhooks serves the webhook endpoints over the dispatcher
+func WithWebhooks(dispatcher *webhooks.Dispatcher) Option {
+	return func(h *Handler) {
+		h.webhooks = dispatcher
+	}
 }
 
-func NewHandler(kvstoreClient KVStoreInterface) *Handler {
-	return &Handler{
+// NewHandler creates the HTTP handlers, rejecting keys and values that break the limits before calling the store
+func NewHandler(kvstoreClient KVStoreInterface, limits limits.Limits, opts ...Option) *Handler {
+	h := &Handler{
 		kvstoreClient: kvstoreClient,
+		limits:        limits,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// validateKey checks a key against the limits and rejects reserved keys
+func (h *Handler) validateKey(key string) error {
+	if isReserved(key) {
+		return ErrReservedKey
+	}
+	return h.limits.ValidateKey(key)
+}
+
+// validate checks a pair against the limits and rejects reserved keys
+func (h *Handler) validate(key string, value string) error {
+	if isReserved(key) {
+		return ErrReservedKey
+	}
+	return h.limits.Validate(key, value)
+}
+
+// isReserved reports whether a key holds the state of the gateways
+func isReserved(key string) bool {
+	return strings.HasPrefix(key, webhooks.StatePrefix)
+}
+
+// public leaves the reserved keys out of pairs
+func public(pairs []models.KeyValue) []models.KeyValue {
+	return slices.DeleteFunc(pairs, func(kv models.KeyValue) bool { return isReserved(kv.Key) })
 }
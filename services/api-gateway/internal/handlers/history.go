@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"key-value/client"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RevisionResponse is the value of a key as of a revision of the store
+type RevisionResponse struct {
+	Key      string    `json:"key"`
+	Value    string    `json:"value"`
+	Revision uint64    `json:"revision"`
+	Deleted  bool      `json:"deleted,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// HistoryResponse lists the retained revisions of a key, newest first
+type HistoryResponse struct {
+	Key               string             `json:"key"`
+	Revisions         []RevisionResponse `json:"revisions"`
+	CompactedRevision uint64             `json:"compacted_revision"`
+	CurrentRevision   uint64             `json:"current_revision"`
+}
+
+// CompactRequest is the body of a compaction
+type CompactRequest struct {
+	Revision uint64 `json:"revision"`
+}
+
+// CompactResponse reports how many revisions a compaction dropped
+type CompactResponse struct {
+	Removed int `json:"removed"`
+}
+
+// getRevision answers a get with the revision or at query parameter
+func (h *Handler) getRevision(c echo.Context, key string) error {
+	historian, ok := h.kvstoreClient.(Historian)
+	if !ok {
+		return c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "Key history is not available through this gateway"})
+	}
+
+	var revision client.Revision
+	var err error
+	if raw := c.QueryParam("revision"); raw != "" {
+		rev, parseErr := strconv.ParseUint(raw, 10, 64)
+		if parseErr != nil || rev == 0 {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "revision must be a positive integer"})
+		}
+		revision, err = historian.GetAtRevision(c.Request().Context(), key, rev)
+	} else {
+		at, parseErr := time.Parse(time.RFC3339Nano, c.QueryParam("at"))
+		if parseErr != nil {
+			return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "at must be an RFC 3339 time"})
+		}
+		revision, err = historian.GetAtTime(c.Request().Context(), key, at)
+	}
+	if err != nil {
+		log.Printf("Failed to get revision: %v", err)
+		status := httpStatus(err)
+		if status == http.StatusNotFound {
+			return c.JSON(status, ErrorResponse{Error: "Key not found"})
+		}
+		return c.JSON(status, ErrorResponse{Error: "Failed to get value " + err.Error()})
+	}
+	return c.JSON(http.StatusOK, toRevisionResponse(key, revision))
+}
+
+// GetValueHistory lists the retained revisions of a key, newest first and including deletions
+func (h *Handler) GetValueHistory(c echo.Context) error {
+	historian, ok := h.kvstoreClient.(Historian)
+	if !ok {
+		return c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "Key history is not available through this gateway"})
+	}
+	key := c.Param("key")
+	if err := h.validateKey(key); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	history, err := historian.History(c.Request().Context(), key)
+	if err != nil {
+		log.Printf("Failed to get history: %v", err)
+		status := httpStatus(err)
+		if status == http.StatusNotFound {
+			return c.JSON(status, ErrorResponse{Error: "Key has no history"})
+		}
+		return c.JSON(status, ErrorResponse{Error: "Failed to get history"})
+	}
+
+	response := HistoryResponse{
+		Key:               key,
+		Revisions:         make([]RevisionResponse, len(history.Revisions)),
+		CompactedRevision: history.Compacted,
+		CurrentRevision:   history.Current,
+	}
+	for i, revision := range history.Revisions {
+		response.Revisions[i] = toRevisionResponse(key, revision)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// Compact drops the revisions replaced before the revision in the body
+func (h *Handler) Compact(c echo.Context) error {
+	historian, ok := h.kvstoreClient.(Historian)
+	if !ok {
+		return c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "Key history is not available through this gateway"})
+	}
+
+	request := CompactRequest{}
+	if err := c.Bind(&request); err != nil {
+		log.Printf("Failed to bind request body: %v", err)
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request body"})
+	}
+	if request.Revision == 0 {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "revision must be a positive integer"})
+	}
+
+	removed, err := historian.Compact(c.Request().Context(), request.Revision)
+	if err != nil {
+		log.Printf("Failed to compact: %v", err)
+		return c.JSON(httpStatus(err), ErrorResponse{Error: "Failed to compact " + err.Error()})
+	}
+	return c.JSON(http.StatusOK, CompactResponse{Removed: removed})
+}
+
+func toRevisionResponse(key string, revision client.Revision) RevisionResponse {
+	return RevisionResponse{
+		Key:      key,
+		Value:    revision.Value,
+		Revision: revision.Revision,
+		Deleted:  revision.Deleted,
+		Time:     revision.Time,
+	}
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"key-value/client"
+	"key-value/shared/limits"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockHistoryClient is a MockKVStoreClient that keeps the revisions of every key, oldest first
+type MockHistoryClient struct {
+	MockKVStoreClient
+	Revisions map[string][]client.Revision
+	Compacted uint64
+}
+
+func (m *MockHistoryClient) GetAtRevision(ctx context.Context, key string, revision uint64) (client.Revision, error) {
+	if revision < m.Compacted {
+		return client.Revision{}, client.ErrCompacted
+	}
+	for i := len(m.Revisions[key]) - 1; i >= 0; i-- {
+		if r := m.Revisions[key][i]; r.Revision <= revision {
+			if r.Deleted {
+				break
+			}
+			return r, nil
+		}
+	}
+	return client.Revision{}, client.ErrNotFound
+}
+
+func (m *MockHistoryClient) GetAtTime(ctx context.Context, key string, t time.Time) (client.Revision, error) {
+	for i := len(m.Revisions[key]) - 1; i >= 0; i-- {
+		if r := m.Revisions[key][i]; !r.Time.After(t) {
+			return r, nil
+		}
+	}
+	return client.Revision{}, client.ErrNotFound
+}
+
+func (m *MockHistoryClient) History(ctx context.Context, key string) (client.KeyHistory, error) {
+	revisions := m.Revisions[key]
+	if len(revisions) == 0 {
+		return client.KeyHistory{}, client.ErrNotFound
+	}
+	history := client.KeyHistory{Compacted: m.Compacted, Current: 3}
+	for i := len(revisions) - 1; i >= 0; i-- {
+		history.Revisions = append(history.Revisions, revisions[i])
+	}
+	return history, nil
+}
+
+func (m *MockHistoryClient) Compact(ctx context.Context, revision uint64) (int, error) {
+	if revision > 3 {
+		return 0, fmt.Errorf("compact: %w", client.ErrFutureRevision)
+	}
+	m.Compacted = revision
+	return 1, nil
+}
+
+func newMockHistoryClient() *MockHistoryClient {
+	written := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &MockHistoryClient{Revisions: map[string][]client.Revision{
+		"config": {
+			{Revision: 1, Value: "v1", Time: written},
+			{Revision: 3, Value: "v2", Time: written.Add(time.Hour)},
+		},
+	}}
+}
+
+func TestHandler_GetValueAtRevision(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedValue  string
+	}{
+		{name: "revision", query: "revision=2", expectedStatus: http.StatusOK, expectedValue: "v1"},
+		{name: "latest revision", query: "revision=3", expectedStatus: http.StatusOK, expectedValue: "v2"},
+		{name: "time", query: "at=2026-01-01T00:30:00Z", expectedStatus: http.StatusOK, expectedValue: "v1"},
+		{name: "before the key existed", query: "at=2025-01-01T00:00:00Z", expectedStatus: http.StatusNotFound},
+		{name: "invalid revision", query: "revision=abc", expectedStatus: http.StatusBadRequest},
+		{name: "zero revision", query: "revision=0", expectedStatus: http.StatusBadRequest},
+		{name: "invalid time", query: "at=yesterday", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewHandler(newMockHistoryClient(), limits.Default())
+
+			e := echo.New()
+			rec := httptest.NewRecorder()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/v1/values/config?"+tt.query, nil), rec)
+			c.SetParamNames("key")
+			c.SetParamValues("config")
+
+			assert.NoError(t, handler.GetValueByKey(c))
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedValue != "" {
+				var response RevisionResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedValue, response.Value)
+				assert.Equal(t, "config", response.Key)
+			}
+		})
+	}
+}
+
+func TestHandler_GetValueHistory(t *testing.T) {
+	handler := NewHandler(newMockHistoryClient(), limits.Default())
+
+	rec := call(handler.GetValueHistory, http.MethodGet, "", "key", "config")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var response HistoryResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Len(t, response.Revisions, 2)
+	assert.Equal(t, uint64(3), response.Revisions[0].Revision)
+	assert.Equal(t, "v1", response.Revisions[1].Value)
+	assert.Equal(t, uint64(3), response.CurrentRevision)
+
+	assert.Equal(t, http.StatusNotFound, call(handler.GetValueHistory, http.MethodGet, "", "key", "missing").Code)
+}
+
+func TestHandler_Compact(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		expectedStatus int
+	}{
+		{name: "compacted", requestBody: `{"revision": 2}`, expectedStatus: http.StatusOK},
+		{name: "future revision", requestBody: `{"revision": 9}`, expectedStatus: http.StatusBadRequest},
+		{name: "missing revision", requestBody: `{}`, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := newMockHistoryClient()
+			handler := NewHandler(mockClient, limits.Default())
+
+			rec := call(handler.Compact, http.MethodPost, tt.requestBody)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+
+	// Reads before the compaction are gone
+	mockClient := newMockHistoryClient()
+	handler := NewHandler(mockClient, limits.Default())
+	require.Equal(t, http.StatusOK, call(handler.Compact, http.MethodPost, `{"revision": 3}`).Code)
+
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/v1/values/config?revision=1", strings.NewReader("")), rec)
+	c.SetParamNames("key")
+	c.SetParamValues("config")
+	assert.NoError(t, handler.GetValueByKey(c))
+	assert.Equal(t, http.StatusGone, rec.Code)
+}
+
+func TestHandler_HistoryRequiresSupport(t *testing.T) {
+	handler := NewHandler(&MockKVStoreClient{}, limits.Default())
+	assert.Equal(t, http.StatusNotImplemented, call(handler.GetValueHistory, http.MethodGet, "", "key", "config").Code)
+}
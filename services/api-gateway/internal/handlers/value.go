@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
+	"key-value/shared/limits"
 	"key-value/shared/models"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
 )
@@ -12,13 +15,26 @@ type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
-// GetValueByKey retrieves a KeyValue by key
+// IncrementRequest is the optional body of an increment call, delta defaults to 1
+type IncrementRequest struct {
+	Delta *int64 `json:"delta"`
+}
+
+// GetValueByKey retrieves a KeyValue by key. The revision or at query parameters read the value the key held
+// at a revision of the store or at an RFC 3339 time instead.
 func (h *Handler) GetValueByKey(c echo.Context) error {
 	key := c.Param("key")
+	if err := h.validateKey(key); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+	if c.QueryParam("revision") != "" || c.QueryParam("at") != "" {
+		return h.getRevision(c, key)
+	}
+
 	value, found, err := h.kvstoreClient.Get(c.Request().Context(), key)
 	if err != nil {
 		log.Printf("Failed to get value: %v", err)
-		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to get value"})
+		return c.JSON(httpStatus(err), ErrorResponse{Error: "Failed to get value"})
 	}
 	if !found {
 		log.Printf("Key not found: %s", key)
@@ -44,11 +60,20 @@ func (h *Handler) UpdateValue(c echo.Context) error {
 		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Key is required"})
 	}
 
+	// Validate the key and value against the configured limits
+	if err := h.validate(keyValue.Key, keyValue.Value); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, limits.ErrValueTooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		return c.JSON(status, ErrorResponse{Error: err.Error()})
+	}
+
 	// Update the value
 	err := h.kvstoreClient.Set(c.Request().Context(), keyValue)
 	if err != nil {
 		log.Printf("Failed to update value: %v", err)
-		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to update value " + err.Error()})
+		return c.JSON(httpStatus(err), ErrorResponse{Error: "Failed to update value " + err.Error()})
 	}
 
 	return c.JSON(http.StatusOK, models.KeyValue{
@@ -64,13 +89,45 @@ func (h *Handler) DeleteValue(c echo.Context) error {
 		log.Printf("Key is required")
 		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Key is required"})
 	}
+	if err := h.validateKey(key); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
 
 	// Delete the value
 	err := h.kvstoreClient.Delete(c.Request().Context(), key)
 	if err != nil {
 		log.Printf("Failed to delete value: %v", err)
-		return c.JSON(http.StatusInternalServerError, ErrorResponse{Error: "Failed to delete value"})
+		return c.JSON(httpStatus(err), ErrorResponse{Error: "Failed to delete value"})
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+// IncrementValue atomically adds delta to an integer value, creating the key at 0 if missing
+func (h *Handler) IncrementValue(c echo.Context) error {
+	key := c.Param("key")
+	if err := h.validateKey(key); err != nil {
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+
+	request := IncrementRequest{}
+	if err := c.Bind(&request); err != nil {
+		log.Printf("Failed to bind request body: %v", err)
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request body"})
+	}
+	delta := int64(1)
+	if request.Delta != nil {
+		delta = *request.Delta
+	}
+
+	value, err := h.kvstoreClient.Increment(c.Request().Context(), key, delta)
+	if err != nil {
+		log.Printf("Failed to increment value: %v", err)
+		return c.JSON(httpStatus(err), ErrorResponse{Error: "Failed to increment value " + err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, models.KeyValue{
+		Key:   key,
+		Value: strconv.FormatInt(value, 10),
+	})
+}
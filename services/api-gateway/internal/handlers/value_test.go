@@ -5,10 +5,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
 	"testing"
 
+	"key-value/client"
+	"key-value/shared/limits"
 	"key-value/shared/models"
 
 	"github.com/labstack/echo/v4"
@@ -17,11 +21,15 @@ import (
 
 // MockKVStoreClient implements a mock for testing
 type MockKVStoreClient struct {
-	GetFunc    func(ctx context.Context, key string) (string, bool, error)
-	SetFunc    func(ctx context.Context, kv models.KeyValue) error
-	DeleteFunc func(ctx context.Context, key string) error
-	HealthFunc func(ctx context.Context) error
-	CloseFunc  func() error
+	GetFunc       func(ctx context.Context, key string) (string, bool, error)
+	SetFunc       func(ctx context.Context, kv models.KeyValue) error
+	DeleteFunc    func(ctx context.Context, key string) error
+	IncrementFunc func(ctx context.Context, key string, delta int64) (int64, error)
+	ScanFunc      func(ctx context.Context, prefix string, startAfter string, limit int) ([]models.KeyValue, bool, error)
+	BatchGetFunc  func(ctx context.Context, keys []string) (map[string]string, error)
+	BatchSetFunc  func(ctx context.Context, items []models.KeyValue) error
+	HealthFunc    func(ctx context.Context) error
+	CloseFunc     func() error
 }
 
 func (m *MockKVStoreClient) Get(ctx context.Context, key string) (string, bool, error) {
@@ -45,6 +53,34 @@ func (m *MockKVStoreClient) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+func (m *MockKVStoreClient) Increment(ctx context.Context, key string, delta int64) (int64, error) {
+	if m.IncrementFunc != nil {
+		return m.IncrementFunc(ctx, key, delta)
+	}
+	return delta, nil
+}
+
+func (m *MockKVStoreClient) Scan(ctx context.Context, prefix string, startAfter string, limit int) ([]models.KeyValue, bool, error) {
+	if m.ScanFunc != nil {
+		return m.ScanFunc(ctx, prefix, startAfter, limit)
+	}
+	return nil, false, nil
+}
+
+func (m *MockKVStoreClient) BatchGet(ctx context.Context, keys []string) (map[string]string, error) {
+	if m.BatchGetFunc != nil {
+		return m.BatchGetFunc(ctx, keys)
+	}
+	return map[string]string{}, nil
+}
+
+func (m *MockKVStoreClient) BatchSet(ctx context.Context, items []models.KeyValue) error {
+	if m.BatchSetFunc != nil {
+		return m.BatchSetFunc(ctx, items)
+	}
+	return nil
+}
+
 func (m *MockKVStoreClient) Health(ctx context.Context) error {
 	if m.HealthFunc != nil {
 		return m.HealthFunc(ctx)
@@ -103,6 +139,17 @@ func TestHandler_GetValueByKey(t *testing.T) {
 			expectedStatus: http.StatusInternalServerError,
 			expectError:    true,
 		},
+		{
+			name: "service unavailable",
+			key:  "error-key",
+			setupMock: func(m *MockKVStoreClient) {
+				m.GetFunc = func(ctx context.Context, key string) (string, bool, error) {
+					return "", false, fmt.Errorf("failed to get key %s: %w", key, client.ErrUnavailable)
+				}
+			},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectError:    true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -112,7 +159,7 @@ func TestHandler_GetValueByKey(t *testing.T) {
 			tt.setupMock(mockClient)
 
 			// Create handler with mock client
-			handler := NewHandler(mockClient)
+			handler := NewHandler(mockClient, limits.Default())
 
 			// Set up Echo context
 			e := echo.New()
@@ -217,6 +264,20 @@ func TestHandler_UpdateValue(t *testing.T) {
 			expectedStatus: http.StatusInternalServerError,
 			expectedError:  "Failed to update value connection failed",
 		},
+		{
+			name: "value too large",
+			requestBody: map[string]string{
+				"key":   "test-key",
+				"value": "test-value",
+			},
+			setupMock: func(m *MockKVStoreClient) {
+				m.SetFunc = func(ctx context.Context, kv models.KeyValue) error {
+					return fmt.Errorf("failed to set key %s: %w", kv.Key, client.ErrTooLarge)
+				}
+			},
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedError:  "Failed to update value failed to set key test-key: too large",
+		},
 	}
 
 	for _, tt := range tests {
@@ -226,7 +287,7 @@ func TestHandler_UpdateValue(t *testing.T) {
 			tt.setupMock(mockClient)
 
 			// Create handler with mock client
-			handler := NewHandler(mockClient)
+			handler := NewHandler(mockClient, limits.Default())
 
 			// Set up request
 			e := echo.New()
@@ -302,6 +363,17 @@ func TestHandler_DeleteValue(t *testing.T) {
 			expectedStatus: http.StatusInternalServerError,
 			expectedError:  "Failed to delete value",
 		},
+		{
+			name: "conflict",
+			key:  "locked-key",
+			setupMock: func(m *MockKVStoreClient) {
+				m.DeleteFunc = func(ctx context.Context, key string) error {
+					return client.ErrConflict
+				}
+			},
+			expectedStatus: http.StatusConflict,
+			expectedError:  "Failed to delete value",
+		},
 	}
 
 	for _, tt := range tests {
@@ -311,7 +383,7 @@ func TestHandler_DeleteValue(t *testing.T) {
 			tt.setupMock(mockClient)
 
 			// Create handler with mock client
-			handler := NewHandler(mockClient)
+			handler := NewHandler(mockClient, limits.Default())
 
 			// Set up Echo context
 			e := echo.New()
@@ -336,3 +408,214 @@ func TestHandler_DeleteValue(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_IncrementValue(t *testing.T) {
+	tests := []struct {
+		name           string
+		key            string
+		requestBody    string
+		setupMock      func(*MockKVStoreClient)
+		expectedStatus int
+		expectedValue  string
+		expectedError  string
+	}{
+		{
+			name:        "increment with delta",
+			key:         "counter",
+			requestBody: `{"delta": 5}`,
+			setupMock: func(m *MockKVStoreClient) {
+				m.IncrementFunc = func(ctx context.Context, key string, delta int64) (int64, error) {
+					return 10 + delta, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedValue:  "15",
+		},
+		{
+			name:        "empty body defaults to one",
+			key:         "counter",
+			requestBody: "",
+			setupMock: func(m *MockKVStoreClient) {
+				m.IncrementFunc = func(ctx context.Context, key string, delta int64) (int64, error) {
+					return 10 + delta, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedValue:  "11",
+		},
+		{
+			name:        "negative delta",
+			key:         "counter",
+			requestBody: `{"delta": -3}`,
+			setupMock: func(m *MockKVStoreClient) {
+				m.IncrementFunc = func(ctx context.Context, key string, delta int64) (int64, error) {
+					return 10 + delta, nil
+				}
+			},
+			expectedStatus: http.StatusOK,
+			expectedValue:  "7",
+		},
+		{
+			name:           "invalid JSON",
+			key:            "counter",
+			requestBody:    `{"delta": "lots"}`,
+			setupMock:      func(m *MockKVStoreClient) {},
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "Invalid request body",
+		},
+		{
+			name:        "non numeric value",
+			key:         "text",
+			requestBody: `{"delta": 1}`,
+			setupMock: func(m *MockKVStoreClient) {
+				m.IncrementFunc = func(ctx context.Context, key string, delta int64) (int64, error) {
+					return 0, fmt.Errorf("failed to increment key %s: %w", key, client.ErrNotNumeric)
+				}
+			},
+			expectedStatus: http.StatusUnprocessableEntity,
+			expectedError:  "Failed to increment value failed to increment key text: value is not an integer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create mock client
+			mockClient := &MockKVStoreClient{}
+			tt.setupMock(mockClient)
+
+			// Create handler with mock client
+			handler := NewHandler(mockClient, limits.Default())
+
+			// Set up Echo context
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(tt.requestBody)))
+			if tt.requestBody != "" {
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("key")
+			c.SetParamValues(tt.key)
+
+			// Call handler
+			err := handler.IncrementValue(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			var response map[string]string
+			err = json.Unmarshal(rec.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, response["error"])
+			} else {
+				assert.Equal(t, tt.key, response["key"])
+				assert.Equal(t, tt.expectedValue, response["value"])
+			}
+		})
+	}
+}
+
+func TestHandler_Limits(t *testing.T) {
+	handler := NewHandler(&MockKVStoreClient{}, limits.Limits{
+		MaxKeyLength: 8,
+		MaxValueSize: 4,
+		KeyPattern:   regexp.MustCompile(`^[a-z]+$`),
+	})
+	e := echo.New()
+
+	tests := []struct {
+		name            string
+		method          string
+		key             string
+		body            string
+		expectedStatus  int
+		expectedMessage string
+	}{
+		{"put within limits", http.MethodPut, "", `{"key":"abc","value":"1234"}`, http.StatusOK, ""},
+		{"put value too large", http.MethodPut, "", `{"key":"abc","value":"12345"}`, http.StatusRequestEntityTooLarge, "maximum value size is 4 bytes"},
+		{"put key too long", http.MethodPut, "", `{"key":"abcdefghi","value":"1"}`, http.StatusBadRequest, "maximum key length is 8 bytes"},
+		{"put key characters", http.MethodPut, "", `{"key":"ABC","value":"1"}`, http.StatusBadRequest, "key must match"},
+		{"get key too long", http.MethodGet, "abcdefghi", "", http.StatusBadRequest, "maximum key length is 8 bytes"},
+		{"delete key characters", http.MethodDelete, "a-b", "", http.StatusBadRequest, "key must match"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("key")
+			c.SetParamValues(tt.key)
+
+			var err error
+			switch tt.method {
+			case http.MethodPut:
+				err = handler.UpdateValue(c)
+			case http.MethodGet:
+				err = handler.GetValueByKey(c)
+			case http.MethodDelete:
+				err = handler.DeleteValue(c)
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			if tt.expectedMessage != "" {
+				var response map[string]string
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				assert.Contains(t, response["error"], tt.expectedMessage)
+			}
+		})
+	}
+}
+
+func TestHandler_ReservedKeys(t *testing.T) {
+	mockClient := &MockKVStoreClient{
+		GetFunc: func(ctx context.Context, key string) (string, bool, error) {
+			t.Errorf("Get(%q) reached the store", key)
+			return "", false, nil
+		},
+		SetFunc: func(ctx context.Context, kv models.KeyValue) error {
+			t.Errorf("Set(%q) reached the store", kv.Key)
+			return nil
+		},
+		BatchSetFunc: func(ctx context.Context, items []models.KeyValue) error {
+			t.Errorf("BatchSet() reached the store")
+			return nil
+		},
+	}
+	handler := NewHandler(mockClient, limits.Default())
+	e := echo.New()
+
+	tests := []struct {
+		name    string
+		method  string
+		key     string
+		body    string
+		handler func(echo.Context) error
+	}{
+		{name: "get", method: http.MethodGet, key: "_gateway/webhooks/1", handler: handler.GetValueByKey},
+		{name: "put", method: http.MethodPut, body: `{"key":"_gateway/webhooks/1","value":"{}"}`, handler: handler.UpdateValue},
+		{name: "delete", method: http.MethodDelete, key: "_gateway/locks/webhooks", handler: handler.DeleteValue},
+		{name: "increment", method: http.MethodPost, key: "_gateway/locks/webhooks", handler: handler.IncrementValue},
+		{name: "batch get", method: http.MethodPost, body: `{"keys":["a","_gateway/webhooks/1"]}`, handler: handler.BatchGetValues},
+		{name: "batch set", method: http.MethodPost, body: `{"items":[{"key":"_gateway/webhooks/1","value":"{}"}]}`, handler: handler.BatchUpdateValues},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("key")
+			c.SetParamValues(tt.key)
+
+			assert.NoError(t, tt.handler(c))
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			var response ErrorResponse
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.Equal(t, ErrReservedKey.Error(), response.Error)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"errors"
+	"key-value/services/api-gateway/internal/webhooks"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// WebhookRequest is the body registering a webhook. Events defaults to every event type and the secret is
+// generated when empty.
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Prefix string   `json:"prefix"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+}
+
+// WebhookResponse describes a webhook, the secret is only returned when it is registered
+type WebhookResponse struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Prefix    string    `json:"prefix"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhooksResponse lists the registered webhooks
+type WebhooksResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+// AttemptResponse is one try at delivering an event
+type AttemptResponse struct {
+	Time       time.Time `json:"time"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+// DeliveryResponse is an event sent to a webhook with its attempts
+type DeliveryResponse struct {
+	ID       string            `json:"id"`
+	Event    string            `json:"event"`
+	Key      string            `json:"key"`
+	Time     time.Time         `json:"time"`
+	State    string            `json:"state"`
+	Attempts []AttemptResponse `json:"attempts"`
+}
+
+// DeliveriesResponse lists the most recent deliveries of a webhook, newest first
+type DeliveriesResponse struct {
+	Deliveries []DeliveryResponse `json:"deliveries"`
+}
+
+// CreateWebhook registers a webhook notified about the changes of keys matching its prefix
+func (h *Handler) CreateWebhook(c echo.Context) error {
+	if h.webhooks == nil {
+		return c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "Webhooks are not enabled"})
+	}
+
+	request := WebhookRequest{}
+	if err := c.Bind(&request); err != nil {
+		log.Printf("Failed to bind request body: %v", err)
+		return c.JSON(http.StatusBadRequest, ErrorResponse{Error: "Invalid request body"})
+	}
+
+	events := make([]webhooks.EventType, len(request.Events))
+	for i, event := range request.Events {
+		events[i] = webhooks.EventType(event)
+	}
+	webhook, err := h.webhooks.Register(c.Request().Context(), webhooks.Webhook{
+		URL:    request.URL,
+		Prefix: request.Prefix,
+		Events: events,
+		Secret: request.Secret,
+	})
+	if err != nil {
+		return c.JSON(webhookStatus(err), ErrorResponse{Error: err.Error()})
+	}
+
+	response := toWebhookResponse(webhook)
+	response.Secret = webhook.Secret
+	return c.JSON(http.StatusCreated, response)
+}
+
+// ListWebhooks lists the registered webhooks
+func (h *Handler) ListWebhooks(c echo.Context) error {
+	if h.webhooks == nil {
+		return c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "Webhooks are not enabled"})
+	}
+
+	registered, err := h.webhooks.Webhooks(c.Request().Context())
+	if err != nil {
+		return c.JSON(webhookStatus(err), ErrorResponse{Error: err.Error()})
+	}
+
+	response := WebhooksResponse{Webhooks: []WebhookResponse{}}
+	for _, webhook := range registered {
+		response.Webhooks = append(response.Webhooks, toWebhookResponse(webhook))
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// GetWebhook describes a registered webhook
+func (h *Handler) GetWebhook(c echo.Context) error {
+	if h.webhooks == nil {
+		return c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "Webhooks are not enabled"})
+	}
+
+	webhook, err := h.webhooks.Webhook(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return c.JSON(webhookStatus(err), ErrorResponse{Error: err.Error()})
+	}
+	return c.JSON(http.StatusOK, toWebhookResponse(webhook))
+}
+
+// DeleteWebhook unregisters a webhook, abandoning its pending deliveries
+func (h *Handler) DeleteWebhook(c echo.Context) error {
+	if h.webhooks == nil {
+		return c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "Webhooks are not enabled"})
+	}
+
+	if err := h.webhooks.Remove(c.Request().Context(), c.Param("id")); err != nil {
+		return c.JSON(webhookStatus(err), ErrorResponse{Error: err.Error()})
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetWebhookDeliveries lists the most recent deliveries of a webhook and every attempt made for them
+func (h *Handler) GetWebhookDeliveries(c echo.Context) error {
+	if h.webhooks == nil {
+		return c.JSON(http.StatusNotImplemented, ErrorResponse{Error: "Webhooks are not enabled"})
+	}
+
+	deliveries, err := h.webhooks.Deliveries(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return c.JSON(webhookStatus(err), ErrorResponse{Error: err.Error()})
+	}
+
+	response := DeliveriesResponse{Deliveries: make([]DeliveryResponse, 0, len(deliveries))}
+	for i := len(deliveries) - 1; i >= 0; i-- {
+		delivery := deliveries[i]
+		attempts := make([]AttemptResponse, len(delivery.Attempts))
+		for j, attempt := range delivery.Attempts {
+			attempts[j] = AttemptResponse{
+				Time:       attempt.Time,
+				StatusCode: attempt.StatusCode,
+				Error:      attempt.Error,
+				DurationMs: attempt.Duration.Milliseconds(),
+			}
+		}
+		response.Deliveries = append(response.Deliveries, DeliveryResponse{
+			ID:       delivery.ID,
+			Event:    string(delivery.Event.Type),
+			Key:      delivery.Event.Key,
+			Time:     delivery.Event.Time,
+			State:    string(delivery.State),
+			Attempts: attempts,
+		})
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func toWebhookResponse(webhook webhooks.Webhook) WebhookResponse {
+	events := make([]string, len(webhook.Events))
+	for i, event := range webhook.Events {
+		events[i] = string(event)
+	}
+	return WebhookResponse{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Prefix:    webhook.Prefix,
+		Events:    events,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+// webhookStatus maps the errors of the dispatcher to HTTP status codes
+func webhookStatus(err error) int {
+	if errors.Is(err, webhooks.ErrNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, webhooks.ErrInvalidURL) || errors.Is(err, webhooks.ErrInvalidEvent) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"key-value/services/api-gateway/internal/webhooks"
+	"key-value/shared/limits"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// call runs a handler with the given method, path parameters and body and returns the recorder
+func call(handler echo.HandlerFunc, method, body string, params ...string) *httptest.ResponseRecorder {
+	e := echo.New()
+	req := httptest.NewRequest(method, "/", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	for i := 0; i+1 < len(params); i += 2 {
+		c.SetParamNames(params[i])
+		c.SetParamValues(params[i+1])
+	}
+	handler(c)
+	return rec
+}
+
+func TestHandler_Webhooks(t *testing.T) {
+	var mutex sync.Mutex
+	var received []webhooks.Payload
+	var signatures []bool
+	fail := true
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload webhooks.Payload
+		json.Unmarshal(body, &payload)
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		received = append(received, payload)
+		signatures = append(signatures, webhooks.Verify("s3cret", body, r.Header.Get(webhooks.HeaderSignature)))
+		// The first attempt fails so the delivery is retried
+		if fail {
+			fail = false
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer receiver.Close()
+
+	dispatcher := webhooks.NewDispatcher(webhooks.WithBackoff(time.Millisecond, time.Millisecond))
+	defer dispatcher.Close()
+	handler := NewHandler(&MockKVStoreClient{}, limits.Default(), WithWebhooks(dispatcher))
+
+	rec := call(handler.CreateWebhook, http.MethodPost,
+		`{"url": "`+receiver.URL+`", "prefix": "user:", "events": ["set"], "secret": "s3cret"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	var webhook WebhookResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &webhook))
+	assert.Equal(t, "s3cret", webhook.Secret)
+	assert.Equal(t, []string{"set"}, webhook.Events)
+
+	// Only the set of a matching key is delivered
+	dispatcher.Notify(webhooks.Event{Type: webhooks.EventSet, Key: "user:1", Value: "alice"})
+	dispatcher.Notify(webhooks.Event{Type: webhooks.EventSet, Key: "order:1", Value: "book"})
+	dispatcher.Notify(webhooks.Event{Type: webhooks.EventDelete, Key: "user:1"})
+
+	var deliveries DeliveriesResponse
+	require.Eventually(t, func() bool {
+		rec := call(handler.GetWebhookDeliveries, http.MethodGet, "", "id", webhook.ID)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &deliveries))
+		return len(deliveries.Deliveries) == 1 && deliveries.Deliveries[0].State == "succeeded"
+	}, 5*time.Second, 5*time.Millisecond)
+
+	delivery := deliveries.Deliveries[0]
+	assert.Equal(t, "set", delivery.Event)
+	assert.Equal(t, "user:1", delivery.Key)
+	require.Len(t, delivery.Attempts, 2)
+	assert.Equal(t, http.StatusBadGateway, delivery.Attempts[0].StatusCode)
+	assert.NotEmpty(t, delivery.Attempts[0].Error)
+	assert.Equal(t, http.StatusOK, delivery.Attempts[1].StatusCode)
+
+	mutex.Lock()
+	require.Len(t, received, 2)
+	assert.Equal(t, "alice", received[1].Value)
+	assert.Equal(t, []bool{true, true}, signatures)
+	mutex.Unlock()
+
+	// The secret is not listed
+	rec = call(handler.GetWebhook, http.MethodGet, "", "id", webhook.ID)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "s3cret")
+
+	assert.Equal(t, http.StatusNoContent, call(handler.DeleteWebhook, http.MethodDelete, "", "id", webhook.ID).Code)
+	assert.Equal(t, http.StatusNotFound, call(handler.GetWebhookDeliveries, http.MethodGet, "", "id", webhook.ID).Code)
+}
+
+func TestHandler_CreateWebhook(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    string
+		expectedStatus int
+	}{
+		{name: "registered", requestBody: `{"url": "http://example.com/hook"}`, expectedStatus: http.StatusCreated},
+		{name: "invalid url", requestBody: `{"url": "example.com"}`, expectedStatus: http.StatusBadRequest},
+		{name: "unknown event", requestBody: `{"url": "http://example.com", "events": ["update"]}`, expectedStatus: http.StatusBadRequest},
+		{name: "invalid JSON", requestBody: `{"url": 1}`, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dispatcher := webhooks.NewDispatcher()
+			defer dispatcher.Close()
+			handler := NewHandler(&MockKVStoreClient{}, limits.Default(), WithWebhooks(dispatcher))
+
+			rec := call(handler.CreateWebhook, http.MethodPost, tt.requestBody)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestHandler_WebhooksDisabled(t *testing.T) {
+	handler := NewHandler(&MockKVStoreClient{}, limits.Default())
+	assert.Equal(t, http.StatusNotImplemented, call(handler.ListWebhooks, http.MethodGet, "").Code)
+}
@@ -3,12 +3,23 @@ package router
 import (
 	"key-value/services/api-gateway/internal/config"
 	"key-value/services/api-gateway/internal/handlers"
+	"key-value/services/api-gateway/internal/webhooks"
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
 )
 
-func SetupRoutes(e *echo.Echo, config *config.Config, kvstoreClient handlers.KVStoreInterface) error {
+// maxBatchBody is the body limit of batch requests, in bytes
+const maxBatchBody = 32 << 20
+
+// noBodyLimit is used when no limits are configured
+func noBodyLimit(next echo.HandlerFunc) echo.HandlerFunc {
+	return next
+}
+
+func SetupRoutes(e *echo.Echo, config *config.Config, kvstoreClient handlers.KVStoreInterface, dispatcher *webhooks.Dispatcher) error {
 
 	// Health check endpoint
 	e.GET("/health", func(c echo.Context) error {
@@ -27,13 +38,49 @@ func SetupRoutes(e *echo.Echo, config *config.Config, kvstoreClient handlers.KVS
 			}
 		})
 
+	// Reject oversized bodies before they are read into memory. JSON escaping can grow
+	// a value, so leave room above the raw limits; the handlers check the exact sizes.
+	// Batches carry many pairs, so they get their own, larger limit.
+	singleLimit, batchLimit := noBodyLimit, noBodyLimit
+	if config.Limits.MaxKeyLength > 0 && config.Limits.MaxValueSize > 0 {
+		bodyLimit := 2*(config.Limits.MaxKeyLength+config.Limits.MaxValueSize) + 1024
+		singleLimit = middleware.BodyLimit(strconv.Itoa(bodyLimit))
+		batchLimit = middleware.BodyLimit(strconv.Itoa(max(bodyLimit, maxBatchBody)))
+	}
+
 	// Initialize handlers
-	handler := handlers.NewHandler(kvstoreClient)
+	handler := handlers.NewHandler(kvstoreClient, config.Limits, handlers.WithWebhooks(dispatcher))
 
 	// Value endpoints
+	v1.GET("/values", handler.ScanValues)
 	v1.GET("/values/:key", handler.GetValueByKey)
-	v1.PUT("/values", handler.UpdateValue)
+	v1.PUT("/values", handler.UpdateValue, singleLimit)
 	v1.DELETE("/values/:key", handler.DeleteValue)
+	v1.POST("/values/:key/increment", handler.IncrementValue, singleLimit)
+	v1.GET("/values/:key/history", handler.GetValueHistory)
+
+	// Batch endpoints, fanned out across shards when the gateway is sharded
+	v1.POST("/values/batch-get", handler.BatchGetValues, batchLimit)
+	v1.PUT("/values/batch", handler.BatchUpdateValues, batchLimit)
+
+	// Bulk endpoints stream their pairs, so imports are not bounded by a body limit. Only available when the
+	// gateway is not sharded.
+	v1.GET("/export", handler.ExportValues)
+	v1.POST("/import", handler.ImportValues)
+
+	// Admin endpoints, rebalancing is only available when the gateway is sharded
+	v1.POST("/admin/rebalance", handler.StartRebalance)
+	v1.GET("/admin/rebalance", handler.GetRebalanceStatus)
+
+	// Drops old revisions of keys, only available when the gateway is not sharded
+	v1.POST("/admin/compact", handler.Compact)
+
+	// Webhooks notified about the changes of the store
+	v1.POST("/webhooks", handler.CreateWebhook)
+	v1.GET("/webhooks", handler.ListWebhooks)
+	v1.GET("/webhooks/:id", handler.GetWebhook)
+	v1.DELETE("/webhooks/:id", handler.DeleteWebhook)
+	v1.GET("/webhooks/:id/deliveries", handler.GetWebhookDeliveries)
 
 	return nil
 }
@@ -0,0 +1,726 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"key-value/client"
+	"key-value/client/lock"
+	"key-value/shared/models"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventType is the kind of change a webhook is notified about
+type EventType string
+
+// Event types
+const (
+	EventSet    EventType = "set"
+	EventDelete EventType = "delete"
+)
+
+// DeliveryState is the outcome of a delivery
+type DeliveryState string
+
+// Delivery states
+const (
+	DeliveryPending   DeliveryState = "pending"
+	DeliverySucceeded DeliveryState = "succeeded"
+	DeliveryFailed    DeliveryState = "failed"
+	DeliveryDropped   DeliveryState = "dropped" // the queue of the webhook was full when notified
+)
+
+// Headers sent with every delivery
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderWebhook   = "X-Webhook-Id"
+)
+
+const (
+	DefaultMaxAttempts = 5
+	DefaultBackoff     = time.Second
+	DefaultMaxBackoff  = 30 * time.Second
+	DefaultTimeout     = 10 * time.Second
+
+	defaultQueueSize = 1024 // pending deliveries per webhook
+	history          = 100  // deliveries kept per webhook
+)
+
+// StatePrefix starts the keys the gateways keep their own state in. Changes of these keys are not delivered.
+const StatePrefix = "_gateway/"
+
+// Group is the consumer group of the change stream whose offset is the last change handed to the webhooks
+const Group = "api-gateway-webhooks"
+
+const (
+	webhookPrefix = StatePrefix + "webhooks/" // followed by a webhook ID, holds the webhook as JSON
+	lockPrefix    = StatePrefix + "locks/"
+	lockName      = "webhooks" // held by the gateway delivering the changes
+
+	retryInterval = 5 * time.Second // wait before following the changes again after a failure
+	scanPage      = 100             // webhooks read per scan
+)
+
+var (
+	ErrNotFound     = errors.New("webhook not found")
+	ErrInvalidURL   = errors.New("webhook url must be an absolute http or https url")
+	ErrInvalidEvent = errors.New("unknown webhook event type")
+)
+
+// Webhook is an HTTP endpoint notified about the changes of keys starting with Prefix.
+// An empty Events list subscribes to every event type.
+type Webhook struct {
+	ID        string
+	URL       string
+	Prefix    string
+	Events    []EventType
+	Secret    string
+	CreatedAt time.Time
+}
+
+// Store keeps the registered webhooks, so every gateway shares them and they outlive restarts. The key-value
+// clients implement it.
+type Store interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, kv models.KeyValue) error
+	Delete(ctx context.Context, key string) error
+	Scan(ctx context.Context, prefix string, startAfter string, limit int) ([]models.KeyValue, bool, error)
+}
+
+// ChangeSource streams the changes of the store to a consumer group, like client.KVStoreClient
+type ChangeSource interface {
+	Consume(ctx context.Context, group string, handle func(client.Change) error) error
+	Offset(ctx context.Context, group string) (client.ChangeOffset, error)
+	CommitOffset(ctx context.Context, group string, seq uint64) error
+}
+
+// Source streams the changes of the store and grants the lock electing the gateway that delivers them, like
+// client.KVStoreClient
+type Source interface {
+	ChangeSource
+	lock.Client
+}
+
+// Event is a change of the store
+type Event struct {
+	Type  EventType
+	Key   string
+	Value string // new value of set events
+	Time  time.Time
+}
+
+// Attempt is one try at delivering an event
+type Attempt struct {
+	Time       time.Time
+	StatusCode int // 0 when no response was received
+	Error      string
+	Duration   time.Duration
+}
+
+// Delivery is an event sent to one webhook along with every attempt made so far
+type Delivery struct {
+	ID        string
+	WebhookID string
+	Event     Event
+	State     DeliveryState
+	Attempts  []Attempt
+}
+
+// Payload is the JSON body POSTed to webhooks
+type Payload struct {
+	DeliveryID string    `json:"delivery_id"`
+	WebhookID  string    `json:"webhook_id"`
+	Event      EventType `json:"event"`
+	Key        string    `json:"key"`
+	Value      string    `json:"value,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// Dispatcher delivers events to the registered webhooks. Every webhook has its own queue, so a slow or failing
+// endpoint does not hold up the others until its queue is full, and receives its events in order.
+//
+// With a Store the webhooks are registered in the key-value store and the dispatcher delivers its changes, streamed
+// by Run, which wait for room in full queues rather than being dropped; without one they are kept in memory and only
+// receive the events passed to Notify. Deliveries are kept in memory by the dispatcher making them.
+type Dispatcher struct {
+	store       Store
+	mutex       sync.Mutex
+	hooks       map[string]*hook // webhooks delivered to
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	now         func() time.Time
+	queueSize   int
+}
+
+// hook is a registered webhook with its queue and recent deliveries
+type hook struct {
+	Webhook
+	queue      chan *Delivery
+	deliveries []*Delivery // oldest first
+	cancel     context.CancelFunc
+	done       chan struct{}
+}
+
+// Option configures a Dispatcher
+type Option func(*Dispatcher)
+
+// WithMaxAttempts sets how many times a delivery is tried before it fails
+func WithMaxAttempts(n int) Option {
+	return func(d *Dispatcher) {
+		if n > 0 {
+			d.maxAttempts = n
+		}
+	}
+}
+
+// WithBackoff sets the wait after the first failed attempt, doubled after each further one up to max
+func WithBackoff(initial, max time.Duration) Option {
+	return func(d *Dispatcher) {
+		if initial > 0 {
+			d.backoff = initial
+		}
+		if max > 0 {
+			d.maxBackoff = max
+		}
+	}
+}
+
+// WithStore registers the webhooks in store
+func WithStore(store Store) Option {
+	return func(d *Dispatcher) {
+		d.store = store
+	}
+}
+
+// WithTimeout bounds each attempt
+func WithTimeout(timeout time.Duration) Option {
+	return func(d *Dispatcher) {
+		if timeout > 0 {
+			d.client.Timeout = timeout
+		}
+	}
+}
+
+// NewDispatcher creates a dispatcher without webhooks
+func NewDispatcher(opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		hooks:       make(map[string]*hook),
+		client:      &http.Client{Timeout: DefaultTimeout},
+		maxAttempts: DefaultMaxAttempts,
+		backoff:     DefaultBackoff,
+		maxBackoff:  DefaultMaxBackoff,
+		now:         time.Now,
+		queueSize:   defaultQueueSize,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Register validates and adds a webhook, generating its ID and, when empty, its secret
+func (d *Dispatcher) Register(ctx context.Context, webhook Webhook) (Webhook, error) {
+	target, err := url.Parse(webhook.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return Webhook{}, ErrInvalidURL
+	}
+	for _, event := range webhook.Events {
+		if event != EventSet && event != EventDelete {
+			return Webhook{}, fmt.Errorf("%w: %q", ErrInvalidEvent, event)
+		}
+	}
+
+	webhook.ID = randomHex(8)
+	if webhook.Secret == "" {
+		webhook.Secret = randomHex(32)
+	}
+	webhook.Events = slices.Clone(webhook.Events)
+	webhook.CreatedAt = d.now()
+
+	if d.store == nil {
+		d.add(webhook)
+		return webhook, nil
+	}
+
+	// The dispatcher following the changes starts delivering to the webhook at its registration
+	encoded, err := json.Marshal(webhook)
+	if err != nil {
+		return Webhook{}, err
+	}
+	if err := d.store.Set(ctx, models.KeyValue{Key: webhookPrefix + webhook.ID, Value: string(encoded)}); err != nil {
+		return Webhook{}, fmt.Errorf("failed to register webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// Webhook returns a registered webhook
+func (d *Dispatcher) Webhook(ctx context.Context, id string) (Webhook, error) {
+	if d.store != nil {
+		encoded, found, err := d.store.Get(ctx, webhookPrefix+id)
+		if err != nil {
+			return Webhook{}, fmt.Errorf("failed to read webhook: %w", err)
+		}
+		if !found {
+			return Webhook{}, ErrNotFound
+		}
+		return decode(encoded)
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	h, ok := d.hooks[id]
+	if !ok {
+		return Webhook{}, ErrNotFound
+	}
+	return h.Webhook, nil
+}
+
+// Webhooks lists the registered webhooks, oldest first
+func (d *Dispatcher) Webhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+	if d.store != nil {
+		startAfter := ""
+		for {
+			pairs, more, err := d.store.Scan(ctx, webhookPrefix, startAfter, scanPage)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list webhooks: %w", err)
+			}
+			for _, pair := range pairs {
+				webhook, err := decode(pair.Value)
+				if err != nil {
+					return nil, err
+				}
+				webhooks = append(webhooks, webhook)
+			}
+			if !more || len(pairs) == 0 {
+				break
+			}
+			startAfter = pairs[len(pairs)-1].Key
+		}
+	} else {
+		d.mutex.Lock()
+		for _, h := range d.hooks {
+			webhooks = append(webhooks, h.Webhook)
+		}
+		d.mutex.Unlock()
+	}
+
+	slices.SortFunc(webhooks, func(a, b Webhook) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return webhooks, nil
+}
+
+// Remove unregisters a webhook, abandoning its pending deliveries
+func (d *Dispatcher) Remove(ctx context.Context, id string) error {
+	if d.store != nil {
+		if _, err := d.Webhook(ctx, id); err != nil {
+			return err
+		}
+		if err := d.store.Delete(ctx, webhookPrefix+id); err != nil {
+			return fmt.Errorf("failed to remove webhook: %w", err)
+		}
+		d.remove(id)
+		return nil
+	}
+	if !d.remove(id) {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Deliveries returns the most recent deliveries of a webhook made by this dispatcher, oldest first
+func (d *Dispatcher) Deliveries(ctx context.Context, id string) ([]Delivery, error) {
+	d.mutex.Lock()
+	h, ok := d.hooks[id]
+	var deliveries []Delivery
+	if ok {
+		deliveries = make([]Delivery, len(h.deliveries))
+		for i, delivery := range h.deliveries {
+			deliveries[i] = *delivery
+			deliveries[i].Attempts = slices.Clone(delivery.Attempts)
+		}
+	}
+	d.mutex.Unlock()
+	if ok {
+		return deliveries, nil
+	}
+
+	// Only the dispatcher following the changes delivers
+	if _, err := d.Webhook(ctx, id); err != nil {
+		return nil, err
+	}
+	return []Delivery{}, nil
+}
+
+// add starts delivering to a webhook unless it is delivered to already
+func (d *Dispatcher) add(webhook Webhook) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if _, ok := d.hooks[webhook.ID]; ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &hook{
+		Webhook: webhook,
+		queue:   make(chan *Delivery, d.queueSize),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	d.hooks[webhook.ID] = h
+	go d.run(ctx, h)
+}
+
+// remove stops delivering to a webhook, reporting whether it was delivered to
+func (d *Dispatcher) remove(id string) bool {
+	d.mutex.Lock()
+	h, ok := d.hooks[id]
+	delete(d.hooks, id)
+	d.mutex.Unlock()
+	if ok {
+		h.cancel()
+		<-h.done
+	}
+	return ok
+}
+
+// Run delivers the changes of the store to the webhooks registered in it until ctx is done. Only the gateway
+// holding the webhook lock delivers, so each change is delivered by one gateway; the others wait to take over.
+// Failures are logged and retried.
+func (d *Dispatcher) Run(ctx context.Context, source Source) {
+	locker := lock.NewLocker(source, lock.WithPrefix(lockPrefix))
+	for ctx.Err() == nil {
+		err := d.lead(ctx, source, locker)
+		if err == nil || ctx.Err() != nil {
+			continue
+		}
+		log.Printf("webhooks: %v", err)
+		select {
+		case <-ctx.Done():
+		case <-time.After(retryInterval):
+		}
+	}
+}
+
+// lead takes the webhook lock and follows the changes while holding it
+func (d *Dispatcher) lead(ctx context.Context, source Source, locker *lock.Locker) error {
+	l, err := locker.Lock(ctx, lockName)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		unlockCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), retryInterval)
+		defer cancel()
+		l.Unlock(unlockCtx)
+	}()
+
+	followCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-l.Lost():
+			cancel()
+		case <-followCtx.Done():
+		}
+	}()
+	err = d.Follow(followCtx, source)
+	select {
+	case <-l.Lost():
+		return lock.ErrLost
+	default:
+		return err
+	}
+}
+
+// Follow delivers the changes streamed by source to the webhooks until ctx is done or the stream fails, resuming
+// after the last change handed to them. It loads the registered webhooks first and keeps them up to date with the
+// registrations changed through any gateway. Changes the service no longer retains are skipped with a warning.
+func (d *Dispatcher) Follow(ctx context.Context, source ChangeSource) error {
+	if err := d.load(ctx); err != nil {
+		return err
+	}
+	for {
+		err := source.Consume(ctx, Group, func(change client.Change) error {
+			return d.apply(ctx, change)
+		})
+		if !errors.Is(err, client.ErrChangesExpired) {
+			return err
+		}
+		offset, err := source.Offset(ctx, Group)
+		if err != nil {
+			return err
+		}
+		log.Printf("webhooks: skipping to change %d, the changes after %d are no longer retained",
+			offset.First, offset.Committed)
+		if err := source.CommitOffset(ctx, Group, offset.First-1); err != nil {
+			return err
+		}
+	}
+}
+
+// load delivers to the webhooks registered in the store, and no others
+func (d *Dispatcher) load(ctx context.Context) error {
+	webhooks, err := d.Webhooks(ctx)
+	if err != nil {
+		return err
+	}
+	registered := make(map[string]bool, len(webhooks))
+	for _, webhook := range webhooks {
+		registered[webhook.ID] = true
+		d.add(webhook)
+	}
+
+	d.mutex.Lock()
+	var removed []string
+	for id := range d.hooks {
+		if !registered[id] {
+			removed = append(removed, id)
+		}
+	}
+	d.mutex.Unlock()
+	for _, id := range removed {
+		d.remove(id)
+	}
+	return nil
+}
+
+// apply hands a change of the store to the webhooks, or follows a change of their registrations. It returns once
+// the change is queued for every webhook matching it, so its offset is not committed before.
+func (d *Dispatcher) apply(ctx context.Context, change client.Change) error {
+	switch {
+	case strings.HasPrefix(change.Key, webhookPrefix):
+		if change.Op == client.ChangeDelete {
+			d.remove(strings.TrimPrefix(change.Key, webhookPrefix))
+			return nil
+		}
+		webhook, err := decode(change.Value)
+		if err != nil {
+			log.Printf("webhooks: %v", err)
+			return nil
+		}
+		d.add(webhook)
+	case strings.HasPrefix(change.Key, StatePrefix):
+	default:
+		event := Event{Type: EventSet, Key: change.Key, Value: change.Value, Time: change.Time}
+		if change.Op == client.ChangeDelete {
+			event = Event{Type: EventDelete, Key: change.Key, Time: change.Time}
+		}
+		return d.enqueue(ctx, event)
+	}
+	return nil
+}
+
+// Notify queues the event for every webhook matching it. It never blocks; when the queue of a webhook is full the
+// delivery is recorded as dropped.
+func (d *Dispatcher) Notify(event Event) {
+	for _, q := range d.record(event) {
+		select {
+		case q.hook.queue <- q.delivery:
+		default:
+			d.finish(q.delivery, DeliveryDropped)
+		}
+	}
+}
+
+// enqueue queues the event for every webhook matching it, waiting for room in full queues until ctx is done. A
+// webhook whose queue is full holds up the others until it has room; webhooks removed meanwhile are skipped.
+func (d *Dispatcher) enqueue(ctx context.Context, event Event) error {
+	for _, q := range d.record(event) {
+		select {
+		case q.hook.queue <- q.delivery:
+		case <-q.hook.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// queued is a delivery of an event to a webhook, to be queued
+type queued struct {
+	hook     *hook
+	delivery *Delivery
+}
+
+// record adds a pending delivery of the event to every webhook matching it and returns them
+func (d *Dispatcher) record(event Event) []queued {
+	if event.Time.IsZero() {
+		event.Time = d.now()
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	var deliveries []queued
+	for _, h := range d.hooks {
+		if !h.matches(event) {
+			continue
+		}
+		delivery := &Delivery{ID: randomHex(8), WebhookID: h.ID, Event: event, State: DeliveryPending}
+		h.deliveries = append(h.deliveries, delivery)
+		if len(h.deliveries) > history {
+			h.deliveries = slices.Delete(h.deliveries, 0, len(h.deliveries)-history)
+		}
+		deliveries = append(deliveries, queued{hook: h, delivery: delivery})
+	}
+	return deliveries
+}
+
+// Close stops delivering, abandoning pending deliveries
+func (d *Dispatcher) Close() {
+	d.mutex.Lock()
+	hooks := d.hooks
+	d.hooks = make(map[string]*hook)
+	d.mutex.Unlock()
+
+	for _, h := range hooks {
+		h.cancel()
+	}
+	for _, h := range hooks {
+		<-h.done
+	}
+}
+
+func (h *hook) matches(event Event) bool {
+	if !strings.HasPrefix(event.Key, h.Prefix) {
+		return false
+	}
+	return len(h.Events) == 0 || slices.Contains(h.Events, event.Type)
+}
+
+// run delivers the queued events of a webhook one at a time
+func (d *Dispatcher) run(ctx context.Context, h *hook) {
+	defer close(h.done)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case delivery := <-h.queue:
+			d.deliver(ctx, h.Webhook, delivery)
+		}
+	}
+}
+
+// deliver POSTs the event until the webhook accepts it with a 2xx status or the attempts run out
+func (d *Dispatcher) deliver(ctx context.Context, webhook Webhook, delivery *Delivery) {
+	body, err := json.Marshal(Payload{
+		DeliveryID: delivery.ID,
+		WebhookID:  webhook.ID,
+		Event:      delivery.Event.Type,
+		Key:        delivery.Event.Key,
+		Value:      delivery.Event.Value,
+		Timestamp:  delivery.Event.Time.UTC(),
+	})
+	if err != nil {
+		d.finish(delivery, DeliveryFailed)
+		return
+	}
+	signature := Sign(webhook.Secret, body)
+
+	backoff := d.backoff
+	for attempt := 1; ; attempt++ {
+		result := d.attempt(ctx, webhook, delivery, body, signature)
+		d.mutex.Lock()
+		delivery.Attempts = append(delivery.Attempts, result)
+		d.mutex.Unlock()
+
+		if result.Error == "" {
+			d.finish(delivery, DeliverySucceeded)
+			return
+		}
+		if attempt >= d.maxAttempts {
+			d.finish(delivery, DeliveryFailed)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			d.finish(delivery, DeliveryFailed)
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, d.maxBackoff)
+	}
+}
+
+func (d *Dispatcher) attempt(ctx context.Context, webhook Webhook, delivery *Delivery, body []byte, signature string) (result Attempt) {
+	start := d.now()
+	result.Time = start
+	// The result is named so the duration set here is the one returned
+	defer func() { result.Duration = d.now().Sub(start) }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderSignature, signature)
+	req.Header.Set(HeaderEvent, string(delivery.Event.Type))
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderWebhook, webhook.ID)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		result.Error = "unexpected status " + resp.Status
+	}
+	return result
+}
+
+func (d *Dispatcher) finish(delivery *Delivery, state DeliveryState) {
+	d.mutex.Lock()
+	delivery.State = state
+	d.mutex.Unlock()
+}
+
+// Sign returns the signature header of a payload, the hex HMAC-SHA256 of the body prefixed with "sha256="
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of body under secret
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// decode reads a webhook registered in the store
+func decode(encoded string) (Webhook, error) {
+	var webhook Webhook
+	if err := json.Unmarshal([]byte(encoded), &webhook); err != nil {
+		return Webhook{}, fmt.Errorf("invalid webhook registration: %w", err)
+	}
+	return webhook, nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,416 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"key-value/client"
+	"key-value/shared/models"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver is a local webhook endpoint answering with the queued statuses, then 200
+type receiver struct {
+	*httptest.Server
+	mutex    sync.Mutex
+	statuses []int
+	payloads []Payload
+	headers  []http.Header
+	bodies   [][]byte
+}
+
+func newReceiver(t *testing.T, statuses ...int) *receiver {
+	r := &receiver{statuses: statuses}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		var payload Payload
+		json.Unmarshal(body, &payload)
+
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		r.payloads = append(r.payloads, payload)
+		r.headers = append(r.headers, req.Header.Clone())
+		r.bodies = append(r.bodies, body)
+		status := http.StatusOK
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *receiver) received() []Payload {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]Payload(nil), r.payloads...)
+}
+
+// waitState waits until the delivery at index i of the webhook reaches a final state
+func waitState(t *testing.T, d *Dispatcher, id string, i int, want DeliveryState) Delivery {
+	t.Helper()
+	var delivery Delivery
+	require.Eventually(t, func() bool {
+		deliveries, err := d.Deliveries(context.Background(), id)
+		require.NoError(t, err)
+		if len(deliveries) <= i {
+			return false
+		}
+		delivery = deliveries[i]
+		return delivery.State == want
+	}, 5*time.Second, 5*time.Millisecond)
+	return delivery
+}
+
+func TestDispatcher_SignsPayload(t *testing.T) {
+	r := newReceiver(t)
+	d := NewDispatcher()
+	defer d.Close()
+
+	webhook, err := d.Register(context.Background(), Webhook{URL: r.URL, Secret: "s3cret"})
+	require.NoError(t, err)
+	d.Notify(Event{Type: EventSet, Key: "user:1", Value: "alice"})
+
+	delivery := waitState(t, d, webhook.ID, 0, DeliverySucceeded)
+	require.Len(t, delivery.Attempts, 1)
+	assert.Equal(t, http.StatusOK, delivery.Attempts[0].StatusCode)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	require.Len(t, r.payloads, 1)
+	assert.Equal(t, delivery.ID, r.payloads[0].DeliveryID)
+	assert.Equal(t, webhook.ID, r.payloads[0].WebhookID)
+	assert.Equal(t, EventSet, r.payloads[0].Event)
+	assert.Equal(t, "user:1", r.payloads[0].Key)
+	assert.Equal(t, "alice", r.payloads[0].Value)
+	assert.Equal(t, "set", r.headers[0].Get(HeaderEvent))
+	assert.True(t, Verify("s3cret", r.bodies[0], r.headers[0].Get(HeaderSignature)))
+	assert.False(t, Verify("other", r.bodies[0], r.headers[0].Get(HeaderSignature)))
+}
+
+func TestDispatcher_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantState    DeliveryState
+		wantAttempts int
+	}{
+		{name: "succeeds after failures", statuses: []int{500, 503}, wantState: DeliverySucceeded, wantAttempts: 3},
+		{name: "gives up", statuses: []int{500, 500, 500, 500}, wantState: DeliveryFailed, wantAttempts: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newReceiver(t, tt.statuses...)
+			d := NewDispatcher(WithMaxAttempts(3), WithBackoff(time.Millisecond, 4*time.Millisecond))
+			defer d.Close()
+
+			webhook, err := d.Register(context.Background(), Webhook{URL: r.URL})
+			require.NoError(t, err)
+			d.Notify(Event{Type: EventDelete, Key: "k"})
+
+			delivery := waitState(t, d, webhook.ID, 0, tt.wantState)
+			require.Len(t, delivery.Attempts, tt.wantAttempts)
+			assert.Equal(t, 500, delivery.Attempts[0].StatusCode)
+			assert.NotEmpty(t, delivery.Attempts[0].Error)
+			assert.Len(t, r.received(), tt.wantAttempts)
+		})
+	}
+}
+
+func TestDispatcher_AttemptDuration(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer slow.Close()
+	d := NewDispatcher()
+	defer d.Close()
+
+	webhook, err := d.Register(context.Background(), Webhook{URL: slow.URL})
+	require.NoError(t, err)
+	d.Notify(Event{Type: EventSet, Key: "k", Value: "v"})
+
+	delivery := waitState(t, d, webhook.ID, 0, DeliverySucceeded)
+	require.Len(t, delivery.Attempts, 1)
+	assert.GreaterOrEqual(t, delivery.Attempts[0].Duration, 20*time.Millisecond)
+}
+
+func TestDispatcher_Matches(t *testing.T) {
+	r := newReceiver(t)
+	d := NewDispatcher()
+	defer d.Close()
+
+	users, err := d.Register(context.Background(), Webhook{URL: r.URL, Prefix: "user:", Events: []EventType{EventDelete}})
+	require.NoError(t, err)
+	all, err := d.Register(context.Background(), Webhook{URL: r.URL})
+	require.NoError(t, err)
+
+	d.Notify(Event{Type: EventSet, Key: "user:1", Value: "v"})
+	d.Notify(Event{Type: EventDelete, Key: "order:1"})
+	d.Notify(Event{Type: EventDelete, Key: "user:1"})
+
+	delivery := waitState(t, d, users.ID, 0, DeliverySucceeded)
+	assert.Equal(t, Event{Type: EventDelete, Key: "user:1", Time: delivery.Event.Time}, delivery.Event)
+	waitState(t, d, all.ID, 2, DeliverySucceeded)
+
+	deliveries, err := d.Deliveries(context.Background(), users.ID)
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+}
+
+func TestDispatcher_Register(t *testing.T) {
+	d := NewDispatcher()
+	defer d.Close()
+
+	_, err := d.Register(context.Background(), Webhook{URL: "ftp://example.com"})
+	assert.ErrorIs(t, err, ErrInvalidURL)
+	_, err = d.Register(context.Background(), Webhook{URL: "/relative"})
+	assert.ErrorIs(t, err, ErrInvalidURL)
+	_, err = d.Register(context.Background(), Webhook{URL: "http://example.com", Events: []EventType{"update"}})
+	assert.ErrorIs(t, err, ErrInvalidEvent)
+
+	webhook, err := d.Register(context.Background(), Webhook{URL: "http://example.com"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, webhook.ID)
+	assert.Len(t, webhook.Secret, 64)
+
+	require.NoError(t, d.Remove(context.Background(), webhook.ID))
+	assert.ErrorIs(t, d.Remove(context.Background(), webhook.ID), ErrNotFound)
+	_, err = d.Deliveries(context.Background(), webhook.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	webhooks, err := d.Webhooks(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, webhooks)
+}
+
+// memoryStore is a Store kept in a map
+type memoryStore struct {
+	mutex sync.Mutex
+	data  map[string]string
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{data: map[string]string{}}
+}
+
+func (s *memoryStore) Get(ctx context.Context, key string) (string, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	value, ok := s.data[key]
+	return value, ok, nil
+}
+
+func (s *memoryStore) Set(ctx context.Context, kv models.KeyValue) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.data[kv.Key] = kv.Value
+	return nil
+}
+
+func (s *memoryStore) Delete(ctx context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.data, key)
+	return nil
+}
+
+func (s *memoryStore) Scan(ctx context.Context, prefix string, startAfter string, limit int) ([]models.KeyValue, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var keys []string
+	for key := range s.data {
+		if strings.HasPrefix(key, prefix) && key > startAfter {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	more := len(keys) > limit
+	if more {
+		keys = keys[:limit]
+	}
+	pairs := make([]models.KeyValue, len(keys))
+	for i, key := range keys {
+		pairs[i] = models.KeyValue{Key: key, Value: s.data[key]}
+	}
+	return pairs, more, nil
+}
+
+// changeLog is a ChangeSource streaming the changes from first on to a single group
+type changeLog struct {
+	mutex     sync.Mutex
+	changes   []client.Change
+	first     uint64
+	committed uint64
+}
+
+func (l *changeLog) Consume(ctx context.Context, group string, handle func(client.Change) error) error {
+	l.mutex.Lock()
+	if l.committed+1 < l.first {
+		l.mutex.Unlock()
+		return client.ErrChangesExpired
+	}
+	changes, committed := slices.Clone(l.changes), l.committed
+	l.mutex.Unlock()
+
+	for _, change := range changes {
+		if change.Seq <= committed {
+			continue
+		}
+		if err := handle(change); err != nil {
+			return err
+		}
+		l.CommitOffset(ctx, group, change.Seq)
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (l *changeLog) Offset(ctx context.Context, group string) (client.ChangeOffset, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return client.ChangeOffset{
+		Committed:    l.committed,
+		HasCommitted: true,
+		First:        l.first,
+		Latest:       l.changes[len(l.changes)-1].Seq,
+	}, nil
+}
+
+func (l *changeLog) CommitOffset(ctx context.Context, group string, seq uint64) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.committed = seq
+	return nil
+}
+
+func TestDispatcher_Store(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	d := NewDispatcher(WithStore(store))
+	defer d.Close()
+	other := NewDispatcher(WithStore(store))
+	defer other.Close()
+
+	webhook, err := d.Register(ctx, Webhook{URL: "http://example.com", Prefix: "user:"})
+	require.NoError(t, err)
+	assert.Contains(t, store.data, StatePrefix+"webhooks/"+webhook.ID)
+
+	found, err := other.Webhook(ctx, webhook.ID)
+	require.NoError(t, err)
+	assert.Equal(t, webhook.URL, found.URL)
+	assert.Equal(t, webhook.Secret, found.Secret)
+	webhooks, err := other.Webhooks(ctx)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	assert.Equal(t, webhook.ID, webhooks[0].ID)
+	deliveries, err := other.Deliveries(ctx, webhook.ID)
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+
+	require.NoError(t, other.Remove(ctx, webhook.ID))
+	_, err = d.Webhook(ctx, webhook.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, other.Remove(ctx, webhook.ID), ErrNotFound)
+}
+
+func TestDispatcher_Follow(t *testing.T) {
+	r := newReceiver(t)
+	store := newMemoryStore()
+	registrar := NewDispatcher(WithStore(store))
+	defer registrar.Close()
+	webhook, err := registrar.Register(context.Background(), Webhook{URL: r.URL})
+	require.NoError(t, err)
+
+	changes := &changeLog{
+		first: 2,
+		changes: []client.Change{
+			{Seq: 1, Op: client.ChangeSet, Key: "expired", Value: "v"},
+			{Seq: 2, Op: client.ChangeSet, Key: StatePrefix + "webhooks/" + webhook.ID, Value: store.data[StatePrefix+"webhooks/"+webhook.ID]},
+			{Seq: 3, Op: client.ChangeSet, Key: "user:1", Value: "alice"},
+			{Seq: 4, Op: client.ChangeSet, Key: StatePrefix + "locks/webhooks", Value: "1"},
+			{Seq: 5, Op: client.ChangeDelete, Key: "user:1"},
+		},
+	}
+	d := NewDispatcher(WithStore(store))
+	defer d.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- d.Follow(ctx, changes) }()
+
+	waitState(t, d, webhook.ID, 1, DeliverySucceeded)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+
+	payloads := r.received()
+	require.Len(t, payloads, 2)
+	assert.Equal(t, EventSet, payloads[0].Event)
+	assert.Equal(t, "user:1", payloads[0].Key)
+	assert.Equal(t, "alice", payloads[0].Value)
+	assert.Equal(t, EventDelete, payloads[1].Event)
+	assert.Equal(t, "user:1", payloads[1].Key)
+	assert.Equal(t, uint64(5), changes.committed)
+}
+
+func TestDispatcher_FollowBackpressure(t *testing.T) {
+	release := make(chan struct{})
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-release
+	}))
+	defer endpoint.Close()
+	defer close(release)
+
+	store := newMemoryStore()
+	registrar := NewDispatcher(WithStore(store))
+	defer registrar.Close()
+	webhook, err := registrar.Register(context.Background(), Webhook{URL: endpoint.URL})
+	require.NoError(t, err)
+
+	key := StatePrefix + "webhooks/" + webhook.ID
+	changes := &changeLog{
+		first: 1,
+		changes: []client.Change{
+			{Seq: 1, Op: client.ChangeSet, Key: key, Value: store.data[key]},
+			{Seq: 2, Op: client.ChangeSet, Key: "a", Value: "1"},
+			{Seq: 3, Op: client.ChangeSet, Key: "b", Value: "2"},
+			{Seq: 4, Op: client.ChangeSet, Key: "c", Value: "3"},
+		},
+	}
+	d := NewDispatcher(WithStore(store))
+	defer d.Close()
+	d.queueSize = 1
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Follow(ctx, changes)
+
+	// The first change is being delivered and the second fills the queue, so the third waits uncommitted
+	require.Eventually(t, func() bool {
+		offset, _ := changes.Offset(ctx, Group)
+		return offset.Committed == 3
+	}, 5*time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	offset, err := changes.Offset(ctx, Group)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), offset.Committed)
+
+	release <- struct{}{}
+	require.Eventually(t, func() bool {
+		offset, _ := changes.Offset(ctx, Group)
+		return offset.Committed == 4
+	}, 5*time.Second, 5*time.Millisecond)
+	deliveries, err := d.Deliveries(ctx, webhook.ID)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 3)
+	for _, delivery := range deliveries {
+		assert.NotEqual(t, DeliveryDropped, delivery.State)
+	}
+}
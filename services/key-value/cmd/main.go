@@ -1,17 +1,39 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
 	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/antientropy"
+	"key-value/services/key-value/internal/cdc"
 	"key-value/services/key-value/internal/config"
+	"key-value/services/key-value/internal/encryption"
+	"key-value/services/key-value/internal/history"
 	"key-value/services/key-value/internal/kvstore"
+	"key-value/services/key-value/internal/lease"
+	"key-value/services/key-value/internal/membership"
+	"key-value/services/key-value/internal/memcache"
+	"key-value/services/key-value/internal/meta"
+	"key-value/services/key-value/internal/raftstore"
+	"key-value/services/key-value/internal/replication"
+	"key-value/services/key-value/internal/resp"
 	"key-value/services/key-value/internal/server"
+	"key-value/services/key-value/internal/watch"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 )
 
@@ -19,17 +41,226 @@ func main() {
 	// Load configuration
 	config := config.Load()
 
-	// Create the key-value store
-	store := kvstore.NewInMemoryStore()
+	// Create the key-value store with the configured backend, sealing what the file backend writes with a data key
+	// kept next to its data
+	var storeCipher *encryption.Cipher
+	if config.Store.Dir != "" {
+		cipher, err := openDataKey(config.Store.Dir, "STORE_DIR", config.Encryption)
+		if err != nil {
+			log.Fatalf("Failed to open the data key: %v", err)
+		}
+		storeCipher = cipher
+	}
+	store, err := kvstore.Open(config.Store.Backend, kvstore.BackendOptions{
+		Limits: config.Limits,
+		Shards: config.Store.Shards,
+		Dir:    config.Store.Dir,
+		Cipher: storeCipher,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create the store: %v", err)
+	}
+	if closer, ok := store.(io.Closer); ok {
+		defer closer.Close()
+	}
+	if config.Store.Backend != "" {
+		log.Printf("🗄️ Storing data with the %s backend", config.Store.Backend)
+	}
+	kvOptions := []server.Option{server.WithLimits(config.Limits)}
+
+	// Make sure a maximum sized request or reply still fits in a message, both served and sent to other nodes
+	maxMsgSize := config.Limits.MessageSize()
+	dialOptions := []grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxMsgSize), grpc.MaxCallSendMsgSize(maxMsgSize)),
+	}
+
+	// History, change capture and watches wrap the store below Raft and replication, so every node records the
+	// writes those apply and not only the writes made through it
+	var historyStore *history.Store
+	if config.History.Enabled {
+		historyStore, err = history.NewStore(store,
+			history.WithMaxRevisions(config.History.MaxRevisions),
+			history.WithMaxAge(config.History.MaxAge),
+		)
+		if err != nil {
+			log.Fatalf("Failed to open the key history: %v", err)
+		}
+		defer historyStore.Close()
+		store = historyStore
+		log.Println("🕰️ Keeping key history")
+	}
+
+	var changeLog *cdc.Log
+	if config.CDC.Enabled {
+		changeLog, err = cdc.NewLog(store, cdc.WithRetention(config.CDC.Retention), cdc.WithMaxAge(config.CDC.MaxAge))
+		if err != nil {
+			log.Fatalf("Failed to open the change log: %v", err)
+		}
+		store = changeLog
+		log.Println("📜 Capturing changes")
+	}
+
+	watchStore := watch.NewStore(store)
+	store = watchStore
+
+	if config.Raft.NodeID != "" && config.Replication.Role != "" {
+		log.Fatal("Raft and primary/follower replication cannot be enabled together")
+	}
+
+	// Optionally replicate writes through a Raft group
+	var raftNode *raftstore.Node
+	if config.Raft.NodeID != "" {
+		var dataCipher *encryption.Cipher
+		if config.Raft.DataDir != "" {
+			cipher, err := openDataKey(config.Raft.DataDir, "RAFT_DATA_DIR", config.Encryption)
+			if err != nil {
+				log.Fatalf("Failed to open the data key: %v", err)
+			}
+			dataCipher = cipher
+			log.Printf("🔐 Keeping encrypted Raft data in %s", config.Raft.DataDir)
+		}
+		node, err := raftstore.NewNode(raftstore.Config{
+			NodeID:            config.Raft.NodeID,
+			RaftAddr:          config.Raft.Addr,
+			RaftAdvertiseAddr: config.Raft.AdvertiseAddr,
+			GRPCAddr:          config.Raft.GRPCAddr,
+			Bootstrap:         config.Raft.Bootstrap,
+			ReadConsistency:   config.Raft.ReadConsistency,
+			DataDir:           config.Raft.DataDir,
+			Cipher:            dataCipher,
+		}, store)
+		if err != nil {
+			log.Fatalf("Failed to start Raft node: %v", err)
+		}
+		raftNode = node
+		store = node
+		if config.Raft.ForwardRequests {
+			kvOptions = append(kvOptions, server.WithForwarding(dialOptions...))
+		}
+		log.Printf("🗳️ Raft node %s listening on %s", config.Raft.NodeID, node.RaftAddr())
+	}
+
+	// Optionally stream mutations from a primary to read replicas
+	var replicationNode server.ReplicationNode
+	switch config.Replication.Role {
+	case "":
+	case replication.RolePrimary:
+		primary := replication.NewPrimary(store, config.Replication.LogSize)
+		replicationNode = primary
+		store = primary
+		log.Println("📤 Replicating as primary")
+	case replication.RoleFollower:
+		if config.Replication.PrimaryAddr == "" {
+			log.Fatal("REPLICATION_PRIMARY_ADDR is required for followers")
+		}
+		follower, err := replication.NewFollower(store, config.Replication.PrimaryAddr, replication.WithDialOptions(dialOptions...))
+		if err != nil {
+			log.Fatalf("Failed to start replication follower: %v", err)
+		}
+		defer follower.Close()
+		replicationNode = follower
+		store = follower
+		log.Printf("📥 Replicating as follower of %s", config.Replication.PrimaryAddr)
+	default:
+		log.Fatalf("Unknown replication role %q, expected primary or follower", config.Replication.Role)
+	}
+
+	// Keep item flags and CAS versions of the memcached protocol below the leases, so expired keys lose their metadata
+	metaStore := meta.NewStore(store)
+	store = metaStore
+
+	// Grant leases on top of everything else, so keys of expired leases are deleted through Raft or replication
+	leaseStore := lease.NewStore(store, lease.WithCheckInterval(config.Lease.CheckInterval))
+	defer leaseStore.Close()
+	store = leaseStore
 
 	// Create the gRPC server
-	grpcServer := grpc.NewServer()
+	serverOptions := []grpc.ServerOption{grpc.MaxRecvMsgSize(maxMsgSize), grpc.MaxSendMsgSize(maxMsgSize)}
+	grpcServer := grpc.NewServer(serverOptions...)
 
 	reflection.Register(grpcServer) // Allows for gRPC endpoit discovery (helpful for postman testing)
 
 	// Create and register our service
-	kvServer := server.NewKeyValueServer(store)
+	kvServer := server.NewKeyValueServer(store, kvOptions...)
+	defer kvServer.Close()
 	keyvalue.RegisterKeyValueServiceServer(grpcServer, kvServer)
+	keyvalue.RegisterBulkServiceServer(grpcServer, kvServer.Bulk())
+
+	if raftNode != nil {
+		clusterServer := server.NewClusterServer(raftNode)
+		defer clusterServer.Close()
+		keyvalue.RegisterClusterServiceServer(grpcServer, clusterServer)
+	}
+
+	if changeLog != nil {
+		keyvalue.RegisterChangeServiceServer(grpcServer, server.NewChangeServer(changeLog, store))
+	}
+
+	if historyStore != nil {
+		keyvalue.RegisterHistoryServiceServer(grpcServer, server.NewHistoryServer(historyStore))
+	}
+
+	keyvalue.RegisterLeaseServiceServer(grpcServer, kvServer.Leases(leaseStore))
+	keyvalue.RegisterWatchServiceServer(grpcServer, server.NewWatchServer(watchStore))
+
+	if replicationNode != nil {
+		keyvalue.RegisterReplicationServiceServer(grpcServer, server.NewReplicationServer(replicationNode))
+	} else {
+		// Restoring a backup replaces the whole store, which replication cannot ship to its followers
+		keyvalue.RegisterBackupServiceServer(grpcServer, kvServer.Backups())
+	}
+
+	// Versioned keys for clients coordinating quorum reads and writes, kept apart from the store above
+	versionedStore := kvstore.NewVersionedStore()
+	keyvalue.RegisterVersionedServiceServer(grpcServer, server.NewVersionedServer(versionedStore, config.Limits))
+
+	// Repair the versioned keys against the other replicas with Merkle trees
+	trees := antientropy.NewTrees(versionedStore)
+	var repairer *antientropy.Repairer
+	if len(config.AntiEntropy.Peers) > 0 {
+		r, err := antientropy.NewRepairer(versionedStore, trees, config.AntiEntropy.Peers,
+			antientropy.WithInterval(config.AntiEntropy.Interval),
+			antientropy.WithDialOptions(dialOptions...))
+		if err != nil {
+			log.Fatalf("Failed to start anti-entropy: %v", err)
+		}
+		defer r.Close()
+		repairer = r
+		expvar.Publish("anti_entropy", expvar.Func(func() any {
+			stats := repairer.Stats()
+			return map[string]any{
+				"last_repair_unix": stats.LastRepair.Unix(),
+				"keys_repaired":    stats.KeysRepaired,
+				"rounds":           stats.Rounds,
+				"last_error":       stats.LastError,
+			}
+		}))
+		log.Printf("🌳 Anti-entropy with %d peers every %s", len(config.AntiEntropy.Peers), config.AntiEntropy.Interval)
+	}
+	keyvalue.RegisterAntiEntropyServiceServer(grpcServer, server.NewAntiEntropyServer(versionedStore, trees, repairer))
+
+	// Optionally discover the other key-value services and detect their failures through gossip
+	var gossip *membership.Gossip
+	if config.Gossip.NodeID != "" {
+		g, err := membership.NewGossip(config.Gossip.NodeID, config.Gossip.AdvertiseAddr,
+			membership.WithProbeInterval(config.Gossip.ProbeInterval),
+			membership.WithProbeTimeout(config.Gossip.ProbeInterval/2),
+			membership.WithSuspicionTimeout(config.Gossip.SuspicionTimeout),
+		)
+		if err != nil {
+			log.Fatalf("Failed to start gossip membership: %v", err)
+		}
+		defer g.Close()
+		gossip = g
+		keyvalue.RegisterMembershipServiceServer(grpcServer, server.NewMembershipServer(g))
+		expvar.Publish("members", expvar.Func(func() any { return g.Members() }))
+		log.Printf("🛰️ Gossip member %s advertising %s", config.Gossip.NodeID, config.Gossip.AdvertiseAddr)
+	}
+
+	// Standard gRPC health service so load balancing clients can skip unhealthy replicas
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
 
 	lis, err := net.Listen("tcp", ":"+config.Port)
 	if err != nil {
@@ -38,6 +269,48 @@ func main() {
 
 	log.Printf("🚀 gRPC Key-Value server starting on port %s", config.Port)
 
+	// Metrics are published with expvar and served on /debug/vars
+	if config.MetricsAddr != "" {
+		go func() {
+			if err := http.ListenAndServe(config.MetricsAddr, nil); err != nil {
+				log.Printf("Failed to serve metrics: %v", err)
+			}
+		}()
+		log.Printf("📈 Metrics on http://%s/debug/vars", config.MetricsAddr)
+	}
+
+	// Optionally serve the store to Redis clients
+	if config.RESP.Addr != "" {
+		respLis, err := net.Listen("tcp", config.RESP.Addr)
+		if err != nil {
+			log.Fatalf("Failed to listen for RESP on %s: %v", config.RESP.Addr, err)
+		}
+		respServer := resp.NewServer(store, resp.WithLimits(config.Limits))
+		defer respServer.Close()
+		go func() {
+			if err := respServer.Serve(respLis); err != nil && !errors.Is(err, resp.ErrServerClosed) {
+				log.Printf("Failed to serve RESP: %v", err)
+			}
+		}()
+		log.Printf("🧰 Redis protocol on %s", config.RESP.Addr)
+	}
+
+	// Optionally serve the store to memcached clients
+	if config.Memcache.Addr != "" {
+		memcacheLis, err := net.Listen("tcp", config.Memcache.Addr)
+		if err != nil {
+			log.Fatalf("Failed to listen for memcached protocol on %s: %v", config.Memcache.Addr, err)
+		}
+		memcacheServer := memcache.NewServer(metaStore, memcache.WithLimits(config.Limits), memcache.WithExpirer(leaseStore))
+		defer memcacheServer.Close()
+		go func() {
+			if err := memcacheServer.Serve(memcacheLis); err != nil && !errors.Is(err, memcache.ErrServerClosed) {
+				log.Printf("Failed to serve memcached protocol: %v", err)
+			}
+		}()
+		log.Printf("🗃️ Memcached protocol on %s", config.Memcache.Addr)
+	}
+
 	// Start server in a goroutine
 	go func() {
 		if err := grpcServer.Serve(lis); err != nil {
@@ -47,6 +320,35 @@ func main() {
 
 	log.Println("✅ gRPC server started successfully. Press Ctrl+C to shutdown gracefully.")
 
+	// Join an existing Raft group through one of its members
+	joinCtx, cancelJoin := context.WithCancel(context.Background())
+	defer cancelJoin()
+	if raftNode != nil && config.Raft.Join != "" {
+		go func() {
+			err := server.JoinCluster(joinCtx, config.Raft.Join, &keyvalue.AddMemberRequest{
+				Id:       config.Raft.NodeID,
+				RaftAddr: raftNode.RaftAddr(),
+				GrpcAddr: config.Raft.GRPCAddr,
+			})
+			if err != nil {
+				log.Printf("Failed to join Raft cluster: %v", err)
+				return
+			}
+			log.Printf("✅ Joined Raft cluster through %s", config.Raft.Join)
+		}()
+	}
+
+	// Join the gossip group; members started later are retried in the background
+	if gossip != nil && len(config.Gossip.Seeds) > 0 {
+		go func() {
+			if err := gossip.Join(joinCtx, config.Gossip.Seeds); err != nil {
+				log.Printf("Failed to join gossip group, retrying: %v", err)
+				return
+			}
+			log.Printf("✅ Joined gossip group through %v", config.Gossip.Seeds)
+		}()
+	}
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -54,7 +356,46 @@ func main() {
 
 	log.Println("🛑 Received shutdown signal, starting graceful shutdown...")
 
+	// Mark the replica as not serving so clients move their traffic elsewhere before we stop
+	healthServer.Shutdown()
+
+	// Tell the other members we are leaving rather than letting them time out
+	if gossip != nil {
+		leaveCtx, cancelLeave := context.WithTimeout(context.Background(), config.Gossip.ProbeInterval)
+		if err := gossip.Leave(leaveCtx); err != nil {
+			log.Printf("Failed to announce leaving the gossip group: %v", err)
+		}
+		cancelLeave()
+	}
+
 	// Graceful shutdown
+	cancelJoin()
 	grpcServer.GracefulStop()
+	if raftNode != nil {
+		if err := raftNode.Shutdown(); err != nil {
+			log.Printf("Failed to shut down Raft node: %v", err)
+		}
+	}
 	log.Println("✅ gRPC server exited gracefully")
 }
+
+// openDataKey returns the cipher of the data key kept in dir, wrapped by the master key of the key file. Data is
+// never written to disk unencrypted, so the key file is required by the setting naming dir.
+func openDataKey(dir string, setting string, keys config.EncryptionConfig) (*encryption.Cipher, error) {
+	if keys.KeyFile == "" {
+		return nil, fmt.Errorf("%s requires ENCRYPTION_KEY_FILE", setting)
+	}
+	master, err := encryption.LoadMasterKey(keys.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	var previous []*encryption.MasterKey
+	for _, path := range keys.PreviousKeyFiles {
+		key, err := encryption.LoadMasterKey(path)
+		if err != nil {
+			return nil, err
+		}
+		previous = append(previous, key)
+	}
+	return encryption.OpenDataKey(filepath.Join(dir, "data.key"), master, previous...)
+}
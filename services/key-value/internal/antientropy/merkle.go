@@ -0,0 +1,191 @@
+// Package antientropy repairs drift between replicas of the versioned keys. Each node hashes its keys
+// into a Merkle tree over hash ranges; peers compare trees top down and exchange only the keys that differ.
+package antientropy
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"hash/fnv"
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/shared/vclock"
+	"sort"
+	"sync"
+)
+
+// DefaultDepth gives the tree 1024 leaves
+const DefaultDepth = 10
+
+// MaxDepth bounds the size of a tree a peer may ask for
+const MaxDepth = 16
+
+// Hash is the hash of a tree node or the digest of a key
+type Hash = [sha256.Size]byte
+
+// Tree is a Merkle tree over the keys of a store. Keys are placed in 2^depth leaves by the hash of the key,
+// a leaf hashes the digests of its keys and every inner node hashes its two children.
+type Tree struct {
+	depth   int
+	levels  [][]Hash          // levels[0] is the root, levels[depth] the leaves
+	buckets []map[string]Hash // digest of every key, per leaf
+}
+
+// Bucket returns the leaf of a tree of the given depth holding key
+func Bucket(key string, depth int) int {
+	if depth == 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return int(h.Sum64() >> (64 - depth))
+}
+
+// Build hashes the siblings of every key into a tree of the given depth
+func Build(data map[string][]vclock.Version, depth int) *Tree {
+	t := &Tree{
+		depth:   depth,
+		levels:  make([][]Hash, depth+1),
+		buckets: make([]map[string]Hash, 1<<depth),
+	}
+	for key, versions := range data {
+		if len(versions) == 0 {
+			continue
+		}
+		bucket := Bucket(key, depth)
+		if t.buckets[bucket] == nil {
+			t.buckets[bucket] = make(map[string]Hash)
+		}
+		t.buckets[bucket][key] = Digest(key, versions)
+	}
+
+	leaves := make([]Hash, 1<<depth)
+	for i, digests := range t.buckets {
+		leaves[i] = hashBucket(digests)
+	}
+	t.levels[depth] = leaves
+	for level := depth - 1; level >= 0; level-- {
+		children := t.levels[level+1]
+		nodes := make([]Hash, 1<<level)
+		for i := range nodes {
+			nodes[i] = sha256.Sum256(append(children[2*i][:], children[2*i+1][:]...))
+		}
+		t.levels[level] = nodes
+	}
+	return t
+}
+
+// Depth returns the number of levels below the root
+func (t *Tree) Depth() int {
+	return t.depth
+}
+
+// Hash returns the hash of node index on level. Out of range nodes hash to zero.
+func (t *Tree) Hash(level int, index int) Hash {
+	if level < 0 || level > t.depth || index < 0 || index >= len(t.levels[level]) {
+		return Hash{}
+	}
+	return t.levels[level][index]
+}
+
+// Digests returns the digest of every key in a leaf
+func (t *Tree) Digests(bucket int) map[string]Hash {
+	if bucket < 0 || bucket >= len(t.buckets) {
+		return nil
+	}
+	return t.buckets[bucket]
+}
+
+// hashBucket hashes the digests of a leaf in key order. An empty leaf hashes to zero.
+func hashBucket(digests map[string]Hash) Hash {
+	if len(digests) == 0 {
+		return Hash{}
+	}
+	keys := make([]string, 0, len(digests))
+	for key := range digests {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		digest := digests[key]
+		h.Write(digest[:])
+	}
+	var sum Hash
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// Digest identifies a key and its siblings independently of their order
+func Digest(key string, versions []vclock.Version) Hash {
+	encoded := make([][]byte, len(versions))
+	for i, v := range versions {
+		encoded[i] = encodeVersion(v)
+	}
+	sort.Slice(encoded, func(a, b int) bool { return string(encoded[a]) < string(encoded[b]) })
+
+	h := sha256.New()
+	h.Write(appendString(nil, key))
+	for _, e := range encoded {
+		h.Write(e)
+	}
+	var sum Hash
+	copy(sum[:], h.Sum(nil))
+	return sum
+}
+
+// encodeVersion writes a version with its clock in actor order
+func encodeVersion(v vclock.Version) []byte {
+	var buf []byte
+	if v.Deleted {
+		buf = append(buf, 1)
+	} else {
+		buf = append(buf, 0)
+	}
+	buf = appendString(buf, v.Value)
+
+	actors := make([]string, 0, len(v.Clock))
+	for actor, count := range v.Clock {
+		if count > 0 {
+			actors = append(actors, actor)
+		}
+	}
+	sort.Strings(actors)
+	buf = binary.AppendUvarint(buf, uint64(len(actors)))
+	for _, actor := range actors {
+		buf = appendString(buf, actor)
+		buf = binary.AppendUvarint(buf, v.Clock[actor])
+	}
+	return buf
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+// Trees builds the tree of a store on demand and keeps it until the store is written
+type Trees struct {
+	store *kvstore.VersionedStore
+
+	mutex      sync.Mutex
+	tree       *Tree
+	generation uint64
+}
+
+// NewTrees creates a Trees over store
+func NewTrees(store *kvstore.VersionedStore) *Trees {
+	return &Trees{store: store}
+}
+
+// Tree returns the tree of the given depth for the current contents of the store
+func (t *Trees) Tree(depth int) *Tree {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.tree != nil && t.tree.depth == depth && t.generation == t.store.Generation() {
+		return t.tree
+	}
+	data, generation := t.store.Snapshot()
+	t.tree, t.generation = Build(data, depth), generation
+	return t.tree
+}
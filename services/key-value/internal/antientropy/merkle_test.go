@@ -0,0 +1,95 @@
+package antientropy
+
+import (
+	"fmt"
+	"testing"
+
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/shared/vclock"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testData(n int) map[string][]vclock.Version {
+	data := make(map[string][]vclock.Version, n)
+	for i := range n {
+		data[fmt.Sprintf("key-%d", i)] = []vclock.Version{{Value: fmt.Sprintf("v%d", i), Clock: vclock.Clock{"a": uint64(i + 1)}}}
+	}
+	return data
+}
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(data map[string][]vclock.Version)
+		equal  bool
+	}{
+		{name: "same data", change: func(map[string][]vclock.Version) {}, equal: true},
+		{name: "changed value", change: func(data map[string][]vclock.Version) {
+			data["key-7"] = []vclock.Version{{Value: "other", Clock: vclock.Clock{"a": 8}}}
+		}},
+		{name: "changed clock", change: func(data map[string][]vclock.Version) {
+			data["key-7"] = []vclock.Version{{Value: "v7", Clock: vclock.Clock{"b": 8}}}
+		}},
+		{name: "tombstone", change: func(data map[string][]vclock.Version) {
+			data["key-7"] = []vclock.Version{{Value: "v7", Deleted: true, Clock: vclock.Clock{"a": 8}}}
+		}},
+		{name: "extra key", change: func(data map[string][]vclock.Version) {
+			data["new"] = []vclock.Version{{Value: "v", Clock: vclock.Clock{"a": 1}}}
+		}},
+		{name: "missing key", change: func(data map[string][]vclock.Version) {
+			delete(data, "key-7")
+		}},
+	}
+
+	const depth = 6
+	base := Build(testData(100), depth)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := testData(100)
+			tt.change(data)
+			tree := Build(data, depth)
+
+			if tt.equal {
+				assert.Equal(t, base.Hash(0, 0), tree.Hash(0, 0))
+				return
+			}
+			assert.NotEqual(t, base.Hash(0, 0), tree.Hash(0, 0))
+
+			// Exactly one leaf differs
+			var differing []int
+			for i := range 1 << depth {
+				if base.Hash(depth, i) != tree.Hash(depth, i) {
+					differing = append(differing, i)
+				}
+			}
+			assert.Len(t, differing, 1)
+		})
+	}
+}
+
+func TestDigest_SiblingOrder(t *testing.T) {
+	a := vclock.Version{Value: "a", Clock: vclock.Clock{"x": 1}}
+	b := vclock.Version{Value: "b", Clock: vclock.Clock{"y": 1}}
+
+	assert.Equal(t, Digest("k", []vclock.Version{a, b}), Digest("k", []vclock.Version{b, a}))
+	assert.NotEqual(t, Digest("k", []vclock.Version{a, b}), Digest("other", []vclock.Version{a, b}))
+	// Zero counters are the same clock
+	assert.Equal(t, Digest("k", []vclock.Version{a}), Digest("k", []vclock.Version{{Value: "a", Clock: vclock.Clock{"x": 1, "z": 0}}}))
+}
+
+func TestTrees_RebuildsAfterWrites(t *testing.T) {
+	store := kvstore.NewVersionedStore()
+	trees := NewTrees(store)
+
+	empty := trees.Tree(4)
+	assert.Same(t, empty, trees.Tree(4))
+	assert.Equal(t, Build(nil, 4).Hash(0, 0), empty.Hash(0, 0))
+
+	store.Put("k", []vclock.Version{{Value: "v", Clock: vclock.Clock{"a": 1}}})
+	tree := trees.Tree(4)
+	assert.NotSame(t, empty, tree)
+	assert.NotEqual(t, empty.Hash(0, 0), tree.Hash(0, 0))
+	assert.Contains(t, tree.Digests(Bucket("k", 4)), "k")
+}
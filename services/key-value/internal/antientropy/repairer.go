@@ -0,0 +1,270 @@
+package antientropy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/shared/vclock/vclockpb"
+	"log"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// DefaultInterval is the time between two repair rounds
+const DefaultInterval = 30 * time.Second
+
+// exchangeBatch is the number of keys sent in one Exchange call
+const exchangeBatch = 500
+
+// Stats reports the work of a Repairer
+type Stats struct {
+	LastRepair   time.Time // end of the last round that reached every peer, zero if none did
+	KeysRepaired uint64    // keys found different and exchanged since the start
+	Rounds       uint64
+	LastError    string
+	Peers        []string
+}
+
+// Repairer periodically compares the versioned keys of this node with each peer and exchanges the
+// keys that differ. Versions merge by their vector clocks, so both sides end up with the same siblings.
+type Repairer struct {
+	store    *kvstore.VersionedStore
+	trees    *Trees
+	peers    []string
+	conns    []*grpc.ClientConn
+	clients  []keyvalue.AntiEntropyServiceClient
+	interval time.Duration
+	depth    int
+
+	dialOptions []grpc.DialOption
+
+	mutex sync.Mutex
+	stats Stats
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Option configures a Repairer
+type Option func(*Repairer)
+
+// WithInterval sets the time between two repair rounds, DefaultInterval by default
+func WithInterval(interval time.Duration) Option {
+	return func(r *Repairer) {
+		r.interval = interval
+	}
+}
+
+// WithDepth sets the depth of the Merkle trees, DefaultDepth by default. Peers must use the same depth.
+func WithDepth(depth int) Option {
+	return func(r *Repairer) {
+		r.depth = depth
+	}
+}
+
+// WithDialOptions sets the options used to connect to the peers. They default to plaintext.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(r *Repairer) {
+		r.dialOptions = opts
+	}
+}
+
+// NewRepairer starts repairing the keys of store against the peers, given as gRPC addresses
+func NewRepairer(store *kvstore.VersionedStore, trees *Trees, peers []string, opts ...Option) (*Repairer, error) {
+	r := &Repairer{
+		store:       store,
+		trees:       trees,
+		peers:       peers,
+		interval:    DefaultInterval,
+		depth:       DefaultDepth,
+		dialOptions: []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
+		done:        make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.depth < 0 || r.depth > MaxDepth {
+		return nil, fmt.Errorf("merkle tree depth %d is outside 0..%d", r.depth, MaxDepth)
+	}
+	r.stats.Peers = peers
+
+	for _, peer := range peers {
+		conn, err := grpc.NewClient(peer, r.dialOptions...)
+		if err != nil {
+			r.closeConns()
+			return nil, fmt.Errorf("failed to connect to peer %s: %w", peer, err)
+		}
+		r.conns = append(r.conns, conn)
+		r.clients = append(r.clients, keyvalue.NewAntiEntropyServiceClient(conn))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	go r.run(ctx)
+	return r, nil
+}
+
+// Stats returns the work done so far
+func (r *Repairer) Stats() Stats {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.stats
+}
+
+// Close stops the repairer and closes the connections to the peers
+func (r *Repairer) Close() error {
+	r.cancel()
+	<-r.done
+	return r.closeConns()
+}
+
+func (r *Repairer) closeConns() error {
+	var errs []error
+	for _, conn := range r.conns {
+		errs = append(errs, conn.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Repairer) run(ctx context.Context) {
+	defer close(r.done)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.Repair(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("anti-entropy: %v", err)
+			}
+		}
+	}
+}
+
+// Repair runs one round against every peer and returns the number of keys exchanged.
+// A failing peer does not stop the round; its error is returned once the others are done.
+func (r *Repairer) Repair(ctx context.Context) (int, error) {
+	repaired := 0
+	var errs []error
+	for i, client := range r.clients {
+		n, err := r.repairPeer(ctx, client)
+		repaired += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("peer %s: %w", r.peers[i], err))
+		}
+	}
+	err := errors.Join(errs...)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.stats.Rounds++
+	r.stats.KeysRepaired += uint64(repaired)
+	if err != nil {
+		r.stats.LastError = err.Error()
+	} else {
+		r.stats.LastRepair = time.Now()
+		r.stats.LastError = ""
+	}
+	return repaired, err
+}
+
+// repairPeer walks down the two trees along the differing nodes, compares the key digests of the
+// differing leaves and exchanges the keys that differ
+func (r *Repairer) repairPeer(ctx context.Context, client keyvalue.AntiEntropyServiceClient) (int, error) {
+	tree := r.trees.Tree(r.depth)
+	differing := []uint32{0}
+	for level := 0; level <= r.depth && len(differing) > 0; level++ {
+		if level > 0 {
+			differing = children(differing)
+		}
+		resp, err := client.MerkleHashes(ctx, &keyvalue.MerkleHashesRequest{Depth: uint32(r.depth), Level: uint32(level), Indexes: differing})
+		if err != nil {
+			return 0, err
+		}
+		if len(resp.Hashes) != len(differing) {
+			return 0, fmt.Errorf("peer returned %d hashes for %d nodes", len(resp.Hashes), len(differing))
+		}
+
+		var next []uint32
+		for i, index := range differing {
+			local := tree.Hash(level, int(index))
+			if !bytes.Equal(local[:], resp.Hashes[i]) {
+				next = append(next, index)
+			}
+		}
+		differing = next
+	}
+	if len(differing) == 0 {
+		return 0, nil
+	}
+
+	resp, err := client.BucketDigests(ctx, &keyvalue.BucketDigestsRequest{Depth: uint32(r.depth), Buckets: differing})
+	if err != nil {
+		return 0, err
+	}
+	keys := differingKeys(tree, differing, resp.Digests)
+
+	for start := 0; start < len(keys); start += exchangeBatch {
+		if err := r.exchange(ctx, client, keys[start:min(start+exchangeBatch, len(keys))]); err != nil {
+			return start, err
+		}
+	}
+	return len(keys), nil
+}
+
+// exchange sends the local versions of keys to the peer and merges back what the peer holds
+func (r *Repairer) exchange(ctx context.Context, client keyvalue.AntiEntropyServiceClient, keys []string) error {
+	req := &keyvalue.ExchangeRequest{}
+	for _, key := range keys {
+		req.Keys = append(req.Keys, &keyvalue.KeyVersions{Key: key, Versions: vclockpb.ToProto(r.store.Get(key))})
+	}
+
+	resp, err := client.Exchange(ctx, req)
+	if err != nil {
+		return err
+	}
+	for _, kv := range resp.Keys {
+		r.store.Put(kv.Key, vclockpb.FromProto(kv.Versions))
+	}
+	return nil
+}
+
+// children returns the indexes of the children of nodes on the next level
+func children(nodes []uint32) []uint32 {
+	out := make([]uint32, 0, 2*len(nodes))
+	for _, node := range nodes {
+		out = append(out, 2*node, 2*node+1)
+	}
+	return out
+}
+
+// differingKeys returns the keys of the buckets whose digests differ between the local tree and the peer
+func differingKeys(tree *Tree, buckets []uint32, remote []*keyvalue.KeyDigest) []string {
+	remoteDigests := make(map[string][]byte, len(remote))
+	for _, d := range remote {
+		remoteDigests[d.Key] = d.Digest
+	}
+
+	var keys []string
+	for _, bucket := range buckets {
+		for key, digest := range tree.Digests(int(bucket)) {
+			if theirs, ok := remoteDigests[key]; !ok || !bytes.Equal(digest[:], theirs) {
+				keys = append(keys, key)
+			}
+			delete(remoteDigests, key)
+		}
+	}
+	// Keys only the peer has
+	for key := range remoteDigests {
+		keys = append(keys, key)
+	}
+	return keys
+}
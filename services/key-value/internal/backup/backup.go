@@ -0,0 +1,162 @@
+// Package backup reads and writes backup files, a point in time copy of every key-value pair of the store.
+//
+// A file starts with a header holding the magic "KVBACKUP", the format version, the creation time and the number of
+// pairs. The pairs follow in key order, each a uvarint length and the bytes of the key, then of the value. The file
+// ends with the SHA-256 of everything before it, so truncated or damaged files are rejected before anything is
+// restored.
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Version is the format version written by Write. Read accepts this version only.
+const Version = 1
+
+// magic starts every backup file
+var magic = []byte("KVBACKUP")
+
+// ErrInvalid is wrapped by the errors of files that are not backups, are damaged or have an unsupported version
+var ErrInvalid = errors.New("invalid backup")
+
+// Backup is the content of a backup file
+type Backup struct {
+	Created time.Time
+	Data    map[string]string
+}
+
+// header follows the magic
+type header struct {
+	Version     uint32
+	CreatedNano int64
+	Pairs       uint64
+}
+
+// Write encodes a backup to w, writing the pairs in key order
+func Write(w io.Writer, b Backup) error {
+	sum := sha256.New()
+	out := bufio.NewWriter(io.MultiWriter(w, sum))
+
+	// Errors stick to out and are returned by Flush
+	out.Write(magic)
+	binary.Write(out, binary.BigEndian, header{Version: Version, CreatedNano: b.Created.UnixNano(), Pairs: uint64(len(b.Data))})
+
+	keys := make([]string, 0, len(b.Data))
+	for key := range b.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	length := make([]byte, binary.MaxVarintLen64)
+	for _, key := range keys {
+		for _, field := range []string{key, b.Data[key]} {
+			out.Write(length[:binary.PutUvarint(length, uint64(len(field)))])
+			out.WriteString(field)
+		}
+	}
+	if err := out.Flush(); err != nil {
+		return err
+	}
+	_, err := w.Write(sum.Sum(nil))
+	return err
+}
+
+// Read decodes a backup from r, checking its version and checksum. It fails with ErrInvalid when r holds anything
+// but one whole backup file.
+func Read(r io.Reader) (Backup, error) {
+	in := &hashingReader{r: bufio.NewReader(r), sum: sha256.New()}
+
+	start := make([]byte, len(magic))
+	if _, err := io.ReadFull(in, start); err != nil || !bytes.Equal(start, magic) {
+		return Backup{}, invalid(err, "not a backup file")
+	}
+	var h header
+	if err := binary.Read(in, binary.BigEndian, &h); err != nil {
+		return Backup{}, invalid(err, "truncated header")
+	}
+	if h.Version != Version {
+		return Backup{}, fmt.Errorf("%w: unsupported version %d, expected %d", ErrInvalid, h.Version, Version)
+	}
+
+	// The count comes from the file, so it only sizes the map up to a bound
+	data := make(map[string]string, min(h.Pairs, 1<<16))
+	for i := uint64(0); i < h.Pairs; i++ {
+		key, err := in.readField()
+		if err != nil {
+			return Backup{}, invalid(err, "truncated pair %d", i+1)
+		}
+		value, err := in.readField()
+		if err != nil {
+			return Backup{}, invalid(err, "truncated pair %d", i+1)
+		}
+		data[key] = value
+	}
+
+	want := in.sum.Sum(nil)
+	got := make([]byte, len(want))
+	if _, err := io.ReadFull(in.r, got); err != nil {
+		return Backup{}, invalid(err, "missing checksum")
+	}
+	if !bytes.Equal(got, want) {
+		return Backup{}, fmt.Errorf("%w: checksum mismatch", ErrInvalid)
+	}
+	if _, err := in.r.ReadByte(); !errors.Is(err, io.EOF) {
+		return Backup{}, invalid(err, "data after the checksum")
+	}
+	return Backup{Created: time.Unix(0, h.CreatedNano), Data: data}, nil
+}
+
+// invalid wraps a format problem in ErrInvalid, along with the read error behind it other than the end of the input
+func invalid(err error, format string, args ...any) error {
+	message := fmt.Sprintf(format, args...)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: %s: %w", ErrInvalid, message, err)
+	}
+	return fmt.Errorf("%w: %s", ErrInvalid, message)
+}
+
+// hashingReader hashes what it reads
+type hashingReader struct {
+	r   *bufio.Reader
+	sum hash.Hash
+}
+
+func (h *hashingReader) Read(p []byte) (int, error) {
+	n, err := h.r.Read(p)
+	h.sum.Write(p[:n])
+	return n, err
+}
+
+func (h *hashingReader) ReadByte() (byte, error) {
+	b, err := h.r.ReadByte()
+	if err == nil {
+		h.sum.Write([]byte{b})
+	}
+	return b, err
+}
+
+// readField reads a length prefixed string, growing it as the bytes arrive rather than trusting the length
+func (h *hashingReader) readField() (string, error) {
+	length, err := binary.ReadUvarint(h)
+	if err != nil {
+		return "", err
+	}
+	var field strings.Builder
+	n, err := io.CopyN(&field, h, int64(min(length, 1<<62)))
+	if err != nil {
+		return "", err
+	}
+	if uint64(n) != length {
+		return "", io.ErrUnexpectedEOF
+	}
+	return field.String(), nil
+}
@@ -0,0 +1,71 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encode writes a backup of data
+func encode(t *testing.T, data map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, Backup{Created: time.Unix(1700000000, 5), Data: data}))
+	return buf.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	for name, data := range map[string]map[string]string{
+		"empty":  {},
+		"pairs":  {"a": "1", "b": "", "binary\x00key": "\xff\xfe", "large": strings.Repeat("x", 100000)},
+		"single": {"only": "one"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			b, err := Read(bytes.NewReader(encode(t, data)))
+			require.NoError(t, err)
+			assert.Equal(t, data, b.Data)
+			assert.True(t, b.Created.Equal(time.Unix(1700000000, 5)))
+		})
+	}
+}
+
+func TestWrite_KeyOrder(t *testing.T) {
+	file := encode(t, map[string]string{"b": "2", "a": "1"})
+	assert.Less(t, bytes.Index(file, []byte("a")), bytes.Index(file, []byte("b")))
+}
+
+func TestRead_Invalid(t *testing.T) {
+	file := encode(t, map[string]string{"a": "1", "b": "2"})
+	damaged := bytes.Clone(file)
+	damaged[len(damaged)-sha256.Size-1] ^= 0xff
+	future := bytes.Clone(file)
+	binary.BigEndian.PutUint32(future[len(magic):], Version+1)
+
+	tests := []struct {
+		name  string
+		input []byte
+		err   string
+	}{
+		{"empty", nil, "invalid backup: not a backup file"},
+		{"other file", []byte("key,value\na,1\n"), "invalid backup: not a backup file"},
+		{"truncated header", file[:len(magic)+3], "invalid backup: truncated header"},
+		{"truncated pair", file[:len(file)-36], "invalid backup: truncated pair 2"},
+		{"missing checksum", file[:len(file)-10], "invalid backup: missing checksum"},
+		{"damaged", damaged, "invalid backup: checksum mismatch"},
+		{"trailing data", append(bytes.Clone(file), 0), "invalid backup: data after the checksum"},
+		{"future version", future, "invalid backup: unsupported version 2, expected 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(bytes.NewReader(tt.input))
+			assert.ErrorIs(t, err, ErrInvalid)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
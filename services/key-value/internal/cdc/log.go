@@ -0,0 +1,346 @@
+// Package cdc captures every change made to the key-value store in a numbered, bounded log that downstream
+// consumers stream from, and keeps the position each consumer group has processed.
+package cdc
+
+import (
+	"errors"
+	"fmt"
+	"key-value/services/key-value/internal/kvstore"
+	"maps"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reserved keys holding the state of the log in the wrapped store
+const (
+	seqKey       = kvstore.ReservedPrefix + "cdc/seq"     // number of the latest change
+	offsetPrefix = kvstore.ReservedPrefix + "cdc/offset/" // followed by a consumer group, holds its offset
+)
+
+// Op is the kind of change
+type Op string
+
+// Change operations. Increments are captured as a set of the resulting value.
+const (
+	OpSet    Op = "set"
+	OpDelete Op = "delete"
+)
+
+// DefaultRetention is the number of changes retained when no limit is given
+const DefaultRetention = 100000
+
+// maxBatch bounds the number of entries returned by a single read of the log
+const maxBatch = 1024
+
+var (
+	// ErrExpired is returned when the requested changes fell out of the retention window
+	ErrExpired = errors.New("changes are no longer retained")
+	// ErrInvalidOffset is returned when committing a position the log has not reached
+	ErrInvalidOffset = errors.New("offset is beyond the latest change")
+)
+
+// Entry is a single change, numbered from 1 in the order it was applied
+type Entry struct {
+	Seq   uint64
+	Op    Op
+	Key   string
+	Value string
+	Time  time.Time
+}
+
+// Log wraps a store and captures its changes. It implements kvstore.Storer and passes the optional
+// store interfaces through, so it can sit under Raft or replication and capture what they apply.
+// Restoring a snapshot replaces the data without capturing it.
+//
+// The entries are kept in memory, but the number of the latest change and the offsets of the consumer groups are
+// kept in reserved keys of the wrapped store. Raft or replication above the log persist and replicate them with the
+// data, so every node numbers the changes alike and a restarted node continues the numbering rather than reusing
+// numbers consumers committed already.
+type Log struct {
+	kvstore.Layer
+	mutex     sync.Mutex // orders changes of the store with their entries
+	entries   []Entry
+	lastSeq   uint64
+	retention int
+	maxAge    time.Duration
+	notify    chan struct{} // closed and replaced on every change
+	now       func() time.Time
+}
+
+// Option configures a Log
+type Option func(*Log)
+
+// WithRetention sets the number of changes retained at least, DefaultRetention by default
+func WithRetention(entries int) Option {
+	return func(l *Log) {
+		l.retention = entries
+	}
+}
+
+// WithMaxAge drops changes older than age even when the log holds fewer than the retained number. Zero keeps them.
+func WithMaxAge(age time.Duration) Option {
+	return func(l *Log) {
+		l.maxAge = age
+	}
+}
+
+// NewLog starts capturing the changes made through the returned store, numbering them after the latest change
+// recorded in it
+func NewLog(store kvstore.Storer, opts ...Option) (*Log, error) {
+	l := &Log{
+		Layer:     kvstore.Layer{Next: store},
+		retention: DefaultRetention,
+		notify:    make(chan struct{}),
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.retention <= 0 {
+		l.retention = DefaultRetention
+	}
+	seq, err := readSeq(store.Get(seqKey))
+	if err != nil {
+		return nil, err
+	}
+	l.lastSeq = seq
+	return l, nil
+}
+
+// Restore replaces the data of the store without capturing a change. A snapshot taken on a node further ahead
+// moves the numbering on, dropping the retained changes, which can no longer be numbered alike. Data holding no
+// state of the log, like a backup, keeps the offsets of the consumer groups.
+func (l *Log) Restore(data map[string]string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	stored, snapshot := data[seqKey]
+	seq, err := readSeq(stored, nil)
+	if err != nil {
+		return err
+	}
+	if seq > l.lastSeq {
+		l.entries = nil
+		l.lastSeq = seq
+	}
+
+	data = maps.Clone(data)
+	data[seqKey] = strconv.FormatUint(l.lastSeq, 10)
+	if !snapshot {
+		current, err := l.Layer.Snapshot()
+		if err != nil {
+			return err
+		}
+		for key, value := range current {
+			if strings.HasPrefix(key, offsetPrefix) {
+				data[key] = value
+			}
+		}
+	}
+	return l.Layer.Restore(data)
+}
+
+// Set stores a key-value pair and captures it
+func (l *Log) Set(key string, value string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if err := l.reserve(key); err != nil {
+		return err
+	}
+	if err := l.Next.Set(key, value); err != nil {
+		return err
+	}
+	l.append(OpSet, key, value)
+	return nil
+}
+
+// Delete removes a key and captures it. Deleting a missing key changes nothing and is not captured.
+func (l *Log) Delete(key string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	_, err := l.Next.Get(key)
+	if errors.Is(err, kvstore.ErrNotFound) {
+		return l.Next.Delete(key)
+	}
+	if err := l.reserve(key); err != nil {
+		return err
+	}
+	if err := l.Next.Delete(key); err != nil {
+		return err
+	}
+	l.append(OpDelete, key, "")
+	return nil
+}
+
+// Increment adds delta to a key and captures the resulting value
+func (l *Log) Increment(key string, delta int64) (int64, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if err := l.reserve(key); err != nil {
+		return 0, err
+	}
+	value, err := l.Next.Increment(key, delta)
+	if err != nil {
+		return 0, err
+	}
+	l.append(OpSet, key, strconv.FormatInt(value, 10))
+	return value, nil
+}
+
+// SetIfAbsent stores a key-value pair only if the key does not exist, capturing it when it does
+func (l *Log) SetIfAbsent(key string, value string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if err := l.reserve(key); err != nil {
+		return err
+	}
+	if err := l.Layer.SetIfAbsent(key, value); err != nil {
+		return err
+	}
+	l.append(OpSet, key, value)
+	return nil
+}
+
+// DeleteIfValue removes a key only if it holds value, capturing it when it does
+func (l *Log) DeleteIfValue(key string, value string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if err := l.reserve(key); err != nil {
+		return err
+	}
+	if err := l.Layer.DeleteIfValue(key, value); err != nil {
+		return err
+	}
+	l.append(OpDelete, key, "")
+	return nil
+}
+
+// reserve records the number of the next change before the change of key is made, so a crash in between skips a
+// number rather than reusing one. Changes of reserved keys are not captured and need none. The caller holds the
+// mutex.
+func (l *Log) reserve(key string) error {
+	if kvstore.IsReserved(key) {
+		return nil
+	}
+	return l.Next.Set(seqKey, strconv.FormatUint(l.lastSeq+1, 10))
+}
+
+// append records a change and wakes up readers waiting for it, leaving out the reserved keys the service keeps its
+// own state in. The caller holds the mutex.
+func (l *Log) append(op Op, key string, value string) {
+	if kvstore.IsReserved(key) {
+		return
+	}
+	l.lastSeq++
+	l.entries = append(l.entries, Entry{Seq: l.lastSeq, Op: op, Key: key, Value: value, Time: l.now()})
+
+	// Trim in bulk so appends stay amortized O(1)
+	if len(l.entries) >= 2*l.retention {
+		l.entries = append([]Entry(nil), l.entries[len(l.entries)-l.retention:]...)
+	}
+	l.expire()
+
+	close(l.notify)
+	l.notify = make(chan struct{})
+}
+
+// expire drops the entries older than the maximum age. The caller holds the mutex.
+func (l *Log) expire() {
+	if l.maxAge <= 0 || len(l.entries) == 0 {
+		return
+	}
+	cutoff := l.now().Add(-l.maxAge)
+	n := sort.Search(len(l.entries), func(i int) bool { return l.entries[i].Time.After(cutoff) })
+	if n > 0 {
+		l.entries = append([]Entry(nil), l.entries[n:]...)
+	}
+}
+
+// firstSeq returns the sequence number of the oldest retained entry. The caller holds the mutex.
+func (l *Log) firstSeq() uint64 {
+	return l.lastSeq - uint64(len(l.entries)) + 1
+}
+
+// Changes returns the changes starting at fromSeq and a channel closed when more are captured.
+// A fromSeq of 0 starts at the oldest retained change. It returns ErrExpired when fromSeq is no longer retained, and
+// also when it lies past the change following the latest one, like the offset of a group on a node that lost its data.
+func (l *Log) Changes(fromSeq uint64) ([]Entry, <-chan struct{}, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.expire()
+	first := l.firstSeq()
+	if fromSeq == 0 {
+		fromSeq = first
+	}
+	if fromSeq > l.lastSeq+1 {
+		return nil, l.notify, fmt.Errorf("%w: change %d is past the latest change %d", ErrExpired, fromSeq, l.lastSeq)
+	}
+	if fromSeq > l.lastSeq {
+		return nil, l.notify, nil
+	}
+	if fromSeq < first {
+		return nil, l.notify, fmt.Errorf("%w: change %d, oldest retained is %d", ErrExpired, fromSeq, first)
+	}
+
+	start := len(l.entries) - int(l.lastSeq-fromSeq) - 1
+	end := min(len(l.entries), start+maxBatch)
+	return append([]Entry(nil), l.entries[start:end]...), l.notify, nil
+}
+
+// Bounds returns the sequence numbers of the oldest retained and the latest change.
+// When nothing is retained first is one past last.
+func (l *Log) Bounds() (first uint64, last uint64) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.expire()
+	return l.firstSeq(), l.lastSeq
+}
+
+// Commit records that group processed every change up to and including seq. The offset is written through store,
+// the top of the chain of stores the log sits in, so Raft or replication persist it and carry it to the other nodes.
+func (l *Log) Commit(store kvstore.Storer, group string, seq uint64) error {
+	// Leave the mutex before writing, which comes back down through the log
+	l.mutex.Lock()
+	last := l.lastSeq
+	l.mutex.Unlock()
+	if seq > last {
+		return fmt.Errorf("%w: %d, latest is %d", ErrInvalidOffset, seq, last)
+	}
+	return store.Set(offsetPrefix+group, strconv.FormatUint(seq, 10))
+}
+
+// Offset returns the last change group committed, and whether it committed any
+func (l *Log) Offset(group string) (uint64, bool, error) {
+	stored, err := l.Next.Get(offsetPrefix + group)
+	if errors.Is(err, kvstore.ErrNotFound) {
+		return 0, false, nil
+	}
+	seq, err := readSeq(stored, err)
+	if err != nil {
+		return 0, false, err
+	}
+	return seq, true, nil
+}
+
+// readSeq parses a change number kept in a reserved key, 0 when the key does not exist
+func readSeq(stored string, err error) (uint64, error) {
+	if errors.Is(err, kvstore.ErrNotFound) || (err == nil && stored == "") {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	seq, err := strconv.ParseUint(stored, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid change number %q: %w", stored, err)
+	}
+	return seq, nil
+}
@@ -0,0 +1,199 @@
+package cdc
+
+import (
+	"testing"
+	"time"
+
+	"key-value/services/key-value/internal/kvstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withoutTime clears the capture time so entries can be compared
+func withoutTime(entries []Entry) []Entry {
+	for i := range entries {
+		entries[i].Time = time.Time{}
+	}
+	return entries
+}
+
+// newLog starts capturing the changes of store
+func newLog(t *testing.T, store kvstore.Storer, opts ...Option) *Log {
+	t.Helper()
+	log, err := NewLog(store, opts...)
+	require.NoError(t, err)
+	return log
+}
+
+func TestLog_CapturesChanges(t *testing.T) {
+	log := newLog(t, kvstore.NewInMemoryStore())
+
+	require.NoError(t, log.Set("a", "1"))
+	_, err := log.Increment("counter", 5)
+	require.NoError(t, err)
+	require.NoError(t, log.Delete("a"))
+	require.NoError(t, log.Delete("missing"))
+	require.NoError(t, log.SetIfAbsent("b", "2"))
+	assert.ErrorIs(t, log.SetIfAbsent("b", "3"), kvstore.ErrConflict)
+	require.NoError(t, log.DeleteIfValue("b", "2"))
+
+	// Failed changes are not captured
+	require.NoError(t, log.Set("text", "abc"))
+	_, err = log.Increment("text", 1)
+	assert.ErrorIs(t, err, kvstore.ErrNotNumeric)
+
+	entries, _, err := log.Changes(0)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Seq: 1, Op: OpSet, Key: "a", Value: "1"},
+		{Seq: 2, Op: OpSet, Key: "counter", Value: "5"},
+		{Seq: 3, Op: OpDelete, Key: "a"},
+		{Seq: 4, Op: OpSet, Key: "b", Value: "2"},
+		{Seq: 5, Op: OpDelete, Key: "b"},
+		{Seq: 6, Op: OpSet, Key: "text", Value: "abc"},
+	}, withoutTime(entries))
+
+	entries, _, err = log.Changes(6)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	entries, _, err = log.Changes(7)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestLog_NotifiesOnChange(t *testing.T) {
+	log := newLog(t, kvstore.NewInMemoryStore())
+
+	_, notify, err := log.Changes(1)
+	require.NoError(t, err)
+
+	require.NoError(t, log.Set("a", "1"))
+	select {
+	case <-notify:
+	default:
+		t.Fatal("expected notification after a change")
+	}
+}
+
+func TestLog_Retention(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		advance   time.Duration // clock advance between changes
+		wantFirst uint64
+	}{
+		// The log trims in bulk, so it may hold up to twice the retention
+		{name: "by count", opts: []Option{WithRetention(3)}, wantFirst: 7},
+		{name: "by age", opts: []Option{WithMaxAge(35 * time.Second)}, advance: 10 * time.Second, wantFirst: 8},
+		{name: "unbounded", wantFirst: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log := newLog(t, kvstore.NewInMemoryStore(), tt.opts...)
+			now := time.Unix(1000, 0)
+			log.now = func() time.Time { return now }
+
+			for range 10 {
+				require.NoError(t, log.Set("k", "v"))
+				now = now.Add(tt.advance)
+			}
+
+			first, last := log.Bounds()
+			assert.Equal(t, tt.wantFirst, first)
+			assert.Equal(t, uint64(10), last)
+
+			entries, _, err := log.Changes(0)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantFirst, entries[0].Seq)
+
+			if tt.wantFirst > 1 {
+				_, _, err = log.Changes(tt.wantFirst - 1)
+				assert.ErrorIs(t, err, ErrExpired)
+			}
+		})
+	}
+}
+
+func TestLog_Offsets(t *testing.T) {
+	log := newLog(t, kvstore.NewInMemoryStore())
+	require.NoError(t, log.Set("a", "1"))
+	require.NoError(t, log.Set("b", "2"))
+
+	_, ok, err := log.Offset("indexer")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, log.Commit(log, "indexer", 2))
+	seq, ok, err := log.Offset("indexer")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), seq)
+
+	assert.ErrorIs(t, log.Commit(log, "indexer", 3), ErrInvalidOffset)
+
+	// Committing is not a change
+	_, last := log.Bounds()
+	assert.Equal(t, uint64(2), last)
+}
+
+func TestLog_KeepsStateInTheWrappedStore(t *testing.T) {
+	base := kvstore.NewInMemoryStore()
+	log := newLog(t, base)
+	for range 3 {
+		require.NoError(t, log.Set("a", "1"))
+	}
+	require.NoError(t, log.Commit(log, "indexer", 2))
+
+	// A new log over the same data, like after a restart, continues the numbering and keeps the offsets
+	log = newLog(t, base)
+	first, last := log.Bounds()
+	assert.Equal(t, uint64(4), first)
+	assert.Equal(t, uint64(3), last)
+	seq, ok, err := log.Offset("indexer")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), seq)
+	_, _, err = log.Changes(seq + 1)
+	assert.ErrorIs(t, err, ErrExpired, "changes lost with the restart are no longer retained")
+
+	require.NoError(t, log.Set("b", "2"))
+	entries, _, err := log.Changes(4)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{{Seq: 4, Op: OpSet, Key: "b", Value: "2"}}, withoutTime(entries))
+
+	// An offset past the log, like one committed before a node lost its data, is not waited for
+	_, _, err = newLog(t, kvstore.NewInMemoryStore()).Changes(seq + 1)
+	assert.ErrorIs(t, err, ErrExpired)
+}
+
+func TestLog_Restore(t *testing.T) {
+	log := newLog(t, kvstore.NewInMemoryStore())
+	require.NoError(t, log.Set("a", "1"))
+	require.NoError(t, log.Commit(log, "indexer", 1))
+
+	// Restoring a backup keeps the numbering and the offsets
+	require.NoError(t, log.Restore(map[string]string{"b": "2"}))
+	_, last := log.Bounds()
+	assert.Equal(t, uint64(1), last)
+	seq, ok, err := log.Offset("indexer")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), seq)
+
+	// Restoring a snapshot of a node further ahead moves the numbering on
+	ahead := newLog(t, kvstore.NewInMemoryStore())
+	for range 5 {
+		require.NoError(t, ahead.Set("c", "3"))
+	}
+	snapshot, err := ahead.Snapshot()
+	require.NoError(t, err)
+	require.NoError(t, log.Restore(snapshot))
+	first, last := log.Bounds()
+	assert.Equal(t, uint64(6), first)
+	assert.Equal(t, uint64(5), last)
+	_, ok, err = log.Offset("indexer")
+	require.NoError(t, err)
+	assert.False(t, ok, "the snapshot holds the offsets of its node")
+}
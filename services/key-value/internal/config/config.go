@@ -1,16 +1,110 @@
 package config
 
 import (
+	"key-value/shared/limits"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	APIKey      string `env:"API_KEY"`
-	Port        string `env:"PORT"`
-	Environment string `env:"ENVIRONMENT"`
+	APIKey      string        `env:"API_KEY"`
+	Port        string        `env:"PORT"`
+	Environment string        `env:"ENVIRONMENT"`
+	Limits      limits.Limits // MAX_KEY_LENGTH, MAX_VALUE_SIZE and KEY_PATTERN
+	Store       StoreConfig
+	Raft        RaftConfig
+	Encryption  EncryptionConfig
+	Replication ReplicationConfig
+	AntiEntropy AntiEntropyConfig
+	Gossip      GossipConfig
+	CDC         CDCConfig
+	History     HistoryConfig
+	Lease       LeaseConfig
+	RESP        RESPConfig
+	Memcache    MemcacheConfig
+	MetricsAddr string `env:"METRICS_ADDR"` // HTTP address serving expvar metrics on /debug/vars, disabled when empty
+}
+
+// StoreConfig selects the storage backend and holds the options of the backends
+type StoreConfig struct {
+	Backend string `env:"STORE_BACKEND"` // Registered backend name, memory by default
+	Shards  int    `env:"STORE_SHARDS"`  // Shards of the sharded backend, 0 uses its default
+	Dir     string `env:"STORE_DIR"`     // Directory of the file backend
+}
+
+// RaftConfig enables Raft replication when NodeID is set
+type RaftConfig struct {
+	NodeID          string `env:"RAFT_NODE_ID"`
+	Addr            string `env:"RAFT_ADDR"`             // Raft transport bind address
+	AdvertiseAddr   string `env:"RAFT_ADVERTISE_ADDR"`   // Raft address given to peers, defaults to RAFT_ADDR
+	GRPCAddr        string `env:"GRPC_ADVERTISE_ADDR"`   // gRPC address given to peers, defaults to localhost:PORT
+	Bootstrap       bool   `env:"RAFT_BOOTSTRAP"`        // Start a new cluster with this node
+	Join            string `env:"RAFT_JOIN"`             // gRPC address of an existing member to join through
+	ReadConsistency string `env:"RAFT_READ_CONSISTENCY"` // linearizable (default) or stale
+	ForwardRequests bool   `env:"RAFT_FORWARD_REQUESTS"` // Followers forward to the leader instead of redirecting, default true
+	DataDir         string `env:"RAFT_DATA_DIR"`         // Keeps the Raft log and snapshots on disk, encrypted, when set
+}
+
+// EncryptionConfig holds the master keys encrypting data written to disk
+type EncryptionConfig struct {
+	KeyFile          string   `env:"ENCRYPTION_KEY_FILE"`           // Master key wrapping the data key, required to write data to disk
+	PreviousKeyFiles []string `env:"ENCRYPTION_PREVIOUS_KEY_FILES"` // Comma separated earlier master keys, re-wrapped to KeyFile on start
+}
+
+// ReplicationConfig enables asynchronous primary/follower replication when Role is set
+type ReplicationConfig struct {
+	Role        string `env:"REPLICATION_ROLE"`         // primary or follower
+	PrimaryAddr string `env:"REPLICATION_PRIMARY_ADDR"` // gRPC address of the primary, required on followers
+	LogSize     int    `env:"REPLICATION_LOG_SIZE"`     // Mutations retained by the primary for followers catching up
+}
+
+// AntiEntropyConfig enables Merkle tree repair of the versioned keys when Peers is set
+type AntiEntropyConfig struct {
+	Peers    []string      `env:"ANTI_ENTROPY_PEERS"`    // Comma separated gRPC addresses of the other replicas
+	Interval time.Duration `env:"ANTI_ENTROPY_INTERVAL"` // Time between repair rounds
+}
+
+// GossipConfig enables gossip membership when NodeID is set
+type GossipConfig struct {
+	NodeID           string        `env:"GOSSIP_NODE_ID"`
+	AdvertiseAddr    string        `env:"GOSSIP_ADVERTISE_ADDR"`    // gRPC address given to other members, defaults to GRPC_ADVERTISE_ADDR
+	Seeds            []string      `env:"GOSSIP_SEEDS"`             // Comma separated gRPC addresses of members to join through
+	ProbeInterval    time.Duration `env:"GOSSIP_PROBE_INTERVAL"`    // Protocol period, one member is probed per period
+	SuspicionTimeout time.Duration `env:"GOSSIP_SUSPICION_TIMEOUT"` // Time a suspect member has to refute before it is declared dead
+}
+
+// CDCConfig enables change data capture when Enabled is set
+type CDCConfig struct {
+	Enabled   bool          `env:"CDC_ENABLED"`
+	Retention int           `env:"CDC_RETENTION"` // Changes retained for consumers, 0 uses the default
+	MaxAge    time.Duration `env:"CDC_MAX_AGE"`   // Changes older than this are dropped, 0 keeps them
+}
+
+// HistoryConfig keeps earlier revisions of keys when Enabled is set
+type HistoryConfig struct {
+	Enabled      bool          `env:"HISTORY_ENABLED"`
+	MaxRevisions int           `env:"HISTORY_MAX_REVISIONS"` // Revisions kept per key, 0 uses the default
+	MaxAge       time.Duration `env:"HISTORY_MAX_AGE"`       // Revisions replaced longer ago are dropped, 0 keeps them
+}
+
+// RESPConfig enables the Redis protocol listener when Addr is set
+type RESPConfig struct {
+	Addr string `env:"RESP_ADDR"` // TCP address serving RESP, for example :6379
+}
+
+// MemcacheConfig enables the memcached text protocol listener when Addr is set
+type MemcacheConfig struct {
+	Addr string `env:"MEMCACHE_ADDR"` // TCP address serving the memcached text protocol, for example :11211
+}
+
+// LeaseConfig configures the expiry of leases
+type LeaseConfig struct {
+	CheckInterval time.Duration `env:"LEASE_CHECK_INTERVAL"` // How often expired leases are looked for, 0 uses the default
 }
 
 func Load() *Config {
@@ -19,9 +113,119 @@ func Load() *Config {
 		log.Printf("Not loading .env file")
 	}
 
+	port := os.Getenv("PORT")
+
+	raftAddr := os.Getenv("RAFT_ADDR")
+	if raftAddr == "" {
+		raftAddr = "127.0.0.1:7000"
+	}
+	grpcAddr := os.Getenv("GRPC_ADVERTISE_ADDR")
+	if grpcAddr == "" {
+		grpcAddr = "localhost:" + port
+	}
+	gossipAddr := os.Getenv("GOSSIP_ADVERTISE_ADDR")
+	if gossipAddr == "" {
+		gossipAddr = grpcAddr
+	}
+
 	return &Config{
 		APIKey:      os.Getenv("API_KEY"),
-		Port:        os.Getenv("PORT"),
+		Port:        port,
 		Environment: os.Getenv("ENVIRONMENT"),
+		Limits:      limits.Load(),
+		Store: StoreConfig{
+			Backend: os.Getenv("STORE_BACKEND"),
+			Shards:  envInt("STORE_SHARDS", 0),
+			Dir:     os.Getenv("STORE_DIR"),
+		},
+		Raft: RaftConfig{
+			NodeID:          os.Getenv("RAFT_NODE_ID"),
+			Addr:            raftAddr,
+			AdvertiseAddr:   os.Getenv("RAFT_ADVERTISE_ADDR"),
+			GRPCAddr:        grpcAddr,
+			Bootstrap:       envBool("RAFT_BOOTSTRAP", false),
+			Join:            os.Getenv("RAFT_JOIN"),
+			ReadConsistency: os.Getenv("RAFT_READ_CONSISTENCY"),
+			ForwardRequests: envBool("RAFT_FORWARD_REQUESTS", true),
+			DataDir:         os.Getenv("RAFT_DATA_DIR"),
+		},
+		Encryption: EncryptionConfig{
+			KeyFile:          os.Getenv("ENCRYPTION_KEY_FILE"),
+			PreviousKeyFiles: envList("ENCRYPTION_PREVIOUS_KEY_FILES"),
+		},
+		Replication: ReplicationConfig{
+			Role:        os.Getenv("REPLICATION_ROLE"),
+			PrimaryAddr: os.Getenv("REPLICATION_PRIMARY_ADDR"),
+			LogSize:     envInt("REPLICATION_LOG_SIZE", 0),
+		},
+		AntiEntropy: AntiEntropyConfig{
+			Peers:    envList("ANTI_ENTROPY_PEERS"),
+			Interval: envDuration("ANTI_ENTROPY_INTERVAL", 30*time.Second),
+		},
+		Gossip: GossipConfig{
+			NodeID:           os.Getenv("GOSSIP_NODE_ID"),
+			AdvertiseAddr:    gossipAddr,
+			Seeds:            envList("GOSSIP_SEEDS"),
+			ProbeInterval:    envDuration("GOSSIP_PROBE_INTERVAL", time.Second),
+			SuspicionTimeout: envDuration("GOSSIP_SUSPICION_TIMEOUT", 5*time.Second),
+		},
+		CDC: CDCConfig{
+			Enabled:   envBool("CDC_ENABLED", false),
+			Retention: envInt("CDC_RETENTION", 0),
+			MaxAge:    envDuration("CDC_MAX_AGE", 0),
+		},
+		History: HistoryConfig{
+			Enabled:      envBool("HISTORY_ENABLED", false),
+			MaxRevisions: envInt("HISTORY_MAX_REVISIONS", 0),
+			MaxAge:       envDuration("HISTORY_MAX_AGE", 0),
+		},
+		Lease: LeaseConfig{
+			CheckInterval: envDuration("LEASE_CHECK_INTERVAL", 0),
+		},
+		RESP: RESPConfig{
+			Addr: os.Getenv("RESP_ADDR"),
+		},
+		Memcache: MemcacheConfig{
+			Addr: os.Getenv("MEMCACHE_ADDR"),
+		},
+		MetricsAddr: os.Getenv("METRICS_ADDR"),
+	}
+}
+
+// envBool reads a boolean from the environment, returning def when unset or invalid
+func envBool(name string, def bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(name))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// envInt reads an integer from the environment, returning def when unset or invalid
+func envInt(name string, def int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// envDuration reads a duration such as 30s from the environment, returning def when unset or invalid
+func envDuration(name string, def time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(name))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// envList reads a comma separated list from the environment, skipping empty entries
+func envList(name string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
 	}
+	return values
 }
@@ -0,0 +1,231 @@
+// Package encryption seals data at rest with AES-256-GCM.
+//
+// Data is sealed with a random data key. The data key is stored next to the data, wrapped (sealed) by a master key
+// that is loaded from a local key file and never written anywhere, so everything works offline. Rotating the master
+// key re-wraps the data key under the new one; the data itself is not rewritten.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// KeySize is the size of master and data keys in bytes, selecting AES-256
+const KeySize = 32
+
+// keyFileVersion is the format version of data key files
+const keyFileVersion = 1
+
+// ErrTampered is wrapped by the errors of sealed data that fails authentication: it was modified, truncated,
+// moved or sealed with another key
+var ErrTampered = errors.New("ciphertext was tampered with or sealed with another key")
+
+// MasterKey wraps data keys. It is identified by a fingerprint that is safe to store.
+type MasterKey struct {
+	ID  string
+	key []byte
+}
+
+// LoadMasterKey reads a master key file holding the 64 hex digits of a 32-byte key, as written by
+// `openssl rand -hex 32`. The file must not be readable by other users.
+func LoadMasterKey(path string) (*MasterKey, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read master key: %w", err)
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		return nil, fmt.Errorf("master key file %s is accessible by other users (mode %v), expected 0600", path, info.Mode().Perm())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read master key: %w", err)
+	}
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != KeySize {
+		return nil, fmt.Errorf("master key file %s must hold %d hex digits", path, 2*KeySize)
+	}
+	return NewMasterKey(key)
+}
+
+// NewMasterKey uses a 32-byte key as master key
+func NewMasterKey(key []byte) (*MasterKey, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("master key must be %d bytes, got %d", KeySize, len(key))
+	}
+	sum := sha256.Sum256(key)
+	return &MasterKey{ID: hex.EncodeToString(sum[:8]), key: key}, nil
+}
+
+// Cipher seals and opens data with a data key
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher creates a cipher from a 32-byte data key
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("data key must be %d bytes, got %d", KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// Overhead is the number of bytes Seal adds to the plaintext
+func (c *Cipher) Overhead() int {
+	return c.aead.NonceSize() + c.aead.Overhead()
+}
+
+// Seal encrypts and authenticates plaintext under a random nonce, binding it to additionalData, which is
+// authenticated but not stored: Open must be given the same.
+func (c *Cipher) Seal(plaintext, additionalData []byte) []byte {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(fmt.Sprintf("failed to read random nonce: %v", err))
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, additionalData)
+}
+
+// Open decrypts what Seal returned, failing with ErrTampered unless it is intact and was sealed with the same
+// key and additional data
+func (c *Cipher) Open(sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < c.Overhead() {
+		return nil, ErrTampered
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, ErrTampered
+	}
+	return plaintext, nil
+}
+
+// keyFile is the content of a data key file
+type keyFile struct {
+	Version     int    `json:"version"`
+	MasterKeyID string `json:"master_key_id"`
+	WrappedKey  []byte `json:"wrapped_key"`
+}
+
+// OpenDataKey returns the cipher of the data key stored at path, unwrapping it with master. A missing file is
+// created with a new random data key. A data key wrapped by one of the previous master keys is re-wrapped by
+// master, which rotates the master key.
+func OpenDataKey(path string, master *MasterKey, previous ...*MasterKey) (*Cipher, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return createDataKey(path, master)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data key: %w", err)
+	}
+	var file keyFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid data key file %s: %w", path, err)
+	}
+	if file.Version != keyFileVersion {
+		return nil, fmt.Errorf("unsupported data key file version %d, expected %d", file.Version, keyFileVersion)
+	}
+
+	wrapping := master
+	for _, key := range previous {
+		if key.ID == file.MasterKeyID {
+			wrapping = key
+		}
+	}
+	if wrapping.ID != file.MasterKeyID {
+		return nil, fmt.Errorf("data key %s is wrapped by master key %s, which was not given", path, file.MasterKeyID)
+	}
+	key, err := unwrap(wrapping, file.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key %s: %w", path, err)
+	}
+	if wrapping != master {
+		if err := writeDataKey(path, master, key); err != nil {
+			return nil, fmt.Errorf("failed to rotate master key: %w", err)
+		}
+	}
+	return NewCipher(key)
+}
+
+// Rewrap re-wraps the data key stored at path from one master key to another
+func Rewrap(path string, from, to *MasterKey) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to read data key: %w", err)
+	}
+	_, err := OpenDataKey(path, to, from)
+	return err
+}
+
+// createDataKey stores a new random data key at path
+func createDataKey(path string, master *MasterKey) (*Cipher, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	if err := writeDataKey(path, master, key); err != nil {
+		return nil, fmt.Errorf("failed to write data key: %w", err)
+	}
+	return NewCipher(key)
+}
+
+// writeDataKey replaces the file at path with key wrapped by master, so a crash leaves either file whole
+func writeDataKey(path string, master *MasterKey, key []byte) error {
+	wrapper, err := NewCipher(master.key)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(keyFile{
+		Version:     keyFileVersion,
+		MasterKeyID: master.ID,
+		WrappedKey:  wrapper.Seal(key, []byte(master.ID)),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	temp := path + ".tmp"
+	file, err := os.OpenFile(temp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp, path)
+}
+
+// unwrap opens a data key wrapped by master
+func unwrap(master *MasterKey, wrapped []byte) ([]byte, error) {
+	wrapper, err := NewCipher(master.key)
+	if err != nil {
+		return nil, err
+	}
+	return wrapper.Open(wrapped, []byte(master.ID))
+}
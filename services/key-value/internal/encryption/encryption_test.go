@@ -0,0 +1,144 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// masterKey creates a master key filled with b
+func masterKey(t *testing.T, b byte) *MasterKey {
+	t.Helper()
+	key, err := NewMasterKey(bytes.Repeat([]byte{b}, KeySize))
+	require.NoError(t, err)
+	return key
+}
+
+func TestCipher_SealOpen(t *testing.T) {
+	c, err := NewCipher(bytes.Repeat([]byte{1}, KeySize))
+	require.NoError(t, err)
+
+	sealed := c.Seal([]byte("secret"), []byte("record 1"))
+	assert.Len(t, sealed, len("secret")+c.Overhead())
+	assert.NotContains(t, string(sealed), "secret")
+	assert.NotEqual(t, sealed, c.Seal([]byte("secret"), []byte("record 1")), "every seal uses a new nonce")
+
+	plaintext, err := c.Open(sealed, []byte("record 1"))
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plaintext))
+
+	other, err := NewCipher(bytes.Repeat([]byte{2}, KeySize))
+	require.NoError(t, err)
+	flipped := bytes.Clone(sealed)
+	flipped[len(flipped)-1] ^= 1
+
+	tests := []struct {
+		name           string
+		cipher         *Cipher
+		sealed         []byte
+		additionalData string
+	}{
+		{"flipped bit", c, flipped, "record 1"},
+		{"truncated", c, sealed[:len(sealed)-1], "record 1"},
+		{"too short", c, sealed[:4], "record 1"},
+		{"other additional data", c, sealed, "record 2"},
+		{"other key", other, sealed, "record 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.cipher.Open(tt.sealed, []byte(tt.additionalData))
+			assert.ErrorIs(t, err, ErrTampered)
+		})
+	}
+}
+
+func TestLoadMasterKey(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string, mode os.FileMode) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), mode))
+		require.NoError(t, os.Chmod(path, mode))
+		return path
+	}
+	valid := strings.Repeat("ab", KeySize)
+
+	key, err := LoadMasterKey(write("master.key", valid+"\n", 0o600))
+	require.NoError(t, err)
+	assert.Equal(t, masterKey(t, 0xab).ID, key.ID)
+
+	tests := []struct {
+		name string
+		path string
+		err  string
+	}{
+		{"missing", filepath.Join(dir, "missing.key"), "failed to read master key"},
+		{"readable by others", write("open.key", valid, 0o644), "accessible by other users"},
+		{"not hex", write("text.key", strings.Repeat("zz", KeySize), 0o600), "must hold 64 hex digits"},
+		{"short", write("short.key", valid[:32], 0o600), "must hold 64 hex digits"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadMasterKey(tt.path)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestOpenDataKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "data.key")
+	first, second, third := masterKey(t, 1), masterKey(t, 2), masterKey(t, 3)
+
+	created, err := OpenDataKey(path, first)
+	require.NoError(t, err)
+	sealed := created.Seal([]byte("data"), nil)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// open checks that the current data key still opens what was sealed at first
+	open := func(t *testing.T, master *MasterKey, previous ...*MasterKey) {
+		t.Helper()
+		c, err := OpenDataKey(path, master, previous...)
+		require.NoError(t, err)
+		plaintext, err := c.Open(sealed, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "data", string(plaintext))
+	}
+	open(t, first)
+
+	// Rotating re-wraps the same data key, after which the previous master key is not needed
+	open(t, second, first)
+	open(t, second)
+	_, err = OpenDataKey(path, first)
+	assert.ErrorContains(t, err, "wrapped by master key "+second.ID)
+	require.NoError(t, Rewrap(path, second, third))
+	open(t, third)
+	assert.Error(t, Rewrap(filepath.Join(t.TempDir(), "missing.key"), third, first))
+
+	// A data key that was tampered with is not used
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var file keyFile
+	require.NoError(t, json.Unmarshal(data, &file))
+	file.WrappedKey[len(file.WrappedKey)-1] ^= 1
+	data, err = json.Marshal(file)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	_, err = OpenDataKey(path, third)
+	assert.ErrorIs(t, err, ErrTampered)
+}
+
+func TestNewMasterKey_ID(t *testing.T) {
+	key := masterKey(t, 7)
+	assert.Len(t, key.ID, 16)
+	assert.NotContains(t, key.ID, hex.EncodeToString(bytes.Repeat([]byte{7}, 8)), "the ID does not reveal the key")
+	_, err := NewMasterKey([]byte("short"))
+	assert.Error(t, err)
+}
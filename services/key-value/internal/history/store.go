@@ -0,0 +1,467 @@
+// Package history keeps the earlier revisions of every key so past values can be read back
+// by revision of the store or by time.
+package history
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"key-value/services/key-value/internal/kvstore"
+	"maps"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultMaxRevisions is the number of revisions kept per key when no limit is given
+const DefaultMaxRevisions = 10
+
+// DefaultTrimInterval is how often revisions past the maximum age are looked for
+const DefaultTrimInterval = time.Minute
+
+// revisionKey is the reserved key holding the latest revision in the wrapped store
+const revisionKey = kvstore.ReservedPrefix + "history/revision"
+
+var (
+	// ErrCompacted is returned when the requested revision or time is no longer retained
+	ErrCompacted = errors.New("revision has been compacted")
+	// ErrFutureRevision is returned when the requested revision is past the latest revision of the store
+	ErrFutureRevision = errors.New("revision is in the future")
+)
+
+// Revision is the value of a key as of a revision of the store
+type Revision struct {
+	Revision uint64
+	Value    string
+	Deleted  bool // the key was deleted at this revision
+	Time     time.Time
+}
+
+// keyHistory is the retained revisions of one key
+type keyHistory struct {
+	revisions []Revision // oldest first
+	compacted uint64     // revisions of the key before this one were dropped
+}
+
+// Store wraps a store and keeps the revisions of its keys. Every change of the store gets the next revision
+// number, starting at 1. It implements kvstore.Storer and passes the optional store interfaces through, so it
+// can sit under Raft or replication and record what they apply.
+//
+// The revisions are kept in memory, but the latest revision number is kept in a reserved key of the wrapped store.
+// Raft or replication above the store persist and replicate it with the data, so every node numbers the revisions
+// alike, also after loading a snapshot, and a restarted node continues the numbering.
+type Store struct {
+	kvstore.Layer
+	mutex        sync.RWMutex // orders changes of the store with their revisions
+	keys         map[string]*keyHistory
+	revision     uint64
+	compacted    uint64    // revisions before this one were dropped for every key
+	forgotten    uint64    // keys deleted before this revision may have been forgotten, see trim
+	forgottenAt  time.Time // keys deleted before this time may have been forgotten
+	maxRevisions int
+	maxAge       time.Duration
+	trimInterval time.Duration
+	now          func() time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Option configures a Store
+type Option func(*Store)
+
+// WithMaxRevisions sets the number of revisions kept per key, DefaultMaxRevisions by default
+func WithMaxRevisions(n int) Option {
+	return func(s *Store) {
+		s.maxRevisions = n
+	}
+}
+
+// WithMaxAge drops revisions replaced longer than age ago, and forgets keys deleted longer than age ago. They are
+// looked for when the key is written and every trim interval. Zero keeps them.
+func WithMaxAge(age time.Duration) Option {
+	return func(s *Store) {
+		s.maxAge = age
+	}
+}
+
+// WithTrimInterval sets how often revisions past the maximum age are looked for, DefaultTrimInterval by default
+func WithTrimInterval(interval time.Duration) Option {
+	return func(s *Store) {
+		if interval > 0 {
+			s.trimInterval = interval
+		}
+	}
+}
+
+// NewStore starts recording the revisions of the keys written through the returned store, numbering them after the
+// latest revision recorded in it. With a maximum age it trims them in the background until closed.
+func NewStore(store kvstore.Storer, opts ...Option) (*Store, error) {
+	s := &Store{
+		Layer:        kvstore.Layer{Next: store},
+		keys:         make(map[string]*keyHistory),
+		maxRevisions: DefaultMaxRevisions,
+		trimInterval: DefaultTrimInterval,
+		now:          time.Now,
+		done:         make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.maxRevisions <= 0 {
+		s.maxRevisions = DefaultMaxRevisions
+	}
+	revision, err := readRevision(store.Get(revisionKey))
+	if err != nil {
+		return nil, err
+	}
+	s.revision = revision
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	if s.maxAge > 0 {
+		go s.run(ctx)
+	} else {
+		close(s.done)
+	}
+	return s, nil
+}
+
+// Close stops trimming revisions
+func (s *Store) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+// Restore replaces the data of the store. The history before the restore is dropped and every restored key
+// starts over with one revision holding its restored value. A snapshot taken on a node further ahead carries its
+// latest revision, which the restored keys get so the numbering goes on alike; other data, like a backup, is a change
+// of its own and gets the next revision.
+func (s *Store) Restore(data map[string]string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	stored, snapshot := data[revisionKey]
+	revision, err := readRevision(stored, nil)
+	if err != nil {
+		return err
+	}
+	if !snapshot {
+		revision = s.revision + 1
+	}
+	revision = max(revision, s.revision)
+
+	data = maps.Clone(data)
+	data[revisionKey] = strconv.FormatUint(revision, 10)
+	if err := s.Layer.Restore(data); err != nil {
+		return err
+	}
+
+	s.revision = revision
+	s.compacted = s.revision
+	s.forgotten, s.forgottenAt = 0, time.Time{}
+	now := s.now()
+	s.keys = make(map[string]*keyHistory, len(data))
+	for key, value := range data {
+		if kvstore.IsReserved(key) {
+			continue
+		}
+		s.keys[key] = &keyHistory{revisions: []Revision{{Revision: s.revision, Value: value, Time: now}}}
+	}
+	return nil
+}
+
+// Set stores a key-value pair and records it
+func (s *Store) Set(key string, value string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.reserve(key); err != nil {
+		return err
+	}
+	if err := s.Next.Set(key, value); err != nil {
+		return err
+	}
+	s.record(key, value, false)
+	return nil
+}
+
+// Delete removes a key and records it. Deleting a missing key changes nothing and is not recorded.
+func (s *Store) Delete(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err := s.Next.Get(key)
+	if errors.Is(err, kvstore.ErrNotFound) {
+		return s.Next.Delete(key)
+	}
+	if err := s.reserve(key); err != nil {
+		return err
+	}
+	if err := s.Next.Delete(key); err != nil {
+		return err
+	}
+	s.record(key, "", true)
+	return nil
+}
+
+// Increment adds delta to a key and records the resulting value
+func (s *Store) Increment(key string, delta int64) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.reserve(key); err != nil {
+		return 0, err
+	}
+	value, err := s.Next.Increment(key, delta)
+	if err != nil {
+		return 0, err
+	}
+	s.record(key, strconv.FormatInt(value, 10), false)
+	return value, nil
+}
+
+// SetIfAbsent stores a key-value pair only if the key does not exist, recording it when it does
+func (s *Store) SetIfAbsent(key string, value string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.reserve(key); err != nil {
+		return err
+	}
+	if err := s.Layer.SetIfAbsent(key, value); err != nil {
+		return err
+	}
+	s.record(key, value, false)
+	return nil
+}
+
+// DeleteIfValue removes a key only if it holds value, recording it when it does
+func (s *Store) DeleteIfValue(key string, value string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.reserve(key); err != nil {
+		return err
+	}
+	if err := s.Layer.DeleteIfValue(key, value); err != nil {
+		return err
+	}
+	s.record(key, "", true)
+	return nil
+}
+
+// reserve records the next revision before the change of key is made, so a crash in between skips a revision rather
+// than reusing one. Changes of reserved keys are not recorded and need none. Callers hold the mutex.
+func (s *Store) reserve(key string) error {
+	if kvstore.IsReserved(key) {
+		return nil
+	}
+	return s.Next.Set(revisionKey, strconv.FormatUint(s.revision+1, 10))
+}
+
+// readRevision parses a revision kept in a reserved key, 0 when the key does not exist
+func readRevision(stored string, err error) (uint64, error) {
+	if errors.Is(err, kvstore.ErrNotFound) || (err == nil && stored == "") {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	revision, err := strconv.ParseUint(stored, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid revision %q: %w", stored, err)
+	}
+	return revision, nil
+}
+
+// record gives a change the next revision and trims the history of its key. The reserved keys the service keeps
+// its own state in are left out. Callers hold the mutex.
+func (s *Store) record(key string, value string, deleted bool) {
+	if kvstore.IsReserved(key) {
+		return
+	}
+	s.revision++
+	h, ok := s.keys[key]
+	if !ok {
+		// The key may have existed before a deletion that was forgotten
+		h = &keyHistory{compacted: s.forgotten}
+		s.keys[key] = h
+	}
+	h.revisions = append(h.revisions, Revision{Revision: s.revision, Value: value, Deleted: deleted, Time: s.now()})
+	s.trimKey(h, s.now().Add(-s.maxAge))
+}
+
+// trimKey drops the revisions of a key past the maximum number and those replaced before cutoff when there is a
+// maximum age. Callers hold the mutex.
+func (s *Store) trimKey(h *keyHistory, cutoff time.Time) {
+	drop := max(len(h.revisions)-s.maxRevisions, 0)
+	if s.maxAge > 0 {
+		// A revision is only dropped once the revision replacing it is older than the maximum age,
+		// so reads at any time within the age still find the value current then
+		for drop < len(h.revisions)-1 && h.revisions[drop+1].Time.Before(cutoff) {
+			drop++
+		}
+	}
+	if drop > 0 {
+		h.revisions = append(h.revisions[:0], h.revisions[drop:]...)
+		h.compacted = h.revisions[0].Revision
+	}
+}
+
+// run trims the revisions past the maximum age until the store is closed
+func (s *Store) run(ctx context.Context) {
+	defer close(s.done)
+	ticker := time.NewTicker(s.trimInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.trim()
+		}
+	}
+}
+
+// trim drops the revisions past the maximum age of every key, also of keys that are no longer written, and forgets
+// the keys deleted before it. Reads of keys missing before the latest deletion forgotten fail with ErrCompacted,
+// as they may have existed then.
+func (s *Store) trim() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	cutoff := s.now().Add(-s.maxAge)
+	for key, h := range s.keys {
+		if latest := h.revisions[len(h.revisions)-1]; latest.Deleted && latest.Time.Before(cutoff) {
+			s.forgotten = max(s.forgotten, latest.Revision)
+			if latest.Time.After(s.forgottenAt) {
+				s.forgottenAt = latest.Time
+			}
+			delete(s.keys, key)
+			continue
+		}
+		s.trimKey(h, cutoff)
+	}
+}
+
+// GetAtRevision returns the revision of key current at revision rev of the store; 0 reads the latest.
+// It fails with kvstore.ErrNotFound when the key did not exist at rev.
+func (s *Store) GetAtRevision(key string, rev uint64) (Revision, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if rev == 0 {
+		rev = s.revision
+	}
+	if rev > s.revision {
+		return Revision{}, fmt.Errorf("%w: %d is past %d", ErrFutureRevision, rev, s.revision)
+	}
+	if rev < s.compacted {
+		return Revision{}, fmt.Errorf("%w: revisions before %d were dropped", ErrCompacted, s.compacted)
+	}
+	h, ok := s.keys[key]
+	if !ok && rev < s.forgotten {
+		return Revision{}, fmt.Errorf("%w: keys deleted before %d were forgotten", ErrCompacted, s.forgotten)
+	}
+	if !ok {
+		return Revision{}, kvstore.ErrNotFound
+	}
+	if rev < h.compacted {
+		return Revision{}, fmt.Errorf("%w: revisions of %s before %d were dropped", ErrCompacted, key, h.compacted)
+	}
+
+	i := sort.Search(len(h.revisions), func(i int) bool { return h.revisions[i].Revision > rev }) - 1
+	if i < 0 || h.revisions[i].Deleted {
+		return Revision{}, kvstore.ErrNotFound
+	}
+	return h.revisions[i], nil
+}
+
+// GetAtTime returns the revision of key current at t.
+// It fails with kvstore.ErrNotFound when the key did not exist at t.
+func (s *Store) GetAtTime(key string, t time.Time) (Revision, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	h, ok := s.keys[key]
+	if !ok && t.Before(s.forgottenAt) {
+		return Revision{}, fmt.Errorf("%w: keys deleted before %s were forgotten", ErrCompacted, s.forgottenAt)
+	}
+	if !ok {
+		return Revision{}, kvstore.ErrNotFound
+	}
+	i := sort.Search(len(h.revisions), func(i int) bool { return h.revisions[i].Time.After(t) }) - 1
+	if i < 0 {
+		if h.compacted > 0 {
+			return Revision{}, fmt.Errorf("%w: revisions of %s before %d were dropped", ErrCompacted, key, h.compacted)
+		}
+		return Revision{}, kvstore.ErrNotFound
+	}
+	if h.revisions[i].Deleted {
+		return Revision{}, kvstore.ErrNotFound
+	}
+	return h.revisions[i], nil
+}
+
+// History returns the retained revisions of key, newest first, including deletions.
+// It fails with kvstore.ErrNotFound when none are retained.
+func (s *Store) History(key string) ([]Revision, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	h, ok := s.keys[key]
+	if !ok {
+		return nil, kvstore.ErrNotFound
+	}
+	revisions := make([]Revision, len(h.revisions))
+	for i, revision := range h.revisions {
+		revisions[len(revisions)-1-i] = revision
+	}
+	return revisions, nil
+}
+
+// Compact drops the revisions replaced at or before revision rev of the store, keeping for every key the
+// revision current at rev. Keys deleted by then are forgotten. It returns the number of revisions dropped.
+func (s *Store) Compact(rev uint64) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if rev > s.revision {
+		return 0, fmt.Errorf("%w: %d is past %d", ErrFutureRevision, rev, s.revision)
+	}
+	if rev <= s.compacted {
+		return 0, nil
+	}
+
+	removed := 0
+	for key, h := range s.keys {
+		i := sort.Search(len(h.revisions), func(i int) bool { return h.revisions[i].Revision > rev }) - 1
+		if i < 0 {
+			continue
+		}
+		if i == len(h.revisions)-1 && h.revisions[i].Deleted {
+			removed += len(h.revisions)
+			delete(s.keys, key)
+			continue
+		}
+		if h.revisions[i].Deleted {
+			i++ // the key did not exist at rev, so the deletion is not needed either
+		}
+		if i > 0 {
+			removed += i
+			h.revisions = append(h.revisions[:0], h.revisions[i:]...)
+			h.compacted = h.revisions[0].Revision
+		}
+	}
+	s.compacted = rev
+	return removed, nil
+}
+
+// Bounds returns the revision before which history was compacted for every key and the latest revision
+func (s *Store) Bounds() (compacted uint64, latest uint64) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.compacted, s.revision
+}
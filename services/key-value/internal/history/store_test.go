@@ -0,0 +1,286 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"key-value/services/key-value/internal/kvstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clock is a settable time source for the store
+type clock struct{ now time.Time }
+
+func newClock() *clock                   { return &clock{now: time.Unix(1000, 0)} }
+func (c *clock) Now() time.Time          { return c.now }
+func (c *clock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// values lists the values of revisions, marking deletions
+func values(revisions []Revision) []string {
+	var values []string
+	for _, revision := range revisions {
+		if revision.Deleted {
+			values = append(values, "<deleted>")
+			continue
+		}
+		values = append(values, revision.Value)
+	}
+	return values
+}
+
+// newStore records the revisions of store, failing the test when the latest revision cannot be read
+func newStore(t *testing.T, store kvstore.Storer, opts ...Option) *Store {
+	t.Helper()
+	s, err := NewStore(store, opts...)
+	require.NoError(t, err)
+	return s
+}
+
+func TestStore_GetAtRevision(t *testing.T) {
+	s := newStore(t, kvstore.NewInMemoryStore())
+
+	require.NoError(t, s.Set("config", "v1")) // 1
+	require.NoError(t, s.Set("other", "x"))   // 2
+	require.NoError(t, s.Set("config", "v2")) // 3
+	require.NoError(t, s.Delete("config"))    // 4
+	require.NoError(t, s.Delete("config"))    // missing, not recorded
+	_, err := s.Increment("config", 5)        // 5
+	require.NoError(t, err)
+	assert.ErrorIs(t, s.SetIfAbsent("config", "x"), kvstore.ErrConflict) // failed, not recorded
+
+	tests := []struct {
+		revision  uint64
+		wantValue string
+		wantErr   error
+	}{
+		{revision: 1, wantValue: "v1"},
+		{revision: 2, wantValue: "v1"},
+		{revision: 3, wantValue: "v2"},
+		{revision: 4, wantErr: kvstore.ErrNotFound},
+		{revision: 5, wantValue: "5"},
+		{revision: 0, wantValue: "5"},
+		{revision: 6, wantErr: ErrFutureRevision},
+	}
+	for _, tt := range tests {
+		revision, err := s.GetAtRevision("config", tt.revision)
+		if tt.wantErr != nil {
+			assert.ErrorIs(t, err, tt.wantErr, "revision %d", tt.revision)
+			continue
+		}
+		require.NoError(t, err, "revision %d", tt.revision)
+		assert.Equal(t, tt.wantValue, revision.Value, "revision %d", tt.revision)
+	}
+
+	_, err = s.GetAtRevision("missing", 5)
+	assert.ErrorIs(t, err, kvstore.ErrNotFound)
+
+	history, err := s.History("config")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"5", "<deleted>", "v2", "v1"}, values(history))
+	assert.Equal(t, uint64(5), history[0].Revision)
+}
+
+func TestStore_GetAtTime(t *testing.T) {
+	c := newClock()
+	s := newStore(t, kvstore.NewInMemoryStore())
+	s.now = c.Now
+
+	start := c.now
+	require.NoError(t, s.Set("config", "v1"))
+	c.Advance(time.Minute)
+	require.NoError(t, s.Set("config", "v2"))
+	c.Advance(time.Minute)
+	require.NoError(t, s.Delete("config"))
+
+	_, err := s.GetAtTime("config", start.Add(-time.Second))
+	assert.ErrorIs(t, err, kvstore.ErrNotFound)
+	revision, err := s.GetAtTime("config", start.Add(30*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, "v1", revision.Value)
+	revision, err = s.GetAtTime("config", start.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, "v2", revision.Value)
+	_, err = s.GetAtTime("config", start.Add(time.Hour))
+	assert.ErrorIs(t, err, kvstore.ErrNotFound)
+}
+
+func TestStore_Retention(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        []Option
+		wantHistory []string
+	}{
+		{name: "by count", opts: []Option{WithMaxRevisions(3)}, wantHistory: []string{"v5", "v4", "v3"}},
+		// v1 was replaced 30s ago, past the age, while v2 was replaced 20s ago and is still needed for reads then
+		{name: "by age", opts: []Option{WithMaxAge(25 * time.Second)}, wantHistory: []string{"v5", "v4", "v3", "v2"}},
+		{name: "default", wantHistory: []string{"v5", "v4", "v3", "v2", "v1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newClock()
+			s := newStore(t, kvstore.NewInMemoryStore(), tt.opts...)
+			s.now = c.Now
+
+			for _, value := range []string{"v1", "v2", "v3", "v4", "v5"} {
+				require.NoError(t, s.Set("config", value))
+				c.Advance(10 * time.Second)
+			}
+
+			history, err := s.History("config")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantHistory, values(history))
+
+			if len(tt.wantHistory) < 5 {
+				_, err = s.GetAtRevision("config", 1)
+				assert.ErrorIs(t, err, ErrCompacted)
+				_, err = s.GetAtTime("config", time.Unix(1000, 0))
+				assert.ErrorIs(t, err, ErrCompacted)
+			}
+		})
+	}
+}
+
+func TestStore_Trim(t *testing.T) {
+	c := newClock()
+	s := newStore(t, kvstore.NewInMemoryStore(), WithMaxAge(25*time.Second))
+	t.Cleanup(func() { s.Close() })
+	s.now = c.Now
+
+	require.NoError(t, s.Set("config", "v1"))
+	require.NoError(t, s.Set("gone", "x"))
+	c.Advance(10 * time.Second)
+	require.NoError(t, s.Set("config", "v2"))
+	require.NoError(t, s.Delete("gone"))
+	c.Advance(10 * time.Second)
+	require.NoError(t, s.Delete("recent"), "missing, not recorded")
+	require.NoError(t, s.Set("recent", "r"))
+	require.NoError(t, s.Delete("recent"))
+
+	// Nothing is written anymore, trimming alone drops what is past the age
+	c.Advance(20 * time.Second)
+	s.trim()
+
+	history, err := s.History("config")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v2"}, values(history))
+	_, err = s.GetAtTime("config", time.Unix(1000, 0))
+	assert.ErrorIs(t, err, ErrCompacted)
+
+	_, err = s.History("gone")
+	assert.ErrorIs(t, err, kvstore.ErrNotFound, "deleted past the age, so forgotten")
+	_, err = s.GetAtRevision("gone", 3)
+	assert.ErrorIs(t, err, ErrCompacted, "existed before its forgotten deletion")
+	_, err = s.GetAtTime("gone", time.Unix(1005, 0))
+	assert.ErrorIs(t, err, ErrCompacted)
+	_, err = s.GetAtRevision("gone", 4)
+	assert.ErrorIs(t, err, kvstore.ErrNotFound, "deleted at 4")
+	require.NoError(t, s.Set("gone", "back"))
+	_, err = s.GetAtRevision("gone", 3)
+	assert.ErrorIs(t, err, ErrCompacted, "may have existed before its forgotten deletion")
+	history, err = s.History("recent")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"<deleted>", "r"}, values(history), "deleted within the age, so kept")
+}
+
+func TestStore_TrimInBackground(t *testing.T) {
+	s := newStore(t, kvstore.NewInMemoryStore())
+	assert.NoError(t, s.Close(), "no trimming without a maximum age")
+
+	s = newStore(t, kvstore.NewInMemoryStore(), WithMaxAge(time.Millisecond), WithTrimInterval(time.Millisecond))
+	require.NoError(t, s.Set("gone", "x"))
+	require.NoError(t, s.Delete("gone"))
+	assert.Eventually(t, func() bool {
+		_, err := s.History("gone")
+		return err != nil
+	}, time.Second, time.Millisecond)
+	assert.NoError(t, s.Close())
+}
+
+func TestStore_Compact(t *testing.T) {
+	s := newStore(t, kvstore.NewInMemoryStore())
+	require.NoError(t, s.Set("a", "1"))    // 1
+	require.NoError(t, s.Set("a", "2"))    // 2
+	require.NoError(t, s.Set("gone", "x")) // 3
+	require.NoError(t, s.Delete("gone"))   // 4
+	require.NoError(t, s.Set("a", "3"))    // 5
+
+	_, err := s.Compact(6)
+	assert.ErrorIs(t, err, ErrFutureRevision)
+
+	removed, err := s.Compact(4)
+	require.NoError(t, err)
+	assert.Equal(t, 3, removed) // a@1 and both revisions of gone
+
+	history, err := s.History("a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"3", "2"}, values(history))
+	_, err = s.History("gone")
+	assert.ErrorIs(t, err, kvstore.ErrNotFound)
+
+	revision, err := s.GetAtRevision("a", 4)
+	require.NoError(t, err)
+	assert.Equal(t, "2", revision.Value)
+	_, err = s.GetAtRevision("a", 3)
+	assert.ErrorIs(t, err, ErrCompacted)
+	_, err = s.GetAtRevision("gone", 3)
+	assert.ErrorIs(t, err, ErrCompacted)
+
+	compacted, latest := s.Bounds()
+	assert.Equal(t, uint64(4), compacted)
+	assert.Equal(t, uint64(5), latest)
+}
+
+func TestStore_Restore(t *testing.T) {
+	s := newStore(t, kvstore.NewInMemoryStore())
+	require.NoError(t, s.Set("a", "1"))
+	require.NoError(t, s.Restore(map[string]string{"a": "restored"}))
+
+	history, err := s.History("a")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"restored"}, values(history))
+	_, err = s.GetAtRevision("a", 1)
+	assert.ErrorIs(t, err, ErrCompacted)
+	revision, err := s.GetAtRevision("a", 0)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), revision.Revision)
+}
+
+func TestStore_RevisionKept(t *testing.T) {
+	data := kvstore.NewInMemoryStore()
+	s := newStore(t, data)
+	require.NoError(t, s.Set("a", "1"))
+	require.NoError(t, s.Set("b", "2"))
+
+	// A restarted node continues the numbering kept in the store
+	s = newStore(t, data)
+	require.NoError(t, s.Set("c", "3"))
+	_, latest := s.Bounds()
+	assert.Equal(t, uint64(3), latest)
+
+	// A node loading a snapshot numbers the following revisions alike
+	snapshot, err := data.Snapshot()
+	require.NoError(t, err)
+	follower := newStore(t, kvstore.NewInMemoryStore())
+	require.NoError(t, follower.Set("stale", "x"))
+	require.NoError(t, follower.Restore(snapshot))
+	require.NoError(t, s.Set("d", "4"))
+	require.NoError(t, follower.Set("d", "4"))
+	for _, store := range []*Store{s, follower} {
+		revision, err := store.GetAtRevision("d", 0)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(4), revision.Revision)
+	}
+
+	// Restoring a backup is a change of its own on every node
+	backup := map[string]string{"a": "restored"}
+	require.NoError(t, s.Restore(backup))
+	require.NoError(t, follower.Restore(backup))
+	for _, store := range []*Store{s, follower} {
+		revision, err := store.GetAtRevision("a", 0)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(5), revision.Revision)
+	}
+}
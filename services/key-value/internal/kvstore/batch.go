@@ -0,0 +1,60 @@
+package kvstore
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Check is a condition of a batch: Key holds Value, or does not exist when Absent is set
+type Check struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Absent bool   `json:"absent,omitempty"`
+}
+
+// Write is a change of a batch: Key is set to Value, or removed when Delete is set
+type Write struct {
+	Key    string `json:"key"`
+	Value  string `json:"value,omitempty"`
+	Delete bool   `json:"delete,omitempty"`
+}
+
+// CheckBatch returns ErrConflict when a check does not hold in store
+func CheckBatch(store Storer, checks []Check) error {
+	for _, check := range checks {
+		value, err := store.Get(check.Key)
+		switch {
+		case errors.Is(err, ErrNotFound):
+			if !check.Absent {
+				return fmt.Errorf("key %s does not exist: %w", check.Key, ErrConflict)
+			}
+		case err != nil:
+			return err
+		case check.Absent:
+			return fmt.Errorf("key %s already exists: %w", check.Key, ErrConflict)
+		case value != check.Value:
+			return fmt.Errorf("key %s does not hold the expected value: %w", check.Key, ErrConflict)
+		}
+	}
+	return nil
+}
+
+// ApplyBatch checks and writes a batch to store one call at a time. It is as atomic as the caller makes it: the
+// stores implementing Batcher call it where their changes are already ordered, like the apply loop of Raft.
+func ApplyBatch(store Storer, checks []Check, writes []Write) error {
+	if err := CheckBatch(store, checks); err != nil {
+		return err
+	}
+	for _, write := range writes {
+		var err error
+		if write.Delete {
+			err = store.Delete(write.Key)
+		} else {
+			err = store.Set(write.Key, write.Value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
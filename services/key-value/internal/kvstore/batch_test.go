@@ -0,0 +1,39 @@
+package kvstore
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestApplyBatch(t *testing.T) {
+	s := NewInMemoryStore()
+	s.Set("a", "1")
+	s.Set("b", "2")
+
+	// A check that does not hold changes nothing
+	for _, checks := range [][]Check{
+		{{Key: "a", Value: "1"}, {Key: "b", Value: "3"}},
+		{{Key: "a", Absent: true}},
+		{{Key: "c", Value: ""}},
+	} {
+		err := ApplyBatch(s, checks, []Write{{Key: "a", Value: "changed"}})
+		if !errors.Is(err, ErrConflict) {
+			t.Errorf("ApplyBatch(%v) error = %v, want %v", checks, err, ErrConflict)
+		}
+	}
+
+	checks := []Check{{Key: "a", Value: "1"}, {Key: "c", Absent: true}}
+	writes := []Write{{Key: "a", Value: "changed"}, {Key: "b", Delete: true}, {Key: "c", Value: "3"}, {Key: "d", Delete: true}}
+	if err := ApplyBatch(s, checks, writes); err != nil {
+		t.Fatalf("ApplyBatch() error = %v", err)
+	}
+	want := map[string]string{"a": "changed", "c": "3"}
+	if got, _ := s.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Snapshot() = %q, want %q", got, want)
+	}
+
+	if err := Batch(s, checks, writes); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Batch() of a local store error = %v, want %v", err, ErrUnsupported)
+	}
+}
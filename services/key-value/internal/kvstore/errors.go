@@ -0,0 +1,44 @@
+package kvstore
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors returned by Storer implementations. Callers should compare with errors.Is
+// since implementations may wrap them with extra context.
+var (
+	// ErrNotFound is returned when the requested key does not exist
+	ErrNotFound = errors.New("key not found")
+	// ErrConflict is returned when a write loses against a concurrent modification
+	ErrConflict = errors.New("conflicting modification")
+	// ErrTooLarge is returned when a key or value exceeds a size limit
+	ErrTooLarge = errors.New("too large")
+	// ErrInvalidKey is returned when a key is empty or contains characters that are not allowed
+	ErrInvalidKey = errors.New("invalid key")
+	// ErrNotNumeric is returned when incrementing a value that is not a base 10 int64
+	ErrNotNumeric = errors.New("value is not an integer")
+	// ErrOverflow is returned when an increment would overflow an int64
+	ErrOverflow = errors.New("integer overflow")
+	// ErrNotLeader is returned by replicated stores when the request must be served by another node
+	ErrNotLeader = errors.New("not the leader")
+	// ErrUnsupported is returned when a store lacks an optional capability, like scans or snapshots
+	ErrUnsupported = errors.ErrUnsupported
+)
+
+// LeaderError wraps ErrNotLeader with the gRPC address of the node that can serve the request.
+// LeaderAddr is empty when the leader is unknown (for example during an election).
+type LeaderError struct {
+	LeaderAddr string
+}
+
+func (e *LeaderError) Error() string {
+	if e.LeaderAddr == "" {
+		return fmt.Sprintf("%v: leader unknown", ErrNotLeader)
+	}
+	return fmt.Sprintf("%v: leader is %s", ErrNotLeader, e.LeaderAddr)
+}
+
+func (e *LeaderError) Unwrap() error {
+	return ErrNotLeader
+}
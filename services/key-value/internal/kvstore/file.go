@@ -0,0 +1,260 @@
+package kvstore
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"key-value/services/key-value/internal/encryption"
+	"key-value/services/key-value/internal/sealedlog"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// fileStoreName is the name of the log of a FileStore in its directory
+const fileStoreName = "store.log"
+
+// fileStoreFormat is the format of the log of a FileStore
+var fileStoreFormat = sealedlog.Format{Magic: "KVSTOLOG", Name: "store log", Label: "kvstore log"}
+
+// minCompactRecords is the number of records a log holds at least before it is rewritten
+const minCompactRecords = 1024
+
+// Operations of the records of a FileStore log
+const (
+	fileOpSet    byte = 's'
+	fileOpDelete byte = 'd'
+)
+
+func init() {
+	Register("file", func(options BackendOptions) (Storer, error) {
+		if options.Dir == "" {
+			return nil, errors.New("a directory is required")
+		}
+		if options.Cipher == nil {
+			return nil, errors.New("a data key is required")
+		}
+		return OpenFileStore(options.Dir, options.Cipher, WithLimits(options.Limits))
+	})
+}
+
+// FileStore keeps its data in memory like InMemoryStore and appends every change to a log file in a directory,
+// sealed with a data key and synced before the change returns, so the data survives restarts without being written
+// to disk unencrypted. The log is replayed on open and rewritten holding the current data only once it holds twice
+// as many records as there are keys.
+type FileStore struct {
+	memory *InMemoryStore
+
+	mutex sync.Mutex // orders the changes of the memory with their records
+	log   *sealedlog.Log
+}
+
+// OpenFileStore replays the log in dir, creating both when missing. A record cut short at the end of the log is an
+// interrupted write and is dropped with a warning; any other damaged record, and a log sealed with another key,
+// fails the open. The options apply to the data kept in memory.
+func OpenFileStore(dir string, cipher *encryption.Cipher, opts ...Option) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create store directory: %w", err)
+	}
+	s := &FileStore{memory: NewInMemoryStore(opts...)}
+	storeLog, err := sealedlog.Open(filepath.Join(dir, fileStoreName), fileStoreFormat, cipher, func(record []byte) error {
+		op, key, value, err := decodeFileRecord(record)
+		if err != nil {
+			return err
+		}
+		if op == fileOpSet {
+			s.memory.store[key] = value
+		} else {
+			delete(s.memory.store, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.log = storeLog
+	return s, nil
+}
+
+// encodeFileRecord encodes an operation, a key and, for sets, a value
+func encodeFileRecord(op byte, key string, value string) []byte {
+	record := binary.AppendUvarint([]byte{op}, uint64(len(key)))
+	return append(append(record, key...), value...)
+}
+
+// decodeFileRecord splits a record into its operation, key and value
+func decodeFileRecord(record []byte) (op byte, key string, value string, err error) {
+	if len(record) == 0 || (record[0] != fileOpSet && record[0] != fileOpDelete) {
+		return 0, "", "", errors.New("unknown operation")
+	}
+	length, n := binary.Uvarint(record[1:])
+	if n <= 0 || length > uint64(len(record)-1-n) {
+		return 0, "", "", errors.New("invalid key length")
+	}
+	rest := record[1+n:]
+	return record[0], string(rest[:length]), string(rest[length:]), nil
+}
+
+// append writes a record to the end of the log and syncs it. The caller holds the mutex and applies the change once
+// it returns.
+func (s *FileStore) append(op byte, key string, value string) error {
+	return s.log.Append(encodeFileRecord(op, key, value))
+}
+
+// compact rewrites the log holding the current data only once it holds twice as many records as there are keys.
+// The change is already durable, so a failed rewrite is reported and tried again with a later change. The caller
+// holds the mutex.
+func (s *FileStore) compact() {
+	s.memory.mutex.RLock()
+	keys := len(s.memory.store)
+	s.memory.mutex.RUnlock()
+	if s.log.Records() < uint64(max(2*keys, minCompactRecords)) {
+		return
+	}
+	data, _ := s.memory.Snapshot()
+	if err := s.rewrite(data); err != nil {
+		log.Printf("kvstore: %v", err)
+	}
+}
+
+// rewrite replaces the log with one holding data only, so a crash leaves either log whole. The caller holds the mutex.
+func (s *FileStore) rewrite(data map[string]string) error {
+	return s.log.Rewrite(func(add func([]byte) error) error {
+		for key, value := range data {
+			if err := add(encodeFileRecord(fileOpSet, key, value)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Get retrieves a value by key
+func (s *FileStore) Get(key string) (string, error) {
+	return s.memory.Get(key)
+}
+
+// Set stores a key-value pair once its record is synced
+func (s *FileStore) Set(key string, value string) error {
+	if s.memory.limits != nil && !IsReserved(key) {
+		if err := CheckLimits(*s.memory.limits, key, value); err != nil {
+			return err
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.append(fileOpSet, key, value); err != nil {
+		return err
+	}
+	s.memory.Set(key, value)
+	s.compact()
+	return nil
+}
+
+// Delete removes a key once its record is synced. Deleting a missing key writes nothing.
+func (s *FileStore) Delete(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, err := s.memory.Get(key); errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err := s.append(fileOpDelete, key, ""); err != nil {
+		return err
+	}
+	s.memory.Delete(key)
+	s.compact()
+	return nil
+}
+
+// Increment adds delta to the integer stored at key once the result is synced, like InMemoryStore.Increment
+func (s *FileStore) Increment(key string, delta int64) (int64, error) {
+	if s.memory.limits != nil && !IsReserved(key) {
+		if err := CheckKey(*s.memory.limits, key); err != nil {
+			return 0, err
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	stored, err := s.memory.Get(key)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return 0, err
+	}
+	result, err := increment(stored, err == nil, delta)
+	if err != nil {
+		return 0, err
+	}
+	value := strconv.FormatInt(result, 10)
+	if err := s.append(fileOpSet, key, value); err != nil {
+		return 0, err
+	}
+	s.memory.Set(key, value)
+	s.compact()
+	return result, nil
+}
+
+// SetIfAbsent stores a key-value pair only if the key does not exist
+func (s *FileStore) SetIfAbsent(key string, value string) error {
+	if s.memory.limits != nil && !IsReserved(key) {
+		if err := CheckLimits(*s.memory.limits, key, value); err != nil {
+			return err
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, err := s.memory.Get(key); err == nil {
+		return fmt.Errorf("key %s already exists: %w", key, ErrConflict)
+	}
+	if err := s.append(fileOpSet, key, value); err != nil {
+		return err
+	}
+	s.memory.Set(key, value)
+	s.compact()
+	return nil
+}
+
+// DeleteIfValue removes a key only if it currently holds value
+func (s *FileStore) DeleteIfValue(key string, value string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if current, err := s.memory.Get(key); err != nil || current != value {
+		return fmt.Errorf("key %s does not hold the expected value: %w", key, ErrConflict)
+	}
+	if err := s.append(fileOpDelete, key, ""); err != nil {
+		return err
+	}
+	s.memory.Delete(key)
+	s.compact()
+	return nil
+}
+
+// Snapshot returns a copy of every key-value pair
+func (s *FileStore) Snapshot() (map[string]string, error) {
+	return s.memory.Snapshot()
+}
+
+// Restore replaces the contents of the store with a copy of data, rewriting the log to hold it
+func (s *FileStore) Restore(data map[string]string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.rewrite(data); err != nil {
+		return err
+	}
+	return s.memory.Restore(data)
+}
+
+// Scan lists matching pairs in key order, like InMemoryStore.Scan
+func (s *FileStore) Scan(prefix string, startAfter string, limit int) ([]KeyValue, bool, error) {
+	return s.memory.Scan(prefix, startAfter, limit)
+}
+
+// Close closes the log
+func (s *FileStore) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.log.Close()
+}
@@ -0,0 +1,171 @@
+package kvstore
+
+import (
+	"bytes"
+	"errors"
+	"key-value/services/key-value/internal/encryption"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testCipher returns a cipher with a data key filled with b
+func testCipher(t *testing.T, b byte) *encryption.Cipher {
+	t.Helper()
+	c, err := encryption.NewCipher(bytes.Repeat([]byte{b}, encryption.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// openFileStore opens the store in dir, closing it when the test ends
+func openFileStore(t *testing.T, dir string) *FileStore {
+	t.Helper()
+	s, err := OpenFileStore(dir, testCipher(t, 9))
+	if err != nil {
+		t.Fatalf("OpenFileStore() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestFileStore_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	s := openFileStore(t, dir)
+	for _, kv := range [][2]string{{"a", "1"}, {"b", "2"}, {"binary\x00key", "\xff\x00"}, {"a", "overwritten"}} {
+		if err := s.Set(kv[0], kv[1]); err != nil {
+			t.Fatalf("Set(%q) error = %v", kv[0], err)
+		}
+	}
+	if err := s.Delete("b"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := s.Increment("counter", 5); err != nil {
+		t.Fatalf("Increment() error = %v", err)
+	}
+	if err := s.SetIfAbsent("new", "x"); err != nil {
+		t.Fatalf("SetIfAbsent() error = %v", err)
+	}
+	if err := s.DeleteIfValue("new", "x"); err != nil {
+		t.Fatalf("DeleteIfValue() error = %v", err)
+	}
+	s.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, fileStoreName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("overwritten")) {
+		t.Error("log holds a value unencrypted")
+	}
+
+	s = openFileStore(t, dir)
+	want := map[string]string{"a": "overwritten", "binary\x00key": "\xff\x00", "counter": "5"}
+	if got, _ := s.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Snapshot() after reopening = %q, want %q", got, want)
+	}
+
+	if err := s.Restore(map[string]string{"restored": "r"}); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if err := s.Set("after", "restore"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	s.Close()
+	s = openFileStore(t, dir)
+	want = map[string]string{"restored": "r", "after": "restore"}
+	if got, _ := s.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Snapshot() after restoring and reopening = %q, want %q", got, want)
+	}
+}
+
+func TestFileStore_Compaction(t *testing.T) {
+	dir := t.TempDir()
+	s := openFileStore(t, dir)
+	for i := 0; i < minCompactRecords+10; i++ {
+		if _, err := s.Increment("counter", 1); err != nil {
+			t.Fatalf("Increment() error = %v", err)
+		}
+	}
+	if records := s.log.Records(); records != 11 {
+		t.Errorf("records after compacting = %d, want 11", records)
+	}
+	s.Close()
+
+	s = openFileStore(t, dir)
+	if got, err := s.Get("counter"); err != nil || got != "1034" {
+		t.Errorf("Get(counter) after reopening = %q, %v, want 1034", got, err)
+	}
+}
+
+func TestFileStore_InterruptedWrite(t *testing.T) {
+	dir := t.TempDir()
+	s := openFileStore(t, dir)
+	s.Set("a", "1")
+	s.Set("b", "2")
+	s.Close()
+
+	// A crash while appending leaves the last record cut short
+	path := filepath.Join(dir, fileStoreName)
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, info.Size()-1); err != nil {
+		t.Fatal(err)
+	}
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	s = openFileStore(t, dir)
+	if !strings.Contains(logged.String(), "interrupted write after record 1") {
+		t.Errorf("log = %q, want the dropped write reported", logged.String())
+	}
+	if _, err := s.Get("b"); err == nil {
+		t.Error("Get(b) of the cut short record should fail")
+	}
+	s.Set("c", "3")
+	s.Close()
+
+	s = openFileStore(t, dir)
+	want := map[string]string{"a": "1", "c": "3"}
+	if got, _ := s.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Snapshot() = %q, want %q", got, want)
+	}
+}
+
+func TestFileStore_Damaged(t *testing.T) {
+	dir := t.TempDir()
+	s := openFileStore(t, dir)
+	s.Set("a", "1")
+	s.Set("b", "2")
+	s.Close()
+
+	path := filepath.Join(dir, fileStoreName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)/4] ^= 1
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = OpenFileStore(dir, testCipher(t, 9))
+	if !errors.Is(err, encryption.ErrTampered) || !strings.Contains(err.Error(), "record 0") {
+		t.Errorf("OpenFileStore() of a damaged log error = %v", err)
+	}
+
+	// Another data key cannot read the log either
+	data[len(data)/4] ^= 1
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenFileStore(dir, testCipher(t, 1)); !errors.Is(err, encryption.ErrTampered) {
+		t.Errorf("OpenFileStore() with another data key error = %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package kvstore
+
+// Layer is embedded by stores that wrap another store to act on some of its calls, like recording history or
+// notifying watchers. It passes Storer and the optional Scanner, Snapshotter, ConditionalWriter and ConsistentReader
+// through to the wrapped store, failing the optional calls it does not implement with ErrUnsupported. The embedding store
+// overrides the calls it acts on and reaches the wrapped store through the Layer methods.
+type Layer struct {
+	Next Storer // the wrapped store
+}
+
+// Get reads a key from the wrapped store
+func (l Layer) Get(key string) (string, error) {
+	return l.Next.Get(key)
+}
+
+// Set stores a key-value pair in the wrapped store
+func (l Layer) Set(key string, value string) error {
+	return l.Next.Set(key, value)
+}
+
+// Delete removes a key from the wrapped store
+func (l Layer) Delete(key string) error {
+	return l.Next.Delete(key)
+}
+
+// Increment adds delta to a key of the wrapped store
+func (l Layer) Increment(key string, delta int64) (int64, error) {
+	return l.Next.Increment(key, delta)
+}
+
+// LinearizableGet reads a key of the wrapped store after every write committed before the call
+func (l Layer) LinearizableGet(key string) (string, error) {
+	return LinearizableGet(l.Next, key)
+}
+
+// StaleGet reads the local copy of a key of the wrapped store
+func (l Layer) StaleGet(key string) (string, error) {
+	return StaleGet(l.Next, key)
+}
+
+// Scan lists keys of the wrapped store in order
+func (l Layer) Scan(prefix string, startAfter string, limit int) ([]KeyValue, bool, error) {
+	return Scan(l.Next, prefix, startAfter, limit)
+}
+
+// Snapshot copies the data of the wrapped store
+func (l Layer) Snapshot() (map[string]string, error) {
+	return Snapshot(l.Next)
+}
+
+// Restore replaces the data of the wrapped store
+func (l Layer) Restore(data map[string]string) error {
+	return Restore(l.Next, data)
+}
+
+// SetIfAbsent stores a key-value pair in the wrapped store only if the key does not exist
+func (l Layer) SetIfAbsent(key string, value string) error {
+	return SetIfAbsent(l.Next, key, value)
+}
+
+// DeleteIfValue removes a key from the wrapped store only if it holds value
+func (l Layer) DeleteIfValue(key string, value string) error {
+	return DeleteIfValue(l.Next, key, value)
+}
@@ -0,0 +1,30 @@
+package kvstore
+
+import (
+	"fmt"
+	"key-value/shared/limits"
+)
+
+// CheckLimits validates a key and value against the limits. Key violations (including oversized keys)
+// wrap ErrInvalidKey and value violations wrap ErrTooLarge; the message names the limit that was hit.
+func CheckLimits(l limits.Limits, key string, value string) error {
+	if err := CheckKey(l, key); err != nil {
+		return err
+	}
+	if err := l.ValidateValue(value); err != nil {
+		return fmt.Errorf("%w: %w", ErrTooLarge, err)
+	}
+	return nil
+}
+
+// CheckKey validates a key against the limits, returning an error that wraps ErrInvalidKey. Reserved keys are
+// rejected as well, so clients cannot reach the state the service keeps in them.
+func CheckKey(l limits.Limits, key string) error {
+	if err := l.ValidateKey(key); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidKey, err)
+	}
+	if IsReserved(key) {
+		return fmt.Errorf("%w: keys starting with %q are reserved", ErrInvalidKey, ReservedPrefix)
+	}
+	return nil
+}
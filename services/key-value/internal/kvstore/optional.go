@@ -0,0 +1,88 @@
+package kvstore
+
+import "fmt"
+
+// The functions below call the optional interfaces of a store, failing with ErrUnsupported when it does not
+// implement them. Wrapping stores implement every optional interface to pass it through, so callers find out
+// what the store at the bottom supports from the error rather than from a type assertion.
+
+// Scan lists keys of store in order
+func Scan(store Storer, prefix string, startAfter string, limit int) ([]KeyValue, bool, error) {
+	scanner, ok := store.(Scanner)
+	if !ok {
+		return nil, false, fmt.Errorf("store %T does not support scans: %w", store, ErrUnsupported)
+	}
+	return scanner.Scan(prefix, startAfter, limit)
+}
+
+// Snapshot copies the data of store
+func Snapshot(store Storer) (map[string]string, error) {
+	snapshotter, ok := store.(Snapshotter)
+	if !ok {
+		return nil, fmt.Errorf("store %T does not support snapshots: %w", store, ErrUnsupported)
+	}
+	return snapshotter.Snapshot()
+}
+
+// Restore replaces the data of store
+func Restore(store Storer, data map[string]string) error {
+	snapshotter, ok := store.(Snapshotter)
+	if !ok {
+		return fmt.Errorf("store %T does not support snapshots: %w", store, ErrUnsupported)
+	}
+	return snapshotter.Restore(data)
+}
+
+// LinearizableGet reads a key of store after every write committed before the call
+func LinearizableGet(store Storer, key string) (string, error) {
+	reader, ok := store.(ConsistentReader)
+	if !ok {
+		return "", fmt.Errorf("store %T does not support read modes: %w", store, ErrUnsupported)
+	}
+	return reader.LinearizableGet(key)
+}
+
+// StaleGet reads the local copy of a key of store
+func StaleGet(store Storer, key string) (string, error) {
+	reader, ok := store.(ConsistentReader)
+	if !ok {
+		return "", fmt.Errorf("store %T does not support read modes: %w", store, ErrUnsupported)
+	}
+	return reader.StaleGet(key)
+}
+
+// SetIfAbsent stores a key-value pair in store only if the key does not exist
+func SetIfAbsent(store Storer, key string, value string) error {
+	writer, ok := store.(ConditionalWriter)
+	if !ok {
+		return fmt.Errorf("store %T does not support conditional writes: %w", store, ErrUnsupported)
+	}
+	return writer.SetIfAbsent(key, value)
+}
+
+// DeleteIfValue removes a key from store only if it holds value
+func DeleteIfValue(store Storer, key string, value string) error {
+	writer, ok := store.(ConditionalWriter)
+	if !ok {
+		return fmt.Errorf("store %T does not support conditional writes: %w", store, ErrUnsupported)
+	}
+	return writer.DeleteIfValue(key, value)
+}
+
+// SetWithLease stores a key-value pair in store, attached to a lease
+func SetWithLease(store Storer, key string, value string, leaseID int64) error {
+	writer, ok := store.(LeaseWriter)
+	if !ok {
+		return fmt.Errorf("store %T does not support leases: %w", store, ErrUnsupported)
+	}
+	return writer.SetWithLease(key, value, leaseID)
+}
+
+// Batch commits writes to store as one change, only if every check holds
+func Batch(store Storer, checks []Check, writes []Write) error {
+	batcher, ok := store.(Batcher)
+	if !ok {
+		return fmt.Errorf("store %T does not support batches: %w", store, ErrUnsupported)
+	}
+	return batcher.Batch(checks, writes)
+}
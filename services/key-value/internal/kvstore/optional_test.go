@@ -0,0 +1,42 @@
+package kvstore
+
+import (
+	"errors"
+	"testing"
+)
+
+// plainStore implements Storer and none of the optional interfaces
+type plainStore struct {
+	Storer
+}
+
+func TestLayer_Unsupported(t *testing.T) {
+	layer := Layer{Next: plainStore{NewInMemoryStore()}}
+
+	if _, _, err := layer.Scan("", "", 10); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Scan() error = %v, want %v", err, ErrUnsupported)
+	}
+	if _, err := layer.Snapshot(); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Snapshot() error = %v, want %v", err, ErrUnsupported)
+	}
+	if err := layer.Restore(nil); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Restore() error = %v, want %v", err, ErrUnsupported)
+	}
+	if err := layer.SetIfAbsent("k", "v"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("SetIfAbsent() error = %v, want %v", err, ErrUnsupported)
+	}
+	if err := layer.DeleteIfValue("k", "v"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("DeleteIfValue() error = %v, want %v", err, ErrUnsupported)
+	}
+	if _, err := layer.StaleGet("k"); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("StaleGet() error = %v, want %v", err, ErrUnsupported)
+	}
+	if err := SetWithLease(layer, "k", "v", 1); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("SetWithLease() error = %v, want %v", err, ErrUnsupported)
+	}
+
+	supported := Layer{Next: NewInMemoryStore()}
+	if _, _, err := supported.Scan("", "", 10); err != nil {
+		t.Errorf("Scan() error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,74 @@
+package kvstore
+
+import (
+	"fmt"
+	"key-value/services/key-value/internal/encryption"
+	"key-value/shared/limits"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultBackend is the backend used when none is configured
+const DefaultBackend = "memory"
+
+// BackendOptions configures a backend. Each backend reads the options that apply to it and ignores the others.
+type BackendOptions struct {
+	Limits limits.Limits      // Keys and values breaking the limits are rejected
+	Shards int                // Number of shards of the sharded backend, 0 uses its default
+	Dir    string             // Directory of the file backend, required by it
+	Cipher *encryption.Cipher // Seals the data the file backend writes to disk, required by it
+}
+
+// Backend opens a store. The store should implement Snapshotter, ConditionalWriter and Scanner as well, which the
+// service relies on; the storetest package checks a backend against all of them.
+type Backend func(options BackendOptions) (Storer, error)
+
+var (
+	backendsMutex sync.RWMutex
+	backends      = make(map[string]Backend)
+)
+
+// Register makes a backend available by name, usually from the init function of the file defining it.
+// It panics when the name is empty or taken, as both are programming errors.
+func Register(name string, backend Backend) {
+	backendsMutex.Lock()
+	defer backendsMutex.Unlock()
+	if name == "" || backend == nil {
+		panic("kvstore: backend name and function are required")
+	}
+	if _, taken := backends[name]; taken {
+		panic("kvstore: backend " + name + " is registered twice")
+	}
+	backends[name] = backend
+}
+
+// Backends returns the names of the registered backends in order
+func Backends() []string {
+	backendsMutex.RLock()
+	defer backendsMutex.RUnlock()
+	names := make([]string, 0, len(backends))
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Open opens a store with the named backend, DefaultBackend when the name is empty
+func Open(name string, options BackendOptions) (Storer, error) {
+	if name == "" {
+		name = DefaultBackend
+	}
+	backendsMutex.RLock()
+	backend, ok := backends[name]
+	backendsMutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown store backend %q, expected one of %s", name, strings.Join(Backends(), ", "))
+	}
+	store, err := backend(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s store: %w", name, err)
+	}
+	return store, nil
+}
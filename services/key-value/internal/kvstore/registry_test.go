@@ -0,0 +1,68 @@
+package kvstore_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"key-value/services/key-value/internal/encryption"
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/services/key-value/internal/kvstore/storetest"
+	"key-value/shared/limits"
+)
+
+// TestBackends runs the conformance suite against every registered backend
+func TestBackends(t *testing.T) {
+	cipher, err := encryption.NewCipher(bytes.Repeat([]byte{9}, encryption.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range kvstore.Backends() {
+		t.Run(name, func(t *testing.T) {
+			storetest.Run(t, func(t *testing.T, l limits.Limits) kvstore.Storer {
+				store, err := kvstore.Open(name, kvstore.BackendOptions{Limits: l, Shards: 4, Dir: t.TempDir(), Cipher: cipher})
+				if err != nil {
+					t.Fatalf("Open(%q) error = %v", name, err)
+				}
+				if closer, ok := store.(io.Closer); ok {
+					t.Cleanup(func() { closer.Close() })
+				}
+				return store
+			})
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	store, err := kvstore.Open("", kvstore.BackendOptions{Limits: limits.Default()})
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if _, ok := store.(*kvstore.InMemoryStore); !ok {
+		t.Errorf("Open() of the default backend = %T, want *kvstore.InMemoryStore", store)
+	}
+
+	_, err = kvstore.Open("papyrus", kvstore.BackendOptions{})
+	if err == nil || !strings.Contains(err.Error(), `unknown store backend "papyrus", expected one of file, memory, sharded`) {
+		t.Errorf("Open(papyrus) error = %v", err)
+	}
+
+	_, err = kvstore.Open("file", kvstore.BackendOptions{})
+	if err == nil || !strings.Contains(err.Error(), "failed to open file store: a directory is required") {
+		t.Errorf("Open(file) without a directory error = %v", err)
+	}
+	_, err = kvstore.Open("file", kvstore.BackendOptions{Dir: t.TempDir()})
+	if err == nil || !strings.Contains(err.Error(), "failed to open file store: a data key is required") {
+		t.Errorf("Open(file) without a data key error = %v", err)
+	}
+}
+
+func TestRegister_Twice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a name twice should panic")
+		}
+	}()
+	kvstore.Register(kvstore.DefaultBackend, func(kvstore.BackendOptions) (kvstore.Storer, error) { return nil, nil })
+}
@@ -0,0 +1,47 @@
+package kvstore
+
+import "strings"
+
+// ReservedPrefix starts the keys in which the layers of the service keep their own state next to the data, like the
+// metadata of keys, so it is replicated and persisted with the data. CheckKey rejects reserved keys, keeping clients
+// away from them, and stores do not apply their limits to them.
+const ReservedPrefix = "\x00kv/"
+
+// reservedEnd sorts after every reserved key, which continue the prefix with the name of the layer keeping them
+const reservedEnd = ReservedPrefix + "\xff"
+
+// IsReserved reports whether key is kept by a layer of the service rather than by clients
+func IsReserved(key string) bool {
+	return strings.HasPrefix(key, ReservedPrefix)
+}
+
+// ScanPublic scans store like Scan, leaving out the reserved keys. They sort together, so it skips them at once.
+func ScanPublic(store Storer, prefix string, startAfter string, limit int) ([]KeyValue, bool, error) {
+	if IsReserved(prefix) {
+		return nil, false, nil
+	}
+	pairs, more, err := Scan(store, prefix, startAfter, limit)
+	if err != nil {
+		return nil, false, err
+	}
+	for i, pair := range pairs {
+		if IsReserved(pair.Key) {
+			rest, more, err := Scan(store, prefix, reservedEnd, limit-i)
+			if err != nil {
+				return nil, false, err
+			}
+			return append(pairs[:i:i], rest...), more, nil
+		}
+	}
+	return pairs, more, nil
+}
+
+// PublicData removes the reserved keys from a snapshot of the store and returns it
+func PublicData(data map[string]string) map[string]string {
+	for key := range data {
+		if IsReserved(key) {
+			delete(data, key)
+		}
+	}
+	return data
+}
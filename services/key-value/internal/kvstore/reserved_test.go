@@ -0,0 +1,66 @@
+package kvstore
+
+import (
+	"errors"
+	"key-value/shared/limits"
+	"testing"
+)
+
+func TestCheckKey_Reserved(t *testing.T) {
+	if err := CheckKey(limits.Limits{}, ReservedPrefix+"meta/version"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("CheckKey(reserved) error = %v, want %v", err, ErrInvalidKey)
+	}
+	if err := CheckKey(limits.Limits{}, "\x00other"); err != nil {
+		t.Errorf("CheckKey(other) error = %v, want nil", err)
+	}
+}
+
+func TestScanPublic(t *testing.T) {
+	store := NewInMemoryStore()
+	for _, key := range []string{"\x00", "\x00a", ReservedPrefix + "a", ReservedPrefix + "b", "a", "b"} {
+		if err := store.Set(key, "v"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		prefix     string
+		startAfter string
+		limit      int
+		want       []string
+		more       bool
+	}{
+		{"all", "", "", 10, []string{"\x00", "\x00a", "a", "b"}, false},
+		{"page before the reserved keys", "", "", 2, []string{"\x00", "\x00a"}, true},
+		{"page across the reserved keys", "", "", 3, []string{"\x00", "\x00a", "a"}, true},
+		{"start inside the reserved keys", "", ReservedPrefix + "a", 10, []string{"a", "b"}, false},
+		{"prefix of the reserved keys", "\x00", "", 10, []string{"\x00", "\x00a"}, false},
+		{"reserved prefix", ReservedPrefix, "", 10, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairs, more, err := ScanPublic(store, tt.prefix, tt.startAfter, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var keys []string
+			for _, pair := range pairs {
+				keys = append(keys, pair.Key)
+			}
+			if len(keys) != len(tt.want) || more != tt.more {
+				t.Fatalf("ScanPublic() = %q, %v, want %q, %v", keys, more, tt.want, tt.more)
+			}
+			for i := range keys {
+				if keys[i] != tt.want[i] {
+					t.Fatalf("ScanPublic() = %q, want %q", keys, tt.want)
+				}
+			}
+		})
+	}
+
+	data, _ := store.Snapshot()
+	if got := PublicData(data); len(got) != 4 {
+		t.Errorf("PublicData() kept %d keys, want 4", len(got))
+	}
+}
@@ -0,0 +1,163 @@
+package kvstore
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+// DefaultShards is the number of shards of a ShardedStore when none is given
+const DefaultShards = 16
+
+func init() {
+	Register("sharded", func(options BackendOptions) (Storer, error) {
+		return NewShardedStore(options.Shards, WithLimits(options.Limits)), nil
+	})
+}
+
+// ShardedStore spreads keys over several InMemoryStores by hash, so writes to different shards do not wait for
+// each other. Snapshots and restores hold every shard at once, keeping them consistent.
+type ShardedStore struct {
+	// mutex is held for reading by calls on single shards and for writing by calls on all of them
+	mutex  sync.RWMutex
+	shards []*InMemoryStore
+}
+
+// NewShardedStore creates a store of shards in-memory shards, DefaultShards when not positive.
+// The options apply to every shard.
+func NewShardedStore(shards int, opts ...Option) *ShardedStore {
+	if shards <= 0 {
+		shards = DefaultShards
+	}
+	s := &ShardedStore{shards: make([]*InMemoryStore, shards)}
+	for i := range s.shards {
+		s.shards[i] = NewInMemoryStore(opts...)
+	}
+	return s
+}
+
+// shard returns the shard holding key
+func (s *ShardedStore) shard(key string) *InMemoryStore {
+	return s.shards[s.shardIndex(key)]
+}
+
+func (s *ShardedStore) shardIndex(key string) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	return int(hash.Sum32() % uint32(len(s.shards)))
+}
+
+// Get retrieves a value by key
+func (s *ShardedStore) Get(key string) (string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.shard(key).Get(key)
+}
+
+// Set stores a key-value pair
+func (s *ShardedStore) Set(key string, value string) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.shard(key).Set(key, value)
+}
+
+// Delete removes a key-value pair, doing nothing when the key does not exist
+func (s *ShardedStore) Delete(key string) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.shard(key).Delete(key)
+}
+
+// Increment atomically adds delta to the integer stored at key and returns the new value
+func (s *ShardedStore) Increment(key string, delta int64) (int64, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.shard(key).Increment(key, delta)
+}
+
+// SetIfAbsent stores a key-value pair only if the key does not exist
+func (s *ShardedStore) SetIfAbsent(key string, value string) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.shard(key).SetIfAbsent(key, value)
+}
+
+// DeleteIfValue removes a key only if it currently holds value
+func (s *ShardedStore) DeleteIfValue(key string, value string) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.shard(key).DeleteIfValue(key, value)
+}
+
+// Snapshot returns a copy of every key-value pair, taken while no shard changes
+func (s *ShardedStore) Snapshot() (map[string]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	data := make(map[string]string)
+	for _, shard := range s.shards {
+		part, err := shard.Snapshot()
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range part {
+			data[key] = value
+		}
+	}
+	return data, nil
+}
+
+// Restore replaces the contents of every shard with a copy of data
+func (s *ShardedStore) Restore(data map[string]string) error {
+	parts := make([]map[string]string, len(s.shards))
+	for i := range parts {
+		parts[i] = make(map[string]string)
+	}
+	for key, value := range data {
+		parts[s.shardIndex(key)][key] = value
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for i, shard := range s.shards {
+		if err := shard.Restore(parts[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Scan lists matching pairs in key order, merging the first limit pairs of every shard
+func (s *ShardedStore) Scan(prefix string, startAfter string, limit int) ([]KeyValue, bool, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var pages [][]KeyValue
+	more, found := false, 0
+	for _, shard := range s.shards {
+		page, shardMore, err := shard.Scan(prefix, startAfter, limit)
+		if err != nil {
+			return nil, false, err
+		}
+		pages = append(pages, page)
+		more = more || shardMore
+		found += len(page)
+	}
+
+	// Callers choose the limit, which may be huge, so room is only made for what the shards found
+	pairs := make([]KeyValue, 0, min(max(limit, 0), found))
+	for {
+		next := -1
+		for i, page := range pages {
+			if len(page) > 0 && (next < 0 || page[0].Key < pages[next][0].Key) {
+				next = i
+			}
+		}
+		if next < 0 {
+			return pairs, more, nil
+		}
+		if len(pairs) == limit {
+			return pairs, true, nil
+		}
+		pairs = append(pairs, pages[next][0])
+		pages[next] = pages[next][1:]
+	}
+}
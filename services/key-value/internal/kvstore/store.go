@@ -1,29 +1,104 @@
 package kvstore
 
 import (
-	"errors"
+	"fmt"
+	"key-value/shared/limits"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 )
 
-// Storer interface defines the methods for the key-value store
+// Storer interface defines the methods for the key-value store.
+// Get returns ErrNotFound for missing keys; see errors.go for the other sentinel errors.
 type Storer interface {
 	Get(key string) (string, error)
 	Set(key string, value string) error
 	Delete(key string) error
+	Increment(key string, delta int64) (int64, error)
+}
+
+// Snapshotter is implemented by stores that can copy out and replace their whole contents.
+// Replication uses it to ship a consistent image of the data to other nodes.
+type Snapshotter interface {
+	Snapshot() (map[string]string, error)
+	Restore(data map[string]string) error
+}
+
+// ConditionalWriter is implemented by stores whose writes can depend on the current value.
+// A write whose condition does not hold returns ErrConflict and changes nothing.
+type ConditionalWriter interface {
+	SetIfAbsent(key string, value string) error
+	DeleteIfValue(key string, value string) error
+}
+
+// Batcher is implemented by replicated stores that can commit several writes as one change.
+// Batch applies the writes in order only if every check holds, returning ErrConflict and changing nothing otherwise.
+type Batcher interface {
+	Batch(checks []Check, writes []Write) error
+}
+
+// ConsistentReader is implemented by replicated stores that can serve a read in either mode, whatever their default.
+// A linearizable read sees every write committed before it; a stale read returns the local copy.
+type ConsistentReader interface {
+	LinearizableGet(key string) (string, error)
+	StaleGet(key string) (string, error)
+}
+
+// LeaseWriter is implemented by stores that can attach keys to leases, deleting them when the lease ends.
+// Writing to a lease that ended returns an error.
+type LeaseWriter interface {
+	SetWithLease(key string, value string, leaseID int64) error
+}
+
+// KeyValue is a key and its value as returned by Scan
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// Scanner is implemented by stores that can list their keys in order
+type Scanner interface {
+	// Scan returns up to limit pairs whose key starts with prefix and sorts after startAfter, in key order.
+	// more reports whether further pairs match.
+	Scan(prefix string, startAfter string, limit int) (pairs []KeyValue, more bool, err error)
+}
+
+func init() {
+	Register(DefaultBackend, func(options BackendOptions) (Storer, error) {
+		return NewInMemoryStore(WithLimits(options.Limits)), nil
+	})
 }
 
 // InMemoryStore implements the Storer interface with a thread safe map
 type InMemoryStore struct {
-	mutex sync.RWMutex
-	store map[string]string
+	mutex  sync.RWMutex
+	store  map[string]string
+	limits *limits.Limits
+}
+
+// Option configures an InMemoryStore
+type Option func(*InMemoryStore)
+
+// WithLimits makes the store reject keys and values that break the limits.
+// Without it the store accepts anything.
+func WithLimits(l limits.Limits) Option {
+	return func(s *InMemoryStore) {
+		s.limits = &l
+	}
 }
 
 // NewInMemoryStore creates a new InMemoryStore
-func NewInMemoryStore() *InMemoryStore {
-	return &InMemoryStore{
+func NewInMemoryStore(opts ...Option) *InMemoryStore {
+	s := &InMemoryStore{
 		mutex: sync.RWMutex{},
 		store: make(map[string]string),
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // Get retrieves a value by key
@@ -31,13 +106,19 @@ func (s *InMemoryStore) Get(key string) (string, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 	if _, ok := s.store[key]; !ok {
-		return "", errors.New("key not found")
+		return "", ErrNotFound
 	}
 	return s.store[key], nil
 }
 
 // Set stores a key-value pair as a upsert operation
 func (s *InMemoryStore) Set(key string, value string) error {
+	if s.limits != nil && !IsReserved(key) {
+		if err := CheckLimits(*s.limits, key, value); err != nil {
+			return err
+		}
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	s.store[key] = value
@@ -51,3 +132,119 @@ func (s *InMemoryStore) Delete(key string) error {
 	delete(s.store, key)
 	return nil
 }
+
+// Increment atomically adds delta to the integer stored at key and returns the new value.
+// A missing key starts at 0; a value that is not a base 10 int64 returns ErrNotNumeric.
+func (s *InMemoryStore) Increment(key string, delta int64) (int64, error) {
+	if s.limits != nil && !IsReserved(key) {
+		if err := CheckKey(*s.limits, key); err != nil {
+			return 0, err
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored, found := s.store[key]
+	current, err := increment(stored, found, delta)
+	if err != nil {
+		return 0, err
+	}
+	s.store[key] = strconv.FormatInt(current, 10)
+	return current, nil
+}
+
+// increment adds delta to a stored base 10 int64, a value that is not found counting as 0
+func increment(stored string, found bool, delta int64) (int64, error) {
+	var current int64
+	if found {
+		parsed, err := strconv.ParseInt(stored, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %q", ErrNotNumeric, stored)
+		}
+		current = parsed
+	}
+
+	if (delta > 0 && current > math.MaxInt64-delta) || (delta < 0 && current < math.MinInt64-delta) {
+		return 0, fmt.Errorf("%w: %d + %d", ErrOverflow, current, delta)
+	}
+	return current + delta, nil
+}
+
+// Snapshot returns a copy of every key-value pair
+func (s *InMemoryStore) Snapshot() (map[string]string, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	data := make(map[string]string, len(s.store))
+	for key, value := range s.store {
+		data[key] = value
+	}
+	return data, nil
+}
+
+// Restore replaces the contents of the store with a copy of data
+func (s *InMemoryStore) Restore(data map[string]string) error {
+	store := make(map[string]string, len(data))
+	for key, value := range data {
+		store[key] = value
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.store = store
+	return nil
+}
+
+// Scan lists matching pairs in key order. The map is unordered, so every call sorts the matching keys.
+// A limit below 1 lists nothing, only reporting whether any pair matches.
+func (s *InMemoryStore) Scan(prefix string, startAfter string, limit int) ([]KeyValue, bool, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var keys []string
+	for key := range s.store {
+		if strings.HasPrefix(key, prefix) && key > startAfter {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	more := len(keys) > max(limit, 0)
+	if more {
+		keys = keys[:max(limit, 0)]
+	}
+	pairs := make([]KeyValue, len(keys))
+	for i, key := range keys {
+		pairs[i] = KeyValue{Key: key, Value: s.store[key]}
+	}
+	return pairs, more, nil
+}
+
+// SetIfAbsent stores a key-value pair only if the key does not exist
+func (s *InMemoryStore) SetIfAbsent(key string, value string) error {
+	if s.limits != nil && !IsReserved(key) {
+		if err := CheckLimits(*s.limits, key, value); err != nil {
+			return err
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, exists := s.store[key]; exists {
+		return fmt.Errorf("key %s already exists: %w", key, ErrConflict)
+	}
+	s.store[key] = value
+	return nil
+}
+
+// DeleteIfValue removes a key only if it currently holds value
+func (s *InMemoryStore) DeleteIfValue(key string, value string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if current, exists := s.store[key]; !exists || current != value {
+		return fmt.Errorf("key %s does not hold the expected value: %w", key, ErrConflict)
+	}
+	delete(s.store, key)
+	return nil
+}
@@ -1,6 +1,14 @@
 package kvstore
 
 import (
+	"errors"
+	"key-value/shared/limits"
+	"key-value/shared/vclock"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -60,8 +68,8 @@ func TestInMemoryStore_Get(t *testing.T) {
 				if err == nil {
 					t.Errorf("Get() error = nil, want error")
 				}
-				if err.Error() != "key not found" {
-					t.Errorf("Get() error = %v, want 'key not found'", err)
+				if !errors.Is(err, ErrNotFound) {
+					t.Errorf("Get() error = %v, want ErrNotFound", err)
 				}
 			} else {
 				if err != nil {
@@ -121,3 +129,239 @@ func TestInMemoryStore_Delete(t *testing.T) {
 		t.Error("Other keys should not be affected by delete operation")
 	}
 }
+
+func TestInMemoryStore_SetWithLimits(t *testing.T) {
+	store := NewInMemoryStore(WithLimits(limits.Limits{
+		MaxKeyLength: 4,
+		MaxValueSize: 4,
+		KeyPattern:   regexp.MustCompile(`^[a-z]+$`),
+	}))
+
+	tests := []struct {
+		name      string
+		key       string
+		value     string
+		wantError error
+	}{
+		{"within limits", "key", "1234", nil},
+		{"empty key", "", "v", ErrInvalidKey},
+		{"key too long", "abcde", "v", ErrInvalidKey},
+		{"key characters", "KEY", "v", ErrInvalidKey},
+		{"value too large", "key", "12345", ErrTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := store.Set(tt.key, tt.value)
+			if tt.wantError == nil {
+				if err != nil {
+					t.Errorf("Set() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantError) {
+				t.Errorf("Set() error = %v, want %v", err, tt.wantError)
+			}
+			if stored, exists := store.store[tt.key]; exists && stored == tt.value {
+				t.Errorf("Rejected value for key %q should not have been stored", tt.key)
+			}
+		})
+	}
+}
+
+func TestInMemoryStore_Increment(t *testing.T) {
+	store := NewInMemoryStore()
+
+	// Setup test data
+	store.Set("counter", "41")
+	store.Set("text", "hello")
+	store.Set("max", strconv.FormatInt(math.MaxInt64, 10))
+
+	tests := []struct {
+		name      string
+		key       string
+		delta     int64
+		wantValue int64
+		wantError error
+	}{
+		{"increment existing", "counter", 1, 42, nil},
+		{"decrement existing", "counter", -2, 40, nil},
+		{"missing key starts at zero", "new_counter", 5, 5, nil},
+		{"non numeric value", "text", 1, 0, ErrNotNumeric},
+		{"overflow", "max", 1, 0, ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := store.Increment(tt.key, tt.delta)
+			if tt.wantError != nil {
+				if !errors.Is(err, tt.wantError) {
+					t.Errorf("Increment() error = %v, want %v", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Increment() error = %v, want nil", err)
+			}
+			if value != tt.wantValue {
+				t.Errorf("Increment() value = %d, want %d", value, tt.wantValue)
+			}
+			if stored := store.store[tt.key]; stored != strconv.FormatInt(tt.wantValue, 10) {
+				t.Errorf("Expected store[%s] = %d, got %s", tt.key, tt.wantValue, stored)
+			}
+		})
+	}
+}
+
+func TestInMemoryStore_IncrementConcurrent(t *testing.T) {
+	store := NewInMemoryStore()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if _, err := store.Increment("hits", 1); err != nil {
+					t.Errorf("Increment() error = %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if value, _ := store.Get("hits"); value != "5000" {
+		t.Errorf("Expected 5000 increments, got %s", value)
+	}
+}
+
+func TestInMemoryStore_SnapshotRestore(t *testing.T) {
+	store := NewInMemoryStore()
+	store.Set("a", "1")
+	store.Set("b", "2")
+
+	snapshot, err := store.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+
+	// Changes after the snapshot must not leak into it
+	store.Set("c", "3")
+	if _, exists := snapshot["c"]; exists {
+		t.Error("Snapshot should be a copy of the store")
+	}
+
+	other := NewInMemoryStore()
+	other.Set("stale", "x")
+	if err := other.Restore(snapshot); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	if len(other.store) != 2 || other.store["a"] != "1" || other.store["b"] != "2" {
+		t.Errorf("Restore() store = %v, want a=1 b=2", other.store)
+	}
+}
+
+func TestInMemoryStore_Scan(t *testing.T) {
+	store := NewInMemoryStore()
+	for _, key := range []string{"user:3", "user:1", "order:1", "user:2", "user:10"} {
+		store.Set(key, "v-"+key)
+	}
+
+	tests := []struct {
+		name       string
+		prefix     string
+		startAfter string
+		limit      int
+		wantKeys   []string
+		wantMore   bool
+	}{
+		{"all keys in order", "", "", 10, []string{"order:1", "user:1", "user:10", "user:2", "user:3"}, false},
+		{"prefix", "user:", "", 10, []string{"user:1", "user:10", "user:2", "user:3"}, false},
+		{"limit", "user:", "", 2, []string{"user:1", "user:10"}, true},
+		{"resume after cursor", "user:", "user:10", 2, []string{"user:2", "user:3"}, false},
+		{"no match", "missing", "", 10, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairs, more, err := store.Scan(tt.prefix, tt.startAfter, tt.limit)
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+			var keys []string
+			for _, pair := range pairs {
+				keys = append(keys, pair.Key)
+				if pair.Value != "v-"+pair.Key {
+					t.Errorf("Scan() value for %s = %s", pair.Key, pair.Value)
+				}
+			}
+			if strings.Join(keys, ",") != strings.Join(tt.wantKeys, ",") {
+				t.Errorf("Scan() keys = %v, want %v", keys, tt.wantKeys)
+			}
+			if more != tt.wantMore {
+				t.Errorf("Scan() more = %v, want %v", more, tt.wantMore)
+			}
+		})
+	}
+}
+
+func TestInMemoryStore_ConditionalWrites(t *testing.T) {
+	store := NewInMemoryStore()
+	store.Set("existing", "v1")
+
+	tests := []struct {
+		name      string
+		write     func() error
+		wantError error
+	}{
+		{"set if absent on new key", func() error { return store.SetIfAbsent("new", "x") }, nil},
+		{"set if absent on existing key", func() error { return store.SetIfAbsent("existing", "x") }, ErrConflict},
+		{"delete if value differs", func() error { return store.DeleteIfValue("existing", "v2") }, ErrConflict},
+		{"delete if value on missing key", func() error { return store.DeleteIfValue("missing", "") }, ErrConflict},
+		{"delete if value matches", func() error { return store.DeleteIfValue("existing", "v1") }, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.write(); !errors.Is(err, tt.wantError) {
+				t.Errorf("error = %v, want %v", err, tt.wantError)
+			}
+		})
+	}
+
+	if _, exists := store.store["existing"]; exists {
+		t.Errorf("existing should have been deleted")
+	}
+	if store.store["new"] != "x" {
+		t.Errorf("store[new] = %q, want x", store.store["new"])
+	}
+}
+
+func TestVersionedStore_Put(t *testing.T) {
+	store := NewVersionedStore()
+
+	store.Put("k", []vclock.Version{{Value: "1", Clock: vclock.Clock{"a": 1}}})
+	store.Put("k", []vclock.Version{{Value: "b", Clock: vclock.Clock{"a": 1, "b": 1}}})
+	siblings := store.Put("k", []vclock.Version{{Value: "c", Clock: vclock.Clock{"a": 1, "c": 1}}})
+	if len(siblings) != 2 {
+		t.Fatalf("Put() = %v, want the two concurrent writes", siblings)
+	}
+
+	// A version descending from both siblings replaces them
+	store.Put("k", []vclock.Version{{Deleted: true, Clock: vclock.Clock{"a": 1, "b": 1, "c": 1}}})
+	siblings = store.Get("k")
+	if len(siblings) != 1 || !siblings[0].Deleted {
+		t.Errorf("Get() = %v, want a single tombstone", siblings)
+	}
+
+	// Returned clocks are copies
+	siblings[0].Clock["a"] = 99
+	if store.Get("k")[0].Clock["a"] != 1 {
+		t.Errorf("Get() returned the stored clock")
+	}
+
+	if got := store.Get("missing"); len(got) != 0 {
+		t.Errorf("Get(missing) = %v, want no versions", got)
+	}
+}
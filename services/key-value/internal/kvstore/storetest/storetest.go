@@ -0,0 +1,311 @@
+// Package storetest is the conformance suite of kvstore backends. Every backend runs it from its tests:
+//
+//	func TestMyStore(t *testing.T) {
+//		storetest.Run(t, func(t *testing.T, l limits.Limits) kvstore.Storer {
+//			return NewMyStore(l)
+//		})
+//	}
+//
+// Besides kvstore.Storer, the suite requires the optional interfaces the service relies on: kvstore.Snapshotter,
+// kvstore.ConditionalWriter and kvstore.Scanner.
+package storetest
+
+import (
+	"errors"
+	"fmt"
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/shared/limits"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Open creates an empty store for one test, rejecting keys and values that break l
+type Open func(t *testing.T, l limits.Limits) kvstore.Storer
+
+// suiteLimits are small enough to be hit by the tests
+var suiteLimits = limits.Limits{MaxKeyLength: 32, MaxValueSize: 64}
+
+// Run checks that the stores created by open behave like every backend must
+func Run(t *testing.T, open Open) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, open Open)
+	}{
+		{"GetSetDelete", testGetSetDelete},
+		{"Limits", testLimits},
+		{"Increment", testIncrement},
+		{"IncrementConcurrent", testIncrementConcurrent},
+		{"ConditionalWrites", testConditionalWrites},
+		{"SnapshotRestore", testSnapshotRestore},
+		{"Scan", testScan},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, open)
+		})
+	}
+}
+
+// openWith opens a store with the test limits, failing unless it implements T
+func openWith[T any](t *testing.T, open Open) T {
+	t.Helper()
+	store := open(t, suiteLimits)
+	typed, ok := store.(T)
+	if !ok {
+		var want *T
+		t.Fatalf("store %T does not implement %s", store, reflect.TypeOf(want).Elem())
+	}
+	return typed
+}
+
+func testGetSetDelete(t *testing.T, open Open) {
+	store := openWith[kvstore.Storer](t, open)
+
+	if _, err := store.Get("missing"); !errors.Is(err, kvstore.ErrNotFound) {
+		t.Errorf("Get(missing) error = %v, want ErrNotFound", err)
+	}
+	for _, kv := range [][2]string{{"a", "1"}, {"empty", ""}, {"a", "overwritten"}, {"binary\x00key", "\xff\x00"}} {
+		if err := store.Set(kv[0], kv[1]); err != nil {
+			t.Fatalf("Set(%q) error = %v", kv[0], err)
+		}
+		if got, err := store.Get(kv[0]); err != nil || got != kv[1] {
+			t.Errorf("Get(%q) = %q, %v, want %q", kv[0], got, err, kv[1])
+		}
+	}
+
+	if err := store.Delete("a"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Get("a"); !errors.Is(err, kvstore.ErrNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete("missing"); err != nil {
+		t.Errorf("Delete(missing) error = %v, want nil", err)
+	}
+	if got, err := store.Get("empty"); err != nil || got != "" {
+		t.Errorf("Get(empty) = %q, %v, other keys must be kept", got, err)
+	}
+}
+
+func testLimits(t *testing.T, open Open) {
+	store := openWith[kvstore.ConditionalWriter](t, open)
+	storer := store.(kvstore.Storer)
+	longKey := strings.Repeat("k", suiteLimits.MaxKeyLength+1)
+	largeValue := strings.Repeat("v", suiteLimits.MaxValueSize+1)
+
+	tests := []struct {
+		name  string
+		write func() error
+		want  error
+	}{
+		{"set long key", func() error { return storer.Set(longKey, "v") }, kvstore.ErrInvalidKey},
+		{"set empty key", func() error { return storer.Set("", "v") }, kvstore.ErrInvalidKey},
+		{"set large value", func() error { return storer.Set("k", largeValue) }, kvstore.ErrTooLarge},
+		{"set if absent large value", func() error { return store.SetIfAbsent("k", largeValue) }, kvstore.ErrTooLarge},
+		{"increment long key", func() error { _, err := storer.Increment(longKey, 1); return err }, kvstore.ErrInvalidKey},
+		{"set at the limits", func() error {
+			return storer.Set(strings.Repeat("k", suiteLimits.MaxKeyLength), strings.Repeat("v", suiteLimits.MaxValueSize))
+		}, nil},
+		{"set reserved key over the limits", func() error {
+			return storer.Set(kvstore.ReservedPrefix+strings.Repeat("k", suiteLimits.MaxKeyLength), largeValue)
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.write(); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if _, err := storer.Get("k"); !errors.Is(err, kvstore.ErrNotFound) {
+		t.Errorf("rejected writes must not store anything, Get() error = %v", err)
+	}
+}
+
+func testIncrement(t *testing.T, open Open) {
+	store := openWith[kvstore.Storer](t, open)
+	if err := store.Set("text", "abc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("max", strconv.FormatInt(math.MaxInt64, 10)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		delta   int64
+		want    int64
+		wantErr error
+	}{
+		{"missing key starts at zero", "counter", 5, 5, nil},
+		{"adds to the value", "counter", -7, -2, nil},
+		{"not a number", "text", 1, 0, kvstore.ErrNotNumeric},
+		{"overflow", "max", 1, 0, kvstore.ErrOverflow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.Increment(tt.key, tt.delta)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("Increment() = %d, %v, want %d, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+	if got, _ := store.Get("counter"); got != "-2" {
+		t.Errorf("Get(counter) = %q, want -2", got)
+	}
+	if got, _ := store.Get("max"); got != strconv.FormatInt(math.MaxInt64, 10) {
+		t.Errorf("a failed increment changed the value to %q", got)
+	}
+}
+
+func testIncrementConcurrent(t *testing.T, open Open) {
+	store := openWith[kvstore.Storer](t, open)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if _, err := store.Increment("hits", 1); err != nil {
+					t.Error(err)
+					return
+				}
+				// Writes to other keys run alongside
+				store.Set(fmt.Sprintf("key-%d", j), "x")
+			}
+		}()
+	}
+	wg.Wait()
+	if got, _ := store.Get("hits"); got != "1000" {
+		t.Errorf("Get(hits) = %q after 1000 concurrent increments", got)
+	}
+}
+
+func testConditionalWrites(t *testing.T, open Open) {
+	store := openWith[kvstore.ConditionalWriter](t, open)
+	storer := store.(kvstore.Storer)
+	if err := storer.Set("existing", "v1"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		write func() error
+		want  error
+	}{
+		{"set if absent on new key", func() error { return store.SetIfAbsent("new", "x") }, nil},
+		{"set if absent on existing key", func() error { return store.SetIfAbsent("existing", "x") }, kvstore.ErrConflict},
+		{"delete if value differs", func() error { return store.DeleteIfValue("existing", "v2") }, kvstore.ErrConflict},
+		{"delete if value on missing key", func() error { return store.DeleteIfValue("missing", "") }, kvstore.ErrConflict},
+		{"delete if value matches", func() error { return store.DeleteIfValue("existing", "v1") }, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.write(); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+	if _, err := storer.Get("existing"); !errors.Is(err, kvstore.ErrNotFound) {
+		t.Errorf("Get(existing) error = %v, want ErrNotFound", err)
+	}
+	if got, _ := storer.Get("new"); got != "x" {
+		t.Errorf("Get(new) = %q, want x", got)
+	}
+}
+
+func testSnapshotRestore(t *testing.T, open Open) {
+	store := openWith[kvstore.Snapshotter](t, open)
+	storer := store.(kvstore.Storer)
+	for i := 0; i < 50; i++ {
+		storer.Set(fmt.Sprintf("key-%d", i), strconv.Itoa(i))
+	}
+
+	snapshot, err := store.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if len(snapshot) != 50 || snapshot["key-7"] != "7" {
+		t.Fatalf("Snapshot() has %d pairs, key-7 = %q", len(snapshot), snapshot["key-7"])
+	}
+	// Snapshots are copies, in both directions
+	storer.Set("later", "x")
+	snapshot["key-0"] = "changed"
+	if _, ok := snapshot["later"]; ok {
+		t.Error("a write after Snapshot() changed the snapshot")
+	}
+	if got, _ := storer.Get("key-0"); got != "0" {
+		t.Errorf("changing the snapshot changed the store to %q", got)
+	}
+
+	data := map[string]string{"a": "1", "b": "2"}
+	if err := store.Restore(data); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	data["a"] = "changed"
+	restored, err := store.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+	if !reflect.DeepEqual(restored, map[string]string{"a": "1", "b": "2"}) {
+		t.Errorf("Restore() replaced the data with %v, want a=1 b=2", restored)
+	}
+	if _, err := storer.Get("later"); !errors.Is(err, kvstore.ErrNotFound) {
+		t.Errorf("Get(later) after Restore() error = %v, want ErrNotFound", err)
+	}
+}
+
+func testScan(t *testing.T, open Open) {
+	store := openWith[kvstore.Scanner](t, open)
+	storer := store.(kvstore.Storer)
+	for _, key := range []string{"user:3", "user:1", "order:1", "user:2", "user:10"} {
+		storer.Set(key, "v-"+key)
+	}
+
+	tests := []struct {
+		name       string
+		prefix     string
+		startAfter string
+		limit      int
+		wantKeys   []string
+		wantMore   bool
+	}{
+		{"all keys in order", "", "", 10, []string{"order:1", "user:1", "user:10", "user:2", "user:3"}, false},
+		{"prefix", "user:", "", 10, []string{"user:1", "user:10", "user:2", "user:3"}, false},
+		{"limit", "user:", "", 2, []string{"user:1", "user:10"}, true},
+		{"exact limit", "user:", "", 4, []string{"user:1", "user:10", "user:2", "user:3"}, false},
+		{"resume after cursor", "user:", "user:10", 2, []string{"user:2", "user:3"}, false},
+		{"cursor before the prefix", "user:", "a", 1, []string{"user:1"}, true},
+		{"no match", "missing", "", 10, nil, false},
+		{"zero limit", "user:", "", 0, nil, true},
+		{"negative limit", "user:", "", -1, nil, true},
+		{"huge limit", "", "", math.MaxInt32, []string{"order:1", "user:1", "user:10", "user:2", "user:3"}, false},
+		{"max int limit", "user:", "user:2", math.MaxInt, []string{"user:3"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairs, more, err := store.Scan(tt.prefix, tt.startAfter, tt.limit)
+			if err != nil {
+				t.Fatalf("Scan() error = %v", err)
+			}
+			var keys []string
+			for _, pair := range pairs {
+				keys = append(keys, pair.Key)
+				if pair.Value != "v-"+pair.Key {
+					t.Errorf("Scan() value for %s = %s", pair.Key, pair.Value)
+				}
+			}
+			if strings.Join(keys, ",") != strings.Join(tt.wantKeys, ",") {
+				t.Errorf("Scan() keys = %v, want %v", keys, tt.wantKeys)
+			}
+			if more != tt.wantMore {
+				t.Errorf("Scan() more = %v, want %v", more, tt.wantMore)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package kvstore
+
+import (
+	"key-value/shared/vclock"
+	"sync"
+)
+
+// VersionedStore keeps the concurrent versions of each key for quorum replication.
+// It never decides between siblings itself: writes are reconciled by their vector clocks
+// and every version that is not an ancestor of another one is kept until a client merges them.
+type VersionedStore struct {
+	mutex      sync.RWMutex
+	versions   map[string][]vclock.Version
+	generation uint64 // bumped by every Put, so readers can tell whether a snapshot is still current
+}
+
+// NewVersionedStore creates an empty VersionedStore
+func NewVersionedStore() *VersionedStore {
+	return &VersionedStore{versions: make(map[string][]vclock.Version)}
+}
+
+// Get returns a copy of the siblings of key, including tombstones. A missing key has none.
+func (s *VersionedStore) Get(key string) []vclock.Version {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return copyVersions(s.versions[key])
+}
+
+// Put reconciles versions into the siblings of key and returns the resulting siblings
+func (s *VersionedStore) Put(key string, versions []vclock.Version) []vclock.Version {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	siblings := s.versions[key]
+	for _, v := range versions {
+		v.Clock = v.Clock.Copy()
+		siblings = vclock.Reconcile(siblings, v)
+	}
+	s.versions[key] = siblings
+	s.generation++
+	return copyVersions(siblings)
+}
+
+// Snapshot returns a copy of every key's siblings and the generation they were read at
+func (s *VersionedStore) Snapshot() (map[string][]vclock.Version, uint64) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	data := make(map[string][]vclock.Version, len(s.versions))
+	for key, versions := range s.versions {
+		data[key] = copyVersions(versions)
+	}
+	return data, s.generation
+}
+
+// Generation returns a number that changes whenever the store is written
+func (s *VersionedStore) Generation() uint64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.generation
+}
+
+func copyVersions(versions []vclock.Version) []vclock.Version {
+	copied := make([]vclock.Version, len(versions))
+	for i, v := range versions {
+		copied[i] = vclock.Version{Value: v.Value, Deleted: v.Deleted, Clock: v.Clock.Copy()}
+	}
+	return copied
+}
@@ -0,0 +1,562 @@
+// Package lease grants time limited leases that keys can be attached to. A lease that is not kept alive
+// expires, and the keys attached to it are deleted with it.
+package lease
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"key-value/services/key-value/internal/kvstore"
+	"log"
+	"maps"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCheckInterval is how often expired leases are looked for
+const DefaultCheckInterval = 100 * time.Millisecond
+
+// scanPage is the number of reserved keys read per scan when listing leases or their keys
+const scanPage = 1000
+
+// Reserved keys holding the leases in the wrapped store
+const (
+	statePrefix    = kvstore.ReservedPrefix + "lease/"
+	lastIDKey      = statePrefix + "id"        // last lease ID handed out
+	fenceKey       = statePrefix + "fence"     // last fencing token handed out
+	leasePrefix    = statePrefix + "lease/"    // followed by a lease ID, holds its time to live and expiry
+	ownerPrefix    = statePrefix + "owner/"    // followed by a key, holds the ID of the lease it is attached to
+	attachedPrefix = statePrefix + "attached/" // followed by a lease ID, a slash and a key attached to it
+)
+
+var (
+	// ErrNotFound is returned for leases that expired, were revoked or never existed
+	ErrNotFound = errors.New("lease not found")
+	// ErrInvalidTTL is returned when granting a lease without a positive time to live
+	ErrInvalidTTL = errors.New("lease time to live must be positive")
+)
+
+// Lease describes a granted lease
+type Lease struct {
+	ID        int64
+	TTL       time.Duration
+	Remaining time.Duration // time left before the lease expires unless kept alive
+	Keys      []string      // attached keys, sorted
+}
+
+// lease is the state of a granted lease
+type lease struct {
+	id     int64
+	ttl    time.Duration
+	expiry time.Time
+}
+
+// Store wraps a store and deletes the keys attached to a lease when it expires or is revoked. It implements
+// kvstore.Storer and kvstore.LeaseWriter and passes the optional store interfaces through. A key is detached
+// from its lease when it is set again without one or deleted.
+//
+// The leases, their keys and the counters of lease IDs and fencing tokens are kept in reserved keys of the wrapped
+// store, which needs to implement kvstore.Scanner. Raft or replication below it persist and replicate them like the
+// data, so leases and locks outlive a failover and fencing tokens keep growing. Expiry times are wall clock times,
+// and only the node accepting writes ends expired leases.
+type Store struct {
+	kvstore.Layer
+	mutex sync.Mutex // orders changes of the store with the attachments
+
+	checkInterval time.Duration
+	now           func() time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Option configures a Store
+type Option func(*Store)
+
+// WithCheckInterval sets how often expired leases are looked for, DefaultCheckInterval by default
+func WithCheckInterval(interval time.Duration) Option {
+	return func(s *Store) {
+		if interval > 0 {
+			s.checkInterval = interval
+		}
+	}
+}
+
+// NewStore starts expiring the leases granted through the returned store
+func NewStore(store kvstore.Storer, opts ...Option) *Store {
+	s := &Store{
+		Layer:         kvstore.Layer{Next: store},
+		checkInterval: DefaultCheckInterval,
+		now:           time.Now,
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	go s.run(ctx)
+	return s
+}
+
+// Close stops expiring leases
+func (s *Store) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
+
+// Grant creates a lease that expires after ttl unless kept alive
+func (s *Store) Grant(ttl time.Duration) (Lease, error) {
+	if ttl <= 0 {
+		return Lease{}, ErrInvalidTTL
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	id, err := s.Next.Increment(lastIDKey, 1)
+	if err != nil {
+		return Lease{}, err
+	}
+	l := lease{id: id, ttl: ttl, expiry: s.now().Add(ttl)}
+	if err := s.Next.Set(leaseKey(id), encode(l)); err != nil {
+		return Lease{}, err
+	}
+	return Lease{ID: l.id, TTL: l.ttl, Remaining: ttl, Keys: []string{}}, nil
+}
+
+// KeepAlive restarts the time to live of a lease
+func (s *Store) KeepAlive(id int64) (Lease, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	l, err := s.alive(id)
+	if err != nil {
+		return Lease{}, err
+	}
+	l.expiry = s.now().Add(l.ttl)
+	if err := s.Next.Set(leaseKey(id), encode(l)); err != nil {
+		return Lease{}, err
+	}
+	return s.describe(l)
+}
+
+// TimeToLive describes a lease without keeping it alive
+func (s *Store) TimeToLive(id int64) (Lease, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	l, err := s.alive(id)
+	if err != nil {
+		return Lease{}, err
+	}
+	return s.describe(l)
+}
+
+// Revoke ends a lease right away, deleting its keys
+func (s *Store) Revoke(id int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	l, err := s.alive(id)
+	if err != nil {
+		return err
+	}
+	return s.end(l)
+}
+
+// SetWithLease stores a key-value pair attached to a lease, moving it from the lease it was attached to before
+func (s *Store) SetWithLease(key string, value string, id int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	l, err := s.alive(id)
+	if err != nil {
+		return err
+	}
+	if err := s.Next.Set(key, value); err != nil {
+		return err
+	}
+	return s.attach(key, l)
+}
+
+// Attach attaches an existing key to a lease without changing its value, moving it from the lease it was attached
+// to before. It fails with kvstore.ErrNotFound when the key does not exist.
+func (s *Store) Attach(key string, id int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	l, err := s.alive(id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.Next.Get(key); err != nil {
+		return err
+	}
+	return s.attach(key, l)
+}
+
+// Detach detaches a key from its lease, if any, so it no longer expires
+func (s *Store) Detach(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.detach(key)
+}
+
+// Lookup describes the lease a key is attached to, if any
+func (s *Store) Lookup(key string) (Lease, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	stored, err := s.Next.Get(ownerKey(key))
+	if errors.Is(err, kvstore.ErrNotFound) {
+		return Lease{}, false, nil
+	}
+	if err != nil {
+		return Lease{}, false, err
+	}
+	id, err := strconv.ParseInt(stored, 10, 64)
+	if err != nil {
+		return Lease{}, false, fmt.Errorf("invalid lease of key %s: %q", key, stored)
+	}
+	l, err := s.alive(id)
+	if errors.Is(err, ErrNotFound) {
+		return Lease{}, false, nil
+	}
+	if err != nil {
+		return Lease{}, false, err
+	}
+	described, err := s.describe(l)
+	return described, err == nil, err
+}
+
+// Acquire stores a key-value pair attached to a lease only if the key does not exist, and hands out a fencing
+// token larger than every token handed out before. When the key exists, it returns the value holding it.
+func (s *Store) Acquire(key string, value string, id int64) (token uint64, holder string, acquired bool, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	l, err := s.alive(id)
+	if err != nil {
+		return 0, "", false, err
+	}
+
+	err = s.Layer.SetIfAbsent(key, value)
+	if errors.Is(err, kvstore.ErrConflict) {
+		holder, err := s.Next.Get(key)
+		if err != nil {
+			return 0, "", false, err
+		}
+		return 0, holder, false, nil
+	}
+	if err != nil {
+		return 0, "", false, err
+	}
+	if err := s.attach(key, l); err != nil {
+		return 0, "", false, err
+	}
+	fence, err := s.Next.Increment(fenceKey, 1)
+	if err != nil {
+		return 0, "", false, err
+	}
+	return uint64(fence), value, true, nil
+}
+
+// Set stores a key-value pair, detaching the key from its lease
+func (s *Store) Set(key string, value string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.change(key, nil, kvstore.Write{Key: key, Value: value}, func() error {
+		return s.Next.Set(key, value)
+	})
+}
+
+// Delete removes a key, detaching it from its lease
+func (s *Store) Delete(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.change(key, nil, kvstore.Write{Key: key, Delete: true}, func() error {
+		return s.Next.Delete(key)
+	})
+}
+
+// Increment adds delta to a key, which stays attached to its lease
+func (s *Store) Increment(key string, delta int64) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.Next.Increment(key, delta)
+}
+
+// Restore replaces the data of the store. Leases keep their attached keys, and the fencing tokens keep growing.
+func (s *Store) Restore(data map[string]string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	current, err := s.Layer.Snapshot()
+	if err != nil {
+		return err
+	}
+	data = maps.Clone(data)
+	for key, value := range current {
+		if strings.HasPrefix(key, statePrefix) {
+			data[key] = value
+		}
+	}
+	return s.Layer.Restore(data)
+}
+
+// SetIfAbsent stores a key-value pair only if the key does not exist
+func (s *Store) SetIfAbsent(key string, value string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.Layer.SetIfAbsent(key, value)
+}
+
+// DeleteIfValue removes a key only if it holds value, detaching it from its lease when it does
+func (s *Store) DeleteIfValue(key string, value string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	checks := []kvstore.Check{{Key: key, Value: value}}
+	return s.change(key, checks, kvstore.Write{Key: key, Delete: true}, func() error {
+		return s.Layer.DeleteIfValue(key, value)
+	})
+}
+
+// alive returns a lease that has not expired. The caller holds the mutex.
+func (s *Store) alive(id int64) (lease, error) {
+	stored, err := s.Next.Get(leaseKey(id))
+	if errors.Is(err, kvstore.ErrNotFound) {
+		return lease{}, fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	if err != nil {
+		return lease{}, err
+	}
+	l, err := decode(id, stored)
+	if err != nil {
+		return lease{}, err
+	}
+	if !s.now().Before(l.expiry) {
+		return lease{}, fmt.Errorf("%w: %d", ErrNotFound, id)
+	}
+	return l, nil
+}
+
+// change makes a write to key that detaches it from its lease, in one batch with the detaching when the wrapped store
+// supports batches. The batch checks which lease the key is attached to, so the common write of a key attached to
+// none needs no lookup first. Without batches, write makes the change and the key is detached after it. The caller
+// holds the mutex.
+func (s *Store) change(key string, checks []kvstore.Check, change kvstore.Write, write func() error) error {
+	if kvstore.IsReserved(key) {
+		return write()
+	}
+	owner := kvstore.Check{Key: ownerKey(key), Absent: true}
+	writes := []kvstore.Write{change}
+	for {
+		err := kvstore.Batch(s.Next, append(checks[:len(checks):len(checks)], owner), writes)
+		if errors.Is(err, kvstore.ErrUnsupported) {
+			if err := write(); err != nil {
+				return err
+			}
+			return s.detach(key)
+		}
+		if !errors.Is(err, kvstore.ErrConflict) {
+			return err
+		}
+
+		// Either the key is attached to another lease than checked, or a check of the caller failed
+		stored, lookupErr := s.Next.Get(ownerKey(key))
+		switch {
+		case errors.Is(lookupErr, kvstore.ErrNotFound):
+			if owner.Absent {
+				return err
+			}
+			owner, writes = kvstore.Check{Key: ownerKey(key), Absent: true}, []kvstore.Write{change}
+		case lookupErr != nil:
+			return lookupErr
+		case !owner.Absent && owner.Value == stored:
+			return err
+		default:
+			id, parseErr := strconv.ParseInt(stored, 10, 64)
+			if parseErr != nil {
+				return fmt.Errorf("invalid lease of key %s: %q", key, stored)
+			}
+			owner = kvstore.Check{Key: ownerKey(key), Value: stored}
+			writes = []kvstore.Write{change, {Key: ownerKey(key), Delete: true}, {Key: attachedKey(id, key), Delete: true}}
+		}
+	}
+}
+
+// attach moves key to lease l. The caller holds the mutex.
+func (s *Store) attach(key string, l lease) error {
+	if err := s.detach(key); err != nil {
+		return err
+	}
+	if err := s.Next.Set(attachedKey(l.id, key), ""); err != nil {
+		return err
+	}
+	return s.Next.Set(ownerKey(key), strconv.FormatInt(l.id, 10))
+}
+
+// detach removes key from its lease, if any. Reserved keys are never attached. The caller holds the mutex.
+func (s *Store) detach(key string) error {
+	if kvstore.IsReserved(key) {
+		return nil
+	}
+	stored, err := s.Next.Get(ownerKey(key))
+	if errors.Is(err, kvstore.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	id, err := strconv.ParseInt(stored, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid lease of key %s: %q", key, stored)
+	}
+	if err := s.Next.Delete(ownerKey(key)); err != nil {
+		return err
+	}
+	return s.Next.Delete(attachedKey(id, key))
+}
+
+// end deletes the keys of a lease and then the lease, so a lease that could not be ended whole is ended again
+// later. The caller holds the mutex.
+func (s *Store) end(l lease) error {
+	keys, err := s.keys(l.id)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, key := range keys {
+		err := s.Next.Delete(key)
+		if err == nil {
+			err = s.detach(key)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete %s of lease %d: %w", key, l.id, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	return s.Next.Delete(leaseKey(l.id))
+}
+
+// keys lists the keys attached to a lease in order. The caller holds the mutex.
+func (s *Store) keys(id int64) ([]string, error) {
+	prefix := attachedPrefix + strconv.FormatInt(id, 10) + "/"
+	pairs, err := s.scan(prefix)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(pairs))
+	for i, pair := range pairs {
+		keys[i] = strings.TrimPrefix(pair.Key, prefix)
+	}
+	return keys, nil
+}
+
+// scan lists every pair of the wrapped store under a reserved prefix. The caller holds the mutex.
+func (s *Store) scan(prefix string) ([]kvstore.KeyValue, error) {
+	var all []kvstore.KeyValue
+	startAfter := ""
+	for {
+		pairs, more, err := s.Layer.Scan(prefix, startAfter, scanPage)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, pairs...)
+		if !more || len(pairs) == 0 {
+			return all, nil
+		}
+		startAfter = pairs[len(pairs)-1].Key
+	}
+}
+
+// describe returns the public view of a lease. The caller holds the mutex.
+func (s *Store) describe(l lease) (Lease, error) {
+	keys, err := s.keys(l.id)
+	if err != nil {
+		return Lease{}, err
+	}
+	return Lease{ID: l.id, TTL: l.ttl, Remaining: max(l.expiry.Sub(s.now()), 0), Keys: keys}, nil
+}
+
+// run ends the expired leases until the store is closed
+func (s *Store) run(ctx context.Context) {
+	defer close(s.done)
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.expire()
+		}
+	}
+}
+
+// expire ends every lease past its expiry. Nodes that do not accept writes leave them to the node that does.
+func (s *Store) expire() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	pairs, err := s.scan(leasePrefix)
+	if err != nil {
+		if !errors.Is(err, kvstore.ErrNotLeader) {
+			log.Printf("lease: %v", err)
+		}
+		return
+	}
+	now := s.now()
+	for _, pair := range pairs {
+		id, err := strconv.ParseInt(strings.TrimPrefix(pair.Key, leasePrefix), 10, 64)
+		if err != nil {
+			log.Printf("lease: invalid lease key %q", pair.Key)
+			continue
+		}
+		l, err := decode(id, pair.Value)
+		if err != nil {
+			log.Printf("lease: %v", err)
+			continue
+		}
+		if now.Before(l.expiry) {
+			continue
+		}
+		if err := s.end(l); errors.Is(err, kvstore.ErrNotLeader) {
+			return
+		} else if err != nil {
+			log.Printf("lease: %v", err)
+		}
+	}
+}
+
+// leaseKey returns the reserved key holding a lease
+func leaseKey(id int64) string {
+	return leasePrefix + strconv.FormatInt(id, 10)
+}
+
+// ownerKey returns the reserved key holding the lease a key is attached to
+func ownerKey(key string) string {
+	return ownerPrefix + key
+}
+
+// attachedKey returns the reserved key listing key among the keys of a lease
+func attachedKey(id int64, key string) string {
+	return attachedPrefix + strconv.FormatInt(id, 10) + "/" + key
+}
+
+// encode stores a lease as its time to live and its expiry in Unix nanoseconds separated by a space
+func encode(l lease) string {
+	return strconv.FormatInt(int64(l.ttl), 10) + " " + strconv.FormatInt(l.expiry.UnixNano(), 10)
+}
+
+// decode reads a lease written by encode
+func decode(id int64, encoded string) (lease, error) {
+	ttl, expiry, ok := strings.Cut(encoded, " ")
+	t, err := strconv.ParseInt(ttl, 10, 64)
+	if !ok || err != nil {
+		return lease{}, fmt.Errorf("invalid lease %d: %q", id, encoded)
+	}
+	e, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return lease{}, fmt.Errorf("invalid lease %d: %q", id, encoded)
+	}
+	return lease{id: id, ttl: time.Duration(t), expiry: time.Unix(0, e)}, nil
+}
@@ -0,0 +1,250 @@
+package lease
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"key-value/services/key-value/internal/kvstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStore returns a store whose clock only moves through the returned function
+func newTestStore(t *testing.T) (*Store, func(time.Duration)) {
+	t.Helper()
+	s := NewStore(kvstore.NewInMemoryStore(), WithCheckInterval(time.Hour))
+	t.Cleanup(func() { s.Close() })
+	now := time.Unix(1000, 0)
+	s.now = func() time.Time { return now }
+	return s, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestStore_ExpiryDeletesKeys(t *testing.T) {
+	s, advance := newTestStore(t)
+
+	l, err := s.Grant(10 * time.Second)
+	require.NoError(t, err)
+	require.NoError(t, s.SetWithLease("session", "alice", l.ID))
+	require.NoError(t, s.SetWithLease("detached", "x", l.ID))
+	require.NoError(t, s.Set("detached", "y")) // set without a lease, so it outlives the lease
+	require.NoError(t, s.Set("plain", "z"))
+
+	advance(8 * time.Second)
+	l, err = s.KeepAlive(l.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 10*time.Second, l.Remaining)
+	assert.Equal(t, []string{"session"}, l.Keys)
+
+	advance(9 * time.Second)
+	s.expire()
+	_, err = s.Get("session")
+	require.NoError(t, err)
+
+	advance(time.Second)
+	s.expire()
+	_, err = s.Get("session")
+	assert.ErrorIs(t, err, kvstore.ErrNotFound)
+	value, err := s.Get("detached")
+	require.NoError(t, err)
+	assert.Equal(t, "y", value)
+
+	_, err = s.KeepAlive(l.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, s.SetWithLease("session", "bob", l.ID), ErrNotFound)
+}
+
+func TestStore_ExpiredBeforeSweep(t *testing.T) {
+	s, advance := newTestStore(t)
+
+	l, err := s.Grant(time.Second)
+	require.NoError(t, err)
+	advance(time.Second)
+
+	// The lease is gone even though the sweep did not run yet
+	_, err = s.TimeToLive(l.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, _, _, err = s.Acquire("lock", "a", l.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestStore_Acquire(t *testing.T) {
+	s, _ := newTestStore(t)
+
+	a, err := s.Grant(time.Minute)
+	require.NoError(t, err)
+	b, err := s.Grant(time.Minute)
+	require.NoError(t, err)
+
+	token, holder, acquired, err := s.Acquire("lock", "a", a.ID)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, uint64(1), token)
+	assert.Equal(t, "a", holder)
+
+	_, holder, acquired, err = s.Acquire("lock", "b", b.ID)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+	assert.Equal(t, "a", holder)
+
+	// Revoking the holder's lease releases the lock, and the next holder gets a larger token
+	require.NoError(t, s.Revoke(a.ID))
+	token, _, acquired, err = s.Acquire("lock", "b", b.ID)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, uint64(2), token)
+
+	assert.ErrorIs(t, s.Revoke(a.ID), ErrNotFound)
+	_, err = s.Grant(0)
+	assert.ErrorIs(t, err, ErrInvalidTTL)
+}
+
+func TestStore_ExpiresInBackground(t *testing.T) {
+	s := NewStore(kvstore.NewInMemoryStore(), WithCheckInterval(5*time.Millisecond))
+	defer s.Close()
+
+	l, err := s.Grant(20 * time.Millisecond)
+	require.NoError(t, err)
+	require.NoError(t, s.SetWithLease("k", "v", l.ID))
+
+	assert.Eventually(t, func() bool {
+		_, err := s.Get("k")
+		return err != nil
+	}, 2*time.Second, 5*time.Millisecond)
+}
+
+func TestStore_AttachDetachAndLookup(t *testing.T) {
+	s, advance := newTestStore(t)
+
+	l, err := s.Grant(10 * time.Second)
+	require.NoError(t, err)
+	require.NoError(t, s.Set("config", "v1"))
+	_, ok, err := s.Lookup("config")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.ErrorIs(t, s.Attach("missing", l.ID), kvstore.ErrNotFound)
+	require.NoError(t, s.Attach("config", l.ID))
+	value, err := s.Get("config")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", value)
+
+	advance(4 * time.Second)
+	attached, ok, err := s.Lookup("config")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, l.ID, attached.ID)
+	assert.Equal(t, 6*time.Second, attached.Remaining)
+
+	require.NoError(t, s.Detach("config"))
+	_, ok, err = s.Lookup("config")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	require.NoError(t, s.Attach("config", l.ID))
+
+	advance(6 * time.Second)
+	_, ok, err = s.Lookup("config")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.ErrorIs(t, s.Attach("config", l.ID), ErrNotFound)
+}
+
+// batchStore commits batches like a replicated store, counting them and the reads of owners
+type batchStore struct {
+	*kvstore.InMemoryStore
+	batches int
+	lookups int
+}
+
+func (s *batchStore) Get(key string) (string, error) {
+	if strings.HasPrefix(key, ownerPrefix) {
+		s.lookups++
+	}
+	return s.InMemoryStore.Get(key)
+}
+
+func (s *batchStore) Batch(checks []kvstore.Check, writes []kvstore.Write) error {
+	s.batches++
+	return kvstore.ApplyBatch(s.InMemoryStore, checks, writes)
+}
+
+func TestStore_DetachesInOneBatch(t *testing.T) {
+	base := &batchStore{InMemoryStore: kvstore.NewInMemoryStore()}
+	s := NewStore(base, WithCheckInterval(time.Hour))
+	t.Cleanup(func() { s.Close() })
+
+	// Keys attached to no lease are written without looking the lease up
+	require.NoError(t, s.Set("a", "1"))
+	require.NoError(t, s.Set("b", "2"))
+	require.NoError(t, s.Delete("b"))
+	assert.Equal(t, 3, base.batches)
+	assert.Equal(t, 0, base.lookups)
+	assert.ErrorIs(t, s.DeleteIfValue("a", "2"), kvstore.ErrConflict)
+
+	l, err := s.Grant(10 * time.Second)
+	require.NoError(t, err)
+	for i, write := range []func() error{
+		func() error { return s.Set("a", "2") },
+		func() error { return s.DeleteIfValue("a", "2") },
+	} {
+		require.NoError(t, s.Attach("a", l.ID))
+		batches := base.batches
+		require.NoError(t, write(), "write %d", i)
+		assert.Equal(t, batches+2, base.batches, "write %d is retried once with the lease it is attached to", i)
+		_, ok, err := s.Lookup("a")
+		require.NoError(t, err)
+		assert.False(t, ok)
+		attached, err := s.TimeToLive(l.ID)
+		require.NoError(t, err)
+		assert.Empty(t, attached.Keys)
+	}
+	_, err = s.Get("a")
+	assert.ErrorIs(t, err, kvstore.ErrNotFound)
+}
+
+func TestStore_KeepsLeasesInTheWrappedStore(t *testing.T) {
+	s, _ := newTestStore(t)
+	l, err := s.Grant(time.Minute)
+	require.NoError(t, err)
+	token, _, acquired, err := s.Acquire("lock", "a", l.ID)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	// A new store over the same data, like after a failover, keeps the lease, its keys and the fencing tokens
+	next, _ := newTestStore(t)
+	next.Next = s.Next
+	s = next
+	described, err := s.TimeToLive(l.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lock"}, described.Keys)
+	other, err := s.Grant(time.Minute)
+	require.NoError(t, err)
+	assert.Greater(t, other.ID, l.ID, "lease IDs must not be reused")
+
+	require.NoError(t, s.Revoke(l.ID))
+	later, _, acquired, err := s.Acquire("lock", "b", other.ID)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	assert.Greater(t, later, token)
+
+	// Reserved keys are left alone, and only the data reaches a backup
+	data, err := s.Snapshot()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"lock": "b"}, kvstore.PublicData(data))
+}
+
+func TestStore_RestoreKeepsLeases(t *testing.T) {
+	s, _ := newTestStore(t)
+	l, err := s.Grant(time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, s.SetWithLease("session", "alice", l.ID))
+
+	require.NoError(t, s.Restore(map[string]string{"session": "bob"}))
+	described, err := s.TimeToLive(l.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"session"}, described.Keys)
+	require.NoError(t, s.Revoke(l.ID))
+	_, err = s.Get("session")
+	assert.ErrorIs(t, err, kvstore.ErrNotFound)
+}
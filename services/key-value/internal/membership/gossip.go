@@ -0,0 +1,664 @@
+// Package membership runs SWIM style gossip between key-value services. Every protocol period a node probes one
+// member, asks a few others to probe it indirectly when it does not answer, then marks it suspect and finally dead
+// unless the member refutes the suspicion in time. Membership changes are piggybacked on the probes.
+package membership
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"key-value/proto/keyvalue"
+	"log"
+	"math/bits"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Member states
+const (
+	Alive   = keyvalue.MemberState_MEMBER_STATE_ALIVE
+	Suspect = keyvalue.MemberState_MEMBER_STATE_SUSPECT
+	Dead    = keyvalue.MemberState_MEMBER_STATE_DEAD
+	Left    = keyvalue.MemberState_MEMBER_STATE_LEFT
+)
+
+const (
+	// DefaultProbeInterval is the protocol period, one member is probed per period
+	DefaultProbeInterval = time.Second
+	// DefaultProbeTimeout is how long a direct probe waits for its answer
+	DefaultProbeTimeout = 500 * time.Millisecond
+	// DefaultSuspicionTimeout is how long a suspect member has to refute the suspicion before it is declared dead
+	DefaultSuspicionTimeout = 5 * time.Second
+	// DefaultIndirectChecks is the number of members asked to probe a member that did not answer
+	DefaultIndirectChecks = 3
+
+	// deadRetention is how long dead and departed members stay in the list, so late gossip cannot revive them
+	deadRetention = time.Minute
+	// maxPiggyback bounds the updates sent with one message
+	maxPiggyback = 16
+	// retransmitMult scales how many times an update is sent, times the log of the group size
+	retransmitMult = 3
+	// leaveFanout is the number of members told directly that this node leaves
+	leaveFanout = 3
+)
+
+// ErrWrongTarget is returned by HandlePing when the probe was meant for another member at the same address
+var ErrWrongTarget = errors.New("probe addressed to another member")
+
+// Member is a node of the group as known locally
+type Member struct {
+	ID          string
+	Addr        string // gRPC address
+	State       keyvalue.MemberState
+	Incarnation uint64
+}
+
+// member adds local bookkeeping to a Member
+type member struct {
+	Member
+	changed time.Time // last state change, starts the suspicion and retention timers
+}
+
+// broadcast is an update waiting to be piggybacked
+type broadcast struct {
+	member    Member
+	transmits int
+}
+
+// Gossip is the membership of this node
+type Gossip struct {
+	id   string
+	addr string
+
+	probeInterval    time.Duration
+	probeTimeout     time.Duration
+	suspicionTimeout time.Duration
+	indirectChecks   int
+	dialOptions      []grpc.DialOption
+
+	mutex       sync.Mutex
+	incarnation uint64
+	leaving     bool
+	members     map[string]*member
+	queue       map[string]*broadcast // latest update per member still being spread
+	probeOrder  []string
+	probeIndex  int
+	seeds       []string
+	subscribers map[chan []Member]struct{}
+	conns       map[string]*grpc.ClientConn
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Option configures a Gossip
+type Option func(*Gossip)
+
+// WithProbeInterval sets the protocol period, DefaultProbeInterval by default
+func WithProbeInterval(interval time.Duration) Option {
+	return func(g *Gossip) {
+		g.probeInterval = interval
+	}
+}
+
+// WithProbeTimeout sets how long a direct probe waits, DefaultProbeTimeout by default. It must be below the probe interval.
+func WithProbeTimeout(timeout time.Duration) Option {
+	return func(g *Gossip) {
+		g.probeTimeout = timeout
+	}
+}
+
+// WithSuspicionTimeout sets how long a suspect member has to refute, DefaultSuspicionTimeout by default
+func WithSuspicionTimeout(timeout time.Duration) Option {
+	return func(g *Gossip) {
+		g.suspicionTimeout = timeout
+	}
+}
+
+// WithIndirectChecks sets how many members probe a member that did not answer, DefaultIndirectChecks by default
+func WithIndirectChecks(n int) Option {
+	return func(g *Gossip) {
+		g.indirectChecks = n
+	}
+}
+
+// WithDialOptions sets the options used to connect to other members. They default to plaintext.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(g *Gossip) {
+		g.dialOptions = opts
+	}
+}
+
+// NewGossip starts the membership of the node id reachable at addr. It is alone until it joins a member.
+func NewGossip(id string, addr string, opts ...Option) (*Gossip, error) {
+	if id == "" || addr == "" {
+		return nil, fmt.Errorf("member ID and address are required")
+	}
+
+	g := &Gossip{
+		id:               id,
+		addr:             addr,
+		probeInterval:    DefaultProbeInterval,
+		probeTimeout:     DefaultProbeTimeout,
+		suspicionTimeout: DefaultSuspicionTimeout,
+		indirectChecks:   DefaultIndirectChecks,
+		dialOptions:      []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
+		members:          make(map[string]*member),
+		queue:            make(map[string]*broadcast),
+		subscribers:      make(map[chan []Member]struct{}),
+		conns:            make(map[string]*grpc.ClientConn),
+		done:             make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	if g.probeTimeout >= g.probeInterval {
+		return nil, fmt.Errorf("probe timeout %s must be below the probe interval %s", g.probeTimeout, g.probeInterval)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g.cancel = cancel
+	go g.run(ctx)
+	return g, nil
+}
+
+// ID returns the ID of this node
+func (g *Gossip) ID() string {
+	return g.id
+}
+
+// Members returns every known member sorted by ID, including this node and members that recently failed or left
+func (g *Gossip) Members() []Member {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return g.list()
+}
+
+// Subscribe returns a channel receiving the member list after every change and a function ending the subscription.
+// A slow reader only misses intermediate lists, never the latest one.
+func (g *Gossip) Subscribe() (<-chan []Member, func()) {
+	ch := make(chan []Member, 1)
+	g.mutex.Lock()
+	g.subscribers[ch] = struct{}{}
+	g.mutex.Unlock()
+
+	return ch, func() {
+		g.mutex.Lock()
+		defer g.mutex.Unlock()
+		if _, ok := g.subscribers[ch]; ok {
+			delete(g.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Join exchanges the member list with the seeds, given as gRPC addresses. It fails only when no seed answered.
+// Until another member is known the node keeps trying the seeds every protocol period.
+func (g *Gossip) Join(ctx context.Context, seeds []string) error {
+	g.mutex.Lock()
+	g.seeds = slices.DeleteFunc(slices.Clone(seeds), func(seed string) bool { return seed == g.addr })
+	seeds = g.seeds
+	g.mutex.Unlock()
+
+	var errs []error
+	joined := len(seeds) == 0
+	for _, seed := range seeds {
+		if err := g.pushPull(ctx, seed); err != nil {
+			errs = append(errs, fmt.Errorf("seed %s: %w", seed, err))
+			continue
+		}
+		joined = true
+	}
+	if !joined {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// Leave tells other members that this node is leaving, so they drop it without waiting for the suspicion timeout
+func (g *Gossip) Leave(ctx context.Context) error {
+	g.mutex.Lock()
+	g.leaving = true
+	g.incarnation++
+	g.enqueue(g.self())
+	targets := g.randomMembers(leaveFanout, "")
+	g.mutex.Unlock()
+
+	var errs []error
+	for _, target := range targets {
+		if err := g.ping(ctx, target); err != nil {
+			errs = append(errs, fmt.Errorf("member %s: %w", target.ID, err))
+		}
+	}
+	if len(errs) == len(targets) {
+		return errors.Join(errs...)
+	}
+	return nil
+}
+
+// Close stops probing and closes the connections to other members. Call Leave first for a graceful departure.
+func (g *Gossip) Close() error {
+	g.cancel()
+	<-g.done
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	for ch := range g.subscribers {
+		delete(g.subscribers, ch)
+		close(ch)
+	}
+	var errs []error
+	for addr, conn := range g.conns {
+		errs = append(errs, conn.Close())
+		delete(g.conns, addr)
+	}
+	return errors.Join(errs...)
+}
+
+// HandlePing answers a direct probe with the updates this node is spreading
+func (g *Gossip) HandlePing(req *keyvalue.PingRequest) (*keyvalue.PingResponse, error) {
+	if req.TargetId != "" && req.TargetId != g.id {
+		return nil, fmt.Errorf("%w %s, this is %s", ErrWrongTarget, req.TargetId, g.id)
+	}
+	g.merge(req.From, req.Updates)
+	updates := g.piggyback()
+	if disputed := g.disputed(req.From); disputed != nil {
+		updates = append(updates, disputed)
+	}
+	return &keyvalue.PingResponse{Updates: updates}, nil
+}
+
+// disputed returns what this node knows about sender when it declares the sender suspect or dead, so a sender
+// that missed the gossip about itself can still refute it
+func (g *Gossip) disputed(sender *keyvalue.GossipMember) *keyvalue.GossipMember {
+	if sender == nil {
+		return nil
+	}
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	known, ok := g.members[sender.Id]
+	if !ok || known.State == Alive || known.Incarnation < sender.Incarnation {
+		return nil
+	}
+	return toProto([]Member{known.Member})[0]
+}
+
+// HandleIndirectPing probes a member on behalf of the sender
+func (g *Gossip) HandleIndirectPing(ctx context.Context, req *keyvalue.IndirectPingRequest) *keyvalue.IndirectPingResponse {
+	g.merge(req.From, req.Updates)
+
+	ctx, cancel := context.WithTimeout(ctx, g.probeTimeout)
+	defer cancel()
+	ack := req.Target != nil && g.ping(ctx, fromProto(req.Target)) == nil
+	return &keyvalue.IndirectPingResponse{Ack: ack, Updates: g.piggyback()}
+}
+
+// HandleJoin adds the sender and returns every known member
+func (g *Gossip) HandleJoin(req *keyvalue.JoinRequest) *keyvalue.JoinResponse {
+	g.merge(req.Member, nil)
+	return &keyvalue.JoinResponse{Members: toProto(g.Members())}
+}
+
+func (g *Gossip) run(ctx context.Context) {
+	defer close(g.done)
+	ticker := time.NewTicker(g.probeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			g.tick(ctx)
+		}
+	}
+}
+
+// tick runs one protocol period
+func (g *Gossip) tick(ctx context.Context) {
+	g.expire()
+
+	g.mutex.Lock()
+	var seeds []string
+	if !g.leaving && len(g.randomMembers(1, "")) == 0 {
+		seeds = g.seeds
+	}
+	target, ok := g.nextTarget()
+	g.mutex.Unlock()
+
+	// Alone, probably started before the seeds; try joining again
+	for _, seed := range seeds {
+		joinCtx, cancel := context.WithTimeout(ctx, g.probeTimeout)
+		err := g.pushPull(joinCtx, seed)
+		cancel()
+		if err == nil {
+			return
+		}
+	}
+
+	if ok {
+		g.probe(ctx, target)
+	}
+}
+
+// probe checks one member directly, then through others, and suspects it when nobody reached it
+func (g *Gossip) probe(ctx context.Context, target Member) {
+	pingCtx, cancel := context.WithTimeout(ctx, g.probeTimeout)
+	err := g.ping(pingCtx, target)
+	cancel()
+	if err == nil || ctx.Err() != nil {
+		return
+	}
+
+	g.mutex.Lock()
+	helpers := g.randomMembers(g.indirectChecks, target.ID)
+	g.mutex.Unlock()
+
+	if len(helpers) > 0 {
+		indirectCtx, cancel := context.WithTimeout(ctx, g.probeInterval-g.probeTimeout)
+		defer cancel()
+		acks := make(chan bool, len(helpers))
+		for _, helper := range helpers {
+			go func() {
+				acks <- g.indirectPing(indirectCtx, helper, target)
+			}()
+		}
+		for range helpers {
+			if <-acks {
+				return
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if current, ok := g.members[target.ID]; ok && current.State == Alive && current.Incarnation == target.Incarnation {
+		log.Printf("gossip: suspecting %s at %s: %v", target.ID, target.Addr, err)
+		suspected := current.Member
+		suspected.State = Suspect
+		g.apply(suspected)
+	}
+}
+
+// ping probes target directly, exchanging piggybacked updates
+func (g *Gossip) ping(ctx context.Context, target Member) error {
+	client, err := g.client(target.Addr)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Ping(ctx, &keyvalue.PingRequest{TargetId: target.ID, From: g.selfProto(), Updates: g.piggyback()})
+	if err != nil {
+		return err
+	}
+	g.merge(nil, resp.Updates)
+	return nil
+}
+
+// indirectPing asks helper to probe target and reports whether target answered
+func (g *Gossip) indirectPing(ctx context.Context, helper Member, target Member) bool {
+	client, err := g.client(helper.Addr)
+	if err != nil {
+		return false
+	}
+	resp, err := client.IndirectPing(ctx, &keyvalue.IndirectPingRequest{
+		Target:  toProto([]Member{target})[0],
+		From:    g.selfProto(),
+		Updates: g.piggyback(),
+	})
+	if err != nil {
+		return false
+	}
+	g.merge(nil, resp.Updates)
+	return resp.Ack
+}
+
+// pushPull sends this node to addr and merges every member it knows
+func (g *Gossip) pushPull(ctx context.Context, addr string) error {
+	client, err := g.client(addr)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Join(ctx, &keyvalue.JoinRequest{Member: g.selfProto()})
+	if err != nil {
+		return err
+	}
+	g.merge(nil, resp.Members)
+	return nil
+}
+
+// client returns a client for the member at addr, reusing connections
+func (g *Gossip) client(addr string) (keyvalue.MembershipServiceClient, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	conn, ok := g.conns[addr]
+	if !ok {
+		var err error
+		conn, err = grpc.NewClient(addr, g.dialOptions...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+		}
+		g.conns[addr] = conn
+	}
+	return keyvalue.NewMembershipServiceClient(conn), nil
+}
+
+// expire declares dead the suspects that did not refute in time and forgets old dead members
+func (g *Gossip) expire() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	now := time.Now()
+	for id, m := range g.members {
+		switch {
+		case m.State == Suspect && now.Sub(m.changed) > g.suspicionTimeout:
+			log.Printf("gossip: %s at %s is dead", m.ID, m.Addr)
+			dead := m.Member
+			dead.State = Dead
+			g.apply(dead)
+		case (m.State == Dead || m.State == Left) && now.Sub(m.changed) > deadRetention:
+			delete(g.members, id)
+			delete(g.queue, id)
+			g.closeConn(m.Addr)
+			g.notify()
+		}
+	}
+}
+
+// closeConn closes the connection to addr unless another member still uses that address
+func (g *Gossip) closeConn(addr string) {
+	for _, m := range g.members {
+		if m.Addr == addr {
+			return
+		}
+	}
+	if conn, ok := g.conns[addr]; ok {
+		conn.Close()
+		delete(g.conns, addr)
+	}
+}
+
+// merge applies the sender of a message and the updates it carried
+func (g *Gossip) merge(from *keyvalue.GossipMember, updates []*keyvalue.GossipMember) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if from != nil {
+		g.apply(fromProto(from))
+	}
+	for _, update := range updates {
+		g.apply(fromProto(update))
+	}
+}
+
+// apply merges one update following the SWIM precedence rules and spreads it further when it changed anything.
+// The caller holds the mutex.
+func (g *Gossip) apply(m Member) bool {
+	if m.ID == "" {
+		return false
+	}
+
+	if m.ID == g.id {
+		// Refute a suspicion, or newer state left behind by a previous run of this node
+		if g.leaving || !(m.Incarnation > g.incarnation || (m.State != Alive && m.Incarnation == g.incarnation)) {
+			return false
+		}
+		g.incarnation = m.Incarnation + 1
+		g.enqueue(g.self())
+		g.notify()
+		return true
+	}
+
+	existing, ok := g.members[m.ID]
+	switch {
+	case !ok && (m.State == Dead || m.State == Left):
+		return false
+	case !ok:
+		g.members[m.ID] = &member{Member: m, changed: time.Now()}
+	case supersedes(m, existing.Member):
+		if existing.State != m.State {
+			existing.changed = time.Now()
+		}
+		existing.Member = m
+	default:
+		return false
+	}
+	g.enqueue(m)
+	g.notify()
+	return true
+}
+
+// supersedes reports whether update replaces what is known about a member
+func supersedes(update Member, known Member) bool {
+	switch update.State {
+	case Alive:
+		return update.Incarnation > known.Incarnation
+	case Suspect:
+		return update.Incarnation > known.Incarnation || (update.Incarnation == known.Incarnation && known.State == Alive)
+	case Dead, Left:
+		return update.Incarnation > known.Incarnation ||
+			(update.Incarnation == known.Incarnation && (known.State == Alive || known.State == Suspect))
+	}
+	return false
+}
+
+// enqueue queues an update for piggybacking, replacing an older update of the same member
+func (g *Gossip) enqueue(m Member) {
+	g.queue[m.ID] = &broadcast{member: m}
+}
+
+// piggyback returns the updates to send with the next message, least sent first
+func (g *Gossip) piggyback() []*keyvalue.GossipMember {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	pending := make([]*broadcast, 0, len(g.queue))
+	for _, b := range g.queue {
+		pending = append(pending, b)
+	}
+	slices.SortFunc(pending, func(a, b *broadcast) int { return a.transmits - b.transmits })
+
+	limit := retransmitMult * bits.Len(uint(len(g.members)+1))
+	updates := make([]Member, 0, min(len(pending), maxPiggyback))
+	for _, b := range pending[:min(len(pending), maxPiggyback)] {
+		updates = append(updates, b.member)
+		if b.transmits++; b.transmits >= limit {
+			delete(g.queue, b.member.ID)
+		}
+	}
+	return toProto(updates)
+}
+
+// notify sends the member list to every subscriber, replacing a list it did not read yet. The caller holds the mutex.
+func (g *Gossip) notify() {
+	if len(g.subscribers) == 0 {
+		return
+	}
+	members := g.list()
+	for ch := range g.subscribers {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- members
+	}
+}
+
+// nextTarget picks the next member to probe, going round robin through a shuffled list. The caller holds the mutex.
+func (g *Gossip) nextTarget() (Member, bool) {
+	for range 2 {
+		for ; g.probeIndex < len(g.probeOrder); g.probeIndex++ {
+			if m, ok := g.members[g.probeOrder[g.probeIndex]]; ok && (m.State == Alive || m.State == Suspect) {
+				g.probeIndex++
+				return m.Member, true
+			}
+		}
+		g.probeOrder = g.probeOrder[:0]
+		for id := range g.members {
+			g.probeOrder = append(g.probeOrder, id)
+		}
+		rand.Shuffle(len(g.probeOrder), func(i, j int) {
+			g.probeOrder[i], g.probeOrder[j] = g.probeOrder[j], g.probeOrder[i]
+		})
+		g.probeIndex = 0
+	}
+	return Member{}, false
+}
+
+// randomMembers returns up to n random alive members other than exclude. The caller holds the mutex.
+func (g *Gossip) randomMembers(n int, exclude string) []Member {
+	var candidates []Member
+	for _, m := range g.members {
+		if m.State == Alive && m.ID != exclude {
+			candidates = append(candidates, m.Member)
+		}
+	}
+	rand.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	return candidates[:min(n, len(candidates))]
+}
+
+// self returns this node as a member. The caller holds the mutex.
+func (g *Gossip) self() Member {
+	state := Alive
+	if g.leaving {
+		state = Left
+	}
+	return Member{ID: g.id, Addr: g.addr, State: state, Incarnation: g.incarnation}
+}
+
+func (g *Gossip) selfProto() *keyvalue.GossipMember {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return toProto([]Member{g.self()})[0]
+}
+
+// list returns every member sorted by ID. The caller holds the mutex.
+func (g *Gossip) list() []Member {
+	members := []Member{g.self()}
+	for _, m := range g.members {
+		members = append(members, m.Member)
+	}
+	slices.SortFunc(members, func(a, b Member) int { return strings.Compare(a.ID, b.ID) })
+	return members
+}
+
+func toProto(members []Member) []*keyvalue.GossipMember {
+	out := make([]*keyvalue.GossipMember, len(members))
+	for i, m := range members {
+		out[i] = &keyvalue.GossipMember{Id: m.ID, Addr: m.Addr, State: m.State, Incarnation: m.Incarnation}
+	}
+	return out
+}
+
+func fromProto(m *keyvalue.GossipMember) Member {
+	return Member{ID: m.Id, Addr: m.Addr, State: m.State, Incarnation: m.Incarnation}
+}
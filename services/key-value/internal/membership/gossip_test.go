@@ -0,0 +1,130 @@
+package membership
+
+import (
+	"testing"
+	"time"
+
+	"key-value/proto/keyvalue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSupersedes(t *testing.T) {
+	tests := []struct {
+		name   string
+		update Member
+		known  Member
+		want   bool
+	}{
+		{"alive with higher incarnation", Member{State: Alive, Incarnation: 2}, Member{State: Suspect, Incarnation: 1}, true},
+		{"alive with same incarnation", Member{State: Alive, Incarnation: 1}, Member{State: Suspect, Incarnation: 1}, false},
+		{"alive revives dead with higher incarnation", Member{State: Alive, Incarnation: 3}, Member{State: Dead, Incarnation: 2}, true},
+		{"suspect of alive", Member{State: Suspect, Incarnation: 1}, Member{State: Alive, Incarnation: 1}, true},
+		{"suspect of refuted alive", Member{State: Suspect, Incarnation: 1}, Member{State: Alive, Incarnation: 2}, false},
+		{"suspect again", Member{State: Suspect, Incarnation: 1}, Member{State: Suspect, Incarnation: 1}, false},
+		{"dead of suspect", Member{State: Dead, Incarnation: 1}, Member{State: Suspect, Incarnation: 1}, true},
+		{"dead of refuted alive", Member{State: Dead, Incarnation: 1}, Member{State: Alive, Incarnation: 2}, false},
+		{"left of alive", Member{State: Left, Incarnation: 2}, Member{State: Alive, Incarnation: 1}, true},
+		{"dead of left", Member{State: Dead, Incarnation: 2}, Member{State: Left, Incarnation: 2}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, supersedes(tt.update, tt.known))
+		})
+	}
+}
+
+func newTestGossip(t *testing.T, id string) *Gossip {
+	t.Helper()
+	g, err := NewGossip(id, id+":50051", WithProbeInterval(time.Hour), WithProbeTimeout(time.Minute))
+	require.NoError(t, err)
+	t.Cleanup(func() { g.Close() })
+	return g
+}
+
+func TestGossip_RefutesSuspicion(t *testing.T) {
+	g := newTestGossip(t, "a")
+
+	_, err := g.HandlePing(&keyvalue.PingRequest{
+		TargetId: "a",
+		From:     &keyvalue.GossipMember{Id: "b", Addr: "b:50051", State: Alive},
+		Updates:  []*keyvalue.GossipMember{{Id: "a", Addr: "a:50051", State: Suspect}},
+	})
+	require.NoError(t, err)
+
+	members := g.Members()
+	require.Len(t, members, 2)
+	assert.Equal(t, Member{ID: "a", Addr: "a:50051", State: Alive, Incarnation: 1}, members[0])
+	assert.Equal(t, "b", members[1].ID)
+
+	// The refutation is spread with the next messages
+	resp, err := g.HandlePing(&keyvalue.PingRequest{TargetId: "a", From: &keyvalue.GossipMember{Id: "b", Addr: "b:50051", State: Alive}})
+	require.NoError(t, err)
+	var self *keyvalue.GossipMember
+	for _, update := range resp.Updates {
+		if update.Id == "a" {
+			self = update
+		}
+	}
+	require.NotNil(t, self)
+	assert.Equal(t, Alive, self.State)
+	assert.Equal(t, uint64(1), self.Incarnation)
+}
+
+func TestGossip_WrongTarget(t *testing.T) {
+	g := newTestGossip(t, "a")
+
+	_, err := g.HandlePing(&keyvalue.PingRequest{TargetId: "old"})
+	assert.ErrorIs(t, err, ErrWrongTarget)
+}
+
+func TestGossip_DisputedSender(t *testing.T) {
+	g := newTestGossip(t, "a")
+	g.HandleJoin(&keyvalue.JoinRequest{Member: &keyvalue.GossipMember{Id: "b", Addr: "b:50051", State: Alive}})
+	g.merge(nil, []*keyvalue.GossipMember{{Id: "b", Addr: "b:50051", State: Dead}})
+
+	// b missed the gossip declaring it dead; the answer to its next probe tells it
+	for range 20 {
+		g.piggyback()
+	}
+	resp, err := g.HandlePing(&keyvalue.PingRequest{TargetId: "a", From: &keyvalue.GossipMember{Id: "b", Addr: "b:50051", State: Alive}})
+	require.NoError(t, err)
+	require.Len(t, resp.Updates, 1)
+	assert.Equal(t, Dead, resp.Updates[0].State)
+}
+
+func TestGossip_PiggybackRetransmitLimit(t *testing.T) {
+	g := newTestGossip(t, "a")
+	g.HandleJoin(&keyvalue.JoinRequest{Member: &keyvalue.GossipMember{Id: "b", Addr: "b:50051", State: Alive}})
+
+	sent := 0
+	for range 100 {
+		sent += len(g.piggyback())
+	}
+	// One queued update, sent 3 * log2 of the group size times
+	assert.Equal(t, retransmitMult*2, sent)
+}
+
+func TestGossip_Subscribe(t *testing.T) {
+	g := newTestGossip(t, "a")
+	updates, cancel := g.Subscribe()
+	defer cancel()
+
+	g.HandleJoin(&keyvalue.JoinRequest{Member: &keyvalue.GossipMember{Id: "b", Addr: "b:50051", State: Alive}})
+	g.HandleJoin(&keyvalue.JoinRequest{Member: &keyvalue.GossipMember{Id: "c", Addr: "c:50051", State: Alive}})
+
+	// Only the latest list is kept for a slow reader
+	members := <-updates
+	assert.Len(t, members, 3)
+	select {
+	case <-updates:
+		t.Fatal("unexpected second list")
+	default:
+	}
+
+	// Unknown members reported dead are not added
+	g.merge(nil, []*keyvalue.GossipMember{{Id: "d", Addr: "d:50051", State: Dead}})
+	assert.Len(t, g.Members(), 3)
+}
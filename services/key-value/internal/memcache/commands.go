@@ -0,0 +1,452 @@
+package memcache
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"key-value/services/key-value/internal/kvstore"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errBadChunk is returned when a data block cannot be read, leaving the connection out of sync
+var errBadChunk = errors.New("bad data chunk")
+
+// run executes a command line, reading its data block if it has one
+func (c *session) run(line string) error {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		c.reply("ERROR")
+		return nil
+	}
+
+	switch name, args := fields[0], fields[1:]; name {
+	case "get", "gets":
+		c.get(args, name == "gets")
+	case "set", "add", "replace", "cas":
+		return c.storage(name, args)
+	case "delete":
+		c.delete(args)
+	case "incr", "decr":
+		c.arithmetic(name == "incr", args)
+	case "touch":
+		c.touch(args)
+	case "stats":
+		c.stats()
+	case "version":
+		c.reply("VERSION " + Version)
+	case "verbosity":
+		c.replyUnless(noreply(args), "OK")
+	case "quit":
+		c.quit = true
+	default:
+		c.reply("ERROR")
+	}
+	return nil
+}
+
+// reply writes a reply line
+func (c *session) reply(line string) {
+	c.w.WriteString(line + "\r\n")
+}
+
+// replyUnless writes a reply line unless the client asked for no reply
+func (c *session) replyUnless(quiet bool, line string) {
+	if !quiet {
+		c.reply(line)
+	}
+}
+
+// noreply reports whether the last argument asks for no reply
+func noreply(args []string) bool {
+	return len(args) > 0 && args[len(args)-1] == "noreply"
+}
+
+// storeError replies to a failed write
+func (c *session) storeError(quiet bool, err error) {
+	switch {
+	case errors.Is(err, kvstore.ErrInvalidKey):
+		c.replyUnless(quiet, "CLIENT_ERROR "+err.Error())
+	case errors.Is(err, kvstore.ErrTooLarge):
+		c.replyUnless(quiet, "SERVER_ERROR object too large for cache")
+	default:
+		c.replyUnless(quiet, "SERVER_ERROR "+err.Error())
+	}
+}
+
+// get replies with the items found, with their CAS values for gets
+func (c *session) get(keys []string, withCAS bool) {
+	if len(keys) == 0 {
+		c.reply("ERROR")
+		return
+	}
+	for _, key := range keys {
+		c.server.stats.cmdGet.Add(1)
+		if err := kvstore.CheckKey(c.server.limits, key); err != nil {
+			c.reply("CLIENT_ERROR " + err.Error())
+			return
+		}
+		item, err := c.server.items.Lookup(key)
+		if errors.Is(err, kvstore.ErrNotFound) {
+			c.server.stats.getMisses.Add(1)
+			continue
+		}
+		if err != nil {
+			c.reply("SERVER_ERROR " + err.Error())
+			return
+		}
+
+		c.server.stats.getHits.Add(1)
+		header := fmt.Sprintf("VALUE %s %d %d", key, item.Flags, len(item.Value))
+		if withCAS {
+			header += " " + strconv.FormatUint(item.Version, 10)
+		}
+		c.reply(header)
+		c.reply(item.Value)
+	}
+	c.reply("END")
+}
+
+// storage runs set, add, replace and cas:
+//
+//	<command> <key> <flags> <exptime> <bytes> [noreply]
+//	cas <key> <flags> <exptime> <bytes> <cas unique> [noreply]
+func (c *session) storage(name string, args []string) error {
+	quiet := noreply(args)
+	if quiet {
+		args = args[:len(args)-1]
+	}
+	want := 4
+	if name == "cas" {
+		want = 5
+	}
+	if len(args) != want {
+		c.reply("ERROR")
+		return nil
+	}
+
+	key := args[0]
+	flags, errFlags := strconv.ParseUint(args[1], 10, 32)
+	exptime, errExp := strconv.ParseInt(args[2], 10, 64)
+	size, errSize := strconv.Atoi(args[3])
+	var casUnique uint64
+	var errCAS error
+	if name == "cas" {
+		casUnique, errCAS = strconv.ParseUint(args[4], 10, 64)
+	}
+	if errSize != nil || size < 0 {
+		c.reply("CLIENT_ERROR bad command line format")
+		return nil
+	}
+
+	// Read the data block before anything else can fail, so the connection stays in sync
+	if size > c.maxData {
+		c.reply("SERVER_ERROR object too large for cache")
+		return errBadChunk
+	}
+	data := make([]byte, size+2)
+	if _, err := io.ReadFull(c.r, data); err != nil {
+		return err
+	}
+	if data[size] != '\r' || data[size+1] != '\n' {
+		c.reply("CLIENT_ERROR bad data chunk")
+		return errBadChunk
+	}
+	value := string(data[:size])
+
+	if errFlags != nil || errExp != nil || errCAS != nil {
+		c.replyUnless(quiet, "CLIENT_ERROR bad command line format")
+		return nil
+	}
+	c.server.stats.cmdSet.Add(1)
+	if err := kvstore.CheckLimits(c.server.limits, key, value); err != nil {
+		c.storeError(quiet, err)
+		return nil
+	}
+
+	items := c.server.items
+	reply, err := c.expiring(key, exptime, func() error {
+		var err error
+		switch name {
+		case "set":
+			_, err = items.SetItem(key, value, uint32(flags))
+		case "add":
+			_, err = items.AddItem(key, value, uint32(flags))
+		case "replace":
+			_, err = items.ReplaceItem(key, value, uint32(flags))
+		case "cas":
+			_, err = items.CompareAndSwap(key, value, uint32(flags), casUnique)
+		}
+		return err
+	})
+
+	switch {
+	case err == nil:
+		if name == "cas" {
+			c.server.stats.casHits.Add(1)
+		}
+		c.replyUnless(quiet, reply)
+	case name == "cas" && errors.Is(err, kvstore.ErrConflict):
+		c.server.stats.casBadval.Add(1)
+		c.replyUnless(quiet, "EXISTS")
+	case name == "cas" && errors.Is(err, kvstore.ErrNotFound):
+		c.server.stats.casMisses.Add(1)
+		c.replyUnless(quiet, "NOT_FOUND")
+	case errors.Is(err, kvstore.ErrConflict) || errors.Is(err, kvstore.ErrNotFound):
+		c.replyUnless(quiet, "NOT_STORED")
+	default:
+		c.storeError(quiet, err)
+	}
+	return nil
+}
+
+// expiring runs a write and then applies the expiration time to the key, replying STORED when it succeeds
+func (c *session) expiring(key string, exptime int64, write func() error) (string, error) {
+	ttl, expired := expiry(exptime)
+	expirer := c.server.expirer
+	if expirer == nil {
+		if ttl > 0 || expired {
+			return "", errors.New("expiration times are not supported")
+		}
+		return "STORED", write()
+	}
+
+	// Grant the lease first, so a write is not left without its expiry
+	var leaseID int64
+	if ttl > 0 {
+		l, err := expirer.Grant(ttl)
+		if err != nil {
+			return "", err
+		}
+		leaseID = l.ID
+	}
+	if err := write(); err != nil {
+		if leaseID != 0 {
+			expirer.Revoke(leaseID)
+		}
+		return "", err
+	}
+	return "STORED", c.expire(key, ttl, expired, leaseID)
+}
+
+// expire attaches a key to a lease, detaches it when it no longer expires, or deletes it when already expired
+func (c *session) expire(key string, ttl time.Duration, expired bool, leaseID int64) error {
+	expirer := c.server.expirer
+	switch {
+	case expired:
+		if err := c.server.items.Delete(key); err != nil {
+			return err
+		}
+		return expirer.Detach(key)
+	case ttl > 0:
+		err := expirer.Attach(key, leaseID)
+		if errors.Is(err, kvstore.ErrNotFound) {
+			// Deleted in the meantime
+			expirer.Revoke(leaseID)
+			return nil
+		}
+		return err
+	default:
+		return expirer.Detach(key)
+	}
+}
+
+// expiry converts a memcached expiration time: 0 never expires, up to 30 days is relative, larger values are Unix
+// timestamps, and negative values or past timestamps are already expired
+func expiry(exptime int64) (ttl time.Duration, expired bool) {
+	switch {
+	case exptime == 0:
+		return 0, false
+	case exptime < 0:
+		return 0, true
+	case exptime <= maxRelativeExp:
+		return time.Duration(exptime) * time.Second, false
+	}
+	ttl = time.Until(time.Unix(exptime, 0))
+	if ttl <= 0 {
+		return 0, true
+	}
+	return ttl, false
+}
+
+// delete removes an item: delete <key> [noreply]
+func (c *session) delete(args []string) {
+	quiet := noreply(args)
+	if quiet {
+		args = args[:len(args)-1]
+	}
+	// Old clients send a hold time of 0
+	if len(args) == 2 && args[1] == "0" {
+		args = args[:1]
+	}
+	if len(args) != 1 {
+		c.reply("CLIENT_ERROR bad command line format. Usage: delete <key> [noreply]")
+		return
+	}
+	key := args[0]
+	if err := kvstore.CheckKey(c.server.limits, key); err != nil {
+		c.storeError(quiet, err)
+		return
+	}
+
+	items := c.server.items
+	if _, err := items.Lookup(key); err != nil {
+		if errors.Is(err, kvstore.ErrNotFound) {
+			c.server.stats.deleteMisses.Add(1)
+			c.replyUnless(quiet, "NOT_FOUND")
+			return
+		}
+		c.storeError(quiet, err)
+		return
+	}
+	if err := items.Delete(key); err != nil {
+		c.storeError(quiet, err)
+		return
+	}
+	if c.server.expirer != nil {
+		if err := c.server.expirer.Detach(key); err != nil {
+			c.storeError(quiet, err)
+			return
+		}
+	}
+	c.server.stats.deleteHits.Add(1)
+	c.replyUnless(quiet, "DELETED")
+}
+
+// arithmetic runs incr and decr on a decimal unsigned 64 bit value: incr|decr <key> <value> [noreply].
+// Increments wrap around and decrements stop at 0, like memcached. The item keeps its flags and expiry.
+func (c *session) arithmetic(increment bool, args []string) {
+	quiet := noreply(args)
+	if quiet {
+		args = args[:len(args)-1]
+	}
+	if len(args) != 2 {
+		c.reply("ERROR")
+		return
+	}
+	key := args[0]
+	delta, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		c.replyUnless(quiet, "CLIENT_ERROR invalid numeric delta argument")
+		return
+	}
+	if err := kvstore.CheckKey(c.server.limits, key); err != nil {
+		c.storeError(quiet, err)
+		return
+	}
+
+	hits, misses := &c.server.stats.incrHits, &c.server.stats.incrMisses
+	if !increment {
+		hits, misses = &c.server.stats.decrHits, &c.server.stats.decrMisses
+	}
+
+	// Retry until no other write comes in between the read and the swap
+	items := c.server.items
+	for {
+		item, err := items.Lookup(key)
+		if errors.Is(err, kvstore.ErrNotFound) {
+			misses.Add(1)
+			c.replyUnless(quiet, "NOT_FOUND")
+			return
+		}
+		if err != nil {
+			c.storeError(quiet, err)
+			return
+		}
+		current, err := strconv.ParseUint(strings.TrimSpace(item.Value), 10, 64)
+		if err != nil {
+			c.replyUnless(quiet, "CLIENT_ERROR cannot increment or decrement non-numeric value")
+			return
+		}
+
+		result := current + delta
+		if !increment {
+			result = current - min(delta, current)
+		}
+		value := strconv.FormatUint(result, 10)
+		_, err = items.CompareAndSwap(key, value, item.Flags, item.Version)
+		if errors.Is(err, kvstore.ErrConflict) || errors.Is(err, kvstore.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			c.storeError(quiet, err)
+			return
+		}
+		hits.Add(1)
+		c.replyUnless(quiet, value)
+		return
+	}
+}
+
+// touch changes the expiration time of an item: touch <key> <exptime> [noreply]
+func (c *session) touch(args []string) {
+	quiet := noreply(args)
+	if quiet {
+		args = args[:len(args)-1]
+	}
+	if len(args) != 2 {
+		c.reply("ERROR")
+		return
+	}
+	key := args[0]
+	exptime, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		c.replyUnless(quiet, "CLIENT_ERROR invalid exptime argument")
+		return
+	}
+	c.server.stats.cmdTouch.Add(1)
+	if err := kvstore.CheckKey(c.server.limits, key); err != nil {
+		c.storeError(quiet, err)
+		return
+	}
+
+	if _, err := c.server.items.Lookup(key); err != nil {
+		if errors.Is(err, kvstore.ErrNotFound) {
+			c.server.stats.touchMisses.Add(1)
+			c.replyUnless(quiet, "NOT_FOUND")
+			return
+		}
+		c.storeError(quiet, err)
+		return
+	}
+	if _, err := c.expiring(key, exptime, func() error { return nil }); err != nil {
+		c.storeError(quiet, err)
+		return
+	}
+	c.server.stats.touchHits.Add(1)
+	c.replyUnless(quiet, "TOUCHED")
+}
+
+// stats reports the general statistics
+func (c *session) stats() {
+	s := c.server
+	now := time.Now()
+	stat := func(name string, value any) {
+		c.reply(fmt.Sprintf("STAT %s %v", name, value))
+	}
+	stat("pid", os.Getpid())
+	stat("uptime", int64(now.Sub(s.started).Seconds()))
+	stat("time", now.Unix())
+	stat("version", Version)
+	stat("curr_connections", s.stats.currConnections.Load())
+	stat("total_connections", s.stats.totalConnections.Load())
+	stat("cmd_get", s.stats.cmdGet.Load())
+	stat("cmd_set", s.stats.cmdSet.Load())
+	stat("cmd_touch", s.stats.cmdTouch.Load())
+	stat("get_hits", s.stats.getHits.Load())
+	stat("get_misses", s.stats.getMisses.Load())
+	stat("delete_hits", s.stats.deleteHits.Load())
+	stat("delete_misses", s.stats.deleteMisses.Load())
+	stat("incr_hits", s.stats.incrHits.Load())
+	stat("incr_misses", s.stats.incrMisses.Load())
+	stat("decr_hits", s.stats.decrHits.Load())
+	stat("decr_misses", s.stats.decrMisses.Load())
+	stat("cas_hits", s.stats.casHits.Load())
+	stat("cas_misses", s.stats.casMisses.Load())
+	stat("cas_badval", s.stats.casBadval.Load())
+	stat("touch_hits", s.stats.touchHits.Load())
+	stat("touch_misses", s.stats.touchMisses.Load())
+	c.reply("END")
+}
@@ -0,0 +1,243 @@
+// Package memcache serves the key-value store over the memcached text protocol, so legacy memcached clients can
+// read and write it. Item flags and CAS values come from the key metadata of meta.Store, and expiration times
+// from leases.
+package memcache
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"key-value/services/key-value/internal/lease"
+	"key-value/services/key-value/internal/meta"
+	"key-value/shared/limits"
+	"log"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrServerClosed is returned by Serve after Close
+var ErrServerClosed = errors.New("memcache: server closed")
+
+// Protocol bounds, protecting the server from oversized requests
+const (
+	maxLineLength  = 8 << 10    // bytes of a command line
+	defaultMaxData = 1 << 20    // bytes of a data block when the value size is not limited, like memcached
+	dataSlack      = 64 << 10   // data blocks this much over the limit are read and rejected instead of closing the connection
+	maxRelativeExp = 30 * 86400 // larger expiration times are Unix timestamps
+)
+
+// Version is reported by the version and stats commands
+const Version = "1.0.0"
+
+// Expirer expires keys after a time to live. Expiration times need it, as lease.Store implements it.
+type Expirer interface {
+	Grant(ttl time.Duration) (lease.Lease, error)
+	Revoke(id int64) error
+	Attach(key string, id int64) error
+	Detach(key string) error
+}
+
+// stats counts the commands served, reported by the stats command
+type stats struct {
+	currConnections  atomic.Int64
+	totalConnections atomic.Uint64
+	cmdGet           atomic.Uint64
+	cmdSet           atomic.Uint64
+	cmdTouch         atomic.Uint64
+	getHits          atomic.Uint64
+	getMisses        atomic.Uint64
+	deleteHits       atomic.Uint64
+	deleteMisses     atomic.Uint64
+	incrHits         atomic.Uint64
+	incrMisses       atomic.Uint64
+	decrHits         atomic.Uint64
+	decrMisses       atomic.Uint64
+	casHits          atomic.Uint64
+	casMisses        atomic.Uint64
+	casBadval        atomic.Uint64
+	touchHits        atomic.Uint64
+	touchMisses      atomic.Uint64
+}
+
+// Server serves memcached text protocol connections over a store
+type Server struct {
+	items   *meta.Store
+	expirer Expirer
+	limits  limits.Limits
+	started time.Time
+	stats   stats
+
+	mutex     sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithLimits sets the key and value limits enforced before commands reach the store
+func WithLimits(l limits.Limits) Option {
+	return func(s *Server) {
+		s.limits = l
+	}
+}
+
+// WithExpirer expires items through e. Without it, storing an item with an expiration time fails.
+// Deleted items are detached from their lease through e, so the store must sit below it.
+func WithExpirer(e Expirer) Option {
+	return func(s *Server) {
+		s.expirer = e
+	}
+}
+
+// NewServer creates a memcached server over items enforcing limits.Default() unless WithLimits is given
+func NewServer(items *meta.Store, opts ...Option) *Server {
+	s := &Server{
+		items:     items,
+		limits:    limits.Default(),
+		started:   time.Now(),
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Serve accepts connections on lis until Close, then returns ErrServerClosed
+func (s *Server) Serve(lis net.Listener) error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		lis.Close()
+		return ErrServerClosed
+	}
+	s.listeners[lis] = struct{}{}
+	s.mutex.Unlock()
+
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			s.mutex.Lock()
+			closed := s.closed
+			delete(s.listeners, lis)
+			s.mutex.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+
+		s.mutex.Lock()
+		if s.closed {
+			s.mutex.Unlock()
+			conn.Close()
+			return ErrServerClosed
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mutex.Unlock()
+
+		go s.serveConn(conn)
+	}
+}
+
+// Close stops the listeners, closes the connections and waits for their commands to finish
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	s.closed = true
+	for lis := range s.listeners {
+		lis.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mutex.Unlock()
+	s.wg.Wait()
+	return nil
+}
+
+// session is the state of one client connection
+type session struct {
+	server  *Server
+	r       *bufio.Reader
+	w       *bufio.Writer
+	maxData int
+	quit    bool
+}
+
+// serveConn runs the commands of a connection until it is closed or sends quit
+func (s *Server) serveConn(conn net.Conn) {
+	defer s.wg.Done()
+	s.stats.currConnections.Add(1)
+	s.stats.totalConnections.Add(1)
+	defer func() {
+		s.stats.currConnections.Add(-1)
+		s.mutex.Lock()
+		delete(s.conns, conn)
+		s.mutex.Unlock()
+		conn.Close()
+	}()
+
+	maxData := defaultMaxData
+	if s.limits.MaxValueSize > 0 {
+		maxData = s.limits.MaxValueSize
+	}
+	c := &session{
+		server:  s,
+		r:       bufio.NewReader(conn),
+		w:       bufio.NewWriter(conn),
+		maxData: maxData + dataSlack,
+	}
+
+	for !c.quit {
+		line, err := c.line()
+		if err != nil {
+			if errors.Is(err, errLineTooLong) {
+				c.w.WriteString("CLIENT_ERROR line too long\r\n")
+				c.w.Flush()
+			} else if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				log.Printf("memcache: reading from %s: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+
+		if err := c.run(line); err != nil {
+			// The connection is out of sync with the client
+			c.w.Flush()
+			return
+		}
+
+		// Flush once the pipelined commands received so far are answered
+		if c.r.Buffered() == 0 || c.quit {
+			if err := c.w.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+var errLineTooLong = errors.New("line too long")
+
+// line reads a command line without its terminator
+func (c *session) line() (string, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := c.r.ReadLine()
+		if err != nil {
+			return "", err
+		}
+		line = append(line, chunk...)
+		if len(line) > maxLineLength {
+			return "", errLineTooLong
+		}
+		if !isPrefix {
+			return string(line), nil
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package memcache
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/services/key-value/internal/lease"
+	"key-value/services/key-value/internal/meta"
+	"key-value/shared/limits"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serve starts a server over items and returns its address
+func serve(t *testing.T, items *meta.Store, opts ...Option) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	opts = append([]Option{WithLimits(limits.Limits{MaxKeyLength: 16, MaxValueSize: 64})}, opts...)
+	server := NewServer(items, opts...)
+	go server.Serve(lis)
+	t.Cleanup(func() { server.Close() })
+	return lis.Addr().String()
+}
+
+// serveLeases starts a server whose items expire through a lease store on top of them
+func serveLeases(t *testing.T) string {
+	t.Helper()
+	items := meta.NewStore(kvstore.NewInMemoryStore())
+	leases := lease.NewStore(items, lease.WithCheckInterval(5*time.Millisecond))
+	t.Cleanup(func() { leases.Close() })
+	return serve(t, items, WithExpirer(leases))
+}
+
+// conn is a raw client connection
+type conn struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dial(t *testing.T, addr string) *conn {
+	t.Helper()
+	c, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+	return &conn{t: t, conn: c, r: bufio.NewReader(c)}
+}
+
+// do sends a raw request and asserts the raw reply
+func (c *conn) do(request string, reply string) {
+	c.t.Helper()
+	_, err := c.conn.Write([]byte(request))
+	require.NoError(c.t, err)
+	c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, len(reply))
+	_, err = io.ReadFull(c.r, buf)
+	require.NoError(c.t, err, "waiting for %q", reply)
+	assert.Equal(c.t, reply, string(buf), "reply to %q", request)
+}
+
+// line sends a raw request and returns the first line of its reply
+func (c *conn) line(request string) string {
+	c.t.Helper()
+	_, err := c.conn.Write([]byte(request))
+	require.NoError(c.t, err)
+	c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	line, err := c.r.ReadString('\n')
+	require.NoError(c.t, err)
+	return strings.TrimSuffix(line, "\r\n")
+}
+
+func TestServer_Commands(t *testing.T) {
+	c := dial(t, serveLeases(t))
+
+	tests := []struct {
+		name    string
+		request string
+		reply   string
+	}{
+		{"get missing", "get a\r\n", "END\r\n"},
+		{"set", "set a 5 0 1\r\n1\r\n", "STORED\r\n"},
+		{"get", "get a\r\n", "VALUE a 5 1\r\n1\r\nEND\r\n"},
+		{"get many", "get a missing a\r\n", "VALUE a 5 1\r\n1\r\nVALUE a 5 1\r\n1\r\nEND\r\n"},
+		{"add existing", "add a 0 0 1\r\n2\r\n", "NOT_STORED\r\n"},
+		{"add missing", "add b 0 0 1\r\n2\r\n", "STORED\r\n"},
+		{"replace missing", "replace c 0 0 1\r\n3\r\n", "NOT_STORED\r\n"},
+		{"replace existing", "replace b 9 0 2\r\n30\r\n", "STORED\r\n"},
+		{"empty value", "set e 0 0 0\r\n\r\nget e\r\n", "STORED\r\nVALUE e 0 0\r\n\r\nEND\r\n"},
+		{"incr", "incr b 12\r\n", "42\r\n"},
+		{"incr keeps flags", "get b\r\n", "VALUE b 9 2\r\n42\r\nEND\r\n"},
+		{"decr", "decr b 2\r\n", "40\r\n"},
+		{"decr stops at zero", "decr b 100\r\n", "0\r\n"},
+		{"incr wraps", "set w 0 0 20\r\n18446744073709551615\r\nincr w 2\r\n", "STORED\r\n1\r\n"},
+		{"incr missing", "incr missing 1\r\n", "NOT_FOUND\r\n"},
+		{"incr text", "set t 0 0 1\r\nx\r\nincr t 1\r\n", "STORED\r\nCLIENT_ERROR cannot increment or decrement non-numeric value\r\n"},
+		{"incr bad delta", "incr b x\r\n", "CLIENT_ERROR invalid numeric delta argument\r\n"},
+		{"delete", "delete b\r\n", "DELETED\r\n"},
+		{"delete missing", "delete b\r\n", "NOT_FOUND\r\n"},
+		{"noreply", "set n 0 0 1 noreply\r\n1\r\ndelete n noreply\r\nget n\r\n", "END\r\n"},
+		{"touch", "touch a 100\r\n", "TOUCHED\r\n"},
+		{"touch missing", "touch missing 100\r\n", "NOT_FOUND\r\n"},
+		{"bad format", "set a x 0 1\r\n1\r\n", "CLIENT_ERROR bad command line format\r\n"},
+		{"wrong arguments", "set a 0 0\r\n", "ERROR\r\n"},
+		{"key too long", "set " + strings.Repeat("k", 17) + " 0 0 1\r\n1\r\n", "CLIENT_ERROR invalid key: key too long"},
+		{"value too large", "set v 0 0 65\r\n" + strings.Repeat("v", 65) + "\r\n", "SERVER_ERROR object too large for cache\r\n"},
+		{"version", "version\r\n", "VERSION " + Version + "\r\n"},
+		{"unknown command", "flush_all\r\n", "ERROR\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.t = t
+			c.do(tt.request, tt.reply)
+			if strings.HasSuffix(tt.reply, "\r\n") {
+				return
+			}
+			// Skip the rest of a reply matched by prefix
+			_, err := c.r.ReadString('\n')
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestServer_CompareAndSwap(t *testing.T) {
+	c := dial(t, serve(t, meta.NewStore(kvstore.NewInMemoryStore())))
+
+	c.do("cas a 0 0 1 1\r\n1\r\n", "NOT_FOUND\r\n")
+	c.do("set a 3 0 1\r\n1\r\n", "STORED\r\n")
+
+	header := strings.Fields(c.line("gets a\r\n"))
+	require.Len(t, header, 5)
+	assert.Equal(t, []string{"VALUE", "a", "3", "1"}, header[:4])
+	c.do("", "1\r\nEND\r\n")
+	unique := header[4]
+
+	c.do("cas a 4 0 1 "+unique+"\r\n2\r\n", "STORED\r\n")
+	c.do("cas a 0 0 1 "+unique+"\r\n3\r\n", "EXISTS\r\n")
+	c.do("get a\r\n", "VALUE a 4 1\r\n2\r\nEND\r\n")
+	c.do("cas a 0 0 1 x\r\n3\r\n", "CLIENT_ERROR bad command line format\r\n")
+
+	stats := map[string]string{}
+	c.line("stats\r\n")
+	for {
+		line := c.line("")
+		if line == "END" {
+			break
+		}
+		fields := strings.Fields(line)
+		require.Len(t, fields, 3)
+		stats[fields[1]] = fields[2]
+	}
+	assert.Equal(t, "1", stats["cas_hits"])
+	assert.Equal(t, "1", stats["cas_misses"])
+	assert.Equal(t, "1", stats["cas_badval"])
+	assert.Equal(t, Version, stats["version"])
+}
+
+func TestServer_Expiry(t *testing.T) {
+	c := dial(t, serveLeases(t))
+
+	c.do("set session 0 1 5\r\nalice\r\n", "STORED\r\n")
+	c.do("set config 0 100 2\r\nv1\r\n", "STORED\r\n")
+	c.do("set config 0 0 2\r\nv2\r\n", "STORED\r\n") // storing without an expiration time removes it
+	c.do("set gone 0 -1 1\r\nx\r\nget gone\r\n", "STORED\r\nEND\r\n")
+	c.do("set past 0 1000000000 1\r\nx\r\nget past\r\n", "STORED\r\nEND\r\n")
+	c.do("set later 0 "+strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)+" 1\r\nx\r\nget later\r\n",
+		"STORED\r\nVALUE later 0 1\r\nx\r\nEND\r\n")
+	c.do("set touched 0 0 1\r\nx\r\ntouch touched -1\r\nget touched\r\n", "STORED\r\nTOUCHED\r\nEND\r\n")
+
+	assert.Eventually(t, func() bool {
+		if c.line("get session\r\n") == "END" {
+			return true
+		}
+		c.do("", "alice\r\nEND\r\n")
+		return false
+	}, 3*time.Second, 50*time.Millisecond)
+	c.do("get config\r\n", "VALUE config 0 2\r\nv2\r\nEND\r\n")
+}
+
+func TestServer_ExpiryNeedsExpirer(t *testing.T) {
+	c := dial(t, serve(t, meta.NewStore(kvstore.NewInMemoryStore())))
+
+	c.do("set a 0 10 1\r\n1\r\n", "SERVER_ERROR expiration times are not supported\r\n")
+	c.do("set a 0 0 1\r\n1\r\n", "STORED\r\n")
+}
+
+func TestServer_Protocol(t *testing.T) {
+	addr := serve(t, meta.NewStore(kvstore.NewInMemoryStore()))
+
+	t.Run("pipelined", func(t *testing.T) {
+		c := dial(t, addr)
+		c.do("set p 0 0 1\r\n1\r\nincr p 1\r\nget p\r\n", "STORED\r\n2\r\nVALUE p 0 1\r\n2\r\nEND\r\n")
+	})
+
+	t.Run("quit", func(t *testing.T) {
+		c := dial(t, addr)
+		c.do("quit\r\n", "")
+		_, err := c.r.ReadByte()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("bad data chunk", func(t *testing.T) {
+		c := dial(t, addr)
+		c.do("set a 0 0 1\r\n12\r\n", "CLIENT_ERROR bad data chunk\r\n")
+		_, err := c.r.ReadByte()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("oversized data", func(t *testing.T) {
+		c := dial(t, addr)
+		c.do("set a 0 0 100000000\r\n", "SERVER_ERROR object too large for cache\r\n")
+		_, err := c.r.ReadByte()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("line too long", func(t *testing.T) {
+		c := dial(t, addr)
+		c.do("get "+strings.Repeat("k", maxLineLength)+"\r\n", "CLIENT_ERROR line too long\r\n")
+	})
+}
+
+func TestExpiry(t *testing.T) {
+	tests := []struct {
+		name    string
+		exptime int64
+		ttl     time.Duration
+		expired bool
+	}{
+		{"never", 0, 0, false},
+		{"negative", -1, 0, true},
+		{"relative", 60, time.Minute, false},
+		{"longest relative", maxRelativeExp, maxRelativeExp * time.Second, false},
+		{"past timestamp", maxRelativeExp + 1, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ttl, expired := expiry(tt.exptime)
+			assert.Equal(t, tt.ttl, ttl)
+			assert.Equal(t, tt.expired, expired)
+		})
+	}
+}
@@ -0,0 +1,296 @@
+// Package meta keeps metadata next to the keys written through the memcached protocol: opaque client flags and a
+// version that changes with every write, so clients can make compare-and-swap writes.
+package meta
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"key-value/services/key-value/internal/kvstore"
+	"maps"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Reserved keys holding the metadata in the wrapped store
+const (
+	versionKey = kvstore.ReservedPrefix + "meta/version" // last version handed out
+	itemPrefix = kvstore.ReservedPrefix + "meta/item/"   // followed by a key, holds its flags, version and fingerprint
+)
+
+// derivedVersion is set in the versions of keys written outside the item methods, which are derived from their value
+// and never collide with the versions handed out
+const derivedVersion = 1 << 63
+
+// maxAttempts bounds the retries of an item write whose version was handed out concurrently, like by a former leader
+const maxAttempts = 8
+
+// Item is a value with its metadata
+type Item struct {
+	Value string
+	Flags uint32
+	// Version changes with every write of the key through the item methods and is never reused. Keys last written
+	// some other way have a version derived from their value, with no flags.
+	Version uint64
+}
+
+// meta is the metadata of a key
+type meta struct {
+	flags       uint32
+	version     uint64
+	fingerprint string // of the value written with the metadata, telling whether it is still current
+}
+
+// Store wraps a store and keeps the metadata of the keys written through its item methods in reserved keys of the
+// wrapped store, so Raft or replication below it replicate and persist it like the values. An item is written with
+// its metadata and the next version as one batch, a single command for Raft, and its conditions are checked in the
+// same batch, so no other write slips in between.
+//
+// Other writes pass through as they are: the metadata records a fingerprint of its value, so a key changed some other
+// way loses its flags and gets a version derived from its new value. Only deletes also remove the metadata. Without a
+// kvstore.Batcher below, the batches are made one call at a time with the metadata after the value, so a write cut
+// short leaves metadata that no longer matches rather than metadata of a value never written.
+type Store struct {
+	kvstore.Layer
+	mutex sync.Mutex // orders the item writes, so they do not race for the next version
+}
+
+// NewStore starts keeping the metadata of the items written through the returned store
+func NewStore(store kvstore.Storer) *Store {
+	return &Store{Layer: kvstore.Layer{Next: store}}
+}
+
+// Lookup reads a key with its metadata
+func (s *Store) Lookup(key string) (Item, error) {
+	value, err := s.Next.Get(key)
+	if err != nil {
+		return Item{}, err
+	}
+	encoded, err := s.Next.Get(itemPrefix + key)
+	if errors.Is(err, kvstore.ErrNotFound) {
+		return Item{Value: value, Version: derive(value)}, nil
+	}
+	if err != nil {
+		return Item{}, err
+	}
+	m, err := decode(encoded)
+	if err != nil {
+		return Item{}, err
+	}
+	if m.fingerprint != fingerprint(value) {
+		return Item{Value: value, Version: derive(value)}, nil
+	}
+	return Item{Value: value, Flags: m.flags, Version: m.version}, nil
+}
+
+// SetItem stores a key-value pair with flags and returns its new version
+func (s *Store) SetItem(key string, value string, flags uint32) (uint64, error) {
+	return s.write(key, value, flags, func() ([]kvstore.Check, error) {
+		return nil, nil
+	})
+}
+
+// AddItem stores a key-value pair with flags only if the key does not exist, failing with kvstore.ErrConflict
+// otherwise
+func (s *Store) AddItem(key string, value string, flags uint32) (uint64, error) {
+	return s.write(key, value, flags, func() ([]kvstore.Check, error) {
+		_, err := s.Next.Get(key)
+		switch {
+		case err == nil:
+			return nil, fmt.Errorf("key %s already exists: %w", key, kvstore.ErrConflict)
+		case !errors.Is(err, kvstore.ErrNotFound):
+			return nil, err
+		}
+		return []kvstore.Check{{Key: key, Absent: true}}, nil
+	})
+}
+
+// ReplaceItem stores a key-value pair with flags only if the key exists, failing with kvstore.ErrNotFound otherwise
+func (s *Store) ReplaceItem(key string, value string, flags uint32) (uint64, error) {
+	return s.write(key, value, flags, func() ([]kvstore.Check, error) {
+		current, err := s.Next.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		return []kvstore.Check{{Key: key, Value: current}}, nil
+	})
+}
+
+// CompareAndSwap stores a key-value pair with flags only if the key is still at version. It fails with
+// kvstore.ErrNotFound when the key does not exist and with kvstore.ErrConflict when it changed since.
+func (s *Store) CompareAndSwap(key string, value string, flags uint32, version uint64) (uint64, error) {
+	return s.write(key, value, flags, func() ([]kvstore.Check, error) {
+		item, err := s.Lookup(key)
+		if err != nil {
+			return nil, err
+		}
+		if item.Version != version {
+			return nil, fmt.Errorf("key %s is at version %d, not %d: %w", key, item.Version, version, kvstore.ErrConflict)
+		}
+		return []kvstore.Check{{Key: key, Value: item.Value}}, nil
+	})
+}
+
+// Delete removes a key and its metadata
+func (s *Store) Delete(key string) error {
+	return s.batch(nil, s.withMetadata([]kvstore.Write{{Key: key, Delete: true}}))
+}
+
+// DeleteIfValue removes a key and its metadata only if it holds value
+func (s *Store) DeleteIfValue(key string, value string) error {
+	err := s.Batch([]kvstore.Check{{Key: key, Value: value}}, []kvstore.Write{{Key: key, Delete: true}})
+	if errors.Is(err, kvstore.ErrUnsupported) {
+		if err := s.Layer.DeleteIfValue(key, value); err != nil {
+			return err
+		}
+		return s.Next.Delete(itemPrefix + key)
+	}
+	return err
+}
+
+// Batch passes a batch through, removing the metadata of the keys it deletes with them
+func (s *Store) Batch(checks []kvstore.Check, writes []kvstore.Write) error {
+	return kvstore.Batch(s.Next, checks, s.withMetadata(writes))
+}
+
+// Restore replaces the data of the store. The metadata in data is restored with it, its fingerprints telling which
+// of it is still current, while the versions keep growing from the last one handed out before.
+func (s *Store) Restore(data map[string]string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	last, err := s.lastVersion(false)
+	if err != nil {
+		return err
+	}
+	if stored, ok := data[versionKey]; ok {
+		restored, err := strconv.ParseUint(stored, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid metadata version %q", stored)
+		}
+		last = max(last, restored)
+	}
+	if last == 0 {
+		return s.Layer.Restore(data)
+	}
+	restored := make(map[string]string, len(data)+1)
+	maps.Copy(restored, data)
+	restored[versionKey] = strconv.FormatUint(last, 10)
+	return s.Layer.Restore(restored)
+}
+
+// write stores an item under the next version, once the checks returned by prepare hold in the same batch. prepare
+// fails the write instead when it cannot be made, and runs again when the version was taken in the meantime.
+func (s *Store) write(key string, value string, flags uint32, prepare func() ([]kvstore.Check, error)) (uint64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var err error
+	for attempt := range maxAttempts {
+		var checks []kvstore.Check
+		if checks, err = prepare(); err != nil {
+			return 0, err
+		}
+		var last uint64
+		if last, err = s.lastVersion(attempt == 0); err != nil {
+			return 0, err
+		}
+		if last == 0 {
+			checks = append(checks, kvstore.Check{Key: versionKey, Absent: true})
+		} else {
+			checks = append(checks, kvstore.Check{Key: versionKey, Value: strconv.FormatUint(last, 10)})
+		}
+		version := last + 1
+		m := meta{flags: flags, version: version, fingerprint: fingerprint(value)}
+		err = s.batch(checks, []kvstore.Write{
+			{Key: versionKey, Value: strconv.FormatUint(version, 10)},
+			{Key: key, Value: value},
+			{Key: itemPrefix + key, Value: encode(m)},
+		})
+		if !errors.Is(err, kvstore.ErrConflict) {
+			if err != nil {
+				return 0, err
+			}
+			return version, nil
+		}
+	}
+	return 0, err
+}
+
+// batch commits a batch to the wrapped store, making it one call at a time when the store has no batches
+func (s *Store) batch(checks []kvstore.Check, writes []kvstore.Write) error {
+	err := kvstore.Batch(s.Next, checks, writes)
+	if errors.Is(err, kvstore.ErrUnsupported) {
+		return kvstore.ApplyBatch(s.Next, checks, writes)
+	}
+	return err
+}
+
+// withMetadata adds the removal of their metadata after the deletes of writes
+func (s *Store) withMetadata(writes []kvstore.Write) []kvstore.Write {
+	var deletes []kvstore.Write
+	for _, write := range writes {
+		if write.Delete && !kvstore.IsReserved(write.Key) {
+			deletes = append(deletes, kvstore.Write{Key: itemPrefix + write.Key, Delete: true})
+		}
+	}
+	if len(deletes) == 0 {
+		return writes
+	}
+	return append(append([]kvstore.Write(nil), writes...), deletes...)
+}
+
+// lastVersion reads the last version handed out. A stale read saves the round trip of a linearizable one for writes
+// that check the version they read anyway.
+func (s *Store) lastVersion(stale bool) (uint64, error) {
+	var stored string
+	var err error
+	if stale {
+		stored, err = kvstore.StaleGet(s.Next, versionKey)
+	}
+	if !stale || errors.Is(err, kvstore.ErrUnsupported) {
+		stored, err = s.Next.Get(versionKey)
+	}
+	if errors.Is(err, kvstore.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(stored, 10, 64)
+}
+
+// fingerprint identifies a value without keeping a copy of it
+func fingerprint(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:16])
+}
+
+// derive returns the version of a value written outside the item methods
+func derive(value string) uint64 {
+	sum := sha256.Sum256([]byte(value))
+	return derivedVersion | binary.BigEndian.Uint64(sum[:])
+}
+
+// encode stores metadata as its flags, version and fingerprint separated by spaces
+func encode(m meta) string {
+	return strconv.FormatUint(uint64(m.flags), 10) + " " + strconv.FormatUint(m.version, 10) + " " + m.fingerprint
+}
+
+// decode reads metadata written by encode
+func decode(encoded string) (meta, error) {
+	fields := strings.Fields(encoded)
+	if len(fields) != 3 {
+		return meta{}, fmt.Errorf("invalid key metadata %q", encoded)
+	}
+	flags, err := strconv.ParseUint(fields[0], 10, 32)
+	if err != nil {
+		return meta{}, fmt.Errorf("invalid key metadata %q", encoded)
+	}
+	version, err := strconv.ParseUint(fields[1], 10, 64)
+	if err != nil {
+		return meta{}, fmt.Errorf("invalid key metadata %q", encoded)
+	}
+	return meta{flags: uint32(flags), version: version, fingerprint: fields[2]}, nil
+}
@@ -0,0 +1,214 @@
+package meta
+
+import (
+	"sync"
+	"testing"
+
+	"key-value/services/key-value/internal/kvstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Items(t *testing.T) {
+	s := NewStore(kvstore.NewInMemoryStore())
+
+	_, err := s.Lookup("a")
+	assert.ErrorIs(t, err, kvstore.ErrNotFound)
+	_, err = s.ReplaceItem("a", "1", 7)
+	assert.ErrorIs(t, err, kvstore.ErrNotFound)
+
+	v1, err := s.AddItem("a", "1", 7)
+	require.NoError(t, err)
+	_, err = s.AddItem("a", "2", 0)
+	assert.ErrorIs(t, err, kvstore.ErrConflict)
+
+	item, err := s.Lookup("a")
+	require.NoError(t, err)
+	assert.Equal(t, Item{Value: "1", Flags: 7, Version: v1}, item)
+
+	v2, err := s.ReplaceItem("a", "2", 8)
+	require.NoError(t, err)
+	assert.Greater(t, v2, v1)
+
+	// Other writes lose the flags and get a version derived from the value
+	version, err := s.SetItem("b", "5", 3)
+	require.NoError(t, err)
+	_, err = s.Increment("b", 1)
+	require.NoError(t, err)
+	item, err = s.Lookup("b")
+	require.NoError(t, err)
+	assert.Equal(t, Item{Value: "6", Version: derive("6")}, item)
+	assert.NotEqual(t, version, item.Version)
+	require.NoError(t, s.Set("b", "x"))
+	item, err = s.Lookup("b")
+	require.NoError(t, err)
+	assert.Equal(t, Item{Value: "x", Version: derive("x")}, item)
+}
+
+func TestStore_CompareAndSwap(t *testing.T) {
+	base := kvstore.NewInMemoryStore()
+	s := NewStore(base)
+
+	_, err := s.CompareAndSwap("a", "1", 0, 1)
+	assert.ErrorIs(t, err, kvstore.ErrNotFound)
+
+	version, err := s.SetItem("a", "1", 0)
+	require.NoError(t, err)
+	_, err = s.CompareAndSwap("a", "2", 0, version+1)
+	assert.ErrorIs(t, err, kvstore.ErrConflict)
+	next, err := s.CompareAndSwap("a", "2", 4, version)
+	require.NoError(t, err)
+	_, err = s.CompareAndSwap("a", "3", 0, version)
+	assert.ErrorIs(t, err, kvstore.ErrConflict, "a version is only good for one swap")
+
+	// Deleting and recreating a key never brings an old version back
+	require.NoError(t, s.Delete("a"))
+	_, err = s.SetItem("a", "2", 4)
+	require.NoError(t, err)
+	item, err := s.Lookup("a")
+	require.NoError(t, err)
+	assert.Greater(t, item.Version, next)
+
+	// Keys written below the store have a version derived from their value, which changes with it
+	require.NoError(t, base.Set("old", "x"))
+	item, err = s.Lookup("old")
+	require.NoError(t, err)
+	require.NoError(t, base.Set("old", "changed"))
+	_, err = s.CompareAndSwap("old", "y", 0, item.Version)
+	assert.ErrorIs(t, err, kvstore.ErrConflict)
+	item, err = s.Lookup("old")
+	require.NoError(t, err)
+	_, err = s.CompareAndSwap("old", "y", 0, item.Version)
+	require.NoError(t, err)
+
+	// So do keys written below the store after an item write
+	version, err = s.SetItem("c", "1", 4)
+	require.NoError(t, err)
+	require.NoError(t, base.Set("c", "2"))
+	_, err = s.CompareAndSwap("c", "3", 0, version)
+	assert.ErrorIs(t, err, kvstore.ErrConflict)
+}
+
+func TestStore_KeepsMetadataInTheWrappedStore(t *testing.T) {
+	base := kvstore.NewInMemoryStore()
+	version, err := NewStore(base).SetItem("a", "1", 7)
+	require.NoError(t, err)
+
+	// A new store over the same data, like after a restart or a failover, keeps the metadata
+	s := NewStore(base)
+	item, err := s.Lookup("a")
+	require.NoError(t, err)
+	assert.Equal(t, Item{Value: "1", Flags: 7, Version: version}, item)
+	next, err := s.SetItem("b", "2", 0)
+	require.NoError(t, err)
+	assert.Greater(t, next, version, "versions must not be reused")
+
+	// Deleting a key removes its metadata
+	require.NoError(t, s.Delete("a"))
+	_, err = base.Get(itemPrefix + "a")
+	assert.ErrorIs(t, err, kvstore.ErrNotFound)
+}
+
+func TestStore_Restore(t *testing.T) {
+	base := kvstore.NewInMemoryStore()
+	s := NewStore(base)
+	version, err := s.SetItem("a", "1", 7)
+	require.NoError(t, err)
+	data, err := s.Snapshot()
+	require.NoError(t, err)
+
+	_, err = s.SetItem("b", "2", 0)
+	require.NoError(t, err)
+
+	// Restoring the snapshot brings the metadata back, but not the versions handed out since
+	require.NoError(t, s.Restore(data))
+	item, err := s.Lookup("a")
+	require.NoError(t, err)
+	assert.Equal(t, Item{Value: "1", Flags: 7, Version: version}, item)
+	next, err := s.SetItem("b", "2", 0)
+	require.NoError(t, err)
+	assert.Equal(t, version+2, next)
+
+	// A backup holds no metadata
+	require.NoError(t, s.Restore(map[string]string{"a": "1"}))
+	item, err = s.Lookup("a")
+	require.NoError(t, err)
+	assert.Equal(t, Item{Value: "1", Version: derive("1")}, item)
+	next, err = s.SetItem("b", "2", 0)
+	require.NoError(t, err)
+	assert.Equal(t, version+3, next)
+}
+
+func TestStore_PassesReservedKeysThrough(t *testing.T) {
+	base := kvstore.NewInMemoryStore()
+	s := NewStore(base)
+	key := kvstore.ReservedPrefix + "other/state"
+
+	require.NoError(t, s.Set(key, "1"))
+	_, err := base.Get(itemPrefix + key)
+	assert.ErrorIs(t, err, kvstore.ErrNotFound, "reserved keys get no metadata")
+	require.NoError(t, s.Delete(key))
+	_, err = base.Get(key)
+	assert.ErrorIs(t, err, kvstore.ErrNotFound)
+}
+
+// batchStore commits batches like a replicated store, counting them
+type batchStore struct {
+	*kvstore.InMemoryStore
+	mutex   sync.Mutex
+	batches int
+	before  func() // runs before the next batch is applied
+}
+
+func (s *batchStore) Batch(checks []kvstore.Check, writes []kvstore.Write) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.batches++
+	if before := s.before; before != nil {
+		s.before = nil
+		before()
+	}
+	return kvstore.ApplyBatch(s.InMemoryStore, checks, writes)
+}
+
+func TestStore_Batches(t *testing.T) {
+	base := &batchStore{InMemoryStore: kvstore.NewInMemoryStore()}
+	s := NewStore(base)
+
+	// An item is written with its metadata in one batch
+	version, err := s.SetItem("a", "1", 7)
+	require.NoError(t, err)
+	assert.Equal(t, 1, base.batches)
+	_, err = s.CompareAndSwap("a", "2", 7, version)
+	require.NoError(t, err)
+	assert.Equal(t, 2, base.batches)
+
+	// A version handed out elsewhere, like by a former leader, is not reused
+	base.before = func() { base.InMemoryStore.Set(versionKey, "10") }
+	version, err = s.SetItem("a", "3", 7)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(11), version)
+	assert.Equal(t, 4, base.batches)
+
+	// So is a key changed between the lookup and the batch of a conditional write
+	base.before = func() { base.InMemoryStore.Set("a", "changed") }
+	_, err = s.CompareAndSwap("a", "4", 7, version)
+	assert.ErrorIs(t, err, kvstore.ErrConflict)
+	value, err := base.Get("a")
+	require.NoError(t, err)
+	assert.Equal(t, "changed", value)
+
+	// Deletes remove the metadata in the same batch
+	batches := base.batches
+	require.NoError(t, s.Delete("a"))
+	assert.Equal(t, batches+1, base.batches)
+	_, err = base.Get(itemPrefix + "a")
+	assert.ErrorIs(t, err, kvstore.ErrNotFound)
+
+	// Plain writes pass through untouched
+	require.NoError(t, s.Set("b", "1"))
+	assert.Equal(t, batches+1, base.batches)
+	_, err = base.Get(itemPrefix + "b")
+	assert.ErrorIs(t, err, kvstore.ErrNotFound)
+}
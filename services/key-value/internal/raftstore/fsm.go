@@ -0,0 +1,169 @@
+package raftstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"key-value/services/key-value/internal/kvstore"
+	"sync"
+
+	"github.com/hashicorp/raft"
+)
+
+// Operations carried by log entries
+const (
+	opSet           = "set"
+	opDelete        = "delete"
+	opIncrement     = "increment"
+	opSetIfAbsent   = "set_if_absent"
+	opDeleteIfValue = "delete_if_value"
+	opBatch         = "batch"
+	opRestore       = "restore"
+	opAddPeer       = "add_peer"
+	opRemovePeer    = "remove_peer"
+)
+
+// command is the payload of a Raft log entry
+type command struct {
+	Op    string `json:"op"`
+	Key   string `json:"key,omitempty"`
+	Value string `json:"value,omitempty"`
+	Delta int64  `json:"delta,omitempty"`
+
+	// A batch applies its writes only if all of its checks hold
+	Checks []kvstore.Check `json:"checks,omitempty"`
+	Writes []kvstore.Write `json:"writes,omitempty"`
+
+	// Data replaces the whole store on restore
+	Data map[string]string `json:"data,omitempty"`
+
+	// Peer commands replicate the gRPC address of each member so followers know where to forward
+	NodeID   string `json:"node_id,omitempty"`
+	GRPCAddr string `json:"grpc_addr,omitempty"`
+}
+
+// applyResult is returned from fsm.Apply through the ApplyFuture response
+type applyResult struct {
+	value int64
+	err   error
+}
+
+// snapshotData is the serialized form of an FSM snapshot
+type snapshotData struct {
+	Data  map[string]string `json:"data"`
+	Peers map[string]string `json:"peers"`
+}
+
+// fsm applies committed commands to the underlying store
+type fsm struct {
+	store kvstore.Storer
+
+	mutex sync.RWMutex
+	peers map[string]string // node ID -> gRPC address
+}
+
+func newFSM(store kvstore.Storer) *fsm {
+	return &fsm{
+		store: store,
+		peers: make(map[string]string),
+	}
+}
+
+// Apply is called once a log entry is committed
+func (f *fsm) Apply(entry *raft.Log) interface{} {
+	var cmd command
+	if err := json.Unmarshal(entry.Data, &cmd); err != nil {
+		return applyResult{err: fmt.Errorf("failed to decode log entry %d: %w", entry.Index, err)}
+	}
+
+	switch cmd.Op {
+	case opSet:
+		return applyResult{err: f.store.Set(cmd.Key, cmd.Value)}
+	case opDelete:
+		return applyResult{err: f.store.Delete(cmd.Key)}
+	case opIncrement:
+		value, err := f.store.Increment(cmd.Key, cmd.Delta)
+		return applyResult{value: value, err: err}
+	case opSetIfAbsent, opDeleteIfValue:
+		if cmd.Op == opSetIfAbsent {
+			return applyResult{err: kvstore.SetIfAbsent(f.store, cmd.Key, cmd.Value)}
+		}
+		return applyResult{err: kvstore.DeleteIfValue(f.store, cmd.Key, cmd.Value)}
+	case opBatch:
+		return applyResult{err: kvstore.ApplyBatch(f.store, cmd.Checks, cmd.Writes)}
+	case opRestore:
+		return applyResult{err: kvstore.Restore(f.store, cmd.Data)}
+	case opAddPeer:
+		f.mutex.Lock()
+		f.peers[cmd.NodeID] = cmd.GRPCAddr
+		f.mutex.Unlock()
+		return applyResult{}
+	case opRemovePeer:
+		f.mutex.Lock()
+		delete(f.peers, cmd.NodeID)
+		f.mutex.Unlock()
+		return applyResult{}
+	default:
+		return applyResult{err: fmt.Errorf("unknown operation %q in log entry %d", cmd.Op, entry.Index)}
+	}
+}
+
+// peerAddr returns the gRPC address registered for a node
+func (f *fsm) peerAddr(nodeID string) string {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+	return f.peers[nodeID]
+}
+
+// Snapshot captures the store and peer table. Apply is never called concurrently with Snapshot.
+func (f *fsm) Snapshot() (raft.FSMSnapshot, error) {
+	data, err := kvstore.Snapshot(f.store)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mutex.RLock()
+	peers := make(map[string]string, len(f.peers))
+	for id, addr := range f.peers {
+		peers[id] = addr
+	}
+	f.mutex.RUnlock()
+
+	return &fsmSnapshot{data: snapshotData{Data: data, Peers: peers}}, nil
+}
+
+// Restore replaces the state with a snapshot received from the leader or read at startup
+func (f *fsm) Restore(reader io.ReadCloser) error {
+	defer reader.Close()
+
+	var snapshot snapshotData
+	if err := json.NewDecoder(reader).Decode(&snapshot); err != nil {
+		return fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	if err := kvstore.Restore(f.store, snapshot.Data); err != nil {
+		return err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.peers = snapshot.Peers
+	if f.peers == nil {
+		f.peers = make(map[string]string)
+	}
+	return nil
+}
+
+// fsmSnapshot is a point in time copy written out by Raft
+type fsmSnapshot struct {
+	data snapshotData
+}
+
+func (s *fsmSnapshot) Persist(sink raft.SnapshotSink) error {
+	if err := json.NewEncoder(sink).Encode(s.data); err != nil {
+		sink.Cancel()
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return sink.Close()
+}
+
+func (s *fsmSnapshot) Release() {}
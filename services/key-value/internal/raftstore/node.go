@@ -0,0 +1,427 @@
+package raftstore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"key-value/services/key-value/internal/encryption"
+	"key-value/services/key-value/internal/kvstore"
+	"net"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/raft"
+)
+
+// Read consistency modes for Get
+const (
+	// Linearizable reads confirm leadership with a read index before reading, so they see every
+	// acknowledged write. They are only served by the leader.
+	Linearizable = "linearizable"
+	// Stale reads are served from the local copy of any node and may lag behind the leader
+	Stale = "stale"
+)
+
+// Config describes a single Raft node
+type Config struct {
+	NodeID    string // Unique and stable ID of the node
+	RaftAddr  string // Address the Raft transport binds to (host:port, port 0 picks one)
+	GRPCAddr  string // Address other nodes use to reach this node's gRPC server
+	Bootstrap bool   // Start a new cluster with this node as its only member
+
+	// RaftAdvertiseAddr is the Raft address given to other nodes, required when RaftAddr binds
+	// to all interfaces. Defaults to RaftAddr.
+	RaftAdvertiseAddr string
+
+	ReadConsistency  string        // Default for Get, Linearizable unless set to Stale
+	HeartbeatTimeout time.Duration // Defaults to raft.DefaultConfig()
+	ElectionTimeout  time.Duration // Defaults to raft.DefaultConfig()
+	ApplyTimeout     time.Duration // How long a write waits to be committed, defaults to 5s
+	LogOutput        io.Writer     // Raft library logs, defaults to os.Stderr
+
+	// DataDir keeps the Raft log and snapshots on disk, sealed with Cipher, so a restarted node recovers its
+	// state. They are kept in memory when empty.
+	DataDir string
+	Cipher  *encryption.Cipher
+}
+
+// Member describes a node of the cluster
+type Member struct {
+	ID       string
+	RaftAddr string
+	GRPCAddr string
+	Voter    bool
+	Leader   bool
+}
+
+// Node is a member of a Raft group. It implements kvstore.Storer: writes are committed
+// through the Raft log before being applied to the wrapped store on every node.
+// It implements kvstore.Snapshotter too, so restoring the whole data reaches every node.
+// Calls that must be served by the leader return a *kvstore.LeaderError on followers.
+type Node struct {
+	config    Config
+	raft      *raft.Raft
+	fsm       *fsm
+	transport *raft.NetworkTransport
+	store     kvstore.Storer
+	wal       *walStore // Set when the node has a data directory
+
+	// leaderReady is set once this node is leader and has applied every entry of earlier terms,
+	// which is required before its commit index can be used as a read index
+	leaderReady atomic.Bool
+	done        chan struct{}
+}
+
+// NewNode starts a Raft node replicating writes into store, which must support snapshots.
+// Log entries and snapshots are kept in memory, matching the in-memory store, unless a data directory is configured.
+func NewNode(config Config, store kvstore.Storer) (*Node, error) {
+	if config.NodeID == "" {
+		return nil, errors.New("raft node ID is required")
+	}
+	if config.ReadConsistency == "" {
+		config.ReadConsistency = Linearizable
+	}
+	if config.ReadConsistency != Linearizable && config.ReadConsistency != Stale {
+		return nil, fmt.Errorf("unknown read consistency %q", config.ReadConsistency)
+	}
+	if config.ApplyTimeout == 0 {
+		config.ApplyTimeout = 5 * time.Second
+	}
+	if config.LogOutput == nil {
+		config.LogOutput = os.Stderr
+	}
+	if config.DataDir != "" && config.Cipher == nil {
+		return nil, errors.New("a cipher is required to keep raft data on disk")
+	}
+
+	var advertise net.Addr
+	if config.RaftAdvertiseAddr != "" {
+		addr, err := net.ResolveTCPAddr("tcp", config.RaftAdvertiseAddr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid raft advertise address %s: %w", config.RaftAdvertiseAddr, err)
+		}
+		advertise = addr
+	}
+
+	var logStore raft.LogStore
+	var stableStore raft.StableStore
+	var snapshots raft.SnapshotStore
+	var wal *walStore
+	if config.DataDir == "" {
+		inmem := raft.NewInmemStore()
+		logStore, stableStore, snapshots = inmem, inmem, raft.NewInmemSnapshotStore()
+	} else {
+		if err := os.MkdirAll(config.DataDir, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create raft data directory: %w", err)
+		}
+		files, err := raft.NewFileSnapshotStoreWithLogger(config.DataDir, 2, hclog.New(&hclog.LoggerOptions{
+			Name: "snapshot", Output: config.LogOutput, Level: hclog.Warn,
+		}))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open raft snapshots: %w", err)
+		}
+		if wal, err = openWALStore(config.DataDir, config.Cipher); err != nil {
+			return nil, err
+		}
+		logStore, stableStore, snapshots = wal, wal, &sealedSnapshots{store: files, cipher: config.Cipher}
+	}
+
+	transport, err := raft.NewTCPTransport(config.RaftAddr, advertise, 3, 10*time.Second, config.LogOutput)
+	if err != nil {
+		wal.Close()
+		return nil, fmt.Errorf("failed to start raft transport on %s: %w", config.RaftAddr, err)
+	}
+
+	notifyCh := make(chan bool, 8)
+	raftConfig := raft.DefaultConfig()
+	raftConfig.LocalID = raft.ServerID(config.NodeID)
+	raftConfig.NotifyCh = notifyCh
+	raftConfig.LogOutput = config.LogOutput
+	raftConfig.LogLevel = "WARN"
+	if config.HeartbeatTimeout > 0 {
+		raftConfig.HeartbeatTimeout = config.HeartbeatTimeout
+		raftConfig.LeaderLeaseTimeout = config.HeartbeatTimeout
+	}
+	if config.ElectionTimeout > 0 {
+		raftConfig.ElectionTimeout = config.ElectionTimeout
+	}
+
+	fsm := newFSM(store)
+	r, err := raft.NewRaft(raftConfig, fsm, logStore, stableStore, snapshots, transport)
+	if err != nil {
+		transport.Close()
+		wal.Close()
+		return nil, fmt.Errorf("failed to start raft: %w", err)
+	}
+
+	if config.Bootstrap {
+		bootstrap := raft.Configuration{Servers: []raft.Server{{
+			Suffrage: raft.Voter,
+			ID:       raftConfig.LocalID,
+			Address:  transport.LocalAddr(),
+		}}}
+		if err := r.BootstrapCluster(bootstrap).Error(); err != nil && !errors.Is(err, raft.ErrCantBootstrap) {
+			r.Shutdown()
+			transport.Close()
+			wal.Close()
+			return nil, fmt.Errorf("failed to bootstrap cluster: %w", err)
+		}
+	}
+
+	n := &Node{
+		config:    config,
+		raft:      r,
+		fsm:       fsm,
+		transport: transport,
+		store:     store,
+		wal:       wal,
+		done:      make(chan struct{}),
+	}
+	go n.watchLeadership(notifyCh)
+
+	return n, nil
+}
+
+// watchLeadership prepares the node to serve reads whenever it gains leadership
+func (n *Node) watchLeadership(notifyCh <-chan bool) {
+	for {
+		select {
+		case <-n.done:
+			return
+		case isLeader := <-notifyCh:
+			n.leaderReady.Store(false)
+			if !isLeader {
+				continue
+			}
+			// A barrier commits an entry of the new term, so everything before it is applied
+			if err := n.raft.Barrier(n.config.ApplyTimeout).Error(); err != nil {
+				continue
+			}
+			// Make sure followers know where to forward requests to us
+			if n.fsm.peerAddr(n.config.NodeID) != n.config.GRPCAddr {
+				if _, err := n.apply(command{Op: opAddPeer, NodeID: n.config.NodeID, GRPCAddr: n.config.GRPCAddr}); err != nil {
+					continue
+				}
+			}
+			n.leaderReady.Store(n.raft.State() == raft.Leader)
+		}
+	}
+}
+
+// RaftAddr returns the address the Raft transport is listening on
+func (n *Node) RaftAddr() string {
+	return string(n.transport.LocalAddr())
+}
+
+// IsLeader reports whether this node is the leader and ready to serve requests
+func (n *Node) IsLeader() bool {
+	return n.raft.State() == raft.Leader && n.leaderReady.Load()
+}
+
+// Leader returns the ID and gRPC address of the current leader, empty when unknown
+func (n *Node) Leader() (string, string) {
+	_, id := n.raft.LeaderWithID()
+	return string(id), n.fsm.peerAddr(string(id))
+}
+
+// notLeader builds the error returned when a request reaches a node that cannot serve it
+func (n *Node) notLeader() error {
+	_, addr := n.Leader()
+	return &kvstore.LeaderError{LeaderAddr: addr}
+}
+
+// apply commits a command through the log and returns the result of applying it
+func (n *Node) apply(cmd command) (applyResult, error) {
+	if n.raft.State() != raft.Leader {
+		return applyResult{}, n.notLeader()
+	}
+
+	data, err := json.Marshal(cmd)
+	if err != nil {
+		return applyResult{}, fmt.Errorf("failed to encode command: %w", err)
+	}
+
+	future := n.raft.Apply(data, n.config.ApplyTimeout)
+	if err := future.Error(); err != nil {
+		if errors.Is(err, raft.ErrNotLeader) || errors.Is(err, raft.ErrLeadershipLost) {
+			return applyResult{}, n.notLeader()
+		}
+		return applyResult{}, fmt.Errorf("failed to commit %s: %w", cmd.Op, err)
+	}
+
+	result, ok := future.Response().(applyResult)
+	if !ok {
+		return applyResult{}, fmt.Errorf("unexpected apply response %T", future.Response())
+	}
+	return result, result.err
+}
+
+// Get reads a key using the configured read consistency
+func (n *Node) Get(key string) (string, error) {
+	if n.config.ReadConsistency == Stale {
+		return n.StaleGet(key)
+	}
+	return n.LinearizableGet(key)
+}
+
+// StaleGet reads the local copy of a key without contacting other nodes
+func (n *Node) StaleGet(key string) (string, error) {
+	return n.store.Get(key)
+}
+
+// LinearizableGet reads a key on the leader after waiting for the read index
+func (n *Node) LinearizableGet(key string) (string, error) {
+	if err := n.waitReadIndex(); err != nil {
+		return "", err
+	}
+	return n.store.Get(key)
+}
+
+// waitReadIndex makes local reads linearizable: it records the commit index, confirms this node
+// is still leader with a heartbeat round and waits for that index to be applied
+func (n *Node) waitReadIndex() error {
+	if !n.IsLeader() {
+		return n.notLeader()
+	}
+
+	readIndex := n.raft.CommitIndex()
+	if err := n.raft.VerifyLeader().Error(); err != nil {
+		return n.notLeader()
+	}
+
+	deadline := time.Now().Add(n.config.ApplyTimeout)
+	for n.raft.AppliedIndex() < readIndex {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for index %d to be applied", readIndex)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return nil
+}
+
+// Scan lists keys in order using the configured read consistency
+func (n *Node) Scan(prefix string, startAfter string, limit int) ([]kvstore.KeyValue, bool, error) {
+	if n.config.ReadConsistency != Stale {
+		if err := n.waitReadIndex(); err != nil {
+			return nil, false, err
+		}
+	}
+	return kvstore.Scan(n.store, prefix, startAfter, limit)
+}
+
+// Set commits a key-value pair through the log
+func (n *Node) Set(key string, value string) error {
+	_, err := n.apply(command{Op: opSet, Key: key, Value: value})
+	return err
+}
+
+// Delete commits a delete through the log
+func (n *Node) Delete(key string) error {
+	_, err := n.apply(command{Op: opDelete, Key: key})
+	return err
+}
+
+// Increment commits an increment through the log and returns the new value
+func (n *Node) Increment(key string, delta int64) (int64, error) {
+	result, err := n.apply(command{Op: opIncrement, Key: key, Delta: delta})
+	return result.value, err
+}
+
+// SetIfAbsent commits a set that only applies when the key does not exist
+func (n *Node) SetIfAbsent(key string, value string) error {
+	_, err := n.apply(command{Op: opSetIfAbsent, Key: key, Value: value})
+	return err
+}
+
+// DeleteIfValue commits a delete that only applies when the key holds value
+func (n *Node) DeleteIfValue(key string, value string) error {
+	_, err := n.apply(command{Op: opDeleteIfValue, Key: key, Value: value})
+	return err
+}
+
+// Batch commits writes as one log entry that only applies when every check holds
+func (n *Node) Batch(checks []kvstore.Check, writes []kvstore.Write) error {
+	_, err := n.apply(command{Op: opBatch, Checks: checks, Writes: writes})
+	return err
+}
+
+// Snapshot copies the data using the configured read consistency
+func (n *Node) Snapshot() (map[string]string, error) {
+	if n.config.ReadConsistency != Stale {
+		if err := n.waitReadIndex(); err != nil {
+			return nil, err
+		}
+	}
+	return kvstore.Snapshot(n.store)
+}
+
+// Restore commits the replacement of the whole data through the log, as a single entry holding every pair
+func (n *Node) Restore(data map[string]string) error {
+	_, err := n.apply(command{Op: opRestore, Data: data})
+	return err
+}
+
+// AddMember adds a voting member to the cluster. It must be called on the leader.
+func (n *Node) AddMember(id string, raftAddr string, grpcAddr string) error {
+	if n.raft.State() != raft.Leader {
+		return n.notLeader()
+	}
+	if err := n.raft.AddVoter(raft.ServerID(id), raft.ServerAddress(raftAddr), 0, n.config.ApplyTimeout).Error(); err != nil {
+		return fmt.Errorf("failed to add member %s: %w", id, err)
+	}
+	_, err := n.apply(command{Op: opAddPeer, NodeID: id, GRPCAddr: grpcAddr})
+	return err
+}
+
+// RemoveMember removes a member from the cluster. It must be called on the leader.
+func (n *Node) RemoveMember(id string) error {
+	if n.raft.State() != raft.Leader {
+		return n.notLeader()
+	}
+	if err := n.raft.RemoveServer(raft.ServerID(id), 0, n.config.ApplyTimeout).Error(); err != nil {
+		return fmt.Errorf("failed to remove member %s: %w", id, err)
+	}
+	// The leader may have removed itself, in which case the peer entry is cleaned up by the next leader's view
+	if n.raft.State() != raft.Leader {
+		return nil
+	}
+	_, err := n.apply(command{Op: opRemovePeer, NodeID: id})
+	return err
+}
+
+// Members lists the current cluster configuration
+func (n *Node) Members() ([]Member, error) {
+	future := n.raft.GetConfiguration()
+	if err := future.Error(); err != nil {
+		return nil, fmt.Errorf("failed to read cluster configuration: %w", err)
+	}
+
+	leaderID, _ := n.Leader()
+	var members []Member
+	for _, server := range future.Configuration().Servers {
+		members = append(members, Member{
+			ID:       string(server.ID),
+			RaftAddr: string(server.Address),
+			GRPCAddr: n.fsm.peerAddr(string(server.ID)),
+			Voter:    server.Suffrage == raft.Voter,
+			Leader:   string(server.ID) == leaderID,
+		})
+	}
+	return members, nil
+}
+
+// Shutdown stops the node. The wrapped store keeps its data.
+func (n *Node) Shutdown() error {
+	close(n.done)
+	err := n.raft.Shutdown().Error()
+	if closeErr := n.transport.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := n.wal.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
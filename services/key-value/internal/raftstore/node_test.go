@@ -0,0 +1,333 @@
+package raftstore
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"key-value/services/key-value/internal/kvstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testNode is a Raft node over loopback together with its local store
+type testNode struct {
+	*Node
+	store   *kvstore.InMemoryStore
+	stopped bool
+}
+
+// stop shuts the node down once, so tests can kill nodes before the cleanup runs
+func (n *testNode) stop() error {
+	if n.stopped {
+		return nil
+	}
+	n.stopped = true
+	return n.Shutdown()
+}
+
+func startNode(t *testing.T, id string, bootstrap bool) *testNode {
+	t.Helper()
+	store := kvstore.NewInMemoryStore()
+	node, err := NewNode(Config{
+		NodeID:           id,
+		RaftAddr:         "127.0.0.1:0",
+		GRPCAddr:         "grpc-" + id,
+		Bootstrap:        bootstrap,
+		HeartbeatTimeout: 100 * time.Millisecond,
+		ElectionTimeout:  100 * time.Millisecond,
+		ApplyTimeout:     2 * time.Second,
+		LogOutput:        io.Discard,
+	}, store)
+	require.NoError(t, err)
+	return &testNode{Node: node, store: store}
+}
+
+// startCluster bootstraps the first node and adds the others through the leader
+func startCluster(t *testing.T, size int) []*testNode {
+	t.Helper()
+	nodes := []*testNode{startNode(t, "node-0", true)}
+	waitForLeader(t, nodes)
+
+	for i := 1; i < size; i++ {
+		node := startNode(t, fmt.Sprintf("node-%d", i), false)
+		nodes = append(nodes, node)
+		require.NoError(t, nodes[0].AddMember(node.config.NodeID, node.RaftAddr(), node.config.GRPCAddr))
+	}
+
+	t.Cleanup(func() {
+		for _, node := range nodes {
+			node.stop()
+		}
+	})
+	return nodes
+}
+
+// waitForLeader returns the node that is leader and ready to serve, among the running ones
+func waitForLeader(t *testing.T, nodes []*testNode) *testNode {
+	t.Helper()
+	var leader *testNode
+	require.Eventually(t, func() bool {
+		for _, node := range nodes {
+			if !node.stopped && node.IsLeader() {
+				leader = node
+				return true
+			}
+		}
+		return false
+	}, 10*time.Second, 10*time.Millisecond)
+	return leader
+}
+
+// waitForValue waits until a node's local copy of key holds value
+func waitForValue(t *testing.T, node *testNode, key string, value string) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		got, err := node.StaleGet(key)
+		return err == nil && got == value
+	}, 5*time.Second, 10*time.Millisecond, "node %s never saw %s=%s", node.config.NodeID, key, value)
+}
+
+func TestNode_ReplicatesWrites(t *testing.T) {
+	nodes := startCluster(t, 3)
+	leader := waitForLeader(t, nodes)
+
+	require.NoError(t, leader.Set("greeting", "hello"))
+	value, err := leader.Increment("counter", 5)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), value)
+
+	for _, node := range nodes {
+		waitForValue(t, node, "greeting", "hello")
+		waitForValue(t, node, "counter", "5")
+	}
+
+	require.NoError(t, leader.Delete("greeting"))
+	for _, node := range nodes {
+		require.Eventually(t, func() bool {
+			_, err := node.StaleGet("greeting")
+			return errors.Is(err, kvstore.ErrNotFound)
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+}
+
+func TestNode_Batch(t *testing.T) {
+	nodes := startCluster(t, 3)
+	leader := waitForLeader(t, nodes)
+
+	require.NoError(t, leader.Set("a", "1"))
+	err := leader.Batch([]kvstore.Check{{Key: "a", Value: "2"}}, []kvstore.Write{{Key: "b", Value: "2"}})
+	assert.ErrorIs(t, err, kvstore.ErrConflict)
+
+	checks := []kvstore.Check{{Key: "a", Value: "1"}, {Key: "b", Absent: true}}
+	require.NoError(t, leader.Batch(checks, []kvstore.Write{{Key: "a", Delete: true}, {Key: "b", Value: "2"}}))
+	for _, node := range nodes {
+		waitForValue(t, node, "b", "2")
+		_, err := node.StaleGet("a")
+		assert.ErrorIs(t, err, kvstore.ErrNotFound)
+	}
+}
+
+func TestNode_LinearizableReadsOnLeader(t *testing.T) {
+	nodes := startCluster(t, 3)
+	leader := waitForLeader(t, nodes)
+
+	require.NoError(t, leader.Set("key", "value"))
+
+	// The write is acknowledged, so a linearizable read must see it immediately
+	value, err := leader.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, "value", value)
+
+	_, err = leader.Get("missing")
+	assert.ErrorIs(t, err, kvstore.ErrNotFound)
+}
+
+func TestNode_FollowersRedirectToLeader(t *testing.T) {
+	nodes := startCluster(t, 3)
+	leader := waitForLeader(t, nodes)
+
+	for _, node := range nodes {
+		if node == leader {
+			continue
+		}
+
+		// The follower learns the leader's address once the peer entry is applied locally
+		require.Eventually(t, func() bool {
+			var leaderErr *kvstore.LeaderError
+			err := node.Set("key", "value")
+			return errors.As(err, &leaderErr) && leaderErr.LeaderAddr == leader.config.GRPCAddr
+		}, 5*time.Second, 10*time.Millisecond)
+
+		_, err := node.LinearizableGet("key")
+		assert.ErrorIs(t, err, kvstore.ErrNotLeader)
+
+		_, err = node.Increment("counter", 1)
+		assert.ErrorIs(t, err, kvstore.ErrNotLeader)
+	}
+}
+
+func TestNode_SnapshotAndRestore(t *testing.T) {
+	nodes := startCluster(t, 3)
+	leader := waitForLeader(t, nodes)
+
+	require.NoError(t, leader.Set("old", "1"))
+	data, err := leader.Snapshot()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"old": "1"}, data)
+
+	require.NoError(t, leader.Restore(map[string]string{"a": "1", "b": "2"}))
+	for _, node := range nodes {
+		waitForValue(t, node, "b", "2")
+		_, err := node.StaleGet("old")
+		assert.ErrorIs(t, err, kvstore.ErrNotFound, "restoring replaces the data on %s", node.config.NodeID)
+	}
+
+	for _, node := range nodes {
+		if node != leader {
+			assert.ErrorIs(t, node.Restore(map[string]string{}), kvstore.ErrNotLeader)
+			_, err := node.Snapshot()
+			assert.ErrorIs(t, err, kvstore.ErrNotLeader)
+		}
+	}
+}
+
+func TestNode_DataDirSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	start := func(addr string) (*Node, *kvstore.InMemoryStore) {
+		store := kvstore.NewInMemoryStore()
+		node, err := NewNode(Config{
+			NodeID:           "node-0",
+			RaftAddr:         addr,
+			GRPCAddr:         "grpc-node-0",
+			Bootstrap:        true,
+			HeartbeatTimeout: 100 * time.Millisecond,
+			ElectionTimeout:  100 * time.Millisecond,
+			LogOutput:        io.Discard,
+			DataDir:          dir,
+			Cipher:           testCipher(t),
+		}, store)
+		require.NoError(t, err)
+		require.Eventually(t, node.IsLeader, 10*time.Second, 10*time.Millisecond)
+		return node, store
+	}
+
+	node, _ := start("127.0.0.1:0")
+	require.NoError(t, node.Set("snapshotted", "1"))
+	require.NoError(t, node.raft.Snapshot().Error())
+	require.NoError(t, node.Set("logged", "2"))
+	addr := node.RaftAddr()
+	require.NoError(t, node.Shutdown())
+
+	// The restarted node reads the snapshot and replays the log after it
+	node, store := start(addr)
+	defer node.Shutdown()
+	data, err := store.Snapshot()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"snapshotted": "1", "logged": "2"}, data)
+	leader, _ := node.Leader()
+	assert.Equal(t, "node-0", leader)
+}
+
+func TestNode_StaleReadsOnFollowers(t *testing.T) {
+	nodes := startCluster(t, 3)
+	leader := waitForLeader(t, nodes)
+
+	require.NoError(t, leader.Set("key", "value"))
+	for _, node := range nodes {
+		waitForValue(t, node, "key", "value")
+	}
+}
+
+func TestNode_LeaderFailover(t *testing.T) {
+	nodes := startCluster(t, 3)
+	leader := waitForLeader(t, nodes)
+
+	require.NoError(t, leader.Set("before", "failover"))
+	for _, node := range nodes {
+		waitForValue(t, node, "before", "failover")
+	}
+
+	require.NoError(t, leader.stop())
+	var survivors []*testNode
+	for _, node := range nodes {
+		if node != leader {
+			survivors = append(survivors, node)
+		}
+	}
+
+	newLeader := waitForLeader(t, survivors)
+	value, err := newLeader.Get("before")
+	require.NoError(t, err)
+	assert.Equal(t, "failover", value)
+
+	require.NoError(t, newLeader.Set("after", "failover"))
+	for _, node := range survivors {
+		waitForValue(t, node, "after", "failover")
+	}
+}
+
+func TestNode_MembershipChanges(t *testing.T) {
+	nodes := startCluster(t, 3)
+	leader := waitForLeader(t, nodes)
+
+	members, err := leader.Members()
+	require.NoError(t, err)
+	require.Len(t, members, 3)
+	for _, member := range members {
+		assert.True(t, member.Voter)
+		assert.Equal(t, "grpc-"+member.ID, member.GRPCAddr)
+		assert.Equal(t, member.ID == leader.config.NodeID, member.Leader)
+	}
+
+	// Remove a follower and check writes still commit with the remaining two
+	var removed *testNode
+	for _, node := range nodes {
+		if node != leader {
+			removed = node
+			break
+		}
+	}
+	require.NoError(t, leader.RemoveMember(removed.config.NodeID))
+
+	members, err = leader.Members()
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+
+	require.NoError(t, leader.Set("key", "value"))
+	time.Sleep(200 * time.Millisecond)
+	_, err = removed.StaleGet("key")
+	assert.ErrorIs(t, err, kvstore.ErrNotFound, "removed member should not receive new writes")
+
+	// A fresh node joins and catches up on existing data
+	joined := startNode(t, "node-3", false)
+	t.Cleanup(func() { joined.stop() })
+	require.NoError(t, leader.AddMember(joined.config.NodeID, joined.RaftAddr(), joined.config.GRPCAddr))
+	waitForValue(t, joined, "key", "value")
+}
+
+func TestNode_FiveNodeCluster(t *testing.T) {
+	nodes := startCluster(t, 5)
+	leader := waitForLeader(t, nodes)
+
+	for i := 0; i < 20; i++ {
+		require.NoError(t, leader.Set(fmt.Sprintf("key-%d", i), fmt.Sprint(i)))
+	}
+	for _, node := range nodes {
+		waitForValue(t, node, "key-19", "19")
+	}
+}
+
+func TestNewNode_Validation(t *testing.T) {
+	_, err := NewNode(Config{RaftAddr: "127.0.0.1:0"}, kvstore.NewInMemoryStore())
+	assert.Error(t, err)
+
+	_, err = NewNode(Config{NodeID: "n", RaftAddr: "127.0.0.1:0", ReadConsistency: "eventual"}, kvstore.NewInMemoryStore())
+	assert.Error(t, err)
+
+	_, err = NewNode(Config{NodeID: "n", RaftAddr: "127.0.0.1:0", DataDir: t.TempDir()}, kvstore.NewInMemoryStore())
+	assert.Error(t, err)
+}
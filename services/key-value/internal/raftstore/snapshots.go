@@ -0,0 +1,162 @@
+package raftstore
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"key-value/services/key-value/internal/encryption"
+	"key-value/services/key-value/internal/sealedlog"
+
+	"github.com/hashicorp/raft"
+)
+
+// snapshotSegmentBytes is the plaintext size of every segment of a sealed snapshot but the last, which is shorter
+const snapshotSegmentBytes = 64 << 10
+
+// sealedSnapshots stores snapshots in another snapshot store, sealing them in segments. Each segment is bound to the
+// snapshot ID, its position and whether it is the last, so segments cannot be moved, dropped or cut off unnoticed.
+// The metadata of the snapshots (index, term and cluster configuration) is stored as is.
+type sealedSnapshots struct {
+	store  raft.SnapshotStore
+	cipher *encryption.Cipher
+}
+
+// Create starts a snapshot whose data is sealed as it is written
+func (s *sealedSnapshots) Create(version raft.SnapshotVersion, index, term uint64, configuration raft.Configuration, configurationIndex uint64, trans raft.Transport) (raft.SnapshotSink, error) {
+	sink, err := s.store.Create(version, index, term, configuration, configurationIndex, trans)
+	if err != nil {
+		return nil, err
+	}
+	return &sealingSink{SnapshotSink: sink, cipher: s.cipher}, nil
+}
+
+// List lists the snapshots with the size of their data before sealing
+func (s *sealedSnapshots) List() ([]*raft.SnapshotMeta, error) {
+	metas, err := s.store.List()
+	for _, meta := range metas {
+		meta.Size = s.plaintextSize(meta.Size)
+	}
+	return metas, err
+}
+
+// Open opens a snapshot, returning its data opened as it is read
+func (s *sealedSnapshots) Open(id string) (*raft.SnapshotMeta, io.ReadCloser, error) {
+	meta, reader, err := s.store.Open(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	meta.Size = s.plaintextSize(meta.Size)
+	return meta, &openingReader{in: bufio.NewReader(reader), closer: reader, cipher: s.cipher, id: id}, nil
+}
+
+// plaintextSize derives the size of snapshot data from its sealed size, which Raft sends to followers before the data
+func (s *sealedSnapshots) plaintextSize(sealed int64) int64 {
+	segmentOverhead := int64(4 + s.cipher.Overhead())
+	segments := (sealed + snapshotSegmentBytes + segmentOverhead - 1) / (snapshotSegmentBytes + segmentOverhead)
+	return sealed - segments*segmentOverhead
+}
+
+// segmentAD binds a segment to its snapshot, position and whether it is the last
+func segmentAD(id string, segment uint64, last bool) []byte {
+	ad := binary.BigEndian.AppendUint64([]byte("raft snapshot "+id+" "), segment)
+	if last {
+		ad = append(ad, 1)
+	}
+	return ad
+}
+
+// sealingSink seals what is written to a snapshot in segments
+type sealingSink struct {
+	raft.SnapshotSink
+	cipher   *encryption.Cipher
+	buf      []byte
+	segments uint64
+}
+
+func (s *sealingSink) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		taken := min(snapshotSegmentBytes-len(s.buf), len(p))
+		s.buf = append(s.buf, p[:taken]...)
+		p = p[taken:]
+		if len(s.buf) == snapshotSegmentBytes {
+			if err := s.writeSegment(false); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Close seals the last segment, which is shorter than the others and may be empty, and closes the snapshot
+func (s *sealingSink) Close() error {
+	if err := s.writeSegment(true); err != nil {
+		s.SnapshotSink.Cancel()
+		return err
+	}
+	return s.SnapshotSink.Close()
+}
+
+func (s *sealingSink) writeSegment(last bool) error {
+	sealed := s.cipher.Seal(s.buf, segmentAD(s.ID(), s.segments, last))
+	if _, err := s.SnapshotSink.Write(sealedlog.AppendFrame(make([]byte, 0, 4+len(sealed)), sealed)); err != nil {
+		return err
+	}
+	s.buf = s.buf[:0]
+	s.segments++
+	return nil
+}
+
+// openingReader opens the segments of a sealed snapshot as they are read
+type openingReader struct {
+	in       *bufio.Reader
+	closer   io.Closer
+	cipher   *encryption.Cipher
+	id       string
+	segment  uint64
+	data     []byte
+	finished bool
+}
+
+func (r *openingReader) Read(p []byte) (int, error) {
+	for len(r.data) == 0 {
+		if r.finished {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// next opens the next segment, which is the last one when it is shorter than the others
+func (r *openingReader) next() error {
+	sealed, err := sealedlog.ReadFrame(r.in, snapshotSegmentBytes+r.cipher.Overhead())
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("snapshot %s is truncated: %w", r.id, encryption.ErrTampered)
+	}
+	if err != nil {
+		return err
+	}
+	last := len(sealed) != snapshotSegmentBytes+r.cipher.Overhead()
+	data, err := r.cipher.Open(sealed, segmentAD(r.id, r.segment, last))
+	if err != nil {
+		return fmt.Errorf("snapshot %s segment %d: %w", r.id, r.segment, err)
+	}
+	r.data, r.segment, r.finished = data, r.segment+1, last
+	if last {
+		if _, err := r.in.ReadByte(); !errors.Is(err, io.EOF) {
+			return fmt.Errorf("snapshot %s has data after the last segment: %w", r.id, encryption.ErrTampered)
+		}
+	}
+	return nil
+}
+
+func (r *openingReader) Close() error {
+	return r.closer.Close()
+}
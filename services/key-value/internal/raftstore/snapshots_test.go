@@ -0,0 +1,128 @@
+package raftstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"hash/crc64"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"key-value/services/key-value/internal/encryption"
+
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createSnapshot writes data to a new sealed snapshot, returning its ID
+func createSnapshot(t *testing.T, snapshots *sealedSnapshots, data []byte) string {
+	t.Helper()
+	sink, err := snapshots.Create(1, 10, 2, raft.Configuration{}, 1, nil)
+	require.NoError(t, err)
+	// Write in pieces that do not line up with the segments
+	for len(data) > 0 {
+		n, err := sink.Write(data[:min(1000, len(data))])
+		require.NoError(t, err)
+		data = data[n:]
+	}
+	require.NoError(t, sink.Close())
+	return sink.ID()
+}
+
+func newSealedSnapshots(t *testing.T, dir string) *sealedSnapshots {
+	t.Helper()
+	files, err := raft.NewFileSnapshotStore(dir, 2, io.Discard)
+	require.NoError(t, err)
+	return &sealedSnapshots{store: files, cipher: testCipher(t)}
+}
+
+func TestSealedSnapshots_RoundTrip(t *testing.T) {
+	for name, size := range map[string]int{
+		"empty":            0,
+		"one segment":      100,
+		"whole segments":   2 * snapshotSegmentBytes,
+		"partial segments": 2*snapshotSegmentBytes + 7,
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			snapshots := newSealedSnapshots(t, dir)
+			data := []byte(strings.Repeat("secret", size/6+1)[:size])
+			id := createSnapshot(t, snapshots, data)
+
+			metas, err := snapshots.List()
+			require.NoError(t, err)
+			require.Len(t, metas, 1)
+			assert.Equal(t, int64(size), metas[0].Size, "raft sends the size of the data to followers")
+
+			meta, reader, err := snapshots.Open(id)
+			require.NoError(t, err)
+			defer reader.Close()
+			assert.Equal(t, int64(size), meta.Size)
+			got, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			assert.Equal(t, data, got)
+
+			file, err := os.ReadFile(filepath.Join(dir, "snapshots", id, "state.bin"))
+			require.NoError(t, err)
+			if size > 0 {
+				assert.NotContains(t, string(file), "secret")
+			}
+		})
+	}
+}
+
+func TestSealedSnapshots_Tampered(t *testing.T) {
+	segment := 4 + snapshotSegmentBytes + testCipher(t).Overhead()
+	tests := []struct {
+		name   string
+		change func([]byte) []byte
+	}{
+		{"flipped bit", func(b []byte) []byte { b[len(b)-1] ^= 1; return b }},
+		{"dropped last segment", func(b []byte) []byte { return b[:2*segment] }},
+		{"dropped middle segment", func(b []byte) []byte { return append(b[:segment:segment], b[2*segment:]...) }},
+		{"swapped segments", func(b []byte) []byte {
+			return append(append(bytes.Clone(b[segment:2*segment]), b[:segment]...), b[2*segment:]...)
+		}},
+		{"appended data", func(b []byte) []byte { return append(b, 0) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			snapshots := newSealedSnapshots(t, dir)
+			id := createSnapshot(t, snapshots, bytes.Repeat([]byte("x"), 2*snapshotSegmentBytes+10))
+
+			// The file store checks the CRC of the file, which is rewritten so the sealing itself is tested
+			path := filepath.Join(dir, "snapshots", id, "state.bin")
+			file, err := os.ReadFile(path)
+			require.NoError(t, err)
+			changed := tt.change(file)
+			require.NoError(t, os.WriteFile(path, changed, 0o600))
+			rewriteSnapshotMeta(t, filepath.Join(dir, "snapshots", id), changed)
+
+			_, reader, err := snapshots.Open(id)
+			require.NoError(t, err)
+			defer reader.Close()
+			_, err = io.ReadAll(reader)
+			assert.ErrorIs(t, err, encryption.ErrTampered)
+		})
+	}
+}
+
+// rewriteSnapshotMeta updates the size and CRC that the file store keeps of the sealed data
+func rewriteSnapshotMeta(t *testing.T, dir string, data []byte) {
+	t.Helper()
+	path := filepath.Join(dir, "meta.json")
+	file, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var meta map[string]any
+	require.NoError(t, json.Unmarshal(file, &meta))
+	hash := crc64.New(crc64.MakeTable(crc64.ECMA))
+	hash.Write(data)
+	meta["Size"], meta["CRC"] = len(data), hash.Sum(nil)
+	file, err = json.Marshal(meta)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, file, 0o600))
+}
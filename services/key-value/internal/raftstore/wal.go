@@ -0,0 +1,371 @@
+package raftstore
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"key-value/services/key-value/internal/encryption"
+	"key-value/services/key-value/internal/sealedlog"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/hashicorp/raft"
+)
+
+// walFormat is the format of the write-ahead log
+var walFormat = sealedlog.Format{Magic: "KVWALLOG", Name: "write-ahead log", Label: "raft wal"}
+
+// walFileName is the name of the write-ahead log in the data directory
+const walFileName = "raft.wal"
+
+// walCheckpointFileName is the name of the file holding the sealed record count of the write-ahead log
+const walCheckpointFileName = "raft.wal.checkpoint"
+
+// walCheckpointRecords is the number of records appended between two checkpoints
+const walCheckpointRecords = 256
+
+// walCheckpoint is the number of records a write-ahead log file held at least
+type walCheckpoint struct {
+	ID      []byte `json:"id"`
+	Records uint64 `json:"records"`
+}
+
+// walRecord is one change of the write-ahead log, sealed on its own
+type walRecord struct {
+	Logs []*raft.Log `json:"logs,omitempty"`
+
+	// Deleted logs from DeleteMin to DeleteMax
+	DeleteMin uint64 `json:"delete_min,omitempty"`
+	DeleteMax uint64 `json:"delete_max,omitempty"`
+
+	// Stable state set by Raft
+	Key   []byte  `json:"key,omitempty"`
+	Value []byte  `json:"value,omitempty"`
+	Uint  *uint64 `json:"uint,omitempty"`
+}
+
+// walStore is the Raft log and stable store of a node with a data directory. It serves reads from memory and
+// appends every change as a sealed record to a write-ahead log, synced before the change is acknowledged and
+// replayed on start. The log is rewritten without the compacted entries whenever Raft compacts it. The record count
+// is checkpointed every walCheckpointRecords records, so a file ending before the checkpoint fails the replay.
+type walStore struct {
+	path   string
+	cipher *encryption.Cipher
+
+	mutex        sync.RWMutex
+	wal          *sealedlog.Log
+	checkpointed uint64 // Records in the file at the last checkpoint
+	logs         map[uint64]*raft.Log
+	first        uint64
+	last         uint64
+	values       map[string][]byte
+	uints        map[string]uint64
+}
+
+// openWALStore replays the write-ahead log in dir, creating it when missing. A record cut short at the end of the
+// file is an interrupted write and is dropped with a warning; any other record that fails authentication, and a file
+// holding fewer records than its checkpoint, fails the open.
+func openWALStore(dir string, cipher *encryption.Cipher) (*walStore, error) {
+	s := &walStore{
+		path:   filepath.Join(dir, walFileName),
+		cipher: cipher,
+		logs:   make(map[uint64]*raft.Log),
+		values: make(map[string][]byte),
+		uints:  make(map[string]uint64),
+	}
+	wal, err := sealedlog.Open(s.path, walFormat, cipher, func(data []byte) error {
+		var record walRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return err
+		}
+		s.apply(record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.wal = wal
+	if err := s.verifyCheckpoint(); err != nil {
+		wal.Close()
+		return nil, fmt.Errorf("failed to replay write-ahead log %s: %w", s.path, err)
+	}
+	if err := s.checkpoint(); err != nil {
+		wal.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// verifyCheckpoint fails when the file holds fewer records than its last checkpoint, which means whole records were
+// removed from its end. A missing checkpoint, or one of another file, cannot tell and is only reported.
+func (s *walStore) verifyCheckpoint() error {
+	sealed, err := os.ReadFile(s.checkpointPath())
+	if errors.Is(err, fs.ErrNotExist) {
+		if s.wal.Records() > 0 {
+			log.Printf("raftstore: %s is missing, records removed from the end of %s cannot be detected",
+				s.checkpointPath(), s.path)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	data, err := s.cipher.Open(sealed, []byte("raft wal checkpoint"))
+	if err != nil {
+		return fmt.Errorf("checkpoint: %w", err)
+	}
+	var checkpoint walCheckpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return fmt.Errorf("checkpoint: %w", err)
+	}
+	if !bytes.Equal(checkpoint.ID, s.wal.ID()) {
+		// A crash between rewriting the log and checkpointing it leaves the checkpoint of the previous file
+		log.Printf("raftstore: %s is of another file, records removed from the end of %s cannot be detected",
+			s.checkpointPath(), s.path)
+		return nil
+	}
+	if s.wal.Records() < checkpoint.Records {
+		return fmt.Errorf("file ends after %d records but held %d: %w", s.wal.Records(), checkpoint.Records,
+			encryption.ErrTampered)
+	}
+	return nil
+}
+
+// checkpoint replaces the checkpoint file with the current record count, so a crash leaves either file whole. The
+// caller holds the lock.
+func (s *walStore) checkpoint() error {
+	data, err := json.Marshal(walCheckpoint{ID: s.wal.ID(), Records: s.wal.Records()})
+	if err != nil {
+		return err
+	}
+	temp := s.checkpointPath() + ".tmp"
+	file, err := os.OpenFile(temp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to checkpoint write-ahead log: %w", err)
+	}
+	_, err = file.Write(s.cipher.Seal(data, []byte("raft wal checkpoint")))
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temp, s.checkpointPath())
+	}
+	if err != nil {
+		os.Remove(temp)
+		return fmt.Errorf("failed to checkpoint write-ahead log: %w", err)
+	}
+	s.checkpointed = s.wal.Records()
+	return nil
+}
+
+func (s *walStore) checkpointPath() string {
+	return filepath.Join(filepath.Dir(s.path), walCheckpointFileName)
+}
+
+// append writes records and checkpoints the record count every walCheckpointRecords records. The records are
+// durable once written, so a failed checkpoint is reported and tried again with the next record. The caller holds
+// the lock.
+func (s *walStore) append(records ...walRecord) error {
+	if err := s.write(records...); err != nil {
+		return err
+	}
+	if s.wal.Records() >= s.checkpointed+walCheckpointRecords {
+		if err := s.checkpoint(); err != nil {
+			log.Printf("raftstore: %v", err)
+		}
+	}
+	return nil
+}
+
+// write seals records to the end of the file, syncs it, then applies them. The caller holds the lock.
+func (s *walStore) write(records ...walRecord) error {
+	data := make([][]byte, len(records))
+	for i, record := range records {
+		var err error
+		if data[i], err = json.Marshal(record); err != nil {
+			return err
+		}
+	}
+	if err := s.wal.Append(data...); err != nil {
+		return err
+	}
+	for _, record := range records {
+		s.apply(record)
+	}
+	return nil
+}
+
+// apply changes the memory state by a record
+func (s *walStore) apply(record walRecord) {
+	for _, log := range record.Logs {
+		s.logs[log.Index] = log
+		if s.first == 0 {
+			s.first = log.Index
+		}
+		s.last = max(s.last, log.Index)
+	}
+	if record.DeleteMax != 0 {
+		for index := record.DeleteMin; index <= record.DeleteMax; index++ {
+			delete(s.logs, index)
+		}
+		if record.DeleteMin <= s.first {
+			s.first = record.DeleteMax + 1
+		}
+		if record.DeleteMax >= s.last {
+			s.last = record.DeleteMin - 1
+		}
+		if s.first > s.last {
+			s.first, s.last = 0, 0
+		}
+	}
+	if record.Key != nil {
+		if record.Uint != nil {
+			s.uints[string(record.Key)] = *record.Uint
+		} else {
+			s.values[string(record.Key)] = record.Value
+		}
+	}
+}
+
+// FirstIndex returns the first index of the log, 0 when empty
+func (s *walStore) FirstIndex() (uint64, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.first, nil
+}
+
+// LastIndex returns the last index of the log, 0 when empty
+func (s *walStore) LastIndex() (uint64, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.last, nil
+}
+
+// GetLog copies the entry at index into log
+func (s *walStore) GetLog(index uint64, log *raft.Log) error {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	entry, ok := s.logs[index]
+	if !ok {
+		return raft.ErrLogNotFound
+	}
+	*log = *entry
+	return nil
+}
+
+// StoreLog appends an entry
+func (s *walStore) StoreLog(log *raft.Log) error {
+	return s.StoreLogs([]*raft.Log{log})
+}
+
+// StoreLogs appends entries
+func (s *walStore) StoreLogs(logs []*raft.Log) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.append(walRecord{Logs: logs})
+}
+
+// DeleteRange removes the entries from minIndex to maxIndex. Removing the start of the log rewrites the file without them.
+func (s *walStore) DeleteRange(minIndex, maxIndex uint64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	compacted := minIndex <= s.first
+	if err := s.append(walRecord{DeleteMin: minIndex, DeleteMax: maxIndex}); err != nil {
+		return err
+	}
+	if compacted {
+		return s.rewrite()
+	}
+	return nil
+}
+
+// rewrite replaces the file with one holding the current state only. The caller holds the lock.
+func (s *walStore) rewrite() error {
+	err := s.wal.Rewrite(func(add func([]byte) error) error {
+		write := func(record walRecord) error {
+			data, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+			return add(data)
+		}
+		for key, value := range s.values {
+			if err := write(walRecord{Key: []byte(key), Value: value}); err != nil {
+				return err
+			}
+		}
+		for key, value := range s.uints {
+			if err := write(walRecord{Key: []byte(key), Uint: &value}); err != nil {
+				return err
+			}
+		}
+		for start := s.first; start != 0 && start <= s.last; start += 1024 {
+			var logs []*raft.Log
+			for index := start; index < start+1024 && index <= s.last; index++ {
+				logs = append(logs, s.logs[index])
+			}
+			if err := write(walRecord{Logs: logs}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.checkpoint(); err != nil {
+		// The checkpoint of the previous file is only reported on open, the next record tries again
+		s.checkpointed = 0
+		log.Printf("raftstore: %v", err)
+	}
+	return nil
+}
+
+// Set stores a stable value
+func (s *walStore) Set(key []byte, value []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.append(walRecord{Key: key, Value: value})
+}
+
+// Get returns a stable value, failing with "not found" like the Raft stores do
+func (s *walStore) Get(key []byte) ([]byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	value, ok := s.values[string(key)]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return value, nil
+}
+
+// SetUint64 stores a stable number
+func (s *walStore) SetUint64(key []byte, value uint64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.append(walRecord{Key: key, Uint: &value})
+}
+
+// GetUint64 returns a stable number, 0 when unset
+func (s *walStore) GetUint64(key []byte) (uint64, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.uints[string(key)], nil
+}
+
+// Close closes the file, doing nothing on a nil store
+func (s *walStore) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.wal.Close()
+}
@@ -0,0 +1,215 @@
+package raftstore
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"key-value/services/key-value/internal/encryption"
+	"key-value/services/key-value/internal/sealedlog"
+
+	"github.com/hashicorp/raft"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCipher returns a cipher with a fixed data key
+func testCipher(t *testing.T) *encryption.Cipher {
+	t.Helper()
+	c, err := encryption.NewCipher(bytes.Repeat([]byte{9}, encryption.KeySize))
+	require.NoError(t, err)
+	return c
+}
+
+// openWAL opens the write-ahead log in dir, closing it when the test ends
+func openWAL(t *testing.T, dir string) *walStore {
+	t.Helper()
+	s, err := openWALStore(dir, testCipher(t))
+	require.NoError(t, err)
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// storeEntries appends entries from first to last holding "entry <index>"
+func storeEntries(t *testing.T, s *walStore, first, last uint64) {
+	t.Helper()
+	var logs []*raft.Log
+	for index := first; index <= last; index++ {
+		logs = append(logs, &raft.Log{Index: index, Term: 1, Type: raft.LogCommand, Data: []byte("secret entry")})
+	}
+	require.NoError(t, s.StoreLogs(logs))
+}
+
+// assertRange checks the first and last index of the log
+func assertRange(t *testing.T, s *walStore, first, last uint64) {
+	t.Helper()
+	gotFirst, err := s.FirstIndex()
+	require.NoError(t, err)
+	gotLast, err := s.LastIndex()
+	require.NoError(t, err)
+	assert.Equal(t, [2]uint64{first, last}, [2]uint64{gotFirst, gotLast})
+}
+
+func TestWALStore_Replay(t *testing.T) {
+	dir := t.TempDir()
+	s := openWAL(t, dir)
+	storeEntries(t, s, 1, 5)
+	require.NoError(t, s.DeleteRange(4, 5))
+	storeEntries(t, s, 4, 6)
+	require.NoError(t, s.Set([]byte("vote"), []byte("node-1")))
+	require.NoError(t, s.SetUint64([]byte("term"), 3))
+	require.NoError(t, s.Close())
+
+	file, err := os.ReadFile(filepath.Join(dir, walFileName))
+	require.NoError(t, err)
+	assert.NotContains(t, string(file), "secret entry")
+	assert.NotContains(t, string(file), "node-1")
+
+	s = openWAL(t, dir)
+	assertRange(t, s, 1, 6)
+	var log raft.Log
+	require.NoError(t, s.GetLog(6, &log))
+	assert.Equal(t, "secret entry", string(log.Data))
+	assert.ErrorIs(t, s.GetLog(7, &log), raft.ErrLogNotFound)
+	value, err := s.Get([]byte("vote"))
+	require.NoError(t, err)
+	assert.Equal(t, "node-1", string(value))
+	term, err := s.GetUint64([]byte("term"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), term)
+	_, err = s.Get([]byte("missing"))
+	assert.EqualError(t, err, "not found")
+}
+
+func TestWALStore_Compaction(t *testing.T) {
+	dir := t.TempDir()
+	s := openWAL(t, dir)
+	storeEntries(t, s, 1, 100)
+	require.NoError(t, s.SetUint64([]byte("term"), 2))
+	before, err := os.Stat(filepath.Join(dir, walFileName))
+	require.NoError(t, err)
+
+	require.NoError(t, s.DeleteRange(1, 90))
+	assertRange(t, s, 91, 100)
+	after, err := os.Stat(filepath.Join(dir, walFileName))
+	require.NoError(t, err)
+	assert.Less(t, after.Size(), before.Size(), "compacting rewrites the file")
+
+	// Appends go to the rewritten file
+	storeEntries(t, s, 101, 101)
+	require.NoError(t, s.Close())
+	s = openWAL(t, dir)
+	assertRange(t, s, 91, 101)
+	term, err := s.GetUint64([]byte("term"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), term)
+}
+
+func TestWALStore_InterruptedWrite(t *testing.T) {
+	dir := t.TempDir()
+	s := openWAL(t, dir)
+	storeEntries(t, s, 1, 2)
+	storeEntries(t, s, 3, 3)
+	require.NoError(t, s.Close())
+
+	// A crash while appending leaves the last record cut short
+	path := filepath.Join(dir, walFileName)
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path, info.Size()-5))
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	s = openWAL(t, dir)
+	assertRange(t, s, 1, 2)
+	assert.Contains(t, logged.String(), "interrupted write after record 1")
+	storeEntries(t, s, 3, 4)
+	require.NoError(t, s.Close())
+	s = openWAL(t, dir)
+	assertRange(t, s, 1, 4)
+}
+
+func TestWALStore_Checkpoint(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, walFileName)
+	s := openWAL(t, dir)
+	for index := uint64(1); index < walCheckpointRecords; index++ {
+		storeEntries(t, s, index, index)
+	}
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	storeEntries(t, s, walCheckpointRecords, walCheckpointRecords+1)
+	require.NoError(t, s.Close())
+	assert.Equal(t, uint64(walCheckpointRecords), s.checkpointed)
+
+	// Removing whole records from the end leaves a file that replays, but holds fewer records than checkpointed
+	require.NoError(t, os.Truncate(path, info.Size()))
+	_, err = openWALStore(dir, testCipher(t))
+	assert.ErrorContains(t, err, "file ends after 255 records but held 256")
+	assert.ErrorIs(t, err, encryption.ErrTampered)
+
+	// A checkpoint of another file cannot tell
+	other := t.TempDir()
+	openWAL(t, other).Close()
+	checkpoint, err := os.ReadFile(filepath.Join(other, walCheckpointFileName))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, walCheckpointFileName), checkpoint, 0o600))
+	s = openWAL(t, dir)
+	assertRange(t, s, 1, walCheckpointRecords-1)
+}
+
+func TestWALStore_Tampered(t *testing.T) {
+	dir := t.TempDir()
+	s := openWAL(t, dir)
+	storeEntries(t, s, 1, 2)
+	storeEntries(t, s, 3, 4)
+	require.NoError(t, s.Close())
+	path := filepath.Join(dir, walFileName)
+	file, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		change  func([]byte) []byte
+		err     string
+		errorIs error
+	}{
+		{
+			name:    "flipped bit",
+			change:  func(b []byte) []byte { b[sealedlog.MagicSize+30] ^= 1; return b },
+			err:     "record 0",
+			errorIs: encryption.ErrTampered,
+		},
+		{
+			name: "dropped record",
+			change: func(b []byte) []byte {
+				// Both records seal the same size of data, so the second starts in the middle of the rest
+				header := sealedlog.HeaderSize
+				return append(b[:header:header], b[header+(len(b)-header)/2:]...)
+			},
+			err:     "record 0",
+			errorIs: encryption.ErrTampered,
+		},
+		{name: "other file", change: func([]byte) []byte { return []byte("not a log at all") }, err: "not a write-ahead log"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, os.WriteFile(path, tt.change(bytes.Clone(file)), 0o600))
+			_, err := openWALStore(dir, testCipher(t))
+			assert.ErrorContains(t, err, tt.err)
+			if tt.errorIs != nil {
+				assert.ErrorIs(t, err, tt.errorIs)
+			}
+		})
+	}
+
+	// Another data key cannot read the log either
+	require.NoError(t, os.WriteFile(path, file, 0o600))
+	other, err := encryption.NewCipher(bytes.Repeat([]byte{1}, encryption.KeySize))
+	require.NoError(t, err)
+	_, err = openWALStore(dir, other)
+	assert.ErrorIs(t, err, encryption.ErrTampered)
+}
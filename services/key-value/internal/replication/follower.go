@@ -0,0 +1,266 @@
+package replication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/kvstore"
+	"log"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Follower is a read replica of a primary. It implements kvstore.Storer: reads are served
+// from the local copy and writes return a *kvstore.LeaderError naming the primary.
+type Follower struct {
+	store       kvstore.Storer
+	primaryAddr string
+	conn        *grpc.ClientConn
+	client      keyvalue.ReplicationServiceClient
+
+	dialOptions   []grpc.DialOption
+	retryInterval time.Duration
+
+	mutex      sync.RWMutex
+	appliedSeq uint64
+	primarySeq uint64
+	epoch      uint64
+	connected  bool
+	caughtUpAt time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// FollowerOption configures a Follower
+type FollowerOption func(*Follower)
+
+// WithDialOptions sets the options used to connect to the primary. They default to plaintext.
+func WithDialOptions(opts ...grpc.DialOption) FollowerOption {
+	return func(f *Follower) {
+		f.dialOptions = opts
+	}
+}
+
+// WithRetryInterval sets how long a follower waits before reconnecting to the primary, 1s by default
+func WithRetryInterval(interval time.Duration) FollowerOption {
+	return func(f *Follower) {
+		f.retryInterval = interval
+	}
+}
+
+// NewFollower starts replicating the primary at primaryAddr into store, which must support snapshots to catch up
+func NewFollower(store kvstore.Storer, primaryAddr string, opts ...FollowerOption) (*Follower, error) {
+	f := &Follower{
+		store:         store,
+		primaryAddr:   primaryAddr,
+		dialOptions:   []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
+		retryInterval: time.Second,
+		caughtUpAt:    time.Now(),
+		done:          make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	conn, err := grpc.NewClient(primaryAddr, f.dialOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to primary %s: %w", primaryAddr, err)
+	}
+	f.conn = conn
+	f.client = keyvalue.NewReplicationServiceClient(conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f.cancel = cancel
+	go f.run(ctx)
+
+	return f, nil
+}
+
+// Get reads a key from the local copy, which may lag behind the primary
+func (f *Follower) Get(key string) (string, error) {
+	return f.store.Get(key)
+}
+
+// Scan lists keys of the local store in order
+func (f *Follower) Scan(prefix string, startAfter string, limit int) ([]kvstore.KeyValue, bool, error) {
+	return kvstore.Scan(f.store, prefix, startAfter, limit)
+}
+
+// Set is rejected, writes must go to the primary
+func (f *Follower) Set(key string, value string) error {
+	return f.readOnly()
+}
+
+// Delete is rejected, writes must go to the primary
+func (f *Follower) Delete(key string) error {
+	return f.readOnly()
+}
+
+// Increment is rejected, writes must go to the primary
+func (f *Follower) Increment(key string, delta int64) (int64, error) {
+	return 0, f.readOnly()
+}
+
+// SetIfAbsent is rejected, writes must go to the primary
+func (f *Follower) SetIfAbsent(key string, value string) error {
+	return f.readOnly()
+}
+
+// DeleteIfValue is rejected, writes must go to the primary
+func (f *Follower) DeleteIfValue(key string, value string) error {
+	return f.readOnly()
+}
+
+// Batch is rejected, writes must go to the primary
+func (f *Follower) Batch(checks []kvstore.Check, writes []kvstore.Write) error {
+	return f.readOnly()
+}
+
+func (f *Follower) readOnly() error {
+	return &kvstore.LeaderError{LeaderAddr: f.primaryAddr}
+}
+
+// Status reports how far behind the primary the follower is
+func (f *Follower) Status() Status {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	status := Status{
+		Role:        RoleFollower,
+		PrimaryAddr: f.primaryAddr,
+		AppliedSeq:  f.appliedSeq,
+		PrimarySeq:  f.primarySeq,
+		Connected:   f.connected,
+	}
+	if !f.connected || f.appliedSeq < f.primarySeq {
+		status.Lag = time.Since(f.caughtUpAt)
+	}
+	return status
+}
+
+// Close stops replicating. The local store keeps its data.
+func (f *Follower) Close() error {
+	f.cancel()
+	<-f.done
+	return f.conn.Close()
+}
+
+// run keeps a replication stream open, reconnecting after failures
+func (f *Follower) run(ctx context.Context) {
+	defer close(f.done)
+	for {
+		err := f.replicate(ctx)
+		f.setConnected(false)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Replication from %s interrupted: %v", f.primaryAddr, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(f.retryInterval):
+		}
+	}
+}
+
+// replicate streams from the primary, resuming after the last applied mutation
+func (f *Follower) replicate(ctx context.Context) error {
+	f.mutex.RLock()
+	req := &keyvalue.ReplicateRequest{FromSeq: f.appliedSeq + 1, Epoch: f.epoch}
+	f.mutex.RUnlock()
+
+	stream, err := f.client.Replicate(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	var pending map[string]string
+	for {
+		event, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return errors.New("primary closed the stream")
+		}
+		if err != nil {
+			return err
+		}
+		f.setConnected(true)
+
+		switch e := event.Event.(type) {
+		case *keyvalue.ReplicationEvent_Snapshot:
+			if pending == nil {
+				pending = make(map[string]string)
+			}
+			for key, value := range e.Snapshot.Entries {
+				pending[key] = value
+			}
+			if !e.Snapshot.Last {
+				continue
+			}
+			if err := kvstore.Restore(f.store, pending); err != nil {
+				return fmt.Errorf("failed to restore snapshot at %d: %w", e.Snapshot.Seq, err)
+			}
+			pending = nil
+			f.advance(e.Snapshot.Seq, e.Snapshot.Seq)
+
+		case *keyvalue.ReplicationEvent_Mutation:
+			if err := f.apply(e.Mutation); err != nil {
+				return err
+			}
+			f.advance(e.Mutation.Seq, max(e.Mutation.Seq, f.Status().PrimarySeq))
+
+		case *keyvalue.ReplicationEvent_Heartbeat:
+			f.mutex.Lock()
+			f.epoch = e.Heartbeat.Epoch
+			f.mutex.Unlock()
+			f.advance(f.Status().AppliedSeq, e.Heartbeat.Seq)
+		}
+	}
+}
+
+// apply applies a mutation, which must directly follow the last applied one
+func (f *Follower) apply(m *keyvalue.Mutation) error {
+	f.mutex.RLock()
+	expected := f.appliedSeq + 1
+	f.mutex.RUnlock()
+	if m.Seq != expected {
+		return fmt.Errorf("expected mutation %d, received %d", expected, m.Seq)
+	}
+
+	switch m.Op {
+	case keyvalue.MutationOp_MUTATION_OP_SET:
+		return f.store.Set(m.Key, m.Value)
+	case keyvalue.MutationOp_MUTATION_OP_DELETE:
+		if err := f.store.Delete(m.Key); err != nil && !errors.Is(err, kvstore.ErrNotFound) {
+			return err
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown mutation op %s", m.Op)
+	}
+}
+
+// advance records the applied position and the latest known position of the primary.
+// Only the replication goroutine moves them, so reading the current values beforehand is safe.
+func (f *Follower) advance(appliedSeq uint64, primarySeq uint64) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.appliedSeq = appliedSeq
+	f.primarySeq = primarySeq
+	if f.appliedSeq >= f.primarySeq {
+		f.caughtUpAt = time.Now()
+	}
+}
+
+func (f *Follower) setConnected(connected bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.connected = connected
+}
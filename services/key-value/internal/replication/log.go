@@ -0,0 +1,90 @@
+package replication
+
+import (
+	"errors"
+	"sync"
+)
+
+// Op is the kind of change recorded in the mutation log
+type Op string
+
+// Mutation log operations. Increments are recorded as a set of the resulting value so replaying them is idempotent.
+const (
+	OpSet    Op = "set"
+	OpDelete Op = "delete"
+)
+
+// ErrCompacted is returned when the requested entries are no longer retained and a snapshot is needed
+var ErrCompacted = errors.New("log entries are no longer retained")
+
+// maxBatch bounds the number of entries returned by a single read of the log
+const maxBatch = 1024
+
+// Entry is a single mutation, numbered from 1 in the order it was applied
+type Entry struct {
+	Seq   uint64
+	Op    Op
+	Key   string
+	Value string
+}
+
+// changeLog is a bounded, in-memory mutation log. It retains at least capacity entries.
+type changeLog struct {
+	mutex    sync.Mutex
+	entries  []Entry
+	capacity int
+	lastSeq  uint64
+	notify   chan struct{} // closed and replaced on every append
+}
+
+func newChangeLog(capacity int) *changeLog {
+	return &changeLog{
+		capacity: capacity,
+		notify:   make(chan struct{}),
+	}
+}
+
+// append records a mutation and wakes up readers waiting for it
+func (l *changeLog) append(op Op, key string, value string) Entry {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.lastSeq++
+	entry := Entry{Seq: l.lastSeq, Op: op, Key: key, Value: value}
+	l.entries = append(l.entries, entry)
+
+	// Trim in bulk so appends stay amortized O(1)
+	if len(l.entries) >= 2*l.capacity {
+		l.entries = append([]Entry(nil), l.entries[len(l.entries)-l.capacity:]...)
+	}
+
+	close(l.notify)
+	l.notify = make(chan struct{})
+	return entry
+}
+
+// since returns the entries starting at from, and a channel closed when a new entry is appended
+func (l *changeLog) since(from uint64) ([]Entry, <-chan struct{}, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if from > l.lastSeq {
+		return nil, l.notify, nil
+	}
+
+	firstSeq := l.lastSeq - uint64(len(l.entries)) + 1
+	if from < firstSeq {
+		return nil, l.notify, ErrCompacted
+	}
+
+	start := int(from - firstSeq)
+	end := min(len(l.entries), start+maxBatch)
+	return append([]Entry(nil), l.entries[start:end]...), l.notify, nil
+}
+
+// seq returns the sequence number of the latest entry
+func (l *changeLog) seq() uint64 {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.lastSeq
+}
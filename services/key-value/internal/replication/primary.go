@@ -0,0 +1,192 @@
+package replication
+
+import (
+	"key-value/services/key-value/internal/kvstore"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Replication roles
+const (
+	RolePrimary  = "primary"
+	RoleFollower = "follower"
+)
+
+// DefaultLogSize is the number of mutations a primary retains for followers catching up
+const DefaultLogSize = 10000
+
+// Status describes the replication state of a node
+type Status struct {
+	Role        string
+	PrimaryAddr string        // Empty on the primary
+	AppliedSeq  uint64        // Last mutation applied locally
+	PrimarySeq  uint64        // Last mutation known to exist on the primary
+	Lag         time.Duration // Time since the node was last known to be caught up
+	Connected   bool          // Whether a follower is currently streaming from the primary
+}
+
+// LagEntries returns how many mutations the node is behind the primary
+func (s Status) LagEntries() uint64 {
+	if s.PrimarySeq < s.AppliedSeq {
+		return 0
+	}
+	return s.PrimarySeq - s.AppliedSeq
+}
+
+// Primary wraps a store and records every mutation in a bounded log streamed to followers.
+// It implements kvstore.Storer.
+type Primary struct {
+	mutex sync.Mutex // orders mutations of the store with their log entries
+	store kvstore.Storer
+	log   *changeLog
+	epoch uint64 // identifies this incarnation of the log, which starts over on restart
+}
+
+// NewPrimary creates a primary over store, which must support snapshots to bring followers up to date.
+// logSize is the number of mutations retained, DefaultLogSize when zero.
+func NewPrimary(store kvstore.Storer, logSize int) *Primary {
+	if logSize <= 0 {
+		logSize = DefaultLogSize
+	}
+	return &Primary{
+		store: store,
+		log:   newChangeLog(logSize),
+		epoch: uint64(time.Now().UnixNano()),
+	}
+}
+
+// Get reads a key from the local store
+func (p *Primary) Get(key string) (string, error) {
+	return p.store.Get(key)
+}
+
+// Scan lists keys of the local store in order
+func (p *Primary) Scan(prefix string, startAfter string, limit int) ([]kvstore.KeyValue, bool, error) {
+	return kvstore.Scan(p.store, prefix, startAfter, limit)
+}
+
+// Set stores a key-value pair and records it
+func (p *Primary) Set(key string, value string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err := p.store.Set(key, value); err != nil {
+		return err
+	}
+	p.log.append(OpSet, key, value)
+	return nil
+}
+
+// Delete removes a key and records it
+func (p *Primary) Delete(key string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err := p.store.Delete(key); err != nil {
+		return err
+	}
+	p.log.append(OpDelete, key, "")
+	return nil
+}
+
+// Increment adds delta to a key and records the resulting value
+func (p *Primary) Increment(key string, delta int64) (int64, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	value, err := p.store.Increment(key, delta)
+	if err != nil {
+		return 0, err
+	}
+	p.log.append(OpSet, key, strconv.FormatInt(value, 10))
+	return value, nil
+}
+
+// SetIfAbsent stores a key-value pair only if the key does not exist, recording it when it does
+func (p *Primary) SetIfAbsent(key string, value string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err := kvstore.SetIfAbsent(p.store, key, value); err != nil {
+		return err
+	}
+	p.log.append(OpSet, key, value)
+	return nil
+}
+
+// DeleteIfValue removes a key only if it holds value, recording it when it does
+func (p *Primary) DeleteIfValue(key string, value string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err := kvstore.DeleteIfValue(p.store, key, value); err != nil {
+		return err
+	}
+	p.log.append(OpDelete, key, "")
+	return nil
+}
+
+// Batch applies writes only if every check holds, recording each of them. The primary mutex keeps other writes out
+// between the checks and the writes.
+func (p *Primary) Batch(checks []kvstore.Check, writes []kvstore.Write) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err := kvstore.CheckBatch(p.store, checks); err != nil {
+		return err
+	}
+	for _, write := range writes {
+		if write.Delete {
+			if err := p.store.Delete(write.Key); err != nil {
+				return err
+			}
+			p.log.append(OpDelete, write.Key, "")
+			continue
+		}
+		if err := p.store.Set(write.Key, write.Value); err != nil {
+			return err
+		}
+		p.log.append(OpSet, write.Key, write.Value)
+	}
+	return nil
+}
+
+// Changes returns the mutations starting at fromSeq and a channel closed when more are recorded.
+// It returns ErrCompacted when fromSeq is no longer retained.
+func (p *Primary) Changes(fromSeq uint64) ([]Entry, <-chan struct{}, error) {
+	return p.log.since(fromSeq)
+}
+
+// Snapshot returns a copy of the data and the sequence number it reflects
+func (p *Primary) Snapshot() (map[string]string, uint64, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	data, err := kvstore.Snapshot(p.store)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, p.log.seq(), nil
+}
+
+// Seq returns the sequence number of the latest mutation
+func (p *Primary) Seq() uint64 {
+	return p.log.seq()
+}
+
+// Epoch identifies this incarnation of the mutation log
+func (p *Primary) Epoch() uint64 {
+	return p.epoch
+}
+
+// Status reports the primary's position in its own log
+func (p *Primary) Status() Status {
+	seq := p.log.seq()
+	return Status{
+		Role:       RolePrimary,
+		AppliedSeq: seq,
+		PrimarySeq: seq,
+		Connected:  true,
+	}
+}
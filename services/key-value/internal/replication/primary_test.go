@@ -0,0 +1,109 @@
+package replication
+
+import (
+	"errors"
+	"testing"
+
+	"key-value/services/key-value/internal/kvstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrimary_RecordsMutations(t *testing.T) {
+	primary := NewPrimary(kvstore.NewInMemoryStore(), 10)
+
+	require.NoError(t, primary.Set("a", "1"))
+	_, err := primary.Increment("counter", 5)
+	require.NoError(t, err)
+	require.NoError(t, primary.Delete("a"))
+
+	// Failed mutations are not recorded
+	require.NoError(t, primary.Set("text", "abc"))
+	_, err = primary.Increment("text", 1)
+	assert.ErrorIs(t, err, kvstore.ErrNotNumeric)
+
+	entries, _, err := primary.Changes(1)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Seq: 1, Op: OpSet, Key: "a", Value: "1"},
+		{Seq: 2, Op: OpSet, Key: "counter", Value: "5"},
+		{Seq: 3, Op: OpDelete, Key: "a"},
+		{Seq: 4, Op: OpSet, Key: "text", Value: "abc"},
+	}, entries)
+	assert.Equal(t, uint64(4), primary.Seq())
+
+	entries, _, err = primary.Changes(4)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestPrimary_Batch(t *testing.T) {
+	primary := NewPrimary(kvstore.NewInMemoryStore(), 10)
+	require.NoError(t, primary.Set("a", "1"))
+
+	err := primary.Batch([]kvstore.Check{{Key: "a", Absent: true}}, []kvstore.Write{{Key: "b", Value: "2"}})
+	assert.ErrorIs(t, err, kvstore.ErrConflict)
+	checks := []kvstore.Check{{Key: "a", Value: "1"}}
+	require.NoError(t, primary.Batch(checks, []kvstore.Write{{Key: "a", Delete: true}, {Key: "b", Value: "2"}}))
+
+	entries, _, err := primary.Changes(2)
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Seq: 2, Op: OpDelete, Key: "a"},
+		{Seq: 3, Op: OpSet, Key: "b", Value: "2"},
+	}, entries)
+}
+
+func TestPrimary_NotifiesOnAppend(t *testing.T) {
+	primary := NewPrimary(kvstore.NewInMemoryStore(), 10)
+
+	entries, notify, err := primary.Changes(1)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	require.NoError(t, primary.Set("a", "1"))
+	select {
+	case <-notify:
+	default:
+		t.Fatal("expected notification after append")
+	}
+}
+
+func TestPrimary_Compaction(t *testing.T) {
+	primary := NewPrimary(kvstore.NewInMemoryStore(), 2)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, primary.Set("key", "value"))
+	}
+
+	_, _, err := primary.Changes(1)
+	assert.True(t, errors.Is(err, ErrCompacted))
+
+	// At least the configured number of entries is retained
+	entries, _, err := primary.Changes(3)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	data, seq, err := primary.Snapshot()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), seq)
+	assert.Equal(t, map[string]string{"key": "value"}, data)
+}
+
+func TestFollower_RejectsWrites(t *testing.T) {
+	follower, err := NewFollower(kvstore.NewInMemoryStore(), "primary:50051")
+	require.NoError(t, err)
+	defer follower.Close()
+
+	var leaderErr *kvstore.LeaderError
+	require.ErrorAs(t, follower.Set("a", "1"), &leaderErr)
+	assert.Equal(t, "primary:50051", leaderErr.LeaderAddr)
+	assert.ErrorIs(t, follower.Delete("a"), kvstore.ErrNotLeader)
+	_, err = follower.Increment("a", 1)
+	assert.ErrorIs(t, err, kvstore.ErrNotLeader)
+	assert.ErrorIs(t, follower.Batch(nil, []kvstore.Write{{Key: "a"}}), kvstore.ErrNotLeader)
+
+	status := follower.Status()
+	assert.Equal(t, RoleFollower, status.Role)
+	assert.False(t, status.Connected)
+}
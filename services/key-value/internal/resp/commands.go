@@ -0,0 +1,609 @@
+package resp
+
+import (
+	"errors"
+	"fmt"
+	"key-value/services/key-value/internal/kvstore"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults of the key listing commands
+const (
+	defaultScanCount = 10
+	maxScanCount     = 1000 // Larger counts are lowered to this, like the scan limit of the gRPC service
+	keysPageSize     = 1000
+)
+
+// replyError is an error sent to the client as is, starting with its code
+type replyError string
+
+func (e replyError) Error() string {
+	return string(e)
+}
+
+var (
+	errSyntax      = replyError("ERR syntax error")
+	errNotInteger  = replyError("ERR value is not an integer or out of range")
+	errNoExpiry    = replyError("ERR key expiry is not supported by this store")
+	errNoScan      = replyError("ERR listing keys is not supported by this store")
+	errNoCondition = replyError("ERR conditional writes are not supported by this store")
+)
+
+// command is a supported command
+type command struct {
+	arity  int // number of arguments including the name, or the minimum negated
+	handle func(c *session, args []string) error
+}
+
+var commands = map[string]command{
+	"ping":    {-1, ping},
+	"echo":    {2, echo},
+	"hello":   {-1, hello},
+	"quit":    {1, quit},
+	"select":  {2, selectDB},
+	"command": {-1, commandDocs},
+	"get":     {2, get},
+	"set":     {-3, set},
+	"del":     {-2, del},
+	"exists":  {-2, exists},
+	"incr":    {2, incr},
+	"incrby":  {3, incrBy},
+	"decr":    {2, decr},
+	"decrby":  {3, decrBy},
+	"expire":  {3, expire},
+	"pexpire": {3, pexpire},
+	"ttl":     {2, ttl},
+	"pttl":    {2, pttl},
+	"mget":    {-2, mget},
+	"mset":    {-3, mset},
+	"keys":    {2, keys},
+	"scan":    {-2, scan},
+}
+
+// run executes a command and writes its reply
+func (c *session) run(args []string) {
+	cmd, ok := commands[strings.ToLower(args[0])]
+	if !ok {
+		c.writer.error(fmt.Sprintf("ERR unknown command '%s'", args[0]))
+		return
+	}
+	if (cmd.arity > 0 && len(args) != cmd.arity) || len(args) < -cmd.arity {
+		c.writer.error(fmt.Sprintf("ERR wrong number of arguments for '%s' command", strings.ToLower(args[0])))
+		return
+	}
+	if err := cmd.handle(c, args[1:]); err != nil {
+		c.writer.error(toReply(err))
+	}
+}
+
+// toReply converts a store error into a Redis error message
+func toReply(err error) string {
+	var reply replyError
+	switch {
+	case errors.As(err, &reply):
+		return string(reply)
+	case errors.Is(err, kvstore.ErrNotNumeric):
+		return string(errNotInteger)
+	case errors.Is(err, kvstore.ErrOverflow):
+		return "ERR increment or decrement would overflow"
+	case errors.Is(err, kvstore.ErrNotLeader):
+		return "READONLY " + err.Error()
+	default:
+		return "ERR " + err.Error()
+	}
+}
+
+func ping(c *session, args []string) error {
+	switch len(args) {
+	case 0:
+		c.writer.simple("PONG")
+	case 1:
+		c.writer.bulk(args[0])
+	default:
+		return replyError("ERR wrong number of arguments for 'ping' command")
+	}
+	return nil
+}
+
+func echo(c *session, args []string) error {
+	c.writer.bulk(args[0])
+	return nil
+}
+
+// hello switches the protocol version and describes the server
+func hello(c *session, args []string) error {
+	proto := c.writer.proto
+	if len(args) > 0 {
+		version, err := strconv.Atoi(args[0])
+		if err != nil {
+			return replyError("ERR Protocol version is not an integer or out of range")
+		}
+		if version != 2 && version != 3 {
+			return replyError("NOPROTO unsupported protocol version")
+		}
+		proto = version
+		for i := 1; i < len(args); i++ {
+			switch strings.ToUpper(args[i]) {
+			case "SETNAME":
+				i++
+			case "AUTH":
+				return replyError("ERR AUTH is not supported")
+			default:
+				return errSyntax
+			}
+			if i >= len(args) {
+				return errSyntax
+			}
+		}
+	}
+
+	c.writer.proto = proto
+	c.writer.mapHeader(7)
+	c.writer.bulk("server")
+	c.writer.bulk("key-value")
+	c.writer.bulk("version")
+	c.writer.bulk("1.0.0")
+	c.writer.bulk("proto")
+	c.writer.integer(int64(proto))
+	c.writer.bulk("id")
+	c.writer.integer(c.id)
+	c.writer.bulk("mode")
+	c.writer.bulk("standalone")
+	c.writer.bulk("role")
+	c.writer.bulk("master")
+	c.writer.bulk("modules")
+	c.writer.array(0)
+	return nil
+}
+
+func quit(c *session, args []string) error {
+	c.writer.simple("OK")
+	c.quit = true
+	return nil
+}
+
+// selectDB accepts the only database, 0
+func selectDB(c *session, args []string) error {
+	if args[0] != "0" {
+		return replyError("ERR DB index is out of range")
+	}
+	c.writer.simple("OK")
+	return nil
+}
+
+// commandDocs answers COMMAND and its subcommands with an empty list, which clients take as no details
+func commandDocs(c *session, args []string) error {
+	c.writer.array(0)
+	return nil
+}
+
+func get(c *session, args []string) error {
+	value, found, err := c.get(args[0])
+	if err != nil {
+		return err
+	}
+	if !found {
+		c.writer.null()
+		return nil
+	}
+	c.writer.bulk(value)
+	return nil
+}
+
+// set stores a value with the options EX seconds, PX milliseconds, NX and XX.
+// XX checks that the key exists before writing, so it races with a concurrent delete.
+func set(c *session, args []string) error {
+	key, value := args[0], args[1]
+	var (
+		ttl    time.Duration
+		nx, xx bool
+	)
+	for i := 2; i < len(args); i++ {
+		switch option := strings.ToUpper(args[i]); option {
+		case "NX":
+			nx = true
+		case "XX":
+			xx = true
+		case "EX", "PX":
+			if ttl != 0 || i+1 >= len(args) {
+				return errSyntax
+			}
+			i++
+			n, err := strconv.ParseInt(args[i], 10, 64)
+			if err != nil {
+				return errNotInteger
+			}
+			unit := time.Second
+			if option == "PX" {
+				unit = time.Millisecond
+			}
+			if n <= 0 || n > math.MaxInt64/int64(unit) {
+				return replyError("ERR invalid expire time in 'set' command")
+			}
+			ttl = time.Duration(n) * unit
+		default:
+			return errSyntax
+		}
+	}
+	if nx && xx {
+		return errSyntax
+	}
+	if err := kvstore.CheckLimits(c.server.limits, key, value); err != nil {
+		return err
+	}
+
+	if xx {
+		_, found, err := c.get(key)
+		if err != nil {
+			return err
+		}
+		if !found {
+			c.writer.null()
+			return nil
+		}
+	}
+
+	written, err := c.set(key, value, ttl, nx)
+	if err != nil {
+		return err
+	}
+	if !written {
+		c.writer.null()
+		return nil
+	}
+	c.writer.simple("OK")
+	return nil
+}
+
+func del(c *session, args []string) error {
+	var deleted int64
+	for _, key := range args {
+		_, found, err := c.get(key)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		if err := c.server.store.Delete(key); err != nil {
+			return err
+		}
+		deleted++
+	}
+	c.writer.integer(deleted)
+	return nil
+}
+
+func exists(c *session, args []string) error {
+	var n int64
+	for _, key := range args {
+		_, found, err := c.get(key)
+		if err != nil {
+			return err
+		}
+		if found {
+			n++
+		}
+	}
+	c.writer.integer(n)
+	return nil
+}
+
+func incr(c *session, args []string) error {
+	return c.increment(args[0], 1)
+}
+
+func decr(c *session, args []string) error {
+	return c.increment(args[0], -1)
+}
+
+func incrBy(c *session, args []string) error {
+	delta, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return errNotInteger
+	}
+	return c.increment(args[0], delta)
+}
+
+func decrBy(c *session, args []string) error {
+	delta, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil || delta == math.MinInt64 {
+		return errNotInteger
+	}
+	return c.increment(args[0], -delta)
+}
+
+func expire(c *session, args []string) error {
+	return c.expire(args[0], args[1], time.Second)
+}
+
+func pexpire(c *session, args []string) error {
+	return c.expire(args[0], args[1], time.Millisecond)
+}
+
+func ttl(c *session, args []string) error {
+	return c.ttl(args[0], time.Second)
+}
+
+func pttl(c *session, args []string) error {
+	return c.ttl(args[0], time.Millisecond)
+}
+
+func mget(c *session, args []string) error {
+	values := make([]*string, len(args))
+	for i, key := range args {
+		value, found, err := c.get(key)
+		if err != nil {
+			return err
+		}
+		if found {
+			values[i] = &value
+		}
+	}
+
+	c.writer.array(len(values))
+	for _, value := range values {
+		if value == nil {
+			c.writer.null()
+		} else {
+			c.writer.bulk(*value)
+		}
+	}
+	return nil
+}
+
+// mset stores several key-value pairs one after the other, so readers may see some of them before the others
+func mset(c *session, args []string) error {
+	if len(args)%2 != 0 {
+		return replyError("ERR wrong number of arguments for 'mset' command")
+	}
+	for i := 0; i < len(args); i += 2 {
+		if err := kvstore.CheckLimits(c.server.limits, args[i], args[i+1]); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < len(args); i += 2 {
+		if err := c.server.store.Set(args[i], args[i+1]); err != nil {
+			return err
+		}
+	}
+	c.writer.simple("OK")
+	return nil
+}
+
+func keys(c *session, args []string) error {
+	pattern := args[0]
+	prefix := literalPrefix(pattern)
+
+	var matched []string
+	startAfter := ""
+	for {
+		pairs, more, err := kvstore.ScanPublic(c.server.store, prefix, startAfter, keysPageSize)
+		if errors.Is(err, kvstore.ErrUnsupported) {
+			return errNoScan
+		}
+		if err != nil {
+			return err
+		}
+		for _, pair := range pairs {
+			if match(pattern, pair.Key) {
+				matched = append(matched, pair.Key)
+			}
+		}
+		if !more || len(pairs) == 0 {
+			break
+		}
+		startAfter = pairs[len(pairs)-1].Key
+	}
+	c.writer.bulks(matched)
+	return nil
+}
+
+// scan lists a page of keys with the options MATCH pattern, COUNT n and TYPE string. Cursors are kept by the
+// server, so a scan can continue on another connection while the cursor is among the most recent ones.
+func scan(c *session, args []string) error {
+	cursor, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return replyError("ERR invalid cursor")
+	}
+	startAfter := ""
+	if cursor != 0 {
+		var ok bool
+		if startAfter, ok = c.server.cursors.load(cursor); !ok {
+			return replyError("ERR invalid cursor")
+		}
+	}
+
+	pattern, count, onlyStrings := "*", defaultScanCount, true
+	for i := 1; i < len(args); i += 2 {
+		if i+1 >= len(args) {
+			return errSyntax
+		}
+		switch strings.ToUpper(args[i]) {
+		case "MATCH":
+			pattern = args[i+1]
+		case "COUNT":
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 1 {
+				return errSyntax
+			}
+			count = min(n, maxScanCount)
+		case "TYPE":
+			// Every value is a string
+			onlyStrings = strings.EqualFold(args[i+1], "string")
+		default:
+			return errSyntax
+		}
+	}
+
+	prefix := literalPrefix(pattern)
+	if startAfter < prefix {
+		startAfter = ""
+	}
+	pairs, more, err := kvstore.ScanPublic(c.server.store, prefix, startAfter, count)
+	if errors.Is(err, kvstore.ErrUnsupported) {
+		return errNoScan
+	}
+	if err != nil {
+		return err
+	}
+	matched := []string{}
+	for _, pair := range pairs {
+		if onlyStrings && match(pattern, pair.Key) {
+			matched = append(matched, pair.Key)
+		}
+	}
+
+	next := uint64(0)
+	if more && len(pairs) > 0 {
+		next = c.server.cursors.save(pairs[len(pairs)-1].Key)
+	}
+	c.writer.array(2)
+	c.writer.bulk(strconv.FormatUint(next, 10))
+	c.writer.bulks(matched)
+	return nil
+}
+
+// get reads a key, reporting whether it exists
+func (c *session) get(key string) (string, bool, error) {
+	if err := kvstore.CheckKey(c.server.limits, key); err != nil {
+		return "", false, err
+	}
+	value, err := c.server.store.Get(key)
+	if errors.Is(err, kvstore.ErrNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// set writes a key, expiring it after ttl unless zero and only if it does not exist when nx is set.
+// It reports whether the key was written.
+func (c *session) set(key string, value string, ttl time.Duration, nx bool) (bool, error) {
+	store := c.server.store
+	if ttl == 0 {
+		if !nx {
+			return true, store.Set(key, value)
+		}
+		err := kvstore.SetIfAbsent(store, key, value)
+		if errors.Is(err, kvstore.ErrConflict) {
+			return false, nil
+		}
+		if errors.Is(err, kvstore.ErrUnsupported) {
+			return false, errNoCondition
+		}
+		return err == nil, err
+	}
+
+	expirer, ok := store.(Expirer)
+	if !ok {
+		return false, errNoExpiry
+	}
+	l, err := expirer.Grant(ttl)
+	if err != nil {
+		return false, err
+	}
+	if !nx {
+		return true, expirer.SetWithLease(key, value, l.ID)
+	}
+	_, _, acquired, err := expirer.Acquire(key, value, l.ID)
+	if err != nil || !acquired {
+		expirer.Revoke(l.ID)
+	}
+	return acquired, err
+}
+
+// increment adds delta to a key and replies with the result
+func (c *session) increment(key string, delta int64) error {
+	if err := kvstore.CheckKey(c.server.limits, key); err != nil {
+		return err
+	}
+	result, err := c.server.store.Increment(key, delta)
+	if err != nil {
+		return err
+	}
+	c.writer.integer(result)
+	return nil
+}
+
+// expire sets the time to live of a key in unit, deleting it right away when not positive.
+// It replies 1 when the key exists and 0 otherwise.
+func (c *session) expire(key string, amount string, unit time.Duration) error {
+	n, err := strconv.ParseInt(amount, 10, 64)
+	if err != nil {
+		return errNotInteger
+	}
+	// Longer times would overflow a time.Duration
+	if n > math.MaxInt64/int64(unit) {
+		return replyError("ERR invalid expire time")
+	}
+	_, found, err := c.get(key)
+	if err != nil {
+		return err
+	}
+	if !found {
+		c.writer.integer(0)
+		return nil
+	}
+	if n <= 0 {
+		if err := c.server.store.Delete(key); err != nil {
+			return err
+		}
+		c.writer.integer(1)
+		return nil
+	}
+
+	expirer, ok := c.server.store.(Expirer)
+	if !ok {
+		return errNoExpiry
+	}
+	l, err := expirer.Grant(time.Duration(n) * unit)
+	if err != nil {
+		return err
+	}
+	err = expirer.Attach(key, l.ID)
+	if err != nil {
+		expirer.Revoke(l.ID)
+	}
+	if errors.Is(err, kvstore.ErrNotFound) {
+		c.writer.integer(0)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	c.writer.integer(1)
+	return nil
+}
+
+// ttl replies with the time to live of a key in unit, rounded, -1 when it does not expire and -2 when it does not exist
+func (c *session) ttl(key string, unit time.Duration) error {
+	_, found, err := c.get(key)
+	if err != nil {
+		return err
+	}
+	if !found {
+		c.writer.integer(-2)
+		return nil
+	}
+	expirer, ok := c.server.store.(Expirer)
+	if !ok {
+		c.writer.integer(-1)
+		return nil
+	}
+	l, ok, err := expirer.Lookup(key)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		c.writer.integer(-1)
+		return nil
+	}
+	c.writer.integer(int64((l.Remaining + unit/2) / unit))
+	return nil
+}
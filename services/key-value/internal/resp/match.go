@@ -0,0 +1,129 @@
+package resp
+
+import (
+	"strings"
+	"sync"
+)
+
+// maxCursors is the number of SCAN cursors kept; older ones become invalid
+const maxCursors = 1024
+
+// cursors maps SCAN cursors to the last key returned, since Redis clients expect numeric cursors
+type cursors struct {
+	mutex sync.Mutex
+	last  uint64
+	keys  map[uint64]string
+	order []uint64 // oldest first
+}
+
+func newCursors() *cursors {
+	return &cursors{keys: make(map[uint64]string)}
+}
+
+// save returns a new cursor continuing after key
+func (c *cursors) save(key string) uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.last++
+	c.keys[c.last] = key
+	c.order = append(c.order, c.last)
+	if len(c.order) > maxCursors {
+		delete(c.keys, c.order[0])
+		c.order = c.order[1:]
+	}
+	return c.last
+}
+
+// load returns the key a cursor continues after
+func (c *cursors) load(cursor uint64) (string, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	key, ok := c.keys[cursor]
+	return key, ok
+}
+
+// literalPrefix returns the part of a glob pattern before its first special character
+func literalPrefix(pattern string) string {
+	if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+		return pattern[:i]
+	}
+	return pattern
+}
+
+// match reports whether s matches a Redis glob pattern: * matches any sequence, ? any character, [abc], [^abc]
+// and [a-z] a character of the set, and \ escapes the next character
+func match(pattern string, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for len(pattern) > 0 && pattern[0] == '*' {
+				pattern = pattern[1:]
+			}
+			if pattern == "" {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if match(pattern, s[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if s == "" {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		case '[':
+			if s == "" {
+				return false
+			}
+			matched, rest := matchClass(pattern[1:], s[0])
+			if !matched {
+				return false
+			}
+			pattern, s = rest, s[1:]
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+		default:
+			if s == "" || pattern[0] != s[0] {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		}
+	}
+	return s == ""
+}
+
+// matchClass matches c against the character class at the start of pattern, after its '[', and returns the
+// pattern after the class
+func matchClass(pattern string, c byte) (bool, string) {
+	negate := false
+	if len(pattern) > 0 && pattern[0] == '^' {
+		negate, pattern = true, pattern[1:]
+	}
+	matched := false
+	for len(pattern) > 0 && pattern[0] != ']' {
+		switch {
+		case pattern[0] == '\\' && len(pattern) > 1:
+			matched = matched || pattern[1] == c
+			pattern = pattern[2:]
+		case len(pattern) > 2 && pattern[1] == '-' && pattern[2] != ']':
+			lo, hi := pattern[0], pattern[2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			matched = matched || (lo <= c && c <= hi)
+			pattern = pattern[3:]
+		default:
+			matched = matched || pattern[0] == c
+			pattern = pattern[1:]
+		}
+	}
+	if len(pattern) > 0 {
+		pattern = pattern[1:] // the closing ']'
+	}
+	return matched != negate, pattern
+}
@@ -0,0 +1,170 @@
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Protocol bounds, protecting the server from oversized requests
+const (
+	maxInlineLength = 64 << 10 // bytes of an inline command or a header line
+	maxArgs         = 1 << 20  // arguments of a single command
+)
+
+// ErrProtocol is returned for malformed requests. The server replies with an error and closes the connection.
+var ErrProtocol = errors.New("Protocol error")
+
+// reader parses client commands, sent as arrays of bulk strings or inline
+type reader struct {
+	r       *bufio.Reader
+	maxBulk int
+}
+
+func newReader(r io.Reader, maxBulk int) *reader {
+	return &reader{r: bufio.NewReader(r), maxBulk: maxBulk}
+}
+
+// command reads the next command. Empty commands are skipped.
+func (r *reader) command() ([]string, error) {
+	for {
+		line, err := r.line()
+		if err != nil {
+			return nil, err
+		}
+		if line == "" {
+			continue
+		}
+		if line[0] != '*' {
+			if args := strings.Fields(line); len(args) > 0 {
+				return args, nil
+			}
+			continue
+		}
+
+		n, err := strconv.Atoi(line[1:])
+		if err != nil || n > maxArgs {
+			return nil, fmt.Errorf("%w: invalid multibulk length", ErrProtocol)
+		}
+		if n <= 0 {
+			continue
+		}
+		args := make([]string, n)
+		for i := range args {
+			if args[i], err = r.bulk(); err != nil {
+				return nil, err
+			}
+		}
+		return args, nil
+	}
+}
+
+// bulk reads a bulk string argument
+func (r *reader) bulk() (string, error) {
+	line, err := r.line()
+	if err != nil {
+		return "", err
+	}
+	if line == "" || line[0] != '$' {
+		return "", fmt.Errorf("%w: expected '$', got '%.1s'", ErrProtocol, line)
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 0 || n > r.maxBulk {
+		return "", fmt.Errorf("%w: invalid bulk length", ErrProtocol)
+	}
+
+	buf := make([]byte, n+2)
+	if _, err := io.ReadFull(r.r, buf); err != nil {
+		return "", err
+	}
+	if buf[n] != '\r' || buf[n+1] != '\n' {
+		return "", fmt.Errorf("%w: bulk string not terminated by CRLF", ErrProtocol)
+	}
+	return string(buf[:n]), nil
+}
+
+// line reads a line without its terminator, accepting a bare LF like Redis does for inline commands
+func (r *reader) line() (string, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := r.r.ReadLine()
+		if err != nil {
+			return "", err
+		}
+		line = append(line, chunk...)
+		if len(line) > maxInlineLength {
+			return "", fmt.Errorf("%w: too big inline request", ErrProtocol)
+		}
+		if !isPrefix {
+			return string(line), nil
+		}
+	}
+}
+
+// writer encodes replies in RESP2 or RESP3, as negotiated with HELLO
+type writer struct {
+	w     *bufio.Writer
+	proto int
+}
+
+func newWriter(w io.Writer) *writer {
+	return &writer{w: bufio.NewWriter(w), proto: 2}
+}
+
+// simple writes a simple string
+func (w *writer) simple(s string) {
+	w.w.WriteString("+" + s + "\r\n")
+}
+
+// error writes an error, starting with its code such as ERR
+func (w *writer) error(msg string) {
+	w.w.WriteString("-" + strings.NewReplacer("\r", " ", "\n", " ").Replace(msg) + "\r\n")
+}
+
+// integer writes an integer
+func (w *writer) integer(n int64) {
+	w.w.WriteString(":" + strconv.FormatInt(n, 10) + "\r\n")
+}
+
+// bulk writes a bulk string
+func (w *writer) bulk(s string) {
+	w.w.WriteString("$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n")
+}
+
+// null writes a missing value
+func (w *writer) null() {
+	if w.proto == 3 {
+		w.w.WriteString("_\r\n")
+		return
+	}
+	w.w.WriteString("$-1\r\n")
+}
+
+// array starts an array of n elements
+func (w *writer) array(n int) {
+	w.w.WriteString("*" + strconv.Itoa(n) + "\r\n")
+}
+
+// bulks writes an array of bulk strings
+func (w *writer) bulks(items []string) {
+	w.array(len(items))
+	for _, item := range items {
+		w.bulk(item)
+	}
+}
+
+// mapHeader starts a map of n pairs, a flat array of keys and values in RESP2
+func (w *writer) mapHeader(n int) {
+	if w.proto == 3 {
+		w.w.WriteString("%" + strconv.Itoa(n) + "\r\n")
+		return
+	}
+	w.array(2 * n)
+}
+
+func (w *writer) flush() error {
+	return w.w.Flush()
+}
@@ -0,0 +1,175 @@
+// Package resp serves the key-value store over the Redis serialization protocol, RESP2 and RESP3, so Redis tools and
+// client libraries can read and write it. It supports the string commands GET, SET, DEL, EXISTS, INCR, MGET and
+// MSET, key expiry through leases, and listing keys with KEYS and SCAN.
+package resp
+
+import (
+	"errors"
+	"io"
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/services/key-value/internal/lease"
+	"key-value/shared/limits"
+	"log"
+	"net"
+	"sync"
+	"time"
+)
+
+// ErrServerClosed is returned by Serve after Close
+var ErrServerClosed = errors.New("resp: server closed")
+
+// defaultMaxBulk bounds request arguments when the value size is not limited, like the Redis default
+const defaultMaxBulk = 512 << 20
+
+// Expirer expires keys after a time to live. Expiry commands need the store to implement it, as lease.Store does.
+type Expirer interface {
+	Grant(ttl time.Duration) (lease.Lease, error)
+	Revoke(id int64) error
+	SetWithLease(key string, value string, id int64) error
+	Acquire(key string, value string, id int64) (token uint64, holder string, acquired bool, err error)
+	Attach(key string, id int64) error
+	Lookup(key string) (lease.Lease, bool, error)
+}
+
+// Server serves RESP connections over a store
+type Server struct {
+	store   kvstore.Storer
+	limits  limits.Limits
+	cursors *cursors
+
+	mutex     sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	lastID    int64 // last connection ID, reported by HELLO
+	closed    bool
+	wg        sync.WaitGroup
+}
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithLimits sets the key and value limits enforced before commands reach the store
+func WithLimits(l limits.Limits) Option {
+	return func(s *Server) {
+		s.limits = l
+	}
+}
+
+// NewServer creates a RESP server over store enforcing limits.Default() unless WithLimits is given
+func NewServer(store kvstore.Storer, opts ...Option) *Server {
+	s := &Server{
+		store:     store,
+		limits:    limits.Default(),
+		cursors:   newCursors(),
+		listeners: make(map[net.Listener]struct{}),
+		conns:     make(map[net.Conn]struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Serve accepts connections on lis until Close, then returns ErrServerClosed
+func (s *Server) Serve(lis net.Listener) error {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		lis.Close()
+		return ErrServerClosed
+	}
+	s.listeners[lis] = struct{}{}
+	s.mutex.Unlock()
+
+	for {
+		conn, err := lis.Accept()
+		if err != nil {
+			s.mutex.Lock()
+			closed := s.closed
+			delete(s.listeners, lis)
+			s.mutex.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			return err
+		}
+
+		s.mutex.Lock()
+		if s.closed {
+			s.mutex.Unlock()
+			conn.Close()
+			return ErrServerClosed
+		}
+		s.conns[conn] = struct{}{}
+		s.lastID++
+		id := s.lastID
+		s.wg.Add(1)
+		s.mutex.Unlock()
+
+		go s.serveConn(conn, id)
+	}
+}
+
+// Close stops the listeners, closes the connections and waits for their commands to finish
+func (s *Server) Close() error {
+	s.mutex.Lock()
+	s.closed = true
+	for lis := range s.listeners {
+		lis.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mutex.Unlock()
+	s.wg.Wait()
+	return nil
+}
+
+// session is the state of one client connection
+type session struct {
+	id     int64
+	server *Server
+	writer *writer
+	quit   bool
+}
+
+// serveConn runs the commands of a connection until it is closed or sends QUIT
+func (s *Server) serveConn(conn net.Conn, id int64) {
+	defer s.wg.Done()
+	defer func() {
+		s.mutex.Lock()
+		delete(s.conns, conn)
+		s.mutex.Unlock()
+		conn.Close()
+	}()
+
+	// Arguments somewhat over the limits are still read, so they are rejected with an error instead of closing the connection
+	maxBulk := defaultMaxBulk
+	if s.limits.MaxValueSize > 0 {
+		maxBulk = max(s.limits.MaxValueSize, s.limits.MaxKeyLength) + maxInlineLength
+	}
+	r := newReader(conn, maxBulk)
+	c := &session{id: id, server: s, writer: newWriter(conn)}
+
+	for !c.quit {
+		args, err := r.command()
+		if err != nil {
+			if errors.Is(err, ErrProtocol) {
+				c.writer.error("ERR " + err.Error())
+				c.writer.flush()
+			} else if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				log.Printf("resp: reading from %s: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+
+		c.run(args)
+
+		// Flush once the pipelined commands received so far are answered
+		if r.r.Buffered() == 0 || c.quit {
+			if err := c.writer.flush(); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package resp
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/services/key-value/internal/lease"
+	"key-value/shared/limits"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serve starts a server over store and returns its address
+func serve(t *testing.T, store kvstore.Storer) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := NewServer(store, WithLimits(limits.Limits{MaxKeyLength: 16, MaxValueSize: 64}))
+	go server.Serve(lis)
+	t.Cleanup(func() { server.Close() })
+	return lis.Addr().String()
+}
+
+// serveLeases starts a server over a lease store, so keys can expire
+func serveLeases(t *testing.T) string {
+	t.Helper()
+	store := lease.NewStore(kvstore.NewInMemoryStore(), lease.WithCheckInterval(5*time.Millisecond))
+	t.Cleanup(func() { store.Close() })
+	return serve(t, store)
+}
+
+// conn is a raw client connection
+type conn struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func dial(t *testing.T, addr string) *conn {
+	t.Helper()
+	c, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close() })
+	return &conn{t: t, conn: c, r: bufio.NewReader(c)}
+}
+
+// do sends a raw request and asserts the raw reply
+func (c *conn) do(request string, reply string) {
+	c.t.Helper()
+	_, err := c.conn.Write([]byte(request))
+	require.NoError(c.t, err)
+	c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, len(reply))
+	_, err = io.ReadFull(c.r, buf)
+	require.NoError(c.t, err, "waiting for %q", reply)
+	assert.Equal(c.t, reply, string(buf), "reply to %q", request)
+}
+
+// reply reads a whole reply: strings for simple and bulk strings, errors prefixed with "-", int64 for integers,
+// nil for nulls and []any for arrays and maps, flattened into keys and values
+func (c *conn) reply() any {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	line, err := c.r.ReadString('\n')
+	require.NoError(c.t, err)
+	line = strings.TrimSuffix(line, "\r\n")
+	kind, rest := line[0], line[1:]
+	switch kind {
+	case '+':
+		return rest
+	case '-':
+		return line
+	case '_':
+		return nil
+	}
+
+	n, err := strconv.Atoi(rest)
+	require.NoError(c.t, err)
+	switch kind {
+	case ':':
+		return int64(n)
+	case '$':
+		if n < 0 {
+			return nil
+		}
+		buf := make([]byte, n+2)
+		_, err := io.ReadFull(c.r, buf)
+		require.NoError(c.t, err)
+		return string(buf[:n])
+	case '%':
+		n *= 2
+	}
+	items := []any{}
+	for range n {
+		items = append(items, c.reply())
+	}
+	return items
+}
+
+// send writes a command and reads its reply
+func (c *conn) send(args ...string) any {
+	c.t.Helper()
+	_, err := c.conn.Write([]byte(encode(args...)))
+	require.NoError(c.t, err)
+	return c.reply()
+}
+
+// encode encodes a command as an array of bulk strings
+func encode(args ...string) string {
+	var b strings.Builder
+	w := &writer{w: bufio.NewWriter(&b)}
+	w.bulks(args)
+	w.flush()
+	return b.String()
+}
+
+func TestServer_Commands(t *testing.T) {
+	c := dial(t, serveLeases(t))
+
+	tests := []struct {
+		name    string
+		request string
+		reply   string
+	}{
+		{"ping", encode("PING"), "+PONG\r\n"},
+		{"ping message", encode("ping", "hi"), "$2\r\nhi\r\n"},
+		{"inline", "PING\r\n", "+PONG\r\n"},
+		{"get missing", encode("GET", "a"), "$-1\r\n"},
+		{"set", encode("SET", "a", "1"), "+OK\r\n"},
+		{"get", encode("GET", "a"), "$1\r\n1\r\n"},
+		{"set nx existing", encode("SET", "a", "2", "NX"), "$-1\r\n"},
+		{"set nx missing", encode("SET", "b", "2", "nx"), "+OK\r\n"},
+		{"set xx missing", encode("SET", "c", "3", "XX"), "$-1\r\n"},
+		{"set xx existing", encode("SET", "b", "3", "XX"), "+OK\r\n"},
+		{"set nx xx", encode("SET", "b", "3", "NX", "XX"), "-ERR syntax error\r\n"},
+		{"set bad expiry", encode("SET", "b", "3", "EX", "0"), "-ERR invalid expire time in 'set' command\r\n"},
+		{"set overflowing expiry", encode("SET", "b", "3", "EX", "18446744074"), "-ERR invalid expire time in 'set' command\r\n"},
+		{"set overflowing px", encode("SET", "b", "3", "PX", "9223372036855"), "-ERR invalid expire time in 'set' command\r\n"},
+		{"set unknown option", encode("SET", "b", "3", "KEEPTTL"), "-ERR syntax error\r\n"},
+		{"exists", encode("EXISTS", "a", "b", "c", "a"), ":3\r\n"},
+		{"incr", encode("INCR", "a"), ":2\r\n"},
+		{"incrby", encode("INCRBY", "a", "10"), ":12\r\n"},
+		{"decr", encode("DECR", "a"), ":11\r\n"},
+		{"incr text", encode("SET", "t", "x") + encode("INCR", "t"), "+OK\r\n-ERR value is not an integer or out of range\r\n"},
+		{"mset", encode("MSET", "m1", "x", "m2", "y"), "+OK\r\n"},
+		{"mset odd", encode("MSET", "m1", "x", "m2"), "-ERR wrong number of arguments for 'mset' command\r\n"},
+		{"mget", encode("MGET", "m1", "missing", "m2"), "*3\r\n$1\r\nx\r\n$-1\r\n$1\r\ny\r\n"},
+		{"keys", encode("KEYS", "m*"), "*2\r\n$2\r\nm1\r\n$2\r\nm2\r\n"},
+		{"keys class", encode("KEYS", "[ab]"), "*2\r\n$1\r\na\r\n$1\r\nb\r\n"},
+		{"del", encode("DEL", "m1", "m2", "missing"), ":2\r\n"},
+		{"ttl without expiry", encode("TTL", "a"), ":-1\r\n"},
+		{"ttl missing", encode("TTL", "missing"), ":-2\r\n"},
+		{"key too long", encode("SET", strings.Repeat("k", 17), "v"), "-ERR invalid key: key too long"},
+		{"value too large", encode("SET", "v", strings.Repeat("v", 65)), "-ERR too large: value too large"},
+		{"unknown command", encode("FLUSHALL"), "-ERR unknown command 'FLUSHALL'\r\n"},
+		{"wrong arity", encode("GET"), "-ERR wrong number of arguments for 'get' command\r\n"},
+		{"select", encode("SELECT", "0") + encode("SELECT", "1"), "+OK\r\n-ERR DB index is out of range\r\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.t = t
+			c.do(tt.request, tt.reply)
+			if strings.HasSuffix(tt.reply, "\r\n") {
+				return
+			}
+			// Skip the rest of a reply matched by prefix
+			_, err := c.r.ReadString('\n')
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestServer_Expiry(t *testing.T) {
+	c := dial(t, serveLeases(t))
+
+	c.do(encode("SET", "session", "alice", "PX", "50"), "+OK\r\n")
+	assert.Greater(t, c.send("PTTL", "session"), int64(0))
+
+	c.do(encode("SET", "config", "v1"), "+OK\r\n")
+	c.do(encode("EXPIRE", "config", "100"), ":1\r\n")
+	c.do(encode("TTL", "config"), ":100\r\n")
+	c.do(encode("EXPIRE", "missing", "100"), ":0\r\n")
+	c.do(encode("EXPIRE", "config", "9223372037"), "-ERR invalid expire time\r\n")
+	c.do(encode("TTL", "config"), ":100\r\n")
+	c.do(encode("SET", "config", "v2"), "+OK\r\n") // a plain SET removes the expiry
+	c.do(encode("TTL", "config"), ":-1\r\n")
+	c.do(encode("PEXPIRE", "config", "0"), ":1\r\n")
+	c.do(encode("EXISTS", "config"), ":0\r\n")
+
+	// NX with an expiry keeps the existing key and its holder
+	c.do(encode("SET", "lock", "a", "EX", "100", "NX"), "+OK\r\n")
+	c.do(encode("SET", "lock", "b", "EX", "100", "NX"), "$-1\r\n")
+	c.do(encode("GET", "lock"), "$1\r\na\r\n")
+
+	assert.Eventually(t, func() bool {
+		return c.send("EXISTS", "session") == int64(0)
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestServer_ExpiryNeedsLeases(t *testing.T) {
+	c := dial(t, serve(t, kvstore.NewInMemoryStore()))
+
+	c.do(encode("SET", "a", "1", "EX", "10"), "-ERR key expiry is not supported by this store\r\n")
+	c.do(encode("SET", "a", "1"), "+OK\r\n")
+	c.do(encode("TTL", "a"), ":-1\r\n")
+}
+
+func TestServer_Scan(t *testing.T) {
+	c := dial(t, serve(t, kvstore.NewInMemoryStore()))
+	c.do(encode("MSET", "user:1", "a", "user:2", "b", "user:3", "c", "order:1", "d"), "+OK\r\n")
+
+	// Walk the matching keys two at a time
+	var keys []any
+	cursor := "0"
+	for {
+		reply := c.send("SCAN", cursor, "MATCH", "user:*", "COUNT", "2").([]any)
+		require.Len(t, reply, 2)
+		cursor = reply[0].(string)
+		keys = append(keys, reply[1].([]any)...)
+		if cursor == "0" {
+			break
+		}
+	}
+	assert.Equal(t, []any{"user:1", "user:2", "user:3"}, keys)
+
+	// A huge count is lowered rather than sizing anything by it
+	reply := c.send("SCAN", "0", "COUNT", "2000000000").([]any)
+	assert.Equal(t, []any{"0", []any{"order:1", "user:1", "user:2", "user:3"}}, reply)
+
+	c.do(encode("SCAN", "12345"), "-ERR invalid cursor\r\n")
+	c.do(encode("SCAN", "0", "TYPE", "hash"), "*2\r\n$1\r\n0\r\n*0\r\n")
+}
+
+func TestServer_Protocol(t *testing.T) {
+	addr := serve(t, kvstore.NewInMemoryStore())
+
+	t.Run("resp3", func(t *testing.T) {
+		c := dial(t, addr)
+		assert.Nil(t, c.send("GET", "missing"))
+		c.do(encode("GET", "missing"), "$-1\r\n")
+		assert.Equal(t, "-NOPROTO unsupported protocol version", c.send("HELLO", "4"))
+
+		hello := c.send("HELLO", "3").([]any)
+		assert.Equal(t, []any{"server", "key-value"}, hello[:2])
+		assert.Equal(t, []any{"proto", int64(3)}, hello[4:6])
+		c.do(encode("GET", "missing"), "_\r\n")
+		c.do(encode("HELLO", "2"), "*14\r\n")
+	})
+
+	t.Run("pipelined", func(t *testing.T) {
+		c := dial(t, addr)
+		c.do(encode("SET", "p", "1")+encode("INCR", "p")+encode("GET", "p"), "+OK\r\n:2\r\n$1\r\n2\r\n")
+	})
+
+	t.Run("quit", func(t *testing.T) {
+		c := dial(t, addr)
+		c.do(encode("QUIT"), "+OK\r\n")
+		_, err := c.r.ReadByte()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		c := dial(t, addr)
+		c.do("*1\r\n+PING\r\n", "-ERR Protocol error: expected '$', got '+'\r\n")
+		_, err := c.r.ReadByte()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("oversized bulk", func(t *testing.T) {
+		c := dial(t, addr)
+		c.do("*2\r\n$3\r\nGET\r\n$100000000\r\n", "-ERR Protocol error: invalid bulk length\r\n")
+	})
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		want    bool
+	}{
+		{"*", "anything/at:all", true},
+		{"user:*", "user:1", true},
+		{"user:*", "order:1", false},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"h[ae]llo", "hallo", true},
+		{"h[ae]llo", "hillo", false},
+		{"h[^e]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-c]llo", "hbllo", true},
+		{`h\*llo`, "h*llo", true},
+		{`h\*llo`, "hello", false},
+		{"*:*:end", "a:b:c:end", true},
+		{"a*", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.s, func(t *testing.T) {
+			assert.Equal(t, tt.want, match(tt.pattern, tt.s))
+		})
+	}
+	assert.Equal(t, "user:", literalPrefix("user:*"))
+	assert.Equal(t, "exact", literalPrefix("exact"))
+}
@@ -0,0 +1,253 @@
+// Package sealedlog keeps append-only files of records sealed with an encryption.Cipher.
+//
+// A log starts with a header holding a magic, the format version and a random ID. Each record is framed by its
+// length and sealed on its own, bound to the ID of its file and its position, so records cannot be dropped,
+// reordered, repeated or moved between files unnoticed. Only whole records removed from the end cannot be told from
+// a shorter log, which callers needing to can detect by checkpointing the record count.
+package sealedlog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"key-value/services/key-value/internal/encryption"
+	"log"
+	"math"
+	"os"
+)
+
+// version is the format version written after the magic
+const version = 1
+
+// MagicSize is the size of the magic starting a log
+const MagicSize = 8
+
+// IDSize is the size of the random ID written after the version
+const IDSize = 16
+
+// HeaderSize is the size of the magic, the version and the ID
+const HeaderSize = MagicSize + 4 + IDSize
+
+// Format tells the logs of different users apart, so one cannot be opened as the other
+type Format struct {
+	Magic string // MagicSize bytes starting the file
+	Name  string // Names the log in errors, like "write-ahead log"
+	Label string // Binds the records to the format, like "raft wal"
+}
+
+// Log is an open log. It is not safe for concurrent use; callers order their changes with their own lock.
+type Log struct {
+	format  Format
+	path    string
+	cipher  *encryption.Cipher
+	file    *os.File
+	id      []byte
+	records uint64 // Records in the file, numbering the next one
+}
+
+// Open replays the log at path, creating it when missing, and passes the opened records to apply in order. A record
+// cut short at the end of the file is an interrupted write and is dropped with a warning; any other record that fails
+// authentication, and any error of apply, fails the open.
+func Open(path string, format Format, cipher *encryption.Cipher, apply func(record []byte) error) (*Log, error) {
+	if len(format.Magic) != MagicSize {
+		return nil, fmt.Errorf("magic of the %s must be %d bytes", format.Name, MagicSize)
+	}
+	if cipher == nil {
+		return nil, fmt.Errorf("the %s requires a cipher", format.Name)
+	}
+	l := &Log{format: format, path: path, cipher: cipher}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", format.Name, err)
+	}
+	if err := l.replay(file, apply); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to replay %s %s: %w", format.Name, path, err)
+	}
+	l.file = file
+	return l, nil
+}
+
+// replay applies the records of file and leaves it positioned after the last whole one
+func (l *Log) replay(file *os.File, apply func(record []byte) error) error {
+	in := bufio.NewReader(file)
+	header := make([]byte, HeaderSize)
+	n, err := io.ReadFull(in, header)
+	if n == 0 && errors.Is(err, io.EOF) {
+		header, err := l.newHeader()
+		if err != nil {
+			return err
+		}
+		l.id = header[MagicSize+4:]
+		_, err = file.Write(header)
+		return err
+	}
+	if err != nil || string(header[:MagicSize]) != l.format.Magic {
+		return fmt.Errorf("not a %s", l.format.Name)
+	}
+	if v := binary.BigEndian.Uint32(header[MagicSize:]); v != version {
+		return fmt.Errorf("unsupported version %d, expected %d", v, version)
+	}
+	l.id = header[MagicSize+4:]
+
+	end := int64(len(header))
+	for {
+		sealed, err := ReadFrame(in, math.MaxUint32)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			// Drop the interrupted write so new records follow the last whole one
+			info, err := file.Stat()
+			if err != nil {
+				return err
+			}
+			log.Printf("sealedlog: dropping %d bytes of an interrupted write after record %d of %s",
+				info.Size()-end, l.records, l.path)
+			if err := file.Truncate(end); err != nil {
+				return err
+			}
+			break
+		}
+		if err != nil {
+			return err
+		}
+		record, err := l.cipher.Open(sealed, l.recordAD(l.id, l.records))
+		if err != nil {
+			return fmt.Errorf("record %d: %w", l.records, err)
+		}
+		if err := apply(record); err != nil {
+			return fmt.Errorf("record %d: %w", l.records, err)
+		}
+		l.records++
+		end += int64(4 + len(sealed))
+	}
+	_, err = file.Seek(end, io.SeekStart)
+	return err
+}
+
+// ReadFrame reads a length prefixed frame of up to limit bytes, growing it as the bytes arrive rather than trusting
+// the length
+func ReadFrame(in io.Reader, limit int) ([]byte, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(in, length[:]); err != nil {
+		return nil, err
+	}
+	size := int64(binary.BigEndian.Uint32(length[:]))
+	if size > int64(limit) {
+		return nil, fmt.Errorf("record of %d bytes is over the limit of %d: %w", size, limit, encryption.ErrTampered)
+	}
+	var frame bytes.Buffer
+	if n, err := io.CopyN(&frame, in, size); n != size {
+		if err == nil || errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return frame.Bytes(), nil
+}
+
+// AppendFrame appends data prefixed by its length to buf
+func AppendFrame(buf []byte, data []byte) []byte {
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(data)))
+	return append(buf, data...)
+}
+
+// recordAD binds a record to the format, its file and its position
+func (l *Log) recordAD(id []byte, record uint64) []byte {
+	return binary.BigEndian.AppendUint64(append([]byte(l.format.Label+" record "), id...), record)
+}
+
+// newHeader returns the header starting a file under a new random ID
+func (l *Log) newHeader() ([]byte, error) {
+	id := make([]byte, IDSize)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	header := binary.BigEndian.AppendUint32([]byte(l.format.Magic), version)
+	return append(header, id...), nil
+}
+
+// ID returns the random ID of the current file, which changes when the log is rewritten
+func (l *Log) ID() []byte {
+	return l.id
+}
+
+// Records returns the number of records in the current file
+func (l *Log) Records() uint64 {
+	return l.records
+}
+
+// Append seals records to the end of the file and syncs it, so they are durable once it returns
+func (l *Log) Append(records ...[]byte) error {
+	var buf []byte
+	for i, record := range records {
+		buf = AppendFrame(buf, l.cipher.Seal(record, l.recordAD(l.id, l.records+uint64(i))))
+	}
+	offset, err := l.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := l.file.Write(buf); err != nil {
+		// Drop what was written, so the next records do not follow a partial one
+		l.file.Truncate(offset)
+		l.file.Seek(offset, io.SeekStart)
+		return fmt.Errorf("failed to write %s: %w", l.format.Name, err)
+	}
+	if err := l.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync %s: %w", l.format.Name, err)
+	}
+	l.records += uint64(len(records))
+	return nil
+}
+
+// Rewrite replaces the file with a new one under a new ID, holding the records write passes to add only. The new
+// file replaces the old one once it is synced, so a crash leaves either file whole, and a failure keeps the old one.
+func (l *Log) Rewrite(write func(add func(record []byte) error) error) error {
+	temp := l.path + ".tmp"
+	file, err := os.OpenFile(temp, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to rewrite %s: %w", l.format.Name, err)
+	}
+	out := bufio.NewWriter(file)
+	header, err := l.newHeader()
+	var id []byte
+	var records uint64
+	if err == nil {
+		id = header[MagicSize+4:]
+		_, err = out.Write(header)
+	}
+	if err == nil {
+		err = write(func(record []byte) error {
+			_, err := out.Write(AppendFrame(nil, l.cipher.Seal(record, l.recordAD(id, records))))
+			records++
+			return err
+		})
+	}
+	if err == nil {
+		err = out.Flush()
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if err == nil {
+		err = os.Rename(temp, l.path)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(temp)
+		return fmt.Errorf("failed to rewrite %s: %w", l.format.Name, err)
+	}
+	current := l.file
+	l.file, l.id, l.records = file, id, records
+	return current.Close()
+}
+
+// Close closes the file
+func (l *Log) Close() error {
+	return l.file.Close()
+}
@@ -0,0 +1,72 @@
+package sealedlog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"key-value/services/key-value/internal/encryption"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testFormat = Format{Magic: "TESTLOG1", Name: "test log", Label: "test"}
+
+// openLog opens the log at path with a fixed data key, collecting its records
+func openLog(t *testing.T, path string, format Format) (*Log, []string) {
+	t.Helper()
+	cipher, err := encryption.NewCipher(bytes.Repeat([]byte{9}, encryption.KeySize))
+	require.NoError(t, err)
+	var records []string
+	l, err := Open(path, format, cipher, func(record []byte) error {
+		records = append(records, string(record))
+		return nil
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	return l, records
+}
+
+func TestLog_AppendRewrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	l, records := openLog(t, path, testFormat)
+	assert.Empty(t, records)
+	require.NoError(t, l.Append([]byte("secret 1"), []byte("secret 2")))
+	require.NoError(t, l.Append([]byte("secret 3")))
+	assert.Equal(t, uint64(3), l.Records())
+	require.NoError(t, l.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret")
+
+	l, records = openLog(t, path, testFormat)
+	assert.Equal(t, []string{"secret 1", "secret 2", "secret 3"}, records)
+	id := bytes.Clone(l.ID())
+
+	require.NoError(t, l.Rewrite(func(add func([]byte) error) error {
+		return add([]byte("secret 3"))
+	}))
+	assert.Equal(t, uint64(1), l.Records())
+	assert.NotEqual(t, id, l.ID(), "a rewritten log has a new ID")
+	require.NoError(t, l.Append([]byte("secret 4")))
+	require.NoError(t, l.Close())
+
+	_, records = openLog(t, path, testFormat)
+	assert.Equal(t, []string{"secret 3", "secret 4"}, records)
+}
+
+func TestLog_OtherFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	l, _ := openLog(t, path, testFormat)
+	require.NoError(t, l.Close())
+
+	cipher, err := encryption.NewCipher(bytes.Repeat([]byte{9}, encryption.KeySize))
+	require.NoError(t, err)
+	_, err = Open(path, Format{Magic: "FOREIGN1", Name: "foreign log", Label: "foreign"}, cipher, nil)
+	assert.ErrorContains(t, err, "not a foreign log")
+	_, err = Open(path, testFormat, nil, nil)
+	assert.ErrorContains(t, err, "requires a cipher")
+}
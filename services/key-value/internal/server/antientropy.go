@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/antientropy"
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/shared/vclock/vclockpb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AntiEntropyServer implements the gRPC AntiEntropyService, answering peers comparing their versioned keys
+type AntiEntropyServer struct {
+	keyvalue.UnimplementedAntiEntropyServiceServer
+	store    *kvstore.VersionedStore
+	trees    *antientropy.Trees
+	repairer *antientropy.Repairer
+}
+
+// NewAntiEntropyServer serves the trees of store. repairer may be nil when this node has no peers of its own.
+func NewAntiEntropyServer(store *kvstore.VersionedStore, trees *antientropy.Trees, repairer *antientropy.Repairer) *AntiEntropyServer {
+	return &AntiEntropyServer{store: store, trees: trees, repairer: repairer}
+}
+
+// MerkleHashes returns the hashes of the requested nodes of one level
+func (s *AntiEntropyServer) MerkleHashes(ctx context.Context, req *keyvalue.MerkleHashesRequest) (*keyvalue.MerkleHashesResponse, error) {
+	if req.Depth > antientropy.MaxDepth || req.Level > req.Depth {
+		return nil, status.Errorf(codes.InvalidArgument, "level %d of depth %d is outside the maximum depth %d", req.Level, req.Depth, antientropy.MaxDepth)
+	}
+
+	tree := s.trees.Tree(int(req.Depth))
+	resp := &keyvalue.MerkleHashesResponse{Hashes: make([][]byte, len(req.Indexes))}
+	for i, index := range req.Indexes {
+		hash := tree.Hash(int(req.Level), int(index))
+		resp.Hashes[i] = hash[:]
+	}
+	return resp, nil
+}
+
+// BucketDigests returns the digest of every key in the requested leaves
+func (s *AntiEntropyServer) BucketDigests(ctx context.Context, req *keyvalue.BucketDigestsRequest) (*keyvalue.BucketDigestsResponse, error) {
+	if req.Depth > antientropy.MaxDepth {
+		return nil, status.Errorf(codes.InvalidArgument, "depth %d is over the maximum %d", req.Depth, antientropy.MaxDepth)
+	}
+
+	tree := s.trees.Tree(int(req.Depth))
+	resp := &keyvalue.BucketDigestsResponse{}
+	for _, bucket := range req.Buckets {
+		for key, digest := range tree.Digests(int(bucket)) {
+			resp.Digests = append(resp.Digests, &keyvalue.KeyDigest{Key: key, Digest: digest[:]})
+		}
+	}
+	return resp, nil
+}
+
+// Exchange merges the peer's versions and returns the resulting versions of the same keys
+func (s *AntiEntropyServer) Exchange(ctx context.Context, req *keyvalue.ExchangeRequest) (*keyvalue.ExchangeResponse, error) {
+	if len(req.Keys) > MaxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "exchange of %d keys is over the maximum of %d", len(req.Keys), MaxBatchSize)
+	}
+
+	resp := &keyvalue.ExchangeResponse{Keys: make([]*keyvalue.KeyVersions, len(req.Keys))}
+	for i, kv := range req.Keys {
+		versions := s.store.Put(kv.Key, vclockpb.FromProto(kv.Versions))
+		resp.Keys[i] = &keyvalue.KeyVersions{Key: kv.Key, Versions: vclockpb.ToProto(versions)}
+	}
+	return resp, nil
+}
+
+// AntiEntropyStatus reports the work of this node's repairer
+func (s *AntiEntropyServer) AntiEntropyStatus(ctx context.Context, req *keyvalue.AntiEntropyStatusRequest) (*keyvalue.AntiEntropyStatusResponse, error) {
+	if s.repairer == nil {
+		return &keyvalue.AntiEntropyStatusResponse{}, nil
+	}
+
+	stats := s.repairer.Stats()
+	resp := &keyvalue.AntiEntropyStatusResponse{
+		KeysRepaired: stats.KeysRepaired,
+		Rounds:       stats.Rounds,
+		LastError:    stats.LastError,
+		Peers:        stats.Peers,
+	}
+	if !stats.LastRepair.IsZero() {
+		resp.LastRepairMillis = stats.LastRepair.UnixMilli()
+	}
+	return resp, nil
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/antientropy"
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/shared/limits"
+	"key-value/shared/vclock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// serveAntiEntropy serves the versioned and anti-entropy services for store and returns the address
+func serveAntiEntropy(t *testing.T, store *kvstore.VersionedStore, repairer *antientropy.Repairer) string {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	grpcServer := grpc.NewServer()
+	keyvalue.RegisterVersionedServiceServer(grpcServer, NewVersionedServer(store, limits.Default()))
+	keyvalue.RegisterAntiEntropyServiceServer(grpcServer, NewAntiEntropyServer(store, antientropy.NewTrees(store), repairer))
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+	return lis.Addr().String()
+}
+
+func putVersion(store *kvstore.VersionedStore, key, value string, clock vclock.Clock) {
+	store.Put(key, []vclock.Version{{Value: value, Clock: clock}})
+}
+
+func TestAntiEntropy_Repair(t *testing.T) {
+	local, peerA, peerB := kvstore.NewVersionedStore(), kvstore.NewVersionedStore(), kvstore.NewVersionedStore()
+	addrA := serveAntiEntropy(t, peerA, nil)
+	addrB := serveAntiEntropy(t, peerB, nil)
+
+	// Shared keys
+	for i := range 200 {
+		for _, store := range []*kvstore.VersionedStore{local, peerA, peerB} {
+			putVersion(store, fmt.Sprintf("key-%d", i), "v", vclock.Clock{"c": 1})
+		}
+	}
+	// Drift: a newer write only local has, one only peer A has, concurrent writes and a tombstone
+	putVersion(local, "key-1", "newer", vclock.Clock{"c": 2})
+	putVersion(peerA, "only-a", "a", vclock.Clock{"c": 1})
+	putVersion(local, "key-2", "mine", vclock.Clock{"c": 1, "x": 1})
+	putVersion(peerB, "key-2", "theirs", vclock.Clock{"c": 1, "y": 1})
+	peerB.Put("key-3", []vclock.Version{{Deleted: true, Clock: vclock.Clock{"c": 2}}})
+
+	repairer, err := antientropy.NewRepairer(local, antientropy.NewTrees(local), []string{addrA, addrB},
+		antientropy.WithInterval(time.Hour), antientropy.WithDepth(6))
+	require.NoError(t, err)
+	t.Cleanup(func() { repairer.Close() })
+
+	repaired, err := repairer.Repair(context.Background())
+	require.NoError(t, err)
+	// Peer A: key-1, only-a and key-2. Peer B: key-1, key-2, key-3 and only-a, learned from peer A.
+	assert.Equal(t, 7, repaired)
+
+	// Peer A was repaired before local learned key-2 and key-3 from peer B
+	repaired, err = repairer.Repair(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, repaired)
+
+	repaired, err = repairer.Repair(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, repaired)
+
+	for _, store := range []*kvstore.VersionedStore{local, peerA, peerB} {
+		assert.Equal(t, "newer", store.Get("key-1")[0].Value)
+		assert.Equal(t, "a", store.Get("only-a")[0].Value)
+		assert.Len(t, store.Get("key-2"), 2)
+		assert.True(t, store.Get("key-3")[0].Deleted)
+	}
+
+	stats := repairer.Stats()
+	assert.Equal(t, uint64(9), stats.KeysRepaired)
+	assert.Equal(t, uint64(3), stats.Rounds)
+	assert.False(t, stats.LastRepair.IsZero())
+	assert.Empty(t, stats.LastError)
+}
+
+func TestAntiEntropy_Status(t *testing.T) {
+	store := kvstore.NewVersionedStore()
+	repairer, err := antientropy.NewRepairer(store, antientropy.NewTrees(store), []string{"127.0.0.1:1"},
+		antientropy.WithInterval(time.Hour))
+	require.NoError(t, err)
+	t.Cleanup(func() { repairer.Close() })
+
+	addr := serveAntiEntropy(t, store, repairer)
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	client := keyvalue.NewAntiEntropyServiceClient(conn)
+
+	// The only peer is unreachable
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = repairer.Repair(ctx)
+	require.Error(t, err)
+
+	resp, err := client.AntiEntropyStatus(context.Background(), &keyvalue.AntiEntropyStatusRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), resp.Rounds)
+	assert.Zero(t, resp.LastRepairMillis)
+	assert.NotEmpty(t, resp.LastError)
+	assert.Equal(t, []string{"127.0.0.1:1"}, resp.Peers)
+
+	_, err = client.MerkleHashes(context.Background(), &keyvalue.MerkleHashesRequest{Depth: antientropy.MaxDepth + 1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
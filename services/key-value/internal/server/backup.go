@@ -0,0 +1,202 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/backup"
+	"key-value/services/key-value/internal/kvstore"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// backupChunkBytes bounds the part of a backup file carried by one message
+const backupChunkBytes = 1 << 20
+
+// BackupServer implements the gRPC BackupService with the store, limits and forwarding of a KeyValueServer
+type BackupServer struct {
+	keyvalue.UnimplementedBackupServiceServer
+	kv *KeyValueServer
+}
+
+// Backups returns the backup service of the key-value server. The store must implement kvstore.Snapshotter.
+func (s *KeyValueServer) Backups() *BackupServer {
+	return &BackupServer{kv: s}
+}
+
+// Backup streams a backup file of a copy of the store taken at once, so writes carry on while it is sent
+func (s *BackupServer) Backup(req *keyvalue.BackupRequest, stream keyvalue.BackupService_BackupServer) error {
+	ctx := stream.Context()
+	data, err := kvstore.Snapshot(s.kv.store)
+	if conn, ok := s.kv.forwarder.target(ctx, err); ok {
+		return forwardBackup(ctx, keyvalue.NewBackupServiceClient(conn), stream)
+	}
+	if err != nil {
+		return toStatus(err, "")
+	}
+
+	out := &chunkWriter{send: func(chunk []byte) error {
+		return stream.Send(&keyvalue.BackupChunk{Data: chunk})
+	}}
+	if err := backup.Write(out, backup.Backup{Created: time.Now(), Data: kvstore.PublicData(data)}); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// forwardBackup relays the backup of the leader
+func forwardBackup(ctx context.Context, leader keyvalue.BackupServiceClient, stream keyvalue.BackupService_BackupServer) error {
+	upstream, err := leader.Backup(forwardContext(ctx), &keyvalue.BackupRequest{})
+	if err != nil {
+		return err
+	}
+	for {
+		chunk, err := upstream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(chunk); err != nil {
+			return err
+		}
+	}
+}
+
+// Restore loads a streamed backup file with the mode of the first request. Nothing is changed until the whole file
+// was received and checked.
+func (s *BackupServer) Restore(stream keyvalue.BackupService_RestoreServer) error {
+	ctx := stream.Context()
+	first, err := stream.Recv()
+	if err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	mode := first.GetMode()
+	if mode != keyvalue.RestoreMode_RESTORE_MODE_REPLACE && mode != keyvalue.RestoreMode_RESTORE_MODE_MERGE {
+		return status.Errorf(codes.InvalidArgument, "unknown restore mode %v", mode)
+	}
+
+	in := &restoreReader{stream: stream, data: first.GetData(), done: first == nil}
+	b, err := backup.Read(in)
+	if in.err != nil {
+		return in.err
+	}
+	if err != nil {
+		return toStatus(err, "")
+	}
+	for key, value := range b.Data {
+		if err := kvstore.CheckLimits(s.kv.limits, key, value); err != nil {
+			return toStatus(err, key)
+		}
+	}
+
+	err = s.restore(mode, b.Data)
+	if conn, ok := s.kv.forwarder.target(ctx, err); ok {
+		return forwardRestore(ctx, keyvalue.NewBackupServiceClient(conn), mode, b, stream)
+	}
+	if err != nil {
+		return toStatus(err, "")
+	}
+	return stream.SendAndClose(&keyvalue.RestoreResponse{Pairs: uint64(len(b.Data)), CreatedMillis: b.Created.UnixMilli()})
+}
+
+// restore replaces the data of the store or writes the pairs over it
+func (s *BackupServer) restore(mode keyvalue.RestoreMode, data map[string]string) error {
+	if mode == keyvalue.RestoreMode_RESTORE_MODE_MERGE {
+		for key, value := range data {
+			if err := s.kv.store.Set(key, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return kvstore.Restore(s.kv.store, data)
+}
+
+// forwardRestore sends the backup to the leader again, keeping its creation time
+func forwardRestore(ctx context.Context, leader keyvalue.BackupServiceClient, mode keyvalue.RestoreMode, b backup.Backup, stream keyvalue.BackupService_RestoreServer) error {
+	upstream, err := leader.Restore(forwardContext(ctx))
+	if err != nil {
+		return err
+	}
+	// A failed send ends the upstream, whose error CloseAndRecv reports
+	out := &chunkWriter{send: func(chunk []byte) error {
+		return upstream.Send(&keyvalue.RestoreRequest{Mode: mode, Data: chunk})
+	}}
+	if backup.Write(out, b) == nil {
+		out.Flush()
+	}
+	resp, err := upstream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(resp)
+}
+
+// chunkWriter collects what is written and sends it in chunks of backupChunkBytes
+type chunkWriter struct {
+	send func(chunk []byte) error
+	buf  []byte
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		if w.buf == nil {
+			w.buf = make([]byte, 0, backupChunkBytes)
+		}
+		taken := min(backupChunkBytes-len(w.buf), len(p))
+		w.buf = append(w.buf, p[:taken]...)
+		p = p[taken:]
+		if len(w.buf) == backupChunkBytes {
+			if err := w.Flush(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+// Flush sends what was written since the last chunk
+func (w *chunkWriter) Flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	chunk := w.buf
+	w.buf = nil
+	return w.send(chunk)
+}
+
+// restoreReader reads the backup file carried by a Restore stream
+type restoreReader struct {
+	stream keyvalue.BackupService_RestoreServer
+	data   []byte
+	done   bool
+	// err is the failure of the stream, which is not a problem of the file
+	err error
+}
+
+func (r *restoreReader) Read(p []byte) (int, error) {
+	for len(r.data) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		req, err := r.stream.Recv()
+		if errors.Is(err, io.EOF) {
+			r.done = true
+			continue
+		}
+		if err != nil {
+			r.err = err
+			return 0, err
+		}
+		r.data = req.Data
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/backup"
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/shared/limits"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// serveBackups serves the backup service over store
+func serveBackups(t *testing.T, store kvstore.Storer) keyvalue.BackupServiceClient {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := grpc.NewServer()
+	keyvalue.RegisterBackupServiceServer(grpcServer, NewKeyValueServer(store, WithLimits(limits.Limits{MaxKeyLength: 16})).Backups())
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return keyvalue.NewBackupServiceClient(conn)
+}
+
+// takeBackup reads a whole backup file
+func takeBackup(t *testing.T, client keyvalue.BackupServiceClient) []byte {
+	t.Helper()
+	stream, err := client.Backup(context.Background(), &keyvalue.BackupRequest{})
+	require.NoError(t, err)
+	var file bytes.Buffer
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return file.Bytes()
+		}
+		require.NoError(t, err)
+		file.Write(chunk.Data)
+	}
+}
+
+// restoreBackup streams file in chunks of 3 bytes
+func restoreBackup(t *testing.T, client keyvalue.BackupServiceClient, mode keyvalue.RestoreMode, file []byte) (*keyvalue.RestoreResponse, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := client.Restore(ctx)
+	require.NoError(t, err)
+	for i := 0; i < len(file); i += 3 {
+		if err := stream.Send(&keyvalue.RestoreRequest{Mode: mode, Data: file[i:min(i+3, len(file))]}); err != nil {
+			break
+		}
+	}
+	return stream.CloseAndRecv()
+}
+
+// backupOf encodes a backup of data
+func backupOf(t *testing.T, data map[string]string) []byte {
+	t.Helper()
+	var file bytes.Buffer
+	require.NoError(t, backup.Write(&file, backup.Backup{Created: time.UnixMilli(1700000000000), Data: data}))
+	return file.Bytes()
+}
+
+func TestBackupServer_Backup(t *testing.T) {
+	store := kvstore.NewInMemoryStore()
+	store.Set("a", "1")
+	store.Set("b", string(make([]byte, 3*backupChunkBytes)))
+
+	b, err := backup.Read(bytes.NewReader(takeBackup(t, serveBackups(t, store))))
+	require.NoError(t, err)
+	data, err := store.Snapshot()
+	require.NoError(t, err)
+	assert.Equal(t, data, b.Data)
+	assert.WithinDuration(t, time.Now(), b.Created, time.Minute)
+}
+
+func TestBackupServer_Restore(t *testing.T) {
+	file := backupOf(t, map[string]string{"a": "new", "b": "2"})
+	tests := []struct {
+		name string
+		mode keyvalue.RestoreMode
+		file []byte
+		code codes.Code
+		want map[string]string
+	}{
+		{
+			name: "replace",
+			mode: keyvalue.RestoreMode_RESTORE_MODE_REPLACE,
+			file: file,
+			want: map[string]string{"a": "new", "b": "2"},
+		},
+		{
+			name: "merge",
+			mode: keyvalue.RestoreMode_RESTORE_MODE_MERGE,
+			file: file,
+			want: map[string]string{"a": "new", "b": "2", "other": "x"},
+		},
+		{
+			name: "damaged",
+			mode: keyvalue.RestoreMode_RESTORE_MODE_REPLACE,
+			file: file[:len(file)-1],
+			code: codes.InvalidArgument,
+			want: map[string]string{"a": "old", "other": "x"},
+		},
+		{
+			name: "empty",
+			mode: keyvalue.RestoreMode_RESTORE_MODE_REPLACE,
+			code: codes.InvalidArgument,
+			want: map[string]string{"a": "old", "other": "x"},
+		},
+		{
+			name: "invalid key",
+			mode: keyvalue.RestoreMode_RESTORE_MODE_MERGE,
+			file: backupOf(t, map[string]string{"b": "2", "a-key-that-is-far-too-long": "x"}),
+			code: codes.InvalidArgument,
+			want: map[string]string{"a": "old", "other": "x"},
+		},
+		{
+			name: "unknown mode",
+			mode: keyvalue.RestoreMode(42),
+			file: file,
+			code: codes.InvalidArgument,
+			want: map[string]string{"a": "old", "other": "x"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := kvstore.NewInMemoryStore()
+			store.Set("a", "old")
+			store.Set("other", "x")
+
+			resp, err := restoreBackup(t, serveBackups(t, store), tt.mode, tt.file)
+			if tt.code != codes.OK {
+				assert.Equal(t, tt.code, status.Code(err))
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, uint64(2), resp.Pairs)
+				assert.Equal(t, int64(1700000000000), resp.CreatedMillis)
+			}
+			data, err := store.Snapshot()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, data)
+		})
+	}
+}
+
+func TestBackupServer_Unsupported(t *testing.T) {
+	for _, store := range []kvstore.Storer{&MockStorer{}, kvstore.Layer{Next: &MockStorer{}}} {
+		client := serveBackups(t, store)
+
+		stream, err := client.Backup(context.Background(), &keyvalue.BackupRequest{})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+		assert.Equal(t, codes.Unimplemented, status.Code(err), "%T", store)
+
+		_, err = restoreBackup(t, client, keyvalue.RestoreMode_RESTORE_MODE_REPLACE, backupOf(t, map[string]string{"a": "1"}))
+		assert.Equal(t, codes.Unimplemented, status.Code(err), "%T", store)
+	}
+}
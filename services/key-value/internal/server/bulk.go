@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/kvstore"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// BulkServer implements the gRPC BulkService with the store, limits and forwarding of a KeyValueServer
+type BulkServer struct {
+	keyvalue.UnimplementedBulkServiceServer
+	kv *KeyValueServer
+}
+
+// Bulk returns the bulk service of the key-value server
+func (s *KeyValueServer) Bulk() *BulkServer {
+	return &BulkServer{kv: s}
+}
+
+// Export streams the pairs with a prefix in key order, reading the store a page at a time so neither side holds the
+// whole data. Writes made during the export may or may not be included.
+func (s *BulkServer) Export(req *keyvalue.ExportRequest, stream keyvalue.BulkService_ExportServer) error {
+	ctx := stream.Context()
+	startAfter := req.StartAfter
+	for {
+		pairs, more, err := kvstore.ScanPublic(s.kv.store, req.Prefix, startAfter, MaxScanLimit)
+		if conn, ok := s.kv.forwarder.target(ctx, err); ok {
+			return forwardExport(ctx, keyvalue.NewBulkServiceClient(conn), &keyvalue.ExportRequest{Prefix: req.Prefix, StartAfter: startAfter}, stream)
+		}
+		if err != nil {
+			return toStatus(err, "")
+		}
+		for _, pair := range pairs {
+			if err := stream.Send(&keyvalue.KeyValuePair{Key: pair.Key, Value: pair.Value}); err != nil {
+				return err
+			}
+		}
+		if !more || len(pairs) == 0 {
+			return nil
+		}
+		startAfter = pairs[len(pairs)-1].Key
+	}
+}
+
+// forwardExport relays the export of the leader, continuing from where the local one stopped
+func forwardExport(ctx context.Context, leader keyvalue.BulkServiceClient, req *keyvalue.ExportRequest, stream keyvalue.BulkService_ExportServer) error {
+	upstream, err := leader.Export(forwardContext(ctx), req)
+	if err != nil {
+		return err
+	}
+	for {
+		pair, err := upstream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(pair); err != nil {
+			return err
+		}
+	}
+}
+
+// Import stores the streamed pairs in order with the mode of the first request
+func (s *BulkServer) Import(stream keyvalue.BulkService_ImportServer) error {
+	ctx := stream.Context()
+	resp := &keyvalue.ImportResponse{}
+	var mode keyvalue.ImportMode
+	for first := true; ; first = false {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.SendAndClose(resp)
+		}
+		if err != nil {
+			return err
+		}
+		if first {
+			mode = req.Mode
+			if err := checkImportMode(mode); err != nil {
+				return err
+			}
+		}
+
+		for i, item := range req.Items {
+			if err := kvstore.CheckLimits(s.kv.limits, item.Key, item.Value); err != nil {
+				return toStatus(err, item.Key)
+			}
+			written, err := s.importPair(mode, item)
+			if conn, ok := s.kv.forwarder.target(ctx, err); ok {
+				rest := &keyvalue.ImportRequest{Mode: mode, Items: req.Items[i:]}
+				return forwardImport(ctx, keyvalue.NewBulkServiceClient(conn), rest, resp, stream)
+			}
+			if err != nil {
+				return toStatus(err, item.Key)
+			}
+			if written {
+				resp.Written++
+			} else {
+				resp.Skipped++
+			}
+		}
+	}
+}
+
+// checkImportMode rejects unknown import modes
+func checkImportMode(mode keyvalue.ImportMode) error {
+	switch mode {
+	case keyvalue.ImportMode_IMPORT_MODE_OVERWRITE, keyvalue.ImportMode_IMPORT_MODE_SKIP_EXISTING,
+		keyvalue.ImportMode_IMPORT_MODE_FAIL_ON_CONFLICT:
+		return nil
+	}
+	return status.Errorf(codes.InvalidArgument, "unknown import mode %v", mode)
+}
+
+// importPair stores a pair unless the mode keeps the existing value, reporting whether it was written
+func (s *BulkServer) importPair(mode keyvalue.ImportMode, item *keyvalue.KeyValuePair) (bool, error) {
+	if mode == keyvalue.ImportMode_IMPORT_MODE_OVERWRITE {
+		return true, s.kv.store.Set(item.Key, item.Value)
+	}
+	for {
+		err := kvstore.SetIfAbsent(s.kv.store, item.Key, item.Value)
+		if !errors.Is(err, kvstore.ErrConflict) {
+			return err == nil, err
+		}
+		if mode == keyvalue.ImportMode_IMPORT_MODE_SKIP_EXISTING {
+			return false, nil
+		}
+
+		current, err := s.kv.store.Get(item.Key)
+		if errors.Is(err, kvstore.ErrNotFound) {
+			// Deleted in the meantime
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if current != item.Value {
+			return false, fmt.Errorf("key %s already holds a different value: %w", item.Key, kvstore.ErrConflict)
+		}
+		return false, nil
+	}
+}
+
+// forwardImport relays the rest of an import to the leader and adds its counts to the local ones
+func forwardImport(ctx context.Context, leader keyvalue.BulkServiceClient, rest *keyvalue.ImportRequest, resp *keyvalue.ImportResponse, stream keyvalue.BulkService_ImportServer) error {
+	upstream, err := leader.Import(forwardContext(ctx))
+	if err != nil {
+		return err
+	}
+	for req := rest; ; {
+		// A failed send ends the upstream, whose error CloseAndRecv reports
+		if err := upstream.Send(req); err != nil {
+			break
+		}
+		next, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		req = next
+	}
+	leaderResp, err := upstream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	resp.Written += leaderResp.Written
+	resp.Skipped += leaderResp.Skipped
+	return stream.SendAndClose(resp)
+}
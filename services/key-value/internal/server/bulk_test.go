@@ -0,0 +1,209 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/services/key-value/internal/lease"
+	"key-value/services/key-value/internal/meta"
+	"key-value/services/key-value/internal/replication"
+	"key-value/shared/limits"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// serveBulk serves the bulk service over store
+func serveBulk(t *testing.T, store kvstore.Storer) keyvalue.BulkServiceClient {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := grpc.NewServer()
+	keyvalue.RegisterBulkServiceServer(grpcServer, NewKeyValueServer(store, WithLimits(limits.Limits{MaxKeyLength: 16})).Bulk())
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return keyvalue.NewBulkServiceClient(conn)
+}
+
+// export reads a whole export
+func export(t *testing.T, bulk keyvalue.BulkServiceClient, req *keyvalue.ExportRequest) []string {
+	t.Helper()
+	stream, err := bulk.Export(context.Background(), req)
+	require.NoError(t, err)
+	var keys []string
+	for {
+		pair, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return keys
+		}
+		require.NoError(t, err)
+		keys = append(keys, pair.Key)
+	}
+}
+
+// importPairs streams key, value, key, value... in requests of two pairs
+func importPairs(t *testing.T, bulk keyvalue.BulkServiceClient, mode keyvalue.ImportMode, pairs ...string) (*keyvalue.ImportResponse, error) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	stream, err := bulk.Import(ctx)
+	require.NoError(t, err)
+	for i := 0; i < len(pairs); i += 4 {
+		req := &keyvalue.ImportRequest{Mode: mode}
+		for j := i; j < min(i+4, len(pairs)); j += 2 {
+			req.Items = append(req.Items, &keyvalue.KeyValuePair{Key: pairs[j], Value: pairs[j+1]})
+		}
+		if err := stream.Send(req); err != nil {
+			break
+		}
+	}
+	return stream.CloseAndRecv()
+}
+
+// countingStore counts the scans of an in-memory store
+type countingStore struct {
+	*kvstore.InMemoryStore
+	scans atomic.Int32
+}
+
+func (s *countingStore) Scan(prefix string, startAfter string, limit int) ([]kvstore.KeyValue, bool, error) {
+	s.scans.Add(1)
+	return s.InMemoryStore.Scan(prefix, startAfter, limit)
+}
+
+func TestBulkServer_Export(t *testing.T) {
+	store := &countingStore{InMemoryStore: kvstore.NewInMemoryStore()}
+	for i := 0; i < MaxScanLimit+5; i++ {
+		store.Set(fmt.Sprintf("user:%05d", i), "v")
+	}
+	store.Set("other", "v")
+	bulk := serveBulk(t, store)
+
+	keys := export(t, bulk, &keyvalue.ExportRequest{Prefix: "user:"})
+	require.Len(t, keys, MaxScanLimit+5, "exports are not bounded by the scan limit")
+	assert.Equal(t, "user:00000", keys[0])
+	assert.Equal(t, fmt.Sprintf("user:%05d", MaxScanLimit+4), keys[len(keys)-1])
+	assert.IsIncreasing(t, keys)
+	assert.Equal(t, int32(2), store.scans.Load(), "exports read a page at a time")
+
+	keys = export(t, bulk, &keyvalue.ExportRequest{Prefix: "user:", StartAfter: fmt.Sprintf("user:%05d", MaxScanLimit+2)})
+	assert.Equal(t, []string{fmt.Sprintf("user:%05d", MaxScanLimit+3), fmt.Sprintf("user:%05d", MaxScanLimit+4)}, keys)
+	assert.Empty(t, export(t, bulk, &keyvalue.ExportRequest{Prefix: "none"}))
+}
+
+func TestBulkServer_ExportReplicationPrimary(t *testing.T) {
+	// The stores wrapping the primary in the service
+	leases := lease.NewStore(meta.NewStore(replication.NewPrimary(kvstore.NewInMemoryStore(), 0)))
+	defer leases.Close()
+	require.NoError(t, leases.Set("b", "2"))
+	require.NoError(t, leases.Set("a", "1"))
+	bulk := serveBulk(t, leases)
+
+	assert.Equal(t, []string{"a", "b"}, export(t, bulk, &keyvalue.ExportRequest{}))
+}
+
+func TestBulkServer_Import(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    keyvalue.ImportMode
+		pairs   []string
+		written uint64
+		skipped uint64
+		code    codes.Code
+		want    map[string]string
+	}{
+		{
+			name:    "overwrite",
+			mode:    keyvalue.ImportMode_IMPORT_MODE_OVERWRITE,
+			pairs:   []string{"a", "new", "b", "2", "c", "3"},
+			written: 3,
+			want:    map[string]string{"a": "new", "b": "2", "c": "3", "same": "s"},
+		},
+		{
+			name:    "skip existing",
+			mode:    keyvalue.ImportMode_IMPORT_MODE_SKIP_EXISTING,
+			pairs:   []string{"a", "new", "b", "2", "same", "other"},
+			written: 1,
+			skipped: 2,
+			want:    map[string]string{"a": "old", "b": "2", "same": "s"},
+		},
+		{
+			name:    "fail on conflict skips equal values",
+			mode:    keyvalue.ImportMode_IMPORT_MODE_FAIL_ON_CONFLICT,
+			pairs:   []string{"same", "s", "b", "2"},
+			written: 1,
+			skipped: 1,
+			want:    map[string]string{"a": "old", "b": "2", "same": "s"},
+		},
+		{
+			name:  "fail on conflict",
+			mode:  keyvalue.ImportMode_IMPORT_MODE_FAIL_ON_CONFLICT,
+			pairs: []string{"b", "2", "a", "new", "c", "3"},
+			code:  codes.Aborted,
+			want:  map[string]string{"a": "old", "b": "2", "same": "s"},
+		},
+		{
+			name:  "invalid key",
+			mode:  keyvalue.ImportMode_IMPORT_MODE_OVERWRITE,
+			pairs: []string{"b", "2", "a-key-that-is-far-too-long", "x"},
+			code:  codes.InvalidArgument,
+			want:  map[string]string{"a": "old", "b": "2", "same": "s"},
+		},
+		{
+			name:  "unknown mode",
+			mode:  keyvalue.ImportMode(42),
+			pairs: []string{"b", "2"},
+			code:  codes.InvalidArgument,
+			want:  map[string]string{"a": "old", "same": "s"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := kvstore.NewInMemoryStore()
+			store.Set("a", "old")
+			store.Set("same", "s")
+
+			resp, err := importPairs(t, serveBulk(t, store), tt.mode, tt.pairs...)
+			if tt.code != codes.OK {
+				assert.Equal(t, tt.code, status.Code(err))
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.written, resp.Written)
+				assert.Equal(t, tt.skipped, resp.Skipped)
+			}
+			data, err := store.Snapshot()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, data)
+		})
+	}
+}
+
+func TestBulkServer_ImportUnsupported(t *testing.T) {
+	bulk := serveBulk(t, &MockStorer{})
+
+	_, err := importPairs(t, bulk, keyvalue.ImportMode_IMPORT_MODE_SKIP_EXISTING, "a", "1")
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+	_, err = importPairs(t, bulk, keyvalue.ImportMode_IMPORT_MODE_OVERWRITE, "a", "1")
+	assert.NoError(t, err)
+
+	stream, err := bulk.Export(context.Background(), &keyvalue.ExportRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/cdc"
+	"key-value/services/key-value/internal/kvstore"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ChangeServer implements the gRPC ChangeService over the captured changes of the store
+type ChangeServer struct {
+	keyvalue.UnimplementedChangeServiceServer
+	log   *cdc.Log
+	store kvstore.Storer // top of the store chain, which commits go through
+}
+
+// NewChangeServer creates a new gRPC change service. Offsets are committed through store, the top of the chain of
+// stores log sits in, so they are replicated like the data.
+func NewChangeServer(log *cdc.Log, store kvstore.Storer) *ChangeServer {
+	return &ChangeServer{log: log, store: store}
+}
+
+// Changes streams the captured changes in order until the client goes away
+func (s *ChangeServer) Changes(req *keyvalue.ChangesRequest, stream keyvalue.ChangeService_ChangesServer) error {
+	from := req.FromSeq
+	if from == 0 && req.Group != "" {
+		seq, ok, err := s.log.Offset(req.Group)
+		if err != nil {
+			return toStatus(err, "")
+		}
+		if ok {
+			from = seq + 1
+		}
+	}
+
+	for {
+		entries, notify, err := s.log.Changes(from)
+		if err != nil {
+			return toStatus(err, "")
+		}
+
+		for _, entry := range entries {
+			if err := stream.Send(toChange(entry)); err != nil {
+				return err
+			}
+			from = entry.Seq + 1
+		}
+		if len(entries) > 0 {
+			continue
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-notify:
+		}
+	}
+}
+
+// CommitOffset stores the position of a consumer group
+func (s *ChangeServer) CommitOffset(ctx context.Context, req *keyvalue.CommitOffsetRequest) (*keyvalue.CommitOffsetResponse, error) {
+	if req.Group == "" {
+		return nil, status.Error(codes.InvalidArgument, "group is required")
+	}
+	if err := s.log.Commit(s.store, req.Group, req.Seq); err != nil {
+		if errors.Is(err, cdc.ErrInvalidOffset) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+		return nil, toStatus(err, "")
+	}
+	return &keyvalue.CommitOffsetResponse{}, nil
+}
+
+// GetOffset returns the position of a consumer group and the retained window
+func (s *ChangeServer) GetOffset(ctx context.Context, req *keyvalue.GetOffsetRequest) (*keyvalue.GetOffsetResponse, error) {
+	first, latest := s.log.Bounds()
+	resp := &keyvalue.GetOffsetResponse{FirstSeq: first, LatestSeq: latest}
+	if req.Group != "" {
+		seq, ok, err := s.log.Offset(req.Group)
+		if err != nil {
+			return nil, toStatus(err, "")
+		}
+		resp.CommittedSeq, resp.Committed = seq, ok
+	}
+	return resp, nil
+}
+
+func toChange(entry cdc.Entry) *keyvalue.Change {
+	op := keyvalue.MutationOp_MUTATION_OP_SET
+	if entry.Op == cdc.OpDelete {
+		op = keyvalue.MutationOp_MUTATION_OP_DELETE
+	}
+	return &keyvalue.Change{
+		Seq:             entry.Seq,
+		Op:              op,
+		Key:             entry.Key,
+		Value:           entry.Value,
+		TimestampMillis: entry.Time.UnixMilli(),
+	}
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/cdc"
+	"key-value/services/key-value/internal/kvstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// serveChanges serves the key-value and change services over a new change log and returns clients for both
+func serveChanges(t *testing.T, opts ...cdc.Option) (keyvalue.KeyValueServiceClient, keyvalue.ChangeServiceClient) {
+	t.Helper()
+	log, err := cdc.NewLog(kvstore.NewInMemoryStore(), opts...)
+	require.NoError(t, err)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	grpcServer := grpc.NewServer()
+	keyvalue.RegisterKeyValueServiceServer(grpcServer, NewKeyValueServer(log))
+	keyvalue.RegisterChangeServiceServer(grpcServer, NewChangeServer(log, log))
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return keyvalue.NewKeyValueServiceClient(conn), keyvalue.NewChangeServiceClient(conn)
+}
+
+// receive reads n changes from the stream
+func receive(t *testing.T, stream keyvalue.ChangeService_ChangesClient, n int) []*keyvalue.Change {
+	t.Helper()
+	changes := make([]*keyvalue.Change, n)
+	for i := range changes {
+		change, err := stream.Recv()
+		require.NoError(t, err)
+		changes[i] = change
+	}
+	return changes
+}
+
+func TestChangeServer_StreamsInOrder(t *testing.T) {
+	kv, changes := serveChanges(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := kv.Set(ctx, &keyvalue.SetRequest{Key: "a", Value: "1"})
+	require.NoError(t, err)
+
+	stream, err := changes.Changes(ctx, &keyvalue.ChangesRequest{})
+	require.NoError(t, err)
+	first := receive(t, stream, 1)[0]
+	assert.Equal(t, uint64(1), first.Seq)
+	assert.Equal(t, keyvalue.MutationOp_MUTATION_OP_SET, first.Op)
+	assert.NotZero(t, first.TimestampMillis)
+
+	// Changes made while the stream is open follow in order
+	_, err = kv.Increment(ctx, &keyvalue.IncrementRequest{Key: "n", Delta: 2})
+	require.NoError(t, err)
+	_, err = kv.Delete(ctx, &keyvalue.DeleteRequest{Key: "a"})
+	require.NoError(t, err)
+
+	got := receive(t, stream, 2)
+	assert.Equal(t, uint64(2), got[0].Seq)
+	assert.Equal(t, keyvalue.MutationOp_MUTATION_OP_SET, got[0].Op)
+	assert.Equal(t, "n", got[0].Key)
+	assert.Equal(t, "2", got[0].Value)
+	assert.Equal(t, uint64(3), got[1].Seq)
+	assert.Equal(t, keyvalue.MutationOp_MUTATION_OP_DELETE, got[1].Op)
+	assert.Equal(t, "a", got[1].Key)
+}
+
+func TestChangeServer_GroupResumes(t *testing.T) {
+	kv, changes := serveChanges(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for i := range 5 {
+		_, err := kv.Set(ctx, &keyvalue.SetRequest{Key: fmt.Sprintf("k%d", i), Value: "v"})
+		require.NoError(t, err)
+	}
+
+	// The consumer processes three changes, commits, and goes away
+	streamCtx, stop := context.WithCancel(ctx)
+	stream, err := changes.Changes(streamCtx, &keyvalue.ChangesRequest{Group: "indexer"})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), receive(t, stream, 3)[2].Seq)
+	_, err = changes.CommitOffset(ctx, &keyvalue.CommitOffsetRequest{Group: "indexer", Seq: 3})
+	require.NoError(t, err)
+	stop()
+
+	stream, err = changes.Changes(ctx, &keyvalue.ChangesRequest{Group: "indexer"})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), receive(t, stream, 1)[0].Seq)
+
+	offset, err := changes.GetOffset(ctx, &keyvalue.GetOffsetRequest{Group: "indexer"})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), offset.CommittedSeq)
+	assert.True(t, offset.Committed)
+	assert.Equal(t, uint64(1), offset.FirstSeq)
+	assert.Equal(t, uint64(5), offset.LatestSeq)
+
+	_, err = changes.CommitOffset(ctx, &keyvalue.CommitOffsetRequest{Group: "indexer", Seq: 6})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = changes.CommitOffset(ctx, &keyvalue.CommitOffsetRequest{Seq: 1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestChangeServer_Expired(t *testing.T) {
+	kv, changes := serveChanges(t, cdc.WithRetention(2))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for range 4 {
+		_, err := kv.Set(ctx, &keyvalue.SetRequest{Key: "k", Value: "v"})
+		require.NoError(t, err)
+	}
+
+	stream, err := changes.Changes(ctx, &keyvalue.ChangesRequest{FromSeq: 1})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	st := status.Convert(err)
+	assert.Equal(t, codes.OutOfRange, st.Code())
+	require.Len(t, st.Details(), 1)
+	assert.Equal(t, ReasonExpired, st.Details()[0].(*errdetails.ErrorInfo).Reason)
+}
+
+func TestChangeServer_PastTheLog(t *testing.T) {
+	_, changes := serveChanges(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// An offset past the log fails rather than waiting for changes that were numbered before
+	stream, err := changes.Changes(ctx, &keyvalue.ChangesRequest{FromSeq: 10})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.OutOfRange, status.Code(err))
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/raftstore"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// ClusterNode defines the membership operations of a replicated store
+type ClusterNode interface {
+	AddMember(id string, raftAddr string, grpcAddr string) error
+	RemoveMember(id string) error
+	Members() ([]raftstore.Member, error)
+}
+
+// ClusterServer implements the gRPC ClusterService
+type ClusterServer struct {
+	keyvalue.UnimplementedClusterServiceServer
+	node      ClusterNode
+	forwarder *forwarder
+}
+
+// NewClusterServer creates a new gRPC cluster service. Membership changes reaching a follower
+// are forwarded to the leader.
+func NewClusterServer(node ClusterNode) *ClusterServer {
+	return &ClusterServer{
+		node:      node,
+		forwarder: newForwarder(),
+	}
+}
+
+// AddMember adds a voting member to the cluster
+func (s *ClusterServer) AddMember(ctx context.Context, req *keyvalue.AddMemberRequest) (*keyvalue.AddMemberResponse, error) {
+	if req.Id == "" || req.RaftAddr == "" || req.GrpcAddr == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "id, raft_addr and grpc_addr are required")
+	}
+
+	err := s.node.AddMember(req.Id, req.RaftAddr, req.GrpcAddr)
+	if conn, ok := s.forwarder.target(ctx, err); ok {
+		return keyvalue.NewClusterServiceClient(conn).AddMember(forwardContext(ctx), req)
+	}
+	if err != nil {
+		return nil, toStatus(err, "")
+	}
+
+	return &keyvalue.AddMemberResponse{}, nil
+}
+
+// RemoveMember removes a member from the cluster
+func (s *ClusterServer) RemoveMember(ctx context.Context, req *keyvalue.RemoveMemberRequest) (*keyvalue.RemoveMemberResponse, error) {
+	if req.Id == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "id is required")
+	}
+
+	err := s.node.RemoveMember(req.Id)
+	if conn, ok := s.forwarder.target(ctx, err); ok {
+		return keyvalue.NewClusterServiceClient(conn).RemoveMember(forwardContext(ctx), req)
+	}
+	if err != nil {
+		return nil, toStatus(err, "")
+	}
+
+	return &keyvalue.RemoveMemberResponse{}, nil
+}
+
+// ListMembers returns the current cluster configuration as seen by this node
+func (s *ClusterServer) ListMembers(ctx context.Context, req *keyvalue.ListMembersRequest) (*keyvalue.ListMembersResponse, error) {
+	members, err := s.node.Members()
+	if err != nil {
+		return nil, toStatus(err, "")
+	}
+
+	resp := &keyvalue.ListMembersResponse{}
+	for _, member := range members {
+		resp.Members = append(resp.Members, &keyvalue.Member{
+			Id:       member.ID,
+			RaftAddr: member.RaftAddr,
+			GrpcAddr: member.GRPCAddr,
+			Voter:    member.Voter,
+			Leader:   member.Leader,
+		})
+	}
+	return resp, nil
+}
+
+// Close releases the connections used for forwarding
+func (s *ClusterServer) Close() error {
+	return s.forwarder.Close()
+}
+
+// JoinCluster asks an existing member (any node, requests are forwarded to the leader) to add this node,
+// retrying until it succeeds or ctx is done
+func JoinCluster(ctx context.Context, seedAddr string, member *keyvalue.AddMemberRequest) error {
+	conn, err := grpc.NewClient(seedAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", seedAddr, err)
+	}
+	defer conn.Close()
+
+	client := keyvalue.NewClusterServiceClient(conn)
+	for {
+		callCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		_, err = client.AddMember(callCtx, member)
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to join cluster through %s: %w", seedAddr, err)
+		case <-time.After(time.Second):
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/services/key-value/internal/lease"
+	"key-value/services/key-value/internal/meta"
+	"key-value/services/key-value/internal/raftstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// clusterNode is a Raft node served over gRPC on loopback
+type clusterNode struct {
+	node   *raftstore.Node
+	addr   string
+	kv     keyvalue.KeyValueServiceClient
+	admin  keyvalue.ClusterServiceClient
+	bulk   keyvalue.BulkServiceClient
+	backup keyvalue.BackupServiceClient
+	leases keyvalue.LeaseServiceClient
+	server *grpc.Server
+}
+
+func startClusterNode(t *testing.T, id string, bootstrap bool, forwarding bool) *clusterNode {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	node, err := raftstore.NewNode(raftstore.Config{
+		NodeID:           id,
+		RaftAddr:         "127.0.0.1:0",
+		GRPCAddr:         lis.Addr().String(),
+		Bootstrap:        bootstrap,
+		HeartbeatTimeout: 100 * time.Millisecond,
+		ElectionTimeout:  100 * time.Millisecond,
+		LogOutput:        io.Discard,
+	}, kvstore.NewInMemoryStore())
+	require.NoError(t, err)
+
+	var opts []Option
+	if forwarding {
+		opts = append(opts, WithForwarding())
+	}
+	// The stores wrapping the node in the service
+	leaseStore := lease.NewStore(meta.NewStore(node))
+	kvServer := NewKeyValueServer(leaseStore, opts...)
+	clusterServer := NewClusterServer(node)
+
+	grpcServer := grpc.NewServer()
+	keyvalue.RegisterKeyValueServiceServer(grpcServer, kvServer)
+	keyvalue.RegisterClusterServiceServer(grpcServer, clusterServer)
+	keyvalue.RegisterBulkServiceServer(grpcServer, kvServer.Bulk())
+	keyvalue.RegisterBackupServiceServer(grpcServer, kvServer.Backups())
+	keyvalue.RegisterLeaseServiceServer(grpcServer, kvServer.Leases(leaseStore))
+	go grpcServer.Serve(lis)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		conn.Close()
+		grpcServer.Stop()
+		kvServer.Close()
+		clusterServer.Close()
+		leaseStore.Close()
+		node.Shutdown()
+	})
+
+	return &clusterNode{
+		node:   node,
+		addr:   lis.Addr().String(),
+		kv:     keyvalue.NewKeyValueServiceClient(conn),
+		admin:  keyvalue.NewClusterServiceClient(conn),
+		bulk:   keyvalue.NewBulkServiceClient(conn),
+		backup: keyvalue.NewBackupServiceClient(conn),
+		leases: keyvalue.NewLeaseServiceClient(conn),
+		server: grpcServer,
+	}
+}
+
+// startGRPCCluster bootstraps node 0 and joins the others through node 0's ClusterService
+func startGRPCCluster(t *testing.T, size int, forwarding bool) []*clusterNode {
+	t.Helper()
+	nodes := []*clusterNode{startClusterNode(t, "node-0", true, forwarding)}
+	require.Eventually(t, nodes[0].node.IsLeader, 10*time.Second, 10*time.Millisecond)
+
+	for i := 1; i < size; i++ {
+		node := startClusterNode(t, fmt.Sprintf("node-%d", i), false, forwarding)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err := JoinCluster(ctx, nodes[0].addr, &keyvalue.AddMemberRequest{
+			Id:       fmt.Sprintf("node-%d", i),
+			RaftAddr: node.node.RaftAddr(),
+			GrpcAddr: node.addr,
+		})
+		cancel()
+		require.NoError(t, err)
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// followerOf returns a node that is not the leader, once it knows the leader's address
+func followerOf(t *testing.T, nodes []*clusterNode) *clusterNode {
+	t.Helper()
+	for _, n := range nodes {
+		if !n.node.IsLeader() {
+			require.Eventually(t, func() bool {
+				_, addr := n.node.Leader()
+				return addr != ""
+			}, 5*time.Second, 10*time.Millisecond)
+			return n
+		}
+	}
+	t.Fatal("no follower found")
+	return nil
+}
+
+func TestCluster_FollowerForwardsToLeader(t *testing.T) {
+	nodes := startGRPCCluster(t, 3, true)
+	follower := followerOf(t, nodes)
+	ctx := context.Background()
+
+	_, err := follower.kv.Set(ctx, &keyvalue.SetRequest{Key: "key", Value: "value"})
+	require.NoError(t, err)
+
+	resp, err := follower.kv.Increment(ctx, &keyvalue.IncrementRequest{Key: "counter", Delta: 2})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), resp.Value)
+
+	// A linearizable read on a follower is forwarded and sees the write immediately
+	get, err := follower.kv.Get(ctx, &keyvalue.GetRequest{Key: "key", Consistency: keyvalue.ReadConsistency_READ_CONSISTENCY_LINEARIZABLE})
+	require.NoError(t, err)
+	assert.True(t, get.Found)
+	assert.Equal(t, "value", get.Value)
+
+	// A stale read is served locally and eventually converges
+	require.Eventually(t, func() bool {
+		get, err := follower.kv.Get(ctx, &keyvalue.GetRequest{Key: "counter", Consistency: keyvalue.ReadConsistency_READ_CONSISTENCY_STALE})
+		return err == nil && get.Found && get.Value == "2"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = follower.kv.Delete(ctx, &keyvalue.DeleteRequest{Key: "key"})
+	require.NoError(t, err)
+}
+
+func TestCluster_FollowerForwardsBulkImport(t *testing.T) {
+	nodes := startGRPCCluster(t, 3, true)
+	follower := followerOf(t, nodes)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stream, err := follower.bulk.Import(ctx)
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&keyvalue.ImportRequest{Items: []*keyvalue.KeyValuePair{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}}}))
+	require.NoError(t, stream.Send(&keyvalue.ImportRequest{Items: []*keyvalue.KeyValuePair{{Key: "c", Value: "3"}}}))
+	resp, err := stream.CloseAndRecv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), resp.Written)
+
+	get, err := follower.kv.Get(ctx, &keyvalue.GetRequest{Key: "c", Consistency: keyvalue.ReadConsistency_READ_CONSISTENCY_LINEARIZABLE})
+	require.NoError(t, err)
+	assert.Equal(t, "3", get.Value)
+}
+
+func TestCluster_FollowerForwardsBackupAndRestore(t *testing.T) {
+	nodes := startGRPCCluster(t, 3, true)
+	follower := followerOf(t, nodes)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := follower.kv.Set(ctx, &keyvalue.SetRequest{Key: "kept", Value: "1"})
+	require.NoError(t, err)
+	file := takeBackup(t, follower.backup)
+	_, err = follower.kv.Set(ctx, &keyvalue.SetRequest{Key: "later", Value: "2"})
+	require.NoError(t, err)
+
+	resp, err := restoreBackup(t, follower.backup, keyvalue.RestoreMode_RESTORE_MODE_REPLACE, file)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), resp.Pairs)
+	for _, n := range nodes {
+		require.Eventually(t, func() bool {
+			get, err := n.kv.Get(ctx, &keyvalue.GetRequest{Key: "later", Consistency: keyvalue.ReadConsistency_READ_CONSISTENCY_STALE})
+			return err == nil && !get.Found
+		}, 5*time.Second, 10*time.Millisecond, "the restore reaches every node")
+	}
+}
+
+func TestCluster_FollowerForwardsLeases(t *testing.T) {
+	nodes := startGRPCCluster(t, 3, true)
+	follower := followerOf(t, nodes)
+	ctx := context.Background()
+
+	granted, err := follower.leases.Grant(ctx, &keyvalue.LeaseGrantRequest{TtlMillis: 60000})
+	require.NoError(t, err)
+	acquired, err := follower.leases.Acquire(ctx, &keyvalue.AcquireRequest{Key: "lock", Value: "a", LeaseId: granted.Id})
+	require.NoError(t, err)
+	assert.True(t, acquired.Acquired)
+	_, err = follower.leases.KeepAlive(ctx, &keyvalue.LeaseKeepAliveRequest{Id: granted.Id})
+	require.NoError(t, err)
+
+	// The lease lives in the replicated store, so a new lease layer on the leader, like one after a failover, knows it
+	var leader *clusterNode
+	for _, n := range nodes {
+		if n.node.IsLeader() {
+			leader = n
+		}
+	}
+	require.NotNil(t, leader)
+	leases := lease.NewStore(leader.node)
+	defer leases.Close()
+	described, err := leases.TimeToLive(granted.Id)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lock"}, described.Keys)
+
+	require.NoError(t, leases.Revoke(granted.Id))
+	other, err := follower.leases.Grant(ctx, &keyvalue.LeaseGrantRequest{TtlMillis: 60000})
+	require.NoError(t, err)
+	assert.Greater(t, other.Id, granted.Id)
+	next, err := follower.leases.Acquire(ctx, &keyvalue.AcquireRequest{Key: "lock", Value: "b", LeaseId: other.Id})
+	require.NoError(t, err)
+	assert.True(t, next.Acquired)
+	assert.Greater(t, next.FencingToken, acquired.FencingToken)
+	_, err = follower.leases.Revoke(ctx, &keyvalue.LeaseRevokeRequest{Id: other.Id})
+	require.NoError(t, err)
+}
+
+func TestCluster_FollowerRedirectsWithoutForwarding(t *testing.T) {
+	nodes := startGRPCCluster(t, 3, false)
+	follower := followerOf(t, nodes)
+	_, leaderAddr := follower.node.Leader()
+
+	_, err := follower.kv.Set(context.Background(), &keyvalue.SetRequest{Key: "key", Value: "value"})
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+
+	require.Len(t, st.Details(), 1)
+	info := st.Details()[0].(*errdetails.ErrorInfo)
+	assert.Equal(t, ReasonNotLeader, info.Reason)
+	assert.Equal(t, leaderAddr, info.Metadata["leader"])
+}
+
+func TestCluster_ReadConsistencyPerRequest(t *testing.T) {
+	nodes := startGRPCCluster(t, 3, false)
+	follower := followerOf(t, nodes)
+	ctx := context.Background()
+	var leader *clusterNode
+	for _, n := range nodes {
+		if n.node.IsLeader() {
+			leader = n
+		}
+	}
+	require.NotNil(t, leader)
+
+	_, err := leader.kv.Set(ctx, &keyvalue.SetRequest{Key: "key", Value: "value"})
+	require.NoError(t, err)
+
+	// The nodes default to linearizable reads, but a stale read is served by the follower itself
+	require.Eventually(t, func() bool {
+		get, err := follower.kv.Get(ctx, &keyvalue.GetRequest{Key: "key", Consistency: keyvalue.ReadConsistency_READ_CONSISTENCY_STALE})
+		return err == nil && get.Found && get.Value == "value"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	for _, consistency := range []keyvalue.ReadConsistency{keyvalue.ReadConsistency_READ_CONSISTENCY_DEFAULT, keyvalue.ReadConsistency_READ_CONSISTENCY_LINEARIZABLE} {
+		_, err = follower.kv.Get(ctx, &keyvalue.GetRequest{Key: "key", Consistency: consistency})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err), consistency.String())
+	}
+}
+
+func TestCluster_MembershipThroughAnyNode(t *testing.T) {
+	nodes := startGRPCCluster(t, 3, true)
+	follower := followerOf(t, nodes)
+	ctx := context.Background()
+
+	resp, err := follower.admin.ListMembers(ctx, &keyvalue.ListMembersRequest{})
+	require.NoError(t, err)
+	assert.Len(t, resp.Members, 3)
+
+	// Removal requested on a follower is forwarded to the leader
+	var removeID string
+	for _, member := range resp.Members {
+		if !member.Leader && member.GrpcAddr != follower.addr {
+			removeID = member.Id
+		}
+	}
+	_, err = follower.admin.RemoveMember(ctx, &keyvalue.RemoveMemberRequest{Id: removeID})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		resp, err := follower.admin.ListMembers(ctx, &keyvalue.ListMembersRequest{})
+		return err == nil && len(resp.Members) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = follower.admin.AddMember(ctx, &keyvalue.AddMemberRequest{Id: "incomplete"})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+}
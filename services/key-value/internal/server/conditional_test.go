@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"testing"
+
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/kvstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestKeyValueServer_ConditionalWrites(t *testing.T) {
+	store := kvstore.NewInMemoryStore()
+	store.Set("existing", "v1")
+	server := NewKeyValueServer(store)
+	ctx := context.Background()
+	value := func(v string) *string { return &v }
+
+	tests := []struct {
+		name         string
+		call         func() error
+		expectedCode codes.Code
+	}{
+		{"set if absent on new key", func() error {
+			_, err := server.Set(ctx, &keyvalue.SetRequest{Key: "new", Value: "x", IfAbsent: true})
+			return err
+		}, codes.OK},
+		{"set if absent on existing key", func() error {
+			_, err := server.Set(ctx, &keyvalue.SetRequest{Key: "existing", Value: "x", IfAbsent: true})
+			return err
+		}, codes.Aborted},
+		{"delete if value differs", func() error {
+			_, err := server.Delete(ctx, &keyvalue.DeleteRequest{Key: "existing", IfValue: value("v2")})
+			return err
+		}, codes.Aborted},
+		{"delete if value on missing key", func() error {
+			_, err := server.Delete(ctx, &keyvalue.DeleteRequest{Key: "missing", IfValue: value("")})
+			return err
+		}, codes.Aborted},
+		{"delete if value matches", func() error {
+			_, err := server.Delete(ctx, &keyvalue.DeleteRequest{Key: "existing", IfValue: value("v1")})
+			return err
+		}, codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			assert.Equal(t, tt.expectedCode, status.Code(err))
+		})
+	}
+
+	_, err := store.Get("existing")
+	assert.ErrorIs(t, err, kvstore.ErrNotFound)
+	stored, err := store.Get("new")
+	require.NoError(t, err)
+	assert.Equal(t, "x", stored)
+}
+
+func TestKeyValueServer_ConditionalWritesUnsupported(t *testing.T) {
+	server := NewKeyValueServer(&MockStorer{})
+
+	_, err := server.Set(context.Background(), &keyvalue.SetRequest{Key: "k", IfAbsent: true})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
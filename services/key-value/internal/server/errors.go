@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"key-value/services/key-value/internal/backup"
+	"key-value/services/key-value/internal/cdc"
+	"key-value/services/key-value/internal/history"
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/services/key-value/internal/lease"
+	"key-value/services/key-value/internal/watch"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain identifies errors raised by this service in errdetails.ErrorInfo
+const ErrorDomain = "key-value"
+
+// Reasons reported in errdetails.ErrorInfo. Clients use them to translate a status back into a typed error.
+const (
+	ReasonNotFound    = "NOT_FOUND"
+	ReasonConflict    = "CONFLICT"
+	ReasonTooLarge    = "TOO_LARGE"
+	ReasonInvalidKey  = "INVALID_KEY"
+	ReasonNotNumeric  = "NOT_NUMERIC"
+	ReasonOverflow    = "OVERFLOW"
+	ReasonNotLeader   = "NOT_LEADER"
+	ReasonExpired     = "EXPIRED"
+	ReasonCompacted   = "COMPACTED"
+	ReasonFuture      = "FUTURE_REVISION"
+	ReasonNoLease     = "LEASE_NOT_FOUND"
+	ReasonWatchLost   = "WATCH_LOST"
+	ReasonBadBackup   = "INVALID_BACKUP"
+	ReasonUnsupported = "UNSUPPORTED"
+	ReasonInternal    = "INTERNAL"
+)
+
+// errorMapping ties a store sentinel error to its gRPC code and ErrorInfo reason
+type errorMapping struct {
+	err    error
+	code   codes.Code
+	reason string
+}
+
+var errorMappings = []errorMapping{
+	{kvstore.ErrNotFound, codes.NotFound, ReasonNotFound},
+	{kvstore.ErrConflict, codes.Aborted, ReasonConflict},
+	{kvstore.ErrTooLarge, codes.ResourceExhausted, ReasonTooLarge},
+	{kvstore.ErrInvalidKey, codes.InvalidArgument, ReasonInvalidKey},
+	{kvstore.ErrNotNumeric, codes.FailedPrecondition, ReasonNotNumeric},
+	{kvstore.ErrOverflow, codes.OutOfRange, ReasonOverflow},
+	{kvstore.ErrNotLeader, codes.FailedPrecondition, ReasonNotLeader},
+	{kvstore.ErrUnsupported, codes.Unimplemented, ReasonUnsupported},
+	{cdc.ErrExpired, codes.OutOfRange, ReasonExpired},
+	{history.ErrCompacted, codes.OutOfRange, ReasonCompacted},
+	{history.ErrFutureRevision, codes.OutOfRange, ReasonFuture},
+	{lease.ErrNotFound, codes.NotFound, ReasonNoLease},
+	{watch.ErrLost, codes.Aborted, ReasonWatchLost},
+	{backup.ErrInvalid, codes.InvalidArgument, ReasonBadBackup},
+}
+
+// toStatus converts a store error into a gRPC status error carrying an ErrorInfo detail with the key.
+// Errors pointing at another node (*kvstore.LeaderError) also carry its address under "leader".
+func toStatus(err error, key string) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	code, reason := codes.Internal, ReasonInternal
+	for _, m := range errorMappings {
+		if errors.Is(err, m.err) {
+			code, reason = m.code, m.reason
+			break
+		}
+	}
+
+	metadata := map[string]string{"key": key}
+	var leaderErr *kvstore.LeaderError
+	if errors.As(err, &leaderErr) {
+		metadata["leader"] = leaderErr.LeaderAddr
+	}
+
+	return newStatus(code, reason, metadata, err.Error())
+}
+
+// newStatus builds a status error with an ErrorInfo detail
+func newStatus(code codes.Code, reason string, metadata map[string]string, message string) error {
+	st := status.New(code, message)
+	info := &errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   ErrorDomain,
+		Metadata: metadata,
+	}
+	if withDetails, err := st.WithDetails(info); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"key-value/services/key-value/internal/kvstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedCode   codes.Code
+		expectedReason string
+	}{
+		{"not found", kvstore.ErrNotFound, codes.NotFound, ReasonNotFound},
+		{"wrapped conflict", fmt.Errorf("write failed: %w", kvstore.ErrConflict), codes.Aborted, ReasonConflict},
+		{"too large", kvstore.ErrTooLarge, codes.ResourceExhausted, ReasonTooLarge},
+		{"invalid key", kvstore.ErrInvalidKey, codes.InvalidArgument, ReasonInvalidKey},
+		{"not leader", &kvstore.LeaderError{LeaderAddr: "node-2:50051"}, codes.FailedPrecondition, ReasonNotLeader},
+		{"unknown error", errors.New("disk on fire"), codes.Internal, ReasonInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, ok := status.FromError(toStatus(tt.err, "some-key"))
+			require.True(t, ok)
+			assert.Equal(t, tt.expectedCode, st.Code())
+			assert.Equal(t, tt.err.Error(), st.Message())
+
+			require.Len(t, st.Details(), 1)
+			info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+			require.True(t, ok)
+			assert.Equal(t, tt.expectedReason, info.Reason)
+			assert.Equal(t, ErrorDomain, info.Domain)
+			assert.Equal(t, "some-key", info.Metadata["key"])
+		})
+	}
+}
+
+func TestToStatus_LeaderAddress(t *testing.T) {
+	st, _ := status.FromError(toStatus(&kvstore.LeaderError{LeaderAddr: "node-2:50051"}, "some-key"))
+	info := st.Details()[0].(*errdetails.ErrorInfo)
+	assert.Equal(t, "node-2:50051", info.Metadata["leader"])
+}
+
+func TestToStatus_Passthrough(t *testing.T) {
+	assert.NoError(t, toStatus(nil, "key"))
+
+	original := status.Error(codes.Unavailable, "try again")
+	assert.Equal(t, original, toStatus(original, "key"))
+
+	st, _ := status.FromError(toStatus(context.DeadlineExceeded, "key"))
+	assert.Equal(t, codes.DeadlineExceeded, st.Code())
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"key-value/services/key-value/internal/kvstore"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// forwardedHeader marks a request that was already forwarded once. A node receiving it
+// answers with its own error instead of forwarding again, so a stale leader hint cannot loop.
+const forwardedHeader = "x-kv-forwarded"
+
+// forwarder keeps one connection per leader address for forwarding requests
+type forwarder struct {
+	dialOptions []grpc.DialOption
+
+	mutex sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+func newForwarder(dialOptions ...grpc.DialOption) *forwarder {
+	if len(dialOptions) == 0 {
+		dialOptions = []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+	}
+	return &forwarder{
+		dialOptions: dialOptions,
+		conns:       make(map[string]*grpc.ClientConn),
+	}
+}
+
+// target returns the leader connection when err asks for the request to be served by another node
+func (f *forwarder) target(ctx context.Context, err error) (*grpc.ClientConn, bool) {
+	if f == nil || isForwarded(ctx) {
+		return nil, false
+	}
+	var leaderErr *kvstore.LeaderError
+	if !errors.As(err, &leaderErr) || leaderErr.LeaderAddr == "" {
+		return nil, false
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if conn, ok := f.conns[leaderErr.LeaderAddr]; ok {
+		return conn, true
+	}
+	conn, dialErr := grpc.NewClient(leaderErr.LeaderAddr, f.dialOptions...)
+	if dialErr != nil {
+		return nil, false
+	}
+	f.conns[leaderErr.LeaderAddr] = conn
+	return conn, true
+}
+
+// Close closes every leader connection
+func (f *forwarder) Close() error {
+	if f == nil {
+		return nil
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var err error
+	for addr, conn := range f.conns {
+		err = errors.Join(err, conn.Close())
+		delete(f.conns, addr)
+	}
+	return err
+}
+
+// isForwarded reports whether the incoming request was forwarded by another node
+func isForwarded(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	return ok && len(md.Get(forwardedHeader)) > 0
+}
+
+// forwardContext marks the outgoing request as forwarded, keeping the caller's deadline
+func forwardContext(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, forwardedHeader, "true")
+}
@@ -2,45 +2,82 @@ package server
 
 import (
 	"context"
+	"errors"
 	"key-value/services/key-value/internal/kvstore"
+	"key-value/shared/limits"
 	"time"
 
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"key-value/proto/keyvalue"
 )
 
+// Scan and batch sizes
+const (
+	DefaultScanLimit = 100  // Used when a ScanRequest has no limit
+	MaxScanLimit     = 1000 // Larger limits are lowered to this
+	MaxBatchSize     = 1000 // Batches with more keys are rejected
+)
+
 // KeyValueServer implements the gRPC KeyValueService
 type KeyValueServer struct {
 	keyvalue.UnimplementedKeyValueServiceServer
-	store kvstore.Storer
+	store     kvstore.Storer
+	limits    limits.Limits
+	forwarder *forwarder
+}
+
+// Option configures a KeyValueServer
+type Option func(*KeyValueServer)
+
+// WithLimits sets the key and value limits enforced before requests reach the store
+func WithLimits(l limits.Limits) Option {
+	return func(s *KeyValueServer) {
+		s.limits = l
+	}
 }
 
-// NewKeyValueServer creates a new gRPC server instance
-func NewKeyValueServer(store kvstore.Storer) *KeyValueServer {
-	return &KeyValueServer{
-		store: store,
+// WithForwarding makes the server forward requests that must be served by the leader
+// instead of rejecting them with the leader's address. The dial options default to plaintext.
+func WithForwarding(dialOptions ...grpc.DialOption) Option {
+	return func(s *KeyValueServer) {
+		s.forwarder = newForwarder(dialOptions...)
 	}
 }
 
+// NewKeyValueServer creates a new gRPC server instance enforcing limits.Default() unless WithLimits is given
+func NewKeyValueServer(store kvstore.Storer, opts ...Option) *KeyValueServer {
+	s := &KeyValueServer{
+		store:  store,
+		limits: limits.Default(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
 // Get retrieves a value by key
 func (s *KeyValueServer) Get(ctx context.Context, req *keyvalue.GetRequest) (*keyvalue.GetResponse, error) {
-	if req.Key == "" {
-		return nil, status.Errorf(codes.InvalidArgument, "key cannot be empty")
+	if err := kvstore.CheckKey(s.limits, req.Key); err != nil {
+		return nil, toStatus(err, req.Key)
 	}
 
-	value, err := s.store.Get(req.Key)
+	value, err := s.read(req.Key, req.Consistency)
+	if conn, ok := s.forwarder.target(ctx, err); ok {
+		return keyvalue.NewKeyValueServiceClient(conn).Get(forwardContext(ctx), req)
+	}
 	if err != nil {
-		// Check if it's a "key not found" error
-		if err.Error() == "key not found" {
+		// A missing key is a regular outcome for Get, reported through Found
+		if errors.Is(err, kvstore.ErrNotFound) {
 			return &keyvalue.GetResponse{
 				Value: "",
 				Found: false,
 				Error: "",
 			}, nil
 		}
-		// Other errors
-		return nil, status.Errorf(codes.Internal, "service failed to get value: %v", err)
+		return nil, toStatus(err, req.Key)
 	}
 
 	return &keyvalue.GetResponse{
@@ -50,18 +87,46 @@ func (s *KeyValueServer) Get(ctx context.Context, req *keyvalue.GetRequest) (*ke
 	}, nil
 }
 
+// read serves a Get with the requested consistency. Stores without read modes serve every Get the same way.
+func (s *KeyValueServer) read(key string, consistency keyvalue.ReadConsistency) (string, error) {
+	var value string
+	var err error
+	switch consistency {
+	case keyvalue.ReadConsistency_READ_CONSISTENCY_LINEARIZABLE:
+		value, err = kvstore.LinearizableGet(s.store, key)
+	case keyvalue.ReadConsistency_READ_CONSISTENCY_STALE:
+		value, err = kvstore.StaleGet(s.store, key)
+	default:
+		return s.store.Get(key)
+	}
+	if errors.Is(err, kvstore.ErrUnsupported) {
+		return s.store.Get(key)
+	}
+	return value, err
+}
+
 // Set stores a key-value pair
 func (s *KeyValueServer) Set(ctx context.Context, req *keyvalue.SetRequest) (*keyvalue.SetResponse, error) {
-	if req.Key == "" {
-		return nil, status.Errorf(codes.InvalidArgument, "key cannot be empty")
+	if err := kvstore.CheckLimits(s.limits, req.Key, req.Value); err != nil {
+		return nil, toStatus(err, req.Key)
 	}
 
-	err := s.store.Set(req.Key, req.Value)
+	var err error
+	switch {
+	case req.LeaseId != 0 && req.IfAbsent:
+		return nil, status.Errorf(codes.InvalidArgument, "if_absent cannot be combined with a lease, use LeaseService.Acquire")
+	case req.LeaseId != 0:
+		err = kvstore.SetWithLease(s.store, req.Key, req.Value, req.LeaseId)
+	case req.IfAbsent:
+		err = kvstore.SetIfAbsent(s.store, req.Key, req.Value)
+	default:
+		err = s.store.Set(req.Key, req.Value)
+	}
+	if conn, ok := s.forwarder.target(ctx, err); ok {
+		return keyvalue.NewKeyValueServiceClient(conn).Set(forwardContext(ctx), req)
+	}
 	if err != nil {
-		return &keyvalue.SetResponse{
-			Success: false,
-			Error:   err.Error(),
-		}, nil
+		return nil, toStatus(err, req.Key)
 	}
 
 	return &keyvalue.SetResponse{
@@ -72,16 +137,21 @@ func (s *KeyValueServer) Set(ctx context.Context, req *keyvalue.SetRequest) (*ke
 
 // Delete removes a key-value pair
 func (s *KeyValueServer) Delete(ctx context.Context, req *keyvalue.DeleteRequest) (*keyvalue.DeleteResponse, error) {
-	if req.Key == "" {
-		return nil, status.Errorf(codes.InvalidArgument, "key cannot be empty")
+	if err := kvstore.CheckKey(s.limits, req.Key); err != nil {
+		return nil, toStatus(err, req.Key)
 	}
 
-	err := s.store.Delete(req.Key)
+	var err error
+	if req.IfValue != nil {
+		err = kvstore.DeleteIfValue(s.store, req.Key, *req.IfValue)
+	} else {
+		err = s.store.Delete(req.Key)
+	}
+	if conn, ok := s.forwarder.target(ctx, err); ok {
+		return keyvalue.NewKeyValueServiceClient(conn).Delete(forwardContext(ctx), req)
+	}
 	if err != nil {
-		return &keyvalue.DeleteResponse{
-			Success: false,
-			Error:   err.Error(),
-		}, nil
+		return nil, toStatus(err, req.Key)
 	}
 
 	return &keyvalue.DeleteResponse{
@@ -90,6 +160,100 @@ func (s *KeyValueServer) Delete(ctx context.Context, req *keyvalue.DeleteRequest
 	}, nil
 }
 
+// Increment atomically adds delta to an integer value, creating the key at 0 if missing
+func (s *KeyValueServer) Increment(ctx context.Context, req *keyvalue.IncrementRequest) (*keyvalue.IncrementResponse, error) {
+	if err := kvstore.CheckKey(s.limits, req.Key); err != nil {
+		return nil, toStatus(err, req.Key)
+	}
+
+	value, err := s.store.Increment(req.Key, req.Delta)
+	if conn, ok := s.forwarder.target(ctx, err); ok {
+		return keyvalue.NewKeyValueServiceClient(conn).Increment(forwardContext(ctx), req)
+	}
+	if err != nil {
+		return nil, toStatus(err, req.Key)
+	}
+
+	return &keyvalue.IncrementResponse{
+		Value: value,
+	}, nil
+}
+
+// Scan lists key-value pairs in key order
+func (s *KeyValueServer) Scan(ctx context.Context, req *keyvalue.ScanRequest) (*keyvalue.ScanResponse, error) {
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = DefaultScanLimit
+	}
+	limit = min(limit, MaxScanLimit)
+
+	pairs, more, err := kvstore.ScanPublic(s.store, req.Prefix, req.StartAfter, limit)
+	if conn, ok := s.forwarder.target(ctx, err); ok {
+		return keyvalue.NewKeyValueServiceClient(conn).Scan(forwardContext(ctx), req)
+	}
+	if err != nil {
+		return nil, toStatus(err, "")
+	}
+
+	resp := &keyvalue.ScanResponse{More: more}
+	for _, pair := range pairs {
+		resp.Items = append(resp.Items, &keyvalue.KeyValuePair{Key: pair.Key, Value: pair.Value})
+	}
+	return resp, nil
+}
+
+// BatchGet retrieves several keys, leaving missing keys out of the response
+func (s *KeyValueServer) BatchGet(ctx context.Context, req *keyvalue.BatchGetRequest) (*keyvalue.BatchGetResponse, error) {
+	if len(req.Keys) > MaxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "batch has %d keys, the maximum is %d", len(req.Keys), MaxBatchSize)
+	}
+	for _, key := range req.Keys {
+		if err := kvstore.CheckKey(s.limits, key); err != nil {
+			return nil, toStatus(err, key)
+		}
+	}
+
+	resp := &keyvalue.BatchGetResponse{}
+	for _, key := range req.Keys {
+		value, err := s.store.Get(key)
+		if conn, ok := s.forwarder.target(ctx, err); ok {
+			return keyvalue.NewKeyValueServiceClient(conn).BatchGet(forwardContext(ctx), req)
+		}
+		if errors.Is(err, kvstore.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, toStatus(err, key)
+		}
+		resp.Items = append(resp.Items, &keyvalue.KeyValuePair{Key: key, Value: value})
+	}
+	return resp, nil
+}
+
+// BatchSet stores several key-value pairs in order. Every pair is validated before any is written,
+// but the writes are not atomic: on failure the pairs before the failing one stay written.
+func (s *KeyValueServer) BatchSet(ctx context.Context, req *keyvalue.BatchSetRequest) (*keyvalue.BatchSetResponse, error) {
+	if len(req.Items) > MaxBatchSize {
+		return nil, status.Errorf(codes.InvalidArgument, "batch has %d items, the maximum is %d", len(req.Items), MaxBatchSize)
+	}
+	for _, item := range req.Items {
+		if err := kvstore.CheckLimits(s.limits, item.Key, item.Value); err != nil {
+			return nil, toStatus(err, item.Key)
+		}
+	}
+
+	for _, item := range req.Items {
+		err := s.store.Set(item.Key, item.Value)
+		if conn, ok := s.forwarder.target(ctx, err); ok {
+			return keyvalue.NewKeyValueServiceClient(conn).BatchSet(forwardContext(ctx), req)
+		}
+		if err != nil {
+			return nil, toStatus(err, item.Key)
+		}
+	}
+	return &keyvalue.BatchSetResponse{}, nil
+}
+
 // Health provides a health check endpoint
 func (s *KeyValueServer) Health(ctx context.Context, req *keyvalue.HealthRequest) (*keyvalue.HealthResponse, error) {
 	return &keyvalue.HealthResponse{
@@ -97,3 +261,8 @@ func (s *KeyValueServer) Health(ctx context.Context, req *keyvalue.HealthRequest
 		Timestamp: time.Now().Unix(),
 	}, nil
 }
+
+// Close releases the connections used for forwarding
+func (s *KeyValueServer) Close() error {
+	return s.forwarder.Close()
+}
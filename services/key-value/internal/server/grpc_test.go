@@ -3,9 +3,13 @@ package server
 import (
 	"context"
 	"errors"
+	"regexp"
+	"strings"
 	"testing"
 
 	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/shared/limits"
 
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc/codes"
@@ -14,9 +18,10 @@ import (
 
 // MockStorer implements kvstore.Storer for testing
 type MockStorer struct {
-	GetFunc    func(key string) (string, error)
-	SetFunc    func(key, value string) error
-	DeleteFunc func(key string) error
+	GetFunc       func(key string) (string, error)
+	SetFunc       func(key, value string) error
+	DeleteFunc    func(key string) error
+	IncrementFunc func(key string, delta int64) (int64, error)
 }
 
 func (m *MockStorer) Get(key string) (string, error) {
@@ -40,6 +45,13 @@ func (m *MockStorer) Delete(key string) error {
 	return nil
 }
 
+func (m *MockStorer) Increment(key string, delta int64) (int64, error) {
+	if m.IncrementFunc != nil {
+		return m.IncrementFunc(key, delta)
+	}
+	return delta, nil
+}
+
 func TestKeyValueServer_Get(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -66,7 +78,7 @@ func TestKeyValueServer_Get(t *testing.T) {
 			request: &keyvalue.GetRequest{Key: "missing-key"},
 			setupMock: func(m *MockStorer) {
 				m.GetFunc = func(key string) (string, error) {
-					return "", errors.New("key not found")
+					return "", kvstore.ErrNotFound
 				}
 			},
 			expectedValue: "",
@@ -148,8 +160,17 @@ func TestKeyValueServer_Set(t *testing.T) {
 					return errors.New("storage failed")
 				}
 			},
-			expectedSuccess: false,
-			expectedError:   "storage failed",
+			expectGRPCCode: codes.Internal,
+		},
+		{
+			name:    "value too large",
+			request: &keyvalue.SetRequest{Key: "test-key", Value: "test-value"},
+			setupMock: func(m *MockStorer) {
+				m.SetFunc = func(key, value string) error {
+					return kvstore.ErrTooLarge
+				}
+			},
+			expectGRPCCode: codes.ResourceExhausted,
 		},
 		{
 			name:    "empty value allowed",
@@ -223,8 +244,17 @@ func TestKeyValueServer_Delete(t *testing.T) {
 					return errors.New("delete failed")
 				}
 			},
-			expectedSuccess: false,
-			expectedError:   "delete failed",
+			expectGRPCCode: codes.Internal,
+		},
+		{
+			name:    "conflict",
+			request: &keyvalue.DeleteRequest{Key: "test-key"},
+			setupMock: func(m *MockStorer) {
+				m.DeleteFunc = func(key string) error {
+					return kvstore.ErrConflict
+				}
+			},
+			expectGRPCCode: codes.Aborted,
 		},
 	}
 
@@ -254,3 +284,144 @@ func TestKeyValueServer_Delete(t *testing.T) {
 		})
 	}
 }
+
+func TestKeyValueServer_Increment(t *testing.T) {
+	tests := []struct {
+		name           string
+		request        *keyvalue.IncrementRequest
+		setupMock      func(*MockStorer)
+		expectedValue  int64
+		expectGRPCCode codes.Code
+	}{
+		{
+			name:    "successful increment",
+			request: &keyvalue.IncrementRequest{Key: "counter", Delta: 3},
+			setupMock: func(m *MockStorer) {
+				m.IncrementFunc = func(key string, delta int64) (int64, error) {
+					return 10 + delta, nil
+				}
+			},
+			expectedValue: 13,
+		},
+		{
+			name:           "empty key",
+			request:        &keyvalue.IncrementRequest{Key: "", Delta: 1},
+			setupMock:      func(m *MockStorer) {},
+			expectGRPCCode: codes.InvalidArgument,
+		},
+		{
+			name:    "non numeric value",
+			request: &keyvalue.IncrementRequest{Key: "text", Delta: 1},
+			setupMock: func(m *MockStorer) {
+				m.IncrementFunc = func(key string, delta int64) (int64, error) {
+					return 0, kvstore.ErrNotNumeric
+				}
+			},
+			expectGRPCCode: codes.FailedPrecondition,
+		},
+		{
+			name:    "overflow",
+			request: &keyvalue.IncrementRequest{Key: "max", Delta: 1},
+			setupMock: func(m *MockStorer) {
+				m.IncrementFunc = func(key string, delta int64) (int64, error) {
+					return 0, kvstore.ErrOverflow
+				}
+			},
+			expectGRPCCode: codes.OutOfRange,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStore := &MockStorer{}
+			tt.setupMock(mockStore)
+
+			server := NewKeyValueServer(mockStore)
+			ctx := context.Background()
+
+			resp, err := server.Increment(ctx, tt.request)
+
+			if tt.expectGRPCCode != codes.OK {
+				assert.Error(t, err)
+				st, ok := status.FromError(err)
+				assert.True(t, ok)
+				assert.Equal(t, tt.expectGRPCCode, st.Code())
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, resp)
+				assert.Equal(t, tt.expectedValue, resp.Value)
+			}
+		})
+	}
+}
+
+func TestKeyValueServer_Limits(t *testing.T) {
+	server := NewKeyValueServer(&MockStorer{}, WithLimits(limits.Limits{
+		MaxKeyLength: 8,
+		MaxValueSize: 16,
+		KeyPattern:   regexp.MustCompile(`^[a-z-]+$`),
+	}))
+	ctx := context.Background()
+
+	tests := []struct {
+		name            string
+		call            func() error
+		expectGRPCCode  codes.Code
+		expectedMessage string
+	}{
+		{
+			name: "set within limits",
+			call: func() error {
+				_, err := server.Set(ctx, &keyvalue.SetRequest{Key: "key", Value: "value"})
+				return err
+			},
+			expectGRPCCode: codes.OK,
+		},
+		{
+			name: "set key too long",
+			call: func() error {
+				_, err := server.Set(ctx, &keyvalue.SetRequest{Key: "much-too-long", Value: "value"})
+				return err
+			},
+			expectGRPCCode:  codes.InvalidArgument,
+			expectedMessage: "maximum key length is 8 bytes",
+		},
+		{
+			name: "set value too large",
+			call: func() error {
+				_, err := server.Set(ctx, &keyvalue.SetRequest{Key: "key", Value: strings.Repeat("v", 17)})
+				return err
+			},
+			expectGRPCCode:  codes.ResourceExhausted,
+			expectedMessage: "maximum value size is 16 bytes",
+		},
+		{
+			name: "get key with disallowed characters",
+			call: func() error {
+				_, err := server.Get(ctx, &keyvalue.GetRequest{Key: "KEY"})
+				return err
+			},
+			expectGRPCCode:  codes.InvalidArgument,
+			expectedMessage: "key must match",
+		},
+		{
+			name: "delete key too long",
+			call: func() error {
+				_, err := server.Delete(ctx, &keyvalue.DeleteRequest{Key: "much-too-long"})
+				return err
+			},
+			expectGRPCCode:  codes.InvalidArgument,
+			expectedMessage: "maximum key length",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			st, ok := status.FromError(err)
+			assert.True(t, ok)
+			assert.Equal(t, tt.expectGRPCCode, st.Code())
+			assert.Contains(t, st.Message(), tt.expectedMessage)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/history"
+	"time"
+)
+
+// HistoryServer implements the gRPC HistoryService over the revisions kept by the store
+type HistoryServer struct {
+	keyvalue.UnimplementedHistoryServiceServer
+	store *history.Store
+}
+
+// NewHistoryServer creates a new gRPC history service
+func NewHistoryServer(store *history.Store) *HistoryServer {
+	return &HistoryServer{store: store}
+}
+
+// GetAtRevision returns the value a key held at a revision of the store
+func (s *HistoryServer) GetAtRevision(ctx context.Context, req *keyvalue.GetAtRevisionRequest) (*keyvalue.KeyRevision, error) {
+	revision, err := s.store.GetAtRevision(req.Key, req.Revision)
+	if err != nil {
+		return nil, toStatus(err, req.Key)
+	}
+	return toKeyRevision(revision), nil
+}
+
+// GetAtTime returns the value a key held at a point in time
+func (s *HistoryServer) GetAtTime(ctx context.Context, req *keyvalue.GetAtTimeRequest) (*keyvalue.KeyRevision, error) {
+	revision, err := s.store.GetAtTime(req.Key, time.UnixMilli(req.TimestampMillis))
+	if err != nil {
+		return nil, toStatus(err, req.Key)
+	}
+	return toKeyRevision(revision), nil
+}
+
+// History lists the retained revisions of a key, newest first
+func (s *HistoryServer) History(ctx context.Context, req *keyvalue.HistoryRequest) (*keyvalue.HistoryResponse, error) {
+	revisions, err := s.store.History(req.Key)
+	if err != nil {
+		return nil, toStatus(err, req.Key)
+	}
+	compacted, latest := s.store.Bounds()
+	resp := &keyvalue.HistoryResponse{CompactedRevision: compacted, CurrentRevision: latest}
+	for _, revision := range revisions {
+		resp.Revisions = append(resp.Revisions, toKeyRevision(revision))
+	}
+	return resp, nil
+}
+
+// Compact drops the revisions replaced before a revision of the store
+func (s *HistoryServer) Compact(ctx context.Context, req *keyvalue.CompactRequest) (*keyvalue.CompactResponse, error) {
+	removed, err := s.store.Compact(req.Revision)
+	if err != nil {
+		return nil, toStatus(err, "")
+	}
+	return &keyvalue.CompactResponse{Removed: uint64(removed)}, nil
+}
+
+func toKeyRevision(revision history.Revision) *keyvalue.KeyRevision {
+	return &keyvalue.KeyRevision{
+		Revision:        revision.Revision,
+		Value:           revision.Value,
+		Deleted:         revision.Deleted,
+		TimestampMillis: revision.Time.UnixMilli(),
+	}
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/history"
+	"key-value/services/key-value/internal/kvstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// serveHistory serves the key-value and history services over a history store and returns clients for both
+func serveHistory(t *testing.T, store *history.Store) (keyvalue.KeyValueServiceClient, keyvalue.HistoryServiceClient) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	grpcServer := grpc.NewServer()
+	keyvalue.RegisterKeyValueServiceServer(grpcServer, NewKeyValueServer(store))
+	keyvalue.RegisterHistoryServiceServer(grpcServer, NewHistoryServer(store))
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return keyvalue.NewKeyValueServiceClient(conn), keyvalue.NewHistoryServiceClient(conn)
+}
+
+// reason returns the ErrorInfo reason of a status error
+func reason(t *testing.T, err error) string {
+	t.Helper()
+	details := status.Convert(err).Details()
+	require.Len(t, details, 1)
+	return details[0].(*errdetails.ErrorInfo).Reason
+}
+
+func TestHistoryServer_TimeTravel(t *testing.T) {
+	store, err := history.NewStore(kvstore.NewInMemoryStore())
+	require.NoError(t, err)
+	kv, hist := serveHistory(t, store)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, value := range []string{"v1", "v2", "v3"} {
+		_, err := kv.Set(ctx, &keyvalue.SetRequest{Key: "config", Value: value})
+		require.NoError(t, err)
+	}
+	_, err = kv.Delete(ctx, &keyvalue.DeleteRequest{Key: "config"})
+	require.NoError(t, err)
+
+	revision, err := hist.GetAtRevision(ctx, &keyvalue.GetAtRevisionRequest{Key: "config", Revision: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "v2", revision.Value)
+	assert.Equal(t, uint64(2), revision.Revision)
+
+	_, err = hist.GetAtRevision(ctx, &keyvalue.GetAtRevisionRequest{Key: "config"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = hist.GetAtRevision(ctx, &keyvalue.GetAtRevisionRequest{Key: "config", Revision: 9})
+	assert.Equal(t, codes.OutOfRange, status.Code(err))
+	assert.Equal(t, ReasonFuture, reason(t, err))
+
+	_, err = hist.GetAtTime(ctx, &keyvalue.GetAtTimeRequest{Key: "config", TimestampMillis: time.Now().Add(-time.Hour).UnixMilli()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	resp, err := hist.History(ctx, &keyvalue.HistoryRequest{Key: "config"})
+	require.NoError(t, err)
+	require.Len(t, resp.Revisions, 4)
+	assert.True(t, resp.Revisions[0].Deleted)
+	assert.Equal(t, "v1", resp.Revisions[3].Value)
+	assert.Equal(t, uint64(4), resp.CurrentRevision)
+
+	compacted, err := hist.Compact(ctx, &keyvalue.CompactRequest{Revision: 3})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), compacted.Removed)
+
+	_, err = hist.GetAtRevision(ctx, &keyvalue.GetAtRevisionRequest{Key: "config", Revision: 1})
+	assert.Equal(t, codes.OutOfRange, status.Code(err))
+	assert.Equal(t, ReasonCompacted, reason(t, err))
+}
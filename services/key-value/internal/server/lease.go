@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/services/key-value/internal/lease"
+	"math"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// LeaseServer implements the gRPC LeaseService over the leases of the store
+type LeaseServer struct {
+	keyvalue.UnimplementedLeaseServiceServer
+	kv    *KeyValueServer
+	store *lease.Store
+}
+
+// Leases returns the lease service over store, the lease layer of the store served by s. It enforces the same key
+// and value limits on Acquire and forwards requests the same way.
+func (s *KeyValueServer) Leases(store *lease.Store) *LeaseServer {
+	return &LeaseServer{kv: s, store: store}
+}
+
+// Grant creates a lease
+func (s *LeaseServer) Grant(ctx context.Context, req *keyvalue.LeaseGrantRequest) (*keyvalue.LeaseResponse, error) {
+	if req.TtlMillis <= 0 {
+		return nil, status.Error(codes.InvalidArgument, lease.ErrInvalidTTL.Error())
+	}
+	// Longer times would overflow a time.Duration
+	if req.TtlMillis > math.MaxInt64/int64(time.Millisecond) {
+		return nil, status.Errorf(codes.InvalidArgument, "lease time to live must be at most %d milliseconds",
+			math.MaxInt64/int64(time.Millisecond))
+	}
+	l, err := s.store.Grant(time.Duration(req.TtlMillis) * time.Millisecond)
+	if conn, ok := s.kv.forwarder.target(ctx, err); ok {
+		return keyvalue.NewLeaseServiceClient(conn).Grant(forwardContext(ctx), req)
+	}
+	if err != nil {
+		return nil, toStatus(err, "")
+	}
+	return toLeaseResponse(l), nil
+}
+
+// KeepAlive restarts the time to live of a lease
+func (s *LeaseServer) KeepAlive(ctx context.Context, req *keyvalue.LeaseKeepAliveRequest) (*keyvalue.LeaseResponse, error) {
+	l, err := s.store.KeepAlive(req.Id)
+	if conn, ok := s.kv.forwarder.target(ctx, err); ok {
+		return keyvalue.NewLeaseServiceClient(conn).KeepAlive(forwardContext(ctx), req)
+	}
+	if err != nil {
+		return nil, toStatus(err, "")
+	}
+	return toLeaseResponse(l), nil
+}
+
+// Revoke ends a lease, deleting its keys
+func (s *LeaseServer) Revoke(ctx context.Context, req *keyvalue.LeaseRevokeRequest) (*keyvalue.LeaseRevokeResponse, error) {
+	err := s.store.Revoke(req.Id)
+	if conn, ok := s.kv.forwarder.target(ctx, err); ok {
+		return keyvalue.NewLeaseServiceClient(conn).Revoke(forwardContext(ctx), req)
+	}
+	if err != nil {
+		return nil, toStatus(err, "")
+	}
+	return &keyvalue.LeaseRevokeResponse{}, nil
+}
+
+// TimeToLive describes a lease
+func (s *LeaseServer) TimeToLive(ctx context.Context, req *keyvalue.LeaseTimeToLiveRequest) (*keyvalue.LeaseResponse, error) {
+	l, err := s.store.TimeToLive(req.Id)
+	if conn, ok := s.kv.forwarder.target(ctx, err); ok {
+		return keyvalue.NewLeaseServiceClient(conn).TimeToLive(forwardContext(ctx), req)
+	}
+	if err != nil {
+		return nil, toStatus(err, "")
+	}
+	return toLeaseResponse(l), nil
+}
+
+// Acquire sets a key attached to a lease if it does not exist and hands out a fencing token
+func (s *LeaseServer) Acquire(ctx context.Context, req *keyvalue.AcquireRequest) (*keyvalue.AcquireResponse, error) {
+	if err := kvstore.CheckLimits(s.kv.limits, req.Key, req.Value); err != nil {
+		return nil, toStatus(err, req.Key)
+	}
+	token, holder, acquired, err := s.store.Acquire(req.Key, req.Value, req.LeaseId)
+	if conn, ok := s.kv.forwarder.target(ctx, err); ok {
+		return keyvalue.NewLeaseServiceClient(conn).Acquire(forwardContext(ctx), req)
+	}
+	if err != nil {
+		return nil, toStatus(err, req.Key)
+	}
+	return &keyvalue.AcquireResponse{Acquired: acquired, FencingToken: token, Holder: holder}, nil
+}
+
+func toLeaseResponse(l lease.Lease) *keyvalue.LeaseResponse {
+	return &keyvalue.LeaseResponse{
+		Id:              l.ID,
+		TtlMillis:       l.TTL.Milliseconds(),
+		RemainingMillis: l.Remaining.Milliseconds(),
+		Keys:            l.Keys,
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/services/key-value/internal/lease"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// serveLeases serves the key-value and lease services over a lease store and returns clients for both
+func serveLeases(t *testing.T) (keyvalue.KeyValueServiceClient, keyvalue.LeaseServiceClient) {
+	t.Helper()
+	store := lease.NewStore(kvstore.NewInMemoryStore(), lease.WithCheckInterval(5*time.Millisecond))
+	t.Cleanup(func() { store.Close() })
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := grpc.NewServer()
+	kvServer := NewKeyValueServer(store)
+	keyvalue.RegisterKeyValueServiceServer(grpcServer, kvServer)
+	keyvalue.RegisterLeaseServiceServer(grpcServer, kvServer.Leases(store))
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return keyvalue.NewKeyValueServiceClient(conn), keyvalue.NewLeaseServiceClient(conn)
+}
+
+func TestLeaseServer_KeysExpire(t *testing.T) {
+	kv, leases := serveLeases(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	granted, err := leases.Grant(ctx, &keyvalue.LeaseGrantRequest{TtlMillis: 50})
+	require.NoError(t, err)
+	_, err = kv.Set(ctx, &keyvalue.SetRequest{Key: "session", Value: "alice", LeaseId: granted.Id})
+	require.NoError(t, err)
+
+	ttl, err := leases.TimeToLive(ctx, &keyvalue.LeaseTimeToLiveRequest{Id: granted.Id})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"session"}, ttl.Keys)
+
+	assert.Eventually(t, func() bool {
+		resp, err := kv.Get(ctx, &keyvalue.GetRequest{Key: "session"})
+		return err == nil && !resp.Found
+	}, 2*time.Second, 5*time.Millisecond)
+
+	_, err = leases.KeepAlive(ctx, &keyvalue.LeaseKeepAliveRequest{Id: granted.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, ReasonNoLease, reason(t, err))
+	_, err = kv.Set(ctx, &keyvalue.SetRequest{Key: "session", Value: "bob", LeaseId: granted.Id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestLeaseServer_Acquire(t *testing.T) {
+	kv, leases := serveLeases(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	a, err := leases.Grant(ctx, &keyvalue.LeaseGrantRequest{TtlMillis: 60000})
+	require.NoError(t, err)
+	b, err := leases.Grant(ctx, &keyvalue.LeaseGrantRequest{TtlMillis: 60000})
+	require.NoError(t, err)
+
+	resp, err := leases.Acquire(ctx, &keyvalue.AcquireRequest{Key: "lock", Value: "a", LeaseId: a.Id})
+	require.NoError(t, err)
+	assert.True(t, resp.Acquired)
+	first := resp.FencingToken
+
+	resp, err = leases.Acquire(ctx, &keyvalue.AcquireRequest{Key: "lock", Value: "b", LeaseId: b.Id})
+	require.NoError(t, err)
+	assert.False(t, resp.Acquired)
+	assert.Equal(t, "a", resp.Holder)
+
+	_, err = leases.Revoke(ctx, &keyvalue.LeaseRevokeRequest{Id: a.Id})
+	require.NoError(t, err)
+	resp, err = leases.Acquire(ctx, &keyvalue.AcquireRequest{Key: "lock", Value: "b", LeaseId: b.Id})
+	require.NoError(t, err)
+	assert.True(t, resp.Acquired)
+	assert.Greater(t, resp.FencingToken, first)
+
+	_, err = leases.Grant(ctx, &keyvalue.LeaseGrantRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = kv.Set(ctx, &keyvalue.SetRequest{Key: "k", Value: "v", LeaseId: b.Id, IfAbsent: true})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestLeaseServer_GrantOverflow(t *testing.T) {
+	_, leases := serveLeases(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := leases.Grant(ctx, &keyvalue.LeaseGrantRequest{TtlMillis: math.MaxInt64})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
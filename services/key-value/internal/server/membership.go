@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/membership"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MembershipServer implements the gRPC MembershipService on top of this node's gossip
+type MembershipServer struct {
+	keyvalue.UnimplementedMembershipServiceServer
+	gossip *membership.Gossip
+}
+
+// NewMembershipServer creates a new gRPC membership service
+func NewMembershipServer(gossip *membership.Gossip) *MembershipServer {
+	return &MembershipServer{gossip: gossip}
+}
+
+// Ping answers a direct probe
+func (s *MembershipServer) Ping(ctx context.Context, req *keyvalue.PingRequest) (*keyvalue.PingResponse, error) {
+	resp, err := s.gossip.HandlePing(req)
+	if errors.Is(err, membership.ErrWrongTarget) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return resp, err
+}
+
+// IndirectPing probes a member on behalf of the sender
+func (s *MembershipServer) IndirectPing(ctx context.Context, req *keyvalue.IndirectPingRequest) (*keyvalue.IndirectPingResponse, error) {
+	return s.gossip.HandleIndirectPing(ctx, req), nil
+}
+
+// Join adds the sender to the group
+func (s *MembershipServer) Join(ctx context.Context, req *keyvalue.JoinRequest) (*keyvalue.JoinResponse, error) {
+	if req.Member == nil || req.Member.Id == "" || req.Member.Addr == "" {
+		return nil, status.Error(codes.InvalidArgument, "member ID and address are required")
+	}
+	return s.gossip.HandleJoin(req), nil
+}
+
+// Members returns the member list of this node
+func (s *MembershipServer) Members(ctx context.Context, req *keyvalue.MembersRequest) (*keyvalue.MemberList, error) {
+	return toMemberList(s.gossip.Members()), nil
+}
+
+// WatchMembers streams the member list until the client goes away or the node shuts down
+func (s *MembershipServer) WatchMembers(req *keyvalue.MembersRequest, stream keyvalue.MembershipService_WatchMembersServer) error {
+	updates, cancel := s.gossip.Subscribe()
+	defer cancel()
+
+	if err := stream.Send(toMemberList(s.gossip.Members())); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case members, ok := <-updates:
+			if !ok {
+				return status.Error(codes.Unavailable, "membership is shutting down")
+			}
+			if err := stream.Send(toMemberList(members)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toMemberList(members []membership.Member) *keyvalue.MemberList {
+	list := &keyvalue.MemberList{Members: make([]*keyvalue.GossipMember, len(members))}
+	for i, m := range members {
+		list.Members[i] = &keyvalue.GossipMember{Id: m.ID, Addr: m.Addr, State: m.State, Incarnation: m.Incarnation}
+	}
+	return list
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/membership"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// gossipNode is an in-process member of a gossip group
+type gossipNode struct {
+	gossip *membership.Gossip
+	server *grpc.Server
+	addr   string
+}
+
+func startGossipNode(t *testing.T, id string) *gossipNode {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	gossip, err := membership.NewGossip(id, lis.Addr().String(),
+		membership.WithProbeInterval(50*time.Millisecond),
+		membership.WithProbeTimeout(20*time.Millisecond),
+		membership.WithSuspicionTimeout(300*time.Millisecond),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { gossip.Close() })
+
+	grpcServer := grpc.NewServer()
+	keyvalue.RegisterMembershipServiceServer(grpcServer, NewMembershipServer(gossip))
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	return &gossipNode{gossip: gossip, server: grpcServer, addr: lis.Addr().String()}
+}
+
+// stateOf returns the state of member id as seen by node
+func stateOf(node *gossipNode, id string) keyvalue.MemberState {
+	for _, m := range node.gossip.Members() {
+		if m.ID == id {
+			return m.State
+		}
+	}
+	return keyvalue.MemberState_MEMBER_STATE_UNSPECIFIED
+}
+
+func eventuallyState(t *testing.T, nodes []*gossipNode, id string, state keyvalue.MemberState) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		for _, node := range nodes {
+			if stateOf(node, id) != state {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond, "%s never became %s everywhere", id, state)
+}
+
+func startGossipGroup(t *testing.T, ids ...string) []*gossipNode {
+	t.Helper()
+	nodes := make([]*gossipNode, len(ids))
+	for i, id := range ids {
+		nodes[i] = startGossipNode(t, id)
+	}
+	// Everyone joins through the first node only and learns the others by gossip
+	for _, node := range nodes[1:] {
+		require.NoError(t, node.gossip.Join(context.Background(), []string{nodes[0].addr}))
+	}
+	for _, id := range ids {
+		eventuallyState(t, nodes, id, membership.Alive)
+	}
+	return nodes
+}
+
+func TestMembership_DetectsFailure(t *testing.T) {
+	nodes := startGossipGroup(t, "a", "b", "c", "d")
+
+	// d crashes without leaving
+	nodes[3].server.Stop()
+	nodes[3].gossip.Close()
+
+	eventuallyState(t, nodes[:3], "d", membership.Dead)
+	for _, id := range []string{"a", "b", "c"} {
+		assert.Equal(t, membership.Alive, stateOf(nodes[0], id))
+	}
+}
+
+func TestMembership_Leave(t *testing.T) {
+	nodes := startGossipGroup(t, "a", "b", "c")
+
+	require.NoError(t, nodes[2].gossip.Leave(context.Background()))
+	nodes[2].gossip.Close()
+	nodes[2].server.Stop()
+
+	eventuallyState(t, nodes[:2], "c", membership.Left)
+}
+
+func TestMembership_RestartRefutesDeath(t *testing.T) {
+	nodes := startGossipGroup(t, "a", "b", "c")
+	nodes[2].server.Stop()
+	nodes[2].gossip.Close()
+	eventuallyState(t, nodes[:2], "c", membership.Dead)
+
+	// c comes back under the same ID at a new address
+	restarted := startGossipNode(t, "c")
+	require.NoError(t, restarted.gossip.Join(context.Background(), []string{nodes[0].addr}))
+
+	all := []*gossipNode{nodes[0], nodes[1], restarted}
+	eventuallyState(t, all, "c", membership.Alive)
+	for _, m := range nodes[1].gossip.Members() {
+		if m.ID == "c" {
+			assert.Equal(t, restarted.addr, m.Addr)
+		}
+	}
+}
+
+func TestMembership_WatchMembers(t *testing.T) {
+	nodes := startGossipGroup(t, "a", "b")
+
+	conn, err := grpc.NewClient(nodes[0].addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := keyvalue.NewMembershipServiceClient(conn).WatchMembers(ctx, &keyvalue.MembersRequest{})
+	require.NoError(t, err)
+
+	list, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Len(t, list.Members, 2)
+
+	// A new member shows up on the stream
+	joined := startGossipNode(t, "c")
+	require.NoError(t, joined.gossip.Join(context.Background(), []string{nodes[1].addr}))
+	for len(list.Members) < 3 {
+		list, err = stream.Recv()
+		require.NoError(t, err)
+	}
+	assert.Equal(t, "c", list.Members[2].Id)
+	assert.Equal(t, membership.Alive, list.Members[2].State)
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/services/key-value/internal/replication"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// snapshotChunkBytes bounds the keys and values sent in one snapshot message
+const snapshotChunkBytes = 1 << 20
+
+// ReplicationNode reports the replication state of a primary or follower
+type ReplicationNode interface {
+	Status() replication.Status
+}
+
+// replicationSource is implemented by nodes followers can stream from
+type replicationSource interface {
+	Changes(fromSeq uint64) ([]replication.Entry, <-chan struct{}, error)
+	Snapshot() (map[string]string, uint64, error)
+	Seq() uint64
+	Epoch() uint64
+}
+
+// ReplicationServer implements the gRPC ReplicationService
+type ReplicationServer struct {
+	keyvalue.UnimplementedReplicationServiceServer
+	node      ReplicationNode
+	heartbeat time.Duration
+}
+
+// NewReplicationServer creates a new gRPC replication service. Only primaries serve Replicate.
+func NewReplicationServer(node ReplicationNode) *ReplicationServer {
+	return &ReplicationServer{
+		node:      node,
+		heartbeat: time.Second,
+	}
+}
+
+// Replicate streams the mutation log, starting with a snapshot when the follower is too far behind
+func (s *ReplicationServer) Replicate(req *keyvalue.ReplicateRequest, stream keyvalue.ReplicationService_ReplicateServer) error {
+	source, ok := s.node.(replicationSource)
+	if !ok {
+		return toStatus(&kvstore.LeaderError{LeaderAddr: s.node.Status().PrimaryAddr}, "")
+	}
+
+	from := max(req.FromSeq, 1)
+	// A follower that replicated a previous incarnation of this primary must start over
+	if from > 1 && req.Epoch != source.Epoch() {
+		seq, err := s.sendSnapshot(stream, source)
+		if err != nil {
+			return err
+		}
+		from = seq + 1
+	}
+	if err := stream.Send(heartbeatEvent(source)); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(s.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		entries, notify, err := source.Changes(from)
+		if errors.Is(err, replication.ErrCompacted) {
+			seq, err := s.sendSnapshot(stream, source)
+			if err != nil {
+				return err
+			}
+			from = seq + 1
+			continue
+		}
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to read mutation log: %v", err)
+		}
+
+		for _, entry := range entries {
+			if err := stream.Send(mutationEvent(entry)); err != nil {
+				return err
+			}
+			from = entry.Seq + 1
+		}
+		if len(entries) > 0 {
+			continue
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-notify:
+		case <-ticker.C:
+			if err := stream.Send(heartbeatEvent(source)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sendSnapshot sends the source's data in chunks and returns the sequence number it reflects
+func (s *ReplicationServer) sendSnapshot(stream keyvalue.ReplicationService_ReplicateServer, source replicationSource) (uint64, error) {
+	data, seq, err := source.Snapshot()
+	if err != nil {
+		return 0, status.Errorf(codes.Internal, "failed to snapshot store: %v", err)
+	}
+
+	chunk := &keyvalue.SnapshotChunk{Seq: seq, Entries: make(map[string]string)}
+	size := 0
+	for key, value := range data {
+		if size > 0 && size+len(key)+len(value) > snapshotChunkBytes {
+			if err := stream.Send(&keyvalue.ReplicationEvent{Event: &keyvalue.ReplicationEvent_Snapshot{Snapshot: chunk}}); err != nil {
+				return 0, err
+			}
+			chunk = &keyvalue.SnapshotChunk{Seq: seq, Entries: make(map[string]string)}
+			size = 0
+		}
+		chunk.Entries[key] = value
+		size += len(key) + len(value)
+	}
+
+	chunk.Last = true
+	if err := stream.Send(&keyvalue.ReplicationEvent{Event: &keyvalue.ReplicationEvent_Snapshot{Snapshot: chunk}}); err != nil {
+		return 0, err
+	}
+	return seq, nil
+}
+
+// ReplicationStatus reports the role of the node and its replication lag
+func (s *ReplicationServer) ReplicationStatus(ctx context.Context, req *keyvalue.ReplicationStatusRequest) (*keyvalue.ReplicationStatusResponse, error) {
+	st := s.node.Status()
+	return &keyvalue.ReplicationStatusResponse{
+		Role:        st.Role,
+		PrimaryAddr: st.PrimaryAddr,
+		AppliedSeq:  st.AppliedSeq,
+		PrimarySeq:  st.PrimarySeq,
+		LagEntries:  st.LagEntries(),
+		LagMillis:   st.Lag.Milliseconds(),
+		Connected:   st.Connected,
+	}, nil
+}
+
+func mutationEvent(entry replication.Entry) *keyvalue.ReplicationEvent {
+	op := keyvalue.MutationOp_MUTATION_OP_SET
+	if entry.Op == replication.OpDelete {
+		op = keyvalue.MutationOp_MUTATION_OP_DELETE
+	}
+	return &keyvalue.ReplicationEvent{Event: &keyvalue.ReplicationEvent_Mutation{Mutation: &keyvalue.Mutation{
+		Seq:   entry.Seq,
+		Op:    op,
+		Key:   entry.Key,
+		Value: entry.Value,
+	}}}
+}
+
+func heartbeatEvent(source replicationSource) *keyvalue.ReplicationEvent {
+	return &keyvalue.ReplicationEvent{Event: &keyvalue.ReplicationEvent_Heartbeat{Heartbeat: &keyvalue.Heartbeat{
+		Seq:   source.Seq(),
+		Epoch: source.Epoch(),
+	}}}
+}
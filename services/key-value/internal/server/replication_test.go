@@ -0,0 +1,180 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/services/key-value/internal/replication"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// serveReplication serves the key-value and replication services for store on addr
+func serveReplication(t *testing.T, addr string, store kvstore.Storer, node ReplicationNode) (string, *grpc.Server) {
+	t.Helper()
+	lis, err := net.Listen("tcp", addr)
+	require.NoError(t, err)
+
+	replicationServer := NewReplicationServer(node)
+	replicationServer.heartbeat = 20 * time.Millisecond
+
+	grpcServer := grpc.NewServer()
+	keyvalue.RegisterKeyValueServiceServer(grpcServer, NewKeyValueServer(store))
+	keyvalue.RegisterReplicationServiceServer(grpcServer, replicationServer)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	return lis.Addr().String(), grpcServer
+}
+
+func dialReplica(t *testing.T, addr string) (keyvalue.KeyValueServiceClient, keyvalue.ReplicationServiceClient) {
+	t.Helper()
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return keyvalue.NewKeyValueServiceClient(conn), keyvalue.NewReplicationServiceClient(conn)
+}
+
+func startFollower(t *testing.T, primaryAddr string) (*replication.Follower, keyvalue.KeyValueServiceClient, keyvalue.ReplicationServiceClient) {
+	t.Helper()
+	follower, err := replication.NewFollower(kvstore.NewInMemoryStore(), primaryAddr, replication.WithRetryInterval(20*time.Millisecond))
+	require.NoError(t, err)
+	t.Cleanup(func() { follower.Close() })
+
+	addr, _ := serveReplication(t, "127.0.0.1:0", follower, follower)
+	kv, repl := dialReplica(t, addr)
+	return follower, kv, repl
+}
+
+// eventuallyValue waits for a replica to serve value for key
+func eventuallyValue(t *testing.T, kv keyvalue.KeyValueServiceClient, key string, value string) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		resp, err := kv.Get(context.Background(), &keyvalue.GetRequest{Key: key})
+		return err == nil && resp.Found && resp.Value == value
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestReplication_FollowerServesReads(t *testing.T) {
+	primary := replication.NewPrimary(kvstore.NewInMemoryStore(), 100)
+	primaryAddr, _ := serveReplication(t, "127.0.0.1:0", primary, primary)
+	primaryKV, _ := dialReplica(t, primaryAddr)
+	_, followerKV, followerStatus := startFollower(t, primaryAddr)
+	ctx := context.Background()
+
+	_, err := primaryKV.Set(ctx, &keyvalue.SetRequest{Key: "a", Value: "1"})
+	require.NoError(t, err)
+	_, err = primaryKV.Increment(ctx, &keyvalue.IncrementRequest{Key: "counter", Delta: 3})
+	require.NoError(t, err)
+	_, err = primaryKV.Set(ctx, &keyvalue.SetRequest{Key: "b", Value: "2"})
+	require.NoError(t, err)
+	_, err = primaryKV.Delete(ctx, &keyvalue.DeleteRequest{Key: "b"})
+	require.NoError(t, err)
+
+	eventuallyValue(t, followerKV, "a", "1")
+	eventuallyValue(t, followerKV, "counter", "3")
+	resp, err := followerKV.Get(ctx, &keyvalue.GetRequest{Key: "b"})
+	require.NoError(t, err)
+	assert.False(t, resp.Found)
+
+	require.Eventually(t, func() bool {
+		st, err := followerStatus.ReplicationStatus(ctx, &keyvalue.ReplicationStatusRequest{})
+		return err == nil && st.Connected && st.AppliedSeq == 4 && st.LagEntries == 0 && st.LagMillis == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestReplication_FollowerRejectsWrites(t *testing.T) {
+	primary := replication.NewPrimary(kvstore.NewInMemoryStore(), 100)
+	primaryAddr, _ := serveReplication(t, "127.0.0.1:0", primary, primary)
+	_, followerKV, _ := startFollower(t, primaryAddr)
+
+	_, err := followerKV.Set(context.Background(), &keyvalue.SetRequest{Key: "a", Value: "1"})
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+
+	require.Len(t, st.Details(), 1)
+	info := st.Details()[0].(*errdetails.ErrorInfo)
+	assert.Equal(t, ReasonNotLeader, info.Reason)
+	assert.Equal(t, primaryAddr, info.Metadata["leader"])
+}
+
+func TestReplication_CatchUpFromSnapshot(t *testing.T) {
+	// A log of 2 entries is compacted long before the follower connects
+	primary := replication.NewPrimary(kvstore.NewInMemoryStore(), 2)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, primary.Set(fmt.Sprintf("key-%d", i), fmt.Sprint(i)))
+	}
+	primaryAddr, _ := serveReplication(t, "127.0.0.1:0", primary, primary)
+	follower, followerKV, _ := startFollower(t, primaryAddr)
+
+	for i := 0; i < 10; i++ {
+		eventuallyValue(t, followerKV, fmt.Sprintf("key-%d", i), fmt.Sprint(i))
+	}
+
+	// Mutations after the snapshot keep streaming
+	require.NoError(t, primary.Set("key-0", "updated"))
+	eventuallyValue(t, followerKV, "key-0", "updated")
+	assert.Equal(t, uint64(11), follower.Status().AppliedSeq)
+}
+
+func TestReplication_ReconnectAfterFallingBehind(t *testing.T) {
+	primary := replication.NewPrimary(kvstore.NewInMemoryStore(), 100)
+	primaryAddr, primaryServer := serveReplication(t, "127.0.0.1:0", primary, primary)
+	follower, followerKV, _ := startFollower(t, primaryAddr)
+
+	require.NoError(t, primary.Set("a", "1"))
+	eventuallyValue(t, followerKV, "a", "1")
+
+	// The primary becomes unreachable while writes keep happening
+	primaryServer.Stop()
+	require.Eventually(t, func() bool { return !follower.Status().Connected }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, primary.Set("a", "2"))
+	require.NoError(t, primary.Set("b", "3"))
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Greater(t, follower.Status().Lag, time.Duration(0))
+
+	serveReplication(t, primaryAddr, primary, primary)
+	eventuallyValue(t, followerKV, "a", "2")
+	eventuallyValue(t, followerKV, "b", "3")
+	require.Eventually(t, func() bool {
+		st := follower.Status()
+		return st.Connected && st.LagEntries() == 0 && st.Lag == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestReplication_ResyncAfterPrimaryRestart(t *testing.T) {
+	primary := replication.NewPrimary(kvstore.NewInMemoryStore(), 100)
+	primaryAddr, primaryServer := serveReplication(t, "127.0.0.1:0", primary, primary)
+	_, followerKV, _ := startFollower(t, primaryAddr)
+
+	require.NoError(t, primary.Set("old", "1"))
+	require.NoError(t, primary.Set("shared", "1"))
+	eventuallyValue(t, followerKV, "shared", "1")
+
+	// The restarted primary has lost its data and starts a new log
+	primaryServer.Stop()
+	restarted := replication.NewPrimary(kvstore.NewInMemoryStore(), 100)
+	require.NoError(t, restarted.Set("shared", "2"))
+	require.NoError(t, restarted.Set("new", "2"))
+	require.NoError(t, restarted.Set("extra", "2"))
+	serveReplication(t, primaryAddr, restarted, restarted)
+
+	eventuallyValue(t, followerKV, "shared", "2")
+	eventuallyValue(t, followerKV, "new", "2")
+	resp, err := followerKV.Get(context.Background(), &keyvalue.GetRequest{Key: "old"})
+	require.NoError(t, err)
+	assert.False(t, resp.Found)
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/shared/limits"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func pairKeys(pairs []*keyvalue.KeyValuePair) []string {
+	var keys []string
+	for _, pair := range pairs {
+		keys = append(keys, pair.Key)
+	}
+	return keys
+}
+
+func TestKeyValueServer_Scan(t *testing.T) {
+	store := kvstore.NewInMemoryStore()
+	for i := 0; i < 5; i++ {
+		store.Set(fmt.Sprintf("user:%d", i), fmt.Sprint(i))
+	}
+	store.Set("order:1", "x")
+	server := NewKeyValueServer(store)
+	ctx := context.Background()
+
+	tests := []struct {
+		name         string
+		request      *keyvalue.ScanRequest
+		expectedKeys []string
+		expectMore   bool
+	}{
+		{"default limit", &keyvalue.ScanRequest{}, []string{"order:1", "user:0", "user:1", "user:2", "user:3", "user:4"}, false},
+		{"prefix", &keyvalue.ScanRequest{Prefix: "user:", Limit: 2}, []string{"user:0", "user:1"}, true},
+		{"resume", &keyvalue.ScanRequest{Prefix: "user:", StartAfter: "user:1", Limit: 2}, []string{"user:2", "user:3"}, true},
+		{"last page", &keyvalue.ScanRequest{Prefix: "user:", StartAfter: "user:3", Limit: 2}, []string{"user:4"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := server.Scan(ctx, tt.request)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedKeys, pairKeys(resp.Items))
+			assert.Equal(t, tt.expectMore, resp.More)
+		})
+	}
+}
+
+func TestKeyValueServer_ScanUnsupported(t *testing.T) {
+	for _, store := range []kvstore.Storer{&MockStorer{}, kvstore.Layer{Next: &MockStorer{}}} {
+		server := NewKeyValueServer(store)
+
+		_, err := server.Scan(context.Background(), &keyvalue.ScanRequest{})
+		st, _ := status.FromError(err)
+		assert.Equal(t, codes.Unimplemented, st.Code(), "%T", store)
+	}
+}
+
+func TestKeyValueServer_BatchGet(t *testing.T) {
+	store := kvstore.NewInMemoryStore()
+	store.Set("a", "1")
+	store.Set("c", "3")
+	server := NewKeyValueServer(store)
+
+	resp, err := server.BatchGet(context.Background(), &keyvalue.BatchGetRequest{Keys: []string{"a", "b", "c"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, pairKeys(resp.Items))
+	assert.Equal(t, "3", resp.Items[1].Value)
+
+	_, err = server.BatchGet(context.Background(), &keyvalue.BatchGetRequest{Keys: make([]string, MaxBatchSize+1)})
+	st, _ := status.FromError(err)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+}
+
+func TestKeyValueServer_BatchSet(t *testing.T) {
+	tests := []struct {
+		name           string
+		items          []*keyvalue.KeyValuePair
+		expectGRPCCode codes.Code
+		expectStored   map[string]string
+	}{
+		{
+			name:           "all pairs written",
+			items:          []*keyvalue.KeyValuePair{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}},
+			expectGRPCCode: codes.OK,
+			expectStored:   map[string]string{"a": "1", "b": "2"},
+		},
+		{
+			name:           "invalid pair rejects the whole batch",
+			items:          []*keyvalue.KeyValuePair{{Key: "a", Value: "1"}, {Key: "b", Value: strings.Repeat("v", 17)}},
+			expectGRPCCode: codes.ResourceExhausted,
+			expectStored:   map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := kvstore.NewInMemoryStore()
+			server := NewKeyValueServer(store, WithLimits(limits.Limits{MaxKeyLength: 8, MaxValueSize: 16}))
+
+			_, err := server.BatchSet(context.Background(), &keyvalue.BatchSetRequest{Items: tt.items})
+			st, _ := status.FromError(err)
+			assert.Equal(t, tt.expectGRPCCode, st.Code())
+
+			stored, _ := store.Snapshot()
+			assert.Equal(t, tt.expectStored, stored)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"context"
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/shared/limits"
+	"key-value/shared/vclock/vclockpb"
+)
+
+// VersionedServer implements the gRPC VersionedService on top of a VersionedStore
+type VersionedServer struct {
+	keyvalue.UnimplementedVersionedServiceServer
+	store  *kvstore.VersionedStore
+	limits limits.Limits
+}
+
+// NewVersionedServer creates a VersionedServer enforcing l on every key and value
+func NewVersionedServer(store *kvstore.VersionedStore, l limits.Limits) *VersionedServer {
+	return &VersionedServer{store: store, limits: l}
+}
+
+// GetVersions returns the siblings of a key
+func (s *VersionedServer) GetVersions(ctx context.Context, req *keyvalue.GetVersionsRequest) (*keyvalue.GetVersionsResponse, error) {
+	if err := kvstore.CheckKey(s.limits, req.Key); err != nil {
+		return nil, toStatus(err, req.Key)
+	}
+	return &keyvalue.GetVersionsResponse{Versions: vclockpb.ToProto(s.store.Get(req.Key))}, nil
+}
+
+// PutVersions reconciles the versions into the siblings of a key
+func (s *VersionedServer) PutVersions(ctx context.Context, req *keyvalue.PutVersionsRequest) (*keyvalue.PutVersionsResponse, error) {
+	for _, v := range req.Versions {
+		if err := kvstore.CheckLimits(s.limits, req.Key, v.Value); err != nil {
+			return nil, toStatus(err, req.Key)
+		}
+	}
+	versions := s.store.Put(req.Key, vclockpb.FromProto(req.Versions))
+	return &keyvalue.PutVersionsResponse{Versions: vclockpb.ToProto(versions)}, nil
+}
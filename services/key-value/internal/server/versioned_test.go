@@ -0,0 +1,58 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/shared/limits"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestVersionedServer_Siblings(t *testing.T) {
+	server := NewVersionedServer(kvstore.NewVersionedStore(), limits.Default())
+	ctx := context.Background()
+
+	_, err := server.PutVersions(ctx, &keyvalue.PutVersionsRequest{Key: "k", Versions: []*keyvalue.Version{
+		{Value: "a", Clock: map[string]uint64{"x": 1}},
+	}})
+	require.NoError(t, err)
+	resp, err := server.PutVersions(ctx, &keyvalue.PutVersionsRequest{Key: "k", Versions: []*keyvalue.Version{
+		{Value: "b", Clock: map[string]uint64{"y": 1}},
+	}})
+	require.NoError(t, err)
+	assert.Len(t, resp.Versions, 2)
+
+	// Writing an ancestor changes nothing
+	_, err = server.PutVersions(ctx, &keyvalue.PutVersionsRequest{Key: "k", Versions: []*keyvalue.Version{
+		{Value: "old", Clock: map[string]uint64{}},
+	}})
+	require.NoError(t, err)
+
+	got, err := server.GetVersions(ctx, &keyvalue.GetVersionsRequest{Key: "k"})
+	require.NoError(t, err)
+	var values []string
+	for _, v := range got.Versions {
+		values = append(values, v.Value)
+	}
+	assert.ElementsMatch(t, []string{"a", "b"}, values)
+}
+
+func TestVersionedServer_Limits(t *testing.T) {
+	server := NewVersionedServer(kvstore.NewVersionedStore(), limits.Limits{MaxKeyLength: 4, MaxValueSize: 4})
+	ctx := context.Background()
+
+	_, err := server.GetVersions(ctx, &keyvalue.GetVersionsRequest{Key: "toolong"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.PutVersions(ctx, &keyvalue.PutVersionsRequest{Key: "k", Versions: []*keyvalue.Version{
+		{Value: strings.Repeat("v", 5)},
+	}})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/watch"
+)
+
+// WatchServer implements the gRPC WatchService over the watchers of the store
+type WatchServer struct {
+	keyvalue.UnimplementedWatchServiceServer
+	store *watch.Store
+}
+
+// NewWatchServer creates a new gRPC watch service
+func NewWatchServer(store *watch.Store) *WatchServer {
+	return &WatchServer{store: store}
+}
+
+// Watch sends the current value of a key and then its changes until the client goes away
+func (s *WatchServer) Watch(req *keyvalue.WatchRequest, stream keyvalue.WatchService_WatchServer) error {
+	w, value, found, err := s.store.Watch(req.Key)
+	if err != nil {
+		return toStatus(err, req.Key)
+	}
+	defer w.Close()
+
+	current := watch.Event{Op: watch.OpDelete, Key: req.Key}
+	if found {
+		current = watch.Event{Op: watch.OpSet, Key: req.Key, Value: value}
+	}
+	if err := stream.Send(toWatchEvent(current)); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-w.Events():
+			if !ok {
+				return toStatus(w.Err(), req.Key)
+			}
+			if err := stream.Send(toWatchEvent(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toWatchEvent(event watch.Event) *keyvalue.WatchEvent {
+	op := keyvalue.MutationOp_MUTATION_OP_SET
+	if event.Op == watch.OpDelete {
+		op = keyvalue.MutationOp_MUTATION_OP_DELETE
+	}
+	return &keyvalue.WatchEvent{Op: op, Key: event.Key, Value: event.Value}
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"key-value/proto/keyvalue"
+	"key-value/services/key-value/internal/kvstore"
+	"key-value/services/key-value/internal/lease"
+	"key-value/services/key-value/internal/watch"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// serveWatch serves the key-value, lease and watch services over a lease store on top of a watch store
+func serveWatch(t *testing.T, opts ...watch.Option) (*watch.Store, keyvalue.KeyValueServiceClient, keyvalue.LeaseServiceClient, keyvalue.WatchServiceClient) {
+	t.Helper()
+	watchStore := watch.NewStore(kvstore.NewInMemoryStore(), opts...)
+	leaseStore := lease.NewStore(watchStore, lease.WithCheckInterval(5*time.Millisecond))
+	t.Cleanup(func() { leaseStore.Close() })
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	grpcServer := grpc.NewServer()
+	kvServer := NewKeyValueServer(leaseStore)
+	keyvalue.RegisterKeyValueServiceServer(grpcServer, kvServer)
+	keyvalue.RegisterLeaseServiceServer(grpcServer, kvServer.Leases(leaseStore))
+	keyvalue.RegisterWatchServiceServer(grpcServer, NewWatchServer(watchStore))
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return watchStore, keyvalue.NewKeyValueServiceClient(conn), keyvalue.NewLeaseServiceClient(conn), keyvalue.NewWatchServiceClient(conn)
+}
+
+func TestWatchServer_Watch(t *testing.T) {
+	_, kv, leases, watcher := serveWatch(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := kv.Set(ctx, &keyvalue.SetRequest{Key: "leader", Value: "a"})
+	require.NoError(t, err)
+	stream, err := watcher.Watch(ctx, &keyvalue.WatchRequest{Key: "leader"})
+	require.NoError(t, err)
+
+	// The current value comes first
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, keyvalue.MutationOp_MUTATION_OP_SET, event.Op)
+	assert.Equal(t, "a", event.Value)
+
+	_, err = kv.Delete(ctx, &keyvalue.DeleteRequest{Key: "leader"})
+	require.NoError(t, err)
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, keyvalue.MutationOp_MUTATION_OP_DELETE, event.Op)
+
+	// Keys of an expired lease are reported deleted
+	granted, err := leases.Grant(ctx, &keyvalue.LeaseGrantRequest{TtlMillis: 30})
+	require.NoError(t, err)
+	_, err = kv.Set(ctx, &keyvalue.SetRequest{Key: "leader", Value: "b", LeaseId: granted.Id})
+	require.NoError(t, err)
+
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, keyvalue.MutationOp_MUTATION_OP_SET, event.Op)
+	assert.Equal(t, "b", event.Value)
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, keyvalue.MutationOp_MUTATION_OP_DELETE, event.Op)
+	assert.Equal(t, "leader", event.Key)
+}
+
+func TestWatchServer_Lost(t *testing.T) {
+	store, _, _, watcher := serveWatch(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := watcher.Watch(ctx, &keyvalue.WatchRequest{Key: "missing"})
+	require.NoError(t, err)
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, keyvalue.MutationOp_MUTATION_OP_DELETE, event.Op)
+
+	require.NoError(t, store.Restore(map[string]string{"missing": "restored"}))
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	assert.Equal(t, ReasonWatchLost, reason(t, err))
+}
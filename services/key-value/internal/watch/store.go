@@ -0,0 +1,221 @@
+// Package watch notifies watchers of the changes made to single keys of the key-value store, so clients learn
+// about them without polling.
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"key-value/services/key-value/internal/kvstore"
+	"strconv"
+	"sync"
+)
+
+// Op is the kind of change. Increments are reported as a set of the resulting value.
+type Op string
+
+// Change operations
+const (
+	OpSet    Op = "set"
+	OpDelete Op = "delete"
+)
+
+// DefaultBuffer is the number of events a watcher may fall behind before it is closed
+const DefaultBuffer = 64
+
+// ErrLost is returned by a closed watcher that missed events, because it fell behind or the data was restored
+// from a snapshot. Watch the key again to continue from its current value.
+var ErrLost = errors.New("watch lost events")
+
+// Event is a change of a watched key
+type Event struct {
+	Op    Op
+	Key   string
+	Value string
+}
+
+// Watcher receives the changes of one key until it is closed
+type Watcher struct {
+	store  *Store
+	key    string
+	events chan Event
+	err    error // set before events is closed by the store
+	closed bool  // guarded by the mutex of the store
+}
+
+// Events returns the changes of the key in order. The channel is closed when the watcher is.
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// Err returns why the store closed the watcher once Events is closed, nil when it was closed with Close
+func (w *Watcher) Err() error {
+	return w.err
+}
+
+// Close stops the watcher
+func (w *Watcher) Close() {
+	w.store.mutex.Lock()
+	defer w.store.mutex.Unlock()
+	w.store.remove(w, nil)
+}
+
+// Store wraps a store and notifies the watchers of each key it changes. It implements kvstore.Storer and passes
+// the optional store interfaces through, so it can sit under Raft or replication and report what they apply.
+type Store struct {
+	kvstore.Layer
+	mutex    sync.Mutex // orders changes of the store with their events
+	watchers map[string]map[*Watcher]struct{}
+	buffer   int
+}
+
+// Option configures a Store
+type Option func(*Store)
+
+// WithBuffer sets the number of events a watcher may fall behind before it is closed, DefaultBuffer by default
+func WithBuffer(events int) Option {
+	return func(s *Store) {
+		if events > 0 {
+			s.buffer = events
+		}
+	}
+}
+
+// NewStore starts reporting the changes made through the returned store
+func NewStore(store kvstore.Storer, opts ...Option) *Store {
+	s := &Store{
+		Layer:    kvstore.Layer{Next: store},
+		watchers: make(map[string]map[*Watcher]struct{}),
+		buffer:   DefaultBuffer,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Watch starts watching key and returns its current value, so no change in between is missed. Reserved keys
+// cannot be watched.
+func (s *Store) Watch(key string) (w *Watcher, value string, found bool, err error) {
+	if kvstore.IsReserved(key) {
+		return nil, "", false, fmt.Errorf("%w: keys starting with %q are reserved", kvstore.ErrInvalidKey, kvstore.ReservedPrefix)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	value, err = s.Next.Get(key)
+	switch {
+	case errors.Is(err, kvstore.ErrNotFound):
+	case err != nil:
+		return nil, "", false, err
+	default:
+		found = true
+	}
+
+	w = &Watcher{store: s, key: key, events: make(chan Event, s.buffer)}
+	if s.watchers[key] == nil {
+		s.watchers[key] = make(map[*Watcher]struct{})
+	}
+	s.watchers[key][w] = struct{}{}
+	return w, value, found, nil
+}
+
+// Set stores a key-value pair and reports it
+func (s *Store) Set(key string, value string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.Next.Set(key, value); err != nil {
+		return err
+	}
+	s.notify(Event{Op: OpSet, Key: key, Value: value})
+	return nil
+}
+
+// Delete removes a key and reports it
+func (s *Store) Delete(key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.Next.Delete(key); err != nil {
+		return err
+	}
+	s.notify(Event{Op: OpDelete, Key: key})
+	return nil
+}
+
+// Increment adds delta to a key and reports the resulting value
+func (s *Store) Increment(key string, delta int64) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	result, err := s.Next.Increment(key, delta)
+	if err != nil {
+		return 0, err
+	}
+	s.notify(Event{Op: OpSet, Key: key, Value: strconv.FormatInt(result, 10)})
+	return result, nil
+}
+
+// Restore replaces the data of the store and closes every watcher with ErrLost
+func (s *Store) Restore(data map[string]string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.Layer.Restore(data); err != nil {
+		return err
+	}
+	for _, watchers := range s.watchers {
+		for w := range watchers {
+			s.remove(w, fmt.Errorf("%w: data was restored from a snapshot", ErrLost))
+		}
+	}
+	return nil
+}
+
+// SetIfAbsent stores a key-value pair only if the key does not exist, reporting it when it did not
+func (s *Store) SetIfAbsent(key string, value string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.Layer.SetIfAbsent(key, value); err != nil {
+		return err
+	}
+	s.notify(Event{Op: OpSet, Key: key, Value: value})
+	return nil
+}
+
+// DeleteIfValue removes a key only if it holds value, reporting it when it did
+func (s *Store) DeleteIfValue(key string, value string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.Layer.DeleteIfValue(key, value); err != nil {
+		return err
+	}
+	s.notify(Event{Op: OpDelete, Key: key})
+	return nil
+}
+
+// notify hands an event to the watchers of its key, closing those that fell behind. The caller holds the mutex.
+func (s *Store) notify(event Event) {
+	if kvstore.IsReserved(event.Key) {
+		return
+	}
+	for w := range s.watchers[event.Key] {
+		select {
+		case w.events <- event:
+		default:
+			s.remove(w, fmt.Errorf("%w: watcher of %s fell behind", ErrLost, event.Key))
+		}
+	}
+}
+
+// remove closes a watcher with err. The caller holds the mutex.
+func (s *Store) remove(w *Watcher, err error) {
+	if w.closed {
+		return
+	}
+	w.closed = true
+	w.err = err
+	close(w.events)
+
+	delete(s.watchers[w.key], w)
+	if len(s.watchers[w.key]) == 0 {
+		delete(s.watchers, w.key)
+	}
+}
@@ -0,0 +1,109 @@
+package watch
+
+import (
+	"testing"
+
+	"key-value/services/key-value/internal/kvstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drain returns the events a watcher received so far
+func drain(w *Watcher) []Event {
+	var events []Event
+	for {
+		select {
+		case event, ok := <-w.Events():
+			if !ok {
+				return events
+			}
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestStore_ReportsChanges(t *testing.T) {
+	store := NewStore(kvstore.NewInMemoryStore())
+	require.NoError(t, store.Set("leader", "a"))
+
+	w, value, found, err := store.Watch("leader")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "a", value)
+	other, _, found, err := store.Watch("other")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	require.NoError(t, store.Set("leader", "b"))
+	require.NoError(t, store.Delete("leader"))
+	require.NoError(t, store.SetIfAbsent("leader", "c"))
+	assert.ErrorIs(t, store.SetIfAbsent("leader", "d"), kvstore.ErrConflict)
+	assert.ErrorIs(t, store.DeleteIfValue("leader", "d"), kvstore.ErrConflict)
+	require.NoError(t, store.DeleteIfValue("leader", "c"))
+	_, err = store.Increment("leader", 2)
+	require.NoError(t, err)
+	require.NoError(t, store.Set("unwatched", "x"))
+
+	assert.Equal(t, []Event{
+		{Op: OpSet, Key: "leader", Value: "b"},
+		{Op: OpDelete, Key: "leader"},
+		{Op: OpSet, Key: "leader", Value: "c"},
+		{Op: OpDelete, Key: "leader"},
+		{Op: OpSet, Key: "leader", Value: "2"},
+	}, drain(w))
+	assert.Empty(t, drain(other))
+
+	w.Close()
+	w.Close()
+	_, ok := <-w.Events()
+	assert.False(t, ok)
+	assert.NoError(t, w.Err())
+	require.NoError(t, store.Set("leader", "e"))
+}
+
+func TestStore_ClosesLostWatchers(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(store *Store) error
+	}{
+		{
+			name: "fell behind",
+			change: func(store *Store) error {
+				for range 3 {
+					if err := store.Set("k", "v"); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		},
+		{
+			name: "restored",
+			change: func(store *Store) error {
+				return store.Restore(map[string]string{"k": "restored"})
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewStore(kvstore.NewInMemoryStore(), WithBuffer(2))
+			w, _, _, err := store.Watch("k")
+			require.NoError(t, err)
+
+			require.NoError(t, tt.change(store))
+			drain(w)
+			_, ok := <-w.Events()
+			assert.False(t, ok)
+			assert.ErrorIs(t, w.Err(), ErrLost)
+
+			// Watching again starts from the current value
+			_, value, found, err := store.Watch("k")
+			require.NoError(t, err)
+			assert.True(t, found)
+			assert.NotEmpty(t, value)
+		})
+	}
+}
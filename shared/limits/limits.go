@@ -0,0 +1,113 @@
+package limits
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+const (
+	// DefaultMaxKeyLength is the default maximum key length in bytes
+	DefaultMaxKeyLength = 1024
+	// DefaultMaxValueSize is the default maximum value size in bytes (1 MiB)
+	DefaultMaxValueSize = 1 << 20
+)
+
+// Errors returned by the validation functions. They are wrapped with a message naming the limit.
+var (
+	ErrEmptyKey      = errors.New("key cannot be empty")
+	ErrKeyTooLong    = errors.New("key too long")
+	ErrKeyCharacters = errors.New("key contains characters that are not allowed")
+	ErrValueTooLarge = errors.New("value too large")
+)
+
+// Limits holds the key and value constraints enforced by the gateway, the gRPC server and the store
+type Limits struct {
+	MaxKeyLength int            // Maximum key length in bytes, 0 disables the check
+	MaxValueSize int            // Maximum value size in bytes, 0 disables the check
+	KeyPattern   *regexp.Regexp // Keys must match this pattern, nil allows any key
+}
+
+// Default returns the limits used when nothing is configured
+func Default() Limits {
+	return Limits{
+		MaxKeyLength: DefaultMaxKeyLength,
+		MaxValueSize: DefaultMaxValueSize,
+	}
+}
+
+// Load reads the limits from MAX_KEY_LENGTH, MAX_VALUE_SIZE and KEY_PATTERN, falling back to the defaults.
+// An invalid KEY_PATTERN stops the process, since allowing any key instead would silently lift a restriction.
+func Load() Limits {
+	l := Default()
+	l.MaxKeyLength = envInt("MAX_KEY_LENGTH", l.MaxKeyLength)
+	l.MaxValueSize = envInt("MAX_VALUE_SIZE", l.MaxValueSize)
+
+	if pattern := os.Getenv("KEY_PATTERN"); pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			log.Fatalf("Invalid KEY_PATTERN %q: %v", pattern, err)
+		}
+		l.KeyPattern = re
+	}
+	return l
+}
+
+// MessageSize returns the gRPC message size that fits a request or reply carrying a key and value of the maximum
+// sizes, and never less than gRPC's default of 4 MiB. Without a key or value limit it is the largest size gRPC allows.
+// Servers and every client dialing them should raise their message limits to it, or values the service accepts
+// could not be read back.
+func (l Limits) MessageSize() int {
+	if l.MaxKeyLength == 0 || l.MaxValueSize == 0 {
+		return math.MaxInt32
+	}
+	return max(l.MaxKeyLength+l.MaxValueSize+1024, 4<<20)
+}
+
+// ValidateKey checks a key against the key length and character set limits
+func (l Limits) ValidateKey(key string) error {
+	if key == "" {
+		return ErrEmptyKey
+	}
+	if l.MaxKeyLength > 0 && len(key) > l.MaxKeyLength {
+		return fmt.Errorf("%w: key is %d bytes, the maximum key length is %d bytes", ErrKeyTooLong, len(key), l.MaxKeyLength)
+	}
+	if l.KeyPattern != nil && !l.KeyPattern.MatchString(key) {
+		return fmt.Errorf("%w: key must match %s", ErrKeyCharacters, l.KeyPattern)
+	}
+	return nil
+}
+
+// ValidateValue checks a value against the value size limit
+func (l Limits) ValidateValue(value string) error {
+	if l.MaxValueSize > 0 && len(value) > l.MaxValueSize {
+		return fmt.Errorf("%w: value is %d bytes, the maximum value size is %d bytes", ErrValueTooLarge, len(value), l.MaxValueSize)
+	}
+	return nil
+}
+
+// Validate checks both the key and the value
+func (l Limits) Validate(key string, value string) error {
+	if err := l.ValidateKey(key); err != nil {
+		return err
+	}
+	return l.ValidateValue(value)
+}
+
+// envInt reads a non negative integer from the environment, returning def when unset or invalid
+func envInt(name string, def int) int {
+	raw := os.Getenv(name)
+	if raw == "" {
+		return def
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		log.Printf("Invalid %s %q, using %d", name, raw, def)
+		return def
+	}
+	return n
+}
@@ -0,0 +1,92 @@
+package limits
+
+import (
+	"errors"
+	"math"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestLimits_Validate(t *testing.T) {
+	l := Limits{
+		MaxKeyLength: 8,
+		MaxValueSize: 4,
+		KeyPattern:   regexp.MustCompile(`^[a-z:]+$`),
+	}
+
+	tests := []struct {
+		name        string
+		key         string
+		value       string
+		expectedErr error
+		mentions    string
+	}{
+		{"valid", "user:a", "1234", nil, ""},
+		{"empty key", "", "v", ErrEmptyKey, ""},
+		{"key too long", "abcdefghi", "v", ErrKeyTooLong, "8 bytes"},
+		{"key characters", "User A", "v", ErrKeyCharacters, "^[a-z:]+$"},
+		{"value too large", "key", "12345", ErrValueTooLarge, "4 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := l.Validate(tt.key, tt.value)
+			if tt.expectedErr == nil {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.expectedErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.expectedErr)
+			}
+			if !strings.Contains(err.Error(), tt.mentions) {
+				t.Errorf("Validate() error = %q, want it to mention %q", err, tt.mentions)
+			}
+		})
+	}
+}
+
+func TestLimits_ZeroDisablesChecks(t *testing.T) {
+	l := Limits{}
+	if err := l.Validate(strings.Repeat("k", 10000), strings.Repeat("v", 10000)); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv("MAX_KEY_LENGTH", "16")
+	t.Setenv("MAX_VALUE_SIZE", "not-a-number")
+	t.Setenv("KEY_PATTERN", `^[a-z]+$`)
+
+	l := Load()
+	if l.MaxKeyLength != 16 {
+		t.Errorf("MaxKeyLength = %d, want 16", l.MaxKeyLength)
+	}
+	if l.MaxValueSize != DefaultMaxValueSize {
+		t.Errorf("MaxValueSize = %d, want default %d", l.MaxValueSize, DefaultMaxValueSize)
+	}
+	if l.KeyPattern == nil || l.KeyPattern.String() != `^[a-z]+$` {
+		t.Errorf("KeyPattern = %v, want ^[a-z]+$", l.KeyPattern)
+	}
+}
+
+func TestLimits_MessageSize(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits Limits
+		want   int
+	}{
+		{"defaults fit in the gRPC default", Default(), 4 << 20},
+		{"large values", Limits{MaxKeyLength: 1024, MaxValueSize: 16 << 20}, 16<<20 + 2048},
+		{"no value limit", Limits{MaxKeyLength: 1024}, math.MaxInt32},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.limits.MessageSize(); got != tt.want {
+				t.Errorf("MessageSize() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+// Package pairs reads and writes streams of key-value pairs as NDJSON or CSV, the formats of bulk imports and
+// exports.
+//
+// NDJSON has one {"key": ..., "value": ...} object per line. CSV starts with a header row naming the key and value
+// columns; other columns are ignored when reading.
+package pairs
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"key-value/shared/models"
+	"strings"
+)
+
+// Format is an encoding of a stream of pairs
+type Format string
+
+// Supported formats
+const (
+	NDJSON Format = "ndjson"
+	CSV    Format = "csv"
+)
+
+// maxLineLength bounds an NDJSON line, leaving room for escaping above the largest default value
+const maxLineLength = 64 << 20
+
+// ParseFormat parses a format name, NDJSON when it is empty
+func ParseFormat(name string) (Format, error) {
+	switch Format(strings.ToLower(name)) {
+	case "", NDJSON:
+		return NDJSON, nil
+	case CSV:
+		return CSV, nil
+	}
+	return "", fmt.Errorf("unknown format %q, expected ndjson or csv", name)
+}
+
+// ContentType returns the media type of a format
+func (f Format) ContentType() string {
+	if f == CSV {
+		return "text/csv"
+	}
+	return "application/x-ndjson"
+}
+
+// Writer encodes pairs
+type Writer interface {
+	Write(kv models.KeyValue) error
+	// Flush writes any buffered data to the underlying writer
+	Flush() error
+}
+
+// NewWriter creates a writer encoding pairs to w in format
+func NewWriter(w io.Writer, format Format) Writer {
+	if format == CSV {
+		return &csvWriter{w: csv.NewWriter(w)}
+	}
+	buffered := bufio.NewWriter(w)
+	return &ndjsonWriter{w: buffered, encoder: json.NewEncoder(buffered)}
+}
+
+type ndjsonWriter struct {
+	w       *bufio.Writer
+	encoder *json.Encoder
+}
+
+func (w *ndjsonWriter) Write(kv models.KeyValue) error {
+	return w.encoder.Encode(kv)
+}
+
+func (w *ndjsonWriter) Flush() error {
+	return w.w.Flush()
+}
+
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (w *csvWriter) Write(kv models.KeyValue) error {
+	if !w.headerWritten {
+		w.headerWritten = true
+		if err := w.w.Write([]string{"key", "value"}); err != nil {
+			return err
+		}
+	}
+	return w.w.Write([]string{kv.Key, kv.Value})
+}
+
+// Flush writes the header too when no pair was written, so an empty export is still a valid CSV file
+func (w *csvWriter) Flush() error {
+	if !w.headerWritten {
+		w.headerWritten = true
+		if err := w.w.Write([]string{"key", "value"}); err != nil {
+			return err
+		}
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// Reader decodes pairs
+type Reader interface {
+	// Read returns the next pair, io.EOF after the last one. Errors name the line of the input.
+	Read() (models.KeyValue, error)
+}
+
+// ErrSyntax is wrapped by the errors of malformed input
+var ErrSyntax = errors.New("malformed input")
+
+// NewReader creates a reader decoding pairs from r in format
+func NewReader(r io.Reader, format Format) Reader {
+	if format == CSV {
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.ReuseRecord = true
+		return &csvReader{r: reader, keyColumn: -1, valueColumn: -1}
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), maxLineLength)
+	return &ndjsonReader{scanner: scanner}
+}
+
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (r *ndjsonReader) Read() (models.KeyValue, error) {
+	for r.scanner.Scan() {
+		r.line++
+		line := r.scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		var kv models.KeyValue
+		if err := json.Unmarshal(line, &kv); err != nil {
+			return models.KeyValue{}, fmt.Errorf("line %d: %w: %v", r.line, ErrSyntax, err)
+		}
+		if kv.Key == "" {
+			return models.KeyValue{}, fmt.Errorf("line %d: %w: key is required", r.line, ErrSyntax)
+		}
+		return kv, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return models.KeyValue{}, fmt.Errorf("line %d: %w: line too long", r.line+1, ErrSyntax)
+		}
+		return models.KeyValue{}, err
+	}
+	return models.KeyValue{}, io.EOF
+}
+
+type csvReader struct {
+	r           *csv.Reader
+	keyColumn   int
+	valueColumn int
+}
+
+func (r *csvReader) Read() (models.KeyValue, error) {
+	if r.keyColumn < 0 {
+		if err := r.readHeader(); err != nil {
+			return models.KeyValue{}, err
+		}
+	}
+	record, err := r.r.Read()
+	if err != nil {
+		return models.KeyValue{}, r.wrap(err)
+	}
+	line, _ := r.r.FieldPos(0)
+	if len(record) <= max(r.keyColumn, r.valueColumn) {
+		return models.KeyValue{}, fmt.Errorf("line %d: %w: missing key or value column", line, ErrSyntax)
+	}
+	kv := models.KeyValue{Key: record[r.keyColumn], Value: record[r.valueColumn]}
+	if kv.Key == "" {
+		return models.KeyValue{}, fmt.Errorf("line %d: %w: key is required", line, ErrSyntax)
+	}
+	return kv, nil
+}
+
+// readHeader finds the key and value columns in the header row
+func (r *csvReader) readHeader() error {
+	header, err := r.r.Read()
+	if errors.Is(err, io.EOF) {
+		return io.EOF
+	}
+	if err != nil {
+		return r.wrap(err)
+	}
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "key":
+			r.keyColumn = i
+		case "value":
+			r.valueColumn = i
+		}
+	}
+	if r.keyColumn < 0 || r.valueColumn < 0 {
+		r.keyColumn = -1
+		return fmt.Errorf("line 1: %w: the header row must name a key and a value column", ErrSyntax)
+	}
+	return nil
+}
+
+// wrap marks CSV parse errors as syntax errors
+func (r *csvReader) wrap(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return fmt.Errorf("line %d: %w: %v", parseErr.Line, ErrSyntax, parseErr.Err)
+	}
+	return err
+}
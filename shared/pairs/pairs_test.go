@@ -0,0 +1,100 @@
+package pairs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"key-value/shared/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(t *testing.T, r Reader) ([]models.KeyValue, error) {
+	t.Helper()
+	var items []models.KeyValue
+	for {
+		kv, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return items, nil
+		}
+		if err != nil {
+			return items, err
+		}
+		items = append(items, kv)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	items := []models.KeyValue{
+		{Key: "a", Value: "1"},
+		{Key: "quoted,key", Value: `say "hi"`},
+		{Key: "multi", Value: "line one\nline two"},
+		{Key: "empty", Value: ""},
+	}
+	for _, format := range []Format{NDJSON, CSV} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewWriter(&buf, format)
+			for _, kv := range items {
+				require.NoError(t, w.Write(kv))
+			}
+			require.NoError(t, w.Flush())
+
+			got, err := readAll(t, NewReader(&buf, format))
+			require.NoError(t, err)
+			assert.Equal(t, items, got)
+		})
+	}
+}
+
+func TestWriter_EmptyCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, NewWriter(&buf, CSV).Flush())
+	assert.Equal(t, "key,value\n", buf.String())
+}
+
+func TestReader(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		input  string
+		want   []models.KeyValue
+		err    string
+	}{
+		{"ndjson blank lines", NDJSON, "\n{\"key\":\"a\",\"value\":\"1\"}\n\n", []models.KeyValue{{Key: "a", Value: "1"}}, ""},
+		{"ndjson bad json", NDJSON, "{\"key\":\"a\"}\nnot json\n", []models.KeyValue{{Key: "a"}}, "line 2: malformed input"},
+		{"ndjson missing key", NDJSON, `{"value":"1"}`, nil, "line 1: malformed input: key is required"},
+		{"csv columns in any order", CSV, "value,extra,Key\n1,x,a\n", []models.KeyValue{{Key: "a", Value: "1"}}, ""},
+		{"csv empty", CSV, "", nil, ""},
+		{"csv no header", CSV, "a,1\n", nil, "line 1: malformed input: the header row must name"},
+		{"csv short row", CSV, "key,value\na,1\nb\n", []models.KeyValue{{Key: "a", Value: "1"}}, "line 3: malformed input: missing"},
+		{"csv bad quotes", CSV, "key,value\n\"a,1\n", nil, "malformed input"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readAll(t, NewReader(strings.NewReader(tt.input), tt.format))
+			assert.Equal(t, tt.want, got)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrSyntax)
+			assert.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]Format{"": NDJSON, "ndjson": NDJSON, "CSV": CSV} {
+		format, err := ParseFormat(name)
+		require.NoError(t, err)
+		assert.Equal(t, want, format)
+	}
+	_, err := ParseFormat("xml")
+	assert.EqualError(t, err, `unknown format "xml", expected ndjson or csv`)
+	assert.Equal(t, "text/csv", CSV.ContentType())
+}
@@ -0,0 +1,105 @@
+// Package vclock implements vector clocks and the sibling reconciliation used by quorum replication
+package vclock
+
+// Clock counts the writes each actor has made to a key. A missing actor counts as 0.
+type Clock map[string]uint64
+
+// Order is the causal relation between two clocks
+type Order int
+
+const (
+	Equal      Order = iota // both clocks saw the same writes
+	Before                  // the first clock is an ancestor of the second
+	After                   // the first clock descends from the second
+	Concurrent              // neither saw all the writes of the other
+)
+
+// Compare returns how a relates to b
+func Compare(a Clock, b Clock) Order {
+	aNewer, bNewer := false, false
+	for actor, count := range a {
+		if count > b[actor] {
+			aNewer = true
+		}
+	}
+	for actor, count := range b {
+		if count > a[actor] {
+			bNewer = true
+		}
+	}
+
+	switch {
+	case aNewer && bNewer:
+		return Concurrent
+	case aNewer:
+		return After
+	case bNewer:
+		return Before
+	default:
+		return Equal
+	}
+}
+
+// Copy returns an independent copy of c
+func (c Clock) Copy() Clock {
+	copied := make(Clock, len(c))
+	for actor, count := range c {
+		copied[actor] = count
+	}
+	return copied
+}
+
+// Merge returns a clock that descends from every clock given
+func Merge(clocks ...Clock) Clock {
+	merged := Clock{}
+	for _, c := range clocks {
+		for actor, count := range c {
+			merged[actor] = max(merged[actor], count)
+		}
+	}
+	return merged
+}
+
+// Version is one value of a key together with the clock of the write that produced it.
+// A deleted key is kept as a tombstone, so the delete can supersede older values on other replicas.
+type Version struct {
+	Value   string
+	Deleted bool
+	Clock   Clock
+}
+
+// Reconcile adds v to a set of sibling versions. Versions v descends from are dropped, v is dropped
+// if a sibling already descends from it or is equal to it. The result holds only concurrent versions.
+func Reconcile(siblings []Version, v Version) []Version {
+	result := make([]Version, 0, len(siblings)+1)
+	for _, sibling := range siblings {
+		switch Compare(v.Clock, sibling.Clock) {
+		case Before, Equal:
+			return siblings
+		case Concurrent:
+			result = append(result, sibling)
+		}
+	}
+	return append(result, v)
+}
+
+// Resolve reconciles every version into the smallest set of concurrent siblings
+func Resolve(versions ...[]Version) []Version {
+	var siblings []Version
+	for _, set := range versions {
+		for _, v := range set {
+			siblings = Reconcile(siblings, v)
+		}
+	}
+	return siblings
+}
+
+// Contains reports whether siblings hold a version equal to or descending from v
+func Contains(siblings []Version, v Version) bool {
+	for _, sibling := range siblings {
+		if order := Compare(sibling.Clock, v.Clock); order == Equal || order == After {
+			return true
+		}
+	}
+	return false
+}
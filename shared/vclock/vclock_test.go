@@ -0,0 +1,88 @@
+package vclock
+
+import (
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name string
+		a    Clock
+		b    Clock
+		want Order
+	}{
+		{"both empty", Clock{}, Clock{}, Equal},
+		{"equal", Clock{"x": 1, "y": 2}, Clock{"x": 1, "y": 2}, Equal},
+		{"missing actor counts as zero", Clock{"x": 1, "y": 0}, Clock{"x": 1}, Equal},
+		{"ancestor", Clock{"x": 1}, Clock{"x": 2}, Before},
+		{"ancestor of other actor", Clock{"x": 1}, Clock{"x": 1, "y": 1}, Before},
+		{"descendant", Clock{"x": 2, "y": 1}, Clock{"x": 1}, After},
+		{"concurrent", Clock{"x": 2}, Clock{"x": 1, "y": 1}, Concurrent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Compare(tt.a, tt.b); got != tt.want {
+				t.Errorf("Compare(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	merged := Merge(Clock{"x": 2, "y": 1}, Clock{"y": 3}, nil)
+	if Compare(merged, Clock{"x": 2, "y": 3}) != Equal {
+		t.Errorf("Merge() = %v, want x:2 y:3", merged)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	v1 := Version{Value: "1", Clock: Clock{"a": 1}}
+	v2 := Version{Value: "2", Clock: Clock{"a": 2}}
+	fromB := Version{Value: "b", Clock: Clock{"a": 1, "b": 1}}
+	merged := Version{Value: "m", Clock: Clock{"a": 2, "b": 1}}
+
+	tests := []struct {
+		name     string
+		siblings []Version
+		add      Version
+		want     []string
+	}{
+		{"first version", nil, v1, []string{"1"}},
+		{"descendant replaces ancestor", []Version{v1}, v2, []string{"2"}},
+		{"ancestor is ignored", []Version{v2}, v1, []string{"2"}},
+		{"duplicate is ignored", []Version{v2}, v2, []string{"2"}},
+		{"concurrent becomes a sibling", []Version{v2}, fromB, []string{"2", "b"}},
+		{"merge replaces all siblings", []Version{v2, fromB}, merged, []string{"m"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Reconcile(tt.siblings, tt.add)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Reconcile() = %v, want values %v", got, tt.want)
+			}
+			for i, v := range got {
+				if v.Value != tt.want[i] {
+					t.Errorf("Reconcile()[%d] = %q, want %q", i, v.Value, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	replicaA := []Version{{Value: "old", Clock: Clock{"a": 1}}}
+	replicaB := []Version{{Value: "new", Clock: Clock{"a": 2}}, {Value: "other", Clock: Clock{"a": 1, "b": 1}}}
+
+	got := Resolve(replicaA, replicaB)
+	if len(got) != 2 {
+		t.Fatalf("Resolve() = %v, want the two concurrent versions", got)
+	}
+	if !Contains(got, replicaA[0]) {
+		t.Errorf("Contains() = false for an ancestor of a sibling")
+	}
+	if Contains(replicaA, replicaB[1]) {
+		t.Errorf("Contains() = true for a concurrent version")
+	}
+}
@@ -0,0 +1,25 @@
+// Package vclockpb converts the versions of package vclock to and from their protobuf messages
+package vclockpb
+
+import (
+	"key-value/proto/keyvalue"
+	"key-value/shared/vclock"
+)
+
+// ToProto converts versions for the wire
+func ToProto(versions []vclock.Version) []*keyvalue.Version {
+	out := make([]*keyvalue.Version, len(versions))
+	for i, v := range versions {
+		out[i] = &keyvalue.Version{Value: v.Value, Deleted: v.Deleted, Clock: v.Clock}
+	}
+	return out
+}
+
+// FromProto converts versions received from the wire
+func FromProto(versions []*keyvalue.Version) []vclock.Version {
+	out := make([]vclock.Version, len(versions))
+	for i, v := range versions {
+		out[i] = vclock.Version{Value: v.Value, Deleted: v.Deleted, Clock: v.Clock}
+	}
+	return out
+}
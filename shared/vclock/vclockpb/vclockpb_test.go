@@ -0,0 +1,21 @@
+package vclockpb
+
+import (
+	"reflect"
+	"testing"
+
+	"key-value/shared/vclock"
+)
+
+func TestRoundTrip(t *testing.T) {
+	versions := []vclock.Version{
+		{Value: "a", Clock: vclock.Clock{"x": 1}},
+		{Deleted: true, Clock: vclock.Clock{"x": 1, "y": 2}},
+	}
+	if got := FromProto(ToProto(versions)); !reflect.DeepEqual(got, versions) {
+		t.Errorf("FromProto(ToProto()) = %v, want %v", got, versions)
+	}
+	if got := ToProto(nil); len(got) != 0 {
+		t.Errorf("ToProto(nil) = %v, want empty", got)
+	}
+}